@@ -2,6 +2,11 @@
 // When GT_PROXY_URL, GT_PROXY_CERT, GT_PROXY_KEY, and GT_PROXY_CA are all set, it forwards
 // os.Args[1:] to the proxy server over mTLS and proxies the response.
 // Otherwise it execs the real binary at /usr/local/bin/gt.real (or the path in GT_REAL_BIN).
+//
+// Commands run over the streaming endpoint (/v1/exec/stream) so output appears as it is
+// produced, stdin is forwarded, and Ctrl-C interrupts the remote command. Servers without
+// that endpoint are used through the buffered /v1/exec, as is everything when
+// GT_PROXY_STREAM=0.
 package main
 
 import (
@@ -13,9 +18,13 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sync"
 	"syscall"
 	"time"
+
+	"golang.org/x/term"
 )
 
 type execRequest struct {
@@ -28,6 +37,15 @@ type execResponse struct {
 	ExitCode int    `json:"exitCode"`
 }
 
+// execFrame is one newline-delimited JSON message on /v1/exec/stream.
+type execFrame struct {
+	Type     string   `json:"type"`
+	Argv     []string `json:"argv,omitempty"`
+	Data     []byte   `json:"data,omitempty"`
+	ExitCode int      `json:"exitCode,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
 func main() {
 	// Required environment variables:
 	//   GT_PROXY_URL  — proxy base URL (e.g. https://172.17.0.1:9876)
@@ -36,6 +54,7 @@ func main() {
 	//   GT_PROXY_CA   — path to PEM proxy CA cert (used to verify server cert)
 	// Optional:
 	//   GT_REAL_BIN   — fallback binary path (default /usr/local/bin/gt.real)
+	//   GT_PROXY_STREAM — "0" to use the buffered exec endpoint only
 	proxyURL := os.Getenv("GT_PROXY_URL")
 	certFile := os.Getenv("GT_PROXY_CERT")
 	keyFile := os.Getenv("GT_PROXY_KEY")
@@ -73,10 +92,8 @@ func main() {
 		RootCAs:      pool,
 	}
 
-	httpClient := &http.Client{
-		Timeout:   5 * time.Minute,
-		Transport: &http.Transport{TLSClientConfig: tlsCfg},
-	}
+	// HTTP/2 lets the streaming endpoint send stdin and receive output at once.
+	transport := &http.Transport{TLSClientConfig: tlsCfg, ForceAttemptHTTP2: true}
 
 	// Determine argv: prepend the binary name so the server knows which tool we are.
 	argv := os.Args // os.Args[0] is the binary path; the server needs the tool name as argv[0].
@@ -84,6 +101,19 @@ func main() {
 	toolName := toolNameFromArg0(os.Args[0])
 	argv = append([]string{toolName}, os.Args[1:]...)
 
+	if os.Getenv("GT_PROXY_STREAM") != "0" {
+		// No client timeout: the stream lasts as long as the command, and the
+		// server enforces its own exec timeout.
+		if code, ok := runStream(&http.Client{Transport: transport}, proxyURL, argv); ok {
+			os.Exit(code)
+		}
+	}
+
+	httpClient := &http.Client{
+		Timeout:   5 * time.Minute,
+		Transport: transport,
+	}
+
 	body, err := json.Marshal(execRequest{Argv: argv})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "gt-proxy-client: encode request: %v\n", err)
@@ -118,6 +148,107 @@ func main() {
 	os.Exit(result.ExitCode)
 }
 
+// runStream runs argv over /v1/exec/stream, forwarding stdin (unless it is a
+// terminal) and relaying output as it arrives. The first SIGINT or SIGTERM
+// cancels the remote command; a second exits immediately. It returns
+// ok=false, before reading any stdin, if the server has no streaming endpoint.
+func runStream(client *http.Client, proxyURL string, argv []string) (exitCode int, ok bool) {
+	pr, pw := io.Pipe()
+	var mu sync.Mutex
+	enc := json.NewEncoder(pw)
+	send := func(f execFrame) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return enc.Encode(f)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, proxyURL+"/v1/exec/stream", pr) //nolint:gosec // proxyURL is from trusted env var GT_PROXY_URL
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gt-proxy-client: build request: %v\n", err)
+		os.Exit(1)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	go func() {
+		if err := send(execFrame{Type: "start", Argv: argv}); err != nil {
+			_ = pw.CloseWithError(err)
+		}
+	}()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gt-proxy-client: proxy request failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer resp.Body.Close() //nolint:errcheck // best-effort close on response body
+
+	if resp.StatusCode == http.StatusNotFound {
+		_ = pw.Close()
+		return 0, false
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		fmt.Fprintf(os.Stderr, "gt-proxy-client: server error %d: %s\n", resp.StatusCode, msg)
+		os.Exit(1)
+	}
+
+	go func() {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			buf := make([]byte, 32<<10)
+			for {
+				n, err := os.Stdin.Read(buf)
+				if n > 0 {
+					if send(execFrame{Type: "stdin", Data: buf[:n]}) != nil {
+						return
+					}
+				}
+				if err != nil {
+					break
+				}
+			}
+		}
+		_ = send(execFrame{Type: "eof"})
+	}()
+
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		_ = send(execFrame{Type: "cancel"})
+		<-sigs
+		os.Exit(130)
+	}()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var f execFrame
+		if err := dec.Decode(&f); err != nil {
+			fmt.Fprintf(os.Stderr, "gt-proxy-client: stream ended without exit status: %v\n", err)
+			os.Exit(1)
+		}
+		switch f.Type {
+		case "stdout":
+			_, _ = os.Stdout.Write(f.Data)
+		case "stderr":
+			_, _ = os.Stderr.Write(f.Data)
+		case "exit":
+			switch f.Error {
+			case "":
+			case "canceled":
+				return 130, true
+			case "timeout":
+				fmt.Fprintf(os.Stderr, "gt-proxy-client: command timed out on the proxy\n")
+				return 124, true
+			default:
+				fmt.Fprintf(os.Stderr, "gt-proxy-client: %s\n", f.Error)
+			}
+			if f.ExitCode < 0 {
+				return 1, true
+			}
+			return f.ExitCode, true
+		}
+	}
+}
+
 // toolNameFromArg0 extracts "gt" or "bd" from the argv[0] binary path.
 func toolNameFromArg0(arg0 string) string {
 	return filepath.Base(arg0)
@@ -56,6 +56,15 @@ type ProxyConfig struct {
 	//   - A split-horizon DNS entry that resolves to the proxy IP
 	//   - A mDNS name (e.g. "macbook.local")
 	ExtraSANHosts []string `json:"extra_san_hosts"`
+
+	// ExecCPUQuota is the CPU time (user+system) each client identity may use
+	// across exec commands per ExecCPUQuotaWindow, as a Go duration string
+	// (e.g. "10m"). Empty disables the quota.
+	ExecCPUQuota string `json:"exec_cpu_quota"`
+
+	// ExecCPUQuotaWindow is the period over which ExecCPUQuota refills
+	// (e.g. "1h"). Defaults to one hour.
+	ExecCPUQuotaWindow string `json:"exec_cpu_quota_window"`
 }
 
 // loadConfig reads the config file at path and returns a ProxyConfig.
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -199,3 +200,19 @@ func TestParseAllowedSubcmdsRoundTrip(t *testing.T) {
 		assert.Empty(t, reparsed, "empty sub-list is lost on round-trip")
 	})
 }
+
+func TestParseOptionalDuration(t *testing.T) {
+	d, err := parseOptionalDuration("")
+	require.NoError(t, err)
+	assert.Zero(t, d)
+
+	d, err = parseOptionalDuration(" 10m ")
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, d)
+
+	_, err = parseOptionalDuration("ten minutes")
+	assert.Error(t, err)
+
+	_, err = parseOptionalDuration("-1h")
+	assert.Error(t, err)
+}
@@ -5,6 +5,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log/slog"
 	"net"
@@ -14,6 +15,7 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/steveyegge/gastown/internal/proxy"
 )
@@ -126,6 +128,19 @@ func main() {
 		}
 	}
 
+	// Parse the exec CPU quota: durations, rejected outright if malformed so a
+	// typo doesn't silently disable the limit.
+	cpuQuota, err := parseOptionalDuration(fileCfg.ExecCPUQuota)
+	if err != nil {
+		slog.Error("exec_cpu_quota: invalid duration", "value", fileCfg.ExecCPUQuota, "err", err)
+		os.Exit(1)
+	}
+	cpuQuotaWindow, err := parseOptionalDuration(fileCfg.ExecCPUQuotaWindow)
+	if err != nil {
+		slog.Error("exec_cpu_quota_window: invalid duration", "value", fileCfg.ExecCPUQuotaWindow, "err", err)
+		os.Exit(1)
+	}
+
 	cfg := proxy.Config{
 		ListenAddr:         *listen,
 		AdminListenAddr:    *adminListen,
@@ -134,6 +149,8 @@ func main() {
 		TownRoot:           *townRoot,
 		ExtraSANIPs:        extraSANIPs,
 		ExtraSANHosts:      extraSANHosts,
+		ExecCPUQuota:       cpuQuota,
+		ExecCPUQuotaWindow: cpuQuotaWindow,
 	}
 
 	srv, err := proxy.New(cfg, ca)
@@ -151,6 +168,23 @@ func main() {
 	}
 }
 
+// parseOptionalDuration parses a duration string from the config file; an
+// empty string is zero (use the default).
+func parseOptionalDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, errors.New("must not be negative")
+	}
+	return d, nil
+}
+
 // discoverAllowedSubcmds calls "gt proxy-subcmds" to auto-discover the allowed
 // subcommand list. Falls back to defaultAllowedSubcmds if the command is
 // unavailable or returns empty output.
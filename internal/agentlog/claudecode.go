@@ -359,3 +359,45 @@ func parseClaudeCodeLine(line, sessionID, agentType, nativeSessionID string) []A
 
 	return events
 }
+
+// TranscriptPath returns ~/.claude/projects/<hash>/<nativeSessionID>.jsonl
+// for workDir when it exists.
+func (a *ClaudeCodeAdapter) TranscriptPath(workDir, nativeSessionID string) (string, bool) {
+	if workDir == "" || nativeSessionID == "" {
+		return "", false
+	}
+	projectDir, err := claudeProjectDirFor(workDir)
+	if err != nil {
+		return "", false
+	}
+	path := filepath.Join(projectDir, nativeSessionID+".jsonl")
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// ReadTranscript parses every line of a Claude Code JSONL file.
+func (a *ClaudeCodeAdapter) ReadTranscript(path, sessionID string) ([]AgentEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	nativeID := nativeSessionIDFromPath(path)
+	reader := bufio.NewReaderSize(f, 256*1024)
+	var events []AgentEvent
+	for {
+		line, err := reader.ReadString('\n')
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			events = append(events, parseClaudeCodeLine(line, sessionID, a.AgentType(), nativeID)...)
+		}
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+}
@@ -115,3 +115,44 @@ func TestNewAdapter(t *testing.T) {
 		})
 	}
 }
+
+func TestClaudeCodeAdapter_ReadTranscript(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	workDir := filepath.Join(home, "gt", "gastown", "crew", "joe")
+
+	a := &ClaudeCodeAdapter{}
+	id := "0b4c9f7e-1111-2222-3333-444455556666"
+	if _, ok := a.TranscriptPath(workDir, id); ok {
+		t.Fatal("TranscriptPath found a transcript that does not exist")
+	}
+
+	projectDir, err := claudeProjectDirFor(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	lines := `{"type":"user","message":{"role":"user","content":[{"type":"text","text":"why pin grpc?"}]},"timestamp":"2026-01-02T03:04:05Z"}
+{"type":"summary","summary":"ignored"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Pinned grpc to 1.62 because 1.63 breaks the proxy."}]},"timestamp":"2026-01-02T03:04:09Z"}`
+	if err := os.WriteFile(filepath.Join(projectDir, id+".jsonl"), []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, ok := a.TranscriptPath(workDir, id)
+	if !ok {
+		t.Fatal("TranscriptPath did not find the transcript")
+	}
+	events, err := a.ReadTranscript(path, "gt-crew-joe")
+	if err != nil {
+		t.Fatalf("ReadTranscript: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2 (last line has no newline)", len(events))
+	}
+	if events[1].Role != "assistant" || events[1].NativeSessionID != id || events[1].SessionID != "gt-crew-joe" {
+		t.Errorf("unexpected event: %+v", events[1])
+	}
+}
@@ -45,6 +45,18 @@ type AgentAdapter interface {
 	Watch(ctx context.Context, sessionID, workDir string, since time.Time) (<-chan AgentEvent, error)
 }
 
+// TranscriptReader is implemented by adapters that can read a finished
+// conversation whole, for archiving past sessions.
+type TranscriptReader interface {
+	// TranscriptPath locates the transcript of nativeSessionID, started in
+	// workDir. ok is false when the file does not exist.
+	TranscriptPath(workDir, nativeSessionID string) (path string, ok bool)
+
+	// ReadTranscript parses a complete transcript file. sessionID is the
+	// Gas Town session name to tag events with; it may be empty.
+	ReadTranscript(path, sessionID string) ([]AgentEvent, error)
+}
+
 // NewAdapter returns the AgentAdapter for the given agent type name.
 // Returns nil if the agent type is unknown.
 func NewAdapter(agentType string) AgentAdapter {
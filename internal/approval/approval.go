@@ -0,0 +1,363 @@
+// Package approval stores human approval requests for molecule gate steps.
+//
+// A formula step with gate = "approval" parks its molecule: the agent files
+// a request (gt approve request), the overseer is notified by mail, and the
+// agent blocks in gt approve wait until someone approves or rejects it from
+// the CLI, the web dashboard, or a signed webhook. Requests live as JSON
+// files under <town>/.runtime/approvals/ so every entry point sees the same
+// state without a server.
+package approval
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Status is the state of an approval request.
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+	StatusExpired  Status = "expired" // timed out with on_timeout = reject
+)
+
+// Where a decision came from.
+const (
+	ViaCLI     = "cli"
+	ViaWeb     = "web"
+	ViaWebhook = "webhook"
+	ViaTimeout = "timeout"
+)
+
+// Actions taken when a request times out; these mirror formula on_timeout.
+const (
+	TimeoutReject   = "reject"
+	TimeoutApprove  = "approve"
+	TimeoutEscalate = "escalate"
+)
+
+var (
+	// ErrNotFound is returned when no request has the given ID.
+	ErrNotFound = errors.New("approval request not found")
+	// ErrDecided is returned when deciding a request that is no longer pending.
+	ErrDecided = errors.New("approval request already decided")
+	// ErrNotApprover is returned when the decider may not decide the request.
+	ErrNotApprover = errors.New("not an approver for this request")
+)
+
+// DefaultApprovers is who may decide a request that names no approvers.
+var DefaultApprovers = []string{"overseer"}
+
+// Request is a pending or decided approval for one gate step.
+type Request struct {
+	ID          string     `json:"id"`
+	Bead        string     `json:"bead"`              // molecule root or hooked bead the gate blocks
+	Formula     string     `json:"formula,omitempty"` // formula the gate step belongs to
+	Step        string     `json:"step"`
+	Title       string     `json:"title,omitempty"`
+	RequestedBy string     `json:"requested_by"`
+	Approvers   []string   `json:"approvers,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	OnTimeout   string     `json:"on_timeout,omitempty"`
+	Escalated   bool       `json:"escalated,omitempty"` // on_timeout = escalate has fired
+
+	Status    Status     `json:"status"`
+	DecidedBy string     `json:"decided_by,omitempty"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	Via       string     `json:"via,omitempty"`
+}
+
+// Pending reports whether the request is still waiting for a decision.
+func (r *Request) Pending() bool {
+	return r.Status == StatusPending
+}
+
+// CanDecide returns ErrNotApprover unless by is one of the request's
+// approvers. The requester may never decide its own request, even when it is
+// listed as an approver.
+func (r *Request) CanDecide(by string) error {
+	by = strings.TrimSuffix(by, "/")
+	if by == "" {
+		return fmt.Errorf("%w: no decider identity", ErrNotApprover)
+	}
+	if by == strings.TrimSuffix(r.RequestedBy, "/") {
+		return fmt.Errorf("%w: %s requested %s and cannot decide it", ErrNotApprover, by, r.ID)
+	}
+	approvers := r.Approvers
+	if len(approvers) == 0 {
+		approvers = DefaultApprovers
+	}
+	for _, a := range approvers {
+		if strings.TrimSuffix(a, "/") == by {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is not one of %s", ErrNotApprover, by, strings.Join(approvers, ", "))
+}
+
+// Decider returns who decided the request, for the audit trail.
+func (r *Request) Decider() string {
+	if r.DecidedBy != "" {
+		return r.DecidedBy
+	}
+	if r.Via == ViaTimeout {
+		return "timeout"
+	}
+	return "unknown"
+}
+
+// Decision is a verdict on a pending request.
+type Decision struct {
+	Approve bool
+	By      string
+	Reason  string
+	Via     string
+}
+
+// Dir returns the directory holding approval requests for a town.
+func Dir(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "approvals")
+}
+
+// Store reads and writes approval requests for a town.
+type Store struct {
+	dir string
+	now func() time.Time
+}
+
+// NewStore returns the approval store for townRoot.
+func NewStore(townRoot string) *Store {
+	return &Store{dir: Dir(townRoot), now: time.Now}
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// validID rejects IDs that could escape the store directory.
+func validID(id string) bool {
+	return strings.HasPrefix(id, "ap-") && !strings.ContainsAny(id, `/\.`) && len(id) <= 64
+}
+
+// lock serializes writers across gt processes. Caller must Unlock.
+func (s *Store) lock() (*flock.Flock, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating approvals dir: %w", err)
+	}
+	fl := flock.New(filepath.Join(s.dir, ".lock"))
+	if err := fl.Lock(); err != nil {
+		return nil, fmt.Errorf("acquiring approvals lock: %w", err)
+	}
+	return fl, nil
+}
+
+// Create assigns req an ID, marks it pending, and saves it.
+func (s *Store) Create(req *Request) error {
+	if req.Bead == "" || req.Step == "" {
+		return fmt.Errorf("approval request needs a bead and a step")
+	}
+	fl, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	req.ID = id
+	req.Status = StatusPending
+	if req.CreatedAt.IsZero() {
+		req.CreatedAt = s.now().UTC()
+	}
+	return s.save(req)
+}
+
+// Get loads the request with the given ID.
+func (s *Store) Get(id string) (*Request, error) {
+	if !validID(id) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	data, err := os.ReadFile(s.path(id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("parsing approval %s: %w", id, err)
+	}
+	return &req, nil
+}
+
+// List returns all requests, oldest first. Unreadable files are skipped.
+func (s *Store) List() ([]*Request, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []*Request
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		req, err := s.Get(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			continue
+		}
+		out = append(out, req)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// Decide records d on a pending request and returns the updated request.
+// Deciding an already-decided request returns ErrDecided along with the
+// request as it stands; a decider who may not decide it (see CanDecide)
+// gets ErrNotApprover. A request past its deadline is left for ExpireDue
+// unless it escalates on timeout.
+func (s *Store) Decide(id string, d Decision) (*Request, error) {
+	fl, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	req, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Pending() {
+		return req, fmt.Errorf("%w: %s is %s", ErrDecided, id, req.Status)
+	}
+	if req.ExpiresAt != nil && !s.now().Before(*req.ExpiresAt) && req.OnTimeout != TimeoutEscalate {
+		return req, fmt.Errorf("%w: %s has timed out", ErrDecided, id)
+	}
+	if err := req.CanDecide(d.By); err != nil {
+		return req, err
+	}
+	status := StatusRejected
+	if d.Approve {
+		status = StatusApproved
+	}
+	s.decide(req, status, d.By, d.Reason, d.Via)
+	return req, s.save(req)
+}
+
+// ExpireDue applies the on_timeout action to pending requests whose
+// deadline has passed and returns the requests it changed. Requests set to
+// escalate stay pending and are returned once, with Escalated set, so the
+// caller can notify the escalation route.
+func (s *Store) ExpireDue() ([]*Request, error) {
+	fl, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	reqs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	var changed []*Request
+	for _, req := range reqs {
+		if !req.Pending() || req.ExpiresAt == nil || now.Before(*req.ExpiresAt) {
+			continue
+		}
+		reason := fmt.Sprintf("timed out after %s", req.ExpiresAt.Sub(req.CreatedAt).Round(time.Second))
+		switch req.OnTimeout {
+		case TimeoutApprove:
+			s.decide(req, StatusApproved, "", reason, ViaTimeout)
+		case TimeoutEscalate:
+			if req.Escalated {
+				continue
+			}
+			req.Escalated = true
+		default:
+			s.decide(req, StatusExpired, "", reason, ViaTimeout)
+		}
+		if err := s.save(req); err != nil {
+			return changed, err
+		}
+		changed = append(changed, req)
+	}
+	return changed, nil
+}
+
+// Approved reports whether step of formula has an approved request on any
+// of beads. Gates are checked at step completion (gt done, gt mol step done)
+// so an agent cannot finish past a gate it was never approved through.
+func (s *Store) Approved(formula, step string, beads ...string) (bool, error) {
+	reqs, err := s.List()
+	if err != nil {
+		return false, err
+	}
+	for _, req := range reqs {
+		if req.Status == StatusApproved && req.Formula == formula && req.Step == step && slices.Contains(beads, req.Bead) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HasDue reports whether any pending request is past its deadline and not
+// yet handled by ExpireDue. It takes no lock, so callers polling for work
+// (the daemon heartbeat) can check cheaply before sweeping.
+func (s *Store) HasDue() bool {
+	reqs, err := s.List()
+	if err != nil {
+		return false
+	}
+	now := s.now()
+	for _, req := range reqs {
+		if req.Pending() && req.ExpiresAt != nil && !now.Before(*req.ExpiresAt) && !req.Escalated {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) decide(req *Request, status Status, by, reason, via string) {
+	now := s.now().UTC()
+	req.Status = status
+	req.DecidedBy = by
+	req.DecidedAt = &now
+	req.Reason = reason
+	req.Via = via
+}
+
+func (s *Store) save(req *Request) error {
+	return util.AtomicWriteJSON(s.path(req.ID), req)
+}
+
+func newID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating approval id: %w", err)
+	}
+	return "ap-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,246 @@
+package approval
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T, now *time.Time) *Store {
+	t.Helper()
+	s := NewStore(t.TempDir())
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestCreateDecide(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+
+	req := &Request{Bead: "gt-wisp-abc", Step: "signoff", RequestedBy: "gastown/polecats/Toast"}
+	if err := s.Create(req); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !validID(req.ID) || req.Status != StatusPending {
+		t.Fatalf("created = %+v", req)
+	}
+
+	if _, err := s.Decide(req.ID, Decision{Approve: true, By: "gastown/polecats/Toast", Via: ViaCLI}); !errors.Is(err, ErrNotApprover) {
+		t.Errorf("requester Decide err = %v, want ErrNotApprover", err)
+	}
+	if _, err := s.Decide(req.ID, Decision{Approve: true, By: "mayor/", Via: ViaCLI}); !errors.Is(err, ErrNotApprover) {
+		t.Errorf("non-approver Decide err = %v, want ErrNotApprover", err)
+	}
+
+	got, err := s.Decide(req.ID, Decision{Approve: true, By: "overseer", Reason: "ship it", Via: ViaCLI})
+	if err != nil {
+		t.Fatalf("Decide: %v", err)
+	}
+	if got.Status != StatusApproved || got.DecidedBy != "overseer" || got.Via != ViaCLI {
+		t.Errorf("decided = %+v", got)
+	}
+
+	if _, err := s.Decide(req.ID, Decision{By: "overseer"}); !errors.Is(err, ErrDecided) {
+		t.Errorf("second Decide err = %v, want ErrDecided", err)
+	}
+	if _, err := s.Get("ap-missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get missing err = %v", err)
+	}
+	if _, err := s.Get("../../etc/passwd"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get traversal err = %v", err)
+	}
+
+	list, err := s.List()
+	if err != nil || len(list) != 1 || list[0].Status != StatusApproved {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+}
+
+func TestCanDecide(t *testing.T) {
+	req := &Request{ID: "ap-1", RequestedBy: "gastown/crew/joe", Approvers: []string{"mayor/", "gastown/crew/joe", "overseer"}}
+	for _, by := range []string{"mayor/", "mayor", "overseer"} {
+		if err := req.CanDecide(by); err != nil {
+			t.Errorf("CanDecide(%q) = %v", by, err)
+		}
+	}
+	for _, by := range []string{"", "gastown/crew/joe", "gastown/polecats/Toast", "webhook"} {
+		if err := req.CanDecide(by); !errors.Is(err, ErrNotApprover) {
+			t.Errorf("CanDecide(%q) = %v, want ErrNotApprover", by, err)
+		}
+	}
+
+	// No approvers named: the overseer decides.
+	req.Approvers = nil
+	if err := req.CanDecide("overseer"); err != nil {
+		t.Errorf("default approvers: %v", err)
+	}
+	if err := req.CanDecide("mayor/"); !errors.Is(err, ErrNotApprover) {
+		t.Errorf("default approvers let mayor decide: %v", err)
+	}
+}
+
+func TestDecideAfterDeadline(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+	deadline := now.Add(time.Hour)
+	expiring := &Request{Bead: "gt-1", Step: "a", ExpiresAt: &deadline}
+	escalating := &Request{Bead: "gt-1", Step: "b", ExpiresAt: &deadline, OnTimeout: TimeoutEscalate}
+	for _, req := range []*Request{expiring, escalating} {
+		if err := s.Create(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now = deadline.Add(time.Minute)
+	if _, err := s.Decide(expiring.ID, Decision{Approve: true, By: "overseer"}); !errors.Is(err, ErrDecided) {
+		t.Errorf("timed-out Decide err = %v, want ErrDecided", err)
+	}
+	if _, err := s.Decide(escalating.ID, Decision{Approve: true, By: "overseer"}); err != nil {
+		t.Errorf("escalated request should stay decidable: %v", err)
+	}
+}
+
+func TestDecider(t *testing.T) {
+	if got := (&Request{DecidedBy: "overseer"}).Decider(); got != "overseer" {
+		t.Errorf("decider = %q", got)
+	}
+	if got := (&Request{Via: ViaTimeout}).Decider(); got != "timeout" {
+		t.Errorf("timeout decider = %q", got)
+	}
+}
+
+func TestExpireDue(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+	deadline := now.Add(time.Hour)
+
+	ids := map[string]string{}
+	for _, action := range []string{"", TimeoutApprove, TimeoutEscalate} {
+		req := &Request{Bead: "gt-1", Step: "gate-" + action, ExpiresAt: &deadline, OnTimeout: action}
+		if err := s.Create(req); err != nil {
+			t.Fatal(err)
+		}
+		ids[action] = req.ID
+	}
+	noDeadline := &Request{Bead: "gt-1", Step: "forever"}
+	if err := s.Create(noDeadline); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, err := s.ExpireDue(); err != nil || len(changed) != 0 {
+		t.Fatalf("ExpireDue before deadline = %v, %v", changed, err)
+	}
+	if s.HasDue() {
+		t.Error("HasDue before deadline = true")
+	}
+
+	now = deadline.Add(time.Minute)
+	if !s.HasDue() {
+		t.Error("HasDue after deadline = false")
+	}
+	changed, err := s.ExpireDue()
+	if err != nil || len(changed) != 3 {
+		t.Fatalf("ExpireDue = %d changed, %v", len(changed), err)
+	}
+	want := map[string]Status{"": StatusExpired, TimeoutApprove: StatusApproved, TimeoutEscalate: StatusPending}
+	for action, status := range want {
+		req, _ := s.Get(ids[action])
+		if req.Status != status {
+			t.Errorf("on_timeout %q: status = %s, want %s", action, req.Status, status)
+		}
+	}
+	if req, _ := s.Get(ids[TimeoutEscalate]); !req.Escalated {
+		t.Error("escalate request should be marked escalated")
+	}
+
+	// Escalation fires once.
+	if changed, _ := s.ExpireDue(); len(changed) != 0 {
+		t.Errorf("second ExpireDue changed %d", len(changed))
+	}
+	if s.HasDue() {
+		t.Error("HasDue after sweep = true")
+	}
+}
+
+func TestApproved(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+
+	pending := &Request{Bead: "gt-wisp-abc", Formula: "mol-release", Step: "signoff", RequestedBy: "gastown/polecats/Toast"}
+	rejected := &Request{Bead: "gt-wisp-abc", Formula: "mol-release", Step: "deploy", RequestedBy: "gastown/polecats/Toast"}
+	for _, req := range []*Request{pending, rejected} {
+		if err := s.Create(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.Decide(rejected.ID, Decision{By: "overseer", Via: ViaCLI}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.Approved("mol-release", "signoff", "gt-wisp-abc"); err != nil || ok {
+		t.Errorf("Approved(pending) = %v, %v", ok, err)
+	}
+	if ok, _ := s.Approved("mol-release", "deploy", "gt-wisp-abc"); ok {
+		t.Error("Approved(rejected) = true")
+	}
+
+	if _, err := s.Decide(pending.ID, Decision{Approve: true, By: "overseer", Via: ViaCLI}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.Approved("mol-release", "signoff", "gt-base", "gt-wisp-abc"); err != nil || !ok {
+		t.Errorf("Approved(approved) = %v, %v", ok, err)
+	}
+	if ok, _ := s.Approved("mol-release", "signoff", "gt-other"); ok {
+		t.Error("approval on another bead should not count")
+	}
+	if ok, _ := s.Approved("mol-hotfix", "signoff", "gt-wisp-abc"); ok {
+		t.Error("approval for another formula should not count")
+	}
+}
+
+func TestParseWebhook(t *testing.T) {
+	secret := []byte("s3cret")
+	now := time.Unix(1773144000, 0)
+	body := []byte(fmt.Sprintf(`{"id":"ap-1a2b3c4d","decision":"approve","by":"ci","timestamp":%d}`, now.Unix()))
+
+	p, err := ParseWebhook(secret, body, Sign(secret, body), now)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if p.ID != "ap-1a2b3c4d" || p.Decision != "approve" || p.By != "ci" {
+		t.Errorf("payload = %+v", p)
+	}
+
+	if _, err := ParseWebhook(secret, body, Sign([]byte("other"), body), now); err == nil {
+		t.Error("wrong secret should fail")
+	}
+	if _, err := ParseWebhook(secret, body, Sign(secret, body), now.Add(10*time.Minute)); err == nil {
+		t.Error("stale timestamp should fail")
+	}
+	if _, err := ParseWebhook(nil, body, Sign(nil, body), now); !errors.Is(err, ErrNoSecret) {
+		t.Errorf("empty secret err = %v", err)
+	}
+	bad := []byte(fmt.Sprintf(`{"id":"ap-1","decision":"maybe","timestamp":%d}`, now.Unix()))
+	if _, err := ParseWebhook(secret, bad, Sign(secret, bad), now); err == nil {
+		t.Error("unknown decision should fail")
+	}
+}
+
+func TestLoadSecret(t *testing.T) {
+	townRoot := t.TempDir()
+	t.Setenv(SecretEnv, "")
+	if _, err := LoadSecret(townRoot); !errors.Is(err, ErrNoSecret) {
+		t.Fatalf("LoadSecret without secret err = %v", err)
+	}
+	secret, err := GenerateSecret(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LoadSecret(townRoot); err != nil || string(got) != secret {
+		t.Errorf("LoadSecret = %q, %v", got, err)
+	}
+	t.Setenv(SecretEnv, "from-env")
+	if got, _ := LoadSecret(townRoot); string(got) != "from-env" {
+		t.Errorf("env override = %q", got)
+	}
+}
@@ -0,0 +1,38 @@
+package approval
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// UpdateBead labels and comments on the bead a request gates. The approval
+// store is the source of truth and the bead just carries the audit trail, so
+// callers treat the error as a warning.
+func UpdateBead(townRoot, beadID string, add, remove []string, comment string) error {
+	bd := beads.New(townRoot)
+	var errs []error
+	if err := bd.Update(beadID, beads.UpdateOptions{AddLabels: add, RemoveLabels: remove}); err != nil {
+		errs = append(errs, fmt.Errorf("labeling %s: %w", beadID, err))
+	}
+	if _, err := bd.Run("comment", beadID, comment); err != nil {
+		errs = append(errs, fmt.Errorf("commenting on %s: %w", beadID, err))
+	}
+	return errors.Join(errs...)
+}
+
+// RecordDecision records a decided request on its bead as an
+// approval:<status> label and a comment, and logs an approval_decided event.
+func RecordDecision(townRoot string, req *Request) error {
+	note := fmt.Sprintf("Approval %s for step %s: %s by %s via %s",
+		req.ID, req.Step, req.Status, req.Decider(), req.Via)
+	if req.Reason != "" {
+		note += " — " + req.Reason
+	}
+	err := UpdateBead(townRoot, req.Bead, []string{"approval:" + string(req.Status)}, []string{"approval:pending"}, note)
+	_ = events.LogAt(townRoot, events.TypeApprovalDecided, req.Decider(),
+		events.ApprovalPayload(req.ID, req.Bead, req.Step, string(req.Status)))
+	return err
+}
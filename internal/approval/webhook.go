@@ -0,0 +1,116 @@
+package approval
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the webhook body's HMAC-SHA256 as "sha256=<hex>".
+const SignatureHeader = "X-Gastown-Signature"
+
+// SecretEnv overrides the webhook secret file.
+const SecretEnv = "GT_APPROVAL_WEBHOOK_SECRET"
+
+// MaxWebhookSkew bounds how far a webhook timestamp may be from now, so a
+// captured request cannot be replayed later.
+const MaxWebhookSkew = 5 * time.Minute
+
+// ErrNoSecret is returned when no webhook secret is configured.
+var ErrNoSecret = errors.New("approval webhook secret not configured (run 'gt approve webhook-secret')")
+
+// WebhookPayload is the JSON body of an approval webhook.
+type WebhookPayload struct {
+	ID        string `json:"id"`
+	Decision  string `json:"decision"` // "approve" or "reject"
+	By        string `json:"by,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Timestamp int64  `json:"timestamp"` // unix seconds
+}
+
+// SecretPath returns the file holding the town's webhook secret.
+func SecretPath(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "approval-webhook.secret")
+}
+
+// LoadSecret returns the webhook secret from SecretEnv or the town's
+// secret file.
+func LoadSecret(townRoot string) ([]byte, error) {
+	if s := strings.TrimSpace(os.Getenv(SecretEnv)); s != "" {
+		return []byte(s), nil
+	}
+	data, err := os.ReadFile(SecretPath(townRoot)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if os.IsNotExist(err) {
+		return nil, ErrNoSecret
+	}
+	if err != nil {
+		return nil, err
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return nil, ErrNoSecret
+	}
+	return []byte(secret), nil
+}
+
+// GenerateSecret writes a new random webhook secret for the town and
+// returns it.
+func GenerateSecret(townRoot string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating secret: %w", err)
+	}
+	secret := hex.EncodeToString(b)
+	path := SecretPath(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(secret+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// Sign returns the SignatureHeader value for body.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseWebhook verifies body against signature and returns the payload.
+// The timestamp must be within MaxWebhookSkew of now.
+func ParseWebhook(secret, body []byte, signature string, now time.Time) (*WebhookPayload, error) {
+	if len(secret) == 0 {
+		return nil, ErrNoSecret
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, body))) {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	var p WebhookPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	skew := now.Sub(time.Unix(p.Timestamp, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if p.Timestamp == 0 || skew > MaxWebhookSkew {
+		return nil, fmt.Errorf("timestamp outside allowed window of %s", MaxWebhookSkew)
+	}
+	if !validID(p.ID) {
+		return nil, fmt.Errorf("invalid approval id %q", p.ID)
+	}
+	if p.Decision != "approve" && p.Decision != "reject" {
+		return nil, fmt.Errorf("decision must be approve or reject, got %q", p.Decision)
+	}
+	return &p, nil
+}
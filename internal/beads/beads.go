@@ -10,10 +10,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/telemetry"
 )
@@ -442,7 +444,7 @@ func (b *Beads) buildRunEnv() []string {
 		return env
 	}
 	env := stripEnvPrefixes(os.Environ(), "BEADS_DIR=")
-	return translateDoltPort(env)
+	return followDoltFailover(translateDoltPort(env), b.getTownRoot())
 }
 
 // buildRoutingEnv builds the environment for runWithRouting() calls.
@@ -458,7 +460,7 @@ func (b *Beads) buildRoutingEnv() []string {
 		return env
 	}
 	env := stripEnvPrefixes(os.Environ(), "BEADS_DIR=")
-	return translateDoltPort(env)
+	return followDoltFailover(translateDoltPort(env), b.getTownRoot())
 }
 
 // filterBeadsEnv removes beads-related environment variables from the given
@@ -513,6 +515,23 @@ func translateDoltPort(env []string) []string {
 	return env
 }
 
+// followDoltFailover points bd at the promoted hot-standby replica while the
+// daemon has failed Dolt over (see config.DoltFailoverPort), overriding any
+// inherited port so bd doesn't keep dialing the wedged primary. GT_DOLT_PORT
+// keeps naming the primary: gt reads it as the primary's port and finds the
+// replica through the failover marker.
+func followDoltFailover(env []string, townRoot string) []string {
+	if townRoot == "" {
+		return env
+	}
+	port := config.DoltFailoverPort(townRoot)
+	if port == 0 {
+		return env
+	}
+	env = stripEnvPrefixes(env, "BEADS_DOLT_PORT=")
+	return append(env, "BEADS_DOLT_PORT="+strconv.Itoa(port))
+}
+
 // stripEnvPrefixes removes entries matching any of the given prefixes from an
 // environment variable slice. Used by runWithRouting to strip BEADS_DIR.
 func stripEnvPrefixes(environ []string, prefixes ...string) []string {
@@ -76,6 +76,12 @@ func (b *Beads) AddDelegation(d *Delegation) error {
 		return fmt.Errorf("setting delegation slot: %w", err)
 	}
 
+	// A parent in another town (town:id, from federation) has no local issue
+	// to block; the slot alone records the delegation.
+	if isTownRef(d.Parent) {
+		return nil
+	}
+
 	// Also add a dependency so child blocks parent (work must complete before parent can close)
 	if err := b.AddDependency(d.Parent, d.Child); err != nil {
 		// Log but don't fail - the delegation is still recorded
@@ -165,3 +171,10 @@ func (b *Beads) ListDelegationsFrom(parent string) ([]*Delegation, error) {
 
 	return delegations, nil
 }
+
+// isTownRef reports whether id refers to an issue in another town
+// ("town:id"), as opposed to a local ID or an "external:prefix:id" reference.
+func isTownRef(id string) bool {
+	prefix, rest, ok := strings.Cut(id, ":")
+	return ok && prefix != "" && prefix != "external" && rest != "" && !strings.Contains(rest, ":")
+}
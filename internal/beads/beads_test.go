@@ -727,6 +727,27 @@ Also see http://localhost:8080/api`,
 	}
 }
 
+func TestSetMRFieldsReviewRoundTrip(t *testing.T) {
+	issue := &Issue{Description: "branch: polecat/Nux/gt-xyz\nreview_status: pending\nreview-task-id: gt-rev1\n\nNotes."}
+
+	fields := ParseMRFields(issue)
+	if fields.ReviewStatus != ReviewPending || fields.ReviewTaskID != "gt-rev1" {
+		t.Fatalf("parsed review fields = %q/%q", fields.ReviewStatus, fields.ReviewTaskID)
+	}
+
+	fields.ReviewStatus = ReviewApproved
+	fields.Reviewer = "gastown/crew/max"
+	issue.Description = SetMRFields(issue, fields)
+
+	again := ParseMRFields(issue)
+	if again.ReviewStatus != ReviewApproved || again.Reviewer != "gastown/crew/max" || again.ReviewTaskID != "gt-rev1" {
+		t.Errorf("round trip = %+v", again)
+	}
+	if strings.Count(issue.Description, "review") != 3 || !strings.HasSuffix(issue.Description, "Notes.") {
+		t.Errorf("stale review lines or lost prose:\n%s", issue.Description)
+	}
+}
+
 // TestParseAttachmentFields tests parsing attachment fields from issue descriptions.
 func TestParseAttachmentFields(t *testing.T) {
 	tests := []struct {
@@ -2701,6 +2722,38 @@ func TestTranslateDoltPort(t *testing.T) {
 	}
 }
 
+func TestFollowDoltFailover(t *testing.T) {
+	townRoot := t.TempDir()
+	env := []string{"PATH=/usr/bin", "GT_DOLT_PORT=3307", "BEADS_DOLT_PORT=3307"}
+
+	writeMarker := func(content string) {
+		t.Helper()
+		path := filepath.Join(townRoot, "daemon", "dolt-failover.json")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(name string, got, want []string) {
+		t.Helper()
+		if strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("%s: followDoltFailover() = %v, want %v", name, got, want)
+		}
+	}
+
+	check("no marker", followDoltFailover(env, townRoot), env)
+
+	writeMarker(`{"active": true, "primary_port": 3307, "replica_port": 3308}`)
+	check("active", followDoltFailover(env, townRoot),
+		[]string{"PATH=/usr/bin", "GT_DOLT_PORT=3307", "BEADS_DOLT_PORT=3308"})
+	check("no town root", followDoltFailover(env, ""), env)
+
+	writeMarker(`{"active": false, "primary_port": 3307, "replica_port": 3308}`)
+	check("inactive", followDoltFailover(env, townRoot), env)
+}
+
 // ---------------------------------------------------------------------------
 // Integration tests — verify env behavior with real os.Environ()
 // ---------------------------------------------------------------------------
@@ -407,8 +407,24 @@ type MRFields struct {
 	PreVerified     bool   // Polecat ran full gates after rebasing onto target
 	PreVerifiedAt   string // ISO 8601 timestamp when verification completed
 	PreVerifiedBase string // Target branch SHA at verification time
+
+	// Review stage fields (set by the refinery when merge_queue.review is enabled)
+	ReviewStatus string // pending, approved, or changes_requested
+	ReviewTaskID string // Link to the review task blocking the MR (if any)
+	Reviewer     string // Who the review was routed to
+
+	// Stacked MR fields (set by gt mq submit --on)
+	StackOn   string // ID of the MR this one is stacked on
+	StackBase string // Base MR branch tip this branch was built on (restack point)
 }
 
+// MR review statuses stored in MRFields.ReviewStatus.
+const (
+	ReviewPending          = "pending"
+	ReviewApproved         = "approved"
+	ReviewChangesRequested = "changes_requested"
+)
+
 // ParseMRFields extracts structured merge-request fields from an issue's description.
 // Fields are expected as "key: value" lines, with optional prose text mixed in.
 // Returns nil if no MR fields are found.
@@ -490,6 +506,21 @@ func ParseMRFields(issue *Issue) *MRFields {
 		case "pre_verified_base", "pre-verified-base", "preverifiedbase":
 			fields.PreVerifiedBase = value
 			hasFields = true
+		case "review_status", "review-status", "reviewstatus":
+			fields.ReviewStatus = value
+			hasFields = true
+		case "review_task_id", "review-task-id", "reviewtaskid":
+			fields.ReviewTaskID = value
+			hasFields = true
+		case "reviewer":
+			fields.Reviewer = value
+			hasFields = true
+		case "stack_on", "stack-on", "stackon":
+			fields.StackOn = value
+			hasFields = true
+		case "stack_base", "stack-base", "stackbase":
+			fields.StackBase = value
+			hasFields = true
 		}
 	}
 
@@ -563,6 +594,21 @@ func FormatMRFields(fields *MRFields) string {
 	if fields.PreVerifiedBase != "" {
 		lines = append(lines, "pre_verified_base: "+fields.PreVerifiedBase)
 	}
+	if fields.ReviewStatus != "" {
+		lines = append(lines, "review_status: "+fields.ReviewStatus)
+	}
+	if fields.ReviewTaskID != "" {
+		lines = append(lines, "review_task_id: "+fields.ReviewTaskID)
+	}
+	if fields.Reviewer != "" {
+		lines = append(lines, "reviewer: "+fields.Reviewer)
+	}
+	if fields.StackOn != "" {
+		lines = append(lines, "stack_on: "+fields.StackOn)
+	}
+	if fields.StackBase != "" {
+		lines = append(lines, "stack_base: "+fields.StackBase)
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -618,6 +664,19 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 		"pre_verified_base":  true,
 		"pre-verified-base":  true,
 		"preverifiedbase":    true,
+		"review_status":      true,
+		"review-status":      true,
+		"reviewstatus":       true,
+		"review_task_id":     true,
+		"review-task-id":     true,
+		"reviewtaskid":       true,
+		"reviewer":           true,
+		"stack_on":           true,
+		"stack-on":           true,
+		"stackon":            true,
+		"stack_base":         true,
+		"stack-base":         true,
+		"stackbase":          true,
 	}
 
 	// Collect non-MR lines from existing description
@@ -0,0 +1,300 @@
+// Package beads provides the structured handoff document format.
+package beads
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Handoff section headings, in document order.
+const (
+	HandoffGoal          = "Goal"
+	HandoffState         = "State"
+	HandoffDecisions     = "Decisions"
+	HandoffOpenQuestions = "Open Questions"
+	HandoffNextSteps     = "Next Steps"
+	HandoffReferences    = "References"
+)
+
+// HandoffSections lists the structured handoff sections in document order.
+var HandoffSections = []string{
+	HandoffGoal, HandoffState, HandoffDecisions,
+	HandoffOpenQuestions, HandoffNextSteps, HandoffReferences,
+}
+
+// HandoffRef is a pointer from a handoff to a commit or bead.
+type HandoffRef struct {
+	Kind string // "commit", "bead", or "" when unrecognized
+	ID   string
+	Note string // optional text after the ID
+}
+
+// HandoffDoc is a structured handoff: what the session was doing, where it
+// got to, what it decided and why, what is unresolved, and what comes next.
+// It is stored as markdown with one "## <Section>" heading per field, so it
+// stays readable in mail and bead descriptions.
+type HandoffDoc struct {
+	Goal          string
+	State         string
+	Decisions     []string
+	OpenQuestions []string
+	NextSteps     []string
+	References    []HandoffRef
+	// Notes holds text outside the known sections (preamble, extra headings,
+	// collected state), preserved verbatim.
+	Notes string
+}
+
+var (
+	handoffHeadingRe = regexp.MustCompile(`^#{1,3}\s+(.+?)\s*#*$`)
+	handoffListRe    = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+`)
+	handoffCommitRe  = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+	handoffBeadRe    = regexp.MustCompile(`^[a-z][a-z0-9]*-[a-z0-9][a-z0-9.-]*$`)
+	handoffCommentRe = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// handoffSectionFor maps a heading to its canonical section name.
+func handoffSectionFor(heading string) string {
+	switch strings.ToLower(strings.TrimSuffix(strings.TrimSpace(heading), ":")) {
+	case "goal":
+		return HandoffGoal
+	case "state", "current state", "status":
+		return HandoffState
+	case "decisions", "decisions made":
+		return HandoffDecisions
+	case "open questions", "questions":
+		return HandoffOpenQuestions
+	case "next steps", "next":
+		return HandoffNextSteps
+	case "references", "refs":
+		return HandoffReferences
+	}
+	return ""
+}
+
+// ParseHandoffDoc parses a handoff body. ok is false when the text has no
+// structured section headings, i.e. it is a free-form handoff. Template hint
+// comments (<!-- ... -->) are ignored.
+func ParseHandoffDoc(text string) (doc *HandoffDoc, ok bool) {
+	text = handoffCommentRe.ReplaceAllString(text, "")
+	doc = &HandoffDoc{}
+	bodies := map[string][]string{}
+	var notes []string
+	section := ""
+	for _, line := range strings.Split(text, "\n") {
+		if m := handoffHeadingRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			if name := handoffSectionFor(m[1]); name != "" {
+				section, ok = name, true
+				continue
+			}
+			section = "" // unknown heading: its body goes to notes
+		} else if strings.TrimSpace(line) == "---" && section != "" {
+			section = "" // rule after the sections starts the notes
+			continue
+		}
+		if section == "" {
+			notes = append(notes, line)
+			continue
+		}
+		bodies[section] = append(bodies[section], line)
+	}
+	if !ok {
+		return nil, false
+	}
+
+	doc.Goal = handoffProse(bodies[HandoffGoal])
+	doc.State = handoffProse(bodies[HandoffState])
+	doc.Decisions = handoffList(bodies[HandoffDecisions])
+	doc.OpenQuestions = handoffList(bodies[HandoffOpenQuestions])
+	doc.NextSteps = handoffList(bodies[HandoffNextSteps])
+	for _, item := range handoffList(bodies[HandoffReferences]) {
+		doc.References = append(doc.References, ParseHandoffRef(item))
+	}
+	doc.Notes = handoffProse(notes)
+	return doc, true
+}
+
+// ParseHandoffRef classifies a reference line such as "commit: abc1234 fix
+// parser", "gt-abc12" or "bead gt-abc12 - the parent epic".
+func ParseHandoffRef(s string) HandoffRef {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return HandoffRef{}
+	}
+	kind := ""
+	switch strings.ToLower(strings.TrimSuffix(fields[0], ":")) {
+	case "commit":
+		kind = "commit"
+	case "bead", "issue":
+		kind = "bead"
+	}
+	if kind != "" {
+		fields = fields[1:]
+		if len(fields) == 0 {
+			return HandoffRef{Kind: kind}
+		}
+	}
+	ref := HandoffRef{Kind: kind, ID: strings.Trim(fields[0], "`,:"), Note: strings.TrimLeft(strings.Join(fields[1:], " "), "-–— ")}
+	if ref.Kind == "" {
+		switch {
+		case handoffCommitRe.MatchString(ref.ID):
+			ref.Kind = "commit"
+		case handoffBeadRe.MatchString(ref.ID):
+			ref.Kind = "bead"
+		default:
+			// Not an ID: keep the whole line as the note.
+			ref.ID, ref.Note = "", s
+		}
+	}
+	return ref
+}
+
+func (r HandoffRef) String() string {
+	var parts []string
+	if r.Kind != "" {
+		parts = append(parts, r.Kind+":")
+	}
+	if r.ID != "" {
+		parts = append(parts, r.ID)
+	}
+	if r.Note != "" {
+		if r.ID != "" {
+			parts = append(parts, "-")
+		}
+		parts = append(parts, r.Note)
+	}
+	return strings.Join(parts, " ")
+}
+
+// EmptySections returns the names of sections with no content, in document
+// order. Successors rely on every section; "None" is a fine answer.
+func (d *HandoffDoc) EmptySections() []string {
+	var empty []string
+	for _, name := range HandoffSections {
+		if d.sectionEmpty(name) {
+			empty = append(empty, name)
+		}
+	}
+	return empty
+}
+
+func (d *HandoffDoc) sectionEmpty(name string) bool {
+	switch name {
+	case HandoffGoal:
+		return d.Goal == ""
+	case HandoffState:
+		return d.State == ""
+	case HandoffDecisions:
+		return len(d.Decisions) == 0
+	case HandoffOpenQuestions:
+		return len(d.OpenQuestions) == 0
+	case HandoffNextSteps:
+		return len(d.NextSteps) == 0
+	case HandoffReferences:
+		return len(d.References) == 0
+	}
+	return false
+}
+
+// Markdown renders the document in its stored form. Empty sections are
+// omitted; Notes are appended after the sections.
+func (d *HandoffDoc) Markdown() string {
+	return d.render(false)
+}
+
+// HandoffTemplate renders d as a fill-in template: every section heading is
+// present, and empty sections carry a hint comment that ParseHandoffDoc
+// ignores. Pass an empty doc for a blank template.
+func HandoffTemplate(d *HandoffDoc) string {
+	if d == nil {
+		d = &HandoffDoc{}
+	}
+	return d.render(true)
+}
+
+var handoffHints = map[string]string{
+	HandoffGoal:          "What this session was trying to achieve, in one or two sentences.",
+	HandoffState:         "Where things stand: what works, what is half-done, what is broken.",
+	HandoffDecisions:     "- Choices made and why, including approaches tried and abandoned.",
+	HandoffOpenQuestions: "- Anything unresolved the next session must decide or ask about. \"None\" is fine.",
+	HandoffNextSteps:     "1. The concrete next actions, in order.",
+	HandoffReferences:    "- commit: <sha> <what it did>\n- bead: <id> <why it matters>",
+}
+
+func (d *HandoffDoc) render(template bool) string {
+	var b strings.Builder
+	for _, name := range HandoffSections {
+		empty := d.sectionEmpty(name)
+		if empty && !template {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("## " + name + "\n")
+		if empty {
+			b.WriteString("<!-- " + handoffHints[name] + " -->\n")
+			continue
+		}
+		switch name {
+		case HandoffGoal:
+			b.WriteString(d.Goal + "\n")
+		case HandoffState:
+			b.WriteString(d.State + "\n")
+		case HandoffDecisions:
+			writeHandoffList(&b, d.Decisions, false)
+		case HandoffOpenQuestions:
+			writeHandoffList(&b, d.OpenQuestions, false)
+		case HandoffNextSteps:
+			writeHandoffList(&b, d.NextSteps, true)
+		case HandoffReferences:
+			refs := make([]string, len(d.References))
+			for i, r := range d.References {
+				refs[i] = r.String()
+			}
+			writeHandoffList(&b, refs, false)
+		}
+	}
+	if d.Notes != "" {
+		b.WriteString("\n---\n" + d.Notes + "\n")
+	}
+	return b.String()
+}
+
+func writeHandoffList(b *strings.Builder, items []string, numbered bool) {
+	for i, item := range items {
+		if numbered {
+			fmt.Fprintf(b, "%d. %s\n", i+1, item)
+		} else {
+			b.WriteString("- " + item + "\n")
+		}
+	}
+}
+
+// handoffProse joins section lines, trimming surrounding blank lines.
+func handoffProse(lines []string) string {
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// handoffList splits section lines into items. List markers start a new
+// item; indented or unmarked lines continue the previous one.
+func handoffList(lines []string) []string {
+	var items []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if loc := handoffListRe.FindStringIndex(trimmed); loc != nil {
+			items = append(items, trimmed[loc[1]:])
+			continue
+		}
+		if len(items) > 0 && line != trimmed {
+			items[len(items)-1] += " " + trimmed
+			continue
+		}
+		items = append(items, trimmed)
+	}
+	return items
+}
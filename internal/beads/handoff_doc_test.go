@@ -0,0 +1,114 @@
+package beads
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHandoffDoc(t *testing.T) {
+	text := `Picked up from the witness escalation.
+
+## Goal
+Make the refinery retry flaky gates.
+
+## Current State
+Retry loop works locally;
+integration test still red.
+
+## Decisions
+- Retry at most twice, since gates are slow
+- Dropped the per-gate config idea
+  (too much surface)
+
+## Open questions
+* Should retries count toward the MR score?
+
+## Next Steps
+1. Fix the integration test
+2) Push and submit to the merge queue
+
+## References
+- commit: 1a2b3c4d fix retry counter
+- gt-abc12 - parent epic
+- see the design doc
+
+---
+## Git State
+Branch: polecat/toast`
+
+	doc, ok := ParseHandoffDoc(text)
+	if !ok {
+		t.Fatal("ParseHandoffDoc: not recognized as structured")
+	}
+	if doc.Goal != "Make the refinery retry flaky gates." {
+		t.Errorf("Goal = %q", doc.Goal)
+	}
+	if doc.State != "Retry loop works locally;\nintegration test still red." {
+		t.Errorf("State = %q", doc.State)
+	}
+	wantDecisions := []string{"Retry at most twice, since gates are slow", "Dropped the per-gate config idea (too much surface)"}
+	if !reflect.DeepEqual(doc.Decisions, wantDecisions) {
+		t.Errorf("Decisions = %q, want %q", doc.Decisions, wantDecisions)
+	}
+	if len(doc.OpenQuestions) != 1 {
+		t.Errorf("OpenQuestions = %q", doc.OpenQuestions)
+	}
+	wantSteps := []string{"Fix the integration test", "Push and submit to the merge queue"}
+	if !reflect.DeepEqual(doc.NextSteps, wantSteps) {
+		t.Errorf("NextSteps = %q, want %q", doc.NextSteps, wantSteps)
+	}
+	wantRefs := []HandoffRef{
+		{Kind: "commit", ID: "1a2b3c4d", Note: "fix retry counter"},
+		{Kind: "bead", ID: "gt-abc12", Note: "parent epic"},
+		{Note: "see the design doc"},
+	}
+	if !reflect.DeepEqual(doc.References, wantRefs) {
+		t.Errorf("References = %+v, want %+v", doc.References, wantRefs)
+	}
+	if !strings.Contains(doc.Notes, "Picked up from the witness escalation.") || !strings.Contains(doc.Notes, "Branch: polecat/toast") {
+		t.Errorf("Notes lost free-form text: %q", doc.Notes)
+	}
+	if len(doc.EmptySections()) != 0 {
+		t.Errorf("EmptySections = %v, want none", doc.EmptySections())
+	}
+}
+
+func TestParseHandoffDoc_FreeForm(t *testing.T) {
+	for _, text := range []string{"", "Context cycling. Check bd ready.", "## Hooked Work\ngt-abc"} {
+		if _, ok := ParseHandoffDoc(text); ok {
+			t.Errorf("ParseHandoffDoc(%q) recognized as structured", text)
+		}
+	}
+}
+
+func TestHandoffTemplate_RoundTrip(t *testing.T) {
+	tmpl := HandoffTemplate(&HandoffDoc{
+		Goal:       "Ship gt-abc12",
+		References: []HandoffRef{{Kind: "bead", ID: "gt-abc12"}},
+	})
+	for _, name := range HandoffSections {
+		if !strings.Contains(tmpl, "## "+name+"\n") {
+			t.Errorf("template missing section %q:\n%s", name, tmpl)
+		}
+	}
+
+	// An unfilled template parses, with the hint comments ignored.
+	doc, ok := ParseHandoffDoc(tmpl)
+	if !ok {
+		t.Fatal("template not recognized as structured")
+	}
+	want := []string{HandoffState, HandoffDecisions, HandoffOpenQuestions, HandoffNextSteps}
+	if got := doc.EmptySections(); !reflect.DeepEqual(got, want) {
+		t.Errorf("EmptySections = %v, want %v", got, want)
+	}
+
+	// Markdown round-trips through the parser.
+	doc.State = "Half done"
+	doc.NextSteps = []string{"Finish", "Submit"}
+	doc.Notes = "Collected state"
+	again, ok := ParseHandoffDoc(doc.Markdown())
+	if !ok || !reflect.DeepEqual(again, doc) {
+		t.Errorf("round trip = %+v, want %+v", again, doc)
+	}
+}
@@ -41,6 +41,12 @@ type Checkpoint struct {
 	// HookedBead is the bead ID on the agent's hook.
 	HookedBead string `json:"hooked_bead,omitempty"`
 
+	// SnapshotRef is the git ref holding a snapshot of the uncommitted work.
+	SnapshotRef string `json:"snapshot_ref,omitempty"`
+
+	// SnapshotCommit is the snapshot commit SHA at checkpoint time.
+	SnapshotCommit string `json:"snapshot_commit,omitempty"`
+
 	// Timestamp is when the checkpoint was written.
 	Timestamp time.Time `json:"timestamp"`
 
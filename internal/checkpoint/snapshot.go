@@ -0,0 +1,386 @@
+package checkpoint
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RefPrefix is where work snapshots are stored. Refs outside refs/heads and
+// refs/tags are not pushed by default, and all worktrees of a repository
+// share them, so a snapshot outlives the worktree it was taken from.
+const RefPrefix = "refs/gastown/checkpoints/"
+
+// snapshotIdentity is used for snapshot commits so they work in clones
+// without user.name/user.email configured.
+var snapshotIdentity = []string{
+	"GIT_AUTHOR_NAME=Gas Town", "GIT_AUTHOR_EMAIL=checkpoint@gastown.local",
+	"GIT_COMMITTER_NAME=Gas Town", "GIT_COMMITTER_EMAIL=checkpoint@gastown.local",
+}
+
+// Snapshot is a commit holding a worktree's uncommitted content. Its parent
+// is the HEAD the work was based on, so the snapshot also keeps unpushed
+// commits reachable after the branch is deleted.
+type Snapshot struct {
+	Key    string    // agent identity, e.g. "gastown/polecats/Toast"
+	Ref    string    // full ref name
+	Commit string    // snapshot commit SHA
+	Parent string    // HEAD when the snapshot was taken
+	Branch string    // branch checked out when the snapshot was taken
+	Bead   string    // hooked bead, if known
+	Time   time.Time // when the snapshot was taken
+	Files  []string  // paths changed relative to Parent
+}
+
+// SnapshotRef returns the ref a snapshot for key is stored under.
+func SnapshotRef(key string) string {
+	return RefPrefix + key
+}
+
+// SaveSnapshot records the working tree of dir (tracked and untracked files,
+// honoring .gitignore) as a commit on SnapshotRef(key). The real index and
+// working tree are not touched. It returns nil when the working tree matches
+// HEAD. changed is false when the existing snapshot already holds the same
+// content.
+func SaveSnapshot(dir, key, bead string) (snap *Snapshot, changed bool, err error) {
+	head, err := gitOutput(dir, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, false, fmt.Errorf("reading HEAD: %w", err)
+	}
+	branch, _ := gitOutput(dir, nil, "rev-parse", "--abbrev-ref", "HEAD")
+
+	tree, err := worktreeTree(dir)
+	if err != nil {
+		return nil, false, err
+	}
+	headTree, err := gitOutput(dir, nil, "rev-parse", "HEAD^{tree}")
+	if err != nil {
+		return nil, false, fmt.Errorf("reading HEAD tree: %w", err)
+	}
+	if tree == headTree {
+		return nil, false, nil
+	}
+
+	ref := SnapshotRef(key)
+	if prev, err := LoadSnapshot(dir, key); err == nil && prev != nil && prev.Parent == head {
+		if prevTree, err := gitOutput(dir, nil, "rev-parse", prev.Commit+"^{tree}"); err == nil && prevTree == tree {
+			return prev, false, nil
+		}
+	}
+
+	now := time.Now()
+	msg := fmt.Sprintf("checkpoint: %s\n\nBranch: %s\n", key, branch)
+	if bead != "" {
+		msg += fmt.Sprintf("Bead: %s\n", bead)
+	}
+	commit, err := gitOutput(dir, snapshotIdentity, "commit-tree", tree, "-p", head, "-m", msg)
+	if err != nil {
+		return nil, false, fmt.Errorf("creating snapshot commit: %w", err)
+	}
+	if _, err := gitOutput(dir, nil, "update-ref", "--create-reflog", "-m", "checkpoint", ref, commit); err != nil {
+		return nil, false, fmt.Errorf("updating %s: %w", ref, err)
+	}
+
+	files, _ := changedFiles(dir, head, commit)
+	return &Snapshot{
+		Key:    key,
+		Ref:    ref,
+		Commit: commit,
+		Parent: head,
+		Branch: branch,
+		Bead:   bead,
+		Time:   now,
+		Files:  files,
+	}, true, nil
+}
+
+// worktreeTree writes the working tree to a tree object using a scratch
+// copy of the index, so staged state and concurrent git commands in the
+// worktree are unaffected.
+func worktreeTree(dir string) (string, error) {
+	indexPath, err := gitOutput(dir, nil, "rev-parse", "--path-format=absolute", "--git-path", "index")
+	if err != nil {
+		return "", fmt.Errorf("locating index: %w", err)
+	}
+	tmp, err := os.CreateTemp("", "gt-checkpoint-index-*")
+	if err != nil {
+		return "", err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	// Copying the index keeps its stat cache, so only changed files are hashed.
+	if src, err := os.Open(indexPath); err == nil { //nolint:gosec // G304: path from git rev-parse
+		_, copyErr := io.Copy(tmp, src)
+		src.Close()
+		if copyErr != nil {
+			tmp.Close()
+			return "", copyErr
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	env := []string{"GIT_INDEX_FILE=" + tmpPath}
+	if fi, err := os.Stat(tmpPath); err == nil && fi.Size() == 0 {
+		if _, err := gitOutput(dir, env, "read-tree", "HEAD"); err != nil {
+			return "", fmt.Errorf("reading HEAD into scratch index: %w", err)
+		}
+	}
+	if _, err := gitOutput(dir, env, "add", "-A", "--", ".", ":(exclude)"+Filename); err != nil {
+		return "", fmt.Errorf("staging worktree: %w", err)
+	}
+	tree, err := gitOutput(dir, env, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("writing tree: %w", err)
+	}
+	return tree, nil
+}
+
+// LoadSnapshot reads the snapshot stored for key in dir's repository.
+// Returns nil, nil if there is none.
+func LoadSnapshot(dir, key string) (*Snapshot, error) {
+	ref := SnapshotRef(key)
+	commit, err := gitOutput(dir, nil, "rev-parse", "--verify", "-q", ref+"^{commit}")
+	if err != nil || commit == "" {
+		return nil, nil
+	}
+	out, err := gitOutput(dir, nil, "log", "-1", "--format=%P%n%ct%n%B", commit)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ref, err)
+	}
+	lines := strings.Split(out, "\n")
+	snap := &Snapshot{Key: key, Ref: ref, Commit: commit}
+	if len(lines) > 0 {
+		snap.Parent = strings.Fields(lines[0] + " ")[0]
+	}
+	if len(lines) > 1 {
+		if secs, err := strconv.ParseInt(strings.TrimSpace(lines[1]), 10, 64); err == nil {
+			snap.Time = time.Unix(secs, 0)
+		}
+	}
+	for _, line := range lines[2:] {
+		if v, ok := strings.CutPrefix(line, "Branch: "); ok {
+			snap.Branch = strings.TrimSpace(v)
+		} else if v, ok := strings.CutPrefix(line, "Bead: "); ok {
+			snap.Bead = strings.TrimSpace(v)
+		}
+	}
+	if snap.Parent != "" {
+		snap.Files, _ = changedFiles(dir, snap.Parent, commit)
+	}
+	return snap, nil
+}
+
+// ListSnapshots returns every snapshot in dir's repository.
+func ListSnapshots(dir string) ([]*Snapshot, error) {
+	out, err := gitOutput(dir, nil, "for-each-ref", "--format=%(refname)", RefPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing snapshots: %w", err)
+	}
+	var snaps []*Snapshot
+	for _, ref := range strings.Split(out, "\n") {
+		key, ok := strings.CutPrefix(strings.TrimSpace(ref), RefPrefix)
+		if !ok || key == "" {
+			continue
+		}
+		snap, err := LoadSnapshot(dir, key)
+		if err != nil || snap == nil {
+			continue
+		}
+		snaps = append(snaps, snap)
+	}
+	return snaps, nil
+}
+
+// DeleteSnapshot removes the snapshot for key. Missing snapshots are not
+// an error.
+func DeleteSnapshot(dir, key string) error {
+	ref := SnapshotRef(key)
+	if _, err := gitOutput(dir, nil, "rev-parse", "--verify", "-q", ref); err != nil {
+		return nil
+	}
+	if _, err := gitOutput(dir, nil, "update-ref", "-d", ref); err != nil {
+		return fmt.Errorf("deleting %s: %w", ref, err)
+	}
+	return nil
+}
+
+// Pending reports whether dir looks like a worktree the snapshot should be
+// restored into: HEAD is behind the snapshot's parent, or HEAD is the parent
+// and the snapshot's changes are missing from the working tree (each changed
+// path still matches the parent rather than having moved on).
+func (s *Snapshot) Pending(dir string) bool {
+	head, err := gitOutput(dir, nil, "rev-parse", "HEAD")
+	if err != nil || !isAncestor(dir, head, s.Parent) {
+		return false
+	}
+	if head != s.Parent {
+		return true
+	}
+	if len(s.Files) == 0 {
+		return false
+	}
+	tree, err := worktreeTree(dir)
+	if err != nil {
+		return false
+	}
+	missing, err := gitOutput(dir, nil, append([]string{"diff", "--name-only", tree, s.Commit, "--"}, s.Files...)...)
+	if err != nil || missing == "" {
+		return false
+	}
+	for _, f := range strings.Split(missing, "\n") {
+		if _, err := gitOutput(dir, nil, "diff", "--quiet", tree, s.Parent, "--", f); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// RestoreResult describes what RestoreSnapshot did.
+type RestoreResult struct {
+	// FastForwarded is true when HEAD was behind the snapshot's parent and
+	// was moved forward to restore unpushed commits.
+	FastForwarded bool
+	// MissingCommits counts commits in the snapshot's history that could not
+	// be restored because HEAD has diverged from them.
+	MissingCommits int
+	// Files lists the uncommitted paths reapplied.
+	Files []string
+}
+
+// RestoreSnapshot reapplies snap into the worktree at dir. If HEAD is behind
+// the snapshot's parent (a fresh or repaired worktree), it is fast-forwarded
+// first; then the uncommitted content is applied as unstaged changes.
+// Uncommitted changes in the way are refused unless they already match
+// the snapshot, as the .gitignore patterns a new worktree is set up with do.
+func RestoreSnapshot(dir string, snap *Snapshot) (*RestoreResult, error) {
+	dirty, err := gitOutput(dir, nil, "diff", "--name-only", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("checking worktree: %w", err)
+	}
+	var tracked []string
+	if dirty != "" {
+		tracked = strings.Split(dirty, "\n")
+	}
+	var untracked []string
+	for _, f := range snap.Files {
+		if _, err := gitOutput(dir, nil, "ls-files", "--error-unmatch", "--", f); err == nil {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(dir, f)); err == nil {
+			untracked = append(untracked, f)
+		}
+	}
+	for _, f := range append(append([]string{}, tracked...), untracked...) {
+		if !matchesCommit(dir, snap.Commit, f) {
+			return nil, fmt.Errorf("worktree has uncommitted changes to %s; commit or stash them first", f)
+		}
+	}
+	// Matching changes come back with the snapshot.
+	if len(tracked) > 0 {
+		if _, err := gitOutput(dir, nil, append([]string{"checkout", "HEAD", "--"}, tracked...)...); err != nil {
+			return nil, fmt.Errorf("resetting matching changes: %w", err)
+		}
+	}
+	for _, f := range untracked {
+		if err := os.Remove(filepath.Join(dir, f)); err != nil {
+			return nil, fmt.Errorf("removing %s: %w", f, err)
+		}
+	}
+
+	result := &RestoreResult{Files: snap.Files}
+	head, err := gitOutput(dir, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("reading HEAD: %w", err)
+	}
+	if head != snap.Parent && !isAncestor(dir, snap.Parent, head) {
+		if isAncestor(dir, head, snap.Parent) {
+			if _, err := gitOutput(dir, nil, "merge", "--ff-only", "-q", snap.Parent); err != nil {
+				return nil, fmt.Errorf("fast-forwarding to %s: %w", short(snap.Parent), err)
+			}
+			result.FastForwarded = true
+		} else {
+			count, _ := gitOutput(dir, nil, "rev-list", "--count", head+".."+snap.Parent)
+			result.MissingCommits, _ = strconv.Atoi(count)
+		}
+	}
+
+	patch, err := gitOutput(dir, nil, "diff", "--binary", snap.Parent, snap.Commit)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot diff: %w", err)
+	}
+	if patch == "" {
+		return result, nil
+	}
+	cmd := exec.Command("git", "apply", "--3way", "--whitespace=nowarn")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(patch + "\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return result, fmt.Errorf("applying snapshot (resolve conflicts by hand): %s", strings.TrimSpace(stderr.String()))
+	}
+	// --3way stages what it applies; leave the work unstaged as it was.
+	if _, err := gitOutput(dir, nil, "reset", "-q"); err != nil {
+		return result, fmt.Errorf("unstaging restored changes: %w", err)
+	}
+	return result, nil
+}
+
+func changedFiles(dir, from, to string) ([]string, error) {
+	out, err := gitOutput(dir, nil, "diff", "--name-only", from, to)
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// matchesCommit reports whether path in the worktree has the same content
+// as in commit; a path absent from both matches.
+func matchesCommit(dir, commit, path string) bool {
+	want, wantErr := gitOutput(dir, nil, "rev-parse", "-q", "--verify", commit+":"+path)
+	got, gotErr := gitOutput(dir, nil, "hash-object", "--", path)
+	if wantErr != nil || gotErr != nil {
+		return wantErr != nil && gotErr != nil
+	}
+	return want == got
+}
+
+func isAncestor(dir, ancestor, commit string) bool {
+	_, err := gitOutput(dir, nil, "merge-base", "--is-ancestor", ancestor, commit)
+	return err == nil
+}
+
+func short(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
+
+// gitOutput runs git in dir with extra environment and returns trimmed stdout.
+func gitOutput(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
@@ -0,0 +1,174 @@
+package checkpoint
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func initSnapshotRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		runGit(t, dir, args...)
+	}
+	writeFile(t, dir, "a.txt", "one\n")
+	runGit(t, dir, "add", "a.txt")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	dir := initSnapshotRepo(t)
+
+	snap, changed, err := SaveSnapshot(dir, "gastown/polecats/Toast", "")
+	if err != nil || snap != nil || changed {
+		t.Fatalf("clean tree: snap=%v changed=%v err=%v", snap, changed, err)
+	}
+
+	writeFile(t, dir, "a.txt", "two\n")
+	writeFile(t, dir, "new.txt", "untracked\n")
+	writeFile(t, dir, Filename, "{}")
+	runGit(t, dir, "add", "a.txt") // staged state must survive
+
+	snap, changed, err = SaveSnapshot(dir, "gastown/polecats/Toast", "gt-abc")
+	if err != nil || snap == nil || !changed {
+		t.Fatalf("SaveSnapshot: snap=%v changed=%v err=%v", snap, changed, err)
+	}
+	if got := strings.Join(snap.Files, ","); got != "a.txt,new.txt" {
+		t.Errorf("Files = %q, want a.txt,new.txt", got)
+	}
+	if status := runGit(t, dir, "status", "--porcelain"); !strings.Contains(status, "M  a.txt") {
+		t.Errorf("index was modified: %q", status)
+	}
+
+	loaded, err := LoadSnapshot(dir, "gastown/polecats/Toast")
+	if err != nil || loaded == nil {
+		t.Fatalf("LoadSnapshot: %v, %v", loaded, err)
+	}
+	if loaded.Commit != snap.Commit || loaded.Parent != snap.Parent || loaded.Bead != "gt-abc" || loaded.Branch != "main" {
+		t.Errorf("loaded = %+v, saved = %+v", loaded, snap)
+	}
+
+	if _, changed, _ := SaveSnapshot(dir, "gastown/polecats/Toast", "gt-abc"); changed {
+		t.Error("unchanged tree should not create a new snapshot")
+	}
+
+	snaps, err := ListSnapshots(dir)
+	if err != nil || len(snaps) != 1 || snaps[0].Key != "gastown/polecats/Toast" {
+		t.Errorf("ListSnapshots = %+v, %v", snaps, err)
+	}
+	if err := DeleteSnapshot(dir, "gastown/polecats/Toast"); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := LoadSnapshot(dir, "gastown/polecats/Toast"); s != nil {
+		t.Error("snapshot still present after delete")
+	}
+	if err := DeleteSnapshot(dir, "gastown/polecats/Toast"); err != nil {
+		t.Errorf("deleting missing snapshot: %v", err)
+	}
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	dir := initSnapshotRepo(t)
+	base := runGit(t, dir, "rev-parse", "HEAD")
+
+	// Unpushed commit plus uncommitted work, as a crashed polecat leaves it.
+	runGit(t, dir, "checkout", "-q", "-b", "polecat/toast")
+	writeFile(t, dir, "b.txt", "committed\n")
+	runGit(t, dir, "add", "b.txt")
+	runGit(t, dir, "commit", "-q", "-m", "wip")
+	writeFile(t, dir, "a.txt", "edited\n")
+	writeFile(t, dir, "c.txt", "untracked\n")
+	snap, _, err := SaveSnapshot(dir, "gastown/polecats/Toast", "")
+	if err != nil || snap == nil {
+		t.Fatalf("SaveSnapshot: %v", err)
+	}
+
+	// Repair: fresh branch from the base, old branch gone.
+	runGit(t, dir, "checkout", "-q", "-f", "-b", "polecat/toast-2", base)
+	runGit(t, dir, "clean", "-fdq")
+	runGit(t, dir, "branch", "-D", "polecat/toast")
+
+	if !snap.Pending(dir) {
+		t.Fatal("snapshot should be pending in the fresh worktree")
+	}
+	res, err := RestoreSnapshot(dir, snap)
+	if err != nil {
+		t.Fatalf("RestoreSnapshot: %v", err)
+	}
+	if !res.FastForwarded || res.MissingCommits != 0 {
+		t.Errorf("result = %+v", res)
+	}
+	if readFile(t, dir, "a.txt") != "edited\n" || readFile(t, dir, "b.txt") != "committed\n" || readFile(t, dir, "c.txt") != "untracked\n" {
+		t.Error("worktree content not restored")
+	}
+	if staged := runGit(t, dir, "diff", "--cached", "--name-only"); staged != "" {
+		t.Errorf("restored changes should be unstaged, got %q", staged)
+	}
+	if snap.Pending(dir) {
+		t.Error("snapshot should not be pending after restore")
+	}
+}
+
+func TestRestoreSnapshot_DirtyWorktree(t *testing.T) {
+	dir := initSnapshotRepo(t)
+	writeFile(t, dir, "a.txt", "snapshot\n")
+	writeFile(t, dir, "b.txt", "new\n")
+	snap, _, err := SaveSnapshot(dir, "gastown/polecats/Toast", "")
+	if err != nil || snap == nil {
+		t.Fatalf("SaveSnapshot: %v", err)
+	}
+
+	writeFile(t, dir, "a.txt", "something else\n")
+	if _, err := RestoreSnapshot(dir, snap); err == nil {
+		t.Fatal("restore over conflicting changes should fail")
+	}
+
+	// Work that moved on from the snapshot is not "pending".
+	if snap.Pending(dir) {
+		t.Error("snapshot should not be pending when the worktree has newer changes")
+	}
+
+	// Changes that already match the snapshot are fine.
+	writeFile(t, dir, "a.txt", "snapshot\n")
+	if _, err := RestoreSnapshot(dir, snap); err != nil {
+		t.Fatalf("restore over matching changes: %v", err)
+	}
+	if readFile(t, dir, "a.txt") != "snapshot\n" {
+		t.Error("a.txt not restored")
+	}
+}
@@ -0,0 +1,729 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/approval"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/procstat"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Approve command flags
+var (
+	approveReason string
+
+	approveListAll  bool
+	approveListJSON bool
+	approveShowJSON bool
+
+	approveRequestStep    string
+	approveRequestFormula string
+	approveRequestTitle   string
+	approveRequestJSON    bool
+
+	approveWaitPoll time.Duration
+
+	approveSecretRotate bool
+)
+
+var approveCmd = &cobra.Command{
+	Use:     "approve [approval-id]",
+	GroupID: GroupWork,
+	Short:   "Approve or reject molecule approval gates",
+	Long: `Approve or reject human approval gates in molecules.
+
+A formula step with gate = "approval" parks the molecule until a human
+decides. The agent files a request, the approvers (default: overseer) are
+notified by mail, and the agent waits until the request is approved,
+rejected, or times out:
+
+  [[steps]]
+  id = "release-signoff"
+  title = "Sign off on release"
+  gate = "approval"
+  approvers = ["overseer"]
+  timeout = "24h"
+  on_timeout = "reject"     # or "approve", "escalate"
+
+Decisions can be made here, from the web dashboard, or by a webhook signed
+with the town secret (see 'gt approve webhook-secret'). Only the step's
+approvers may decide a request, never the agent that requested it. Here and
+on the dashboard the decider is the overseer; commands run from inside an
+agent session (GT_ROLE or BD_ACTOR set on the command or any parent process)
+cannot decide at all. Each decision is recorded on the bead as an
+approval:<status> label and a comment.
+
+The gate is enforced when the work completes: gt done and gt mol step done
+refuse to finish past an approval gate step without an approved request.
+The daemon applies timeouts on its heartbeat.
+
+Examples:
+  gt approve list                           # Pending approvals
+  gt approve ap-1a2b3c4d                    # Approve
+  gt approve ap-1a2b3c4d --reason "LGTM"
+  gt approve reject ap-1a2b3c4d --reason "Needs changelog"
+  gt approve show ap-1a2b3c4d`,
+	Args:         cobra.MaximumNArgs(1),
+	RunE:         runApprove,
+	SilenceUsage: true,
+}
+
+var approveRejectCmd = &cobra.Command{
+	Use:          "reject <approval-id>",
+	Short:        "Reject an approval request",
+	Args:         cobra.ExactArgs(1),
+	RunE:         runApproveReject,
+	SilenceUsage: true,
+}
+
+var approveListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List approval requests",
+	Long: `List approval requests. Shows pending requests unless --all is given.
+
+Listing also applies timeouts to requests past their deadline.`,
+	Args: cobra.NoArgs,
+	RunE: runApproveList,
+}
+
+var approveShowCmd = &cobra.Command{
+	Use:   "show <approval-id>",
+	Short: "Show an approval request",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runApproveShow,
+}
+
+var approveRequestCmd = &cobra.Command{
+	Use:   "request <bead-id>",
+	Short: "Request approval for a gate step (used by agents)",
+	Long: `Request human approval for a gate step and notify the approvers.
+
+Approvers, timeout and on_timeout come from the formula step, so the agent
+asking cannot choose who signs off. Prints the approval ID; follow with
+'gt approve wait'.
+
+Examples:
+  gt approve request gt-wisp-abc --step release-signoff --formula mol-release`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runApproveRequest,
+	SilenceUsage: true,
+}
+
+var approveWaitCmd = &cobra.Command{
+	Use:   "wait <approval-id>",
+	Short: "Block until an approval request is decided (used by agents)",
+	Long: `Block until an approval request is approved, rejected, or times out.
+
+Exits 0 when approved and non-zero when rejected or expired, so agents can
+branch on the result. Timeouts are applied while waiting.`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runApproveWait,
+	SilenceUsage: true,
+}
+
+var approveSweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Short: "Apply timeouts to overdue approval requests",
+	Long: `Apply on_timeout to approval requests past their deadline: reject,
+approve, or escalate through the high-severity escalation route.
+
+The daemon runs this on its heartbeat when a request is due.`,
+	Args: cobra.NoArgs,
+	RunE: runApproveSweep,
+}
+
+var approveWebhookSecretCmd = &cobra.Command{
+	Use:   "webhook-secret",
+	Short: "Show or create the approval webhook secret",
+	Long: `Show or create the secret used to sign approval webhooks.
+
+External systems approve or reject by POSTing JSON to the dashboard at
+/api/approvals/webhook:
+
+  {"id": "ap-1a2b3c4d", "decision": "approve", "by": "ci", "reason": "...",
+   "timestamp": <unix seconds>}
+
+with header ` + approval.SignatureHeader + `: sha256=<hex HMAC-SHA256 of the body>.
+The timestamp must be within 5 minutes of the server clock. "by" is required
+and must be one of the request's approvers (e.g. approvers = ["overseer", "ci"]).
+
+The secret is read from ` + approval.SecretEnv + ` if set, otherwise from
+.runtime/approval-webhook.secret, which this command creates.`,
+	Args: cobra.NoArgs,
+	RunE: runApproveWebhookSecret,
+}
+
+func init() {
+	approveCmd.Flags().StringVarP(&approveReason, "reason", "r", "", "Reason for the decision")
+
+	approveRejectCmd.Flags().StringVarP(&approveReason, "reason", "r", "", "Reason for rejecting")
+
+	approveListCmd.Flags().BoolVar(&approveListAll, "all", false, "Include decided requests")
+	approveListCmd.Flags().BoolVar(&approveListJSON, "json", false, "Output as JSON")
+
+	approveShowCmd.Flags().BoolVar(&approveShowJSON, "json", false, "Output as JSON")
+
+	approveRequestCmd.Flags().StringVar(&approveRequestStep, "step", "", "Gate step ID (required)")
+	approveRequestCmd.Flags().StringVar(&approveRequestFormula, "formula", "", "Formula the step belongs to (required)")
+	approveRequestCmd.Flags().StringVar(&approveRequestTitle, "title", "", "Title shown to approvers (default: step title)")
+	approveRequestCmd.Flags().BoolVar(&approveRequestJSON, "json", false, "Output as JSON")
+	_ = approveRequestCmd.MarkFlagRequired("step")
+	_ = approveRequestCmd.MarkFlagRequired("formula")
+
+	approveWaitCmd.Flags().DurationVar(&approveWaitPoll, "poll", 15*time.Second, "How often to check for a decision")
+
+	approveWebhookSecretCmd.Flags().BoolVar(&approveSecretRotate, "rotate", false, "Replace the existing secret")
+
+	approveCmd.AddCommand(approveRejectCmd)
+	approveCmd.AddCommand(approveListCmd)
+	approveCmd.AddCommand(approveShowCmd)
+	approveCmd.AddCommand(approveRequestCmd)
+	approveCmd.AddCommand(approveWaitCmd)
+	approveCmd.AddCommand(approveSweepCmd)
+	approveCmd.AddCommand(approveWebhookSecretCmd)
+	rootCmd.AddCommand(approveCmd)
+}
+
+func runApprove(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cmd.Help()
+	}
+	return decideApproval(args[0], true)
+}
+
+func runApproveReject(cmd *cobra.Command, args []string) error {
+	return decideApproval(args[0], false)
+}
+
+// agentSessionEnv are set in every agent session (see config.AgentEnv).
+var agentSessionEnv = []string{"GT_ROLE", "BD_ACTOR"}
+
+// agentSessionMarker returns why the current command looks like it runs
+// inside an agent session, or "" when it doesn't. Unsetting the variables
+// is not enough: the agent process that spawned the command still has them.
+func agentSessionMarker() string {
+	for _, key := range agentSessionEnv {
+		if v := os.Getenv(key); v != "" {
+			return fmt.Sprintf("%s=%s", key, v)
+		}
+	}
+	if pid, key := procstat.AncestorEnv(agentSessionEnv...); pid != 0 {
+		return fmt.Sprintf("parent process %d has %s set", pid, key)
+	}
+	return ""
+}
+
+func decideApproval(id string, approve bool) error {
+	// Gates exist so a human signs off; an agent may not approve anything,
+	// least of all the request it is blocked on.
+	if marker := agentSessionMarker(); marker != "" {
+		return fmt.Errorf("agents cannot decide approvals (%s); ask an approver", marker)
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	// Like the dashboard, the CLI decides as the overseer. Other approvers
+	// (e.g. "ci") decide through the signed webhook.
+	store := approval.NewStore(townRoot)
+	sweepApprovals(townRoot, store)
+	req, err := store.Decide(id, approval.Decision{
+		Approve: approve,
+		By:      "overseer",
+		Reason:  approveReason,
+		Via:     approval.ViaCLI,
+	})
+	if err != nil {
+		return err
+	}
+	recordApprovalDecision(townRoot, req)
+
+	fmt.Printf("%s %s %s (%s step %s)\n", approvalStatusIcon(req.Status), req.ID, req.Status, req.Bead, req.Step)
+	return nil
+}
+
+func runApproveList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	store := approval.NewStore(townRoot)
+	sweepApprovals(townRoot, store)
+
+	all, err := store.List()
+	if err != nil {
+		return fmt.Errorf("listing approvals: %w", err)
+	}
+	reqs := []*approval.Request{}
+	for _, req := range all {
+		if approveListAll || req.Pending() {
+			reqs = append(reqs, req)
+		}
+	}
+
+	if approveListJSON {
+		out, _ := json.MarshalIndent(reqs, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+	if len(reqs) == 0 {
+		fmt.Printf("%s No pending approvals\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tBEAD\tSTEP\tREQUESTED BY\tAGE\tEXPIRES")
+	for _, req := range reqs {
+		expires := "-"
+		if req.ExpiresAt != nil && req.Pending() {
+			expires = "in " + formatDuration(time.Until(*req.ExpiresAt))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			req.ID, req.Status, req.Bead, req.Step, req.RequestedBy,
+			formatDuration(time.Since(req.CreatedAt)), expires)
+	}
+	return w.Flush()
+}
+
+func runApproveShow(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	req, err := approval.NewStore(townRoot).Get(args[0])
+	if err != nil {
+		return err
+	}
+	if approveShowJSON {
+		out, _ := json.MarshalIndent(req, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Printf("%s %s\n", approvalStatusIcon(req.Status), style.Bold.Render(req.ID))
+	if req.Title != "" {
+		fmt.Printf("  Title:      %s\n", req.Title)
+	}
+	fmt.Printf("  Status:     %s\n", req.Status)
+	fmt.Printf("  Bead:       %s\n", req.Bead)
+	if req.Formula != "" {
+		fmt.Printf("  Step:       %s (%s)\n", req.Step, req.Formula)
+	} else {
+		fmt.Printf("  Step:       %s\n", req.Step)
+	}
+	fmt.Printf("  Requested:  %s by %s\n", req.CreatedAt.Local().Format("2006-01-02 15:04"), req.RequestedBy)
+	if len(req.Approvers) > 0 {
+		fmt.Printf("  Approvers:  %s\n", strings.Join(req.Approvers, ", "))
+	}
+	if req.ExpiresAt != nil {
+		onTimeout := req.OnTimeout
+		if onTimeout == "" {
+			onTimeout = approval.TimeoutReject
+		}
+		fmt.Printf("  Expires:    %s (then %s)\n", req.ExpiresAt.Local().Format("2006-01-02 15:04"), onTimeout)
+	}
+	if req.DecidedAt != nil {
+		by := req.DecidedBy
+		if by == "" {
+			by = "-"
+		}
+		fmt.Printf("  Decided:    %s by %s via %s\n", req.DecidedAt.Local().Format("2006-01-02 15:04"), by, req.Via)
+	}
+	if req.Reason != "" {
+		fmt.Printf("  Reason:     %s\n", req.Reason)
+	}
+	return nil
+}
+
+func runApproveRequest(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	// Who decides and what happens on timeout belong to the formula, not
+	// to the agent filing the request.
+	step, err := loadGateStep(approveRequestFormula, approveRequestStep)
+	if err != nil {
+		return err
+	}
+	req := &approval.Request{
+		Bead:        args[0],
+		Formula:     approveRequestFormula,
+		Step:        approveRequestStep,
+		Title:       approveRequestTitle,
+		RequestedBy: detectSender(),
+		Approvers:   step.Approvers,
+	}
+	if req.Title == "" {
+		req.Title = step.Title
+	}
+	if len(req.Approvers) == 0 {
+		req.Approvers = approval.DefaultApprovers
+	}
+	if step.Timeout != "" {
+		d, err := time.ParseDuration(step.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("step %q in %s has invalid timeout %q", step.ID, approveRequestFormula, step.Timeout)
+		}
+		expires := time.Now().UTC().Add(d)
+		req.ExpiresAt = &expires
+	}
+	switch step.OnTimeout {
+	case "", approval.TimeoutReject, approval.TimeoutApprove, approval.TimeoutEscalate:
+		req.OnTimeout = step.OnTimeout
+	default:
+		return fmt.Errorf("step %q in %s has invalid on_timeout %q: must be reject, approve, or escalate", step.ID, approveRequestFormula, step.OnTimeout)
+	}
+
+	store := approval.NewStore(townRoot)
+	if err := store.Create(req); err != nil {
+		return fmt.Errorf("creating approval request: %w", err)
+	}
+
+	notifyApprovers(townRoot, req)
+	updateApprovalBead(townRoot, req.Bead, []string{"approval:pending"}, nil,
+		fmt.Sprintf("Approval %s requested for step %s by %s", req.ID, req.Step, req.RequestedBy))
+	_ = events.LogFeed(events.TypeApprovalRequested, req.RequestedBy,
+		events.ApprovalPayload(req.ID, req.Bead, req.Step, string(req.Status)))
+
+	if approveRequestJSON {
+		out, _ := json.MarshalIndent(req, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+	fmt.Printf("%s Approval requested: %s\n", style.Bold.Render("⏸"), req.ID)
+	fmt.Printf("  Notified: %s\n", strings.Join(req.Approvers, ", "))
+	fmt.Printf("  Wait with: gt approve wait %s\n", req.ID)
+	return nil
+}
+
+// loadGateStep finds an approval gate step in a formula by name.
+func loadGateStep(formulaName, stepID string) (*formula.Step, error) {
+	f, err := loadFormulaByName(formulaName)
+	if err != nil {
+		return nil, err
+	}
+	step := f.GetStep(stepID)
+	if step == nil {
+		return nil, fmt.Errorf("formula %s has no step %q", formulaName, stepID)
+	}
+	if !step.IsApprovalGate() {
+		return nil, fmt.Errorf("step %q in %s is not an approval gate", stepID, formulaName)
+	}
+	return step, nil
+}
+
+func runApproveWait(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	store := approval.NewStore(townRoot)
+	poll := approveWaitPoll
+	if poll <= 0 {
+		poll = 15 * time.Second
+	}
+
+	announced := false
+	for {
+		sweepApprovals(townRoot, store)
+		req, err := store.Get(args[0])
+		if err != nil {
+			return err
+		}
+		switch req.Status {
+		case approval.StatusApproved:
+			fmt.Printf("%s %s approved by %s\n", approvalStatusIcon(req.Status), req.ID, req.Decider())
+			if req.Reason != "" {
+				fmt.Printf("  Reason: %s\n", req.Reason)
+			}
+			return nil
+		case approval.StatusRejected, approval.StatusExpired:
+			if req.Reason != "" {
+				return fmt.Errorf("%s %s by %s: %s", req.ID, req.Status, req.Decider(), req.Reason)
+			}
+			return fmt.Errorf("%s %s by %s", req.ID, req.Status, req.Decider())
+		}
+		if !announced {
+			fmt.Printf("%s Waiting for approval of %s (step %s)...\n", style.Dim.Render("⏸"), req.ID, req.Step)
+			announced = true
+		}
+		time.Sleep(poll)
+	}
+}
+
+// unapprovedGates returns the IDs of formulaName's approval gate steps that
+// have no approved request on any of beadIDs. Completion paths refuse to
+// finish while this is non-empty.
+func unapprovedGates(townRoot, formulaName string, beadIDs ...string) ([]string, error) {
+	f, err := loadFormulaByName(formulaName)
+	if err != nil {
+		return nil, err
+	}
+	store := approval.NewStore(townRoot)
+	var missing []string
+	for _, step := range f.Steps {
+		if !step.IsApprovalGate() {
+			continue
+		}
+		ok, err := store.Approved(formulaName, step.ID, beadIDs...)
+		if err != nil {
+			return nil, fmt.Errorf("reading approvals: %w", err)
+		}
+		if !ok {
+			missing = append(missing, step.ID)
+		}
+	}
+	return missing, nil
+}
+
+// checkApprovalGates refuses to complete issueID while a gate step of its
+// attached formula is unapproved. Requests may name either the issue or
+// its attached molecule.
+func checkApprovalGates(townRoot string, bd *beads.Beads, issueID string) error {
+	if issueID == "" {
+		return nil
+	}
+	issue, err := bd.Show(issueID)
+	if err != nil {
+		style.PrintWarning("could not check approval gates on %s: %v", issueID, err)
+		return nil
+	}
+	fields := beads.ParseAttachmentFields(issue)
+	if fields == nil || fields.AttachedFormula == "" {
+		return nil
+	}
+	beadIDs := []string{issueID}
+	if fields.AttachedMolecule != "" {
+		beadIDs = append(beadIDs, fields.AttachedMolecule)
+	}
+	missing, err := unapprovedGates(townRoot, fields.AttachedFormula, beadIDs...)
+	if err != nil {
+		return fmt.Errorf("cannot complete: checking approval gates: %w\nIf you are blocked: gt done --status ESCALATED", err)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("cannot complete: approval gate %s of %s has not been approved\n"+
+			"Request it: gt approve request %s --step %s --formula %s\n"+
+			"If it was rejected: gt done --status ESCALATED",
+			missing[0], fields.AttachedFormula, beadIDs[len(beadIDs)-1], missing[0], fields.AttachedFormula)
+	}
+	return nil
+}
+
+// checkStepApprovalGate refuses to close a materialized molecule step that
+// is an approval gate until it is approved. The formula comes from the
+// molecule root or, failing that, from the agent's hooked bead.
+func checkStepApprovalGate(townRoot string, bd *beads.Beads, moleculeID string, step *beads.Issue) error {
+	beadIDs := []string{moleculeID}
+	var formulaName string
+	if root, err := bd.Show(moleculeID); err == nil {
+		if fields := beads.ParseAttachmentFields(root); fields != nil {
+			formulaName = fields.AttachedFormula
+		}
+	}
+	if formulaName == "" {
+		if hookID := findHookedBeadForAgent(bd, detectSender()); hookID != "" {
+			if hooked, err := bd.Show(hookID); err == nil {
+				if fields := beads.ParseAttachmentFields(hooked); fields != nil && fields.AttachedMolecule == moleculeID {
+					formulaName = fields.AttachedFormula
+					beadIDs = append(beadIDs, hookID)
+				}
+			}
+		}
+	}
+	if formulaName == "" {
+		return nil
+	}
+	f, err := loadFormulaByName(formulaName)
+	if err != nil {
+		return fmt.Errorf("checking approval gate: %w", err)
+	}
+	for _, fs := range f.Steps {
+		if !fs.IsApprovalGate() || (fs.Title != step.Title && fs.ID != step.Title) {
+			continue
+		}
+		ok, err := approval.NewStore(townRoot).Approved(formulaName, fs.ID, beadIDs...)
+		if err != nil {
+			return fmt.Errorf("reading approvals: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("step %s is approval gate %s of %s and has not been approved\n"+
+				"Request it: gt approve request %s --step %s --formula %s",
+				step.ID, fs.ID, formulaName, moleculeID, fs.ID, formulaName)
+		}
+	}
+	return nil
+}
+
+func runApproveSweep(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	sweepApprovals(townRoot, approval.NewStore(townRoot))
+	return nil
+}
+
+func runApproveWebhookSecret(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	if !approveSecretRotate {
+		secret, err := approval.LoadSecret(townRoot)
+		if err == nil {
+			fmt.Println(string(secret))
+			return nil
+		}
+		if !errors.Is(err, approval.ErrNoSecret) {
+			return err
+		}
+	}
+	secret, err := approval.GenerateSecret(townRoot)
+	if err != nil {
+		return fmt.Errorf("writing webhook secret: %w", err)
+	}
+	fmt.Println(secret)
+	return nil
+}
+
+// sweepApprovals applies timeouts to overdue requests. Expired and
+// auto-approved requests are recorded on their beads; escalating requests
+// are routed like a high-severity escalation.
+func sweepApprovals(townRoot string, store *approval.Store) {
+	changed, err := store.ExpireDue()
+	if err != nil {
+		style.PrintWarning("applying approval timeouts: %v", err)
+	}
+	for _, req := range changed {
+		if req.Pending() {
+			escalateApproval(townRoot, req)
+			continue
+		}
+		recordApprovalDecision(townRoot, req)
+	}
+}
+
+// recordApprovalDecision labels and comments on the bead and logs the
+// decision to the feed.
+func recordApprovalDecision(townRoot string, req *approval.Request) {
+	if err := approval.RecordDecision(townRoot, req); err != nil {
+		style.PrintWarning("%v", err)
+	}
+}
+
+// updateApprovalBead is best-effort: the approval store is the source of
+// truth, the bead just carries the audit trail.
+func updateApprovalBead(townRoot, beadID string, add, remove []string, comment string) {
+	if err := approval.UpdateBead(townRoot, beadID, add, remove, comment); err != nil {
+		style.PrintWarning("%v", err)
+	}
+}
+
+func notifyApprovers(townRoot string, req *approval.Request) {
+	router := mail.NewRouter(townRoot)
+	defer router.WaitPendingNotifications()
+	subject := fmt.Sprintf("[APPROVAL] %s: %s", req.Bead, req.Step)
+	if req.Title != "" {
+		subject = fmt.Sprintf("[APPROVAL] %s: %s", req.Bead, req.Title)
+	}
+	for _, to := range req.Approvers {
+		msg := &mail.Message{
+			From:     req.RequestedBy,
+			To:       to,
+			Subject:  subject,
+			Body:     formatApprovalMailBody(req),
+			Type:     mail.TypeTask,
+			Priority: mail.PriorityHigh,
+		}
+		if err := router.Send(msg); err != nil {
+			style.PrintWarning("failed to notify %s: %v", to, err)
+		}
+	}
+}
+
+// escalateApproval routes a timed-out request with on_timeout = escalate
+// through the high-severity escalation route.
+func escalateApproval(townRoot string, req *approval.Request) {
+	cfg, err := config.LoadOrCreateEscalationConfig(config.EscalationConfigPath(townRoot))
+	if err != nil {
+		style.PrintWarning("loading escalation config: %v", err)
+		return
+	}
+	actions := cfg.GetRouteForSeverity(config.SeverityHigh)
+	description := fmt.Sprintf("Approval %s for %s step %s timed out", req.ID, req.Bead, req.Step)
+
+	router := mail.NewRouter(townRoot)
+	defer router.WaitPendingNotifications()
+	for _, target := range extractMailTargetsFromActions(actions) {
+		msg := &mail.Message{
+			From:     req.RequestedBy,
+			To:       target,
+			Subject:  "[HIGH] " + description,
+			Body:     formatApprovalMailBody(req),
+			Type:     mail.TypeTask,
+			Priority: mail.PriorityHigh,
+		}
+		if err := router.Send(msg); err != nil {
+			style.PrintWarning("failed to send to %s: %v", target, err)
+		}
+	}
+	executeExternalActions(actions, cfg, req.ID, config.SeverityHigh, description)
+	_ = events.LogFeed(events.TypeEscalationSent, req.RequestedBy,
+		events.EscalationPayload(req.Bead, req.ID, strings.Join(extractMailTargetsFromActions(actions), ","), description))
+}
+
+func formatApprovalMailBody(req *approval.Request) string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("Approval ID: %s", req.ID))
+	lines = append(lines, fmt.Sprintf("Bead: %s", req.Bead))
+	if req.Formula != "" {
+		lines = append(lines, fmt.Sprintf("Step: %s (%s)", req.Step, req.Formula))
+	} else {
+		lines = append(lines, fmt.Sprintf("Step: %s", req.Step))
+	}
+	if req.Title != "" {
+		lines = append(lines, fmt.Sprintf("Title: %s", req.Title))
+	}
+	lines = append(lines, fmt.Sprintf("Requested by: %s", req.RequestedBy))
+	if req.ExpiresAt != nil {
+		onTimeout := req.OnTimeout
+		if onTimeout == "" {
+			onTimeout = approval.TimeoutReject
+		}
+		lines = append(lines, fmt.Sprintf("Expires: %s (then %s)", req.ExpiresAt.Local().Format("2006-01-02 15:04"), onTimeout))
+	}
+	lines = append(lines, "")
+	lines = append(lines, "---")
+	lines = append(lines, "To approve: gt approve "+req.ID)
+	lines = append(lines, "To reject: gt approve reject "+req.ID+" --reason \"why\"")
+	return strings.Join(lines, "\n")
+}
+
+func approvalStatusIcon(s approval.Status) string {
+	switch s {
+	case approval.StatusApproved:
+		return style.Success.Render("✓")
+	case approval.StatusRejected:
+		return style.Error.Render("✗")
+	case approval.StatusExpired:
+		return style.Warning.Render("⌛")
+	default:
+		return style.Dim.Render("⏸")
+	}
+}
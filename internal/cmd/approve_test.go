@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/approval"
+)
+
+func TestFormatApprovalMailBody(t *testing.T) {
+	expires := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	req := &approval.Request{
+		ID:          "ap-1a2b3c4d",
+		Bead:        "gt-wisp-abc",
+		Formula:     "mol-release",
+		Step:        "signoff",
+		Title:       "Sign off on release",
+		RequestedBy: "gastown/Toast",
+		ExpiresAt:   &expires,
+	}
+	body := formatApprovalMailBody(req)
+	for _, want := range []string{
+		"Approval ID: ap-1a2b3c4d",
+		"Step: signoff (mol-release)",
+		"(then reject)",
+		"gt approve ap-1a2b3c4d",
+		"gt approve reject ap-1a2b3c4d",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("mail body missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestDecideApproval_RejectsAgents(t *testing.T) {
+	t.Setenv("GT_ROLE", "polecat")
+	err := decideApproval("ap-1a2b3c4d", true)
+	if err == nil || !strings.Contains(err.Error(), "agents cannot decide") {
+		t.Errorf("decideApproval from an agent session = %v", err)
+	}
+}
+
+func TestDecideApproval_RejectsScrubbedAgentEnv(t *testing.T) {
+	t.Setenv("GT_ROLE", "")
+	t.Setenv("BD_ACTOR", "gastown/polecats/Toast")
+	err := decideApproval("ap-1a2b3c4d", true)
+	if err == nil || !strings.Contains(err.Error(), "BD_ACTOR") {
+		t.Errorf("decideApproval with BD_ACTOR set = %v", err)
+	}
+}
+
+func TestUnapprovedGates_TownFormula(t *testing.T) {
+	town := t.TempDir()
+	formulas := filepath.Join(town, ".beads", "formulas")
+	if err := os.MkdirAll(formulas, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Not an embedded formula: gates must be found through the normal resolver.
+	if err := os.WriteFile(filepath.Join(formulas, "mol-town-release.formula.toml"), []byte(`
+formula = "mol-town-release"
+version = 1
+[[steps]]
+id = "build"
+title = "Build"
+[[steps]]
+id = "signoff"
+title = "Release sign-off"
+needs = ["build"]
+gate = "approval"
+approvers = ["overseer", "ci"]
+timeout = "2h"
+on_timeout = "escalate"
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(town)
+
+	step, err := loadGateStep("mol-town-release", "signoff")
+	if err != nil {
+		t.Fatalf("loadGateStep: %v", err)
+	}
+	if !slices.Equal(step.Approvers, []string{"overseer", "ci"}) || step.OnTimeout != "escalate" {
+		t.Errorf("gate step = %+v", step)
+	}
+	if _, err := loadGateStep("mol-town-release", "build"); err == nil {
+		t.Error("loadGateStep accepted a step without a gate")
+	}
+
+	missing, err := unapprovedGates(town, "mol-town-release", "gt-abc", "gt-wisp-xyz")
+	if err != nil || !slices.Equal(missing, []string{"signoff"}) {
+		t.Fatalf("unapprovedGates before approval = %v, %v", missing, err)
+	}
+
+	store := approval.NewStore(town)
+	req := &approval.Request{Bead: "gt-wisp-xyz", Formula: "mol-town-release", Step: "signoff", RequestedBy: "gastown/polecats/Toast", Approvers: step.Approvers}
+	if err := store.Create(req); err != nil {
+		t.Fatal(err)
+	}
+	if missing, _ := unapprovedGates(town, "mol-town-release", "gt-abc", "gt-wisp-xyz"); len(missing) != 1 {
+		t.Errorf("pending request should not open the gate, missing = %v", missing)
+	}
+	if _, err := store.Decide(req.ID, approval.Decision{Approve: true, By: "overseer", Via: approval.ViaCLI}); err != nil {
+		t.Fatal(err)
+	}
+	missing, err = unapprovedGates(town, "mol-town-release", "gt-abc", "gt-wisp-xyz")
+	if err != nil || len(missing) != 0 {
+		t.Errorf("unapprovedGates after approval = %v, %v", missing, err)
+	}
+}
@@ -3,11 +3,14 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/checkpoint"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -27,8 +30,12 @@ Checkpoint data includes:
 - Modified files list
 - Git branch and last commit
 - Timestamp
+- A git snapshot of uncommitted work
 
-Checkpoints are stored in .polecat-checkpoint.json in the polecat directory.`,
+Checkpoints are stored in .polecat-checkpoint.json in the polecat directory.
+Snapshots are commits under refs/gastown/checkpoints/<agent>, shared by every
+worktree of the rig, so they survive the worktree being nuked or repaired.
+The witness patrol snapshots every polecat periodically (gt checkpoint patrol).`,
 }
 
 var checkpointWriteCmd = &cobra.Command{
@@ -41,7 +48,9 @@ This is typically called:
 - Periodically during long work sessions
 - Before handoff to another session
 
-The checkpoint captures git state, molecule progress, and hooked work.`,
+The checkpoint captures git state, molecule progress, and hooked work,
+and snapshots uncommitted changes (including untracked files) to a hidden
+ref without touching the index.`,
 	RunE: runCheckpointWrite,
 }
 
@@ -55,20 +64,69 @@ var checkpointReadCmd = &cobra.Command{
 var checkpointClearCmd = &cobra.Command{
 	Use:   "clear",
 	Short: "Clear the checkpoint file",
-	Long:  `Remove the checkpoint file. Use after work is complete or checkpoint is no longer needed.`,
-	RunE:  runCheckpointClear,
+	Long: `Remove the checkpoint file. Use after work is complete or checkpoint is no longer needed.
+
+With --snapshot, also delete this agent's work snapshot ref.`,
+	RunE: runCheckpointClear,
+}
+
+var checkpointRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Reapply a work snapshot into this worktree",
+	Long: `Reapply the work snapshot saved for this agent (or --from another agent).
+
+Use this in a fresh or repaired worktree after a crash. If the worktree's
+HEAD is behind the commit the snapshot was taken on, it is fast-forwarded
+first so unpushed commits come back; then the uncommitted changes and
+untracked files are applied as unstaged changes.
+
+Examples:
+  gt checkpoint restore
+  gt checkpoint restore --from gastown/polecats/Toast
+  gt checkpoint restore --dry-run`,
+	SilenceUsage: true,
+	RunE:         runCheckpointRestore,
+}
+
+var checkpointListCmd = &cobra.Command{
+	Use:   "list [rig]",
+	Short: "List work snapshots",
+	Long: `List work snapshots in the current repository, or in a rig's shared
+repository when a rig name is given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCheckpointList,
+}
+
+var checkpointPatrolCmd = &cobra.Command{
+	Use:   "patrol <rig>",
+	Short: "Snapshot every polecat worktree in a rig",
+	Long: `Snapshot uncommitted work in every polecat worktree of a rig.
+
+Run by the witness patrol. Polecats whose snapshot is younger than the
+witness checkpoint_interval (default 10m) are skipped unless --force is set;
+worktrees with no changes are never snapshotted.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runCheckpointPatrol,
 }
 
 var (
-	checkpointNotes    string
-	checkpointMolecule string
-	checkpointStep     string
+	checkpointNotes         string
+	checkpointMolecule      string
+	checkpointStep          string
+	checkpointClearSnapshot bool
+	checkpointRestoreFrom   string
+	checkpointRestoreDryRun bool
+	checkpointPatrolForce   bool
 )
 
 func init() {
 	checkpointCmd.AddCommand(checkpointWriteCmd)
 	checkpointCmd.AddCommand(checkpointReadCmd)
 	checkpointCmd.AddCommand(checkpointClearCmd)
+	checkpointCmd.AddCommand(checkpointRestoreCmd)
+	checkpointCmd.AddCommand(checkpointListCmd)
+	checkpointCmd.AddCommand(checkpointPatrolCmd)
 
 	checkpointWriteCmd.Flags().StringVar(&checkpointNotes, "notes", "",
 		"Add notes to the checkpoint")
@@ -76,6 +134,14 @@ func init() {
 		"Override molecule ID (auto-detected if not specified)")
 	checkpointWriteCmd.Flags().StringVar(&checkpointStep, "step", "",
 		"Override step ID (auto-detected if not specified)")
+	checkpointClearCmd.Flags().BoolVar(&checkpointClearSnapshot, "snapshot", false,
+		"Also delete the work snapshot ref")
+	checkpointRestoreCmd.Flags().StringVar(&checkpointRestoreFrom, "from", "",
+		"Agent whose snapshot to restore (default: this agent)")
+	checkpointRestoreCmd.Flags().BoolVar(&checkpointRestoreDryRun, "dry-run", false,
+		"Show what would be restored without changing the worktree")
+	checkpointPatrolCmd.Flags().BoolVar(&checkpointPatrolForce, "force", false,
+		"Snapshot even if the last snapshot is recent")
 
 	rootCmd.AddCommand(checkpointCmd)
 }
@@ -140,6 +206,16 @@ func runCheckpointWrite(cmd *cobra.Command, args []string) error {
 		cp.WithHookedBead(hookedBead)
 	}
 
+	// Snapshot uncommitted work so it survives the worktree
+	agent := getAgentIdentity(roleInfo)
+	snap, _, err := checkpoint.SaveSnapshot(cwd, agent, hookedBead)
+	if err != nil {
+		style.PrintWarning("could not snapshot work: %v", err)
+	} else if snap != nil {
+		cp.SnapshotRef = snap.Ref
+		cp.SnapshotCommit = snap.Commit
+	}
+
 	// Write checkpoint
 	if err := checkpoint.Write(cwd, cp); err != nil {
 		return fmt.Errorf("writing checkpoint: %w", err)
@@ -147,6 +223,9 @@ func runCheckpointWrite(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("%s Checkpoint written\n", style.Bold.Render("✓"))
 	fmt.Printf("  %s\n", cp.Summary())
+	if snap != nil {
+		fmt.Printf("  snapshot: %s (%d files)\n", snap.Ref, len(snap.Files))
+	}
 
 	return nil
 }
@@ -197,6 +276,9 @@ func runCheckpointRead(cmd *cobra.Command, args []string) error {
 	if cp.Notes != "" {
 		fmt.Printf("Notes: %s\n", cp.Notes)
 	}
+	if cp.SnapshotRef != "" {
+		fmt.Printf("Snapshot: %s @ %s\n", cp.SnapshotRef, cp.SnapshotCommit[:min(12, len(cp.SnapshotCommit))])
+	}
 	if cp.SessionID != "" {
 		fmt.Printf("Session ID: %s\n", cp.SessionID)
 	}
@@ -214,10 +296,162 @@ func runCheckpointClear(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("removing checkpoint: %w", err)
 	}
 
+	if checkpointClearSnapshot {
+		agent, err := checkpointAgent(cwd)
+		if err != nil {
+			return err
+		}
+		if err := checkpoint.DeleteSnapshot(cwd, agent); err != nil {
+			return err
+		}
+	}
+
 	fmt.Printf("%s Checkpoint cleared\n", style.Bold.Render("✓"))
 	return nil
 }
 
+func runCheckpointRestore(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+
+	agent := checkpointRestoreFrom
+	if agent == "" {
+		if agent, err = checkpointAgent(cwd); err != nil {
+			return err
+		}
+	}
+
+	snap, err := checkpoint.LoadSnapshot(cwd, agent)
+	if err != nil {
+		return err
+	}
+	if snap == nil {
+		return fmt.Errorf("no snapshot for %s", agent)
+	}
+
+	fmt.Printf("Snapshot %s from %s ago\n", snap.Commit[:min(12, len(snap.Commit))], time.Since(snap.Time).Round(time.Second))
+	if snap.Branch != "" {
+		fmt.Printf("  branch: %s\n", snap.Branch)
+	}
+	if snap.Bead != "" {
+		fmt.Printf("  bead:   %s\n", snap.Bead)
+	}
+	for _, f := range snap.Files {
+		fmt.Printf("  - %s\n", f)
+	}
+	if checkpointRestoreDryRun {
+		return nil
+	}
+
+	res, err := checkpoint.RestoreSnapshot(cwd, snap)
+	if err != nil {
+		return fmt.Errorf("restoring snapshot: %w", err)
+	}
+	if res.FastForwarded {
+		fmt.Printf("%s Fast-forwarded to %s to recover unpushed commits\n",
+			style.Bold.Render("✓"), snap.Parent[:min(12, len(snap.Parent))])
+	}
+	if res.MissingCommits > 0 {
+		style.PrintWarning("%d commit(s) from the snapshot are not on this branch; cherry-pick them from %s^",
+			res.MissingCommits, snap.Ref)
+	}
+	fmt.Printf("%s Restored %d file(s) as unstaged changes\n", style.Bold.Render("✓"), len(res.Files))
+	return nil
+}
+
+func runCheckpointList(cmd *cobra.Command, args []string) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+	if len(args) == 1 {
+		_, r, err := getRig(args[0])
+		if err != nil {
+			return err
+		}
+		dir = filepath.Join(r.Path, ".repo.git")
+	}
+
+	snaps, err := checkpoint.ListSnapshots(dir)
+	if err != nil {
+		return err
+	}
+	if len(snaps) == 0 {
+		fmt.Printf("%s No work snapshots\n", style.Dim.Render("○"))
+		return nil
+	}
+	for _, s := range snaps {
+		line := fmt.Sprintf("%-32s %s  %3d files  %s ago", s.Key, s.Commit[:min(12, len(s.Commit))],
+			len(s.Files), time.Since(s.Time).Round(time.Minute))
+		if s.Bead != "" {
+			line += "  " + s.Bead
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func runCheckpointPatrol(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+	mgr, _, err := getPolecatManager(rigName)
+	if err != nil {
+		return err
+	}
+	polecats, err := mgr.List()
+	if err != nil {
+		return fmt.Errorf("listing polecats: %w", err)
+	}
+
+	interval := time.Duration(0)
+	if !checkpointPatrolForce {
+		townRoot, _ := workspace.FindFromCwd()
+		interval = config.LoadOperationalConfig(townRoot).GetWitnessConfig().CheckpointIntervalD()
+	}
+
+	var saved, failed int
+	for _, p := range polecats {
+		agent := fmt.Sprintf("%s/polecats/%s", rigName, p.Name)
+		if prev, _ := checkpoint.LoadSnapshot(p.ClonePath, agent); prev != nil && time.Since(prev.Time) < interval {
+			continue
+		}
+		snap, changed, err := checkpoint.SaveSnapshot(p.ClonePath, agent, p.Issue)
+		if err != nil {
+			failed++
+			style.PrintWarning("%s: %v", p.Name, err)
+			continue
+		}
+		if changed {
+			saved++
+			fmt.Printf("  %s %s: %d files\n", style.Bold.Render("✓"), p.Name, len(snap.Files))
+		}
+	}
+
+	fmt.Printf("Snapshotted %d of %d polecat(s)\n", saved, len(polecats))
+	if failed > 0 {
+		return fmt.Errorf("%d polecat(s) could not be snapshotted", failed)
+	}
+	return nil
+}
+
+// checkpointAgent returns the identity snapshots are keyed by for the
+// polecat or crew worker at cwd.
+func checkpointAgent(cwd string) (string, error) {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return "", fmt.Errorf("not in a Gas Town workspace")
+	}
+	roleInfo, err := GetRoleWithContext(cwd, townRoot)
+	if err != nil {
+		return "", fmt.Errorf("detecting role: %w", err)
+	}
+	if roleInfo.Role != RolePolecat && roleInfo.Role != RoleCrew {
+		return "", fmt.Errorf("snapshots only apply to polecats and crew workers")
+	}
+	return getAgentIdentity(roleInfo), nil
+}
+
 // detectMoleculeContext tries to detect the current molecule and step from beads.
 func detectMoleculeContext(workDir string, ctx RoleInfo) (moleculeID, stepID, stepTitle string) {
 	b := beads.New(workDir)
@@ -19,6 +19,8 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	convoyops "github.com/steveyegge/gastown/internal/convoy"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/federation"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
@@ -232,7 +234,11 @@ var convoyStatusCmd = &cobra.Command{
 	Long: `Show detailed status for a convoy.
 
 Displays convoy metadata, tracked issues, and completion progress.
-Without an ID, shows status of all active convoys.`,
+Without an ID, shows status of all active convoys.
+
+Tracked issues delegated to peer towns (gt federation delegate) show the
+delegated issue's status in that town. A <town>:<convoy-id> shows a peer
+town's convoy, read-only.`,
 	Args: cobra.MaximumNArgs(1),
 	SilenceUsage: true,
 	RunE:         runConvoyStatus,
@@ -1517,8 +1523,11 @@ func checkAndCloseCompletedConvoys(townBeads string, dryRun bool) ([]struct{ ID,
 	return closed, nil
 }
 
-// notifyConvoyCompletion sends notifications to owner and any notify addresses.
+// notifyConvoyCompletion logs the landing to the feed and sends notifications
+// to owner and any notify addresses.
 func notifyConvoyCompletion(townBeads, convoyID, title string) {
+	_ = events.LogFeed(events.TypeConvoyLanded, detectActor(), events.ConvoyLandedPayload(convoyID, title))
+
 	// Get convoy description to find owner and notify addresses
 	showArgs := []string{"show", convoyID, "--json"}
 	showCmd := exec.Command("bd", showArgs...)
@@ -1586,6 +1595,11 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 
 	convoyID := args[0]
 
+	// A peer town's convoy (town:id) is fetched through the federation
+	if federation.IsRef(convoyID) {
+		return showRemoteConvoy(convoyID)
+	}
+
 	// Check if it's a numeric shortcut (e.g., "1" instead of "hq-cv-xyz")
 	if n, err := strconv.Atoi(convoyID); err == nil && n > 0 {
 		resolved, err := resolveConvoyNumber(townBeads, n)
@@ -1635,6 +1649,13 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("getting tracked issues for %s: %w", convoyID, err)
 	}
 
+	// Follow work delegated to peer towns so the convoy shows its progress
+	for i := range tracked {
+		if refs := delegatedRefs(tracked[i].Labels); len(refs) > 0 {
+			tracked[i].Delegations = fetchDelegations(filepath.Dir(townBeads), refs)
+		}
+	}
+
 	// Count completed
 	completed := 0
 	for _, t := range tracked {
@@ -1724,6 +1745,9 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 				line += fmt.Sprintf("  %s", style.Dim.Render(workerDisplay))
 			}
 			fmt.Println(line)
+			for _, d := range t.Delegations {
+				fmt.Println(style.Dim.Render(fmt.Sprintf("        ↳ %s %s [%s]", d.ID, d.Status, shortAssignee(d.Assignee))))
+			}
 		}
 	}
 
@@ -1993,6 +2017,10 @@ type trackedIssueInfo struct {
 	Labels    []string `json:"labels,omitempty"`     // Bead labels (propagated from trackedDependency)
 	Worker    string   `json:"worker,omitempty"`     // Worker currently assigned (e.g., gastown/nux)
 	WorkerAge string   `json:"worker_age,omitempty"` // How long worker has been on this issue
+
+	// Delegations are the peer-town issues this issue was delegated to,
+	// with their status there (gt convoy status only).
+	Delegations []federation.IssueView `json:"delegations,omitempty"`
 }
 
 // trackedDependency is dep-list data enriched with fresh issue details.
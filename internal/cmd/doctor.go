@@ -107,6 +107,8 @@ Patrol checks:
   - patrol-hooks-wired       Verify daemon triggers patrols
   - patrol-not-stuck         Detect stale wisps (>1h)
   - patrol-plugins-accessible Verify plugin directories
+  - plugin-lock              Verify installed plugins match plugins.lock.json (fixable)
+  - plugin-config            Verify plugins have their required config values
 
 Use --fix to attempt automatic fixes for issues that support it.
 Use --no-start with --fix to suppress starting the daemon and agents.
@@ -178,6 +180,8 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	d.Register(doctor.NewCustomStatusesCheck())
 	d.Register(doctor.NewRoleLabelCheck())
 	d.Register(doctor.NewFormulaCheck())
+	d.Register(doctor.NewPluginLockCheck())
+	d.Register(doctor.NewPluginConfigCheck())
 	d.Register(doctor.NewPrefixConflictCheck())
 	d.Register(doctor.NewRigNameMismatchCheck())
 	d.Register(doctor.NewPrefixMismatchCheck())
@@ -15,6 +15,7 @@ import (
 	"github.com/steveyegge/gastown/internal/dog"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/plugin"
+	"github.com/steveyegge/gastown/internal/secrets"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/util"
@@ -983,6 +984,16 @@ func runDogDispatch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("finding plugin: %w", err)
 	}
 
+	// A plugin missing required config values can't run.
+	pluginCfg, err := plugin.ResolveConfig(townRoot, p, secrets.Open(townRoot).Get)
+	if err != nil {
+		return fmt.Errorf("resolving plugin config: %w", err)
+	}
+	if problems := pluginCfg.Problems(); len(problems) > 0 {
+		return fmt.Errorf("plugin %s is not configured: %s (see gt plugin config show %s)", p.Name, strings.Join(problems, "; "), p.Name)
+	}
+	pluginEnv, pluginSecretEnv := pluginCfg.Env()
+
 	// Get dog manager (reuse rigsConfig from above)
 	mgr := dog.NewManager(townRoot, rigsConfig)
 
@@ -1112,6 +1123,23 @@ func runDogDispatch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("sending plugin mail to dog: %w", err)
 	}
 
+	// Config values reach the dog through its session environment, so a
+	// configured plugin needs a fresh session.
+	if len(pluginEnv)+len(pluginSecretEnv) > 0 {
+		sm := dog.NewSessionManager(tmux.NewTmux(), townRoot, mgr)
+		if running, _ := sm.IsRunning(targetDog.Name); running {
+			if !dogDispatchJSON {
+				style.PrintWarning("dog %s session already running; plugin config not injected", targetDog.Name)
+			}
+		} else if err := sm.Start(targetDog.Name, dog.SessionStartOptions{
+			WorkDesc:  workDesc,
+			Env:       pluginEnv,
+			SecretEnv: pluginSecretEnv,
+		}); err != nil {
+			return fmt.Errorf("starting dog session: %w", err)
+		}
+	}
+
 	// Success - output result
 	if dogDispatchJSON {
 		return json.NewEncoder(os.Stdout).Encode(result)
@@ -213,6 +213,10 @@ contention on the storage manifest), all write operations fail. This command:
 
 If the server is already writable, this is a no-op.
 
+With --failover and a hot-standby replica enabled, the replica is promoted
+instead of restarting the server, so agents keep writing while the primary
+is recovered (see 'gt dolt failback').
+
 The daemon performs this check automatically every 30 seconds. Use this command
 for immediate recovery without waiting for the daemon's health check loop.`,
 	RunE: runDoltRecover,
@@ -316,6 +320,7 @@ var (
 	doltSyncForce       bool
 	doltSyncDB          string
 	doltSyncGC          bool
+	doltRecoverFailover bool
 )
 
 func init() {
@@ -358,6 +363,8 @@ func init() {
 	doltMigrateWispsCmd.Flags().BoolVar(&doltMigrateWispsDry, "dry-run", false, "Preview what would be migrated without making changes")
 	doltMigrateWispsCmd.Flags().StringVar(&doltMigrateWispsDB, "db", "", "Target database (default: auto-detect from rig)")
 
+	doltRecoverCmd.Flags().BoolVar(&doltRecoverFailover, "failover", false, "Promote the hot-standby replica instead of restarting")
+
 	rootCmd.AddCommand(doltCmd)
 }
 
@@ -673,6 +680,8 @@ func runDoltStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	printDoltFailoverStatus(townRoot)
+
 	return nil
 }
 
@@ -747,18 +756,15 @@ func runDoltSQL(cmd *cobra.Command, args []string) error {
 
 	config := doltserver.DefaultConfig(townRoot)
 
-	// Check if server is running - if so, connect via Dolt SQL client
+	// Check if server is running - if so, connect via Dolt SQL client.
+	// After a failover the promoted replica serves, even with the primary down.
 	running, _, _ := doltserver.IsRunning(townRoot)
-	if running {
+	if running || config.FailoverPort > 0 {
 		// Connect to running server using dolt sql client
 		// Using --no-tls since server doesn't have TLS configured
-		host := config.Host
-		if host == "" {
-			host = "127.0.0.1"
-		}
 		sqlArgs := []string{
-			"--host", host,
-			"--port", strconv.Itoa(config.Port),
+			"--host", config.EffectiveHost(),
+			"--port", strconv.Itoa(config.EffectivePort()),
 			"--user", config.User,
 			"--no-tls",
 			"sql",
@@ -1197,6 +1203,18 @@ func runDoltRecover(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if doltRecoverFailover {
+		state, err := doltserver.Failover(townRoot, "read-only primary (gt dolt recover --failover)")
+		if err != nil {
+			return fmt.Errorf("failover failed: %w", err)
+		}
+		fmt.Printf("%s Failed over to the replica on port %d; the primary stays read-only until restarted\n",
+			style.Bold.Render("✓"), state.ReplicaPort)
+		fmt.Printf("  Restart it with %s, then %s\n",
+			style.Dim.Render("gt dolt restart"), style.Dim.Render("gt dolt failback"))
+		return nil
+	}
+
 	if err := doltserver.RecoverReadOnly(townRoot); err != nil {
 		return fmt.Errorf("recovery failed: %w", err)
 	}
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/daemon"
+	"github.com/steveyegge/gastown/internal/doltserver"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var doltFailoverReason string
+
+var doltReplicaCmd = &cobra.Command{
+	Use:   "replica",
+	Short: "Manage the hot-standby Dolt replica",
+	RunE:  requireSubcommand,
+	Long: `Manage the hot-standby Dolt replica.
+
+The replica is a second dolt sql-server on its own port and data directory
+(default: primary port + 1, .dolt-replica/). The primary replicates every
+commit to it continuously. When the primary breaches its health SLOs, the
+daemon promotes the replica and every Gas Town tool switches to it.
+
+Enable it in mayor/daemon.json:
+
+  "patrols": {
+    "dolt_server": {
+      "replica": { "enabled": true }
+    }
+  }
+
+The daemon starts the replica automatically; these commands are for manual
+control.`,
+}
+
+var doltReplicaStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the hot-standby replica",
+	Long: `Start the hot-standby replica in the background.
+
+Restart the primary afterwards (gt dolt restart) if it was started before the
+replica was enabled, so it picks up the replication config.`,
+	RunE: runDoltReplicaStart,
+}
+
+var doltReplicaStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the hot-standby replica",
+	Long: `Stop the hot-standby replica.
+
+Refused while the replica is serving after a failover; fail back first.`,
+	RunE: runDoltReplicaStop,
+}
+
+var doltReplicaStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show replica and replication status",
+	RunE:  runDoltReplicaStatus,
+}
+
+var doltFailoverCmd = &cobra.Command{
+	Use:   "failover",
+	Short: "Promote the hot-standby replica to primary",
+	Long: `Promote the hot-standby replica to primary now, without waiting for the
+daemon's SLO probe.
+
+The primary is asked to step down first, which waits until the replica has
+every commit. If it won't, the failover only goes ahead when the primary is
+confirmed down and the daemon's last replication check (within the past
+minute) shows the replica at most max_failover_lag behind (default: fully
+caught up). A primary that is still running is never replaced.
+
+Afterwards gt commands and bd calls use the replica, including bare bd calls
+in running agent sessions: each rig's metadata.json is pointed at the replica,
+and back at the primary on failback.
+
+Hand the primary role back with 'gt dolt failback'.`,
+	RunE: runDoltFailover,
+}
+
+var doltFailbackCmd = &cobra.Command{
+	Use:   "failback",
+	Short: "Hand the primary role back from the replica",
+	Long: `Hand the primary role back from the replica to the original primary.
+
+The replica steps down gracefully: it stops taking writes and waits until the
+original primary has every commit before the primary takes over again. If the
+primary is down or behind, the command fails and the replica keeps serving.`,
+	RunE: runDoltFailback,
+}
+
+func init() {
+	doltReplicaCmd.AddCommand(doltReplicaStartCmd)
+	doltReplicaCmd.AddCommand(doltReplicaStopCmd)
+	doltReplicaCmd.AddCommand(doltReplicaStatusCmd)
+	doltCmd.AddCommand(doltReplicaCmd)
+
+	doltFailoverCmd.Flags().StringVar(&doltFailoverReason, "reason", "manual failover", "Reason recorded in the failover marker")
+	doltCmd.AddCommand(doltFailoverCmd)
+	doltCmd.AddCommand(doltFailbackCmd)
+}
+
+func runDoltReplicaStart(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	if err := doltserver.StartReplica(townRoot); err != nil {
+		return err
+	}
+	_, pid := doltserver.IsReplicaRunning(townRoot)
+	rc := doltserver.LoadReplicaConfig(townRoot, doltserver.DefaultConfig(townRoot).Port)
+	fmt.Printf("%s Dolt replica running (PID %d, port %d)\n", style.Bold.Render("✓"), pid, rc.Port)
+	fmt.Printf("  Data dir: %s\n", rc.DataDir)
+	return nil
+}
+
+func runDoltReplicaStop(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	if err := doltserver.StopReplica(townRoot); err != nil {
+		return err
+	}
+	fmt.Printf("%s Dolt replica stopped\n", style.Bold.Render("✓"))
+	return nil
+}
+
+func runDoltReplicaStatus(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	config := doltserver.DefaultConfig(townRoot)
+	rc := doltserver.LoadReplicaConfig(townRoot, config.Port)
+	if rc == nil {
+		fmt.Printf("%s No hot-standby replica configured\n", style.Dim.Render("○"))
+		fmt.Printf("  Enable with patrols.dolt_server.replica.enabled in mayor/daemon.json\n")
+		return nil
+	}
+
+	printDoltServerRole(townRoot, "Primary", config.Port)
+	running, pid := doltserver.IsReplicaRunning(townRoot)
+	if !running {
+		fmt.Printf("%s Replica is %s (port %d)\n", style.Dim.Render("○"), "not running", rc.Port)
+		fmt.Printf("  Start with: %s\n", style.Dim.Render("gt dolt replica start"))
+		return nil
+	}
+	fmt.Printf("  Replica PID: %d, data dir: %s\n", pid, rc.DataDir)
+	printDoltServerRole(townRoot, "Replica", rc.Port)
+
+	// Replication is reported by whichever server is primary.
+	servingPort := config.EffectivePort()
+	statuses, err := doltserver.GetReplicationStatus(townRoot, servingPort)
+	if err != nil {
+		fmt.Printf("\n  %s Replication status unavailable: %v\n", style.Bold.Render("!"), err)
+		return nil
+	}
+	fmt.Printf("\n  %s\n", style.Bold.Render("Replication:"))
+	for _, s := range statuses {
+		if s.Role != "primary" {
+			continue
+		}
+		lag := "unknown"
+		if s.LagMillis.Valid {
+			lag = (time.Duration(s.LagMillis.Int64) * time.Millisecond).String()
+		}
+		line := fmt.Sprintf("    %-20s lag %s", s.Database, lag)
+		if s.Error.Valid && s.Error.String != "" {
+			line += "  " + style.Bold.Render("! "+s.Error.String)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// printDoltServerRole prints a server's cluster role and epoch.
+func printDoltServerRole(townRoot, label string, port int) {
+	role, epoch, err := doltserver.ClusterRole(townRoot, port)
+	if err != nil {
+		fmt.Printf("  %s (port %d): %s\n", label, port, style.Dim.Render("unreachable"))
+		return
+	}
+	fmt.Printf("  %s (port %d): %s, epoch %d\n", label, port, style.Bold.Render(role), epoch)
+}
+
+// printDoltFailoverStatus prints the failover, replica and SLO section of
+// gt dolt status. Prints nothing for a town without a replica or SLO data.
+func printDoltFailoverStatus(townRoot string) {
+	config := doltserver.DefaultConfig(townRoot)
+	rc := doltserver.LoadReplicaConfig(townRoot, config.Port)
+	slo := daemon.LoadDoltSLOStatus(townRoot)
+	if rc == nil && slo == nil {
+		return
+	}
+
+	if config.FailoverPort > 0 {
+		state, _ := doltserver.LoadFailoverState(townRoot)
+		fmt.Printf("\n  %s %s\n", style.Bold.Render("!!!"),
+			style.Bold.Render(fmt.Sprintf("FAILED OVER — clients use the replica on port %d", config.FailoverPort)))
+		if state != nil {
+			fmt.Printf("    Since:  %s (epoch %d)\n", state.Since.Format("2006-01-02 15:04:05"), state.Epoch)
+			fmt.Printf("    Reason: %s\n", state.Reason)
+			if !state.GracefulStepDown {
+				fmt.Printf("    %s\n", unreplicatedNote(state))
+			}
+		}
+		fmt.Printf("    Fail back with: %s\n", style.Dim.Render("gt dolt failback"))
+	}
+
+	if rc != nil {
+		fmt.Printf("\n  %s\n", style.Bold.Render("Hot-standby replica:"))
+		if running, pid := doltserver.IsReplicaRunning(townRoot); running {
+			fmt.Printf("    Port %d, PID %d\n", rc.Port, pid)
+		} else {
+			fmt.Printf("    Port %d, %s\n", rc.Port, style.Bold.Render("not running"))
+		}
+	}
+
+	if slo != nil {
+		fmt.Printf("\n  %s\n", style.Bold.Render("Health SLOs:"))
+		fmt.Printf("    p95 latency: %dms (max %dms)\n", slo.P95LatencyMs, slo.MaxP95Ms)
+		fmt.Printf("    Error rate:  %.0f%% (max %.0f%%)\n", slo.ErrorRate*100, slo.MaxErrorRate*100)
+		fmt.Printf("    Window:      %d probes over %s, as of %s\n",
+			slo.Samples, slo.Window, slo.UpdatedAt.Format("15:04:05"))
+		if slo.Breached {
+			fmt.Printf("    %s %s\n", style.Bold.Render("!"), slo.Breach)
+		}
+	}
+}
+
+// unreplicatedNote describes what a failover from a down primary may have
+// lost.
+func unreplicatedNote(state *doltserver.FailoverState) string {
+	return fmt.Sprintf("Primary was down; up to %v of commits (as of %s) were not replicated",
+		time.Duration(state.UnreplicatedLagMs)*time.Millisecond,
+		state.ReplicationCheckedAt.Format("15:04:05"))
+}
+
+func runDoltFailover(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	state, err := doltserver.Failover(townRoot, doltFailoverReason)
+	if err != nil {
+		return fmt.Errorf("failover failed: %w", err)
+	}
+	fmt.Printf("%s Failed over to the replica on port %d (epoch %d)\n",
+		style.Bold.Render("✓"), state.ReplicaPort, state.Epoch)
+	if !state.GracefulStepDown {
+		fmt.Printf("  %s %s\n", style.Bold.Render("!"), unreplicatedNote(state))
+	}
+	fmt.Printf("  Sessions started before now keep port %d for bare bd calls until they restart.\n", state.PrimaryPort)
+	fmt.Printf("  Fail back with: %s\n", style.Dim.Render("gt dolt failback"))
+	return nil
+}
+
+func runDoltFailback(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	primaryPort := doltserver.DefaultConfig(townRoot).Port
+	epoch, err := doltserver.Failback(townRoot)
+	if err != nil {
+		return fmt.Errorf("failback failed: %w", err)
+	}
+	fmt.Printf("%s Primary on port %d serving again (epoch %d); replica is back on standby\n",
+		style.Bold.Render("✓"), primaryPort, epoch)
+	return nil
+}
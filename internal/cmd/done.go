@@ -17,6 +17,7 @@ import (
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
@@ -351,6 +352,12 @@ func runDone(cmd *cobra.Command, args []string) (retErr error) {
 			return fmt.Errorf("cannot submit %s/master branch to merge queue", defaultBranch)
 		}
 
+		// An approval gate (gt approve) only holds if completing past it is
+		// refused; prime's checklist alone is advice.
+		if err := checkApprovalGates(townRoot, beads.New(cwd), issueID); err != nil {
+			return err
+		}
+
 		// CRITICAL: Verify work exists before completing (hq-xthqf)
 		// Polecats calling gt done without commits results in lost work.
 		// We MUST check for:
@@ -789,6 +796,13 @@ func runDone(cmd *cobra.Command, args []string) (retErr error) {
 			mrID = existingMR.ID
 			fmt.Printf("%s MR already exists (idempotent)\n", style.Bold.Render("✓"))
 			fmt.Printf("  MR ID: %s\n", style.Bold.Render(mrID))
+			// Resubmitting after a review requested changes sends the MR
+			// back to review.
+			if reset, err := refinery.ResetReview(bd, existingMR); err != nil {
+				style.PrintWarning("%v", err)
+			} else if reset {
+				fmt.Printf("  %s\n", style.Dim.Render("Changes were requested in review; MR resubmitted for review"))
+			}
 		} else {
 			// Build MR bead title and description
 			title := fmt.Sprintf("Merge: %s", issueID)
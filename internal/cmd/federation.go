@@ -0,0 +1,700 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/federation"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// delegatedLabelPrefix marks a local issue with the peer issue it was
+// delegated to: "delegated:<town>:<id>".
+const delegatedLabelPrefix = "delegated:"
+
+// sharedLabelPrefix marks a convoy a peer town may view even though none
+// of its tracked issues were delegated with that peer: "shared:<town>".
+const sharedLabelPrefix = "shared:"
+
+// Federation command flags
+var (
+	federationServeListen   string
+	federationPeerCA        string
+	federationPeerListJSON  bool
+	federationDelegateTitle string
+	federationDelegateTo    string
+	federationPortion       string
+	federationDeadline      string
+	federationAcceptance    string
+	federationCreditShare   int
+	federationShowJSON      bool
+)
+
+var federationCmd = &cobra.Command{
+	Use:     "federation",
+	GroupID: GroupServices,
+	Short:   "Peer with other towns over mTLS",
+	Long: `Connect this town to other towns.
+
+Peered towns can mail each other's agents (town:rig/agent addresses), view
+each other's convoys read-only, and delegate issues to each other's rigs.
+Each town authenticates with a certificate from its own CA (the one
+gt-proxy-server uses), and only accepts towns listed as peers.
+
+SETUP (on each town):
+  gt federation ca > mytown-ca.crt           # give this to the other town
+  gt federation peer add <name> https://<host>:9880 --ca <their-ca.crt>
+  gt federation serve                        # accept requests from peers
+
+Peers are stored in settings/federation.json.
+
+Examples:
+  gt mail send bartertown:gastown/max -s "Fuel" -m "Need 40 gallons"
+  gt convoy status bartertown:hq-cv-abc
+  gt federation delegate gt-abc bartertown:refinery
+  gt federation show bartertown:rf-xyz`,
+	RunE: requireSubcommand,
+}
+
+var federationServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the federation server for this town",
+	Long: `Accept mail, convoy views and delegations from peer towns.
+
+Listens on --listen, or listen_addr in settings/federation.json, or :9880.
+Peers added or removed while the server runs take effect immediately.`,
+	Args: cobra.NoArgs,
+	RunE: runFederationServe,
+}
+
+var federationCACmd = &cobra.Command{
+	Use:   "ca",
+	Short: "Print this town's CA certificate for peers",
+	Long: `Print the PEM certificate of this town's CA.
+
+Peer towns pass it to 'gt federation peer add --ca' so they can verify this
+town. The certificate is public; the CA key never leaves .runtime/ca.`,
+	Args: cobra.NoArgs,
+	RunE: runFederationCA,
+}
+
+var federationPeerCmd = &cobra.Command{
+	Use:   "peer",
+	Short: "Manage peer towns",
+	RunE:  requireSubcommand,
+}
+
+var federationPeerAddCmd = &cobra.Command{
+	Use:   "add <town> <url>",
+	Short: "Add or update a peer town",
+	Long: `Add a peer town, or update its URL and CA.
+
+<town> is the peer's town name (mayor/town.json), used in town:rig/agent
+addresses. <url> is its federation server, e.g. https://10.0.0.5:9880.
+
+Examples:
+  gt federation peer add bartertown https://10.0.0.5:9880 --ca bartertown-ca.crt`,
+	Args: cobra.ExactArgs(2),
+	RunE: runFederationPeerAdd,
+}
+
+var federationPeerRemoveCmd = &cobra.Command{
+	Use:   "remove <town>",
+	Short: "Remove a peer town",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFederationPeerRemove,
+}
+
+var federationPeerListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List peer towns",
+	Args:  cobra.NoArgs,
+	RunE:  runFederationPeerList,
+}
+
+var federationDelegateCmd = &cobra.Command{
+	Use:   "delegate <issue-id> <town>:<rig>",
+	Short: "Delegate an issue to a rig in a peer town",
+	Long: `Ask a peer town to take on an issue.
+
+The peer creates a child issue in <rig> and records a delegation whose
+parent is this town's issue. The local issue is labeled
+delegated:<town>:<child>, and convoys tracking it show the child's status.
+
+Examples:
+  gt federation delegate gt-abc bartertown:refinery
+  gt federation delegate gt-abc bartertown:refinery --to refinery/crew/max --credit-share 40`,
+	Args: cobra.ExactArgs(2),
+	RunE: runFederationDelegate,
+}
+
+var federationShowCmd = &cobra.Command{
+	Use:   "show <town>:<issue-id>",
+	Short: "Show an issue this town delegated to a peer",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFederationShow,
+}
+
+func init() {
+	federationServeCmd.Flags().StringVar(&federationServeListen, "listen", "", "Address to listen on (default: listen_addr or :9880)")
+
+	federationPeerAddCmd.Flags().StringVar(&federationPeerCA, "ca", "", "Path to the peer town's CA certificate (required)")
+	_ = federationPeerAddCmd.MarkFlagRequired("ca")
+	federationPeerListCmd.Flags().BoolVar(&federationPeerListJSON, "json", false, "Output as JSON")
+
+	federationDelegateCmd.Flags().StringVar(&federationDelegateTitle, "title", "", "Title for the child issue (default: the issue's title)")
+	federationDelegateCmd.Flags().StringVar(&federationDelegateTo, "to", "", "Agent in the peer town to delegate to (default: the rig)")
+	federationDelegateCmd.Flags().StringVar(&federationPortion, "portion", "", "What part of the issue is delegated")
+	federationDelegateCmd.Flags().StringVar(&federationDeadline, "deadline", "", "Expected completion date")
+	federationDelegateCmd.Flags().StringVar(&federationAcceptance, "acceptance", "", "What constitutes completion")
+	federationDelegateCmd.Flags().IntVar(&federationCreditShare, "credit-share", 0, "Percentage of credit that flows to the delegate (0-100)")
+
+	federationShowCmd.Flags().BoolVar(&federationShowJSON, "json", false, "Output as JSON")
+
+	federationPeerCmd.AddCommand(federationPeerAddCmd)
+	federationPeerCmd.AddCommand(federationPeerRemoveCmd)
+	federationPeerCmd.AddCommand(federationPeerListCmd)
+
+	federationCmd.AddCommand(federationServeCmd)
+	federationCmd.AddCommand(federationCACmd)
+	federationCmd.AddCommand(federationPeerCmd)
+	federationCmd.AddCommand(federationDelegateCmd)
+	federationCmd.AddCommand(federationShowCmd)
+
+	rootCmd.AddCommand(federationCmd)
+}
+
+func runFederationServe(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return err
+	}
+	srv, err := federation.New(federation.Config{
+		TownRoot:   townRoot,
+		ListenAddr: federationServeListen,
+	}, &townFederationBackend{townRoot: townRoot})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	return srv.Start(ctx)
+}
+
+func runFederationCA(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return err
+	}
+	id, err := federation.LoadIdentity(townRoot)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(id.CA.CertPEM)
+	return err
+}
+
+func runFederationPeerAdd(cmd *cobra.Command, args []string) error {
+	name, rawURL := args[0], args[1]
+	if !federation.ValidTownName(name) {
+		return fmt.Errorf("invalid town name %q (letters, digits, '-' and '_' only)", name)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("peer URL must be https://host:port, got %q", rawURL)
+	}
+	caPEM, err := os.ReadFile(federationPeerCA)
+	if err != nil {
+		return fmt.Errorf("reading peer CA: %w", err)
+	}
+	if err := federation.ValidatePeerCA(string(caPEM)); err != nil {
+		return fmt.Errorf("peer CA %s: %w", federationPeerCA, err)
+	}
+
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return err
+	}
+	if self, err := federation.TownName(townRoot); err == nil && self == name {
+		return fmt.Errorf("%q is this town", name)
+	}
+
+	path := config.FederationConfigPath(townRoot)
+	fc, err := config.LoadOrCreateFederationConfig(path)
+	if err != nil {
+		return err
+	}
+	_, existed := fc.Peers[name]
+	fc.Peers[name] = &config.FederationPeer{URL: rawURL, CA: string(caPEM), AddedAt: time.Now().UTC()}
+	if err := config.SaveFederationConfig(path, fc); err != nil {
+		return err
+	}
+
+	verb := "Added"
+	if existed {
+		verb = "Updated"
+	}
+	fmt.Printf("%s %s peer %s (%s)\n", style.Bold.Render("✓"), verb, name, rawURL)
+	return nil
+}
+
+func runFederationPeerRemove(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return err
+	}
+	path := config.FederationConfigPath(townRoot)
+	fc, err := config.LoadOrCreateFederationConfig(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := fc.Peers[args[0]]; !ok {
+		return fmt.Errorf("no peer named %q", args[0])
+	}
+	delete(fc.Peers, args[0])
+	if err := config.SaveFederationConfig(path, fc); err != nil {
+		return err
+	}
+	fmt.Printf("%s Removed peer %s\n", style.Bold.Render("✓"), args[0])
+	return nil
+}
+
+func runFederationPeerList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return err
+	}
+	fc, err := config.LoadOrCreateFederationConfig(config.FederationConfigPath(townRoot))
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(fc.Peers))
+	for name := range fc.Peers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if federationPeerListJSON {
+		type peerJSON struct {
+			Name    string    `json:"name"`
+			URL     string    `json:"url"`
+			AddedAt time.Time `json:"added_at,omitempty"`
+		}
+		out := make([]peerJSON, 0, len(names))
+		for _, name := range names {
+			out = append(out, peerJSON{Name: name, URL: fc.Peers[name].URL, AddedAt: fc.Peers[name].AddedAt})
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No peer towns. Add one with: gt federation peer add <town> <url> --ca <file>")
+		return nil
+	}
+	for _, name := range names {
+		fmt.Printf("  %s  %s\n", style.Bold.Render(name), fc.Peers[name].URL)
+	}
+	return nil
+}
+
+func runFederationDelegate(cmd *cobra.Command, args []string) error {
+	parentID := args[0]
+	town, rig, err := federation.SplitRef(args[1])
+	if err != nil {
+		return fmt.Errorf("target must be <town>:<rig>: %w", err)
+	}
+	if federationCreditShare < 0 || federationCreditShare > 100 {
+		return fmt.Errorf("--credit-share must be between 0 and 100")
+	}
+
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return err
+	}
+	b := beadsForID(townRoot, parentID)
+	parent, err := b.Show(parentID)
+	if err != nil {
+		return fmt.Errorf("issue %s: %w", parentID, err)
+	}
+
+	client, err := federation.NewClient(townRoot, town)
+	if err != nil {
+		return err
+	}
+
+	req := &federation.DelegateRequest{
+		Parent:      parent.ID,
+		Rig:         rig,
+		Title:       parent.Title,
+		Description: parent.Description,
+		DelegatedBy: detectSender(),
+		DelegatedTo: federationDelegateTo,
+	}
+	if federationDelegateTitle != "" {
+		req.Title = federationDelegateTitle
+	}
+	if federationPortion != "" || federationDeadline != "" || federationAcceptance != "" || federationCreditShare != 0 {
+		req.Terms = &beads.DelegationTerms{
+			Portion:            federationPortion,
+			Deadline:           federationDeadline,
+			AcceptanceCriteria: federationAcceptance,
+			CreditShare:        federationCreditShare,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	resp, err := client.Delegate(ctx, req)
+	if err != nil {
+		return fmt.Errorf("delegating %s: %w", parent.ID, err)
+	}
+
+	childRef := town + ":" + resp.Child
+	if err := b.Update(parent.ID, beads.UpdateOptions{AddLabels: []string{delegatedLabelPrefix + childRef}}); err != nil {
+		style.PrintWarning("delegated as %s but could not label %s: %v", childRef, parent.ID, err)
+	}
+
+	fmt.Printf("%s Delegated %s to %s:%s as %s\n", style.Bold.Render("✓"), parent.ID, town, rig, childRef)
+	fmt.Printf("  Follow with: gt federation show %s\n", childRef)
+	return nil
+}
+
+func runFederationShow(cmd *cobra.Command, args []string) error {
+	town, id, err := federation.SplitRef(args[0])
+	if err != nil {
+		return err
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return err
+	}
+	client, err := federation.NewClient(townRoot, town)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	v, err := client.Issue(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if federationShowJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	fmt.Printf("%s %s\n\n", style.Bold.Render(town+":"+v.ID+":"), v.Title)
+	fmt.Printf("  Status:    %s\n", v.Status)
+	if v.Assignee != "" {
+		fmt.Printf("  Assignee:  %s\n", v.Assignee)
+	}
+	if v.DelegatedFrom != "" {
+		fmt.Printf("  Delegated: from %s\n", v.DelegatedFrom)
+	}
+	return nil
+}
+
+// showRemoteConvoy prints a peer town's convoy (gt convoy status town:id).
+func showRemoteConvoy(ref string) error {
+	town, id, err := federation.SplitRef(ref)
+	if err != nil {
+		return err
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return err
+	}
+	client, err := federation.NewClient(townRoot, town)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	v, err := client.Convoy(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if convoyStatusJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	fmt.Printf("🚚 %s %s %s\n\n", style.Bold.Render(v.Town+":"+v.ID+":"), v.Title, style.Dim.Render("(read-only)"))
+	fmt.Printf("  Status:    %s\n", formatConvoyStatus(v.Status))
+	fmt.Printf("  Progress:  %d/%d completed\n", v.Completed, v.Total)
+	if len(v.Tracked) > 0 {
+		fmt.Printf("\n  %s\n", style.Bold.Render("Tracked Issues:"))
+		for _, t := range v.Tracked {
+			fmt.Printf("    %s %s: %s [%s]\n", trackedStatusSymbol(t.Status), t.ID, t.Title, shortAssignee(t.Assignee))
+		}
+	}
+	return nil
+}
+
+// delegatedRefs returns the peer issues (town:id) an issue was delegated to,
+// from its delegated:* labels.
+func delegatedRefs(labels []string) []string {
+	var refs []string
+	for _, l := range labels {
+		if ref, ok := strings.CutPrefix(l, delegatedLabelPrefix); ok && federation.IsRef(ref) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// fetchDelegations looks up the current state of delegated peer issues.
+// Peers that can't be reached are reported with status "unreachable".
+func fetchDelegations(townRoot string, refs []string) []federation.IssueView {
+	views := make([]federation.IssueView, 0, len(refs))
+	for _, ref := range refs {
+		town, id, _ := federation.SplitRef(ref)
+		v := &federation.IssueView{ID: id, Status: "unreachable"}
+		if client, err := federation.NewClient(townRoot, town); err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if got, err := client.Issue(ctx, id); err == nil {
+				v = got
+			}
+			cancel()
+		}
+		v.ID = ref
+		views = append(views, *v)
+	}
+	return views
+}
+
+func trackedStatusSymbol(status string) string {
+	switch status {
+	case "closed":
+		return "✓"
+	case "in_progress", "hooked":
+		return "▶"
+	}
+	return "○"
+}
+
+func shortAssignee(assignee string) string {
+	if assignee == "" {
+		return "unassigned"
+	}
+	parts := strings.Split(assignee, "/")
+	return parts[len(parts)-1]
+}
+
+// beadsForID returns a Beads wrapper for the database an issue ID routes to.
+func beadsForID(townRoot, id string) *beads.Beads {
+	beadsDir := beads.ResolveRoutingTarget(townRoot, id, filepath.Join(townRoot, ".beads"))
+	return beads.NewWithBeadsDir(filepath.Dir(beadsDir), beadsDir)
+}
+
+// townFederationBackend serves peer requests from this town's beads and mail.
+type townFederationBackend struct {
+	townRoot string
+}
+
+func (b *townFederationBackend) DeliverMail(_ context.Context, peer string, m *federation.Mail) (string, error) {
+	// Peers mail individual agents, validated the same way gt mail send
+	// validates local recipients; no lists, queues, channels or groups.
+	if !strings.Contains(m.To, "/") || strings.ContainsAny(m.To, "@*") {
+		return "", fmt.Errorf("%w: peers may only mail individual agents", federation.ErrInvalid)
+	}
+	resolver := mail.NewResolver(beads.New(b.townRoot), b.townRoot)
+	recipients, err := resolver.Resolve(m.To)
+	if err != nil {
+		if errors.Is(err, mail.ErrUnknownRecipient) {
+			return "", fmt.Errorf("%w: %v", federation.ErrInvalid, err)
+		}
+		return "", err
+	}
+	if len(recipients) != 1 || recipients[0].Type != mail.RecipientAgent || recipients[0].Address != m.To {
+		return "", fmt.Errorf("%w: peers may only mail individual agents", federation.ErrInvalid)
+	}
+
+	msg := &mail.Message{
+		From:     m.From,
+		To:       m.To,
+		Subject:  m.Subject,
+		Body:     m.Body,
+		ThreadID: m.ThreadID,
+		ReplyTo:  m.ReplyTo,
+		CC:       m.CC,
+	}
+	msg.Priority, msg.Type = federatedMailKind(m.Priority, m.Type)
+
+	router := mail.NewRouterWithTownRoot(b.townRoot, b.townRoot)
+	defer router.WaitPendingNotifications()
+	if err := router.Send(msg); err != nil {
+		return "", err
+	}
+	_ = events.LogAt(b.townRoot, events.TypeMail, m.From, events.MailPayload(m.To, m.Subject))
+	return msg.ID, nil
+}
+
+// federatedMailKind clamps a peer's requested priority and type. Peers
+// can't page agents with high/urgent mail or hand them task or scavenge
+// work; anything else arrives as a normal-priority notification.
+func federatedMailKind(priority, typ string) (mail.Priority, mail.MessageType) {
+	p := mail.PriorityNormal
+	if mail.Priority(priority) == mail.PriorityLow {
+		p = mail.PriorityLow
+	}
+	t := mail.TypeNotification
+	if mail.MessageType(typ) == mail.TypeReply {
+		t = mail.TypeReply
+	}
+	return p, t
+}
+
+func (b *townFederationBackend) Convoy(_ context.Context, peer string, id string) (*federation.ConvoyView, error) {
+	notFound := fmt.Errorf("%w: convoy %s", federation.ErrNotFound, id)
+	convoy, err := beads.New(b.townRoot).Show(id)
+	if err != nil || convoy.Type != "convoy" {
+		return nil, notFound
+	}
+	tracked, err := getTrackedIssues(filepath.Join(b.townRoot, ".beads"), id)
+	if err != nil {
+		return nil, err
+	}
+	delegatedFrom := func(issueID string) bool {
+		d, err := beadsForID(b.townRoot, issueID).GetDelegation(issueID)
+		return err == nil && d != nil && strings.HasPrefix(d.Parent, peer+":")
+	}
+	// Unshared convoys look the same as missing ones, so peers can't probe
+	// for convoy IDs.
+	if !convoySharedWith(peer, convoy.Labels, tracked, delegatedFrom) {
+		return nil, notFound
+	}
+
+	v := &federation.ConvoyView{
+		ID:      convoy.ID,
+		Title:   convoy.Title,
+		Status:  convoy.Status,
+		Tracked: make([]federation.IssueView, 0, len(tracked)),
+		Total:   len(tracked),
+	}
+	for _, t := range tracked {
+		if t.Status == "closed" {
+			v.Completed++
+		}
+		v.Tracked = append(v.Tracked, federation.IssueView{
+			ID:       t.ID,
+			Title:    t.Title,
+			Status:   t.Status,
+			Assignee: t.Assignee,
+		})
+	}
+	return v, nil
+}
+
+// convoySharedWith reports whether a peer may view a convoy: it carries a
+// shared:<peer> label, or tracks an issue delegated to or from that peer.
+func convoySharedWith(peer string, labels []string, tracked []trackedIssueInfo, delegatedFrom func(id string) bool) bool {
+	if slices.Contains(labels, sharedLabelPrefix+peer) {
+		return true
+	}
+	for _, t := range tracked {
+		for _, ref := range delegatedRefs(t.Labels) {
+			if town, _, _ := federation.SplitRef(ref); town == peer {
+				return true
+			}
+		}
+	}
+	for _, t := range tracked {
+		if delegatedFrom(t.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *townFederationBackend) Issue(_ context.Context, peer, id string) (*federation.IssueView, error) {
+	notFound := fmt.Errorf("%w: issue %s", federation.ErrNotFound, id)
+	bd := beadsForID(b.townRoot, id)
+	d, err := bd.GetDelegation(id)
+	if err != nil || d == nil || !strings.HasPrefix(d.Parent, peer+":") {
+		return nil, notFound
+	}
+	issue, err := bd.Show(id)
+	if err != nil {
+		return nil, notFound
+	}
+	return &federation.IssueView{
+		ID:            issue.ID,
+		Title:         issue.Title,
+		Status:        issue.Status,
+		Assignee:      issue.Assignee,
+		DelegatedFrom: d.Parent,
+	}, nil
+}
+
+func (b *townFederationBackend) Delegate(_ context.Context, peer string, req *federation.DelegateRequest) (string, error) {
+	rigs, err := config.LoadRigsConfig(filepath.Join(b.townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return "", err
+	}
+	if _, ok := rigs.Rigs[req.Rig]; !ok {
+		return "", fmt.Errorf("%w: rig %s", federation.ErrNotFound, req.Rig)
+	}
+	if federation.IsAddress(req.DelegatedTo) {
+		return "", fmt.Errorf("%w: delegated_to must be an agent in this town", federation.ErrInvalid)
+	}
+
+	parent := peer + ":" + req.Parent
+	by := peer + ":" + req.DelegatedBy
+	to := req.DelegatedTo
+	if to == "" {
+		to = req.Rig + "/"
+	}
+
+	description := req.Description
+	if description != "" {
+		description += "\n\n"
+	}
+	description += fmt.Sprintf("Delegated by %s from %s.", by, parent)
+
+	bd := beads.New(filepath.Join(b.townRoot, req.Rig))
+	child, err := bd.Create(beads.CreateOptions{
+		Title:       req.Title,
+		Labels:      []string{"gt:task", "gt:federated"},
+		Priority:    2,
+		Description: description,
+		Actor:       by,
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating delegated issue: %w", err)
+	}
+	if err := bd.AddDelegation(&beads.Delegation{
+		Parent:      parent,
+		Child:       child.ID,
+		DelegatedBy: by,
+		DelegatedTo: to,
+		Terms:       req.Terms,
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		return "", fmt.Errorf("recording delegation on %s: %w", child.ID, err)
+	}
+	return child.ID, nil
+}
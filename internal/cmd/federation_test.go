@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/federation"
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+func TestDelegatedRefs(t *testing.T) {
+	labels := []string{
+		"gt:task",
+		"delegated:bartertown:bt-abc",
+		"delegated:not-a-ref",
+		"delegated:thunderdome:td-1",
+	}
+	got := delegatedRefs(labels)
+	want := []string{"bartertown:bt-abc", "thunderdome:td-1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("delegatedRefs() = %v, want %v", got, want)
+	}
+}
+
+func TestFederationDeliverMailRejectsFanOut(t *testing.T) {
+	b := &townFederationBackend{townRoot: t.TempDir()}
+	for _, to := range []string{"list:oncall", "@town", "gastown/*", "mayor"} {
+		t.Run(to, func(t *testing.T) {
+			_, err := b.DeliverMail(context.Background(), "bartertown",
+				&federation.Mail{From: "bartertown:mayor/", To: to, Subject: "hi"})
+			if !errors.Is(err, federation.ErrInvalid) {
+				t.Errorf("DeliverMail(%q) error = %v, want ErrInvalid", to, err)
+			}
+		})
+	}
+}
+
+func TestFederatedMailKind(t *testing.T) {
+	tests := []struct {
+		priority, typ string
+		wantPriority  mail.Priority
+		wantType      mail.MessageType
+	}{
+		{"", "", mail.PriorityNormal, mail.TypeNotification},
+		{"low", "reply", mail.PriorityLow, mail.TypeReply},
+		{"urgent", "task", mail.PriorityNormal, mail.TypeNotification},
+		{"high", "scavenge", mail.PriorityNormal, mail.TypeNotification},
+		{"bogus", "bogus", mail.PriorityNormal, mail.TypeNotification},
+	}
+	for _, tt := range tests {
+		p, typ := federatedMailKind(tt.priority, tt.typ)
+		if p != tt.wantPriority || typ != tt.wantType {
+			t.Errorf("federatedMailKind(%q, %q) = %q, %q; want %q, %q",
+				tt.priority, tt.typ, p, typ, tt.wantPriority, tt.wantType)
+		}
+	}
+}
+
+func TestConvoySharedWith(t *testing.T) {
+	tracked := []trackedIssueInfo{
+		{ID: "gt-a", Labels: []string{"delegated:thunderdome:td-1"}},
+		{ID: "gt-b"},
+	}
+	never := func(string) bool { return false }
+
+	if convoySharedWith("bartertown", nil, tracked, never) {
+		t.Error("convoy with no link to bartertown should not be shared")
+	}
+	if !convoySharedWith("thunderdome", nil, tracked, never) {
+		t.Error("convoy tracking an issue delegated to thunderdome should be shared with it")
+	}
+	if !convoySharedWith("bartertown", []string{"shared:bartertown"}, tracked, never) {
+		t.Error("convoy labeled shared:bartertown should be shared with it")
+	}
+	from := func(id string) bool { return id == "gt-b" }
+	if !convoySharedWith("bartertown", nil, tracked, from) {
+		t.Error("convoy tracking an issue delegated from bartertown should be shared with it")
+	}
+}
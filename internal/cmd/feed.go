@@ -8,6 +8,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/logquery"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/tui/feed"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -21,6 +22,7 @@ var (
 	feedMol      string
 	feedType     string
 	feedRig      string
+	feedQuery    string
 	feedNoFollow bool
 	feedWindow   bool
 	feedPlain    bool
@@ -37,6 +39,7 @@ func init() {
 	feedCmd.Flags().StringVar(&feedMol, "mol", "", "Filter by molecule/issue ID prefix")
 	feedCmd.Flags().StringVar(&feedType, "type", "", "Filter by event type (create, update, delete, comment)")
 	feedCmd.Flags().StringVar(&feedRig, "rig", "", "Filter events by rig name")
+	feedCmd.Flags().StringVarP(&feedQuery, "query", "q", "", "Filter events with a log query (see 'gt log query --help')")
 	feedCmd.Flags().BoolVarP(&feedWindow, "window", "w", false, "Open in dedicated tmux window (creates 'feed' window)")
 	feedCmd.Flags().BoolVar(&feedPlain, "plain", false, "Use plain text output (bd activity) instead of TUI")
 	feedCmd.Flags().BoolVarP(&feedProblems, "problems", "p", false, "Start in problems view (shows stuck agents)")
@@ -68,6 +71,12 @@ The feed combines multiple event sources:
 
 Use --plain for simple text output (reads .events.jsonl directly).
 
+Query Filter:
+  Press 'f' in the activity view to filter the event stream with a log
+  query (same syntax as 'gt log query', e.g. type=sling rig=gastown).
+  The filter is saved in .runtime/feed-filter.json and restored the next
+  time the feed starts; press esc to clear it. --query overrides it.
+
 Tmux Integration:
   Use --window to open the feed in a dedicated tmux window named 'feed'.
   This creates a persistent window you can cycle to with C-b n/p.
@@ -103,7 +112,8 @@ Examples:
   gt feed --plain               # Plain text output (bd activity)
   gt feed --window              # Open in dedicated tmux window
   gt feed --since 1h            # Events from last hour
-  gt feed --rig greenplace      # Use gastown rig's beads`,
+  gt feed --rig greenplace      # Use gastown rig's beads
+  gt feed -q 'type=done or type=crash'  # Only completions and crashes`,
 	RunE: runFeed,
 }
 
@@ -114,6 +124,13 @@ func runFeed(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not in a Gas Town workspace (run from ~/gt or a rig directory)")
 	}
 
+	// Validate --query up front so every mode reports bad syntax the same way
+	if feedQuery != "" {
+		if _, err := logquery.Parse(feedQuery); err != nil {
+			return fmt.Errorf("invalid --query: %w", err)
+		}
+	}
+
 	// Build feed arguments for window mode
 	bdArgs := buildFeedArgs()
 
@@ -200,6 +217,10 @@ func buildFeedArgs() []string {
 		args = append(args, "--rig", feedRig)
 	}
 
+	if feedQuery != "" {
+		args = append(args, "--query", feedQuery)
+	}
+
 	return args
 }
 
@@ -225,6 +246,13 @@ func runFeedDirect(townRoot string) error {
 		Type:   feedType,
 		Rig:    feedRig,
 	}
+	if feedQuery != "" {
+		q, err := logquery.Parse(feedQuery)
+		if err != nil {
+			return fmt.Errorf("invalid --query: %w", err)
+		}
+		opts.Query = q
+	}
 
 	return feed.PrintGtEvents(townRoot, opts)
 }
@@ -278,6 +306,18 @@ func runFeedTUI(workDir string, problemsView bool) error {
 	m.SetEventChannel(multiSource.Events())
 	m.SetTownRoot(townRoot)
 
+	// --query wins over the filter saved from a previous session
+	filter := feedQuery
+	if filter == "" {
+		filter, err = feed.LoadSavedFilter(townRoot)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: ignoring saved feed filter: %v\n", err)
+		}
+	}
+	if err := m.SetFilter(filter); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring invalid feed filter %q: %v\n", filter, err)
+	}
+
 	// Run the TUI
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
@@ -3,12 +3,22 @@ package cmd
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/memory"
 	"github.com/steveyegge/gastown/internal/style"
 )
 
+var (
+	forgetScope   string
+	forgetExpired bool
+)
+
 func init() {
+	forgetCmd.Flags().StringVar(&forgetScope, "scope", "",
+		"Scope of the memory when <key> is a bare slug (town, rig, role, agent, or kind:<name>)")
+	forgetCmd.Flags().BoolVar(&forgetExpired, "expired", false, "Remove every expired memory")
 	forgetCmd.GroupID = GroupWork
 	rootCmd.AddCommand(forgetCmd)
 }
@@ -19,20 +29,38 @@ var forgetCmd = &cobra.Command{
 	Long: `Remove a memory from the beads key-value store.
 
 The key should match the short name shown by 'gt memories'
-(without the memory. prefix).
+(without the memory. prefix), e.g. rig-gastown.dolt-port. Alternatively
+pass the bare slug with --scope.
 
 Examples:
   gt forget refinery-worktree
-  gt forget hooks-package-structure`,
-	Args: cobra.ExactArgs(1),
+  gt forget rig-gastown.dolt-port
+  gt forget dolt-port --scope rig
+  gt forget --expired`,
 	RunE: runForget,
 }
 
 func runForget(cmd *cobra.Command, args []string) error {
+	if forgetExpired {
+		if len(args) > 0 {
+			return fmt.Errorf("--expired takes no key")
+		}
+		return forgetExpiredMemories()
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("requires a memory key (or --expired)")
+	}
 	key := args[0]
 
 	// Strip memory. prefix if the user included it
 	key = strings.TrimPrefix(key, memoryKeyPrefix)
+	if forgetScope != "" {
+		scope, err := resolveMemoryScope(forgetScope)
+		if err != nil {
+			return err
+		}
+		key = memory.Key(scope, key)
+	}
 
 	fullKey := memoryKeyPrefix + key
 
@@ -49,3 +77,24 @@ func runForget(cmd *cobra.Command, args []string) error {
 	fmt.Printf("%s Forgot memory: %s\n", style.Success.Render("✓"), style.Bold.Render(key))
 	return nil
 }
+
+// forgetExpiredMemories removes every memory past its expiry.
+func forgetExpiredMemories() error {
+	mems, err := loadMemories()
+	if err != nil {
+		return fmt.Errorf("listing memories: %w", err)
+	}
+	now := time.Now()
+	removed := 0
+	for _, m := range mems {
+		if !m.Expired(now) {
+			continue
+		}
+		if err := bdKvClear(memoryKeyPrefix + m.Key); err != nil {
+			return fmt.Errorf("removing memory %s: %w", m.Key, err)
+		}
+		removed++
+	}
+	fmt.Printf("%s Removed %d expired memories\n", style.Success.Render("✓"), removed)
+	return nil
+}
@@ -707,6 +707,28 @@ func parseFormulaFile(path string) (*formula.Formula, error) {
 	return formula.ParseFile(path)
 }
 
+// loadFormulaByName resolves a formula the way bd does: a file in one of
+// the .beads/formulas search paths, falling back to the formulas embedded
+// in gt.
+func loadFormulaByName(name string) (*formula.Formula, error) {
+	if path, err := findFormulaFile(name); err == nil {
+		f, err := parseFormulaFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("parsing formula %s: %w", name, err)
+		}
+		return f, nil
+	}
+	content, err := formula.GetEmbeddedFormulaContent(name)
+	if err != nil {
+		return nil, fmt.Errorf("loading formula %s: %w", name, err)
+	}
+	f, err := formula.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("parsing formula %s: %w", name, err)
+	}
+	return f, nil
+}
+
 // renderTemplate renders a Go text/template with the given context map
 func renderTemplate(tmplText string, ctx map[string]interface{}) (string, error) {
 	tmpl, err := template.New("prompt").Parse(tmplText)
@@ -52,6 +52,17 @@ The --collect (-c) flag gathers current state (hooked work, inbox, ready beads,
 in-progress items) and includes it in the handoff mail. This provides context
 for the next session without manual summarization.
 
+Structured handoffs have Goal, State, Decisions, Open Questions, Next Steps
+and References sections. --template prints one pre-filled with the hooked
+bead, branch and unpushed commits; fill it in and hand off with --stdin:
+
+  gt handoff --template > /tmp/handoff.md
+  # edit /tmp/handoff.md
+  gt handoff --stdin < /tmp/handoff.md
+
+Empty sections produce a warning, and gt prime and gt resume render the
+sections for the successor.
+
 The --cycle flag triggers automatic session cycling (used by PreCompact hooks).
 Unlike --auto (state only) or normal handoff (polecat→gt-done redirect), --cycle
 always does a full respawn regardless of role. This enables crew workers and
@@ -73,6 +84,7 @@ var (
 	handoffCycle      bool
 	handoffReason     string
 	handoffNoGitCheck bool
+	handoffTemplate   bool
 )
 
 func init() {
@@ -86,10 +98,16 @@ func init() {
 	handoffCmd.Flags().BoolVar(&handoffCycle, "cycle", false, "Auto-cycle session (for PreCompact hooks that want full session replacement)")
 	handoffCmd.Flags().StringVar(&handoffReason, "reason", "", "Reason for handoff (e.g., 'compaction', 'idle')")
 	handoffCmd.Flags().BoolVar(&handoffNoGitCheck, "no-git-check", false, "Skip git workspace cleanliness check")
+	handoffCmd.Flags().BoolVar(&handoffTemplate, "template", false, "Print a structured handoff template to fill in and pass back with --stdin")
 	rootCmd.AddCommand(handoffCmd)
 }
 
 func runHandoff(cmd *cobra.Command, args []string) error {
+	if handoffTemplate {
+		fmt.Print(buildHandoffTemplate())
+		return nil
+	}
+
 	// Handle --stdin: read message body from stdin (avoids shell quoting issues)
 	if handoffStdin {
 		if handoffMessage != "" {
@@ -159,6 +177,9 @@ func runHandoff(cmd *cobra.Command, args []string) error {
 	// it can hand off immediately and the daemon respawns, creating a crash loop.
 	enforceHandoffCooldown()
 
+	// Warn about empty sections before any collected state is appended.
+	validateHandoffMessage(handoffMessage)
+
 	// If --collect flag is set, auto-collect state into the message
 	if handoffCollect {
 		collected := collectHandoffState()
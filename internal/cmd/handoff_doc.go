@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/cli"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// buildHandoffTemplate returns a structured handoff template pre-filled with
+// what can be discovered: the hooked bead as goal and reference, the branch
+// and workspace state, and unpushed commits as references.
+func buildHandoffTemplate() string {
+	doc := &beads.HandoffDoc{}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return beads.HandoffTemplate(doc)
+	}
+
+	if townRoot, err := workspace.FindFromCwd(); err == nil && townRoot != "" {
+		if roleInfo, err := GetRoleWithContext(cwd, townRoot); err == nil {
+			if id := detectHookedBead(cwd, roleInfo); id != "" {
+				ref := beads.HandoffRef{Kind: "bead", ID: id, Note: "hooked work"}
+				if issue, err := beads.New(cwd).Show(id); err == nil && issue.Title != "" {
+					doc.Goal = fmt.Sprintf("%s (%s)", issue.Title, id)
+					ref.Note = issue.Title
+				}
+				doc.References = append(doc.References, ref)
+			}
+		}
+	}
+
+	g := git.NewGit(cwd)
+	if g.IsRepo() {
+		var state []string
+		if branch, err := g.CurrentBranch(); err == nil && branch != "" {
+			state = append(state, "Branch: "+branch)
+		}
+		if work, err := g.CheckUncommittedWork(); err == nil && work.HasUncommittedChanges {
+			state = append(state, fmt.Sprintf("Uncommitted: %d modified, %d untracked",
+				len(work.ModifiedFiles), len(work.UntrackedFiles)))
+		}
+		doc.State = strings.Join(state, "\n")
+		doc.References = append(doc.References, unpushedCommitRefs(cwd)...)
+	}
+
+	return beads.HandoffTemplate(doc)
+}
+
+// unpushedCommitRefs lists commits not yet on the upstream branch, newest
+// first, capped at ten.
+func unpushedCommitRefs(dir string) []beads.HandoffRef {
+	out, err := exec.Command("git", "-C", dir, "log", "--format=%h %s", "-10", "@{u}..HEAD").Output()
+	if err != nil {
+		return nil
+	}
+	var refs []beads.HandoffRef
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		sha, subject, _ := strings.Cut(line, " ")
+		if sha != "" {
+			refs = append(refs, beads.HandoffRef{Kind: "commit", ID: sha, Note: subject})
+		}
+	}
+	return refs
+}
+
+// validateHandoffMessage warns about gaps in a handoff body: empty sections
+// of a structured handoff, or a free-form one that could be structured.
+// Returns the warnings printed, for tests.
+func validateHandoffMessage(message string) []string {
+	if strings.TrimSpace(message) == "" {
+		return nil
+	}
+	doc, ok := beads.ParseHandoffDoc(message)
+	if !ok {
+		fmt.Printf("%s Free-form handoff. For goal/state/decisions/next steps, use: %s handoff --template\n",
+			style.Dim.Render("💡"), cli.Name())
+		return nil
+	}
+	var warnings []string
+	for _, name := range doc.EmptySections() {
+		w := fmt.Sprintf("handoff section %q is empty", name)
+		style.PrintWarning("%s", w)
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
+
+// printHandoffBody prints a handoff body, rendering structured handoffs
+// section by section and free-form ones as-is.
+func printHandoffBody(body string) {
+	doc, ok := beads.ParseHandoffDoc(body)
+	if !ok {
+		fmt.Println(body)
+		return
+	}
+	printHandoffDoc(doc)
+}
+
+// printHandoffDoc prints a structured handoff for a successor session.
+// Empty sections are shown as such so the gap is visible.
+func printHandoffDoc(doc *beads.HandoffDoc) {
+	empty := map[string]bool{}
+	for _, name := range doc.EmptySections() {
+		empty[name] = true
+	}
+	for i, name := range beads.HandoffSections {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(style.Bold.Render("### " + name))
+		if empty[name] {
+			fmt.Println(style.Dim.Render("(not recorded)"))
+			continue
+		}
+		switch name {
+		case beads.HandoffGoal:
+			fmt.Println(doc.Goal)
+		case beads.HandoffState:
+			fmt.Println(doc.State)
+		case beads.HandoffDecisions:
+			printHandoffItems(doc.Decisions, false)
+		case beads.HandoffOpenQuestions:
+			printHandoffItems(doc.OpenQuestions, false)
+		case beads.HandoffNextSteps:
+			printHandoffItems(doc.NextSteps, true)
+		case beads.HandoffReferences:
+			for _, ref := range doc.References {
+				fmt.Printf("- %s\n", ref)
+			}
+		}
+	}
+	if doc.Notes != "" {
+		fmt.Println()
+		fmt.Println(style.Bold.Render("### Notes"))
+		fmt.Println(doc.Notes)
+	}
+}
+
+func printHandoffItems(items []string, numbered bool) {
+	for i, item := range items {
+		if numbered {
+			fmt.Printf("%d. %s\n", i+1, item)
+		} else {
+			fmt.Printf("- %s\n", item)
+		}
+	}
+}
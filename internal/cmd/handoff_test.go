@@ -859,3 +859,64 @@ func TestEnforceHandoffCooldown(t *testing.T) {
 		}
 	})
 }
+
+func TestValidateHandoffMessage(t *testing.T) {
+	if w := validateHandoffMessage(""); len(w) != 0 {
+		t.Errorf("empty message: warnings %v", w)
+	}
+	if w := validateHandoffMessage("Context cycling. Check bd ready."); len(w) != 0 {
+		t.Errorf("free-form message: warnings %v", w)
+	}
+
+	msg := "## Goal\nShip it\n\n## State\nDone locally\n\n## Next Steps\n1. Push\n"
+	w := validateHandoffMessage(msg)
+	want := []string{
+		`handoff section "Decisions" is empty`,
+		`handoff section "Open Questions" is empty`,
+		`handoff section "References" is empty`,
+	}
+	if strings.Join(w, "|") != strings.Join(want, "|") {
+		t.Errorf("warnings = %q, want %q", w, want)
+	}
+}
+
+func TestBuildHandoffTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, args := range [][]string{
+		{"git", "init", "-b", "work"},
+		{"git", "config", "user.email", "test@test.com"},
+		{"git", "config", "user.name", "Test"},
+		{"git", "commit", "--allow-empty", "-m", "initial commit"},
+	} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = tmpDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v failed: %s", args, out)
+		}
+	}
+	t.Chdir(tmpDir)
+
+	tmpl := buildHandoffTemplate()
+	if !strings.Contains(tmpl, "## State\nBranch: work\n") {
+		t.Errorf("template State not pre-filled with branch:\n%s", tmpl)
+	}
+	if !strings.Contains(tmpl, "## Next Steps\n<!--") {
+		t.Errorf("template missing Next Steps hint:\n%s", tmpl)
+	}
+}
+
+func TestPrintHandoffBody(t *testing.T) {
+	out := capturePrimeOutput(func() {
+		printHandoffBody("## Goal\nShip it\n\n## References\n- gt-abc12\n")
+	})
+	for _, want := range []string{"### Goal", "Ship it", "### Next Steps", "(not recorded)", "- bead: gt-abc12"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("structured output missing %q:\n%s", want, out)
+		}
+	}
+
+	out = capturePrimeOutput(func() { printHandoffBody("plain notes") })
+	if out != "plain notes\n" {
+		t.Errorf("free-form output = %q", out)
+	}
+}
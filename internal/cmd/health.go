@@ -129,7 +129,7 @@ func runHealth(cmd *cobra.Command, args []string) error {
 	report.Backups = checkBackupHealth(townRoot)
 
 	// 5. Processes
-	report.Processes = checkProcessHealth(report.Server.Port)
+	report.Processes = checkProcessHealth(append(doltserver.ExpectedPorts(townRoot), report.Server.Port))
 
 	// 6. Orphans
 	report.Orphans = checkOrphanDBs(townRoot)
@@ -302,10 +302,11 @@ func checkBackupHealth(townRoot string) *BackupHealth {
 	return bh
 }
 
-// checkProcessHealth finds zombie Dolt servers (not on the expected port).
+// checkProcessHealth finds zombie Dolt servers (not on an expected port:
+// the primary's, or the hot-standby replica's and its replication ports).
 // Uses lsof-based port discovery instead of pgrep/ps string matching (ZFC fix: gt-fj87).
-func checkProcessHealth(expectedPort int) *ProcessHealth {
-	result := health.FindZombieServers([]int{expectedPort})
+func checkProcessHealth(expectedPorts []int) *ProcessHealth {
+	result := health.FindZombieServers(expectedPorts)
 	return &ProcessHealth{
 		ZombieCount: result.Count,
 		ZombiePIDs:  result.PIDs,
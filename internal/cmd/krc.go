@@ -298,7 +298,7 @@ func runKrcPrune(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("pruning: %w", err)
 	}
 
-	if result.EventsPruned == 0 {
+	if result.EventsPruned == 0 && result.RecordingsPruned == 0 {
 		fmt.Println("No expired events to prune.")
 		return nil
 	}
@@ -307,6 +307,9 @@ func runKrcPrune(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Events processed: %d\n", result.EventsProcessed)
 	fmt.Printf("  Events pruned:    %d\n", result.EventsPruned)
 	fmt.Printf("  Events retained:  %d\n", result.EventsRetained)
+	if result.RecordingsPruned > 0 {
+		fmt.Printf("  Recordings:       %d removed\n", result.RecordingsPruned)
+	}
 	fmt.Printf("  Space saved:      %s\n", formatBytes(result.BytesBefore-result.BytesAfter))
 	fmt.Printf("  Duration:         %s\n", result.Duration.Round(time.Millisecond))
 
@@ -490,7 +493,7 @@ func runKrcAutoPrune(townRoot string, config *krc.Config) error {
 		return nil
 	}
 
-	if result.EventsPruned == 0 {
+	if result.EventsPruned == 0 && result.RecordingsPruned == 0 {
 		fmt.Printf("%s Auto-prune ran: no expired events\n", style.Dim.Render("○"))
 		return nil
 	}
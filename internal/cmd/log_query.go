@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/logquery"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var logQueryJSON bool
+
+var logQueryCmd = &cobra.Command{
+	Use:   "query <query>",
+	Short: "Query the town log and events file",
+	Long: `Run a query over logs/town.log and .events.jsonl as one time-ordered stream.
+
+A query is a filter followed by optional pipeline stages:
+
+  FIELD=VALUE    equal (glob when VALUE contains * or ?)
+  FIELD!=VALUE   not equal
+  FIELD~VALUE    contains (case-insensitive); !~ negates
+  FIELD<VALUE    ordered comparison (also <=, >, >=)
+  since=DUR      events newer than DUR ago (90m, 2h, 7d) or a date
+  until=DUR      events older than DUR ago or a date
+
+Fields: ts, source (town|events), type, actor, rig, message, visibility.
+Any other name reads the event payload (e.g. bead, payload.target).
+Predicates are ANDed; use and/or/not and parentheses to combine.
+
+Stages:
+  | count              count matching events
+  | count by F1,F2     count grouped by fields, largest first
+  | head N / tail N    first or last N results
+
+Examples:
+  gt log query 'type=crash since=24h'
+  gt log query 'actor=gastown/polecats/* (type=done or type=crash) | tail 20'
+  gt log query 'since=7d | count by type'
+  gt log query 'source=events type=sling | count by rig, actor | head 10'`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLogQuery,
+}
+
+func init() {
+	logQueryCmd.Flags().BoolVar(&logQueryJSON, "json", false, "Output as JSON")
+	logCmd.AddCommand(logQueryCmd)
+}
+
+// logQueryRecord is the JSON form of a matching record.
+type logQueryRecord struct {
+	Time    string                 `json:"ts"`
+	Source  string                 `json:"source"`
+	Type    string                 `json:"type"`
+	Actor   string                 `json:"actor,omitempty"`
+	Rig     string                 `json:"rig,omitempty"`
+	Message string                 `json:"message,omitempty"`
+	Payload map[string]interface{} `json:"payload,omitempty"`
+}
+
+func runLogQuery(cmd *cobra.Command, args []string) error {
+	q, err := logquery.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	it, closeLogs, err := logquery.OpenTown(townRoot)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = closeLogs() }()
+
+	var enc *json.Encoder
+	if logQueryJSON {
+		enc = json.NewEncoder(os.Stdout)
+	}
+	matched := 0
+	agg, err := q.Exec(it, func(r logquery.Record) error {
+		matched++
+		if enc != nil {
+			return enc.Encode(logQueryRecord{
+				Time:    r.Time.Format(time.RFC3339),
+				Source:  r.Source,
+				Type:    r.Type,
+				Actor:   r.Actor,
+				Rig:     r.Rig,
+				Message: r.Message,
+				Payload: r.Payload,
+			})
+		}
+		printQueryRecord(r)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("reading logs: %w", err)
+	}
+
+	if agg != nil {
+		return printQueryAggregate(agg)
+	}
+	if matched == 0 && !logQueryJSON {
+		fmt.Printf("%s No events match query\n", style.Dim.Render("○"))
+	}
+	return nil
+}
+
+// printQueryRecord prints one record in town.log style.
+func printQueryRecord(r logquery.Record) {
+	ts := r.Time.Local().Format("2006-01-02 15:04:05")
+	detail := r.Message
+	if detail == "" && len(r.Payload) > 0 {
+		detail = formatQueryPayload(r.Payload)
+	}
+	fmt.Printf("%s [%s] %s %s\n", style.Dim.Render(ts), r.Type, r.Actor, detail)
+}
+
+// formatQueryPayload renders a payload as sorted key=value pairs.
+func formatQueryPayload(payload map[string]interface{}) string {
+	keys := make([]string, 0, len(payload))
+	for k := range payload {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, payload[k]))
+	}
+	return style.Dim.Render(strings.Join(parts, " "))
+}
+
+// printQueryAggregate prints count results as a table or JSON.
+func printQueryAggregate(agg *logquery.Aggregate) error {
+	if logQueryJSON {
+		type group struct {
+			Keys  map[string]string `json:"keys,omitempty"`
+			Count int               `json:"count"`
+		}
+		out := struct {
+			Total  int     `json:"total"`
+			Groups []group `json:"groups"`
+		}{Total: agg.Total, Groups: []group{}}
+		for _, g := range agg.Groups {
+			row := group{Count: g.Count}
+			if len(agg.By) > 0 {
+				row.Keys = make(map[string]string, len(agg.By))
+				for i, f := range agg.By {
+					row.Keys[f] = g.Keys[i]
+				}
+			}
+			out.Groups = append(out.Groups, row)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	if len(agg.By) == 0 {
+		fmt.Println(agg.Total)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "COUNT\t%s\n", strings.ToUpper(strings.Join(agg.By, "\t")))
+	for _, g := range agg.Groups {
+		keys := make([]string, len(g.Keys))
+		for i, k := range g.Keys {
+			if k == "" {
+				k = "-"
+			}
+			keys[i] = k
+		}
+		fmt.Fprintf(w, "%d\t%s\n", g.Count, strings.Join(keys, "\t"))
+	}
+	return w.Flush()
+}
@@ -1,73 +1,128 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
-	"sort"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/memory"
 	"github.com/steveyegge/gastown/internal/style"
 )
 
+var (
+	memoriesScope   string
+	memoriesMine    bool
+	memoriesExpired bool
+	memoriesJSON    bool
+)
+
 func init() {
+	memoriesCmd.Flags().StringVar(&memoriesScope, "scope", "",
+		"Only this scope: town, rig, role, agent (yours), or rig:<name>, role:<role>, agent:<identity>")
+	memoriesCmd.Flags().BoolVar(&memoriesMine, "mine", false, "Only memories visible to you")
+	memoriesCmd.Flags().BoolVar(&memoriesExpired, "expired", false, "Include expired memories")
+	memoriesCmd.Flags().BoolVar(&memoriesJSON, "json", false, "Output as JSON")
 	memoriesCmd.GroupID = GroupWork
 	rootCmd.AddCommand(memoriesCmd)
 }
 
 var memoriesCmd = &cobra.Command{
-	Use:   "memories [search-term]",
+	Use:   "memories [search-terms...]",
 	Short: "List or search stored memories",
 	Long: `List or search memories stored in the beads key-value store.
 
-Without arguments, lists all memories. With a search term, filters
-memories whose key or value contains the term (case-insensitive).
+Without arguments, lists all memories, newest first. With search terms,
+shows memories matching every term (prefixes match too) in key, content,
+tags, source bead or author, best match first.
 
 Examples:
   gt memories                    # List all memories
   gt memories refinery           # Search for memories about refinery
-  gt memories "worktree"         # Search for worktree-related memories`,
-	Args: cobra.MaximumNArgs(1),
+  gt memories dolt port          # Memories mentioning both
+  gt memories --mine             # What gt prime may inject for you
+  gt memories --scope rig:gastown`,
 	RunE: runMemories,
 }
 
+// memoryJSON is the --json form of a memory.
+type memoryJSON struct {
+	Key       string     `json:"key"`
+	Content   string     `json:"content"`
+	Scope     string     `json:"scope"`
+	Bead      string     `json:"bead,omitempty"`
+	Author    string     `json:"author,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Expired   bool       `json:"expired,omitempty"`
+}
+
 func runMemories(cmd *cobra.Command, args []string) error {
-	kvs, err := bdKvListJSON()
+	all, err := loadMemories()
 	if err != nil {
 		return fmt.Errorf("listing memories: %w", err)
 	}
 
-	var search string
-	if len(args) > 0 {
-		search = strings.ToLower(args[0])
+	var scope *memory.Scope
+	if memoriesScope != "" {
+		s, err := resolveMemoryScope(memoriesScope)
+		if err != nil {
+			return err
+		}
+		scope = &s
 	}
-
-	// Filter for memory.* keys and optional search
-	type memory struct {
-		key   string
-		value string
+	var viewer memory.Viewer
+	if memoriesMine {
+		if viewer, err = currentMemoryViewer(); err != nil {
+			return err
+		}
 	}
-	var memories []memory
 
-	for k, v := range kvs {
-		if !strings.HasPrefix(k, memoryKeyPrefix) {
+	now := time.Now()
+	var filtered []*memory.Memory
+	for _, m := range all {
+		if m.Expired(now) && !memoriesExpired {
+			continue
+		}
+		if scope != nil && m.Scope != *scope {
 			continue
 		}
-		shortKey := strings.TrimPrefix(k, memoryKeyPrefix)
+		if memoriesMine && !m.VisibleTo(viewer) {
+			continue
+		}
+		filtered = append(filtered, m)
+	}
 
-		if search != "" {
-			if !strings.Contains(strings.ToLower(shortKey), search) &&
-				!strings.Contains(strings.ToLower(v), search) {
-				continue
+	search := strings.Join(args, " ")
+	memories := memory.Search(filtered, search)
+
+	if memoriesJSON {
+		out := make([]memoryJSON, 0, len(memories))
+		for _, m := range memories {
+			j := memoryJSON{
+				Key:       m.Key,
+				Content:   m.Content,
+				Scope:     m.Scope.String(),
+				Bead:      m.Bead,
+				Author:    m.Author,
+				Tags:      m.Tags,
+				ExpiresAt: m.ExpiresAt,
+				Expired:   m.Expired(now),
+			}
+			if !m.CreatedAt.IsZero() {
+				created := m.CreatedAt
+				j.CreatedAt = &created
 			}
+			out = append(out, j)
 		}
-
-		memories = append(memories, memory{key: shortKey, value: v})
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
 	}
 
-	sort.Slice(memories, func(i, j int) bool {
-		return memories[i].key < memories[j].key
-	})
-
 	if len(memories) == 0 {
 		if search != "" {
 			fmt.Printf("No memories matching %q\n", search)
@@ -84,10 +139,31 @@ func runMemories(cmd *cobra.Command, args []string) error {
 	fmt.Printf("%s (%d):\n\n", style.Bold.Render(header), len(memories))
 
 	for _, m := range memories {
-		fmt.Printf("  %s\n", style.Bold.Render(m.key))
-		// Wrap long values for readability
-		fmt.Printf("    %s\n\n", m.value)
+		fmt.Printf("  %s %s\n", style.Bold.Render(m.Key), style.Dim.Render(formatMemoryMeta(m, now)))
+		fmt.Printf("    %s\n\n", m.Content)
 	}
 
 	return nil
 }
+
+// formatMemoryMeta renders a memory's scope, provenance and expiry on one line.
+func formatMemoryMeta(m *memory.Memory, now time.Time) string {
+	parts := []string{m.Scope.String()}
+	if m.Author != "" {
+		parts = append(parts, "by "+m.Author)
+	}
+	if m.Bead != "" {
+		parts = append(parts, "from "+m.Bead)
+	}
+	if len(m.Tags) > 0 {
+		parts = append(parts, "#"+strings.Join(m.Tags, " #"))
+	}
+	if m.ExpiresAt != nil {
+		if m.Expired(now) {
+			parts = append(parts, "expired")
+		} else {
+			parts = append(parts, "expires in "+m.ExpiresAt.Sub(now).Round(time.Minute).String())
+		}
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -112,6 +112,11 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 		MoleculeID: moleculeID,
 	}
 
+	// Approval gate steps stay open until a human approves them.
+	if err := checkStepApprovalGate(townRoot, b, moleculeID, step); err != nil {
+		return err
+	}
+
 	// Step 3: Close the step
 	if moleculeStepDryRun {
 		fmt.Printf("[dry-run] Would close step: %s\n", stepID)
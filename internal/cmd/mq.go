@@ -23,6 +23,7 @@ var (
 	mqSubmitEpic      string
 	mqSubmitPriority  int
 	mqSubmitNoCleanup bool
+	mqSubmitOn        string
 
 	// Retry flags
 	mqRetryNow bool
@@ -87,6 +88,7 @@ Auto-detection:
   - Priority: inherited from source issue
 
 Target branch auto-detection:
+  0. If --on is specified: the target of the MR being stacked on
   1. If --epic is specified: target the integration branch for <epic> (using configured template)
   2. If source issue has a parent epic with an integration branch: target it
   3. Otherwise: target main
@@ -101,12 +103,19 @@ Polecat auto-cleanup:
   Use --no-cleanup to disable this behavior (e.g., if you want to submit
   multiple MRs or continue working).
 
+Stacked MRs:
+  --on <mr> submits a branch built on top of another open MR's branch. The
+  new MR depends on the base MR and lands in the same batch or right after
+  it. When the base is reworked or merged, the Refinery rebases the stacked
+  branch onto the new base automatically.
+
 Examples:
   gt mq submit                           # Auto-detect everything + auto-cleanup
   gt mq submit --issue gp-abc            # Explicit issue
   gt mq submit --epic gt-xyz             # Target integration branch explicitly
   gt mq submit --priority 0              # Override priority (P0)
-  gt mq submit --no-cleanup              # Submit without auto-cleanup`,
+  gt mq submit --no-cleanup              # Submit without auto-cleanup
+  gt mq submit --on gt-mr-abc --no-cleanup  # Stack on an open MR`,
 	RunE: runMqSubmit,
 }
 
@@ -308,6 +317,7 @@ func init() {
 	mqSubmitCmd.Flags().StringVar(&mqSubmitEpic, "epic", "", "Target epic's integration branch instead of main")
 	mqSubmitCmd.Flags().IntVarP(&mqSubmitPriority, "priority", "p", -1, "Override priority (0-4, default: inherit from issue)")
 	mqSubmitCmd.Flags().BoolVar(&mqSubmitNoCleanup, "no-cleanup", false, "Don't auto-cleanup after submit (for polecats)")
+	mqSubmitCmd.Flags().StringVar(&mqSubmitOn, "on", "", "Stack on an open MR whose branch this branch builds on")
 
 	// Retry flags
 	mqRetryCmd.Flags().BoolVar(&mqRetryNow, "now", false, "Immediately process instead of waiting for refinery loop")
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	mqFlakesJSON bool
+	mqFlakesAll  bool
+)
+
+var mqFlakesCmd = &cobra.Command{
+	Use:   "flakes <rig>",
+	Short: "List flaky and quarantined gate tests",
+	Long: `List tests the refinery has seen both pass and fail on the same tree.
+
+Gates that set "results" in their config report per-test outcomes, which the
+refinery records per rig. A gate that fails on tests is rerun on the same
+tree (flakes.retries). Each test gets a flakiness score: the fraction of
+trees it ran on where it both passed and failed. A test that always fails,
+or that one MR broke and a later one fixed, scores 0. Tests at or above the
+threshold are quarantined, and a gate whose only failures are tests
+quarantined before it started no longer blocks the merge.
+
+By default only tests with a non-zero score or in quarantine are listed.
+
+Config (rig config.json):
+  "merge_queue": {
+    "gates": {"test": {"cmd": "go test -json ./...", "results": "go-test-json"}},
+    "flakes": {"quarantine": true, "threshold": 0.2, "min_runs": 5}
+  }
+  "results" is "go-test-json" (gate stdout) or "junit:<glob>".
+
+Examples:
+  gt mq flakes gastown
+  gt mq flakes gastown --all --json
+  gt mq flakes unquarantine gastown ./internal/foo.TestRetry`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQFlakes,
+}
+
+var mqFlakesUnquarantineCmd = &cobra.Command{
+	Use:   "unquarantine <rig> <test>...",
+	Short: "Let quarantined tests block merges again",
+	Long: `Let quarantined tests block merges again.
+
+The tests' history is cleared, so they are only quarantined again if they
+keep flaking.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runMQFlakesUnquarantine,
+}
+
+func init() {
+	mqFlakesCmd.Flags().BoolVar(&mqFlakesJSON, "json", false, "Output as JSON")
+	mqFlakesCmd.Flags().BoolVar(&mqFlakesAll, "all", false, "Include tests that have never flaked")
+
+	mqFlakesCmd.AddCommand(mqFlakesUnquarantineCmd)
+	mqCmd.AddCommand(mqFlakesCmd)
+}
+
+// flakeEntry is one test in gt mq flakes output.
+type flakeEntry struct {
+	Test          string    `json:"test"`
+	Gate          string    `json:"gate"`
+	Score         float64   `json:"score"`
+	Runs          int       `json:"runs"`
+	Failures      int       `json:"failures"`
+	Quarantined   bool      `json:"quarantined"`
+	QuarantinedAt time.Time `json:"quarantined_at,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+}
+
+func runMQFlakes(cmd *cobra.Command, args []string) error {
+	_, r, _, err := getRefineryManager(args[0])
+	if err != nil {
+		return err
+	}
+	history, err := refinery.LoadFlakeHistory(r.Path)
+	if err != nil {
+		return err
+	}
+
+	entries := []flakeEntry{}
+	for name, t := range history.Tests {
+		score := t.Score()
+		if !mqFlakesAll && score == 0 && !t.Quarantined {
+			continue
+		}
+		entries = append(entries, flakeEntry{
+			Test:          name,
+			Gate:          t.Gate,
+			Score:         score,
+			Runs:          len(t.Runs),
+			Failures:      t.Failures(),
+			Quarantined:   t.Quarantined,
+			QuarantinedAt: t.QuarantinedAt,
+			Reason:        t.QuarantineReason,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Quarantined != entries[j].Quarantined {
+			return entries[i].Quarantined
+		}
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].Test < entries[j].Test
+	})
+
+	if mqFlakesJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("%s No flaky tests recorded for %s\n", style.Success.Render("✓"), r.Name)
+		return nil
+	}
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("Gate tests for %s:", r.Name)))
+	for _, e := range entries {
+		status := style.Dim.Render("tracking")
+		if e.Quarantined {
+			status = style.Bold.Render("quarantined")
+		}
+		fmt.Printf("  %-12s %.2f  %2d/%-2d failed  %s %s\n", status, e.Score, e.Failures, e.Runs, e.Test, style.Dim.Render("("+e.Gate+")"))
+	}
+	return nil
+}
+
+func runMQFlakesUnquarantine(cmd *cobra.Command, args []string) error {
+	_, r, _, err := getRefineryManager(args[0])
+	if err != nil {
+		return err
+	}
+	history, err := refinery.LoadFlakeHistory(r.Path)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, name := range args[1:] {
+		if !history.Unquarantine(name) {
+			missing = append(missing, name)
+			continue
+		}
+		fmt.Printf("%s Unquarantined %s\n", style.Success.Render("✓"), name)
+	}
+	if err := history.Save(r.Path); err != nil {
+		return fmt.Errorf("saving flake history: %w", err)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("no history for %v", missing)
+	}
+	return nil
+}
@@ -141,6 +141,22 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		return scored[i].score > scored[j].score
 	})
 
+	// Show stacked MRs as a tree under the MR they build on.
+	ids := make([]string, len(scored))
+	parents := make(map[string]string)
+	for i, s := range scored {
+		ids[i] = s.issue.ID
+		if s.fields != nil && s.fields.StackOn != "" {
+			parents[s.issue.ID] = s.fields.StackOn
+		}
+	}
+	order, depths := stackTree(ids, parents)
+	treed := make([]scoredIssue, len(order))
+	for i, idx := range order {
+		treed[i] = scored[idx]
+	}
+	scored = treed
+
 	// Extract filtered issues for JSON output compatibility
 	var filtered []*beads.Issue
 	for _, s := range scored {
@@ -185,15 +201,17 @@ func runMQList(cmd *cobra.Command, args []string) error {
 	// Create styled table - add GIT column when --verify is set
 	table := style.NewTable(buildMQListColumns(mqListVerify)...)
 
-	// Add rows using scored items (already sorted by score)
-	for _, item := range scored {
+	// Add rows using scored items (already sorted by score, stacks as trees)
+	for i, item := range scored {
 		issue := item.issue
 		fields := item.fields
 
 		// Determine display status
 		displayStatus := issue.Status
 		if issue.Status == "open" {
-			if len(issue.BlockedBy) > 0 || issue.BlockedByCount > 0 {
+			if isOnlyStackBlocked(issue, fields) {
+				displayStatus = "stacked"
+			} else if len(issue.BlockedBy) > 0 || issue.BlockedByCount > 0 {
 				displayStatus = "blocked"
 			} else {
 				displayStatus = "ready"
@@ -209,6 +227,8 @@ func runMQList(cmd *cobra.Command, args []string) error {
 			styledStatus = style.Warning.Render("active")
 		case "blocked":
 			styledStatus = style.Dim.Render("blocked")
+		case "stacked":
+			styledStatus = style.Warning.Render("stacked")
 		case "closed":
 			styledStatus = style.Dim.Render("closed")
 		}
@@ -225,6 +245,9 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		if target == "" {
 			target = style.Dim.Render("(unset)")
 		}
+		if depths[i] > 0 {
+			branch = strings.Repeat("  ", depths[i]-1) + "└─ " + branch
+		}
 
 		// Format convoy column
 		convoyDisplay := style.Dim.Render("(none)")
@@ -297,7 +320,7 @@ func runMQList(cmd *cobra.Command, args []string) error {
 	for _, item := range scored {
 		issue := item.issue
 		displayStatus := issue.Status
-		if issue.Status == "open" && (len(issue.BlockedBy) > 0 || issue.BlockedByCount > 0) {
+		if issue.Status == "open" && (len(issue.BlockedBy) > 0 || issue.BlockedByCount > 0) && !isOnlyStackBlocked(issue, item.fields) {
 			displayStatus = "blocked"
 		}
 		if displayStatus == "blocked" && len(issue.BlockedBy) > 0 {
@@ -313,6 +336,62 @@ func runMQList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// isOnlyStackBlocked reports whether an MR's only blocker is the MR it is
+// stacked on. Such an MR is waiting to land with its base, not stuck.
+func isOnlyStackBlocked(issue *beads.Issue, fields *beads.MRFields) bool {
+	if fields == nil || fields.StackOn == "" || len(issue.BlockedBy) == 0 {
+		return false
+	}
+	for _, id := range issue.BlockedBy {
+		if id != fields.StackOn {
+			return false
+		}
+	}
+	return true
+}
+
+// stackTree orders ids (already in queue order) so each stacked MR follows
+// the MR it is stacked on, depth-first, and returns the new order as
+// indexes into ids with each entry's depth in the tree. parents maps an MR
+// to its stack base; bases not in ids are ignored, so the MR is a root.
+func stackTree(ids []string, parents map[string]string) (order, depths []int) {
+	index := make(map[string]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+	children := make(map[int][]int)
+	var roots []int
+	for i, id := range ids {
+		if p, ok := index[parents[id]]; ok && p != i {
+			children[p] = append(children[p], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	visited := make([]bool, len(ids))
+	var walk func(i, depth int)
+	walk = func(i, depth int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		order = append(order, i)
+		depths = append(depths, depth)
+		for _, c := range children[i] {
+			walk(c, depth+1)
+		}
+	}
+	for _, r := range roots {
+		walk(r, 0)
+	}
+	// MRs caught in a stack cycle have no root; list them flat.
+	for i := range ids {
+		walk(i, 0)
+	}
+	return order, depths
+}
+
 // formatMRAge formats the age of an MR from its created_at timestamp.
 func formatMRAge(createdAt string) string {
 	t, err := time.Parse(time.RFC3339, createdAt)
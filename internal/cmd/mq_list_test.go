@@ -1,6 +1,10 @@
 package cmd
 
-import "testing"
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
 
 func TestBuildMQListColumns_IncludesTarget(t *testing.T) {
 	tests := []struct {
@@ -38,3 +42,18 @@ func TestBuildMQListColumns_IncludesTarget(t *testing.T) {
 		})
 	}
 }
+
+func TestStackTree(t *testing.T) {
+	// Queue order: c (stacked on b), x, b (stacked on a), a, y (stacked on an MR not listed).
+	ids := []string{"c", "x", "b", "a", "y"}
+	parents := map[string]string{"c": "b", "b": "a", "y": "gone"}
+
+	order, depths := stackTree(ids, parents)
+	var got []string
+	for i, idx := range order {
+		got = append(got, fmt.Sprintf("%s:%d", ids[idx], depths[i]))
+	}
+	if want := "x:0,a:0,b:1,c:2,y:0"; strings.Join(got, ",") != want {
+		t.Errorf("stackTree = %s, want %s", strings.Join(got, ","), want)
+	}
+}
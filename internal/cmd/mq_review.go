@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	mqReviewMessage string
+	mqReviewStdin   bool
+)
+
+var mqReviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Record pre-merge review verdicts",
+	Long: `Record verdicts for the optional pre-merge review stage.
+
+When a rig enables merge_queue.review in its config.json, the refinery routes
+each MR to a reviewer before merging: a polecat running a different agent
+preset than the author, or a crew member. The MR is blocked until the
+reviewer records a verdict here.
+
+  route            Route MRs not yet reviewed to the reviewer (refinery patrol)
+  approve          Unblock the MR so the refinery can merge it
+  request-changes  Hold the MR and send the feedback to the author;
+                   the MR is reviewed again after 'gt done' resubmits it
+
+Config (rig config.json):
+  "merge_queue": {
+    "review": {"enabled": true, "reviewer": "agent", "agent": "codex"}
+  }
+  "reviewer" is "agent" (default) or "crew/<name>".`,
+	RunE: requireSubcommand,
+}
+
+var mqReviewRouteCmd = &cobra.Command{
+	Use:   "route <rig>",
+	Short: "Route unreviewed merge requests to the reviewer",
+	Long: `Route every open MR that has not been routed for review yet.
+
+The refinery runs this each patrol cycle. Each MR gets one gt:review task,
+blocking it until a verdict is recorded; rerunning after a partial failure
+reuses the recorded task instead of creating another.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMQReviewRoute(args[0])
+	},
+}
+
+var mqReviewApproveCmd = &cobra.Command{
+	Use:   "approve <rig> <mr-id>",
+	Short: "Approve a merge request for merging",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMQReview(args[0], args[1], true)
+	},
+}
+
+var mqReviewRequestChangesCmd = &cobra.Command{
+	Use:   "request-changes <rig> <mr-id>",
+	Short: "Request changes on a merge request",
+	Long: `Request changes on a merge request.
+
+The MR stays out of the merge queue, and the feedback goes to the author's
+witness as a REWORK_REQUEST, which relays it to the polecat.
+
+Examples:
+  gt mq review request-changes gastown gt-mr-abc -m "Add a test for the retry path"
+  cat review.md | gt mq review request-changes gastown gt-mr-abc --stdin`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMQReview(args[0], args[1], false)
+	},
+}
+
+func init() {
+	mqReviewRequestChangesCmd.Flags().StringVarP(&mqReviewMessage, "message", "m", "", "What the author should change (required unless --stdin)")
+	mqReviewRequestChangesCmd.Flags().BoolVar(&mqReviewStdin, "stdin", false, "Read the feedback from stdin")
+
+	mqReviewCmd.AddCommand(mqReviewRouteCmd)
+	mqReviewCmd.AddCommand(mqReviewApproveCmd)
+	mqReviewCmd.AddCommand(mqReviewRequestChangesCmd)
+	mqCmd.AddCommand(mqReviewCmd)
+}
+
+func runMQReview(rigName, mrID string, approve bool) error {
+	if mqReviewStdin {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("reading stdin: %w", err)
+		}
+		mqReviewMessage = strings.TrimRight(string(data), "\n")
+	}
+	if !approve && strings.TrimSpace(mqReviewMessage) == "" {
+		return fmt.Errorf("say what to change with --message/-m or --stdin")
+	}
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return err
+	}
+
+	reviewer := detectSender()
+	if err := eng.RecordReview(mrID, approve, reviewer, mqReviewMessage); err != nil {
+		return err
+	}
+
+	if approve {
+		fmt.Printf("%s Approved %s %s\n", style.Success.Render("✓"), mrID, style.Dim.Render("(refinery will merge it)"))
+	} else {
+		fmt.Printf("%s Changes requested on %s\n", style.Bold.Render("✗"), mrID)
+		fmt.Printf("  %s\n", style.Dim.Render("Feedback sent to the author via the witness"))
+	}
+	return nil
+}
+
+func runMQReviewRoute(rigName string) error {
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return err
+	}
+
+	routed, err := eng.RouteUnreviewed()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Routed %d MR(s) for review\n", style.Success.Render("✓"), routed)
+	return nil
+}
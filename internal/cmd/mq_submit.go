@@ -161,9 +161,25 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 	// Initialize beads for looking up source issue
 	bd := beads.New(cwd)
 
+	// Stacking: the base MR must be open and this branch must build on it.
+	var stack *stackedBase
+	if mqSubmitOn != "" {
+		if mqSubmitEpic != "" {
+			return fmt.Errorf("--on and --epic are mutually exclusive (a stacked MR targets its base's target)")
+		}
+		stack, err = resolveStackBase(bd, g, mqSubmitOn, branch)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Determine target branch
 	target := defaultBranch
-	if mqSubmitEpic != "" {
+	if stack != nil {
+		if stack.Target != "" {
+			target = stack.Target
+		}
+	} else if mqSubmitEpic != "" {
 		// Explicit --epic flag: read stored branch name, fall back to template
 		rigPath := filepath.Join(townRoot, rigName)
 		target = resolveIntegrationBranchName(bd, rigPath, mqSubmitEpic)
@@ -209,6 +225,9 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 	if worker != "" {
 		description += fmt.Sprintf("\nworker: %s", worker)
 	}
+	if stack != nil {
+		description += fmt.Sprintf("\nstack_on: %s\nstack_base: %s", stack.ID, stack.Tip)
+	}
 
 	// Check if MR bead already exists for this branch (idempotency)
 	var mrIssue *beads.Issue
@@ -234,6 +253,14 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("creating merge request bead: %w", err)
 		}
 
+		// The stacked MR waits on its base; the refinery lets it through
+		// together with or after the base.
+		if stack != nil {
+			if err := bd.AddDependency(mrIssue.ID, stack.ID); err != nil {
+				style.PrintWarning("could not make %s depend on %s: %v", mrIssue.ID, stack.ID, err)
+			}
+		}
+
 		// Nudge refinery to pick up the new MR
 		nudgeRefinery(rigName, "MERGE_READY received - check inbox for pending work")
 	}
@@ -243,6 +270,9 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  MR ID: %s\n", style.Bold.Render(mrIssue.ID))
 	fmt.Printf("  Source: %s\n", branch)
 	fmt.Printf("  Target: %s\n", target)
+	if stack != nil {
+		fmt.Printf("  Stacked on: %s (%s)\n", stack.ID, stack.Branch)
+	}
 	fmt.Printf("  Issue: %s\n", issueID)
 	if worker != "" {
 		fmt.Printf("  Worker: %s\n", worker)
@@ -266,6 +296,48 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// stackedBase is the open MR a stacked MR builds on.
+type stackedBase struct {
+	ID     string
+	Branch string
+	Target string
+	Tip    string // Base branch tip the stacked branch contains
+}
+
+// resolveStackBase looks up the MR to stack on and checks that branch
+// contains the MR's branch.
+func resolveStackBase(bd *beads.Beads, g *git.Git, mrID, branch string) (*stackedBase, error) {
+	issue, err := bd.Show(mrID)
+	if err != nil {
+		return nil, fmt.Errorf("looking up MR %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(issue)
+	if !beads.HasLabel(issue, "gt:merge-request") || fields == nil || fields.Branch == "" {
+		return nil, fmt.Errorf("%s is not a merge request", mrID)
+	}
+	if issue.Status != "open" {
+		return nil, fmt.Errorf("MR %s is %s; stack on an open MR or submit against %s", mrID, issue.Status, fields.Target)
+	}
+	if fields.Branch == branch {
+		return nil, fmt.Errorf("cannot stack %s on itself", branch)
+	}
+
+	// The base branch is shared with the refinery through the rig's repo;
+	// fall back to the remote-tracking ref when it isn't local here.
+	baseRef := fields.Branch
+	if ok, _ := g.BranchExists(baseRef); !ok {
+		baseRef = "origin/" + fields.Branch
+	}
+	tip, err := g.Rev(baseRef)
+	if err != nil {
+		return nil, fmt.Errorf("resolving base branch %s: %w", fields.Branch, err)
+	}
+	if ok, err := g.IsAncestor(tip, branch); err != nil || !ok {
+		return nil, fmt.Errorf("%s does not build on %s (branch %s); rebase onto it first", branch, mrID, fields.Branch)
+	}
+	return &stackedBase{ID: issue.ID, Branch: fields.Branch, Target: fields.Target, Tip: tip}, nil
+}
+
 // polecatCleanup sends a lifecycle shutdown request to the witness and waits for termination.
 // This is called after a polecat successfully submits an MR.
 func polecatCleanup(rigName, worker, townRoot string) error {
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+	mqtui "github.com/steveyegge/gastown/internal/tui/mq"
+)
+
+var mqTUICmd = &cobra.Command{
+	Use:   "tui <rig>",
+	Short: "Interactive merge queue dashboard",
+	Long: `Open a live dashboard for a rig's merge queue.
+
+Shows open merge requests in processing order with their scores, the
+refinery's current batch with per-gate progress, and bisection state
+when a batch fails. The view refreshes every few seconds.
+
+Keybindings:
+  j/k, ↑/↓   Move selection
+  r          Retry: release the MR's claim so the refinery picks it up again
+  x          Reject the MR (prompts for a reason, notifies the worker)
+  +/-        Raise or lower the MR's priority (P0–P4)
+  d          View the MR diff against its target branch
+  ctrl+r     Refresh now
+  q          Quit
+
+Examples:
+  gt mq tui greenplace`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQTUI,
+}
+
+func init() {
+	mqCmd.AddCommand(mqTUICmd)
+}
+
+func runMQTUI(cmd *cobra.Command, args []string) error {
+	mgr, r, rigName, err := getRefineryManager(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Manager warnings would corrupt the alt-screen render.
+	mgr.SetOutput(io.Discard)
+	src := &mqTUISource{
+		rig:   r,
+		mgr:   mgr,
+		eng:   refinery.NewEngineer(r),
+		beads: beads.New(r.BeadsPath()),
+	}
+
+	m := mqtui.New(rigName, src)
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	_, err = p.Run()
+	return err
+}
+
+// mqTUISource adapts beads and the refinery package to the merge queue TUI.
+type mqTUISource struct {
+	rig   *rig.Rig
+	mgr   *refinery.Manager
+	eng   *refinery.Engineer
+	beads *beads.Beads
+}
+
+// LoadQueue returns queued and in-flight MRs sorted the way the refinery
+// will process them.
+func (s *mqTUISource) LoadQueue() ([]mqtui.MRItem, error) {
+	var issues []*beads.Issue
+	for _, status := range []string{"open", "in_progress"} {
+		found, err := s.beads.List(beads.ListOptions{
+			Label:    "gt:merge-request",
+			Status:   status,
+			Priority: -1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("querying merge queue: %w", err)
+		}
+		issues = append(issues, found...)
+	}
+
+	now := time.Now()
+	items := make([]mqtui.MRItem, 0, len(issues))
+	for _, issue := range issues {
+		if issue == nil || (issue.Status != "open" && issue.Status != "in_progress") {
+			continue
+		}
+		fields := beads.ParseMRFields(issue)
+		item := mqtui.MRItem{
+			ID:       issue.ID,
+			Priority: issue.Priority,
+			Score:    calculateMRScore(issue, fields, now),
+			Status:   "ready",
+			Age:      formatMRAge(issue.CreatedAt),
+		}
+		if fields != nil {
+			item.Branch = fields.Branch
+			item.Target = fields.Target
+			item.Worker = fields.Worker
+		}
+		if item.Target == "" {
+			item.Target = s.rig.DefaultBranch()
+		}
+		switch {
+		case len(issue.BlockedBy) > 0 || issue.BlockedByCount > 0:
+			item.Status = "blocked"
+			if len(issue.BlockedBy) > 0 {
+				item.BlockedBy = issue.BlockedBy[0]
+			}
+		case issue.Status == "in_progress" || issue.Assignee != "":
+			item.Status = "active"
+		}
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+// LoadBatch returns the refinery's most recently recorded batch.
+func (s *mqTUISource) LoadBatch() (*refinery.BatchState, error) {
+	return refinery.LoadBatchState(s.rig.Path)
+}
+
+// Retry releases the MR's claim so it re-enters the ready queue.
+func (s *mqTUISource) Retry(id string) error {
+	return s.eng.ReleaseMR(id)
+}
+
+// Reject closes the MR as rejected and nudges the worker.
+func (s *mqTUISource) Reject(id, reason string) error {
+	_, err := s.mgr.RejectMR(id, reason, true)
+	return err
+}
+
+// SetPriority updates the MR bead's priority, which feeds queue scoring.
+func (s *mqTUISource) SetPriority(id string, priority int) error {
+	return s.beads.Update(id, beads.UpdateOptions{Priority: &priority})
+}
+
+// DiffCommand returns a git diff of the MR branch against its target,
+// run in the refinery worktree (falling back to mayor/rig like the Engineer).
+func (s *mqTUISource) DiffCommand(item mqtui.MRItem) *exec.Cmd {
+	if item.Branch == "" {
+		return nil
+	}
+	dir := filepath.Join(s.rig.Path, "refinery", "rig")
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		dir = filepath.Join(s.rig.Path, "mayor", "rig")
+	}
+	c := exec.Command("git", "diff", "origin/"+item.Target+"..."+item.Branch) //nolint:gosec // G204: refs come from MR beads
+	c.Dir = dir
+	return c
+}
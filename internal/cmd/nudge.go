@@ -135,6 +135,10 @@ func deliverNudge(t *tmux.Tmux, sessionName, message, sender string) error {
 	// FormatForInjection adds the prefix, so we must NOT double-prefix.
 	prefixedMessage := fmt.Sprintf("[from %s] %s", sender, message)
 
+	// Headless towns deliver through the daemon's PTY supervisor.
+	backend := session.NewBackend(townRoot, t)
+	headless := session.IsHeadless(townRoot)
+
 	switch nudgeModeFlag {
 	case NudgeModeQueue:
 		if townRoot == "" {
@@ -152,11 +156,15 @@ func deliverNudge(t *tmux.Tmux, sessionName, message, sender string) error {
 			// rather than silently degrading to immediate (destructive) delivery.
 			return fmt.Errorf("--mode=wait-idle requires a Gas Town workspace")
 		}
-		// Try to wait for idle
-		err := t.WaitForIdle(sessionName, waitIdleTimeout)
+		// Try to wait for idle. Headless sessions have no idle detection,
+		// so they always take the queue.
+		err := errors.New("idle detection unavailable for headless sessions")
+		if !headless {
+			err = t.WaitForIdle(sessionName, waitIdleTimeout)
+		}
 		if err == nil {
 			// Agent is idle — safe to deliver directly
-			return t.NudgeSession(sessionName, prefixedMessage)
+			return backend.NudgeSession(sessionName, prefixedMessage)
 		}
 		// Terminal errors (session gone, no server) — propagate, don't queue.
 		// Queueing a nudge for a dead session means it will never be delivered.
@@ -172,12 +180,12 @@ func deliverNudge(t *tmux.Tmux, sessionName, message, sender string) error {
 			// Queue failed — fall back to immediate as last resort.
 			// Better to interrupt than lose the message entirely.
 			fmt.Fprintf(os.Stderr, "Warning: queue fallback failed (%v), delivering immediately\n", qErr)
-			return t.NudgeSession(sessionName, prefixedMessage)
+			return backend.NudgeSession(sessionName, prefixedMessage)
 		}
 		return nil
 
 	default: // NudgeModeImmediate
-		return t.NudgeSession(sessionName, prefixedMessage)
+		return backend.NudgeSession(sessionName, prefixedMessage)
 	}
 }
 
@@ -290,6 +298,7 @@ func runNudge(cmd *cobra.Command, args []string) (retErr error) {
 	}
 
 	t := tmux.NewTmux()
+	sessions := session.NewBackend(townRoot, t)
 
 	// Expand role shortcuts to session names
 	// These shortcuts let users type "mayor" instead of "gt-mayor"
@@ -317,7 +326,7 @@ func runNudge(cmd *cobra.Command, args []string) (retErr error) {
 	if target == constants.RoleDeacon {
 		deaconSession := session.DeaconSessionName()
 		// Check if Deacon session exists
-		exists, err := t.HasSession(deaconSession)
+		exists, err := sessions.HasSession(deaconSession)
 		if err != nil {
 			return fmt.Errorf("checking deacon session: %w", err)
 		}
@@ -370,7 +379,7 @@ func runNudge(cmd *cobra.Command, args []string) (retErr error) {
 			// Try crew first (matches mail system's addressToSessionIDs pattern),
 			// then fall back to polecat.
 			crewSession := crewSessionName(rigName, polecatName)
-			if exists, _ := t.HasSession(crewSession); exists {
+			if exists, _ := sessions.HasSession(crewSession); exists {
 				sessionName = crewSession
 			} else {
 				mgr, _, err := getSessionManager(rigName)
@@ -385,7 +394,7 @@ func runNudge(cmd *cobra.Command, args []string) (retErr error) {
 		// Without this, queue mode silently succeeds for nonexistent sessions —
 		// the file is written but never drained.
 		if nudgeModeFlag != NudgeModeImmediate {
-			exists, err := t.HasSession(sessionName)
+			exists, err := sessions.HasSession(sessionName)
 			if err != nil {
 				return fmt.Errorf("checking session: %w", err)
 			}
@@ -408,7 +417,7 @@ func runNudge(cmd *cobra.Command, args []string) (retErr error) {
 		_ = events.LogFeed(events.TypeNudge, sender, events.NudgePayload(rigName, target, message))
 	} else {
 		// Raw session name (legacy)
-		exists, err := t.HasSession(target)
+		exists, err := sessions.HasSession(target)
 		if err != nil {
 			return fmt.Errorf("checking session: %w", err)
 		}
@@ -44,10 +44,18 @@ GATE TYPES:
   event       Run on events (e.g., startup)
   manual      Never auto-run, trigger explicitly
 
+INSTALLING:
+  Plugins with a [package] section can be installed from a git URL or a
+  local tarball. Installs are pinned in plugins/plugins.lock.json so every
+  machine running the town gets the same plugin contents.
+
 Examples:
   gt plugin list                    # List all discovered plugins
   gt plugin show <name>             # Show plugin details
-  gt plugin list --json             # JSON output`,
+  gt plugin list --json             # JSON output
+  gt plugin install <git-url>       # Install and pin a plugin
+  gt plugin install                 # Sync plugins from the lockfile
+  gt plugin config show <name>      # Show config values (secrets masked)`,
 	RunE: requireSubcommand,
 }
 
@@ -257,7 +265,12 @@ func printPluginSummary(p *plugin.Plugin) {
 		desc = desc[:47] + "..."
 	}
 
-	fmt.Printf("    %s %s\n", style.Bold.Render(p.Name), style.Dim.Render(fmt.Sprintf("[%s]", gateType)))
+	tag := gateType
+	if v := p.PackageVersion(); v != "" {
+		tag = fmt.Sprintf("%s, v%s", gateType, strings.TrimPrefix(v, "v"))
+	}
+
+	fmt.Printf("    %s %s\n", style.Bold.Render(p.Name), style.Dim.Render(fmt.Sprintf("[%s]", tag)))
 	if desc != "" {
 		fmt.Printf("      %s\n", style.Dim.Render(desc))
 	}
@@ -306,6 +319,49 @@ func outputPluginShowText(p *plugin.Plugin) error {
 
 	fmt.Printf("%s %d\n", style.Bold.Render("Version:"), p.Version)
 
+	// Package
+	if p.Package != nil {
+		fmt.Println()
+		fmt.Printf("%s\n", style.Bold.Render("Package:"))
+		if p.Package.Version != "" {
+			fmt.Printf("  Version: %s\n", p.Package.Version)
+		}
+		if p.Package.MinGTVersion != "" {
+			fmt.Printf("  Min gt version: %s\n", p.Package.MinGTVersion)
+		}
+		if len(p.Package.Requires) > 0 {
+			fmt.Printf("  Requires: %s\n", strings.Join(p.Package.Requires, ", "))
+		}
+	}
+
+	// Config schema
+	if len(p.Config) > 0 {
+		fmt.Println()
+		fmt.Printf("%s\n", style.Bold.Render("Config:"))
+		for _, key := range p.ConfigKeys() {
+			f := p.Config[key]
+			typ := string(f.Type)
+			if typ == "" {
+				typ = string(plugin.ConfigString)
+			}
+			line := fmt.Sprintf("  %s (%s", key, typ)
+			if f.Required {
+				line += ", required"
+			}
+			if f.Secret {
+				line += ", secret"
+			}
+			if f.Default != "" {
+				line += fmt.Sprintf(", default %q", f.Default)
+			}
+			line += ") $" + f.EnvName(key)
+			if f.Description != "" {
+				line += " " + style.Dim.Render(f.Description)
+			}
+			fmt.Println(line)
+		}
+	}
+
 	// Gate
 	fmt.Println()
 	fmt.Printf("%s\n", style.Bold.Render("Gate:"))
@@ -323,6 +379,18 @@ func outputPluginShowText(p *plugin.Plugin) error {
 		if p.Gate.On != "" {
 			fmt.Printf("  On: %s\n", p.Gate.On)
 		}
+		if p.Gate.Rig != "" {
+			fmt.Printf("  Rig: %s\n", p.Gate.Rig)
+		}
+		if p.Gate.Label != "" {
+			fmt.Printf("  Label: %s\n", p.Gate.Label)
+		}
+		if p.Gate.Actor != "" {
+			fmt.Printf("  Actor: %s\n", p.Gate.Actor)
+		}
+		if p.Gate.Branch != "" {
+			fmt.Printf("  Branch: %s\n", p.Gate.Branch)
+		}
 	} else {
 		fmt.Printf("  Type: manual (no gate section)\n")
 	}
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/plugin"
+	"github.com/steveyegge/gastown/internal/secrets"
+	"github.com/steveyegge/gastown/internal/style"
+	"golang.org/x/term"
+)
+
+// Plugin config flags
+var pluginConfigRig string
+
+var pluginConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and set plugin configuration values",
+	Long: `Manage the values a plugin declares in its [config.<key>] sections.
+
+Plain values are stored in plugins/config.json (town) or
+<rig>/plugins/config.json (rig) and can be committed with the town.
+Values declared secret = true are stored in the local encrypted secrets
+store (.runtime/secrets.enc, key in ~/.config/gastown/secrets.key) and
+never printed.
+
+When the plugin runs, every value is injected into the dog's environment
+as GT_PLUGIN_<KEY> (or the variable named by env). Rig-level plugins see
+their rig's values first, then the town's. Plugins missing a required
+value are not dispatched; gt doctor reports them.
+
+Examples:
+  gt plugin config show github-sheriff
+  gt plugin config set github-sheriff max_prs 20
+  gt plugin config set github-sheriff token          # Prompts (secret)
+  echo "$TOKEN" | gt plugin config set github-sheriff token
+  gt plugin config set lint threshold 5 --rig gastown
+  gt plugin config unset github-sheriff max_prs`,
+	RunE: requireSubcommand,
+}
+
+var pluginConfigShowCmd = &cobra.Command{
+	Use:   "show <plugin>",
+	Short: "Show a plugin's resolved configuration",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPluginConfigShow,
+}
+
+var pluginConfigSetCmd = &cobra.Command{
+	Use:   "set <plugin> <key> [value]",
+	Short: "Set a plugin configuration value",
+	Long: `Set a plugin configuration value.
+
+The value is checked against the key's declared type. Secret values may be
+omitted from the command line; they are then read from stdin (prompted,
+without echo, on a terminal) so they stay out of shell history.
+
+Values are set where the plugin lives: the town for town-level plugins,
+the plugin's rig for rig-level plugins. Use --rig to pick the rig-level
+plugin in a specific rig when several rigs define one with the same name.`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: runPluginConfigSet,
+}
+
+var pluginConfigUnsetCmd = &cobra.Command{
+	Use:   "unset <plugin> <key>",
+	Short: "Remove a plugin configuration value",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runPluginConfigUnset,
+}
+
+func init() {
+	pluginConfigSetCmd.Flags().StringVar(&pluginConfigRig, "rig", "", "Configure the rig-level plugin in this rig")
+	pluginConfigUnsetCmd.Flags().StringVar(&pluginConfigRig, "rig", "", "Configure the rig-level plugin in this rig")
+
+	pluginConfigCmd.AddCommand(pluginConfigShowCmd)
+	pluginConfigCmd.AddCommand(pluginConfigSetCmd)
+	pluginConfigCmd.AddCommand(pluginConfigUnsetCmd)
+	pluginCmd.AddCommand(pluginConfigCmd)
+}
+
+// pluginConfigField finds a plugin and one of its declared config keys.
+func pluginConfigField(name, key string) (*plugin.Plugin, *plugin.ConfigField, string, error) {
+	scanner, townRoot, err := getPluginScanner()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if pluginConfigRig != "" {
+		scanner = plugin.NewScanner(townRoot, []string{pluginConfigRig})
+	}
+	p, err := scanner.GetPlugin(name)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if pluginConfigRig != "" && p.RigName != pluginConfigRig {
+		return nil, nil, "", fmt.Errorf("rig %s has no rig-level plugin %s", pluginConfigRig, name)
+	}
+	field := p.Config[key]
+	if field == nil {
+		keys := p.ConfigKeys()
+		if len(keys) == 0 {
+			return nil, nil, "", fmt.Errorf("plugin %s declares no config values", p.Name)
+		}
+		return nil, nil, "", fmt.Errorf("plugin %s has no config key %q (have: %s)", p.Name, key, strings.Join(keys, ", "))
+	}
+	return p, field, townRoot, nil
+}
+
+func runPluginConfigShow(cmd *cobra.Command, args []string) error {
+	scanner, townRoot, err := getPluginScanner()
+	if err != nil {
+		return err
+	}
+	p, err := scanner.GetPlugin(args[0])
+	if err != nil {
+		return err
+	}
+	if len(p.Config) == 0 {
+		fmt.Printf("%s Plugin %s declares no config values\n", style.Dim.Render("○"), p.Name)
+		return nil
+	}
+
+	rc, err := plugin.ResolveConfig(townRoot, p, secrets.Open(townRoot).Get)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %s\n\n", style.Bold.Render("Plugin config:"), p.Name)
+	for _, v := range rc.Values {
+		f := p.Config[v.Key]
+		value := v.Value
+		switch {
+		case v.Source == plugin.SourceUnset && f.Required:
+			value = style.Error.Render("(required, not set)")
+		case v.Source == plugin.SourceUnset:
+			value = style.Dim.Render("(not set)")
+		case v.Secret:
+			value = "********"
+		}
+		source := ""
+		if v.Source != plugin.SourceUnset {
+			source = style.Dim.Render(fmt.Sprintf(" [%s]", v.Source))
+		}
+		fmt.Printf("  %s = %s%s\n", style.Bold.Render(v.Key), value, source)
+		fmt.Printf("    %s\n", style.Dim.Render("$"+v.Env))
+	}
+	if problems := rc.Problems(); len(problems) > 0 {
+		fmt.Println()
+		for _, problem := range problems {
+			fmt.Printf("%s %s\n", style.Warning.Render("⚠"), problem)
+		}
+	}
+	return nil
+}
+
+func runPluginConfigSet(cmd *cobra.Command, args []string) error {
+	p, field, townRoot, err := pluginConfigField(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	key, scope := args[1], p.RigName
+
+	var value string
+	if len(args) == 3 {
+		value = args[2]
+	} else if field.Secret {
+		if value, err = readSecretValue(key); err != nil {
+			return err
+		}
+	} else {
+		return fmt.Errorf("missing value for %s", key)
+	}
+	if err := field.Check(value); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	where := "town"
+	if scope != "" {
+		where = "rig " + scope
+	}
+	if field.Secret {
+		if err := secrets.Open(townRoot).Set(plugin.SecretName(scope, p.Name, key), value); err != nil {
+			return fmt.Errorf("storing secret: %w", err)
+		}
+		fmt.Printf("%s Stored secret %s.%s (%s)\n", style.SuccessPrefix, p.Name, key, where)
+		return nil
+	}
+
+	cv, err := plugin.LoadConfigValues(townRoot, scope)
+	if err != nil {
+		return err
+	}
+	cv.Set(p.Name, key, value)
+	if err := cv.Save(townRoot, scope); err != nil {
+		return fmt.Errorf("saving plugin config: %w", err)
+	}
+	fmt.Printf("%s Set %s.%s = %s (%s)\n", style.SuccessPrefix, p.Name, key, value, where)
+	return nil
+}
+
+func runPluginConfigUnset(cmd *cobra.Command, args []string) error {
+	p, field, townRoot, err := pluginConfigField(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	key, scope := args[1], p.RigName
+
+	var removed bool
+	if field.Secret {
+		if removed, err = secrets.Open(townRoot).Delete(plugin.SecretName(scope, p.Name, key)); err != nil {
+			return err
+		}
+	} else {
+		cv, err := plugin.LoadConfigValues(townRoot, scope)
+		if err != nil {
+			return err
+		}
+		if removed = cv.Unset(p.Name, key); removed {
+			if err := cv.Save(townRoot, scope); err != nil {
+				return fmt.Errorf("saving plugin config: %w", err)
+			}
+		}
+	}
+	if !removed {
+		fmt.Printf("%s %s.%s was not set\n", style.Dim.Render("○"), p.Name, key)
+		return nil
+	}
+	fmt.Printf("%s Unset %s.%s\n", style.SuccessPrefix, p.Name, key)
+	return nil
+}
+
+// readSecretValue reads a secret from stdin: prompted without echo on a
+// terminal, otherwise the first line of piped input.
+func readSecretValue(key string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", key)
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("reading value: %w", err)
+		}
+		return string(b), nil
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("reading value from stdin: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/plugin"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Plugin install flags
+var (
+	pluginInstallRig    string
+	pluginInstallRef    string
+	pluginInstallSHA256 string
+	pluginInstallForce  bool
+)
+
+var pluginInstallCmd = &cobra.Command{
+	Use:   "install [source]",
+	Short: "Install a plugin from a git URL or tarball",
+	Long: `Install a plugin from a git repository or a local tarball and pin it
+in plugins/plugins.lock.json.
+
+The source must contain plugin.md at its root or in a single top-level
+directory. The plugin's [package] section is checked before installing:
+min_gt_version must not be newer than this gt, and every tool listed in
+requires must be on PATH (--force skips both checks).
+
+The lockfile records the resolved commit and a sha256 of the plugin's
+contents. Commit it with the town so other machines get identical plugins.
+Run with no source to install or repair every plugin in the lockfile.
+
+Examples:
+  gt plugin install https://github.com/acme/gt-plugins-lint.git
+  gt plugin install ./lint-plugin.tar.gz --rig gastown
+  gt plugin install <url> --ref v1.2.0 --sha256 <checksum>
+  gt plugin install                     # Sync from the lockfile`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPluginInstall,
+}
+
+var pluginUpgradeCmd = &cobra.Command{
+	Use:   "upgrade <name> [source]",
+	Short: "Upgrade an installed plugin",
+	Long: `Reinstall a locked plugin from its recorded source, or from a new source.
+
+Without --ref, a git source is re-fetched at the ref it was installed from
+(its default branch if none). Moving to a lower package version requires
+--force.
+
+Examples:
+  gt plugin upgrade lint
+  gt plugin upgrade lint --ref v1.3.0
+  gt plugin upgrade lint ./lint-plugin-1.3.0.tar.gz`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runPluginUpgrade,
+}
+
+var pluginRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an installed plugin",
+	Long: `Delete a plugin installed with gt plugin install and drop it from the
+lockfile. Hand-written plugins that aren't in the lockfile are not touched.
+
+Examples:
+  gt plugin remove lint
+  gt plugin remove lint --rig gastown`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPluginRemove,
+}
+
+func init() {
+	pluginInstallCmd.Flags().StringVar(&pluginInstallRig, "rig", "", "Install into <rig>/plugins instead of the town")
+	pluginInstallCmd.Flags().StringVar(&pluginInstallRef, "ref", "", "Git branch, tag or commit to install")
+	pluginInstallCmd.Flags().StringVar(&pluginInstallSHA256, "sha256", "", "Expected content checksum")
+	pluginInstallCmd.Flags().BoolVar(&pluginInstallForce, "force", false, "Skip requirement checks and replace unmanaged plugins")
+
+	pluginUpgradeCmd.Flags().StringVar(&pluginInstallRig, "rig", "", "Rig the plugin is installed in")
+	pluginUpgradeCmd.Flags().StringVar(&pluginInstallRef, "ref", "", "Git branch, tag or commit to upgrade to")
+	pluginUpgradeCmd.Flags().StringVar(&pluginInstallSHA256, "sha256", "", "Expected content checksum")
+	pluginUpgradeCmd.Flags().BoolVar(&pluginInstallForce, "force", false, "Skip requirement and downgrade checks")
+
+	pluginRemoveCmd.Flags().StringVar(&pluginInstallRig, "rig", "", "Rig the plugin is installed in")
+
+	pluginCmd.AddCommand(pluginInstallCmd)
+	pluginCmd.AddCommand(pluginUpgradeCmd)
+	pluginCmd.AddCommand(pluginRemoveCmd)
+}
+
+func newPluginInstaller() (*plugin.Installer, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	return &plugin.Installer{TownRoot: townRoot, GTVersion: Version, LookPath: exec.LookPath}, nil
+}
+
+func pluginInstallOptions(source string) plugin.InstallOptions {
+	return plugin.InstallOptions{
+		Source: source,
+		Ref:    pluginInstallRef,
+		Rig:    pluginInstallRig,
+		SHA256: pluginInstallSHA256,
+		Force:  pluginInstallForce,
+	}
+}
+
+func runPluginInstall(cmd *cobra.Command, args []string) error {
+	in, err := newPluginInstaller()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		restored, err := in.Sync()
+		for _, key := range restored {
+			fmt.Printf("%s Restored %s\n", style.SuccessPrefix, key)
+		}
+		if err != nil {
+			return err
+		}
+		if len(restored) == 0 {
+			fmt.Printf("%s Plugins match %s\n", style.SuccessPrefix, plugin.LockFile)
+		}
+		return nil
+	}
+
+	res, err := in.Install(pluginInstallOptions(args[0]))
+	if err != nil {
+		return err
+	}
+	printPluginInstalled("Installed", res)
+	return nil
+}
+
+func runPluginUpgrade(cmd *cobra.Command, args []string) error {
+	in, err := newPluginInstaller()
+	if err != nil {
+		return err
+	}
+	source := ""
+	if len(args) > 1 {
+		source = args[1]
+	}
+
+	res, err := in.Upgrade(args[0], pluginInstallOptions(source))
+	if err != nil {
+		return err
+	}
+	if res.Previous != nil && res.Previous.SHA256 == res.Entry.SHA256 {
+		fmt.Printf("%s %s is already up to date\n", style.SuccessPrefix, res.Plugin.Name)
+		return nil
+	}
+	printPluginInstalled("Upgraded", res)
+	return nil
+}
+
+func runPluginRemove(cmd *cobra.Command, args []string) error {
+	in, err := newPluginInstaller()
+	if err != nil {
+		return err
+	}
+	entry, err := in.Remove(args[0], pluginInstallRig)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Removed %s\n", style.SuccessPrefix, plugin.LockKey(entry.Rig, entry.Name))
+	return nil
+}
+
+func printPluginInstalled(verb string, res *plugin.InstallResult) {
+	e := res.Entry
+	what := plugin.LockKey(e.Rig, e.Name)
+	if e.Version != "" {
+		what += " v" + strings.TrimPrefix(e.Version, "v")
+	}
+	if res.Previous != nil && res.Previous.Version != "" && res.Previous.Version != e.Version {
+		what += fmt.Sprintf(" (was v%s)", strings.TrimPrefix(res.Previous.Version, "v"))
+	}
+	fmt.Printf("%s %s %s\n", style.SuccessPrefix, verb, what)
+	fmt.Printf("  %s %s\n", style.Dim.Render("Path:"), res.Plugin.Path)
+	if e.Commit != "" {
+		fmt.Printf("  %s %s\n", style.Dim.Render("Commit:"), e.Commit)
+	}
+	fmt.Printf("  %s %s\n", style.Dim.Render("SHA256:"), e.SHA256)
+}
@@ -9,7 +9,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 
@@ -17,6 +16,7 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/cli"
 	"github.com/steveyegge/gastown/internal/lock"
+	"github.com/steveyegge/gastown/internal/memory"
 	"github.com/steveyegge/gastown/internal/state"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/telemetry"
@@ -93,7 +93,7 @@ func init() {
 	primeCmd.Flags().BoolVar(&primeStateJSON, "json", false,
 		"Output state as JSON (requires --state)")
 	primeCmd.Flags().BoolVar(&primeExplain, "explain", false,
-		"Show why each section was included")
+		"Show why each section was included, truncated or dropped under the context budget")
 	rootCmd.AddCommand(primeCmd)
 }
 
@@ -166,7 +166,11 @@ func runPrime(cmd *cobra.Command, args []string) (retErr error) {
 		return nil
 	}
 
-	formula, err := outputRoleContext(ctx)
+	// Every section is rendered up front, then emitted within the runtime's
+	// token budget so small-window runtimes keep the parts that matter most.
+	asm := newPrimeAssembler(primeTokenBudget(ctx))
+
+	formula, err := outputRoleContext(asm, ctx)
 	if err != nil {
 		return err
 	}
@@ -175,22 +179,28 @@ func runPrime(cmd *cobra.Command, args []string) (retErr error) {
 	// started with. Only emitted when GT telemetry is active (GT_OTEL_LOGS_URL set).
 	telemetry.RecordPrimeContext(context.Background(), formula, os.Getenv("GT_ROLE"), primeHookMode)
 
-	hasSlungWork := checkSlungWork(ctx, hookedBead)
-	explain(hasSlungWork, "Autonomous mode: hooked/in-progress work detected")
+	var hasSlungWork bool
+	asm.add("work", primeRequired, false, func() {
+		hasSlungWork = checkSlungWork(ctx, hookedBead)
+		explain(hasSlungWork, "Autonomous mode: hooked/in-progress work detected")
+	})
 
-	outputMoleculeContext(ctx)
-	outputCheckpointContext(ctx)
-	runPrimeExternalTools(cwd)
+	asm.add("molecule", primeHigh, true, func() { outputMoleculeContext(ctx) })
+	asm.add("checkpoint", primeNormal, false, func() { outputCheckpointContext(ctx) })
+	runPrimeExternalTools(asm, ctx, cwd, hookedBead)
 
 	if ctx.Role == RoleMayor {
-		checkPendingEscalations(ctx)
+		asm.add("escalations", primeNormal, true, func() { checkPendingEscalations(ctx) })
 	}
 
 	if !hasSlungWork {
-		explain(true, "Startup directive: normal mode (no hooked work)")
-		outputStartupDirective(ctx)
+		asm.add("startup-directive", primeRequired, false, func() {
+			explain(true, "Startup directive: normal mode (no hooked work)")
+			outputStartupDirective(ctx)
+		})
 	}
 
+	asm.flush(os.Stdout)
 	return nil
 }
 
@@ -370,36 +380,42 @@ func setupPrimeSession(ctx RoleContext, roleInfo RoleInfo) error {
 	return nil
 }
 
-// outputRoleContext emits session metadata and all role/context output sections.
+// outputRoleContext adds session metadata and all role/context output sections.
 // Returns the rendered formula content for OTEL telemetry (empty if using fallback path).
-func outputRoleContext(ctx RoleContext) (string, error) {
-	explain(true, "Session metadata: always included for seance discovery")
-	outputSessionMetadata(ctx)
+func outputRoleContext(asm *primeAssembler, ctx RoleContext) (string, error) {
+	asm.add("session-metadata", primeRequired, false, func() {
+		explain(true, "Session metadata: always included for seance discovery")
+		outputSessionMetadata(ctx)
+	})
 
-	explain(true, fmt.Sprintf("Role context: detected role is %s", ctx.Role))
-	formula, err := outputPrimeContext(ctx)
+	var formula string
+	var err error
+	asm.add("role", primeHigh, true, func() {
+		explain(true, fmt.Sprintf("Role context: detected role is %s", ctx.Role))
+		formula, err = outputPrimeContext(ctx)
+	})
 	if err != nil {
 		return "", err
 	}
 
-	outputContextFile(ctx)
-	outputHandoffContent(ctx)
-	outputAttachmentStatus(ctx)
+	asm.add("context-file", primeNormal, true, func() { outputContextFile(ctx) })
+	asm.add("handoff", primeHigh, true, func() { outputHandoffContent(ctx) })
+	asm.add("attachment", primeRequired, false, func() { outputAttachmentStatus(ctx) })
 	return formula, nil
 }
 
-// runPrimeExternalTools runs bd prime, memory injection, and gt mail check --inject.
+// runPrimeExternalTools adds bd prime, memory injection, and gt mail check --inject.
 // Skipped in dry-run mode with explain output.
-func runPrimeExternalTools(cwd string) {
+func runPrimeExternalTools(asm *primeAssembler, ctx RoleContext, cwd string, hookedBead *beads.Issue) {
 	if primeDryRun {
 		explain(true, "bd prime: skipped in dry-run mode")
 		explain(true, "memory injection: skipped in dry-run mode")
 		explain(true, "gt mail check --inject: skipped in dry-run mode")
 		return
 	}
-	runBdPrime(cwd)
-	runMemoryInject()
-	runMailCheckInject(cwd)
+	asm.add("bd-prime", primeNormal, true, func() { runBdPrime(cwd) })
+	asm.add("memories", primeLow, false, func() { runMemoryInject(ctx, hookedBead) })
+	asm.add("mail", primeHigh, true, func() { runMailCheckInject(cwd) })
 }
 
 // runBdPrime runs `bd prime` and outputs the result.
@@ -429,34 +445,35 @@ func runBdPrime(workDir string) {
 	}
 }
 
-// runMemoryInject loads memories from beads kv and outputs them during prime.
+// runMemoryInject loads memories from beads kv and outputs the ones most
+// relevant to the hooked bead that this agent can see, within
+// memory.DefaultPrimeLimit entries and memory.DefaultPrimeTokens tokens.
 // This replaces MEMORY.md injection with bead-backed agent memory.
-func runMemoryInject() {
-	kvs, err := bdKvListJSON()
+func runMemoryInject(ctx RoleContext, hookedBead *beads.Issue) {
+	mems, err := loadMemories()
 	if err != nil {
 		return // Silently skip if kv list fails
 	}
 
-	var memories []string
-	for k, v := range kvs {
-		if !strings.HasPrefix(k, memoryKeyPrefix) {
-			continue
-		}
-		shortKey := strings.TrimPrefix(k, memoryKeyPrefix)
-		memories = append(memories, fmt.Sprintf("- **%s**: %s", shortKey, v))
+	var q memory.Query
+	if hookedBead != nil {
+		q = memory.Query{Bead: hookedBead.ID, Text: hookedBead.Title + "\n" + hookedBead.Description}
 	}
-
-	if len(memories) == 0 {
+	now := time.Now()
+	v := memoryViewer(ctx)
+	selected := memory.Select(mems, v, q, memory.DefaultPrimeLimit, memory.DefaultPrimeTokens, now)
+	if len(selected) == 0 {
 		return
 	}
 
-	sort.Strings(memories)
-
 	fmt.Println()
 	fmt.Println("# Agent Memories")
 	fmt.Println()
-	for _, m := range memories {
-		fmt.Println(m)
+	for _, m := range selected {
+		fmt.Printf("- **%s**: %s\n", m.Key, m.Content)
+	}
+	if total := len(memory.Select(mems, v, q, 0, 0, now)); total > len(selected) {
+		fmt.Printf("\n_%d more: `%s memories --mine <terms>`_\n", total-len(selected), cli.Name())
 	}
 }
 
@@ -658,7 +675,11 @@ func outputHookedBeadDetails(hookedBead *beads.Issue) {
 	fmt.Printf("%s\n\n", style.Bold.Render("## Hooked Work"))
 	fmt.Printf("  Bead ID: %s\n", style.Bold.Render(hookedBead.ID))
 	fmt.Printf("  Title: %s\n", hookedBead.Title)
-	if hookedBead.Description != "" {
+	if doc, ok := beads.ParseHandoffDoc(hookedBead.Description); ok {
+		// Handoff mail on the hook: show the whole structure, not a preview.
+		fmt.Println()
+		printHandoffDoc(doc)
+	} else if hookedBead.Description != "" {
 		lines := strings.Split(hookedBead.Description, "\n")
 		maxLines := 5
 		if len(lines) > maxLines {
@@ -694,9 +715,9 @@ func outputMoleculeWorkflow(ctx RoleContext, attachment *beads.AttachmentFields)
 		return
 	}
 
-	// Show inline formula steps from the embedded binary (root-only: no child wisps to query).
+	// Show inline formula steps (root-only: no child wisps to query).
 	if attachment.AttachedFormula != "" {
-		showFormulaStepsFull(attachment.AttachedFormula)
+		showFormulaStepsFull(attachment.AttachedFormula, attachment.AttachedMolecule)
 		fmt.Println()
 		fmt.Printf("%s\n", style.Bold.Render("Work through the checklist above. When all steps complete, run `"+cli.Name()+" done`."))
 		fmt.Println("The base bead is your assignment. The formula steps define your workflow.")
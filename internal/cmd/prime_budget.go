@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/cli"
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Section priorities for prime output. Lower values win the budget first.
+const (
+	primeRequired = iota // never dropped or truncated
+	primeHigh
+	primeNormal
+	primeLow
+)
+
+// primeMinTruncateTokens is the smallest useful slice of a truncated section;
+// below this the section is dropped instead.
+const primeMinTruncateTokens = 200
+
+// Section outcomes reported by gt prime --explain.
+const (
+	primeIncluded  = "included"
+	primeTruncated = "truncated"
+	primeDropped   = "dropped"
+)
+
+// primeSection is one block of gt prime output.
+type primeSection struct {
+	name        string
+	priority    int
+	truncatable bool
+	text        string
+	tokens      int
+
+	status string
+	kept   int // tokens emitted
+}
+
+// primeAssembler collects prime output sections and emits as many as fit in
+// the runtime's token budget, highest priority first, in their original order.
+type primeAssembler struct {
+	budget   int
+	source   string // where the budget came from, for --explain
+	sections []*primeSection
+}
+
+func newPrimeAssembler(budget int, source string) *primeAssembler {
+	return &primeAssembler{budget: budget, source: source}
+}
+
+// add renders a section by capturing what fn writes to stdout.
+func (a *primeAssembler) add(name string, priority int, truncatable bool, fn func()) {
+	a.addText(name, priority, truncatable, capturePrimeOutput(fn))
+}
+
+// addText adds pre-rendered section text.
+func (a *primeAssembler) addText(name string, priority int, truncatable bool, text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	a.sections = append(a.sections, &primeSection{
+		name:        name,
+		priority:    priority,
+		truncatable: truncatable,
+		text:        text,
+		tokens:      estimateTokens(text),
+	})
+}
+
+// plan decides each section's status. Required sections always go in, even
+// over budget; the rest are admitted by priority, then order, truncating
+// truncatable sections to the remaining budget when they don't fit whole.
+func (a *primeAssembler) plan() {
+	order := make([]*primeSection, len(a.sections))
+	copy(order, a.sections)
+	sort.SliceStable(order, func(i, j int) bool { return order[i].priority < order[j].priority })
+
+	remaining := a.budget
+	for _, s := range order {
+		switch {
+		case s.priority == primeRequired || s.tokens <= remaining:
+			s.status, s.kept = primeIncluded, s.tokens
+		case s.truncatable && remaining >= primeMinTruncateTokens:
+			s.text = truncateToTokens(s.text, remaining, s.tokens)
+			s.status, s.kept = primeTruncated, estimateTokens(s.text)
+		default:
+			s.status, s.kept = primeDropped, 0
+		}
+		remaining -= s.kept
+	}
+}
+
+// flush plans the output and writes the surviving sections to w, followed by
+// the --explain report when enabled.
+func (a *primeAssembler) flush(w io.Writer) {
+	a.plan()
+	for _, s := range a.sections {
+		if s.status != primeDropped {
+			fmt.Fprint(w, s.text)
+		}
+	}
+	if primeExplain {
+		a.writeExplain(w)
+	}
+}
+
+func (a *primeAssembler) writeExplain(w io.Writer) {
+	used, total := 0, 0
+	for _, s := range a.sections {
+		used += s.kept
+		total += s.tokens
+	}
+	fmt.Fprintf(w, "\n[EXPLAIN] Context budget: %d tokens (%s); used ~%d of ~%d rendered\n",
+		a.budget, a.source, used, total)
+	for _, s := range a.sections {
+		detail := fmt.Sprintf("~%d tokens", s.tokens)
+		if s.status == primeTruncated {
+			detail = fmt.Sprintf("~%d of ~%d tokens", s.kept, s.tokens)
+		}
+		fmt.Fprintf(w, "[EXPLAIN]   %-18s %-9s p%d  %s\n", s.name, s.status, s.priority, detail)
+	}
+}
+
+// truncateToTokens cuts text at a line boundary so it fits in budget tokens,
+// including a marker saying how much was cut.
+func truncateToTokens(text string, budget, total int) string {
+	marker := fmt.Sprintf("\n[... truncated to fit the context budget: ~%d of ~%d tokens shown. `%s prime --explain` shows what was cut.]\n",
+		budget, total, cli.Name())
+	limit := budget*4 - len(marker)
+	if limit <= 0 {
+		return strings.TrimSpace(marker) + "\n"
+	}
+	if len(text) > limit {
+		text = text[:limit]
+		if i := strings.LastIndexByte(text, '\n'); i > 0 {
+			text = text[:i]
+		}
+	}
+	return text + marker
+}
+
+// estimateTokens approximates model tokens at four bytes per token.
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// capturePrimeOutput runs fn with os.Stdout redirected and returns what it
+// wrote.
+func capturePrimeOutput(fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		fn()
+		return ""
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	done := make(chan string, 1)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		_ = r.Close()
+		done <- buf.String()
+	}()
+
+	restored := false
+	restore := func() {
+		if !restored {
+			restored = true
+			_ = w.Close()
+			os.Stdout = orig
+		}
+	}
+	defer restore()
+	fn()
+	restore()
+	return <-done
+}
+
+// primeTokenBudget resolves the prime output budget from the agent's runtime
+// config: GT_AGENT when set, else the role's configured agent.
+func primeTokenBudget(ctx RoleContext) (int, string) {
+	rigPath := ""
+	if ctx.Rig != "" && ctx.TownRoot != "" {
+		rigPath = filepath.Join(ctx.TownRoot, ctx.Rig)
+	}
+	var rc *config.RuntimeConfig
+	agent := os.Getenv("GT_AGENT")
+	if agent != "" {
+		if resolved, _, err := config.ResolveAgentConfigWithOverride(ctx.TownRoot, rigPath, agent); err == nil {
+			rc = resolved
+		}
+	}
+	if rc == nil {
+		rc = config.ResolveRoleAgentConfig(string(ctx.Role), ctx.TownRoot, rigPath)
+		agent = rc.ResolvedAgent
+	}
+	if agent == "" {
+		agent = rc.Provider
+	}
+	if agent == "" {
+		agent = "default runtime"
+	}
+	if rc.PrimeBudget > 0 {
+		return rc.PrimeBudget, fmt.Sprintf("prime_budget for %s", agent)
+	}
+	return rc.PrimeTokenBudget(), fmt.Sprintf("%d%% of %s's %d-token window",
+		config.DefaultPrimeBudgetPercent, agent, rc.ContextWindowTokens())
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPrimeAssembler_Plan(t *testing.T) {
+	big := strings.Repeat("line of role context\n", 400) // ~2100 tokens
+	a := newPrimeAssembler(1000, "test")
+	a.addText("metadata", primeRequired, false, "[GAS TOWN] session\n")
+	a.addText("role", primeHigh, true, big)
+	a.addText("checkpoint", primeNormal, false, strings.Repeat("x", 2000))
+	a.addText("memories", primeLow, false, "# Agent Memories\n")
+	a.addText("empty", primeHigh, false, "  \n")
+	a.plan()
+
+	want := map[string]string{
+		"metadata":   primeIncluded,
+		"role":       primeTruncated,
+		"checkpoint": primeDropped,
+		"memories":   primeDropped, // role took the rest of the budget
+	}
+	if len(a.sections) != len(want) {
+		t.Fatalf("got %d sections, want %d (empty sections are skipped)", len(a.sections), len(want))
+	}
+	used := 0
+	for _, s := range a.sections {
+		if s.status != want[s.name] {
+			t.Errorf("%s: status %s, want %s", s.name, s.status, want[s.name])
+		}
+		used += s.kept
+	}
+	if used > 1000 {
+		t.Errorf("used %d tokens, over budget 1000", used)
+	}
+}
+
+func TestPrimeAssembler_RequiredOverBudget(t *testing.T) {
+	a := newPrimeAssembler(10, "test")
+	a.addText("work", primeRequired, false, strings.Repeat("w", 400))
+	a.addText("mail", primeHigh, true, strings.Repeat("m", 400))
+	a.plan()
+
+	if a.sections[0].status != primeIncluded {
+		t.Errorf("required section %s, want included", a.sections[0].status)
+	}
+	if a.sections[1].status != primeDropped {
+		t.Errorf("truncatable section with no room %s, want dropped", a.sections[1].status)
+	}
+}
+
+func TestPrimeAssembler_FlushKeepsOrder(t *testing.T) {
+	oldExplain := primeExplain
+	primeExplain = true
+	defer func() { primeExplain = oldExplain }()
+
+	a := newPrimeAssembler(10_000, "unit")
+	a.addText("low-first", primeLow, false, "A\n")
+	a.addText("required-second", primeRequired, false, "B\n")
+	var buf bytes.Buffer
+	a.flush(&buf)
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "A\nB\n") {
+		t.Errorf("sections not emitted in insertion order:\n%s", out)
+	}
+	for _, want := range []string{"Context budget: 10000 tokens (unit)", "low-first", "required-second", primeIncluded} {
+		if !strings.Contains(out, want) {
+			t.Errorf("explain output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestTruncateToTokens(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&b, "line %03d\n", i)
+	}
+	text := b.String()
+	got := truncateToTokens(text, 100, estimateTokens(text))
+
+	if estimateTokens(got) > 100 {
+		t.Errorf("truncated text is ~%d tokens, want <= 100", estimateTokens(got))
+	}
+	if !strings.Contains(got, "truncated to fit the context budget") {
+		t.Errorf("missing truncation marker:\n%s", got)
+	}
+	body := got[:strings.Index(got, "\n[... truncated")]
+	if !strings.HasPrefix(text, body) || strings.HasSuffix(body, "line") {
+		t.Errorf("truncation did not cut at a line boundary: %q", body[max(0, len(body)-20):])
+	}
+}
+
+func TestCapturePrimeOutput(t *testing.T) {
+	got := capturePrimeOutput(func() { fmt.Println("hello from section") })
+	if got != "hello from section\n" {
+		t.Errorf("capturePrimeOutput = %q", got)
+	}
+}
@@ -109,15 +109,9 @@ func showMoleculeExecutionPrompt(workDir, moleculeID string) {
 // Agents read these steps instead of materializing them as wisp rows.
 // The label parameter customizes the section header (e.g., "Patrol Steps", "Work Steps").
 func showFormulaSteps(formulaName, label string) {
-	content, err := formula.GetEmbeddedFormulaContent(formulaName)
+	f, err := loadFormulaByName(formulaName)
 	if err != nil {
-		style.PrintWarning("could not load formula %s: %v", formulaName, err)
-		return
-	}
-
-	f, err := formula.Parse(content)
-	if err != nil {
-		style.PrintWarning("could not parse formula %s: %v", formulaName, err)
+		style.PrintWarning("%v", err)
 		return
 	}
 
@@ -135,16 +129,10 @@ func showFormulaSteps(formulaName, label string) {
 
 // showFormulaStepsFull renders formula steps with full descriptions.
 // Used for polecat work formulas where step details are the primary instructions.
-func showFormulaStepsFull(formulaName string) {
-	content, err := formula.GetEmbeddedFormulaContent(formulaName)
-	if err != nil {
-		style.PrintWarning("could not load formula %s: %v", formulaName, err)
-		return
-	}
-
-	f, err := formula.Parse(content)
+func showFormulaStepsFull(formulaName, moleculeID string) {
+	f, err := loadFormulaByName(formulaName)
 	if err != nil {
-		style.PrintWarning("could not parse formula %s: %v", formulaName, err)
+		style.PrintWarning("%v", err)
 		return
 	}
 
@@ -160,7 +148,27 @@ func showFormulaStepsFull(formulaName string) {
 			fmt.Println(step.Description)
 			fmt.Println()
 		}
+		if step.IsApprovalGate() {
+			showApprovalGate(formulaName, moleculeID, step)
+		}
+	}
+}
+
+// showApprovalGate tells the agent how to park on an approval gate step.
+func showApprovalGate(formulaName, moleculeID string, step formula.Step) {
+	if moleculeID == "" {
+		moleculeID = "<molecule-id>"
 	}
+	fmt.Println("⏸ **APPROVAL GATE** — do not continue past this step until a human approves:")
+	fmt.Println()
+	fmt.Println("```")
+	fmt.Printf("gt approve request %s --step %s --formula %s\n", moleculeID, step.ID, formulaName)
+	fmt.Println("gt approve wait <approval-id>")
+	fmt.Println("```")
+	fmt.Println()
+	fmt.Println("If the wait exits non-zero the request was rejected or expired: stop, record the")
+	fmt.Println("reason on your work bead, and escalate instead of continuing.")
+	fmt.Println()
 }
 
 // truncateDescription truncates a multi-line description to a single line summary.
@@ -385,7 +385,7 @@ func outputHandoffContent(ctx RoleContext) {
 	// Display handoff content
 	fmt.Println()
 	fmt.Printf("%s\n\n", style.Bold.Render("## 🤝 Handoff from Previous Session"))
-	fmt.Println(issue.Description)
+	printHandoffBody(issue.Description)
 	fmt.Println()
 	fmt.Println(style.Dim.Render("(Clear with: gt rig reset --handoff)"))
 }
@@ -534,7 +534,7 @@ func outputAttachmentStatus(ctx RoleContext) {
 
 	// Show inline formula steps if formula name is known, else fall back to bd mol current
 	if attachment.AttachedFormula != "" {
-		showFormulaStepsFull(attachment.AttachedFormula)
+		showFormulaStepsFull(attachment.AttachedFormula, attachment.AttachedMolecule)
 	} else {
 		showMoleculeExecutionPrompt(ctx.WorkDir, attachment.AttachedMolecule)
 	}
@@ -619,6 +619,8 @@ func outputCheckpointContext(ctx RoleContext) {
 		return
 	}
 
+	outputSnapshotHint(ctx)
+
 	// Read checkpoint
 	cp, err := checkpoint.Read(ctx.WorkDir)
 	if err != nil {
@@ -680,6 +682,28 @@ func outputCheckpointContext(ctx RoleContext) {
 	fmt.Println()
 }
 
+// outputSnapshotHint tells a polecat or crew worker when a work snapshot
+// from a previous session has not been restored into this worktree, e.g.
+// after the worktree was repaired following a crash.
+func outputSnapshotHint(ctx RoleContext) {
+	snap, err := checkpoint.LoadSnapshot(ctx.WorkDir, getAgentIdentity(ctx))
+	if err != nil || snap == nil || time.Since(snap.Time) > 24*time.Hour {
+		return
+	}
+	if !snap.Pending(ctx.WorkDir) {
+		return
+	}
+
+	fmt.Println()
+	fmt.Printf("%s\n\n", style.Bold.Render("## 💾 Unrestored Work Snapshot"))
+	fmt.Printf("A snapshot of uncommitted work (%d files) was taken %s ago", len(snap.Files), time.Since(snap.Time).Round(time.Minute))
+	if snap.Branch != "" {
+		fmt.Printf(" on branch %s", snap.Branch)
+	}
+	fmt.Println(".")
+	fmt.Printf("Run `%s checkpoint restore` to bring it back before starting over.\n", cli.Name())
+}
+
 // outputDeaconPausedMessage outputs a prominent PAUSED message for the Deacon.
 // When paused, the Deacon must not perform any patrol actions.
 func outputDeaconPausedMessage(state *deacon.PauseState) {
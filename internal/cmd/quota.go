@@ -15,6 +15,7 @@ import (
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/quota"
+	sessionpkg "github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
 	ttmux "github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/util"
@@ -231,9 +232,8 @@ func runQuotaScan(cmd *cobra.Command, args []string) error {
 	acctCfg, loadErr := config.LoadAccountsConfig(accountsPath)
 	// acctCfg can be nil if no accounts configured — scan still works
 
-	// Create scanner
-	t := ttmux.NewTmux()
-	scanner, err := quota.NewScanner(t, nil, acctCfg)
+	// Create scanner (headless towns scan the PTY supervisor's sessions)
+	scanner, err := quota.NewScanner(sessionpkg.NewBackend(townRoot, nil), nil, acctCfg)
 	if err != nil {
 		return fmt.Errorf("creating scanner: %w", err)
 	}
@@ -699,6 +699,13 @@ func runRefineryReady(cmd *cobra.Command, args []string) error {
 
 	// Create engineer for the rig (it has beads access for status checking)
 	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		style.PrintWarning("loading merge queue config: %v", err)
+	}
+	if refineryReadyJSON {
+		// Engineer notes must not corrupt the JSON on stdout.
+		eng.SetOutput(os.Stderr)
+	}
 
 	if refineryReadyAll {
 		return runRefineryReadyAll(eng, rigName)
@@ -8,17 +8,31 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/memory"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
-const memoryKeyPrefix = "memory."
+const memoryKeyPrefix = memory.KeyPrefix
 
-var rememberKey string
+var (
+	rememberKey   string
+	rememberScope string
+	rememberBead  string
+	rememberTags  []string
+	rememberTTL   string
+)
 
 func init() {
 	rememberCmd.Flags().StringVar(&rememberKey, "key", "", "Explicit key slug (default: auto-generated from content)")
+	rememberCmd.Flags().StringVar(&rememberScope, "scope", "town",
+		"Audience: town, rig, role, agent (yours), or rig:<name>, role:<role>, agent:<identity>")
+	rememberCmd.Flags().StringVar(&rememberBead, "bead", "", "Source bead (default: your hooked bead, if any)")
+	rememberCmd.Flags().StringSliceVar(&rememberTags, "tag", nil, "Tag for search and ranking (repeatable)")
+	rememberCmd.Flags().StringVar(&rememberTTL, "ttl", "", "Expire after this long (e.g. 12h, 7d)")
 	rememberCmd.GroupID = GroupWork
 	rootCmd.AddCommand(rememberCmd)
 }
@@ -28,8 +42,17 @@ var rememberCmd = &cobra.Command{
 	Short: "Store a persistent memory",
 	Long: `Store a persistent memory in the beads key-value store.
 
-Memories persist across sessions and are injected during gt prime.
-This replaces filesystem-based MEMORY.md with bead-backed storage.
+Memories persist across sessions. gt prime injects the ones most relevant
+to your hooked bead, within a token budget.
+
+Each memory has a scope that decides who sees it:
+  town              every agent (default)
+  rig               agents in your rig          (or rig:<name>)
+  role              agents with your role       (or role:<role>)
+  agent             only you                    (or agent:<identity>)
+
+The author and source bead (your hooked bead unless --bead is given) are
+recorded with the memory. Use --ttl for insights that go stale.
 
 The key is auto-generated from the content if not specified.
 Use --key to provide an explicit slug for easy retrieval.
@@ -37,7 +60,8 @@ Use --key to provide an explicit slug for easy retrieval.
 Examples:
   gt remember "Refinery uses worktree, cannot checkout main"
   gt remember --key refinery-worktree "Refinery uses worktree, cannot checkout main"
-  gt remember "Always use --stdin for multi-line mail"`,
+  gt remember --scope rig --tag dolt "Dolt server for this rig runs on 3307"
+  gt remember --scope agent --ttl 2d "Parser refactor half done in lexer.go"`,
 	Args: cobra.ExactArgs(1),
 	RunE: runRemember,
 }
@@ -48,6 +72,35 @@ func runRemember(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("memory content cannot be empty")
 	}
 
+	scope, err := resolveMemoryScope(rememberScope)
+	if err != nil {
+		return err
+	}
+
+	m := &memory.Memory{
+		Content:   content,
+		Scope:     scope,
+		Bead:      rememberBead,
+		Author:    detectSender(),
+		CreatedAt: time.Now().UTC(),
+	}
+	if m.Bead == "" {
+		m.Bead = os.Getenv("GT_WORK_BEAD")
+	}
+	for _, tag := range rememberTags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			m.Tags = append(m.Tags, tag)
+		}
+	}
+	if rememberTTL != "" {
+		ttl, err := parseDuration(rememberTTL)
+		if err != nil || ttl <= 0 {
+			return fmt.Errorf("invalid --ttl %q", rememberTTL)
+		}
+		expires := m.CreatedAt.Add(ttl)
+		m.ExpiresAt = &expires
+	}
+
 	key := rememberKey
 	if key == "" {
 		key = autoKey(content)
@@ -55,6 +108,10 @@ func runRemember(cmd *cobra.Command, args []string) error {
 
 	// Sanitize key: lowercase, hyphens instead of spaces, strip dots
 	key = sanitizeKey(key)
+	if key == "" {
+		return fmt.Errorf("memory key cannot be empty")
+	}
+	key = memory.Key(scope, key)
 
 	fullKey := memoryKeyPrefix + key
 
@@ -65,14 +122,83 @@ func runRemember(cmd *cobra.Command, args []string) error {
 		verb = "Updated"
 	}
 
-	if err := bdKvSet(fullKey, content); err != nil {
+	value, err := m.Encode()
+	if err != nil {
+		return err
+	}
+	if err := bdKvSet(fullKey, value); err != nil {
 		return fmt.Errorf("storing memory: %w", err)
 	}
 
-	fmt.Printf("%s %s memory: %s\n", style.Success.Render("✓"), verb, style.Bold.Render(key))
+	fmt.Printf("%s %s memory: %s %s\n", style.Success.Render("✓"), verb, style.Bold.Render(key),
+		style.Dim.Render("("+scope.String()+")"))
 	return nil
 }
 
+// resolveMemoryScope turns a --scope value into a Scope. Bare "rig", "role"
+// and "agent" mean the caller's own rig, role or identity.
+func resolveMemoryScope(s string) (memory.Scope, error) {
+	switch memory.ScopeKind(s) {
+	case memory.ScopeRig, memory.ScopeRole, memory.ScopeAgent:
+	default:
+		return memory.ParseScope(s)
+	}
+	v, err := currentMemoryViewer()
+	if err != nil {
+		return memory.Scope{}, fmt.Errorf("--scope %s: %w", s, err)
+	}
+	name := map[memory.ScopeKind]string{
+		memory.ScopeRig:   v.Rig,
+		memory.ScopeRole:  v.Role,
+		memory.ScopeAgent: v.Agent,
+	}[memory.ScopeKind(s)]
+	if name == "" {
+		return memory.Scope{}, fmt.Errorf("--scope %s: cannot determine your %s here; use %s:<name>", s, s, s)
+	}
+	return memory.Scope{Kind: memory.ScopeKind(s), Name: name}, nil
+}
+
+// currentMemoryViewer identifies the agent at the current directory.
+func currentMemoryViewer() (memory.Viewer, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return memory.Viewer{}, err
+	}
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return memory.Viewer{}, fmt.Errorf("not in a Gas Town workspace")
+	}
+	roleInfo, err := GetRoleWithContext(cwd, townRoot)
+	if err != nil {
+		return memory.Viewer{}, fmt.Errorf("detecting role: %w", err)
+	}
+	return memoryViewer(roleInfo), nil
+}
+
+// memoryViewer returns the memory audience identity for a role context.
+func memoryViewer(ctx RoleContext) memory.Viewer {
+	v := memory.Viewer{Rig: ctx.Rig, Agent: getAgentIdentity(ctx)}
+	if ctx.Role != RoleUnknown {
+		v.Role = string(ctx.Role)
+	}
+	return v
+}
+
+// loadMemories reads every memory from the beads kv store.
+func loadMemories() ([]*memory.Memory, error) {
+	kvs, err := bdKvListJSON()
+	if err != nil {
+		return nil, err
+	}
+	var mems []*memory.Memory
+	for k, v := range kvs {
+		if m, ok := memory.Decode(k, v); ok {
+			mems = append(mems, m)
+		}
+	}
+	return mems, nil
+}
+
 // autoKey generates a short key from content using first few meaningful words.
 func autoKey(content string) string {
 	// Take first ~5 words, lowercase, hyphenate
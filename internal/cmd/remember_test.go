@@ -1,6 +1,11 @@
 package cmd
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/memory"
+)
 
 func TestAutoKey(t *testing.T) {
 	tests := []struct {
@@ -97,3 +102,53 @@ func TestSanitizeKey(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveMemoryScope_Explicit(t *testing.T) {
+	tests := map[string]string{
+		"":              "town",
+		"town":          "town",
+		"rig:gastown":   "rig:gastown",
+		"role:refinery": "role:refinery",
+		"agent:mayor":   "agent:mayor",
+	}
+	for in, want := range tests {
+		got, err := resolveMemoryScope(in)
+		if err != nil {
+			t.Errorf("resolveMemoryScope(%q): %v", in, err)
+			continue
+		}
+		if got.String() != want {
+			t.Errorf("resolveMemoryScope(%q) = %s, want %s", in, got, want)
+		}
+	}
+	if _, err := resolveMemoryScope("team:x"); err == nil {
+		t.Error("unknown scope kind should fail")
+	}
+}
+
+func TestMemoryViewer(t *testing.T) {
+	v := memoryViewer(RoleContext{Role: RolePolecat, Rig: "gastown", Polecat: "Toast"})
+	want := memory.Viewer{Rig: "gastown", Role: "polecat", Agent: "gastown/polecats/Toast"}
+	if v != want {
+		t.Errorf("memoryViewer = %+v, want %+v", v, want)
+	}
+}
+
+func TestFormatMemoryMeta(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	exp := now.Add(90 * time.Minute)
+	m := &memory.Memory{
+		Scope:     memory.Scope{Kind: memory.ScopeRig, Name: "gastown"},
+		Author:    "gastown/Toast",
+		Bead:      "gt-42",
+		Tags:      []string{"dolt", "ops"},
+		ExpiresAt: &exp,
+	}
+	want := "[rig:gastown, by gastown/Toast, from gt-42, #dolt #ops, expires in 1h30m0s]"
+	if got := formatMemoryMeta(m, now); got != want {
+		t.Errorf("formatMemoryMeta = %q, want %q", got, want)
+	}
+	if got := formatMemoryMeta(m, exp); got != "[rig:gastown, by gastown/Toast, from gt-42, #dolt #ops, expired]" {
+		t.Errorf("expired meta = %q", got)
+	}
+}
@@ -122,7 +122,8 @@ func checkHandoffMessages() error {
 			fmt.Printf("Date: %s\n", msg.Date)
 		}
 		if msg.Body != "" {
-			fmt.Printf("\n%s\n", msg.Body)
+			fmt.Println()
+			printHandoffBody(msg.Body)
 		}
 		fmt.Println()
 	}
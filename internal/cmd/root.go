@@ -72,6 +72,7 @@ var beadsExemptCommands = map[string]bool{
 	"health":              true, // Health check doesn't require beads
 	"upgrade":             true, // Post-install migration orchestrator
 	"heartbeat":           true, // Heartbeat state update — must be fast and dependency-free
+	"record":              true, // Pane recorder spawned by tmux pipe-pane
 }
 
 // Commands exempt from the town root branch warning.
@@ -88,6 +89,12 @@ var branchCheckExemptCommands = map[string]bool{
 
 // persistentPreRun runs before every command.
 func persistentPreRun(cmd *cobra.Command, args []string) error {
+	// Sandbox wrappers sit between the pane and the agent: skip the
+	// warnings and checks below, which would land in the agent's pane.
+	if isSandboxCommand(cmd) {
+		return nil
+	}
+
 	// Check if binary was built properly (via make build, not raw go build).
 	// Raw go build produces unsigned binaries that macOS may kill.
 	// Warning only - doesn't block execution.
@@ -163,6 +170,11 @@ func isRoleCommand(cmd *cobra.Command) bool {
 	return false
 }
 
+// isSandboxCommand returns true for the hidden `gt sandbox exec/init` wrappers.
+func isSandboxCommand(cmd *cobra.Command) bool {
+	return cmd.Parent() != nil && cmd.Parent().Name() == "sandbox" && cmd.Hidden
+}
+
 // initCLITheme initializes the CLI color theme based on settings and environment.
 func initCLITheme() {
 	// Try to load town settings for CLITheme config
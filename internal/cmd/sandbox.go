@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/sandbox"
+)
+
+// Sandbox command flags
+var (
+	sandboxRig          string
+	sandboxPolecat      string
+	sandboxTown         string
+	sandboxWorkDir      string
+	sandboxWritable     []string
+	sandboxNetwork      string
+	sandboxProxy        string
+	sandboxCPUs         float64
+	sandboxMemoryMB     int
+	sandboxPids         int
+	sandboxCgroupParent string
+)
+
+var sandboxCmd = &cobra.Command{
+	Use:     "sandbox",
+	GroupID: GroupAgents,
+	Short:   "Run polecats in Linux namespaces with resource limits",
+	Long: `Run polecat agents inside a namespace sandbox (Linux only).
+
+Sandboxing is opt-in per rig. Enable it in <rig>/settings/config.json:
+
+  "sandbox": {
+    "enabled": true,
+    "network": "proxy",
+    "proxy_addr": "127.0.0.1:9876",
+    "writable_paths": ["~/.claude", "~/.claude.json"],
+    "cpus": 2,
+    "memory_mb": 4096,
+    "pids": 512
+  }
+
+New polecat sessions in that rig then start under 'gt sandbox exec': the
+agent runs in its own user, mount and pid namespaces, with the filesystem
+read-only except the worktree, its git objects and refs, a private /tmp and
+writable_paths. The tmux, daemon and .runtime socket directories are hidden,
+and the shared repository's hooks and config stay read-only.
+
+network is "host" (default), "none" (loopback only) or "proxy" (loopback
+only, with gt-proxy-server at proxy_addr relayed to 127.0.0.1 on the same
+port and GT_PROXY_URL pointing there).
+
+cpus, memory_mb and pids set cgroup v2 limits. The polecat cgroups are
+created under cgroup_parent, which is required with any limit and must be
+delegated to your user and hold no processes itself. OOM kills and
+refused forks are reported as sandbox_violation events on the feed and the
+witness event channel.`,
+	RunE: requireSubcommand,
+}
+
+var sandboxExecCmd = &cobra.Command{
+	Use:    "exec [flags] -- <command>",
+	Short:  "Run a polecat command in the sandbox (used by polecat session startup)",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	RunE:   runSandboxExec,
+}
+
+var sandboxInitCmd = &cobra.Command{
+	Use:    "init",
+	Short:  "Sandbox init process (started by gt sandbox exec)",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runSandboxInit,
+}
+
+func init() {
+	f := sandboxExecCmd.Flags()
+	f.StringVar(&sandboxRig, "rig", "", "Rig name")
+	f.StringVar(&sandboxPolecat, "polecat", "", "Polecat name")
+	f.StringVar(&sandboxTown, "town", "", "Town root")
+	f.StringVar(&sandboxWorkDir, "workdir", "", "Polecat worktree (writable, and the agent's cwd)")
+	f.StringArrayVar(&sandboxWritable, "writable", nil, "Extra writable path (repeatable)")
+	f.StringVar(&sandboxNetwork, "network", sandbox.NetworkHost, "Network mode: host, none or proxy")
+	f.StringVar(&sandboxProxy, "proxy", "", "gt-proxy-server host:port relayed into proxy sandboxes")
+	f.Float64Var(&sandboxCPUs, "cpus", 0, "CPU limit in cores (0 = unlimited)")
+	f.IntVar(&sandboxMemoryMB, "memory-mb", 0, "Memory limit in MiB (0 = unlimited)")
+	f.IntVar(&sandboxPids, "pids", 0, "Process limit (0 = unlimited)")
+	f.StringVar(&sandboxCgroupParent, "cgroup-parent", "", "Delegated cgroup v2 directory for the polecat cgroup")
+	_ = sandboxExecCmd.MarkFlagRequired("rig")
+	_ = sandboxExecCmd.MarkFlagRequired("polecat")
+	_ = sandboxExecCmd.MarkFlagRequired("workdir")
+
+	sandboxCmd.AddCommand(sandboxExecCmd)
+	sandboxCmd.AddCommand(sandboxInitCmd)
+	rootCmd.AddCommand(sandboxCmd)
+}
+
+func runSandboxExec(cmd *cobra.Command, args []string) error {
+	spec := &sandbox.Spec{
+		Rig:          sandboxRig,
+		Polecat:      sandboxPolecat,
+		TownRoot:     sandboxTown,
+		WorkDir:      sandboxWorkDir,
+		Writable:     sandboxWritable,
+		Network:      sandboxNetwork,
+		ProxyAddr:    sandboxProxy,
+		CgroupParent: sandboxCgroupParent,
+		Limits:       sandbox.Limits{CPUs: sandboxCPUs, MemoryMB: sandboxMemoryMB, Pids: sandboxPids},
+		Command:      args[0],
+	}
+
+	code, err := sandbox.Run(spec, func(v sandbox.Violation) {
+		reportSandboxViolation(spec, v)
+	})
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return NewSilentExit(code)
+	}
+	return nil
+}
+
+func runSandboxInit(cmd *cobra.Command, args []string) error {
+	code, err := sandbox.Init()
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return NewSilentExit(code)
+	}
+	return nil
+}
+
+// reportSandboxViolation records a limit hit on the feed and on the
+// witness event channel, so the rig's witness sees it on its next wake.
+func reportSandboxViolation(spec *sandbox.Spec, v sandbox.Violation) {
+	actor := fmt.Sprintf("%s/polecats/%s", spec.Rig, spec.Polecat)
+	fmt.Fprintf(os.Stderr, "sandbox: %s (x%d)\n", v.Detail, v.Count)
+	_ = events.LogFeed(events.TypeSandboxViolation, actor,
+		events.SandboxViolationPayload(spec.Rig, spec.Polecat, v.Kind, v.Detail, v.Count))
+	if spec.TownRoot == "" {
+		return
+	}
+	if _, err := EmitEventToTown(spec.TownRoot, "witness", "SANDBOX_VIOLATION", []string{
+		"source=sandbox",
+		"rig=" + spec.Rig,
+		"polecat=" + spec.Polecat,
+		"kind=" + v.Kind,
+		"detail=" + v.Detail,
+		"count=" + strconv.Itoa(v.Count),
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to emit witness event: %v\n", err)
+	}
+}
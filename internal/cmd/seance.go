@@ -57,6 +57,10 @@ THE SEANCE (talk to predecessor):
 The --talk flag spawns: claude --fork-session --resume <id>
 This loads the predecessor's full context without modifying their session.
 
+SEARCH (without resuming anyone):
+  gt seance search "why did we pin grpc"     # Full-text over archived transcripts
+  gt seance search --bead gt-abc12           # Sessions that touched a bead
+
 Sessions are discovered from:
   1. Events emitted by SessionStart hooks (~/gt/.events.jsonl)
   2. The [GAS TOWN] beacon makes sessions searchable in /resume`,
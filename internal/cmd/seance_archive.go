@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/agentlog"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/seance"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	seanceSearchBead    string
+	seanceSearchActor   string
+	seanceSearchSince   string
+	seanceSearchLimit   int
+	seanceSearchJSON    bool
+	seanceSearchNoIndex bool
+)
+
+var seanceSearchCmd = &cobra.Command{
+	Use:   "search [query...]",
+	Short: "Search what predecessor sessions said and did",
+	Long: `Search the archive of past session transcripts.
+
+Every query term must appear somewhere in a session (prefixes match, so
+"pin" finds "pinned"); common words like "why", "did" and "we" are ignored.
+Results show the best-matching excerpts, so you rarely need to resume the
+session itself. Sessions new since the last search are archived first.
+
+Examples:
+  gt seance search "why did we pin grpc"
+  gt seance search --bead gt-abc12          # Sessions that mentioned a bead
+  gt seance search refinery --actor witness --since 7d
+  gt seance search dolt port --json`,
+	RunE: runSeanceSearch,
+}
+
+var seanceIndexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Archive transcripts of discoverable sessions",
+	Long: `Archive the transcripts of sessions found in the event stream.
+
+Transcripts are read through the agent's log adapter and stored under
+<town>/.runtime/seance with a full-text index and the bead IDs each session
+mentioned. Unchanged sessions are skipped; gt seance search runs this
+automatically.`,
+	RunE: runSeanceIndex,
+}
+
+func init() {
+	seanceSearchCmd.Flags().StringVar(&seanceSearchBead, "bead", "", "Only sessions that mentioned this bead")
+	seanceSearchCmd.Flags().StringVar(&seanceSearchActor, "actor", "", "Only sessions whose agent matches (e.g. crew, gastown/witness)")
+	seanceSearchCmd.Flags().StringVar(&seanceSearchSince, "since", "", "Only sessions active within this duration (e.g. 24h, 7d)")
+	seanceSearchCmd.Flags().IntVarP(&seanceSearchLimit, "limit", "n", 10, "Maximum sessions to show")
+	seanceSearchCmd.Flags().BoolVar(&seanceSearchJSON, "json", false, "Output as JSON")
+	seanceSearchCmd.Flags().BoolVar(&seanceSearchNoIndex, "no-index", false, "Search the archive as-is without archiving new sessions")
+
+	seanceCmd.AddCommand(seanceSearchCmd)
+	seanceCmd.AddCommand(seanceIndexCmd)
+}
+
+// seanceArchiveDir is where the session archive lives.
+func seanceArchiveDir(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "seance")
+}
+
+func runSeanceIndex(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return fmt.Errorf("not in a Gas Town workspace")
+	}
+	archive, err := seance.Open(seanceArchiveDir(townRoot))
+	if err != nil {
+		return err
+	}
+	added, missing, err := indexSeanceArchive(townRoot, archive)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Archived %d session(s); %d in archive", style.Success.Render("✓"), added, archive.Len())
+	if missing > 0 {
+		fmt.Printf(" %s", style.Dim.Render(fmt.Sprintf("(%d without a readable transcript)", missing)))
+	}
+	fmt.Println()
+	return nil
+}
+
+func runSeanceSearch(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return fmt.Errorf("not in a Gas Town workspace")
+	}
+	text := strings.Join(args, " ")
+	if strings.TrimSpace(text) == "" && seanceSearchBead == "" {
+		return fmt.Errorf("give a query or --bead")
+	}
+
+	q := seance.Query{Text: text, Bead: seanceSearchBead, Actor: seanceSearchActor, Limit: seanceSearchLimit}
+	if seanceSearchSince != "" {
+		d, err := parseDuration(seanceSearchSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		q.Since = time.Now().Add(-d)
+	}
+
+	archive, err := seance.Open(seanceArchiveDir(townRoot))
+	if err != nil {
+		return err
+	}
+	if !seanceSearchNoIndex {
+		if _, _, err := indexSeanceArchive(townRoot, archive); err != nil {
+			style.PrintWarning("archiving new sessions: %v", err)
+		}
+	}
+
+	results, err := archive.Search(q)
+	if err != nil {
+		return err
+	}
+
+	if seanceSearchJSON {
+		if results == nil {
+			results = []seance.Result{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
+	label := text
+	if seanceSearchBead != "" {
+		label = strings.TrimSpace(text + " bead:" + seanceSearchBead)
+	}
+	if len(results) == 0 {
+		fmt.Printf("No archived sessions match %q (%d archived).\n", label, archive.Len())
+		return nil
+	}
+
+	fmt.Printf("%s %d session(s) matching %q\n", style.Bold.Render("🔮"), len(results), label)
+	for _, r := range results {
+		printSeanceResult(r)
+	}
+	fmt.Printf("\n%s\n", style.Bold.Render("Ask a predecessor directly:"))
+	fmt.Printf("  gt seance --talk <session-id> -p \"...\"\n")
+	return nil
+}
+
+func printSeanceResult(r seance.Result) {
+	s := r.Session
+	id := s.ID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	when := "-"
+	if !s.Started.IsZero() {
+		when = s.Started.Local().Format("2006-01-02 15:04")
+	}
+	fmt.Printf("\n  %s  %s  %s", style.Bold.Render(id), s.Actor, style.Dim.Render(when))
+	if s.Topic != "" {
+		fmt.Printf("  %s", s.Topic)
+	}
+	fmt.Println()
+	if len(s.Beads) > 0 {
+		beadList := s.Beads
+		if len(beadList) > 6 {
+			beadList = append(beadList[:6:6], fmt.Sprintf("+%d", len(s.Beads)-6))
+		}
+		fmt.Printf("    %s\n", style.Dim.Render("beads: "+strings.Join(beadList, ", ")))
+	}
+	for _, e := range r.Excerpts {
+		fmt.Printf("    %s %s\n", style.Dim.Render(fmt.Sprintf("%-9s %s", e.Role, e.Time.Local().Format("15:04"))), e.Text)
+	}
+}
+
+// indexSeanceArchive archives every discoverable session whose transcript is
+// new or has changed. Returns how many sessions were (re)archived and how
+// many had no transcript that could be found.
+func indexSeanceArchive(townRoot string, archive *seance.Archive) (added, missing int, err error) {
+	sessions, err := discoverSessions(townRoot)
+	if err != nil {
+		return 0, 0, fmt.Errorf("discovering sessions: %w", err)
+	}
+	pattern := seanceBeadPattern(townRoot)
+
+	seen := map[string]bool{}
+	for _, ev := range sessions {
+		id := getPayloadString(ev.Payload, "session_id")
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true // events are newest first: keep the latest start
+
+		cwd := getPayloadString(ev.Payload, "cwd")
+		path, reader, ok := findSessionTranscript(townRoot, cwd, id)
+		if !ok {
+			missing++
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || archive.Fresh(id, info.ModTime()) {
+			continue
+		}
+		evs, err := reader.ReadTranscript(path, "")
+		if err != nil {
+			style.PrintWarning("reading transcript %s: %v", path, err)
+			continue
+		}
+
+		var turns []seance.Turn
+		for _, e := range evs {
+			if e.EventType == "usage" {
+				continue
+			}
+			turns = append(turns, seance.Turn{Time: e.Timestamp, Role: e.Role, Type: e.EventType, Text: e.Content})
+		}
+		sess := seance.Session{
+			ID:        id,
+			Actor:     ev.Actor,
+			Topic:     getPayloadString(ev.Payload, "topic"),
+			WorkDir:   cwd,
+			Agent:     reader.(agentlog.AgentAdapter).AgentType(),
+			Source:    path,
+			SourceMod: info.ModTime(),
+		}
+		if ts, err := time.Parse(time.RFC3339, ev.Timestamp); err == nil {
+			sess.Started = ts
+		}
+		if err := archive.Add(sess, turns, pattern); err != nil {
+			return added, missing, err
+		}
+		added++
+	}
+	if added > 0 {
+		if err := archive.Save(); err != nil {
+			return added, missing, err
+		}
+	}
+	return added, missing, nil
+}
+
+// seanceTranscriptAdapters are the agent log adapters tried, in order, when
+// locating a session's transcript.
+var seanceTranscriptAdapters = []string{"claudecode", "opencode"}
+
+// findSessionTranscript locates a session transcript through the agent log
+// adapters, falling back to searching every configured account directory.
+func findSessionTranscript(townRoot, cwd, sessionID string) (string, agentlog.TranscriptReader, bool) {
+	for _, name := range seanceTranscriptAdapters {
+		reader, ok := agentlog.NewAdapter(name).(agentlog.TranscriptReader)
+		if !ok {
+			continue
+		}
+		if path, ok := reader.TranscriptPath(cwd, sessionID); ok {
+			return path, reader, true
+		}
+	}
+	if loc := findSessionLocation(townRoot, sessionID); loc != nil {
+		path := filepath.Join(loc.configDir, "projects", loc.projectDir, sessionID+".jsonl")
+		if _, err := os.Stat(path); err == nil {
+			return path, &agentlog.ClaudeCodeAdapter{}, true
+		}
+	}
+	return "", nil, false
+}
+
+// seanceBeadPattern matches bead IDs using the town's routed prefixes.
+func seanceBeadPattern(townRoot string) *regexp.Regexp {
+	routes, _ := beads.LoadRoutes(filepath.Join(townRoot, ".beads"))
+	var prefixes []string
+	for _, r := range routes {
+		prefixes = append(prefixes, r.Prefix)
+	}
+	return seance.BeadPattern(prefixes)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/seance"
 )
 
 // setupSeanceTestEnv creates a test environment with multiple accounts and sessions.
@@ -502,3 +503,65 @@ func TestResolveSessionPrefix(t *testing.T) {
 		}
 	})
 }
+
+func TestIndexSeanceArchive(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	townRoot := t.TempDir()
+	workDir := filepath.Join(townRoot, "gastown", "crew", "joe")
+	id := "5f1e2d3c-aaaa-bbbb-cccc-ddddeeeeffff"
+
+	// Transcript where the Claude Code adapter expects it for workDir.
+	projectDir := filepath.Join(home, ".claude", "projects", strings.ReplaceAll(workDir, "/", "-"))
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	transcript := `{"type":"user","message":{"role":"user","content":[{"type":"text","text":"Fix gt-abc12"}]},"timestamp":"2026-03-01T10:00:00Z"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Pinned grpc to 1.62; 1.63 breaks streaming."}],"usage":{"input_tokens":5,"output_tokens":9}},"timestamp":"2026-03-01T10:01:00Z"}
+`
+	if err := os.WriteFile(filepath.Join(projectDir, id+".jsonl"), []byte(transcript), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	for _, ev := range []map[string]interface{}{
+		{"ts": "2026-03-01T10:00:00Z", "type": events.TypeSessionStart, "actor": "gastown/crew/joe",
+			"payload": events.SessionPayload(id, "gastown/crew/joe", "", workDir)},
+		{"ts": "2026-03-01T11:00:00Z", "type": events.TypeSessionStart, "actor": "mayor",
+			"payload": events.SessionPayload("no-such-session", "mayor", "", townRoot)},
+	} {
+		data, _ := json.Marshal(ev)
+		lines = append(lines, string(data))
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, events.EventsFile), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := seance.Open(seanceArchiveDir(townRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	added, missing, err := indexSeanceArchive(townRoot, archive)
+	if err != nil {
+		t.Fatalf("indexSeanceArchive: %v", err)
+	}
+	if added != 1 || missing != 1 {
+		t.Errorf("added=%d missing=%d, want 1 and 1", added, missing)
+	}
+
+	// Unchanged transcripts are not re-archived.
+	if added, _, _ := indexSeanceArchive(townRoot, archive); added != 0 {
+		t.Errorf("second pass re-archived %d session(s)", added)
+	}
+
+	results, err := archive.Search(seance.Query{Text: "why did we pin grpc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Session.Actor != "gastown/crew/joe" || results[0].Session.Agent != "claudecode" {
+		t.Fatalf("results = %+v", results)
+	}
+	if got := archive.Session(id).Beads; len(got) != 1 || got[0] != "gt-abc12" {
+		t.Errorf("Beads = %v", got)
+	}
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/recording"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Playback command flags
+var (
+	playbackAt      string
+	playbackSearch  string
+	playbackList    bool
+	playbackSpeed   float64
+	playbackMaxIdle time.Duration
+
+	recordOutput string
+	recordTitle  string
+	recordWidth  int
+	recordHeight int
+)
+
+var sessionPlaybackCmd = &cobra.Command{
+	Use:   "playback <rig>/<polecat>",
+	Short: "Replay or search recorded session output",
+	Long: `Replay or search asciicast recordings of a polecat's tmux pane.
+
+Recording is opt-in per rig. Enable it in <rig>/settings/config.json:
+
+  "recording": {"enabled": true}
+
+New polecat sessions in that rig are then recorded to
+.runtime/recordings/<rig>/<polecat>/ via tmux pipe-pane. Recordings are
+removed by 'gt krc prune' after the session_recording TTL (default 3d).
+The files are asciicast v2, so 'asciinema play' can read them too.
+
+--at picks the recording that was running at a time and starts playing
+from that moment. It accepts a duration ago (90m, 2h) or a time
+(15:04, 2006-01-02 15:04, RFC3339).
+
+Examples:
+  gt session playback gastown/Toast                # Replay latest recording
+  gt session playback gastown/Toast --at 03:10     # What happened at 3:10am
+  gt session playback gastown/Toast --at 6h -s 4   # 6 hours ago, 4x speed
+  gt session playback gastown/Toast --search panic # Find lines mentioning panic
+  gt session playback gastown/Toast --list         # List recordings`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionPlayback,
+}
+
+var sessionRecordCmd = &cobra.Command{
+	Use:    "record",
+	Short:  "Write pane output from stdin to an asciicast file (used by tmux pipe-pane)",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runSessionRecord,
+}
+
+func init() {
+	sessionPlaybackCmd.Flags().StringVar(&playbackAt, "at", "", "Start from this time (duration ago or timestamp)")
+	sessionPlaybackCmd.Flags().StringVar(&playbackSearch, "search", "", "Print output lines containing text instead of replaying")
+	sessionPlaybackCmd.Flags().BoolVar(&playbackList, "list", false, "List recordings")
+	sessionPlaybackCmd.Flags().Float64VarP(&playbackSpeed, "speed", "s", 1, "Playback speed multiplier")
+	sessionPlaybackCmd.Flags().DurationVar(&playbackMaxIdle, "max-idle", 2*time.Second, "Cap pauses between output at this duration (0 = no cap)")
+
+	sessionRecordCmd.Flags().StringVar(&recordOutput, "output", "", "Recording file to create")
+	sessionRecordCmd.Flags().StringVar(&recordTitle, "title", "", "Recording title")
+	sessionRecordCmd.Flags().IntVar(&recordWidth, "width", 200, "Terminal width")
+	sessionRecordCmd.Flags().IntVar(&recordHeight, "height", 50, "Terminal height")
+	_ = sessionRecordCmd.MarkFlagRequired("output")
+
+	sessionCmd.AddCommand(sessionPlaybackCmd)
+	sessionCmd.AddCommand(sessionRecordCmd)
+}
+
+func runSessionPlayback(cmd *cobra.Command, args []string) error {
+	rigName, polecatName, err := parseAddress(args[0])
+	if err != nil {
+		return err
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	recs, err := recording.List(townRoot, rigName, polecatName)
+	if err != nil {
+		return fmt.Errorf("listing recordings: %w", err)
+	}
+	if len(recs) == 0 {
+		return fmt.Errorf("no recordings for %s/%s (enable \"recording\" in %s/settings/config.json)",
+			rigName, polecatName, rigName)
+	}
+
+	if playbackList {
+		return printRecordingList(recs)
+	}
+
+	var at time.Time
+	if playbackAt != "" {
+		at, err = parsePlaybackTime(playbackAt, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	if playbackSearch != "" {
+		// Without --at, search everything; with it, just the covering recording.
+		if !at.IsZero() {
+			rec, ok := recording.FindAt(recs, at)
+			if !ok {
+				return fmt.Errorf("no recording of %s/%s was running at %s", rigName, polecatName, at.Format("2006-01-02 15:04:05"))
+			}
+			recs = []recording.Info{rec}
+		}
+		return searchRecordings(recs, playbackSearch)
+	}
+
+	rec := recs[len(recs)-1]
+	var from time.Duration
+	if !at.IsZero() {
+		var ok bool
+		rec, ok = recording.FindAt(recs, at)
+		if !ok {
+			return fmt.Errorf("no recording of %s/%s was running at %s (earliest starts %s)",
+				rigName, polecatName, at.Format("2006-01-02 15:04:05"), recs[0].Start.Local().Format("2006-01-02 15:04:05"))
+		}
+	}
+
+	hdr, events, err := readRecording(rec.Path)
+	if err != nil {
+		return err
+	}
+	if !at.IsZero() {
+		from = at.Sub(recordingStart(rec, hdr))
+	}
+	return recording.Play(os.Stdout, events, recording.PlayOptions{
+		Speed:   playbackSpeed,
+		MaxIdle: playbackMaxIdle,
+		From:    from,
+	})
+}
+
+func readRecording(path string) (recording.Header, []recording.Event, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path comes from recording.List
+	if err != nil {
+		return recording.Header{}, nil, err
+	}
+	defer f.Close()
+	hdr, events, err := recording.Read(f)
+	if err != nil {
+		return hdr, nil, fmt.Errorf("reading %s: %w", filepath.Base(path), err)
+	}
+	return hdr, events, nil
+}
+
+// recordingStart prefers the recorder's own start time from the header over
+// the file name, which is set slightly earlier by the session manager.
+func recordingStart(info recording.Info, hdr recording.Header) time.Time {
+	if hdr.Timestamp != 0 {
+		return hdr.Start()
+	}
+	return info.Start
+}
+
+func printRecordingList(recs []recording.Info) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STARTED\tDURATION\tSIZE\tFILE")
+	for _, r := range recs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			r.Start.Local().Format("2006-01-02 15:04:05"),
+			formatDuration(r.ModTime.Sub(r.Start)),
+			formatBytes(r.Size),
+			r.Path)
+	}
+	return w.Flush()
+}
+
+func searchRecordings(recs []recording.Info, text string) error {
+	found := 0
+	for _, r := range recs {
+		hdr, events, err := readRecording(r.Path)
+		if err != nil {
+			style.PrintWarning("%v", err)
+			continue
+		}
+		start := recordingStart(r, hdr)
+		for _, m := range recording.Search(events, text) {
+			found++
+			ts := start.Add(m.Offset).Local().Format("2006-01-02 15:04:05")
+			fmt.Printf("%s  %s\n", style.Dim.Render(ts), m.Line)
+		}
+	}
+	if found == 0 {
+		fmt.Printf("%s No output matching %q\n", style.Dim.Render("○"), text)
+	}
+	return nil
+}
+
+// parsePlaybackTime parses --at: a duration ago (2h), a clock time today
+// (15:04), or a local date-time.
+func parsePlaybackTime(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, now.Location()), nil
+		}
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --at %q (want a duration like 2h, a time like 15:04, or 2006-01-02 15:04)", s)
+}
+
+func runSessionRecord(cmd *cobra.Command, args []string) error {
+	f, err := os.OpenFile(recordOutput, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644) //nolint:gosec // G304: path chosen by the session manager
+	if err != nil {
+		return fmt.Errorf("creating recording: %w", err)
+	}
+	defer f.Close()
+
+	hdr := recording.Header{
+		Width:  recordWidth,
+		Height: recordHeight,
+		Title:  recordTitle,
+		Env:    map[string]string{"TERM": strings.TrimSpace(os.Getenv("TERM"))},
+	}
+	return recording.Record(os.Stdin, f, hdr, time.Now)
+}
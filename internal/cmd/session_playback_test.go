@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePlaybackTime(t *testing.T) {
+	loc := time.FixedZone("test", -5*3600)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, loc)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2h", now.Add(-2 * time.Hour)},
+		{"03:10", time.Date(2026, 3, 10, 3, 10, 0, 0, loc)},
+		{"03:10:30", time.Date(2026, 3, 10, 3, 10, 30, 0, loc)},
+		{"2026-03-09 23:45", time.Date(2026, 3, 9, 23, 45, 0, 0, loc)},
+		{"2026-03-09T23:45:00Z", time.Date(2026, 3, 9, 23, 45, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parsePlaybackTime(tt.in, now)
+		if err != nil {
+			t.Errorf("parsePlaybackTime(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parsePlaybackTime(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	if _, err := parsePlaybackTime("yesterday", now); err == nil {
+		t.Error("expected error for invalid time")
+	}
+}
@@ -0,0 +1,340 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/activity"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/tmux"
+	toptui "github.com/steveyegge/gastown/internal/tui/top"
+)
+
+// topHookRefreshInterval bounds how often hooked beads are re-queried.
+// Hooks change on the order of minutes; bd calls are not free.
+const topHookRefreshInterval = 30 * time.Second
+
+var topCmd = &cobra.Command{
+	Use:     "top",
+	GroupID: GroupDiag,
+	Short:   "Live monitor of every agent session in the town",
+	Long: `Open a top-like dashboard of every Gas Town tmux session.
+
+For each session shows role, hooked bead, heartbeat state, time since
+last activity, CPU and memory of the agent's process tree, token rate
+and cost (from the session's Claude transcript). Refreshes every few
+seconds.
+
+Keybindings:
+  j/k, ↑/↓   Move selection
+  s / S      Cycle sort column (cpu, mem, activity, tok/min, cost, name) / reverse
+  p          Peek at the session's recent pane output
+  n          Nudge the agent (prompts for a message)
+  R          Restart the agent (asks for confirmation)
+  w          File a death warrant (prompts for a reason)
+  ctrl+r     Refresh now
+  q          Quit
+
+Examples:
+  gt top`,
+	Args: cobra.NoArgs,
+	RunE: runTop,
+}
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+}
+
+func runTop(cmd *cobra.Command, args []string) error {
+	rigs, townRoot, err := getAllRigs()
+	if err != nil {
+		return err
+	}
+
+	src := newTopSource(townRoot, rigs)
+	p := tea.NewProgram(toptui.New(src), tea.WithAltScreen())
+	_, err = p.Run()
+	return err
+}
+
+// topSource adapts tmux, heartbeats, beads, and transcripts to the top TUI.
+type topSource struct {
+	townRoot string
+	rigs     []*rig.Rig
+	tmux     *tmux.Tmux
+
+	mu          sync.Mutex
+	hooked      map[string]string // assignee address → hooked bead ID
+	hookedAt    time.Time
+	usage       map[string]topUsageSample
+	transcripts map[string]topTranscriptCache
+}
+
+// topUsageSample is a session's cumulative token count at a point in time,
+// kept between snapshots to derive a rate.
+type topUsageSample struct {
+	tokens int
+	at     time.Time
+}
+
+// topTranscriptCache avoids re-parsing an unchanged transcript every refresh.
+type topTranscriptCache struct {
+	modTime time.Time
+	size    int64
+	usage   *TokenUsage
+}
+
+func newTopSource(townRoot string, rigs []*rig.Rig) *topSource {
+	return &topSource{
+		townRoot:    townRoot,
+		rigs:        rigs,
+		tmux:        tmux.NewTmux(),
+		usage:       make(map[string]topUsageSample),
+		transcripts: make(map[string]topTranscriptCache),
+	}
+}
+
+// Snapshot gathers one row per known Gas Town session.
+func (s *topSource) Snapshot() ([]toptui.AgentRow, error) {
+	sessions, err := s.tmux.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	// One ps call covers every session's process tree.
+	procs, _ := session.ReadProcessTable()
+	hooked := s.hookedBeads()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	rows := make([]toptui.AgentRow, 0, len(sessions))
+	live := make(map[string]bool, len(sessions))
+	for _, sess := range sessions {
+		if sess == "" || !session.IsKnownSession(sess) {
+			continue
+		}
+		live[sess] = true
+		row := toptui.AgentRow{Session: sess, Role: "unknown"}
+
+		var address string
+		if id, err := session.ParseSessionName(sess); err == nil {
+			row.Role = string(id.Role)
+			row.Rig = id.Rig
+			row.Name = id.Name
+			address = id.Address()
+		}
+
+		if hb := polecat.ReadSessionHeartbeat(s.townRoot, sess); hb != nil {
+			row.Heartbeat = string(hb.EffectiveState())
+			if now.Sub(hb.Timestamp) >= polecat.SessionHeartbeatStaleThreshold {
+				row.Heartbeat = "stale"
+			}
+			row.HookedBead = hb.Bead
+		}
+		if row.HookedBead == "" && address != "" {
+			row.HookedBead = hooked[address]
+		}
+
+		lastActivity, _ := s.tmux.GetSessionActivity(sess)
+		row.Activity = activity.Calculate(lastActivity)
+
+		if procs != nil {
+			if pidStr, err := s.tmux.GetPanePID(sess); err == nil {
+				if pid, err := strconv.Atoi(pidStr); err == nil {
+					u := procs.TreeUsage(pid)
+					row.Procs = u.Procs
+					row.CPUPercent = u.CPUPercent
+					row.RSSKB = u.RSSKB
+				}
+			}
+		}
+
+		if usage := s.transcriptUsageLocked(sess); usage != nil {
+			row.Cost = calculateCost(usage)
+			total := usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens + usage.OutputTokens
+			row.TokenRate = topTokenRate(s.usage[sess], total, now)
+			s.usage[sess] = topUsageSample{tokens: total, at: now}
+		}
+
+		rows = append(rows, row)
+	}
+
+	// Forget sessions that have gone away so a recycled name starts fresh.
+	for sess := range s.usage {
+		if !live[sess] {
+			delete(s.usage, sess)
+		}
+	}
+	return rows, nil
+}
+
+// topTokenRate returns tokens per minute since the previous sample.
+// Returns 0 without a prior sample or when the count went backwards
+// (the session started a new transcript).
+func topTokenRate(prev topUsageSample, total int, now time.Time) float64 {
+	if prev.at.IsZero() || total < prev.tokens {
+		return 0
+	}
+	elapsed := now.Sub(prev.at).Minutes()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(total-prev.tokens) / elapsed
+}
+
+// transcriptUsageLocked returns token usage from the session's latest
+// transcript, re-parsing only when the file has changed.
+// Caller must hold s.mu.
+func (s *topSource) transcriptUsageLocked(sess string) *TokenUsage {
+	workDir, err := getTmuxSessionWorkDir(sess)
+	if err != nil {
+		return nil
+	}
+	projectDir, err := getClaudeProjectDir(workDir)
+	if err != nil {
+		return nil
+	}
+	path, err := findLatestTranscript(projectDir)
+	if err != nil {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	if c, ok := s.transcripts[path]; ok && c.modTime.Equal(info.ModTime()) && c.size == info.Size() {
+		return c.usage
+	}
+	usage, err := parseTranscriptUsage(path)
+	if err != nil {
+		return nil
+	}
+	s.transcripts[path] = topTranscriptCache{modTime: info.ModTime(), size: info.Size(), usage: usage}
+	return usage
+}
+
+// hookedBeads maps agent addresses to their hooked bead across the town
+// and every rig, refreshing at most every topHookRefreshInterval.
+func (s *topSource) hookedBeads() map[string]string {
+	s.mu.Lock()
+	if s.hooked != nil && time.Since(s.hookedAt) < topHookRefreshInterval {
+		defer s.mu.Unlock()
+		return s.hooked
+	}
+	s.mu.Unlock()
+
+	dirs := []string{s.townRoot}
+	for _, r := range s.rigs {
+		dirs = append(dirs, r.BeadsPath())
+	}
+
+	hooked := make(map[string]string)
+	for _, dir := range dirs {
+		issues, err := beads.New(dir).List(beads.ListOptions{
+			Status:   beads.StatusHooked,
+			Priority: -1,
+		})
+		if err != nil {
+			continue
+		}
+		for _, issue := range issues {
+			if issue.Assignee != "" && hooked[issue.Assignee] == "" {
+				hooked[issue.Assignee] = issue.ID
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.hooked = hooked
+	s.hookedAt = time.Now()
+	s.mu.Unlock()
+	return hooked
+}
+
+// Peek captures the session's recent pane output.
+func (s *topSource) Peek(row toptui.AgentRow, lines int) (string, error) {
+	return s.tmux.CapturePane(row.Session, lines)
+}
+
+// NudgeCommand returns a gt nudge invocation for the agent.
+func (s *topSource) NudgeCommand(row toptui.AgentRow, message string) *exec.Cmd {
+	return exec.Command("gt", "nudge", topNudgeTarget(row), message) //nolint:gosec // G204: args are not shell-interpreted
+}
+
+// RestartCommand returns the role-specific gt restart invocation, or nil
+// for roles that have no restart command.
+func (s *topSource) RestartCommand(row toptui.AgentRow) *exec.Cmd {
+	args := topRestartArgs(row)
+	if args == nil {
+		return nil
+	}
+	return exec.Command("gt", args...) //nolint:gosec // G204: args are not shell-interpreted
+}
+
+// WarrantCommand returns a gt warrant file invocation for the agent.
+func (s *topSource) WarrantCommand(row toptui.AgentRow, reason string) *exec.Cmd {
+	return exec.Command("gt", "warrant", "file", topWarrantTarget(row), "--reason", reason) //nolint:gosec // G204: args are not shell-interpreted
+}
+
+// topNudgeTarget returns the gt nudge target for a row, using the same
+// shortcuts as the feed TUI: role names for singletons, rig/role for
+// witness and refinery, rig/crew/name for crew, and rig/name for polecats.
+func topNudgeTarget(row toptui.AgentRow) string {
+	switch session.Role(row.Role) {
+	case session.RoleMayor, session.RoleDeacon:
+		if row.Name != "" {
+			return row.Session // boot has no address shortcut
+		}
+		return row.Role
+	case session.RoleWitness, session.RoleRefinery:
+		return row.Rig + "/" + row.Role
+	case session.RoleCrew:
+		return row.Rig + "/crew/" + row.Name
+	case session.RolePolecat:
+		return row.Rig + "/" + row.Name
+	default:
+		return row.Session
+	}
+}
+
+// topRestartArgs returns the gt arguments that restart the row's agent.
+func topRestartArgs(row toptui.AgentRow) []string {
+	switch session.Role(row.Role) {
+	case session.RoleMayor:
+		return []string{"mayor", "restart"}
+	case session.RoleDeacon:
+		if row.Name != "" {
+			return nil // boot and dogs are managed by the deacon
+		}
+		return []string{"deacon", "restart"}
+	case session.RoleWitness:
+		return []string{"witness", "restart", row.Rig}
+	case session.RoleRefinery:
+		return []string{"refinery", "restart", row.Rig}
+	case session.RoleCrew:
+		return []string{"crew", "restart", row.Rig + "/" + row.Name}
+	case session.RolePolecat:
+		return []string{"session", "restart", row.Rig + "/" + row.Name}
+	default:
+		return nil
+	}
+}
+
+// topWarrantTarget returns the agent path gt warrant file expects.
+func topWarrantTarget(row toptui.AgentRow) string {
+	id := session.AgentIdentity{Role: session.Role(row.Role), Rig: row.Rig, Name: row.Name}
+	if addr := id.Address(); addr != "" {
+		return addr
+	}
+	return row.Session
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	toptui "github.com/steveyegge/gastown/internal/tui/top"
+)
+
+func TestTopActionTargets(t *testing.T) {
+	tests := []struct {
+		row         toptui.AgentRow
+		nudge       string
+		restartArgs []string
+		warrant     string
+	}{
+		{
+			row:         toptui.AgentRow{Session: "hq-mayor", Role: "mayor"},
+			nudge:       "mayor",
+			restartArgs: []string{"mayor", "restart"},
+			warrant:     "mayor",
+		},
+		{
+			row:         toptui.AgentRow{Session: "hq-boot", Role: "deacon", Name: "boot"},
+			nudge:       "hq-boot",
+			restartArgs: nil,
+			warrant:     "deacon",
+		},
+		{
+			row:         toptui.AgentRow{Session: "gt-witness", Role: "witness", Rig: "gastown"},
+			nudge:       "gastown/witness",
+			restartArgs: []string{"witness", "restart", "gastown"},
+			warrant:     "gastown/witness",
+		},
+		{
+			row:         toptui.AgentRow{Session: "gt-refinery", Role: "refinery", Rig: "gastown"},
+			nudge:       "gastown/refinery",
+			restartArgs: []string{"refinery", "restart", "gastown"},
+			warrant:     "gastown/refinery",
+		},
+		{
+			row:         toptui.AgentRow{Session: "gt-crew-max", Role: "crew", Rig: "gastown", Name: "max"},
+			nudge:       "gastown/crew/max",
+			restartArgs: []string{"crew", "restart", "gastown/max"},
+			warrant:     "gastown/crew/max",
+		},
+		{
+			row:         toptui.AgentRow{Session: "gt-alpha", Role: "polecat", Rig: "gastown", Name: "alpha"},
+			nudge:       "gastown/alpha",
+			restartArgs: []string{"session", "restart", "gastown/alpha"},
+			warrant:     "gastown/polecats/alpha",
+		},
+		{
+			row:         toptui.AgentRow{Session: "gt-mystery", Role: "unknown"},
+			nudge:       "gt-mystery",
+			restartArgs: nil,
+			warrant:     "gt-mystery",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.row.Session, func(t *testing.T) {
+			if got := topNudgeTarget(tt.row); got != tt.nudge {
+				t.Errorf("topNudgeTarget = %q, want %q", got, tt.nudge)
+			}
+			if got := topRestartArgs(tt.row); !reflect.DeepEqual(got, tt.restartArgs) {
+				t.Errorf("topRestartArgs = %v, want %v", got, tt.restartArgs)
+			}
+			if got := topWarrantTarget(tt.row); got != tt.warrant {
+				t.Errorf("topWarrantTarget = %q, want %q", got, tt.warrant)
+			}
+		})
+	}
+}
+
+func TestTopTokenRate(t *testing.T) {
+	now := time.Now()
+	prev := topUsageSample{tokens: 1000, at: now.Add(-2 * time.Minute)}
+
+	if got := topTokenRate(prev, 5000, now); got != 2000 {
+		t.Errorf("rate = %v, want 2000", got)
+	}
+	if got := topTokenRate(topUsageSample{}, 5000, now); got != 0 {
+		t.Errorf("rate without prior sample = %v, want 0", got)
+	}
+	if got := topTokenRate(prev, 10, now); got != 0 {
+		t.Errorf("rate after transcript reset = %v, want 0", got)
+	}
+}
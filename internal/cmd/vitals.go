@@ -6,35 +6,71 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/daemon"
 	"github.com/steveyegge/gastown/internal/doltserver"
+	"github.com/steveyegge/gastown/internal/procstat"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
+// vitalsSessionStaleAfter hides sessions whose last resource sample is
+// older than this (the session has ended or the daemon is down).
+const vitalsSessionStaleAfter = 5 * time.Minute
+
+// vitalsSeriesRows caps the rows printed for a session's time series.
+const vitalsSeriesRows = 30
+
+var (
+	vitalsSession string
+	vitalsSince   time.Duration
+)
+
 var vitalsCmd = &cobra.Command{
 	Use:     "vitals",
 	GroupID: GroupDiag,
 	Short:   "Show unified health dashboard",
-	RunE:    runVitals,
+	Long: `Show Dolt servers, databases, backups, and agent session resource usage.
+
+The Sessions section lists each agent session's process tree as last
+sampled by the daemon (processes, CPU, RSS, open files), with the peak
+over --since. Sessions over the daemon's runaway thresholds are marked.
+
+With --session, prints that session's sampled time series instead.
+
+Examples:
+  gt vitals
+  gt vitals --session gt-gastown-toast --since 6h`,
+	RunE: runVitals,
 }
 
-func init() { rootCmd.AddCommand(vitalsCmd) }
+func init() {
+	vitalsCmd.Flags().StringVar(&vitalsSession, "session", "", "Show the resource time series of one session")
+	vitalsCmd.Flags().DurationVar(&vitalsSince, "since", time.Hour, "Window for peaks and the time series")
+	rootCmd.AddCommand(vitalsCmd)
+}
 
 func runVitals(cmd *cobra.Command, args []string) error {
 	townRoot, err := workspace.FindFromCwdOrError()
 	if err != nil {
 		return fmt.Errorf("not in a Gas Town workspace: %w", err)
 	}
+	if vitalsSession != "" {
+		return printVitalsSeries(townRoot, vitalsSession, vitalsSince)
+	}
 	printVitalsDoltServers(townRoot)
 	fmt.Println()
 	printVitalsDatabases(townRoot)
 	fmt.Println()
 	printVitalsBackups(townRoot)
+	fmt.Println()
+	printVitalsSessions(townRoot, vitalsSince)
 	return nil
 }
 
@@ -57,21 +93,34 @@ func printVitalsDoltServers(townRoot string) {
 			style.Dim.Render("○"), config.Port, style.Dim.Render("not running"))
 	}
 
+	if rc := doltserver.LoadReplicaConfig(townRoot, config.Port); rc != nil {
+		role := "replica"
+		if config.FailoverPort > 0 {
+			role = "replica (serving, failed over)"
+		}
+		if running, pid := doltserver.IsReplicaRunning(townRoot); running {
+			fmt.Printf("  %s :%d  %s  PID %d\n", style.Success.Render("●"), rc.Port, role, pid)
+		} else {
+			fmt.Printf("  %s :%d  %s  %s\n", style.Warning.Render("○"), rc.Port, role, style.Dim.Render("not running"))
+		}
+	}
+
 	// Zombie dolt processes (test servers not cleaned up)
-	for _, z := range findVitalsZombies(config.Port) {
+	for _, z := range findVitalsZombies(doltserver.ExpectedPorts(townRoot)) {
 		fmt.Printf("  %s :%s test zombie PID %s\n", style.Warning.Render("○"), z.port, z.pid)
 	}
 }
 
 type vitalsZombie struct{ pid, port string }
 
-// findVitalsZombies finds Dolt servers not on the production port.
+// findVitalsZombies finds Dolt servers not on the production ports (the
+// primary's, plus the hot-standby replica's and its replication ports).
 // Uses lsof-based port discovery instead of pgrep/ps string matching (ZFC fix: gt-fj87).
-func findVitalsZombies(prodPort int) []vitalsZombie {
+func findVitalsZombies(prodPorts []int) []vitalsZombie {
 	listeners := doltserver.FindAllDoltListeners()
 	var zombies []vitalsZombie
 	for _, l := range listeners {
-		if l.Port == prodPort {
+		if slices.Contains(prodPorts, l.Port) {
 			continue
 		}
 		zombies = append(zombies, vitalsZombie{
@@ -133,7 +182,7 @@ func queryVitalsStats(config *doltserver.Config, dbName string) *vitalsStats {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	cmd := exec.CommandContext(ctx, "dolt",
-		"--host", "127.0.0.1", "--port", strconv.Itoa(config.Port),
+		"--host", "127.0.0.1", "--port", strconv.Itoa(config.EffectivePort()),
 		"--user", config.User, "--no-tls", "sql", "-r", "csv", "-q", q)
 	cmd.Env = append(os.Environ(), "DOLT_CLI_PASSWORD="+config.Password)
 	out, err := cmd.Output()
@@ -231,3 +280,76 @@ func vitalsShortHome(path string) string {
 	}
 	return path
 }
+
+func printVitalsSessions(townRoot string, window time.Duration) {
+	latest, _ := procstat.Latest(townRoot, vitalsSessionStaleAfter)
+	fmt.Printf("%s (%d sampled)\n", style.Bold.Render("Sessions"), len(latest))
+	if len(latest) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("no recent samples (is the daemon running?)"))
+		return
+	}
+
+	thresholds, _ := daemon.RunawayThresholds(daemon.LoadPatrolConfig(townRoot))
+	names := make([]string, 0, len(latest))
+	for name := range latest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("  %-28s %5s  %5s  %6s  %5s  %s\n",
+		style.Dim.Render("Session"), style.Dim.Render("Procs"), style.Dim.Render("CPU"),
+		style.Dim.Render("RSS"), style.Dim.Render("Files"), style.Dim.Render("Peak "+window.String()))
+	for _, name := range names {
+		s := latest[name]
+		series, _ := procstat.ReadSeries(townRoot, name, time.Now().Add(-window))
+		peak := procstat.Peak(series)
+		mark := " "
+		if len(thresholds.Exceeded(s)) > 0 {
+			mark = style.Warning.Render("!")
+		}
+		fmt.Printf("%s %-28s %5d  %4.0f%%  %6s  %5d  %s\n",
+			mark, name, s.Procs, s.CPUPercent, procstat.FormatKB(s.RSSKB), s.OpenFiles,
+			style.Dim.Render(fmt.Sprintf("%d procs, %.0f%%, %s", peak.Procs, peak.CPUPercent, procstat.FormatKB(peak.RSSKB))))
+		for _, reason := range thresholds.Exceeded(s) {
+			fmt.Printf("    %s %s\n", style.Warning.Render("!"), reason)
+		}
+	}
+}
+
+func printVitalsSeries(townRoot, sessionName string, window time.Duration) error {
+	series, err := procstat.ReadSeries(townRoot, sessionName, time.Now().Add(-window))
+	if err != nil {
+		return fmt.Errorf("reading resource series: %w", err)
+	}
+	if len(series) == 0 {
+		return fmt.Errorf("no resource samples for %s in the last %s", sessionName, window)
+	}
+
+	peak := procstat.Peak(series)
+	fmt.Printf("%s  %d samples, peak %d procs, %.0f%% CPU, %s RSS, %d files\n",
+		style.Bold.Render(sessionName), len(series),
+		peak.Procs, peak.CPUPercent, procstat.FormatKB(peak.RSSKB), peak.OpenFiles)
+	fmt.Printf("  %-8s %5s  %7s  %5s  %6s  %5s\n",
+		style.Dim.Render("Time"), style.Dim.Render("Procs"), style.Dim.Render("Threads"),
+		style.Dim.Render("CPU"), style.Dim.Render("RSS"), style.Dim.Render("Files"))
+	for _, s := range vitalsDownsample(series, vitalsSeriesRows) {
+		fmt.Printf("  %-8s %5d  %7d  %4.0f%%  %6s  %5d\n",
+			s.Time.Local().Format("15:04:05"), s.Procs, s.Threads, s.CPUPercent,
+			procstat.FormatKB(s.RSSKB), s.OpenFiles)
+	}
+	return nil
+}
+
+// vitalsDownsample keeps at most n samples, evenly spaced, always including
+// the most recent one.
+func vitalsDownsample(series []procstat.Sample, n int) []procstat.Sample {
+	if len(series) <= n || n < 2 {
+		return series
+	}
+	out := make([]procstat.Sample, 0, n)
+	step := float64(len(series)-1) / float64(n-1)
+	for i := 0; i < n; i++ {
+		out = append(out, series[int(float64(i)*step+0.5)])
+	}
+	return out
+}
@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/steveyegge/gastown/internal/procstat"
 )
 
 func TestVitalsFormatCount(t *testing.T) {
@@ -39,3 +41,20 @@ func TestVitalsShortHome(t *testing.T) {
 		t.Errorf("vitalsShortHome(/tmp/other) = %q, want /tmp/other", got)
 	}
 }
+
+func TestVitalsDownsample(t *testing.T) {
+	series := make([]procstat.Sample, 100)
+	for i := range series {
+		series[i].Procs = i
+	}
+	got := vitalsDownsample(series, 10)
+	if len(got) != 10 {
+		t.Fatalf("len = %d, want 10", len(got))
+	}
+	if got[0].Procs != 0 || got[9].Procs != 99 {
+		t.Errorf("endpoints = %d, %d; want 0, 99", got[0].Procs, got[9].Procs)
+	}
+	if short := vitalsDownsample(series[:5], 10); len(short) != 5 {
+		t.Errorf("short series downsampled to %d", len(short))
+	}
+}
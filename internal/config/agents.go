@@ -137,6 +137,10 @@ type AgentPresetInfo struct {
 	// EmitsPermissionWarning indicates the agent shows a bypass-permissions warning on startup
 	// that needs to be acknowledged via tmux.
 	EmitsPermissionWarning bool `json:"emits_permission_warning,omitempty"`
+
+	// ContextWindow is the model context window in tokens. gt prime sizes its
+	// output from it. Zero means DefaultContextWindow.
+	ContextWindow int `json:"context_window,omitempty"`
 }
 
 // NonInteractiveConfig contains settings for running agents non-interactively.
@@ -191,6 +195,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		ReadyDelayMs:           10000,
 		InstructionsFile:       "CLAUDE.md",
 		EmitsPermissionWarning: true,
+		ContextWindow:          200_000,
 	},
 	AgentGemini: {
 		Name:                AgentGemini,
@@ -214,6 +219,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		HooksSettingsFile: "settings.json",
 		ReadyDelayMs:      5000,
 		InstructionsFile:  "AGENTS.md",
+		ContextWindow:     1_000_000,
 	},
 	AgentCodex: {
 		Name:                AgentCodex,
@@ -526,10 +532,11 @@ func RuntimeConfigFromPreset(preset AgentPreset) *RuntimeConfig {
 	}
 
 	rc := &RuntimeConfig{
-		Provider: string(info.Name),
-		Command: info.Command,
-		Args:    append([]string(nil), info.Args...), // Copy to avoid mutation
-		Env:     envCopy,
+		Provider:      string(info.Name),
+		Command:       info.Command,
+		Args:          append([]string(nil), info.Args...), // Copy to avoid mutation
+		Env:           envCopy,
+		ContextWindow: info.ContextWindow,
 	}
 
 	// Resolve command path for claude preset (handles alias installations)
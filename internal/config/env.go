@@ -138,6 +138,11 @@ func AgentEnv(cfg AgentEnvConfig) map[string]string {
 		// This stops accidental commits to the umbrella when running git commands from
 		// intermediate directories (e.g., polecats/) that don't have their own .git.
 		env["GIT_CEILING_DIRECTORIES"] = cfg.TownRoot
+
+		// No Dolt port here, even during a failover: the daemon repoints
+		// each rig's metadata.json at the serving server, so bd in a
+		// long-running session follows failover and failback. A port
+		// pinned in the session environment would outlive either.
 	}
 
 	// Set BEADS_AGENT_NAME for polecat/crew (uses same format as BD_ACTOR)
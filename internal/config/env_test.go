@@ -1,6 +1,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -74,6 +76,34 @@ func TestAgentEnv_Crew(t *testing.T) {
 	assertEnv(t, env, "BEADS_AGENT_NAME", "myrig/emma")
 }
 
+// TestAgentEnv_NoDoltPortDuringFailover checks that sessions started during
+// a failover don't pin the replica's port: bd follows the failover through
+// metadata.json, and a pinned port would outlive the failback.
+func TestAgentEnv_NoDoltPortDuringFailover(t *testing.T) {
+	t.Parallel()
+	townRoot := t.TempDir()
+	path := DoltFailoverPath(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"active": true, "primary_port": 3307, "replica_port": 3308}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if port := DoltFailoverPort(townRoot); port != 3308 {
+		t.Errorf("DoltFailoverPort() with active marker = %d, want 3308", port)
+	}
+	env := AgentEnv(AgentEnvConfig{Role: "mayor", TownRoot: townRoot})
+	assertNotSet(t, env, "GT_DOLT_PORT")
+	assertNotSet(t, env, "BEADS_DOLT_PORT")
+
+	if err := os.WriteFile(path, []byte(`{"active": false, "replica_port": 3308}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if port := DoltFailoverPort(townRoot); port != 0 {
+		t.Errorf("DoltFailoverPort() with inactive marker = %d, want 0", port)
+	}
+}
+
 func TestAgentEnv_Refinery(t *testing.T) {
 	t.Parallel()
 	env := AgentEnv(AgentEnvConfig{
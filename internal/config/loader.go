@@ -219,6 +219,28 @@ func validateRigSettings(c *RigSettings) error {
 			return err
 		}
 	}
+	if c.Sandbox != nil {
+		if err := validateSandboxConfig(c.Sandbox); err != nil {
+			return err
+		}
+	}
+	if c.PushPolicy != nil && c.PushPolicy.MaxPackBytes < 0 {
+		return fmt.Errorf("push_policy.max_pack_bytes must not be negative, got %d", c.PushPolicy.MaxPackBytes)
+	}
+	return nil
+}
+
+// validateSandboxConfig validates a SandboxConfig. Resource limits need a
+// cgroup_parent: the session's own cgroup holds the pane shell, so the
+// kernel refuses to enable controllers in it.
+func validateSandboxConfig(c *SandboxConfig) error {
+	hasLimits := c.CPUs > 0 || c.MemoryMB > 0 || c.Pids > 0
+	if hasLimits && c.CgroupParent == "" {
+		return fmt.Errorf("sandbox.cgroup_parent is required when cpus, memory_mb or pids is set")
+	}
+	if c.CgroupParent != "" && !filepath.IsAbs(c.CgroupParent) {
+		return fmt.Errorf("sandbox.cgroup_parent must be an absolute path, got %q", c.CgroupParent)
+	}
 	return nil
 }
 
@@ -951,6 +973,119 @@ func LoadOrCreateMessagingConfig(path string) (*MessagingConfig, error) {
 	return config, nil
 }
 
+// FederationConfigPath returns the standard path for federation config in a town.
+func FederationConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, "settings", "federation.json")
+}
+
+// LoadFederationConfig loads and validates a federation configuration file.
+func LoadFederationConfig(path string) (*FederationConfig, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally, not from user input
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		return nil, fmt.Errorf("reading federation config: %w", err)
+	}
+
+	var config FederationConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing federation config: %w", err)
+	}
+
+	if err := validateFederationConfig(&config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// LoadOrCreateFederationConfig loads the federation config, returning an
+// empty one (no peers) if the file doesn't exist.
+func LoadOrCreateFederationConfig(path string) (*FederationConfig, error) {
+	config, err := LoadFederationConfig(path)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return NewFederationConfig(), nil
+		}
+		return nil, err
+	}
+	return config, nil
+}
+
+// SaveFederationConfig saves a federation configuration to a file.
+func SaveFederationConfig(path string, config *FederationConfig) error {
+	if err := validateFederationConfig(config); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding federation config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil { //nolint:gosec // G306: peer CA certificates are public
+		return fmt.Errorf("writing federation config: %w", err)
+	}
+
+	return nil
+}
+
+// validateFederationConfig validates a FederationConfig.
+func validateFederationConfig(c *FederationConfig) error {
+	if c.Type != "federation" && c.Type != "" {
+		return fmt.Errorf("%w: expected type 'federation', got '%s'", ErrInvalidType, c.Type)
+	}
+	if c.Version > CurrentFederationVersion {
+		return fmt.Errorf("%w: got %d, max supported %d", ErrInvalidVersion, c.Version, CurrentFederationVersion)
+	}
+
+	if c.Peers == nil {
+		c.Peers = make(map[string]*FederationPeer)
+	}
+
+	for name, peer := range c.Peers {
+		if peer == nil || peer.URL == "" {
+			return fmt.Errorf("%w: peer '%s' url", ErrMissingField, name)
+		}
+		if peer.CA == "" {
+			return fmt.Errorf("%w: peer '%s' ca", ErrMissingField, name)
+		}
+	}
+
+	return nil
+}
+
+// DoltFailoverPath returns the path to the Dolt failover marker, written by
+// the daemon (via doltserver) when it promotes the hot-standby replica.
+func DoltFailoverPath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "dolt-failover.json")
+}
+
+// DoltFailoverPort returns the port Dolt clients should use instead of the
+// primary's while a failover is active, or 0 if there is none.
+// doltserver owns the file; only the fields needed here are parsed, so that
+// beads and agent environments can follow a failover without importing
+// doltserver (which imports beads).
+func DoltFailoverPort(townRoot string) int {
+	data, err := os.ReadFile(DoltFailoverPath(townRoot))
+	if err != nil {
+		return 0
+	}
+	var state struct {
+		Active      bool `json:"active"`
+		ReplicaPort int  `json:"replica_port"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil || !state.Active {
+		return 0
+	}
+	return state.ReplicaPort
+}
+
 // TownSettingsPath returns the path to town settings file.
 func TownSettingsPath(townRoot string) string {
 	return filepath.Join(townRoot, "settings", "config.json")
@@ -1595,6 +1730,8 @@ func fillRuntimeDefaults(rc *RuntimeConfig) *RuntimeConfig {
 		InitialPrompt: rc.InitialPrompt,
 		PromptMode:    rc.PromptMode,
 		ResolvedAgent: rc.ResolvedAgent,
+		ContextWindow: rc.ContextWindow,
+		PrimeBudget:   rc.PrimeBudget,
 	}
 
 	// Deep copy Args slice to avoid sharing backing array
@@ -1663,6 +1800,9 @@ func fillRuntimeDefaults(rc *RuntimeConfig) *RuntimeConfig {
 	if result.Args == nil && preset != nil {
 		result.Args = append([]string(nil), preset.Args...)
 	}
+	if result.ContextWindow == 0 && preset != nil {
+		result.ContextWindow = preset.ContextWindow
+	}
 
 	// Auto-fill Hooks defaults from preset for agents that support hooks.
 	if result.Hooks == nil && preset != nil && preset.HooksProvider != "" {
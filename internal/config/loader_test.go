@@ -372,6 +372,33 @@ func TestRigSettingsValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "sandbox limits with cgroup_parent",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{Enabled: true, MemoryMB: 4096, CgroupParent: "/sys/fs/cgroup/gt"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "sandbox limits without cgroup_parent",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{Enabled: true, Pids: 512},
+			},
+			wantErr: true,
+		},
+		{
+			name: "relative sandbox cgroup_parent",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{Enabled: true, CPUs: 2, CgroupParent: "gt"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
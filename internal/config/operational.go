@@ -102,6 +102,7 @@ const (
 	DefaultWitnessMaxBeadRespawns        = 3
 	DefaultWitnessDoneIntentStuckTimeout = 60 * time.Second
 	DefaultWitnessDoneIntentRecentGrace  = 30 * time.Second
+	DefaultWitnessCheckpointInterval     = 10 * time.Minute
 )
 
 // LoadOperationalConfig loads operational config from a town root.
@@ -679,3 +680,11 @@ func (wt *WitnessThresholds) DoneIntentRecentGraceD() time.Duration {
 	}
 	return DefaultWitnessDoneIntentRecentGrace
 }
+
+// CheckpointIntervalD returns the configured or default work snapshot interval.
+func (wt *WitnessThresholds) CheckpointIntervalD() time.Duration {
+	if wt != nil {
+		return ParseDurationOrDefault(wt.CheckpointInterval, DefaultWitnessCheckpointInterval)
+	}
+	return DefaultWitnessCheckpointInterval
+}
@@ -331,6 +331,9 @@ func TestWitnessThresholds_Defaults(t *testing.T) {
 	if got := wit.DoneIntentRecentGraceD(); got != DefaultWitnessDoneIntentRecentGrace {
 		t.Errorf("DoneIntentRecentGrace: got %v, want %v", got, DefaultWitnessDoneIntentRecentGrace)
 	}
+	if got := wit.CheckpointIntervalD(); got != DefaultWitnessCheckpointInterval {
+		t.Errorf("CheckpointInterval: got %v, want %v", got, DefaultWitnessCheckpointInterval)
+	}
 }
 
 func TestWitnessThresholds_Overrides(t *testing.T) {
@@ -344,6 +347,7 @@ func TestWitnessThresholds_Overrides(t *testing.T) {
 			MaxBeadRespawns:        &maxRespawns,
 			DoneIntentStuckTimeout: "90s",
 			DoneIntentRecentGrace:  "15s",
+			CheckpointInterval:     "5m",
 		},
 	}
 
@@ -363,4 +367,7 @@ func TestWitnessThresholds_Overrides(t *testing.T) {
 	if got := wit.DoneIntentRecentGraceD(); got != 15*time.Second {
 		t.Errorf("DoneIntentRecentGrace: got %v, want 15s", got)
 	}
+	if got := wit.CheckpointIntervalD(); got != 5*time.Minute {
+		t.Errorf("CheckpointInterval: got %v, want 5m", got)
+	}
 }
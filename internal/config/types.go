@@ -96,6 +96,11 @@ type TownSettings struct {
 	// These were previously hardcoded as Go constants throughout the codebase.
 	// All values are optional — omitted values use compiled-in defaults.
 	Operational *OperationalConfig `json:"operational,omitempty"`
+
+	// SessionBackend selects where agent sessions run.
+	// Values: "tmux" (default), "pty" (daemon-owned PTY supervisor, no tmux).
+	// Can be overridden by GT_SESSION_BACKEND environment variable.
+	SessionBackend string `json:"session_backend,omitempty"`
 }
 
 // NewTownSettings creates a new TownSettings with defaults.
@@ -429,6 +434,10 @@ type WitnessThresholds struct {
 	// DoneIntentRecentGrace is how recently a done-intent must have been created
 	// to be considered still in progress (default "30s").
 	DoneIntentRecentGrace string `json:"done_intent_recent_grace,omitempty"`
+
+	// CheckpointInterval is the minimum age of a polecat's work snapshot
+	// before the witness patrol takes a new one (default "10m").
+	CheckpointInterval string `json:"checkpoint_interval,omitempty"`
 }
 
 // DefaultOperationalConfig returns an OperationalConfig with all defaults.
@@ -586,6 +595,68 @@ type WorkflowConfig struct {
 	DefaultFormula string `json:"default_formula,omitempty"`
 }
 
+// RecordingConfig controls session recording for a rig's polecats.
+type RecordingConfig struct {
+	// Enabled turns on asciicast recording of polecat panes via tmux
+	// pipe-pane. Recordings live under .runtime/recordings and are pruned
+	// by KRC using the session_recording TTL. Default: false.
+	Enabled bool `json:"enabled"`
+}
+
+// SandboxConfig controls namespace sandboxing for a rig's polecats.
+type SandboxConfig struct {
+	// Enabled runs polecats under "gt sandbox exec": user, mount and pid
+	// namespaces with the filesystem read-only except the worktree, its git
+	// directory and WritablePaths. Linux only. Default: false.
+	Enabled bool `json:"enabled"`
+
+	// Network is "host" (default), "none" (loopback only) or "proxy"
+	// (loopback only, with ProxyAddr relayed in for gt-proxy-client).
+	Network string `json:"network,omitempty"`
+
+	// ProxyAddr is the gt-proxy-server host:port relayed into "proxy"
+	// sandboxes. Inside, GT_PROXY_URL points at 127.0.0.1 on the same port.
+	ProxyAddr string `json:"proxy_addr,omitempty"`
+
+	// WritablePaths are extra paths left writable, e.g. "~/.claude".
+	WritablePaths []string `json:"writable_paths,omitempty"`
+
+	// cgroup v2 limits; zero means unlimited.
+	CPUs     float64 `json:"cpus,omitempty"`      // CPU cores (cpu.max)
+	MemoryMB int     `json:"memory_mb,omitempty"` // memory.max in MiB
+	Pids     int     `json:"pids,omitempty"`      // pids.max
+
+	// CgroupParent is a delegated cgroup v2 directory to create polecat
+	// cgroups under. Required when any limit is set; it must hold no
+	// processes itself.
+	CgroupParent string `json:"cgroup_parent,omitempty"`
+}
+
+// PushPolicyConfig restricts what polecats may push through gt-proxy-server.
+// It applies on top of the proxy's refs/heads/polecat/<name>-* scoping and is
+// re-read on every push, so edits take effect without restarting the proxy.
+type PushPolicyConfig struct {
+	// AllowedRefs are the ref patterns a push may create or update. "*"
+	// matches any run of characters (including "/") and "{polecat}" expands
+	// to the pushing polecat's name. Empty allows any ref in scope.
+	AllowedRefs []string `json:"allowed_refs,omitempty"`
+
+	// NoDelete rejects ref deletions.
+	NoDelete bool `json:"no_delete,omitempty"`
+
+	// NoForcePush rejects updates that are not fast-forwards.
+	NoForcePush bool `json:"no_force_push,omitempty"`
+
+	// ProtectedPaths are repo-relative paths no pushed commit may add,
+	// modify or delete, e.g. ".github/workflows", "go.mod" or "**/go.sum".
+	// "*" matches within a path segment, "**" across segments, and a
+	// pattern naming a directory protects everything under it.
+	ProtectedPaths []string `json:"protected_paths,omitempty"`
+
+	// MaxPackBytes caps the size of the pushed pack. 0 means unlimited.
+	MaxPackBytes int64 `json:"max_pack_bytes,omitempty"`
+}
+
 // RigSettings represents per-rig behavioral configuration (settings/config.json).
 type RigSettings struct {
 	Type       string            `json:"type"`                  // "rig-settings"
@@ -595,6 +666,9 @@ type RigSettings struct {
 	Namepool   *NamepoolConfig   `json:"namepool,omitempty"`    // polecat name pool settings
 	Crew       *CrewConfig       `json:"crew,omitempty"`        // crew startup settings
 	Workflow   *WorkflowConfig   `json:"workflow,omitempty"`    // workflow settings
+	Recording  *RecordingConfig  `json:"recording,omitempty"`   // polecat session recording
+	Sandbox    *SandboxConfig    `json:"sandbox,omitempty"`     // polecat namespace sandbox
+	PushPolicy *PushPolicyConfig `json:"push_policy,omitempty"` // proxy push policy
 	Runtime    *RuntimeConfig    `json:"runtime,omitempty"`     // LLM runtime settings (deprecated: use Agent)
 
 	// Agent selects which agent preset to use for this rig.
@@ -681,6 +755,14 @@ type RuntimeConfig struct {
 	// Instructions controls the per-workspace instruction file name.
 	Instructions *RuntimeInstructionsConfig `json:"instructions,omitempty"`
 
+	// ContextWindow is the model context window in tokens.
+	// Default: the agent preset's window, else DefaultContextWindow.
+	ContextWindow int `json:"context_window,omitempty"`
+
+	// PrimeBudget caps gt prime output in tokens.
+	// Default: DefaultPrimeBudgetPercent of ContextWindow.
+	PrimeBudget int `json:"prime_budget,omitempty"`
+
 	// ResolvedAgent is the agent name that was resolved during config lookup.
 	// Set by ResolveRoleAgentConfig / resolveAgentConfigInternal so that
 	// BuildStartupCommand can export GT_AGENT for process detection.
@@ -805,6 +887,29 @@ func (rc *RuntimeConfig) BuildArgsWithPrompt(prompt string) []string {
 	return args
 }
 
+// Context budget defaults.
+const (
+	DefaultContextWindow      = 200_000
+	DefaultPrimeBudgetPercent = 10
+)
+
+// PrimeTokenBudget returns how many tokens gt prime may emit for this runtime.
+func (rc *RuntimeConfig) PrimeTokenBudget() int {
+	if rc != nil && rc.PrimeBudget > 0 {
+		return rc.PrimeBudget
+	}
+	return rc.ContextWindowTokens() * DefaultPrimeBudgetPercent / 100
+}
+
+// ContextWindowTokens returns the configured context window, or
+// DefaultContextWindow when unset.
+func (rc *RuntimeConfig) ContextWindowTokens() int {
+	if rc != nil && rc.ContextWindow > 0 {
+		return rc.ContextWindow
+	}
+	return DefaultContextWindow
+}
+
 func normalizeRuntimeConfig(rc *RuntimeConfig) *RuntimeConfig {
 	if rc == nil {
 		rc = &RuntimeConfig{}
@@ -1377,6 +1482,56 @@ func NewMessagingConfig() *MessagingConfig {
 	}
 }
 
+// FederationConfig represents the town's federation settings (settings/federation.json).
+// It lists the peer towns this town exchanges mail, convoy views and delegated
+// work with, and where this town's own federation server listens.
+type FederationConfig struct {
+	Type    string `json:"type"`    // "federation"
+	Version int    `json:"version"` // schema version
+
+	// ListenAddr is the address `gt federation serve` listens on (default ":9880").
+	ListenAddr string `json:"listen_addr,omitempty"`
+
+	// ExtraSANIPs and ExtraSANHosts are added to the server certificate so
+	// peers can reach this town by NAT/VPN addresses or DNS names that are not
+	// local interface addresses.
+	ExtraSANIPs   []string `json:"extra_san_ips,omitempty"`
+	ExtraSANHosts []string `json:"extra_san_hosts,omitempty"`
+
+	// Peers maps peer town names (as used in town:rig/agent addresses) to how
+	// to reach and authenticate them.
+	Peers map[string]*FederationPeer `json:"peers,omitempty"`
+}
+
+// FederationPeer is one peer town.
+type FederationPeer struct {
+	// URL is the peer's federation server, e.g. "https://10.0.0.5:9880".
+	URL string `json:"url"`
+
+	// CA is the PEM certificate of the peer town's CA. The peer's server
+	// certificate and the client certificate it presents must chain to it.
+	CA string `json:"ca"`
+
+	// AddedAt records when the peering was set up.
+	AddedAt time.Time `json:"added_at,omitempty"`
+}
+
+// CurrentFederationVersion is the current schema version for FederationConfig.
+const CurrentFederationVersion = 1
+
+// DefaultFederationListenAddr is where `gt federation serve` listens when
+// FederationConfig.ListenAddr is empty.
+const DefaultFederationListenAddr = ":9880"
+
+// NewFederationConfig creates a new FederationConfig with no peers.
+func NewFederationConfig() *FederationConfig {
+	return &FederationConfig{
+		Type:    "federation",
+		Version: CurrentFederationVersion,
+		Peers:   make(map[string]*FederationPeer),
+	}
+}
+
 // EscalationConfig represents escalation routing configuration (settings/escalation.json).
 // This defines severity-based routing for escalations to different channels.
 type EscalationConfig struct {
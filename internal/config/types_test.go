@@ -581,4 +581,38 @@ func TestParseDurationOrDefault_AllWebTimeoutDefaults(t *testing.T) {
 	}
 }
 
+func TestRuntimeConfig_PrimeTokenBudget(t *testing.T) {
+	t.Parallel()
 
+	tests := []struct {
+		name   string
+		rc     *RuntimeConfig
+		window int
+		budget int
+	}{
+		{"nil", nil, DefaultContextWindow, DefaultContextWindow / 10},
+		{"unset", &RuntimeConfig{}, DefaultContextWindow, DefaultContextWindow / 10},
+		{"window", &RuntimeConfig{ContextWindow: 32_000}, 32_000, 3_200},
+		{"explicit budget", &RuntimeConfig{ContextWindow: 32_000, PrimeBudget: 5_000}, 32_000, 5_000},
+	}
+	for _, tt := range tests {
+		if got := tt.rc.ContextWindowTokens(); got != tt.window {
+			t.Errorf("%s: ContextWindowTokens() = %d, want %d", tt.name, got, tt.window)
+		}
+		if got := tt.rc.PrimeTokenBudget(); got != tt.budget {
+			t.Errorf("%s: PrimeTokenBudget() = %d, want %d", tt.name, got, tt.budget)
+		}
+	}
+}
+
+func TestFillRuntimeDefaults_ContextWindow(t *testing.T) {
+	t.Parallel()
+
+	if got := fillRuntimeDefaults(&RuntimeConfig{Provider: "gemini"}).ContextWindow; got != 1_000_000 {
+		t.Errorf("gemini ContextWindow = %d, want preset 1000000", got)
+	}
+	got := fillRuntimeDefaults(&RuntimeConfig{Provider: "gemini", ContextWindow: 64_000, PrimeBudget: 2_000})
+	if got.ContextWindow != 64_000 || got.PrimeBudget != 2_000 {
+		t.Errorf("explicit window/budget not preserved: %d/%d", got.ContextWindow, got.PrimeBudget)
+	}
+}
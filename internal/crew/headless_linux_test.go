@@ -0,0 +1,102 @@
+//go:build linux
+
+package crew
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/ptyd"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// TestStart_HeadlessTown starts a crew member on a town that runs agents
+// under the PTY supervisor instead of tmux.
+func TestStart_HeadlessTown(t *testing.T) {
+	if _, err := os.Stat("/dev/ptmx"); err != nil {
+		t.Skip("no /dev/ptmx")
+	}
+	t.Setenv("GT_SESSION_BACKEND", "")
+
+	// Unix socket paths are length-limited; t.TempDir() can be too long.
+	town, err := os.MkdirTemp("", "gt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(town) })
+
+	// The fake agent prints its ready prompt (ending in the ❯ the dialog
+	// poll waits for) and waits.
+	agent := filepath.Join(town, "fake-agent")
+	if err := os.WriteFile(agent, []byte("#!/bin/sh\necho fake-ready ❯\nexec cat\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	settings := config.NewTownSettings()
+	settings.SessionBackend = "pty"
+	settings.Agents = map[string]*config.RuntimeConfig{
+		"fake": {
+			Command: agent,
+			Tmux:    &config.RuntimeTmuxConfig{ReadyPromptPrefix: "fake-ready"},
+		},
+	}
+	if err := config.SaveTownSettings(config.TownSettingsPath(town), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	sock := ptyd.SocketPath(town)
+	if err := os.MkdirAll(filepath.Dir(sock), 0755); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := ptyd.Listen(sock)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	s := ptyd.NewSupervisor(0)
+	go func() { _ = s.Serve(ln) }()
+	t.Cleanup(func() {
+		_ = ln.Close()
+		s.Close()
+	})
+
+	bareRepoPath := filepath.Join(town, "bare-repo.git")
+	if err := runCmd("git", "init", "--bare", bareRepoPath); err != nil {
+		t.Fatalf("failed to create bare repo: %v", err)
+	}
+	rigPath := filepath.Join(town, "test-rig")
+	if err := os.MkdirAll(rigPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	r := &rig.Rig{Name: "test-rig", Path: rigPath, GitURL: bareRepoPath}
+	mgr := NewManager(r, git.NewGit(rigPath))
+
+	if err := mgr.Start("dave", StartOptions{AgentOverride: "fake"}); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = mgr.Stop("dave") })
+
+	sessionID := mgr.SessionName("dave")
+	client := ptyd.NewClient(sock)
+	if ok, err := client.HasSession(sessionID); err != nil || !ok {
+		t.Fatalf("supervisor HasSession = %v, %v; want true", ok, err)
+	}
+	if running, err := mgr.IsRunning("dave"); err != nil || !running {
+		t.Fatalf("IsRunning = %v, %v; want true", running, err)
+	}
+	if env, _ := client.GetEnvironment(sessionID, "GT_ROLE"); env != "test-rig/crew/dave" {
+		t.Errorf("GT_ROLE = %q, want test-rig/crew/dave", env)
+	}
+	if err := mgr.Start("dave", StartOptions{AgentOverride: "fake"}); !errors.Is(err, ErrSessionRunning) {
+		t.Errorf("second Start = %v, want ErrSessionRunning", err)
+	}
+
+	if err := mgr.Stop("dave"); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if running, _ := mgr.IsRunning("dave"); running {
+		t.Error("crew member still running after Stop")
+	}
+}
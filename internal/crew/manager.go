@@ -787,12 +787,12 @@ func (m *Manager) Start(name string, opts StartOptions) error {
 		}
 	}
 
-	t := tmux.NewTmux()
+	sessions := m.sessions()
 	sessionID := m.SessionName(name)
 
 	// Check if session already exists — kill AFTER command is fully built
 	// so validation failures don't destroy the user's running session.
-	running, err := t.HasSession(sessionID)
+	running, err := sessions.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -800,17 +800,17 @@ func (m *Manager) Start(name string, opts StartOptions) error {
 		if opts.KillExisting {
 			// Restart/resume mode - kill existing session.
 			// Use KillSessionWithProcesses to ensure all descendant processes are killed.
-			if err := t.KillSessionWithProcesses(sessionID); err != nil {
+			if err := sessions.KillSessionWithProcesses(sessionID); err != nil {
 				return fmt.Errorf("killing existing session: %w", err)
 			}
 		} else {
 			// Normal start - session exists, check if agent is actually running
-			if t.IsAgentAlive(sessionID) {
+			if sessions.IsAgentAlive(sessionID) {
 				return fmt.Errorf("%w: %s", ErrSessionRunning, sessionID)
 			}
 			// Zombie session - kill and recreate.
 			// Use KillSessionWithProcesses to ensure all descendant processes are killed.
-			if err := t.KillSessionWithProcesses(sessionID); err != nil {
+			if err := sessions.KillSessionWithProcesses(sessionID); err != nil {
 				return fmt.Errorf("killing zombie session: %w", err)
 			}
 		}
@@ -821,6 +821,25 @@ func (m *Manager) Start(name string, opts StartOptions) error {
 		claudeCmd = strings.Replace(claudeCmd, " --dangerously-skip-permissions", "", 1)
 	}
 
+	// Headless towns run the agent under the daemon's PTY supervisor; the
+	// tmux theming, key bindings and PID tracking below don't apply.
+	t, ok := sessions.(*tmux.Tmux)
+	if !ok {
+		if opts.Interactive {
+			if err := sessions.NewSessionWithCommandAndEnv(sessionID, worker.ClonePath, claudeCmd, envVars); err != nil {
+				return fmt.Errorf("creating session: %w", err)
+			}
+			return nil
+		}
+		// Wait for the prompt of the agent actually being started.
+		if opts.AgentOverride != "" {
+			if rc, _, err := config.ResolveAgentConfigWithOverride(townRoot, m.rig.Path, opts.AgentOverride); err == nil {
+				runtimeConfig = rc
+			}
+		}
+		return session.StartHeadlessAgent(sessions, sessionID, worker.ClonePath, claudeCmd, envVars, runtimeConfig)
+	}
+
 	// Create session with command and env vars via -e flags.
 	// The -e flags set session-level env BEFORE the shell starts, ensuring the
 	// initial shell inherits the correct GT_ROLE (not the parent's).
@@ -876,7 +895,7 @@ func (m *Manager) Stop(name string) error {
 		return err
 	}
 
-	t := tmux.NewTmux()
+	t := m.sessions()
 	sessionID := m.SessionName(name)
 
 	// Check if session exists
@@ -900,7 +919,12 @@ func (m *Manager) Stop(name string) error {
 
 // IsRunning checks if a crew member's session is active.
 func (m *Manager) IsRunning(name string) (bool, error) {
-	t := tmux.NewTmux()
 	sessionID := m.SessionName(name)
-	return t.HasSession(sessionID)
+	return m.sessions().HasSession(sessionID)
+}
+
+// sessions returns the session backend crew sessions live in: the daemon's
+// PTY supervisor for headless towns, tmux otherwise.
+func (m *Manager) sessions() session.SessionBackend {
+	return session.NewBackend(filepath.Dir(m.rig.Path), nil)
 }
@@ -213,6 +213,22 @@ func (m *ConvoyManager) runEventPoll() {
 	}
 }
 
+// beadsStores returns a snapshot of the beads stores the manager polls, or
+// nil if they have not been opened yet. Other daemon patrols use it so that
+// stores opened lazily after a slow Dolt start are shared.
+func (m *ConvoyManager) beadsStores() map[string]beadsdk.Storage {
+	m.storesMu.Lock()
+	defer m.storesMu.Unlock()
+	if len(m.stores) == 0 {
+		return nil
+	}
+	snapshot := make(map[string]beadsdk.Storage, len(m.stores))
+	for k, v := range m.stores {
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
 // pollStoresSnapshot polls events from all non-parked stores in the snapshot.
 // The first call is a warm-up: it advances high-water marks without
 // processing events, preventing a burst of historical replay on restart.
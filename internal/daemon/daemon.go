@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -18,6 +19,7 @@ import (
 	"github.com/gofrs/flock"
 	beadsdk "github.com/steveyegge/beads"
 	"gopkg.in/natefinch/lumberjack.v2"
+	"github.com/steveyegge/gastown/internal/approval"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/boot"
 	"github.com/steveyegge/gastown/internal/constants"
@@ -27,6 +29,8 @@ import (
 	"github.com/steveyegge/gastown/internal/feed"
 	gitpkg "github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mayor"
+	"github.com/steveyegge/gastown/internal/procstat"
+	"github.com/steveyegge/gastown/internal/ptyd"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/session"
@@ -54,6 +58,11 @@ type Daemon struct {
 	doltServer *DoltServerManager
 	krcPruner  *KRCPruner
 
+	// ptySupervisor runs agent sessions for headless towns
+	// (session_backend "pty"). Nil when the town uses tmux.
+	ptySupervisor *ptyd.Supervisor
+	ptyListener   net.Listener
+
 	// Mass death detection: track recent session deaths
 	deathsMu     sync.Mutex
 	recentDeaths []sessionDeath
@@ -97,6 +106,15 @@ type Daemon struct {
 	// lastMaintenanceRun tracks when scheduled maintenance last ran.
 	// Only accessed from heartbeat loop goroutine - no sync needed.
 	lastMaintenanceRun time.Time
+
+	// resourceSampler and runawayDetector keep per-session history for the
+	// resource_sampler patrol. Only accessed from the main loop goroutine.
+	resourceSampler *procstat.Sampler
+	runawayDetector *procstat.Detector
+
+	// pluginEvents holds the plugin_events patrol's cursors and pending
+	// triggers. Only accessed from the main loop goroutine.
+	pluginEvents *pluginEventState
 }
 
 // sessionDeath records a detected session death for mass death analysis.
@@ -310,6 +328,15 @@ func (d *Daemon) Run() error {
 		d.logger.Println("Feed curator started")
 	}
 
+	// Start the PTY supervisor for headless towns before anything that
+	// starts agent sessions.
+	if session.IsHeadless(d.config.TownRoot) {
+		if err := d.startPTYSupervisor(); err != nil {
+			return fmt.Errorf("starting pty supervisor: %w", err)
+		}
+		d.logger.Printf("PTY supervisor listening on %s", ptyd.SocketPath(d.config.TownRoot))
+	}
+
 	// Start convoy manager (event-driven + periodic stranded scan)
 	// Try opening beads stores eagerly; if Dolt isn't ready yet,
 	// pass the opener as a callback for lazy retry on each poll tick.
@@ -366,6 +393,19 @@ func (d *Daemon) Run() error {
 		d.logger.Printf("Dolt health check ticker started (interval %v)", interval)
 	}
 
+	// Start Dolt SLO probe ticker. The health check above catches a dead
+	// server; this catches a slow or flaky one and fails over to the
+	// hot-standby replica when one is enabled.
+	var doltSLOTicker *time.Ticker
+	var doltSLOChan <-chan time.Time
+	if d.doltServer != nil && d.doltServer.SLOEnabled() {
+		interval := d.doltServer.SLOProbeInterval()
+		doltSLOTicker = time.NewTicker(interval)
+		doltSLOChan = doltSLOTicker.C
+		defer doltSLOTicker.Stop()
+		d.logger.Printf("Dolt SLO probe ticker started (interval %v)", interval)
+	}
+
 	// Start dedicated Dolt remotes push ticker if configured.
 	// This runs at a lower frequency (default 15 min) than the heartbeat (3 min)
 	// to periodically push databases to their git remotes.
@@ -453,6 +493,30 @@ func (d *Daemon) Run() error {
 		d.logger.Printf("Scheduled maintenance ticker started (check interval %v, window %s)", interval, window)
 	}
 
+	// Start resource sampler ticker (default on, Linux only).
+	// Samples each agent session's process tree and flags runaways.
+	var resourceSamplerTicker *time.Ticker
+	var resourceSamplerChan <-chan time.Time
+	if IsPatrolEnabled(d.patrolConfig, "resource_sampler") && procstat.Supported() {
+		interval := resourceSamplerInterval(d.patrolConfig)
+		resourceSamplerTicker = time.NewTicker(interval)
+		resourceSamplerChan = resourceSamplerTicker.C
+		defer resourceSamplerTicker.Stop()
+		d.logger.Printf("Resource sampler ticker started (interval %v)", interval)
+	}
+
+	// Start plugin events ticker (default on).
+	// Dispatches plugins whose event gates match new town events.
+	var pluginEventsTicker *time.Ticker
+	var pluginEventsChan <-chan time.Time
+	if IsPatrolEnabled(d.patrolConfig, "plugin_events") {
+		interval := pluginEventsInterval(d.patrolConfig)
+		pluginEventsTicker = time.NewTicker(interval)
+		pluginEventsChan = pluginEventsTicker.C
+		defer pluginEventsTicker.Stop()
+		d.logger.Printf("Plugin events ticker started (interval %v)", interval)
+	}
+
 	// Note: PATCH-010 uses per-session hooks in deacon/manager.go (SetAutoRespawnHook).
 	// Global pane-died hooks don't fire reliably in tmux 3.2a, so we rely on the
 	// per-session approach which has been tested to work for continuous recovery.
@@ -491,6 +555,13 @@ func (d *Daemon) Run() error {
 				d.ensureDoltServerRunning()
 			}
 
+		case <-doltSLOChan:
+			// Dolt SLO probe — p95 latency and error rate over a sliding
+			// window; a breach promotes the hot-standby replica.
+			if !d.isShutdownInProgress() {
+				d.doltServer.ProbeSLO()
+			}
+
 		case <-doltRemotesChan:
 			// Periodic Dolt remote push — pushes databases to their configured
 			// git remotes on a 15-minute cadence (independent of heartbeat).
@@ -540,6 +611,20 @@ func (d *Daemon) Run() error {
 				d.runScheduledMaintenance()
 			}
 
+		case <-resourceSamplerChan:
+			// Resource sampler — records CPU, RSS, open files and process
+			// counts per session and flags runaway process trees.
+			if !d.isShutdownInProgress() {
+				d.sampleResources()
+			}
+
+		case <-pluginEventsChan:
+			// Plugin events — dispatches plugins with event gates (bead closed,
+			// MR merged, convoy landed, escalation, new commits) to idle dogs.
+			if !d.isShutdownInProgress() {
+				d.dispatchPluginEvents()
+			}
+
 		case <-timer.C:
 			d.heartbeat(state)
 
@@ -676,7 +761,11 @@ func (d *Daemon) heartbeat(state *State) {
 	// Shells out to `gt scheduler run` to avoid circular import between daemon and cmd.
 	d.dispatchQueuedWork()
 
-	// 15. Rotate oversized Dolt logs (copytruncate for child process fds).
+	// 15. Apply approval gate timeouts (on_timeout reject/approve/escalate).
+	// Shells out to `gt approve sweep` for the escalation routing in cmd.
+	d.sweepApprovals()
+
+	// 16. Rotate oversized Dolt logs (copytruncate for child process fds).
 	// daemon.log uses lumberjack for automatic rotation; this handles Dolt server logs.
 	d.rotateOversizedLogs()
 
@@ -690,6 +779,24 @@ func (d *Daemon) heartbeat(state *State) {
 	d.logger.Printf("Heartbeat complete (#%d)", state.HeartbeatCount)
 }
 
+// sweepApprovals applies on_timeout to approval requests past their
+// deadline. Without it a timeout would only fire when someone happened to
+// list or wait on the request. The store is checked in-process first so the
+// common case (nothing due) costs no subprocess.
+func (d *Daemon) sweepApprovals() {
+	if !approval.NewStore(d.config.TownRoot).HasDue() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "gt", "approve", "sweep")
+	cmd.Dir = d.config.TownRoot
+	cmd.Env = append(os.Environ(), "GT_DAEMON=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		d.logger.Printf("Approval sweep failed: %v (output: %s)", err, string(out))
+	}
+}
+
 // rotateOversizedLogs checks Dolt server log files and rotates any that exceed
 // the size threshold. Uses copytruncate which is safe for logs held open by
 // child processes. Runs every heartbeat but is cheap (just stat calls).
@@ -838,8 +945,9 @@ func (d *Daemon) ensureBootRunning() {
 
 	// Check for degraded mode
 	degraded := os.Getenv("GT_DEGRADED") == "true"
-	if degraded || !d.tmux.IsAvailable() {
-		// In degraded mode, run mechanical triage directly
+	if degraded || session.IsHeadless(d.config.TownRoot) || !d.tmux.IsAvailable() {
+		// In degraded mode (or headless, where Boot's tmux session can't
+		// run), run mechanical triage directly
 		d.logger.Println("Degraded mode: running mechanical Boot triage")
 		d.runDegradedBootTriage(b)
 		return
@@ -867,7 +975,7 @@ func (d *Daemon) runDegradedBootTriage(b *boot.Boot) {
 	}
 
 	// Simple check: is Deacon session alive?
-	hasDeacon, err := d.tmux.HasSession(d.getDeaconSessionName())
+	hasDeacon, err := d.sessions().HasSession(d.getDeaconSessionName())
 	if err != nil {
 		d.logger.Printf("Error checking Deacon session: %v", err)
 		status.LastAction = "error"
@@ -1019,7 +1127,7 @@ func (d *Daemon) checkDeaconHeartbeat() {
 	d.logger.Printf("Deacon heartbeat is stale (%s old), checking session...", age.Round(time.Minute))
 
 	// Check if session exists
-	hasSession, err := d.tmux.HasSession(sessionName)
+	hasSession, err := d.sessions().HasSession(sessionName)
 	if err != nil {
 		d.logger.Printf("Error checking Deacon session: %v", err)
 		return
@@ -1040,7 +1148,7 @@ func (d *Daemon) checkDeaconHeartbeat() {
 	} else {
 		// Stuck but not critically - nudge to wake up
 		d.logger.Printf("Deacon stuck for %s - nudging session", age.Round(time.Minute))
-		if err := d.tmux.NudgeSession(sessionName, "HEALTH_CHECK: heartbeat stale, respond to confirm responsiveness"); err != nil {
+		if err := d.sessions().NudgeSession(sessionName, "HEALTH_CHECK: heartbeat stale, respond to confirm responsiveness"); err != nil {
 			d.logger.Printf("Error nudging stuck Deacon: %v", err)
 		}
 	}
@@ -1201,10 +1309,10 @@ func (d *Daemon) ensureMayorRunning() {
 // running their own patrol loops and spawning agents. (hq-2mstj)
 func (d *Daemon) killDeaconSessions() {
 	for _, name := range []string{session.DeaconSessionName(), session.BootSessionName()} {
-		exists, _ := d.tmux.HasSession(name)
+		exists, _ := d.sessions().HasSession(name)
 		if exists {
 			d.logger.Printf("Killing leftover %s session (patrol disabled)", name)
-			if err := d.tmux.KillSessionWithProcesses(name); err != nil {
+			if err := d.sessions().KillSessionWithProcesses(name); err != nil {
 				d.logger.Printf("Error killing %s session: %v", name, err)
 			}
 		}
@@ -1216,10 +1324,10 @@ func (d *Daemon) killDeaconSessions() {
 func (d *Daemon) killWitnessSessions() {
 	for _, rigName := range d.getKnownRigs() {
 		name := session.WitnessSessionName(session.PrefixFor(rigName))
-		exists, _ := d.tmux.HasSession(name)
+		exists, _ := d.sessions().HasSession(name)
 		if exists {
 			d.logger.Printf("Killing leftover %s session (patrol disabled)", name)
-			if err := d.tmux.KillSessionWithProcesses(name); err != nil {
+			if err := d.sessions().KillSessionWithProcesses(name); err != nil {
 				d.logger.Printf("Error killing %s session: %v", name, err)
 			}
 		}
@@ -1231,10 +1339,10 @@ func (d *Daemon) killWitnessSessions() {
 func (d *Daemon) killRefinerySessions() {
 	for _, rigName := range d.getKnownRigs() {
 		name := session.RefinerySessionName(session.PrefixFor(rigName))
-		exists, _ := d.tmux.HasSession(name)
+		exists, _ := d.sessions().HasSession(name)
 		if exists {
 			d.logger.Printf("Killing leftover %s session (patrol disabled)", name)
-			if err := d.tmux.KillSessionWithProcesses(name); err != nil {
+			if err := d.sessions().KillSessionWithProcesses(name); err != nil {
 				d.logger.Printf("Error killing %s session: %v", name, err)
 			}
 		}
@@ -1270,10 +1378,10 @@ func (d *Daemon) killDefaultPrefixGhosts() {
 	// Kill ghost sessions using the default "gt" prefix for patrol roles.
 	for _, role := range []string{"witness", "refinery"} {
 		ghostName := fmt.Sprintf("%s-%s", session.DefaultPrefix, role)
-		exists, _ := d.tmux.HasSession(ghostName)
+		exists, _ := d.sessions().HasSession(ghostName)
 		if exists {
 			d.logger.Printf("Killing ghost session %s (default prefix, stale registry artifact)", ghostName)
-			if err := d.tmux.KillSessionWithProcesses(ghostName); err != nil {
+			if err := d.sessions().KillSessionWithProcesses(ghostName); err != nil {
 				d.logger.Printf("Error killing ghost session %s: %v", ghostName, err)
 			}
 		}
@@ -1297,11 +1405,11 @@ func (d *Daemon) killDefaultPrefixGhosts() {
 			}
 			polecatName := entry.Name()
 			ghostName := fmt.Sprintf("%s-%s", session.DefaultPrefix, polecatName)
-			exists, _ := d.tmux.HasSession(ghostName)
+			exists, _ := d.sessions().HasSession(ghostName)
 			if exists {
 				// Verify the correct session isn't also running (avoid killing legit sessions)
 				correctName := session.PolecatSessionName(rigPrefix, polecatName)
-				correctExists, _ := d.tmux.HasSession(correctName)
+				correctExists, _ := d.sessions().HasSession(correctName)
 				if !correctExists {
 					// Ghost is the only session — it might be doing real work.
 					// Log but don't kill; the registry reload will prevent new ghosts.
@@ -1309,7 +1417,7 @@ func (d *Daemon) killDefaultPrefixGhosts() {
 				} else {
 					// Both exist — ghost is definitely a duplicate, kill it.
 					d.logger.Printf("Killing duplicate ghost polecat session %s (correct session %s exists)", ghostName, correctName)
-					if err := d.tmux.KillSessionWithProcesses(ghostName); err != nil {
+					if err := d.sessions().KillSessionWithProcesses(ghostName); err != nil {
 						d.logger.Printf("Error killing ghost session %s: %v", ghostName, err)
 					}
 				}
@@ -1497,6 +1605,14 @@ func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return
 		d.logger.Println("KRC pruner stopped")
 	}
 
+	// Stop the PTY supervisor; its sessions die with the daemon.
+	if d.ptySupervisor != nil {
+		_ = d.ptyListener.Close()
+		d.ptySupervisor.Close()
+		_ = os.Remove(ptyd.SocketPath(d.config.TownRoot))
+		d.logger.Println("PTY supervisor stopped")
+	}
+
 	// Push Dolt remotes before stopping the server (if patrol is enabled)
 	d.pushDoltRemotes()
 
@@ -1527,6 +1643,28 @@ func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return
 	return nil
 }
 
+// sessions returns the town's session backend: the PTY supervisor for
+// headless towns, otherwise tmux.
+func (d *Daemon) sessions() session.SessionBackend {
+	return session.NewBackend(d.config.TownRoot, d.tmux)
+}
+
+// startPTYSupervisor serves a PTY supervisor on the town's socket.
+func (d *Daemon) startPTYSupervisor() error {
+	ln, err := ptyd.Listen(ptyd.SocketPath(d.config.TownRoot))
+	if err != nil {
+		return err
+	}
+	d.ptySupervisor = ptyd.NewSupervisor(0)
+	d.ptyListener = ln
+	go func() {
+		if err := d.ptySupervisor.Serve(ln); err != nil {
+			d.logger.Printf("Warning: pty supervisor: %v", err)
+		}
+	}()
+	return nil
+}
+
 // Stop signals the daemon to stop.
 func (d *Daemon) Stop() {
 	d.cancel()
@@ -1849,7 +1987,7 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
 
 	// Check if tmux session exists
-	sessionAlive, err := d.tmux.HasSession(sessionName)
+	sessionAlive, err := d.sessions().HasSession(sessionName)
 	if err != nil {
 		d.logger.Printf("Error checking session %s: %v", sessionName, err)
 		return
@@ -1908,7 +2046,7 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	// TOCTOU guard: re-verify session is still dead before restarting.
 	// Between the initial check and now, the session may have been restarted
 	// by another heartbeat cycle, witness, or the polecat itself.
-	sessionRevived, err := d.tmux.HasSession(sessionName)
+	sessionRevived, err := d.sessions().HasSession(sessionName)
 	if err == nil && sessionRevived {
 		return // Session came back - no restart needed
 	}
@@ -78,6 +78,14 @@ type DoltServerConfig struct {
 	// detection of Dolt server crashes without changing the overall
 	// heartbeat frequency. Default 30s.
 	HealthCheckInterval time.Duration `json:"health_check_interval,omitempty"`
+
+	// Replica runs a hot-standby Dolt server that the primary replicates
+	// to, promoted when the primary breaches its SLOs. Off by default.
+	Replica *doltserver.ReplicaConfig `json:"replica,omitempty"`
+
+	// SLO sets the latency and error-rate SLOs the daemon probes the
+	// server against (see dolt_slo.go).
+	SLO *DoltSLOConfig `json:"slo,omitempty"`
 }
 
 // DefaultDoltServerConfig returns sensible defaults for Dolt server config.
@@ -142,6 +150,10 @@ type DoltServerManager struct {
 	// Protected by mu.
 	onRecoveryFn func()
 
+	// SLO probe state (see dolt_slo.go)
+	slo        sloTracker
+	sloAlerted bool // Whether the current breach has been alerted (avoid spamming)
+
 	// Test hooks (nil = use real implementations; set only in tests)
 	healthCheckFn      func() error
 	writeProbeCheckFn  func() error
@@ -156,6 +168,13 @@ type DoltServerManager struct {
 	readOnlyAlertFn    func(error)
 	crashAlertFn       func(int)
 	listDatabasesFn    func() ([]string, error)
+	sloProbeFn         func(port int) (time.Duration, error)
+	failoverFn         func(reason string) (*doltserver.FailoverState, error)
+	failoverAlertFn    func(*doltserver.FailoverState)
+	sloAlertFn         func(breach string)
+	replicaStartFn     func() error
+	demoteFn           func() error
+	replicationCheckFn func(port int) error
 }
 
 // NewDoltServerManager creates a new Dolt server manager.
@@ -380,6 +399,9 @@ func (m *DoltServerManager) EnsureRunning() error {
 		return nil
 	}
 
+	m.ensureReplicaLocked()
+	failover := m.failoverState()
+
 	pid, running := m.isRunning()
 	if running {
 		// Already running, check health
@@ -392,11 +414,17 @@ func (m *DoltServerManager) EnsureRunning() error {
 			m.stopLocked()
 			return m.restartWithBackoff()
 		}
-		// Check write capability (read-only detection).
-		// The health check above only verifies read connectivity.
-		// Under concurrent write load, Dolt can enter a persistent read-only
-		// state that requires a server restart to clear.
-		if err := m.checkWriteHealthLocked(); err != nil {
+		// After a failover the primary is a standby and read-only by design:
+		// skip the write probe and make sure it has stepped down.
+		if failover != nil {
+			if err := m.demoteFormerPrimaryLocked(); err != nil {
+				m.logger("Warning: former Dolt primary has not stepped down yet: %v", err)
+			}
+		} else if err := m.checkWriteHealthLocked(); err != nil {
+			// Check write capability (read-only detection).
+			// The health check above only verifies read connectivity.
+			// Under concurrent write load, Dolt can enter a persistent read-only
+			// state that requires a server restart to clear.
 			m.logger("Dolt server read-only: %v, restarting...", err)
 			m.sendReadOnlyAlert(err)
 			m.writeUnhealthySignal("read_only", err.Error())
@@ -795,6 +823,21 @@ func (m *DoltServerManager) startLocked() error {
 		"--data-dir", m.config.DataDir,
 	}
 
+	// Cluster replication can only be configured in config.yaml, so with a
+	// replica enabled the server runs from a managed config instead.
+	if rc := m.replicaConfig(); rc != nil {
+		serverConfig := doltserver.DefaultConfig(m.townRoot)
+		serverConfig.Host = m.config.Host
+		serverConfig.Port = m.config.Port
+		serverConfig.DataDir = m.config.DataDir
+		serverConfig.Cluster = rc.PrimaryCluster()
+		configPath := filepath.Join(m.config.DataDir, "config.yaml")
+		if err := doltserver.WriteServerConfig(serverConfig, configPath); err != nil {
+			return fmt.Errorf("writing Dolt config: %w", err)
+		}
+		args = []string{"sql-server", "--config", configPath}
+	}
+
 	// Open log file
 	logFile, err := os.OpenFile(m.config.LogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
 	if err != nil {
@@ -851,6 +894,15 @@ func (m *DoltServerManager) Stop() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.stopLocked()
+
+	// The replica goes down with the primary, unless it is the one serving.
+	if m.replicaConfig() != nil && m.failoverState() == nil {
+		if running, _ := doltserver.IsReplicaRunning(m.townRoot); running {
+			if err := doltserver.StopReplica(m.townRoot); err != nil {
+				m.logger("Warning: failed to stop Dolt replica: %v", err)
+			}
+		}
+	}
 	return nil
 }
 
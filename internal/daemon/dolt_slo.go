@@ -0,0 +1,442 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/doltserver"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Default Dolt SLO thresholds. The health check ticker (30s) only notices a
+// server that is down; the SLO probe notices one that is up but too slow or
+// flaky to serve agents, and fails over to the hot-standby replica.
+const (
+	defaultDoltSLOProbeInterval = 5 * time.Second
+	defaultDoltSLOWindow        = 2 * time.Minute
+	defaultDoltSLOMaxP95        = 2 * time.Second
+	defaultDoltSLOMaxErrorRate  = 0.2
+	defaultDoltSLOMinSamples    = 12
+)
+
+// DoltSLOConfig holds the Dolt server's health SLOs. It lives under
+// patrols.dolt_server.slo in mayor/daemon.json.
+type DoltSLOConfig struct {
+	// Enabled controls whether the SLO probe runs. Default: true (nil).
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// ProbeIntervalStr is how often to probe, as a string (e.g., "5s").
+	ProbeIntervalStr string `json:"probe_interval,omitempty"`
+
+	// WindowStr is the sliding window the SLOs are measured over (e.g., "2m").
+	WindowStr string `json:"window,omitempty"`
+
+	// MaxP95LatencyStr is the highest acceptable p95 probe latency (e.g., "2s").
+	MaxP95LatencyStr string `json:"max_p95_latency,omitempty"`
+
+	// MaxErrorRate is the highest acceptable fraction of failed probes
+	// (0.2 = 20%).
+	MaxErrorRate float64 `json:"max_error_rate,omitempty"`
+
+	// MinSamples is how many probes the window must hold before a breach
+	// counts, so one slow query after startup doesn't trigger a failover.
+	MinSamples int `json:"min_samples,omitempty"`
+
+	// AutoFailover promotes the replica on a breach. Without a replica, or
+	// with this off, a breach only alerts. Default: true (nil).
+	AutoFailover *bool `json:"auto_failover,omitempty"`
+}
+
+// doltSLOThresholds is DoltSLOConfig with defaults applied.
+type doltSLOThresholds struct {
+	interval     time.Duration
+	window       time.Duration
+	maxP95       time.Duration
+	maxErrorRate float64
+	minSamples   int
+	autoFailover bool
+}
+
+func parseDurationOr(s string, def time.Duration) time.Duration {
+	if s != "" {
+		if d, err := time.ParseDuration(s); err == nil && d > 0 {
+			return d
+		}
+	}
+	return def
+}
+
+func (m *DoltServerManager) sloThresholds() doltSLOThresholds {
+	cfg := m.config.SLO
+	if cfg == nil {
+		cfg = &DoltSLOConfig{}
+	}
+	t := doltSLOThresholds{
+		interval:     parseDurationOr(cfg.ProbeIntervalStr, defaultDoltSLOProbeInterval),
+		window:       parseDurationOr(cfg.WindowStr, defaultDoltSLOWindow),
+		maxP95:       parseDurationOr(cfg.MaxP95LatencyStr, defaultDoltSLOMaxP95),
+		maxErrorRate: cfg.MaxErrorRate,
+		minSamples:   cfg.MinSamples,
+		autoFailover: cfg.AutoFailover == nil || *cfg.AutoFailover,
+	}
+	if t.maxErrorRate <= 0 {
+		t.maxErrorRate = defaultDoltSLOMaxErrorRate
+	}
+	if t.minSamples <= 0 {
+		t.minSamples = defaultDoltSLOMinSamples
+	}
+	return t
+}
+
+// SLOEnabled returns whether the SLO probe should run for this server.
+func (m *DoltServerManager) SLOEnabled() bool {
+	if !m.IsEnabled() || m.isRemote() {
+		return false
+	}
+	return m.config.SLO == nil || m.config.SLO.Enabled == nil || *m.config.SLO.Enabled
+}
+
+// SLOProbeInterval returns how often the daemon should call ProbeSLO.
+func (m *DoltServerManager) SLOProbeInterval() time.Duration {
+	return m.sloThresholds().interval
+}
+
+// replicaConfig returns the hot-standby replica config with defaults
+// applied, or nil when no replica is enabled.
+func (m *DoltServerManager) replicaConfig() *doltserver.ReplicaConfig {
+	if m.config == nil || m.config.Replica == nil || !m.config.Replica.Enabled || m.isRemote() {
+		return nil
+	}
+	rc := *m.config.Replica
+	rc.ApplyDefaults(m.townRoot, m.config.Port)
+	return &rc
+}
+
+// failoverState returns the active failover for this server, or nil.
+func (m *DoltServerManager) failoverState() *doltserver.FailoverState {
+	state, err := doltserver.LoadFailoverState(m.townRoot)
+	if err != nil || !state.Active || state.PrimaryPort != m.config.Port {
+		return nil
+	}
+	return state
+}
+
+// sloSample is one probe result.
+type sloSample struct {
+	at      time.Time
+	latency time.Duration
+	failed  bool
+}
+
+// sloTracker keeps the probe results inside the SLO window.
+type sloTracker struct {
+	samples []sloSample
+}
+
+func (t *sloTracker) record(s sloSample, window time.Duration) {
+	t.samples = append(t.samples, s)
+	cutoff := s.at.Add(-window)
+	i := 0
+	for i < len(t.samples) && !t.samples[i].at.After(cutoff) {
+		i++
+	}
+	t.samples = t.samples[i:]
+}
+
+func (t *sloTracker) reset() {
+	t.samples = nil
+}
+
+// stats returns the sample count, p95 latency of successful probes, and
+// the fraction of failed probes.
+func (t *sloTracker) stats() (int, time.Duration, float64) {
+	n := len(t.samples)
+	if n == 0 {
+		return 0, 0, 0
+	}
+	var latencies []time.Duration
+	failed := 0
+	for _, s := range t.samples {
+		if s.failed {
+			failed++
+			continue
+		}
+		latencies = append(latencies, s.latency)
+	}
+	var p95 time.Duration
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		idx := (len(latencies)*95+99)/100 - 1
+		p95 = latencies[idx]
+	}
+	return n, p95, float64(failed) / float64(n)
+}
+
+// DoltSLOStatus is the latest SLO measurement, written to
+// daemon/dolt-slo.json for gt dolt status.
+type DoltSLOStatus struct {
+	UpdatedAt     time.Time `json:"updated_at"`
+	Port          int       `json:"port"`
+	Samples       int       `json:"samples"`
+	Window        string    `json:"window"`
+	P95LatencyMs  int64     `json:"p95_latency_ms"`
+	MaxP95Ms      int64     `json:"max_p95_latency_ms"`
+	ErrorRate     float64   `json:"error_rate"`
+	MaxErrorRate  float64   `json:"max_error_rate"`
+	Breached      bool      `json:"breached"`
+	Breach        string    `json:"breach,omitempty"`
+	LastError     string    `json:"last_error,omitempty"`
+	ReplicaActive bool      `json:"replica_active"`
+}
+
+// DoltSLOStatusFile returns the path to the SLO status file.
+func DoltSLOStatusFile(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "dolt-slo.json")
+}
+
+// LoadDoltSLOStatus reads the latest SLO measurement. Returns nil if the
+// daemon has not written one.
+func LoadDoltSLOStatus(townRoot string) *DoltSLOStatus {
+	data, err := os.ReadFile(DoltSLOStatusFile(townRoot))
+	if err != nil {
+		return nil
+	}
+	var status DoltSLOStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil
+	}
+	return &status
+}
+
+// probeSLO measures one probe against the server on port.
+func (m *DoltServerManager) probeSLO(port int, timeout time.Duration) (time.Duration, error) {
+	if m.sloProbeFn != nil {
+		return m.sloProbeFn(port)
+	}
+	return doltserver.ProbeLatency(m.townRoot, port, timeout)
+}
+
+// checkReplication records the primary's replication lag, which Failover
+// needs if the primary later goes down without stepping down.
+func (m *DoltServerManager) checkReplication(port int, timeout time.Duration) {
+	var err error
+	if m.replicationCheckFn != nil {
+		err = m.replicationCheckFn(port)
+	} else {
+		_, err = doltserver.CheckReplication(m.townRoot, port, timeout)
+	}
+	if err != nil {
+		m.logger("Warning: checking Dolt replication on port %d: %v", port, err)
+	}
+}
+
+// ProbeSLO probes whichever server is serving clients and checks the SLO
+// window. When the primary breaches its SLOs and a replica is enabled, the
+// replica is promoted, agents are alerted, and the primary is restarted to
+// rejoin as a standby. Without a replica, a breach only alerts.
+func (m *DoltServerManager) ProbeSLO() {
+	if !m.SLOEnabled() {
+		return
+	}
+	th := m.sloThresholds()
+
+	// Probe without holding the lock: a wedged server can take the whole
+	// timeout to answer, and EnsureRunning shouldn't wait on it.
+	failover := m.failoverState()
+	port := m.config.Port
+	if failover != nil {
+		port = failover.ReplicaPort
+	}
+	latency, err := m.probeSLO(port, th.interval)
+	if err == nil && failover == nil && m.replicaConfig() != nil {
+		m.checkReplication(port, th.interval)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.restarting {
+		return
+	}
+
+	m.slo.record(sloSample{at: m.now(), latency: latency, failed: err != nil}, th.window)
+	n, p95, errRate := m.slo.stats()
+
+	status := &DoltSLOStatus{
+		UpdatedAt:     m.now(),
+		Port:          port,
+		Samples:       n,
+		Window:        th.window.String(),
+		P95LatencyMs:  p95.Milliseconds(),
+		MaxP95Ms:      th.maxP95.Milliseconds(),
+		ErrorRate:     errRate,
+		MaxErrorRate:  th.maxErrorRate,
+		ReplicaActive: failover != nil,
+	}
+	if err != nil {
+		status.LastError = err.Error()
+	}
+	if n >= th.minSamples {
+		switch {
+		case errRate > th.maxErrorRate:
+			status.Breach = fmt.Sprintf("error rate %.0f%% > %.0f%% over %v", errRate*100, th.maxErrorRate*100, th.window)
+		case p95 > th.maxP95:
+			status.Breach = fmt.Sprintf("p95 latency %v > %v over %v", p95.Round(time.Millisecond), th.maxP95, th.window)
+		}
+	}
+	status.Breached = status.Breach != ""
+	m.writeSLOStatus(status)
+
+	if !status.Breached {
+		m.sloAlerted = false
+		return
+	}
+
+	rc := m.replicaConfig()
+	if failover == nil && rc != nil && th.autoFailover && !m.IsExternal() {
+		m.failoverLocked(status.Breach)
+		return
+	}
+	if !m.sloAlerted {
+		m.sloAlerted = true
+		m.logger("Dolt SLO breached on port %d: %s", port, status.Breach)
+		m.sendSLOAlert(port, status.Breach, failover != nil)
+	}
+}
+
+// failoverLocked promotes the replica and takes the sick primary out of
+// service. Must be called with m.mu held.
+func (m *DoltServerManager) failoverLocked(reason string) {
+	m.logger("Dolt SLO breached: %s — failing over to the hot-standby replica", reason)
+	var state *doltserver.FailoverState
+	var err error
+	if m.failoverFn != nil {
+		state, err = m.failoverFn(reason)
+	} else {
+		state, err = doltserver.Failover(m.townRoot, reason)
+	}
+	if err != nil {
+		m.logger("Dolt failover failed: %v", err)
+		if !m.sloAlerted {
+			m.sloAlerted = true
+			m.sendSLOAlert(m.config.Port, fmt.Sprintf("%s (failover failed: %v)", reason, err), false)
+		}
+		return
+	}
+
+	m.logger("Dolt failed over to replica on port %d (epoch %d)", state.ReplicaPort, state.Epoch)
+	_ = events.LogAt(m.townRoot, events.TypeDoltFailover, "daemon",
+		events.DoltFailoverPayload(state.PrimaryPort, state.ReplicaPort, state.Epoch, reason))
+	m.sendFailoverAlert(state)
+	m.slo.reset()
+	m.sloAlerted = false
+
+	// Restart the primary: whatever wedged it is cleared, and it rejoins as
+	// a standby (see DemoteFormerPrimary in EnsureRunning).
+	m.captureGoroutineDump()
+	m.stopLocked()
+	if err := m.restartWithBackoff(); err != nil {
+		m.logger("Warning: restarting former primary after failover: %v", err)
+	}
+}
+
+// ensureReplicaLocked starts the hot-standby replica if one is enabled and
+// not running. Must be called with m.mu held.
+func (m *DoltServerManager) ensureReplicaLocked() {
+	if m.replicaConfig() == nil {
+		return
+	}
+	start := m.replicaStartFn
+	if start == nil {
+		start = func() error { return doltserver.StartReplica(m.townRoot) }
+	}
+	if err := start(); err != nil {
+		m.logger("Warning: Dolt replica not running: %v", err)
+	}
+}
+
+// demoteFormerPrimaryLocked makes sure the primary has stepped down to
+// standby while the replica serves. Must be called with m.mu held.
+func (m *DoltServerManager) demoteFormerPrimaryLocked() error {
+	if m.demoteFn != nil {
+		return m.demoteFn()
+	}
+	return doltserver.DemoteFormerPrimary(m.townRoot)
+}
+
+func (m *DoltServerManager) writeSLOStatus(status *DoltSLOStatus) {
+	path := DoltSLOStatusFile(m.townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	_ = util.AtomicWriteJSON(path, status)
+}
+
+// sendSLOAlert mails the mayor and witnesses about an SLO breach that was
+// not handled by a failover.
+func (m *DoltServerManager) sendSLOAlert(port int, breach string, onReplica bool) {
+	if m.sloAlertFn != nil {
+		m.sloAlertFn(breach)
+		return
+	}
+	subject := "ALERT: Dolt server breaching health SLOs"
+	action := "No hot-standby replica is enabled, so the daemon cannot fail over.\nEnable one under patrols.dolt_server.replica in mayor/daemon.json."
+	if onReplica {
+		action = "The town is already failed over to the replica; it is breaching too.\nCheck: gt dolt status"
+	}
+	body := fmt.Sprintf(`The Dolt server on port %d is up but breaching its health SLOs.
+
+Breach: %s
+
+%s`, port, breach, action)
+
+	townRoot := m.townRoot
+	logger := m.logger
+	go func() {
+		sendDoltAlertMail(townRoot, "mayor/", subject, body, logger)
+		sendDoltAlertToWitnesses(townRoot, subject, body, logger)
+	}()
+}
+
+// sendFailoverAlert tells the mayor and witnesses that the town has failed
+// over, and what to do about sessions started before it.
+func (m *DoltServerManager) sendFailoverAlert(state *doltserver.FailoverState) {
+	if m.failoverAlertFn != nil {
+		m.failoverAlertFn(state)
+		return
+	}
+	subject := "ALERT: Dolt failed over to hot-standby replica"
+	body := fmt.Sprintf(`The Dolt primary on port %d breached its health SLOs and the daemon promoted
+the hot-standby replica on port %d (epoch %d).
+
+Reason: %s
+
+gt commands and bd calls use the replica automatically, including bare bd
+calls in running sessions: each rig's metadata.json now names port %d.
+Only a session that pins BEADS_DOLT_PORT itself still reaches the demoted,
+read-only primary.
+
+The primary is being restarted and rejoins as a standby. Once it has caught
+up, hand the primary role back with: gt dolt failback`,
+		state.PrimaryPort, state.ReplicaPort, state.Epoch, state.Reason, state.ReplicaPort)
+	if !state.GracefulStepDown {
+		body += fmt.Sprintf(`
+
+The primary was down and could not step down. The replica was up to %v
+behind it as of %s; commits in that window are discarded when the primary
+rejoins as a standby.`,
+			time.Duration(state.UnreplicatedLagMs)*time.Millisecond,
+			state.ReplicationCheckedAt.Format("15:04:05"))
+	}
+
+	townRoot := m.townRoot
+	logger := m.logger
+	go func() {
+		sendDoltAlertMail(townRoot, "mayor/", subject, body, logger)
+		sendDoltAlertToWitnesses(townRoot, subject, body, logger)
+	}()
+}
@@ -0,0 +1,218 @@
+package daemon
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/doltserver"
+)
+
+func TestSLOTracker_Stats(t *testing.T) {
+	var tr sloTracker
+	now := time.Now()
+	for i := 1; i <= 20; i++ {
+		tr.record(sloSample{at: now, latency: time.Duration(i*10) * time.Millisecond}, time.Minute)
+	}
+	n, p95, errRate := tr.stats()
+	if n != 20 || p95 != 190*time.Millisecond || errRate != 0 {
+		t.Errorf("stats() = %d, %v, %v; want 20, 190ms, 0", n, p95, errRate)
+	}
+
+	for i := 0; i < 5; i++ {
+		tr.record(sloSample{at: now, failed: true}, time.Minute)
+	}
+	n, p95, errRate = tr.stats()
+	if n != 25 || p95 != 190*time.Millisecond || errRate != 0.2 {
+		t.Errorf("with failures: stats() = %d, %v, %v; want 25, 190ms, 0.2", n, p95, errRate)
+	}
+}
+
+func TestSLOTracker_Window(t *testing.T) {
+	var tr sloTracker
+	start := time.Now()
+	tr.record(sloSample{at: start, failed: true}, time.Minute)
+	tr.record(sloSample{at: start.Add(30 * time.Second), latency: time.Millisecond}, time.Minute)
+	tr.record(sloSample{at: start.Add(61 * time.Second), latency: time.Millisecond}, time.Minute)
+
+	n, _, errRate := tr.stats()
+	if n != 2 || errRate != 0 {
+		t.Errorf("stats() = %d samples, error rate %v; want the failure aged out", n, errRate)
+	}
+}
+
+// newSLOTestManager returns a manager whose probe always reports latency
+// and err, with SLOs that breach after three samples.
+func newSLOTestManager(t *testing.T, latency time.Duration, err error) *DoltServerManager {
+	t.Helper()
+	m := newTestManager(t)
+	m.config.SLO = &DoltSLOConfig{MaxP95LatencyStr: "1s", MinSamples: 3}
+	m.sloProbeFn = func(int) (time.Duration, error) { return latency, err }
+	m.sleepFn = func(time.Duration) {}
+	m.replicationCheckFn = func(int) error { return nil }
+	return m
+}
+
+func TestProbeSLO_BreachFailsOver(t *testing.T) {
+	var failovers, stops, starts, alerts atomic.Int32
+	m := newSLOTestManager(t, 3*time.Second, nil)
+	m.config.Replica = &doltserver.ReplicaConfig{Enabled: true}
+	m.failoverFn = func(reason string) (*doltserver.FailoverState, error) {
+		failovers.Add(1)
+		return &doltserver.FailoverState{Active: true, PrimaryPort: 13306, ReplicaPort: 13307, Epoch: 3, Reason: reason}, nil
+	}
+	m.failoverAlertFn = func(*doltserver.FailoverState) { alerts.Add(1) }
+	m.stopFn = func() { stops.Add(1) }
+	m.startFn = func() error { starts.Add(1); return nil }
+
+	m.ProbeSLO()
+	m.ProbeSLO()
+	if failovers.Load() != 0 {
+		t.Fatal("failed over before MinSamples probes")
+	}
+	m.ProbeSLO()
+
+	if failovers.Load() != 1 || alerts.Load() != 1 {
+		t.Errorf("failovers = %d, alerts = %d; want 1, 1", failovers.Load(), alerts.Load())
+	}
+	if stops.Load() != 1 || starts.Load() != 1 {
+		t.Errorf("former primary stops = %d, starts = %d; want a restart", stops.Load(), starts.Load())
+	}
+	if len(m.slo.samples) != 0 {
+		t.Errorf("SLO window not reset after failover: %d samples", len(m.slo.samples))
+	}
+
+	status := LoadDoltSLOStatus(m.townRoot)
+	if status == nil || !status.Breached || status.P95LatencyMs != 3000 {
+		t.Errorf("SLO status = %+v, want a 3000ms breach", status)
+	}
+}
+
+func TestProbeSLO_BreachWithoutReplicaAlertsOnce(t *testing.T) {
+	var alerts atomic.Int32
+	m := newSLOTestManager(t, 0, fmt.Errorf("connection refused"))
+	m.failoverFn = func(string) (*doltserver.FailoverState, error) {
+		t.Fatal("failover attempted without a replica")
+		return nil, nil
+	}
+	m.sloAlertFn = func(string) { alerts.Add(1) }
+
+	for i := 0; i < 6; i++ {
+		m.ProbeSLO()
+	}
+	if alerts.Load() != 1 {
+		t.Errorf("alerts = %d, want 1 per breach", alerts.Load())
+	}
+}
+
+func TestProbeSLO_AutoFailoverOff(t *testing.T) {
+	var alerts atomic.Int32
+	off := false
+	m := newSLOTestManager(t, 3*time.Second, nil)
+	m.config.SLO.AutoFailover = &off
+	m.config.Replica = &doltserver.ReplicaConfig{Enabled: true}
+	m.failoverFn = func(string) (*doltserver.FailoverState, error) {
+		t.Fatal("failover attempted with auto_failover off")
+		return nil, nil
+	}
+	m.sloAlertFn = func(string) { alerts.Add(1) }
+
+	for i := 0; i < 3; i++ {
+		m.ProbeSLO()
+	}
+	if alerts.Load() != 1 {
+		t.Errorf("alerts = %d, want 1", alerts.Load())
+	}
+}
+
+func TestProbeSLO_Healthy(t *testing.T) {
+	m := newSLOTestManager(t, 20*time.Millisecond, nil)
+	m.config.Replica = &doltserver.ReplicaConfig{Enabled: true}
+	m.failoverFn = func(string) (*doltserver.FailoverState, error) {
+		t.Fatal("failover attempted on a healthy server")
+		return nil, nil
+	}
+	for i := 0; i < 5; i++ {
+		m.ProbeSLO()
+	}
+	if status := LoadDoltSLOStatus(m.townRoot); status == nil || status.Breached || status.Samples != 5 {
+		t.Errorf("SLO status = %+v", status)
+	}
+}
+
+func TestProbeSLO_ProbesReplicaAfterFailover(t *testing.T) {
+	m := newSLOTestManager(t, 20*time.Millisecond, nil)
+	var probed atomic.Int32
+	m.sloProbeFn = func(port int) (time.Duration, error) {
+		probed.Store(int32(port))
+		return 20 * time.Millisecond, nil
+	}
+	state := &doltserver.FailoverState{Active: true, PrimaryPort: 13306, ReplicaPort: 13307, Epoch: 3}
+	if err := doltserver.SaveFailoverState(m.townRoot, state); err != nil {
+		t.Fatal(err)
+	}
+	m.ProbeSLO()
+	if probed.Load() != 13307 {
+		t.Errorf("probed port %d, want the promoted replica", probed.Load())
+	}
+}
+
+// TestEnsureRunning_FailedOverSkipsWriteProbe verifies that the demoted
+// primary, read-only by design, isn't restarted as if it were stuck.
+func TestEnsureRunning_FailedOverSkipsWriteProbe(t *testing.T) {
+	var stops, demotes atomic.Int32
+	m := newTestManager(t)
+	m.runningFn = func() (int, bool) { return 1234, true }
+	m.writeProbeCheckFn = func() error {
+		return fmt.Errorf("dolt server is in read-only mode: database is read only")
+	}
+	m.stopFn = func() { stops.Add(1) }
+	m.demoteFn = func() error { demotes.Add(1); return nil }
+
+	state := &doltserver.FailoverState{Active: true, PrimaryPort: 13306, ReplicaPort: 13307, Epoch: 3}
+	if err := doltserver.SaveFailoverState(m.townRoot, state); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.EnsureRunning(); err != nil {
+		t.Fatalf("EnsureRunning: %v", err)
+	}
+	if stops.Load() != 0 {
+		t.Error("failed-over primary restarted for being read-only")
+	}
+	if demotes.Load() != 1 {
+		t.Errorf("demotes = %d, want 1", demotes.Load())
+	}
+}
+
+func TestProbeSLO_ChecksReplicationWhileHealthy(t *testing.T) {
+	var checks atomic.Int32
+	m := newSLOTestManager(t, 20*time.Millisecond, nil)
+	m.config.Replica = &doltserver.ReplicaConfig{Enabled: true}
+	m.replicationCheckFn = func(port int) error {
+		if port != m.config.Port {
+			t.Errorf("checked port %d, want the primary", port)
+		}
+		checks.Add(1)
+		return nil
+	}
+	m.ProbeSLO()
+	if checks.Load() != 1 {
+		t.Fatalf("replication checks = %d, want 1", checks.Load())
+	}
+
+	// A failed probe says nothing about replication.
+	m.sloProbeFn = func(int) (time.Duration, error) { return 0, fmt.Errorf("timeout") }
+	m.ProbeSLO()
+
+	// Nor is there a primary to check once failed over.
+	m.sloProbeFn = func(int) (time.Duration, error) { return 20 * time.Millisecond, nil }
+	state := &doltserver.FailoverState{Active: true, PrimaryPort: m.config.Port, ReplicaPort: 13307, Epoch: 3}
+	if err := doltserver.SaveFailoverState(m.townRoot, state); err != nil {
+		t.Fatal(err)
+	}
+	m.ProbeSLO()
+	if checks.Load() != 1 {
+		t.Errorf("replication checks = %d, want only the healthy primary probe", checks.Load())
+	}
+}
@@ -3,12 +3,14 @@ package daemon
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/dog"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/plugin"
+	"github.com/steveyegge/gastown/internal/secrets"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
 
@@ -235,51 +237,76 @@ func (d *Daemon) dispatchPlugins(mgr *dog.Manager, sm *dog.SessionManager, rigsC
 			}
 		}
 
-		// Find an idle dog.
-		idleDog, err := mgr.GetIdleDog()
-		if err != nil {
-			d.logger.Printf("Handler: error finding idle dog: %v", err)
-			return // No point continuing if we can't list dogs
-		}
-		if idleDog == nil {
-			d.logger.Printf("Handler: no idle dogs available, deferring remaining plugins")
+		if _, ok := d.sendPluginToDog(mgr, sm, router, p, p.FormatMailBody()); !ok {
 			return
 		}
+	}
+}
 
-		// Assign work and start session.
-		workDesc := fmt.Sprintf("plugin:%s", p.Name)
-		if err := mgr.AssignWork(idleDog.Name, workDesc); err != nil {
-			d.logger.Printf("Handler: failed to assign work to dog %s: %v", idleDog.Name, err)
-			continue
-		}
+// sendPluginToDog hands a plugin run to an idle dog: it assigns the work,
+// starts the dog's session and mails it body. dispatched reports whether the
+// dog got the work; more is false when no dog is available, so callers
+// should stop dispatching for this cycle.
+func (d *Daemon) sendPluginToDog(mgr *dog.Manager, sm *dog.SessionManager, router *mail.Router, p *plugin.Plugin, body string) (dispatched, more bool) {
+	// Resolve the plugin's config; a plugin missing required values can't run.
+	cfg, err := plugin.ResolveConfig(d.config.TownRoot, p, secrets.Open(d.config.TownRoot).Get)
+	if err != nil {
+		d.logger.Printf("Handler: failed to resolve config for plugin %s: %v", p.Name, err)
+		return false, true
+	}
+	if problems := cfg.Problems(); len(problems) > 0 {
+		d.logger.Printf("Handler: not dispatching plugin %s: %s (see gt plugin config)", p.Name, strings.Join(problems, "; "))
+		return false, true
+	}
+	env, secretEnv := cfg.Env()
 
-		if err := sm.Start(idleDog.Name, dog.SessionStartOptions{
-			WorkDesc: workDesc,
-		}); err != nil {
-			d.logger.Printf("Handler: failed to start session for dog %s: %v", idleDog.Name, err)
-			// Roll back assignment on session start failure.
-			if clearErr := mgr.ClearWork(idleDog.Name); clearErr != nil {
-				d.logger.Printf("Handler: failed to clear work after start failure for dog %s: %v", idleDog.Name, clearErr)
-			}
-			continue
-		}
+	// Find an idle dog.
+	idleDog, err := mgr.GetIdleDog()
+	if err != nil {
+		d.logger.Printf("Handler: error finding idle dog: %v", err)
+		return false, false // No point continuing if we can't list dogs
+	}
+	if idleDog == nil {
+		d.logger.Printf("Handler: no idle dogs available, deferring remaining plugins")
+		return false, false
+	}
 
-		// Send mail with plugin instructions.
-		msg := mail.NewMessage(
-			"daemon",
-			fmt.Sprintf("deacon/dogs/%s", idleDog.Name),
-			fmt.Sprintf("Plugin: %s", p.Name),
-			p.FormatMailBody(),
-		)
-		msg.Type = mail.TypeTask
-		msg.Timestamp = time.Now()
-		if err := router.Send(msg); err != nil {
-			d.logger.Printf("Handler: failed to send mail to dog %s: %v", idleDog.Name, err)
-			// Session is already started — dog will find no mail and idle out.
+	// Assign work and start session.
+	workDesc := fmt.Sprintf("plugin:%s", p.Name)
+	if err := mgr.AssignWork(idleDog.Name, workDesc); err != nil {
+		d.logger.Printf("Handler: failed to assign work to dog %s: %v", idleDog.Name, err)
+		return false, true
+	}
+
+	if err := sm.Start(idleDog.Name, dog.SessionStartOptions{
+		WorkDesc:  workDesc,
+		Env:       env,
+		SecretEnv: secretEnv,
+	}); err != nil {
+		d.logger.Printf("Handler: failed to start session for dog %s: %v", idleDog.Name, err)
+		// Roll back assignment on session start failure.
+		if clearErr := mgr.ClearWork(idleDog.Name); clearErr != nil {
+			d.logger.Printf("Handler: failed to clear work after start failure for dog %s: %v", idleDog.Name, clearErr)
 		}
+		return false, true
+	}
 
-		d.logger.Printf("Handler: dispatched plugin %s to dog %s", p.Name, idleDog.Name)
+	// Send mail with plugin instructions.
+	msg := mail.NewMessage(
+		"daemon",
+		fmt.Sprintf("deacon/dogs/%s", idleDog.Name),
+		fmt.Sprintf("Plugin: %s", p.Name),
+		body,
+	)
+	msg.Type = mail.TypeTask
+	msg.Timestamp = time.Now()
+	if err := router.Send(msg); err != nil {
+		d.logger.Printf("Handler: failed to send mail to dog %s: %v", idleDog.Name, err)
+		// Session is already started — dog will find no mail and idle out.
 	}
+
+	d.logger.Printf("Handler: dispatched plugin %s to dog %s", p.Name, idleDog.Name)
+	return true, true
 }
 
 // loadRigsConfig loads the rigs configuration from mayor/rigs.json.
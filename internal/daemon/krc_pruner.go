@@ -81,9 +81,10 @@ func (p *KRCPruner) prune() {
 		return
 	}
 
-	if result.EventsPruned > 0 {
-		p.logger("KRC pruned %d events (saved %d bytes) in %v",
+	if result.EventsPruned > 0 || result.RecordingsPruned > 0 {
+		p.logger("KRC pruned %d events, %d recordings (saved %d bytes) in %v",
 			result.EventsPruned,
+			result.RecordingsPruned,
 			result.BytesBefore-result.BytesAfter,
 			result.Duration.Round(time.Millisecond))
 	}
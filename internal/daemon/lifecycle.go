@@ -173,8 +173,9 @@ func (d *Daemon) executeLifecycleAction(request *LifecycleRequest) error {
 		}
 	}
 
-	// Check if session exists (tmux detection still needed for lifecycle actions)
-	running, err := d.tmux.HasSession(sessionName)
+	// Check if session exists (session detection still needed for lifecycle actions)
+	b := d.sessions()
+	running, err := b.HasSession(sessionName)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -184,7 +185,7 @@ func (d *Daemon) executeLifecycleAction(request *LifecycleRequest) error {
 		if running {
 			// Use KillSessionWithProcesses to ensure all descendant processes are killed.
 			// This prevents orphan bash processes from Claude's Bash tool surviving session termination.
-			if err := d.tmux.KillSessionWithProcesses(sessionName); err != nil {
+			if err := b.KillSessionWithProcesses(sessionName); err != nil {
 				return fmt.Errorf("killing session: %w", err)
 			}
 			d.logger.Printf("Killed session %s", sessionName)
@@ -194,7 +195,7 @@ func (d *Daemon) executeLifecycleAction(request *LifecycleRequest) error {
 	case ActionCycle, ActionRestart:
 		if running {
 			// Kill the session first - use KillSessionWithProcesses to prevent orphan processes.
-			if err := d.tmux.KillSessionWithProcesses(sessionName); err != nil {
+			if err := b.KillSessionWithProcesses(sessionName); err != nil {
 				return fmt.Errorf("killing session: %w", err)
 			}
 			d.logger.Printf("Killed session %s for restart", sessionName)
@@ -373,6 +374,21 @@ func (d *Daemon) restartSession(sessionName, identity string) error {
 	// the shell might not be ready to receive keystrokes, producing empty windows.
 	startCmd := d.getStartCommand(config, parsed)
 
+	// Headless towns run the agent under the PTY supervisor, with its
+	// environment passed at creation.
+	if session.IsHeadless(d.config.TownRoot) {
+		b := d.sessions()
+		if running, _ := b.HasSession(sessionName); running {
+			d.logger.Printf("Session %s already running with healthy agent, skipping restart", sessionName)
+			return nil
+		}
+		env := d.sessionEnvironment(sessionName, config, parsed)
+		if err := session.StartHeadlessAgent(b, sessionName, workDir, startCmd, env, nil); err != nil {
+			return fmt.Errorf("creating session: %w", err)
+		}
+		return nil
+	}
+
 	// Create session with command as initial process (replaces EnsureSessionFresh + SendKeys).
 	// EnsureSessionFreshWithCommand kills zombie sessions and creates a new one atomically.
 	if err := d.tmux.EnsureSessionFreshWithCommand(sessionName, workDir, startCmd); err != nil {
@@ -526,15 +542,7 @@ func (d *Daemon) getStartCommand(roleConfig *beads.RoleConfig, parsed *ParsedIde
 // setSessionEnvironment sets environment variables for the tmux session.
 // Uses centralized AgentEnv for consistency, plus custom env vars from role config if available.
 func (d *Daemon) setSessionEnvironment(sessionName string, roleConfig *beads.RoleConfig, parsed *ParsedIdentity) {
-	// Use centralized AgentEnv for base environment variables
-	envVars := config.AgentEnv(config.AgentEnvConfig{
-		Role:        parsed.RoleType,
-		Rig:         parsed.RigName,
-		AgentName:   parsed.AgentName,
-		TownRoot:    d.config.TownRoot,
-		SessionName: sessionName,
-	})
-	for k, v := range envVars {
+	for k, v := range d.sessionEnvironment(sessionName, roleConfig, parsed) {
 		_ = d.tmux.SetEnvironment(sessionName, k, v)
 	}
 
@@ -542,14 +550,25 @@ func (d *Daemon) setSessionEnvironment(sessionName string, roleConfig *beads.Rol
 	if paneID, err := d.tmux.GetPaneID(sessionName); err == nil {
 		_ = d.tmux.SetEnvironment(sessionName, "GT_PANE_ID", paneID)
 	}
+}
 
-	// Set any custom env vars from role config
+// sessionEnvironment returns the environment of a restarted agent session:
+// the centralized AgentEnv, overridden by custom env vars from role config
+// if available.
+func (d *Daemon) sessionEnvironment(sessionName string, roleConfig *beads.RoleConfig, parsed *ParsedIdentity) map[string]string {
+	envVars := config.AgentEnv(config.AgentEnvConfig{
+		Role:        parsed.RoleType,
+		Rig:         parsed.RigName,
+		AgentName:   parsed.AgentName,
+		TownRoot:    d.config.TownRoot,
+		SessionName: sessionName,
+	})
 	if roleConfig != nil {
 		for k, v := range roleConfig.EnvVars {
-			expanded := beads.ExpandRolePattern(v, d.config.TownRoot, parsed.RigName, parsed.AgentName, parsed.RoleType, session.PrefixFor(parsed.RigName))
-			_ = d.tmux.SetEnvironment(sessionName, k, expanded)
+			envVars[k] = beads.ExpandRolePattern(v, d.config.TownRoot, parsed.RigName, parsed.AgentName, parsed.RoleType, session.PrefixFor(parsed.RigName))
 		}
 	}
+	return envVars
 }
 
 // applySessionTheme applies tmux theming to the session.
@@ -1037,7 +1056,7 @@ func (d *Daemon) checkRigGUPPViolations(rigName string) {
 		sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
 
 		// Check if tmux session exists and agent is running
-		if d.tmux.IsAgentAlive(sessionName) {
+		if d.sessions().IsAgentAlive(sessionName) {
 			// Session is alive - check if it's been stuck too long
 			updatedAt, err := time.Parse(time.RFC3339, agent.UpdatedAt)
 			if err != nil {
@@ -1125,14 +1144,14 @@ func (d *Daemon) checkRigOrphanedWork(rigName string) {
 		sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
 
 		// Session running = not orphaned (work is being processed)
-		if d.tmux.IsAgentAlive(sessionName) {
+		if d.sessions().IsAgentAlive(sessionName) {
 			continue
 		}
 
 		// TOCTOU guard: re-verify agent state before taking action.
 		// Between the bd list above and now, the agent may have been
 		// restarted or its hook_bead cleared. Re-check both conditions.
-		if d.tmux.IsAgentAlive(sessionName) {
+		if d.sessions().IsAgentAlive(sessionName) {
 			continue
 		}
 		currentHookBead := d.getAgentHookBead(agent.ID)
@@ -0,0 +1,565 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	beadsdk "github.com/steveyegge/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/dog"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/plugin"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+const (
+	// defaultPluginEventsInterval is how often the events stream and beads
+	// stores are checked for occurrences that open plugin event gates.
+	defaultPluginEventsInterval = 15 * time.Second
+
+	// defaultPluginCommitsInterval is how often watched branches are checked
+	// for new commits. Each check is a git ls-remote per watched rig.
+	defaultPluginCommitsInterval = 5 * time.Minute
+
+	// maxPendingTriggers caps the triggers kept per plugin while it waits
+	// for an idle dog or its debounce window; older triggers are dropped.
+	maxPendingTriggers = 20
+
+	// lsRemoteTimeout bounds one git ls-remote against a rig's origin.
+	lsRemoteTimeout = 30 * time.Second
+)
+
+// PluginEventsConfig holds configuration for the plugin_events patrol.
+// This patrol dispatches plugins with event gates (bead.closed, mr.merged,
+// convoy.landed, escalation, commits) when matching town events occur.
+type PluginEventsConfig struct {
+	// Enabled controls whether event gates are dispatched. Default: true (nil).
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// IntervalStr is how often to check for new events (e.g., "15s").
+	IntervalStr string `json:"interval,omitempty"`
+
+	// CommitsIntervalStr is how often to check watched branches for new
+	// commits (e.g., "5m").
+	CommitsIntervalStr string `json:"commits_interval,omitempty"`
+}
+
+func pluginEventsConfig(config *DaemonPatrolConfig) *PluginEventsConfig {
+	if config != nil && config.Patrols != nil && config.Patrols.PluginEvents != nil {
+		return config.Patrols.PluginEvents
+	}
+	return &PluginEventsConfig{}
+}
+
+// pluginEventsInterval returns the configured interval, or the default (15s).
+func pluginEventsInterval(config *DaemonPatrolConfig) time.Duration {
+	if s := pluginEventsConfig(config).IntervalStr; s != "" {
+		if d, err := time.ParseDuration(s); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultPluginEventsInterval
+}
+
+// pluginCommitsInterval returns the configured branch check interval, or the
+// default (5m).
+func pluginCommitsInterval(config *DaemonPatrolConfig) time.Duration {
+	if s := pluginEventsConfig(config).CommitsIntervalStr; s != "" {
+		if d, err := time.ParseDuration(s); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultPluginCommitsInterval
+}
+
+// pluginEventState tracks where the plugin_events patrol is in each event
+// source, and the triggers waiting to be dispatched.
+type pluginEventState struct {
+	// feedOffset is the byte offset read so far in .events.jsonl; -1 until
+	// the first tick, which skips history.
+	feedOffset int64
+
+	// beadMarks are per-store event high-water marks; nil while no plugin
+	// watches bead closes, so the next watcher starts from "now".
+	beadMarks map[string]int64
+
+	// branchHeads maps "<rig> <branch>" to the last seen head commit.
+	// watchedRigs records rigs whose heads have been seeded.
+	branchHeads     map[string]string
+	watchedRigs     map[string]bool
+	lastCommitsPoll time.Time
+
+	// pending holds matched triggers per plugin name.
+	pending map[string][]plugin.Event
+}
+
+func newPluginEventState() *pluginEventState {
+	return &pluginEventState{
+		feedOffset:  -1,
+		branchHeads: make(map[string]string),
+		watchedRigs: make(map[string]bool),
+		pending:     make(map[string][]plugin.Event),
+	}
+}
+
+// dispatchPluginEvents collects new town events, queues them for the plugins
+// whose event gates they match, and hands queued plugins to idle dogs.
+func (d *Daemon) dispatchPluginEvents() {
+	if d.pluginEvents == nil {
+		d.pluginEvents = newPluginEventState()
+	}
+	st := d.pluginEvents
+
+	rigsConfig, err := d.loadRigsConfig()
+	if err != nil {
+		d.logger.Printf("plugin_events: failed to load rigs config: %v", err)
+		return
+	}
+	var rigNames []string
+	for name := range rigsConfig.Rigs {
+		rigNames = append(rigNames, name)
+	}
+	sort.Strings(rigNames)
+
+	all, err := plugin.NewScanner(d.config.TownRoot, rigNames).DiscoverAll()
+	if err != nil {
+		d.logger.Printf("plugin_events: failed to discover plugins: %v", err)
+		return
+	}
+	var watchers []*plugin.Plugin
+	wants := make(map[string]bool)
+	for _, p := range all {
+		if p.Gate != nil && p.Gate.Type == plugin.GateEvent && plugin.IsTownEvent(p.Gate.On) {
+			watchers = append(watchers, p)
+			wants[p.Gate.On] = true
+		}
+	}
+
+	// The feed cursor always advances so a newly added plugin doesn't see
+	// a backlog of old events.
+	occurred := d.readFeedPluginEvents(st)
+	if wants[plugin.EventBeadClosed] {
+		occurred = append(occurred, d.pollBeadCloses(st)...)
+	} else {
+		st.beadMarks = nil
+	}
+	if wants[plugin.EventCommits] && time.Since(st.lastCommitsPoll) >= pluginCommitsInterval(d.patrolConfig) {
+		st.lastCommitsPoll = time.Now()
+		occurred = append(occurred, d.pollWatchedBranches(st, watchers, rigNames)...)
+	}
+
+	for _, e := range occurred {
+		for _, p := range watchers {
+			if d.pluginGateMatches(p, e) {
+				q := append(st.pending[p.Name], e)
+				if len(q) > maxPendingTriggers {
+					q = q[len(q)-maxPendingTriggers:]
+				}
+				st.pending[p.Name] = q
+			}
+		}
+	}
+
+	d.flushPluginTriggers(st, watchers, rigsConfig)
+}
+
+// pluginGateMatches is Gate.Matches, except that a commits gate without a
+// branch only watches the rig's default branch.
+func (d *Daemon) pluginGateMatches(p *plugin.Plugin, e plugin.Event) bool {
+	if !p.Gate.Matches(e, p.RigName) {
+		return false
+	}
+	if e.Kind == plugin.EventCommits && p.Gate.Branch == "" {
+		return e.Branch == d.rigDefaultBranch(e.Rig)
+	}
+	return true
+}
+
+// flushPluginTriggers dispatches plugins with pending triggers. A plugin
+// keeps its triggers while a dog is still running it, while its debounce
+// window (gate duration) is open, or until a dog is idle.
+func (d *Daemon) flushPluginTriggers(st *pluginEventState, watchers []*plugin.Plugin, rigsConfig *config.RigsConfig) {
+	if len(st.pending) == 0 {
+		return
+	}
+	byName := make(map[string]*plugin.Plugin, len(watchers))
+	for _, p := range watchers {
+		byName[p.Name] = p
+	}
+	names := make([]string, 0, len(st.pending))
+	for name := range st.pending {
+		if byName[name] == nil {
+			delete(st.pending, name) // plugin removed or gate changed
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	mgr := dog.NewManager(d.config.TownRoot, rigsConfig)
+	sm := dog.NewSessionManager(tmux.NewTmux(), d.config.TownRoot, mgr)
+	dogs, err := mgr.List()
+	if err != nil {
+		d.logger.Printf("plugin_events: failed to list dogs: %v", err)
+		return
+	}
+	running := make(map[string]bool)
+	for _, dg := range dogs {
+		if dg.State == dog.StateWorking {
+			running[dg.Work] = true
+		}
+	}
+
+	recorder := plugin.NewRecorder(d.config.TownRoot)
+	router := mail.NewRouterWithTownRoot(d.config.TownRoot, d.config.TownRoot)
+	for _, name := range names {
+		p := byName[name]
+		if running["plugin:"+name] {
+			continue
+		}
+		if p.Gate.Duration != "" {
+			count, err := recorder.CountRunsSince(p.Name, p.Gate.Duration)
+			if err != nil {
+				d.logger.Printf("plugin_events: error checking debounce for plugin %s: %v", p.Name, err)
+				continue
+			}
+			if count > 0 {
+				continue // Ran recently; keep collecting triggers
+			}
+		}
+		dispatched, more := d.sendPluginToDog(mgr, sm, router, p, p.FormatTriggeredMailBody(st.pending[name]))
+		if dispatched {
+			delete(st.pending, name)
+		}
+		if !more {
+			return
+		}
+	}
+}
+
+// readFeedPluginEvents returns gate-relevant events appended to .events.jsonl
+// since the last call. The first call only records the end of the file.
+func (d *Daemon) readFeedPluginEvents(st *pluginEventState) []plugin.Event {
+	f, err := os.Open(filepath.Join(d.config.TownRoot, events.EventsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			st.feedOffset = 0
+		}
+		return nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil
+	}
+	size := info.Size()
+	// First tick, or the file was pruned (KRC) under us: start from the end.
+	if st.feedOffset < 0 || size < st.feedOffset {
+		st.feedOffset = size
+		return nil
+	}
+	if size == st.feedOffset {
+		return nil
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(f, st.feedOffset, size-st.feedOffset))
+	if err != nil {
+		d.logger.Printf("plugin_events: reading events file: %v", err)
+		return nil
+	}
+	// Leave a partially written last line for the next tick.
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil
+	}
+	st.feedOffset += int64(end + 1)
+
+	var out []plugin.Event
+	for _, line := range bytes.Split(data[:end], []byte{'\n'}) {
+		var fe events.Event
+		if err := json.Unmarshal(line, &fe); err != nil {
+			continue
+		}
+		if e, ok := pluginEventFromFeed(fe); ok {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// pluginEventFromFeed maps a feed event to a plugin gate event. Only merges,
+// convoy landings and escalations are gate-relevant.
+func pluginEventFromFeed(fe events.Event) (plugin.Event, bool) {
+	t, _ := time.Parse(time.RFC3339, fe.Timestamp)
+	str := func(key string) string {
+		if v, ok := fe.Payload[key].(string); ok {
+			return v
+		}
+		return ""
+	}
+	e := plugin.Event{Actor: fe.Actor, Time: t}
+
+	switch fe.Type {
+	case events.TypeMerged:
+		e.Kind = plugin.EventMRMerged
+		e.Rig = str("rig")
+		if e.Rig == "" {
+			e.Rig = rigFromActor(fe.Actor)
+		}
+		e.Branch = str("target")
+		e.Subject = str("mr")
+		e.Summary = str("branch")
+		if w := str("worker"); w != "" {
+			e.Summary = strings.TrimSpace(e.Summary + " from " + w)
+		}
+	case events.TypeConvoyLanded:
+		e.Kind = plugin.EventConvoyLanded
+		e.Subject = str("convoy")
+		e.Summary = str("title")
+	case events.TypeEscalationSent:
+		e.Kind = plugin.EventEscalation
+		e.Rig = rigFromActor(fe.Actor)
+		// gt escalate records the escalation bead under "rig"; re-escalation
+		// uses "escalation_id".
+		e.Subject = str("escalation_id")
+		if e.Subject == "" {
+			e.Subject = str("rig")
+		}
+		e.Summary = str("reason")
+		severity := str("severity")
+		if severity == "" {
+			severity = str("new_severity")
+		}
+		if severity != "" {
+			e.Labels = []string{"severity:" + severity}
+		}
+	default:
+		return plugin.Event{}, false
+	}
+	return e, true
+}
+
+// rigFromActor returns the rig of a rig-level agent address, or "".
+func rigFromActor(actor string) string {
+	id, err := session.ParseAddress(actor)
+	if err != nil {
+		return ""
+	}
+	return id.Rig
+}
+
+// pollBeadCloses returns issues closed since the last poll across all beads
+// stores. The first poll after a plugin starts watching only records
+// high-water marks.
+func (d *Daemon) pollBeadCloses(st *pluginEventState) []plugin.Event {
+	if d.convoyManager == nil {
+		return nil
+	}
+	stores := d.convoyManager.beadsStores()
+	if stores == nil {
+		return nil // Dolt not ready yet
+	}
+	seeding := st.beadMarks == nil
+	if seeding {
+		st.beadMarks = make(map[string]int64)
+	}
+
+	names := make([]string, 0, len(stores))
+	for name := range stores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out []plugin.Event
+	seen := make(map[string]bool)
+	for _, name := range names {
+		store := stores[name]
+		mark, known := st.beadMarks[name]
+		evs, err := store.GetAllEventsSince(d.ctx, mark)
+		if err != nil {
+			d.logger.Printf("plugin_events: event poll error (%s): %v", name, err)
+			continue
+		}
+		for _, e := range evs {
+			if e.ID > mark {
+				mark = e.ID
+			}
+		}
+		st.beadMarks[name] = mark
+		if seeding || !known {
+			continue
+		}
+
+		for _, e := range evs {
+			isClose := e.EventType == beadsdk.EventClosed
+			if !isClose && e.EventType == beadsdk.EventStatusChanged {
+				isClose = e.NewValue != nil && *e.NewValue == "closed"
+			}
+			if !isClose || e.IssueID == "" || seen[e.IssueID] {
+				continue
+			}
+			seen[e.IssueID] = true
+
+			ev := plugin.Event{
+				Kind:    plugin.EventBeadClosed,
+				Actor:   e.Actor,
+				Subject: e.IssueID,
+				Time:    e.CreatedAt,
+			}
+			if name != "hq" {
+				ev.Rig = name
+			}
+			if labels, err := store.GetLabels(d.ctx, e.IssueID); err == nil {
+				ev.Labels = labels
+			}
+			if issue, err := store.GetIssue(d.ctx, e.IssueID); err == nil && issue != nil {
+				ev.Summary = issue.Title
+			}
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+// pollWatchedBranches returns new-commit events for branches watched by
+// commits gates. A rig's first check only records its branch heads.
+func (d *Daemon) pollWatchedBranches(st *pluginEventState, watchers []*plugin.Plugin, rigNames []string) []plugin.Event {
+	// Collect branch patterns per rig.
+	patterns := make(map[string][]string)
+	for _, p := range watchers {
+		if p.Gate.On != plugin.EventCommits {
+			continue
+		}
+		for _, rigName := range rigNames {
+			if !p.Gate.Matches(plugin.Event{Kind: plugin.EventCommits, Rig: rigName}, p.RigName) {
+				continue
+			}
+			pattern := p.Gate.Branch
+			if pattern == "" {
+				pattern = d.rigDefaultBranch(rigName)
+			}
+			patterns[rigName] = append(patterns[rigName], pattern)
+		}
+	}
+
+	var out []plugin.Event
+	for _, rigName := range rigNames {
+		if len(patterns[rigName]) == 0 {
+			delete(st.watchedRigs, rigName)
+			continue
+		}
+		heads, err := d.lsRemoteHeads(rigName)
+		if err != nil {
+			d.logger.Printf("plugin_events: checking branches of %s: %v", rigName, err)
+			continue
+		}
+		seeded := st.watchedRigs[rigName]
+		st.watchedRigs[rigName] = true
+
+		branches := make([]string, 0, len(heads))
+		for branch := range heads {
+			branches = append(branches, branch)
+		}
+		sort.Strings(branches)
+		for _, branch := range branches {
+			if !matchesAny(patterns[rigName], branch) {
+				continue
+			}
+			key := rigName + " " + branch
+			head, prev := heads[branch], st.branchHeads[key]
+			st.branchHeads[key] = head
+			if !seeded || head == prev {
+				continue
+			}
+			e := plugin.Event{
+				Kind:    plugin.EventCommits,
+				Rig:     rigName,
+				Branch:  branch,
+				Subject: shortSHA(head),
+				Summary: "new branch",
+				Time:    time.Now(),
+			}
+			if prev != "" {
+				e.Summary = fmt.Sprintf("%s..%s", shortSHA(prev), shortSHA(head))
+			}
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// lsRemoteHeads lists branch heads on a rig's origin.
+func (d *Daemon) lsRemoteHeads(rigName string) (map[string]string, error) {
+	rigPath := filepath.Join(d.config.TownRoot, rigName)
+	repoDir := filepath.Join(rigPath, ".repo.git")
+	if _, err := os.Stat(repoDir); err != nil {
+		repoDir = filepath.Join(rigPath, "mayor", "rig")
+	}
+
+	ctx, cancel := context.WithTimeout(d.ctx, lsRemoteTimeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--heads", "origin")
+	cmd.Dir = repoDir
+	cmd.Env = os.Environ() // Inherit PATH and credentials helpers
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return parseLsRemoteHeads(string(out)), nil
+}
+
+// parseLsRemoteHeads parses `git ls-remote --heads` output into branch → SHA.
+func parseLsRemoteHeads(out string) map[string]string {
+	heads := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		sha, ref, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		if branch, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			heads[branch] = sha
+		}
+	}
+	return heads
+}
+
+// rigDefaultBranch returns the rig's configured default branch, or "main".
+func (d *Daemon) rigDefaultBranch(rigName string) string {
+	if rigCfg, err := rig.LoadRigConfig(filepath.Join(d.config.TownRoot, rigName)); err == nil && rigCfg.DefaultBranch != "" {
+		return rigCfg.DefaultBranch
+	}
+	return "main"
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if plugin.MatchPattern(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
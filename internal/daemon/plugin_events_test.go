@@ -0,0 +1,228 @@
+package daemon
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/plugin"
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+func TestPluginEventsEnabledByDefault(t *testing.T) {
+	if !IsPatrolEnabled(nil, "plugin_events") {
+		t.Error("plugin_events should be enabled with no config")
+	}
+	off := false
+	cfg := &DaemonPatrolConfig{Patrols: &PatrolsConfig{PluginEvents: &PluginEventsConfig{Enabled: &off}}}
+	if IsPatrolEnabled(cfg, "plugin_events") {
+		t.Error("plugin_events should be disabled with enabled: false")
+	}
+	cfg.Patrols.PluginEvents = &PluginEventsConfig{IntervalStr: "1m", CommitsIntervalStr: "30m"}
+	if d := pluginEventsInterval(cfg); d.String() != "1m0s" {
+		t.Errorf("interval = %v", d)
+	}
+	if d := pluginCommitsInterval(nil); d != defaultPluginCommitsInterval {
+		t.Errorf("default commits interval = %v", d)
+	}
+}
+
+func TestPluginEventFromFeed(t *testing.T) {
+	merged := events.MergePayload("gt-mr1", "nux", "polecat/nux", "")
+	merged["rig"] = "gastown"
+	merged["target"] = "main"
+	esc := events.EscalationPayload("hq-esc1", "gastown/polecats/nux", "mayor/", "stuck on auth")
+	esc["severity"] = "high"
+
+	tests := []struct {
+		name string
+		in   events.Event
+		want plugin.Event
+	}{
+		{
+			"merged",
+			events.Event{Type: events.TypeMerged, Actor: "gastown/refinery", Payload: merged},
+			plugin.Event{Kind: plugin.EventMRMerged, Rig: "gastown", Actor: "gastown/refinery", Branch: "main", Subject: "gt-mr1", Summary: "polecat/nux from nux"},
+		},
+		{
+			"convoy landed",
+			events.Event{Type: events.TypeConvoyLanded, Actor: "mayor", Payload: events.ConvoyLandedPayload("hq-cv1", "Auth rework")},
+			plugin.Event{Kind: plugin.EventConvoyLanded, Actor: "mayor", Subject: "hq-cv1", Summary: "Auth rework"},
+		},
+		{
+			"escalation",
+			events.Event{Type: events.TypeEscalationSent, Actor: "gastown/polecats/nux", Payload: esc},
+			plugin.Event{Kind: plugin.EventEscalation, Rig: "gastown", Actor: "gastown/polecats/nux", Subject: "hq-esc1", Summary: "stuck on auth", Labels: []string{"severity:high"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pluginEventFromFeed(tt.in)
+			if !ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pluginEventFromFeed = %+v, %v; want %+v", got, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := pluginEventFromFeed(events.Event{Type: events.TypeSling}); ok {
+		t.Error("sling should not be gate-relevant")
+	}
+}
+
+func testPluginEventsDaemon(t *testing.T) *Daemon {
+	t.Helper()
+	return &Daemon{
+		config: &Config{TownRoot: t.TempDir()},
+		logger: log.New(io.Discard, "", 0),
+		ctx:    context.Background(),
+	}
+}
+
+func TestReadFeedPluginEvents(t *testing.T) {
+	d := testPluginEventsDaemon(t)
+	path := filepath.Join(d.config.TownRoot, events.EventsFile)
+	appendLine := func(s string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	landed := `{"ts":"2026-01-01T00:00:00Z","type":"convoy_landed","actor":"mayor","payload":{"convoy":"hq-cv%s"}}` + "\n"
+
+	appendLine(landed[:len(landed)-1] + "\n") // history before the daemon started
+	st := newPluginEventState()
+	if got := d.readFeedPluginEvents(st); got != nil {
+		t.Fatalf("first read replayed history: %+v", got)
+	}
+
+	appendLine(`{"type":"sling","actor":"mayor"}` + "\n")
+	appendLine(`{"ts":"2026-01-01T00:00:00Z","type":"convoy_landed","actor":"mayor","payload":{"convoy":"hq-cv2"}}` + "\n")
+	appendLine(`{"ts":"2026-01-01T00:00:01Z","type":"convoy_landed"`) // still being written
+	got := d.readFeedPluginEvents(st)
+	if len(got) != 1 || got[0].Subject != "hq-cv2" {
+		t.Fatalf("read = %+v, want hq-cv2 only", got)
+	}
+
+	appendLine(`,"actor":"mayor","payload":{"convoy":"hq-cv3"}}` + "\n")
+	got = d.readFeedPluginEvents(st)
+	if len(got) != 1 || got[0].Subject != "hq-cv3" {
+		t.Fatalf("read = %+v, want the completed hq-cv3 line", got)
+	}
+
+	// A pruned (shrunk) file restarts from its end.
+	if err := os.WriteFile(path, []byte(landed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.readFeedPluginEvents(st); got != nil {
+		t.Errorf("read after prune = %+v, want nothing", got)
+	}
+}
+
+func TestParseLsRemoteHeads(t *testing.T) {
+	out := "aaaa\trefs/heads/main\nbbbb\trefs/heads/polecat/nux/gt-1@x\ncccc\trefs/tags/v1\n\n"
+	want := map[string]string{"main": "aaaa", "polecat/nux/gt-1@x": "bbbb"}
+	if got := parseLsRemoteHeads(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLsRemoteHeads = %v, want %v", got, want)
+	}
+}
+
+func TestPollWatchedBranches(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	d := testPluginEventsDaemon(t)
+	origin := filepath.Join(t.TempDir(), "origin.git")
+	clone := filepath.Join(d.config.TownRoot, "gastown", "mayor", "rig")
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.MkdirAll(clone, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git(d.config.TownRoot, "init", "--bare", "-b", "main", origin)
+	git(clone, "init", "-b", "main")
+	git(clone, "remote", "add", "origin", origin)
+	git(clone, "commit", "--allow-empty", "-m", "one")
+	git(clone, "push", "origin", "main")
+
+	watchers := []*plugin.Plugin{
+		{Name: "on-main", Gate: &plugin.Gate{Type: plugin.GateEvent, On: plugin.EventCommits}},
+		{Name: "on-release", Gate: &plugin.Gate{Type: plugin.GateEvent, On: plugin.EventCommits, Branch: "release/*"}},
+	}
+	st := newPluginEventState()
+	if got := d.pollWatchedBranches(st, watchers, []string{"gastown"}); got != nil {
+		t.Fatalf("first poll fired: %+v", got)
+	}
+
+	git(clone, "commit", "--allow-empty", "-m", "two")
+	git(clone, "push", "origin", "main")
+	git(clone, "push", "origin", "main:release/1")
+	git(clone, "push", "origin", "main:scratch")
+	got := d.pollWatchedBranches(st, watchers, []string{"gastown"})
+	if len(got) != 2 || got[0].Branch != "main" || got[1].Branch != "release/1" || got[1].Summary != "new branch" {
+		t.Fatalf("second poll = %+v, want main and release/1", got)
+	}
+	if !d.pluginGateMatches(watchers[0], got[0]) || d.pluginGateMatches(watchers[0], got[1]) {
+		t.Error("default-branch watcher should only match main")
+	}
+	if d.pluginGateMatches(watchers[1], got[0]) || !d.pluginGateMatches(watchers[1], got[1]) {
+		t.Error("release watcher should only match release/1")
+	}
+
+	if got := d.pollWatchedBranches(st, watchers, []string{"gastown"}); got != nil {
+		t.Errorf("unchanged poll fired: %+v", got)
+	}
+}
+
+// TestPostMergeOpensMergedGate checks that the event logged on post-merge
+// opens the shipped quality-review plugin's mr.merged gate.
+func TestPostMergeOpensMergedGate(t *testing.T) {
+	d := testPluginEventsDaemon(t)
+	town := d.config.TownRoot
+	content, err := os.ReadFile(filepath.Join("..", "..", "plugins", "quality-review", "plugin.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(town, "plugins", "quality-review")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "plugin.md"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := plugin.NewScanner(town, nil).GetPlugin("quality-review")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Gate == nil || p.Gate.On != plugin.EventMRMerged {
+		t.Fatalf("quality-review gate = %+v, want mr.merged", p.Gate)
+	}
+
+	st := newPluginEventState()
+	d.readFeedPluginEvents(st) // start at the end of the feed
+	refinery.LogMerged(town, "gastown", "gt-mr1", "nux", "polecat/nux", "main", "")
+
+	got := d.readFeedPluginEvents(st)
+	if len(got) != 1 || got[0].Kind != plugin.EventMRMerged || got[0].Rig != "gastown" || got[0].Branch != "main" {
+		t.Fatalf("feed events = %+v, want one gastown merge into main", got)
+	}
+	if !d.pluginGateMatches(p, got[0]) {
+		t.Errorf("merged event %+v does not open gate %+v", got[0], p.Gate)
+	}
+}
@@ -0,0 +1,185 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/procstat"
+	"github.com/steveyegge/gastown/internal/session"
+)
+
+const (
+	// defaultResourceSamplerInterval is how often session process trees
+	// are sampled. Short enough to catch a fork storm before it fills the
+	// process table, cheap enough to run all the time (one /proc walk).
+	defaultResourceSamplerInterval = 30 * time.Second
+
+	// resourceSeriesRetention is how long a session's series is kept after
+	// its last sample.
+	resourceSeriesRetention = 3 * 24 * time.Hour
+)
+
+// Default runaway thresholds for an agent session's process tree.
+const (
+	defaultRunawayMaxProcs      = 256
+	defaultRunawayMaxRSSMB      = 8192
+	defaultRunawayMaxCPUPercent = 400.0
+	defaultRunawayMaxOpenFiles  = 4096
+	defaultRunawaySustain       = 2
+)
+
+// ResourceSamplerConfig holds configuration for the resource_sampler patrol.
+// This patrol samples CPU, RSS, open files and process counts of every agent
+// session's process tree, records them under .runtime/vitals, and flags
+// runaway sessions to the feed and (for polecats) the rig's witness.
+type ResourceSamplerConfig struct {
+	// Enabled controls whether sampling runs. Default: true (nil).
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// IntervalStr is how often to sample, as a string (e.g., "30s").
+	IntervalStr string `json:"interval,omitempty"`
+
+	// Runaway thresholds for one session's process tree. Zero values mean
+	// "use default"; a negative value disables that check.
+	MaxProcs      int     `json:"max_procs,omitempty"`
+	MaxRSSMB      int     `json:"max_rss_mb,omitempty"`
+	MaxCPUPercent float64 `json:"max_cpu_percent,omitempty"` // 100 = one core
+	MaxOpenFiles  int     `json:"max_open_files,omitempty"`
+
+	// Sustain is how many consecutive samples must exceed a threshold
+	// before the session is flagged. Default: 2.
+	Sustain int `json:"sustain,omitempty"`
+}
+
+func resourceSamplerConfig(config *DaemonPatrolConfig) *ResourceSamplerConfig {
+	if config != nil && config.Patrols != nil && config.Patrols.ResourceSampler != nil {
+		return config.Patrols.ResourceSampler
+	}
+	return &ResourceSamplerConfig{}
+}
+
+// resourceSamplerInterval returns the configured interval, or the default (30s).
+func resourceSamplerInterval(config *DaemonPatrolConfig) time.Duration {
+	if s := resourceSamplerConfig(config).IntervalStr; s != "" {
+		if d, err := time.ParseDuration(s); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultResourceSamplerInterval
+}
+
+// RunawayThresholds returns the effective runaway thresholds and sustain
+// count, using config overrides or defaults.
+func RunawayThresholds(config *DaemonPatrolConfig) (procstat.Thresholds, int) {
+	cfg := resourceSamplerConfig(config)
+	pick := func(v, def int) int {
+		switch {
+		case v < 0:
+			return 0
+		case v == 0:
+			return def
+		}
+		return v
+	}
+	t := procstat.Thresholds{
+		MaxProcs:      pick(cfg.MaxProcs, defaultRunawayMaxProcs),
+		MaxRSSMB:      pick(cfg.MaxRSSMB, defaultRunawayMaxRSSMB),
+		MaxCPUPercent: defaultRunawayMaxCPUPercent,
+		MaxOpenFiles:  pick(cfg.MaxOpenFiles, defaultRunawayMaxOpenFiles),
+	}
+	switch {
+	case cfg.MaxCPUPercent < 0:
+		t.MaxCPUPercent = 0
+	case cfg.MaxCPUPercent > 0:
+		t.MaxCPUPercent = cfg.MaxCPUPercent
+	}
+	return t, pick(cfg.Sustain, defaultRunawaySustain)
+}
+
+// sampleResources records one sample per live agent session and reports
+// sessions that have become runaways.
+func (d *Daemon) sampleResources() {
+	table, err := procstat.ReadTable()
+	if err != nil {
+		d.logger.Printf("resource_sampler: reading process table: %v", err)
+		return
+	}
+	backend := d.sessions()
+	names, err := backend.ListSessions()
+	if err != nil {
+		d.logger.Printf("resource_sampler: listing sessions: %v", err)
+		return
+	}
+
+	if d.resourceSampler == nil {
+		d.resourceSampler = procstat.NewSampler()
+	}
+	thresholds, sustain := RunawayThresholds(d.patrolConfig)
+	if d.runawayDetector == nil {
+		d.runawayDetector = procstat.NewDetector(thresholds, sustain)
+	}
+
+	now := time.Now()
+	live := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !session.IsKnownSession(name) {
+			continue
+		}
+		pidStr, err := backend.GetPanePID(name)
+		if err != nil {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(pidStr))
+		if err != nil {
+			continue
+		}
+		live[name] = true
+
+		s := d.resourceSampler.Sample(table, name, pid, now)
+		if err := procstat.Append(d.config.TownRoot, s); err != nil {
+			d.logger.Printf("resource_sampler: recording %s: %v", name, err)
+		}
+		if reasons := d.runawayDetector.Observe(s); reasons != nil {
+			d.reportRunaway(s, reasons)
+		}
+	}
+	d.resourceSampler.Retain(live)
+	d.runawayDetector.Retain(live)
+	procstat.PruneSeries(d.config.TownRoot, resourceSeriesRetention)
+}
+
+// reportRunaway logs a runaway session to the feed and, for polecats, mails
+// the rig's witness, which decides whether to nudge, restart or escalate.
+func (d *Daemon) reportRunaway(s procstat.Sample, reasons []string) {
+	detail := strings.Join(reasons, ", ")
+	d.logger.Printf("RUNAWAY: %s (PID %d): %s", s.Session, s.PID, detail)
+	_ = events.LogFeed(events.TypeRunawayAgent, "daemon",
+		events.RunawayAgentPayload(s.Session, s.PID, detail))
+
+	id, err := session.ParseSessionName(s.Session)
+	if err != nil || id.Role != session.RolePolecat {
+		return
+	}
+	witnessAddr := id.Rig + "/witness"
+	subject := fmt.Sprintf("RUNAWAY_POLECAT: %s/%s", id.Rig, id.Name)
+	body := fmt.Sprintf(`Polecat %s's process tree has stayed over its resource limits.
+
+session: %s
+root_pid: %d
+exceeded: %s
+
+Inspect with: gt vitals --session %s`,
+		id.Name, s.Session, s.PID, detail, s.Session)
+
+	cmd := exec.Command(d.gtPath, "mail", "send", witnessAddr, "-s", subject, "-m", body) //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = d.config.TownRoot
+	cmd.Env = os.Environ() // Inherit PATH to find gt executable
+	if err := cmd.Run(); err != nil {
+		d.logger.Printf("Warning: failed to notify witness of runaway polecat: %v", err)
+	}
+}
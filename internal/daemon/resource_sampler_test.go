@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/procstat"
+)
+
+func TestResourceSamplerEnabledByDefault(t *testing.T) {
+	if !IsPatrolEnabled(nil, "resource_sampler") {
+		t.Error("resource_sampler should be enabled with no config")
+	}
+	cfg := &DaemonPatrolConfig{Patrols: &PatrolsConfig{ResourceSampler: &ResourceSamplerConfig{MaxProcs: 64}}}
+	if !IsPatrolEnabled(cfg, "resource_sampler") {
+		t.Error("resource_sampler should stay enabled when only thresholds are set")
+	}
+	off := false
+	cfg.Patrols.ResourceSampler.Enabled = &off
+	if IsPatrolEnabled(cfg, "resource_sampler") {
+		t.Error("resource_sampler should be disabled with enabled: false")
+	}
+}
+
+func TestRunawayThresholds(t *testing.T) {
+	got, sustain := RunawayThresholds(nil)
+	want := procstat.Thresholds{
+		MaxProcs:      defaultRunawayMaxProcs,
+		MaxRSSMB:      defaultRunawayMaxRSSMB,
+		MaxCPUPercent: defaultRunawayMaxCPUPercent,
+		MaxOpenFiles:  defaultRunawayMaxOpenFiles,
+	}
+	if got != want || sustain != defaultRunawaySustain {
+		t.Errorf("defaults = %+v, %d; want %+v, %d", got, sustain, want, defaultRunawaySustain)
+	}
+
+	cfg := &DaemonPatrolConfig{Patrols: &PatrolsConfig{ResourceSampler: &ResourceSamplerConfig{
+		IntervalStr:   "10s",
+		MaxProcs:      64,
+		MaxCPUPercent: -1,
+		MaxOpenFiles:  -1,
+		Sustain:       5,
+	}}}
+	got, sustain = RunawayThresholds(cfg)
+	want = procstat.Thresholds{MaxProcs: 64, MaxRSSMB: defaultRunawayMaxRSSMB}
+	if got != want || sustain != 5 {
+		t.Errorf("overrides = %+v, %d; want %+v, 5", got, sustain, want)
+	}
+	if d := resourceSamplerInterval(cfg); d != 10*time.Second {
+		t.Errorf("interval = %v, want 10s", d)
+	}
+	if d := resourceSamplerInterval(nil); d != defaultResourceSamplerInterval {
+		t.Errorf("default interval = %v", d)
+	}
+}
@@ -128,6 +128,8 @@ type PatrolsConfig struct {
 	CompactorDog           *CompactorDogConfig            `json:"compactor_dog,omitempty"`
 	ScheduledMaintenance   *ScheduledMaintenanceConfig    `json:"scheduled_maintenance,omitempty"`
 	RestartTracker         *RestartTrackerConfig          `json:"restart_tracker,omitempty"`
+	ResourceSampler        *ResourceSamplerConfig         `json:"resource_sampler,omitempty"`
+	PluginEvents           *PluginEventsConfig            `json:"plugin_events,omitempty"`
 }
 
 // DoltRemotesConfig holds configuration for the dolt_remotes patrol.
@@ -292,6 +294,20 @@ func IsPatrolEnabled(config *DaemonPatrolConfig, patrol string) bool {
 		return true // Default: enabled
 	}
 
+	if patrol == "resource_sampler" {
+		if config.Patrols.ResourceSampler == nil || config.Patrols.ResourceSampler.Enabled == nil {
+			return true
+		}
+		return *config.Patrols.ResourceSampler.Enabled
+	}
+
+	if patrol == "plugin_events" {
+		if config.Patrols.PluginEvents == nil || config.Patrols.PluginEvents.Enabled == nil {
+			return true
+		}
+		return *config.Patrols.PluginEvents.Enabled
+	}
+
 	switch patrol {
 	case constants.RoleRefinery:
 		if config.Patrols.Refinery != nil {
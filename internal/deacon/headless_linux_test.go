@@ -0,0 +1,95 @@
+//go:build linux
+
+package deacon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/ptyd"
+)
+
+// startHeadlessTown creates a town that runs agents under a PTY supervisor
+// serving on the town's socket. The deacon runs a "fake" agent that prints
+// its ready prompt (ending in the ❯ the dialog poll waits for) and waits.
+func startHeadlessTown(t *testing.T) string {
+	t.Helper()
+	if _, err := os.Stat("/dev/ptmx"); err != nil {
+		t.Skip("no /dev/ptmx")
+	}
+	// Unix socket paths are length-limited; t.TempDir() can be too long.
+	town, err := os.MkdirTemp("", "gt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(town) })
+
+	agent := filepath.Join(town, "fake-agent")
+	if err := os.WriteFile(agent, []byte("#!/bin/sh\necho fake-ready ❯\nexec cat\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	settings := config.NewTownSettings()
+	settings.SessionBackend = "pty"
+	settings.Agents = map[string]*config.RuntimeConfig{
+		"fake": {
+			Command: agent,
+			Tmux:    &config.RuntimeTmuxConfig{ReadyPromptPrefix: "fake-ready"},
+		},
+	}
+	settings.RoleAgents = map[string]string{"deacon": "fake"}
+	if err := config.SaveTownSettings(config.TownSettingsPath(town), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	sock := ptyd.SocketPath(town)
+	if err := os.MkdirAll(filepath.Dir(sock), 0755); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := ptyd.Listen(sock)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	s := ptyd.NewSupervisor(0)
+	go func() { _ = s.Serve(ln) }()
+	t.Cleanup(func() {
+		_ = ln.Close()
+		s.Close()
+	})
+	return town
+}
+
+// TestStart_HeadlessTown starts the deacon the way the daemon's degraded
+// boot triage does, on a town without tmux.
+func TestStart_HeadlessTown(t *testing.T) {
+	t.Setenv("GT_SESSION_BACKEND", "")
+	town := startHeadlessTown(t)
+
+	m := NewManager(town)
+	if err := m.Start(""); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = m.Stop() })
+
+	client := ptyd.NewClient(ptyd.SocketPath(town))
+	if ok, err := client.HasSession(m.SessionName()); err != nil || !ok {
+		t.Fatalf("supervisor HasSession = %v, %v; want true", ok, err)
+	}
+	if running, err := m.IsRunning(); err != nil || !running {
+		t.Fatalf("IsRunning = %v, %v; want true", running, err)
+	}
+	if env, _ := client.GetEnvironment(m.SessionName(), "GT_ROLE"); env != "deacon" {
+		t.Errorf("GT_ROLE = %q, want deacon", env)
+	}
+	if err := m.Start(""); err != ErrAlreadyRunning {
+		t.Errorf("second Start = %v, want ErrAlreadyRunning", err)
+	}
+
+	if err := m.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if running, _ := m.IsRunning(); running {
+		t.Error("deacon still running after Stop")
+	}
+}
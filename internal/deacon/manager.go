@@ -43,14 +43,38 @@ type tmuxOps interface {
 type Manager struct {
 	townRoot string
 	tmux     tmuxOps
+
+	// headless is the PTY supervisor backend for towns that run agents
+	// without tmux; nil means tmux.
+	headless session.SessionBackend
 }
 
 // NewManager creates a new deacon manager for a town.
 func NewManager(townRoot string) *Manager {
-	return &Manager{
+	m := &Manager{
 		townRoot: townRoot,
 		tmux:     tmux.NewTmux(),
 	}
+	if session.IsHeadless(townRoot) {
+		m.headless = session.NewBackend(townRoot, nil)
+	}
+	return m
+}
+
+// sessionOps is the part of a session backend that Stop and IsRunning use;
+// both tmuxOps and session.SessionBackend provide it.
+type sessionOps interface {
+	HasSession(name string) (bool, error)
+	SendKeysRaw(session, keys string) error
+	KillSessionWithProcesses(name string) error
+}
+
+// sessions returns the backend the deacon session lives in.
+func (m *Manager) sessions() sessionOps {
+	if m.headless != nil {
+		return m.headless
+	}
+	return m.tmux
 }
 
 // SessionName returns the tmux session name for the deacon.
@@ -73,6 +97,9 @@ func (m *Manager) deaconDir() string {
 // agentOverride allows specifying an alternate agent alias (e.g., for testing).
 // Restarts are handled by daemon via ensureDeaconRunning on each heartbeat.
 func (m *Manager) Start(agentOverride string) error {
+	if m.headless != nil {
+		return m.startHeadless(agentOverride)
+	}
 	t := m.tmux
 	sessionID := m.SessionName()
 
@@ -105,20 +132,9 @@ func (m *Manager) Start(agentOverride string) error {
 		return fmt.Errorf("ensuring runtime settings: %w", err)
 	}
 
-	initialPrompt := session.BuildStartupPrompt(session.BeaconConfig{
-		Recipient: "deacon",
-		Sender:    "daemon",
-		Topic:     "patrol",
-	}, "I am Deacon. Start patrol: run gt deacon heartbeat, then check gt hook. If no hook, create mol-deacon-patrol wisp and execute it.")
-	startupCmd, err := config.BuildStartupCommandFromConfig(config.AgentEnvConfig{
-		Role:        "deacon",
-		TownRoot:    m.townRoot,
-		Prompt:      initialPrompt,
-		Topic:       "patrol",
-		SessionName: sessionID,
-	}, "", initialPrompt, agentOverride)
+	startupCmd, err := m.startupCommand(agentOverride)
 	if err != nil {
-		return fmt.Errorf("building startup command: %w", err)
+		return err
 	}
 
 	// Create session with command directly to avoid send-keys race condition.
@@ -133,14 +149,7 @@ func (m *Manager) Start(agentOverride string) error {
 	_ = t.SetRemainOnExit(sessionID, true)
 
 	// Set environment variables (non-fatal: session works without these)
-	// Use centralized AgentEnv for consistency across all role startup paths
-	envVars := config.AgentEnv(config.AgentEnvConfig{
-		Role:        "deacon",
-		TownRoot:    m.townRoot,
-		Agent:       agentOverride,
-		SessionName: sessionID,
-	})
-	envVars = session.MergeRuntimeLivenessEnv(envVars, runtimeConfig)
+	envVars := m.sessionEnv(agentOverride, runtimeConfig)
 	for k, v := range envVars {
 		_ = t.SetEnvironment(sessionID, k, v)
 	}
@@ -184,9 +193,74 @@ func (m *Manager) Start(agentOverride string) error {
 	return nil
 }
 
+// startHeadless starts the deacon under the daemon's PTY supervisor. PTY
+// sessions end with their agent, so there are no zombies to reap and no
+// respawn hook: the daemon restarts the deacon on its heartbeat.
+func (m *Manager) startHeadless(agentOverride string) error {
+	sessionID := m.SessionName()
+	if running, _ := m.headless.HasSession(sessionID); running {
+		return ErrAlreadyRunning
+	}
+
+	deaconDir := m.deaconDir()
+	if err := os.MkdirAll(deaconDir, 0755); err != nil {
+		return fmt.Errorf("creating deacon directory: %w", err)
+	}
+	runtimeConfig := config.ResolveRoleAgentConfig("deacon", m.townRoot, deaconDir)
+	if err := runtime.EnsureSettingsForRole(deaconDir, deaconDir, "deacon", runtimeConfig); err != nil {
+		return fmt.Errorf("ensuring runtime settings: %w", err)
+	}
+	startupCmd, err := m.startupCommand(agentOverride)
+	if err != nil {
+		return err
+	}
+	// Wait for the prompt of the agent actually being started.
+	if agentOverride != "" {
+		if rc, _, err := config.ResolveAgentConfigWithOverride(m.townRoot, "", agentOverride); err == nil {
+			runtimeConfig = rc
+		}
+	}
+	if err := session.StartHeadlessAgent(m.headless, sessionID, deaconDir, startupCmd, m.sessionEnv(agentOverride, runtimeConfig), runtimeConfig); err != nil {
+		return fmt.Errorf("starting deacon: %w", err)
+	}
+	return nil
+}
+
+// startupCommand builds the deacon's agent command with its patrol prompt.
+func (m *Manager) startupCommand(agentOverride string) (string, error) {
+	initialPrompt := session.BuildStartupPrompt(session.BeaconConfig{
+		Recipient: "deacon",
+		Sender:    "daemon",
+		Topic:     "patrol",
+	}, "I am Deacon. Start patrol: run gt deacon heartbeat, then check gt hook. If no hook, create mol-deacon-patrol wisp and execute it.")
+	startupCmd, err := config.BuildStartupCommandFromConfig(config.AgentEnvConfig{
+		Role:        "deacon",
+		TownRoot:    m.townRoot,
+		Prompt:      initialPrompt,
+		Topic:       "patrol",
+		SessionName: m.SessionName(),
+	}, "", initialPrompt, agentOverride)
+	if err != nil {
+		return "", fmt.Errorf("building startup command: %w", err)
+	}
+	return startupCmd, nil
+}
+
+// sessionEnv returns the deacon session's environment. Use centralized
+// AgentEnv for consistency across all role startup paths.
+func (m *Manager) sessionEnv(agentOverride string, runtimeConfig *config.RuntimeConfig) map[string]string {
+	envVars := config.AgentEnv(config.AgentEnvConfig{
+		Role:        "deacon",
+		TownRoot:    m.townRoot,
+		Agent:       agentOverride,
+		SessionName: m.SessionName(),
+	})
+	return session.MergeRuntimeLivenessEnv(envVars, runtimeConfig)
+}
+
 // Stop stops the deacon session.
 func (m *Manager) Stop() error {
-	t := m.tmux
+	t := m.sessions()
 	sessionID := m.SessionName()
 
 	// Check if session exists
@@ -206,7 +280,10 @@ func (m *Manager) Stop() error {
 	// Use KillSessionWithProcesses to ensure all descendant processes are killed.
 	// This prevents orphan bash processes from Claude's Bash tool surviving session termination.
 	if err := t.KillSessionWithProcesses(sessionID); err != nil {
-		return fmt.Errorf("killing session: %w", err)
+		// The interrupt alone ends a PTY session whose agent exits on it.
+		if stillRunning, _ := t.HasSession(sessionID); stillRunning {
+			return fmt.Errorf("killing session: %w", err)
+		}
 	}
 
 	return nil
@@ -214,15 +291,14 @@ func (m *Manager) Stop() error {
 
 // IsRunning checks if the deacon session is active.
 func (m *Manager) IsRunning() (bool, error) {
-	return m.tmux.HasSession(m.SessionName())
+	return m.sessions().HasSession(m.SessionName())
 }
 
 // Status returns information about the deacon session.
 func (m *Manager) Status() (*tmux.SessionInfo, error) {
-	t := m.tmux
 	sessionID := m.SessionName()
 
-	running, err := t.HasSession(sessionID)
+	running, err := m.sessions().HasSession(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("checking session: %w", err)
 	}
@@ -230,5 +306,8 @@ func (m *Manager) Status() (*tmux.SessionInfo, error) {
 		return nil, ErrNotRunning
 	}
 
-	return t.GetSessionInfo(sessionID)
+	if m.headless != nil {
+		return session.GetSessionInfo(m.headless, sessionID)
+	}
+	return m.tmux.GetSessionInfo(sessionID)
 }
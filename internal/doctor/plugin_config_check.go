@@ -0,0 +1,89 @@
+package doctor
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/plugin"
+	"github.com/steveyegge/gastown/internal/secrets"
+)
+
+// PluginConfigCheck verifies that every plugin has its required config values
+// set and that set values match their declared types. Plugins that fail this
+// check are not dispatched. No auto-fix — values are set with
+// gt plugin config set.
+type PluginConfigCheck struct {
+	BaseCheck
+}
+
+// NewPluginConfigCheck creates a new plugin config check.
+func NewPluginConfigCheck() *PluginConfigCheck {
+	return &PluginConfigCheck{
+		BaseCheck: BaseCheck{
+			CheckName:        "plugin-config",
+			CheckDescription: "Check plugins have their required config values",
+			CheckCategory:    CategoryConfig,
+		},
+	}
+}
+
+// Run resolves each discovered plugin's config.
+func (c *PluginConfigCheck) Run(ctx *CheckContext) *CheckResult {
+	var rigNames []string
+	if rigsConfig, err := config.LoadRigsConfig(filepath.Join(ctx.TownRoot, "mayor", "rigs.json")); err == nil {
+		for name := range rigsConfig.Rigs {
+			rigNames = append(rigNames, name)
+		}
+		sort.Strings(rigNames)
+	}
+
+	plugins, err := plugin.NewScanner(ctx.TownRoot, rigNames).DiscoverAll()
+	if err != nil {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusWarning,
+			Message: fmt.Sprintf("Could not discover plugins: %v", err),
+		}
+	}
+
+	store := secrets.Open(ctx.TownRoot)
+	var details []string
+	var configured, broken int
+	for _, p := range plugins {
+		if len(p.Config) == 0 {
+			continue
+		}
+		configured++
+		name := plugin.LockKey(p.RigName, p.Name)
+		rc, err := plugin.ResolveConfig(ctx.TownRoot, p, store.Get)
+		if err != nil {
+			broken++
+			details = append(details, fmt.Sprintf("  %s: %v", name, err))
+			continue
+		}
+		problems := rc.Problems()
+		if len(problems) > 0 {
+			broken++
+		}
+		for _, problem := range problems {
+			details = append(details, fmt.Sprintf("  %s: %s", name, problem))
+		}
+	}
+
+	if broken == 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: fmt.Sprintf("%d configurable plugin(s) configured", configured),
+		}
+	}
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusWarning,
+		Message: fmt.Sprintf("%d plugin(s) missing or invalid config (not dispatched)", broken),
+		Details: details,
+		FixHint: "Run 'gt plugin config show <plugin>' and 'gt plugin config set <plugin> <key>'",
+	}
+}
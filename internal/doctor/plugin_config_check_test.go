@@ -0,0 +1,45 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/plugin"
+)
+
+func TestPluginConfigCheck(t *testing.T) {
+	townRoot := t.TempDir()
+	dir := filepath.Join(townRoot, "plugins", "lint")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	md := "+++\nname = \"lint\"\n\n[config.repo]\nrequired = true\n\n[config.threshold]\ntype = \"int\"\n+++\n"
+	if err := os.WriteFile(filepath.Join(dir, "plugin.md"), []byte(md), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	check := NewPluginConfigCheck()
+	ctx := &CheckContext{TownRoot: townRoot}
+	result := check.Run(ctx)
+	if result.Status != StatusWarning || len(result.Details) != 1 {
+		t.Errorf("missing value: Status = %v, Details = %v", result.Status, result.Details)
+	}
+
+	cv, _ := plugin.LoadConfigValues(townRoot, "")
+	cv.Set("lint", "repo", "acme/app")
+	if err := cv.Save(townRoot, ""); err != nil {
+		t.Fatal(err)
+	}
+	if result := check.Run(ctx); result.Status != StatusOK {
+		t.Errorf("configured: Status = %v, Details = %v", result.Status, result.Details)
+	}
+
+	cv.Set("lint", "threshold", "high")
+	if err := cv.Save(townRoot, ""); err != nil {
+		t.Fatal(err)
+	}
+	if result := check.Run(ctx); result.Status != StatusWarning {
+		t.Errorf("invalid value: Status = %v", result.Status)
+	}
+}
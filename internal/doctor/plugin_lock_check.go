@@ -0,0 +1,85 @@
+package doctor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/plugin"
+)
+
+// PluginLockCheck verifies that installed plugins match plugins.lock.json.
+// It detects locked plugins that are missing on this machine and plugins
+// whose contents no longer match their pinned checksum. Can auto-fix by
+// reinstalling from the pinned source.
+type PluginLockCheck struct {
+	FixableCheck
+}
+
+// NewPluginLockCheck creates a new plugin lockfile check.
+func NewPluginLockCheck() *PluginLockCheck {
+	return &PluginLockCheck{
+		FixableCheck: FixableCheck{
+			BaseCheck: BaseCheck{
+				CheckName:        "plugin-lock",
+				CheckDescription: "Check installed plugins match the plugin lockfile",
+				CheckCategory:    CategoryConfig,
+			},
+		},
+	}
+}
+
+// Run compares installed plugins with the lockfile.
+func (c *PluginLockCheck) Run(ctx *CheckContext) *CheckResult {
+	in := &plugin.Installer{TownRoot: ctx.TownRoot}
+	statuses, err := in.Status()
+	if err != nil {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusWarning,
+			Message: fmt.Sprintf("Could not read plugin lockfile: %v", err),
+		}
+	}
+
+	var details []string
+	var missing, modified int
+	for _, st := range statuses {
+		switch st.State {
+		case plugin.LockMissing:
+			missing++
+			details = append(details, fmt.Sprintf("  %s: missing (will install from %s)", st.Key, st.Entry.Source))
+		case plugin.LockModified:
+			modified++
+			details = append(details, fmt.Sprintf("  %s: contents differ from lockfile (will reinstall)", st.Key))
+		}
+	}
+
+	if missing == 0 && modified == 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: fmt.Sprintf("%d locked plugin(s) up-to-date", len(statuses)),
+		}
+	}
+
+	var parts []string
+	if missing > 0 {
+		parts = append(parts, fmt.Sprintf("%d missing", missing))
+	}
+	if modified > 0 {
+		parts = append(parts, fmt.Sprintf("%d modified", modified))
+	}
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusWarning,
+		Message: fmt.Sprintf("Locked plugins: %s", strings.Join(parts, ", ")),
+		Details: details,
+		FixHint: "Run 'gt doctor --fix' or 'gt plugin install' to sync plugins from the lockfile",
+	}
+}
+
+// Fix reinstalls missing and modified plugins from the lockfile.
+func (c *PluginLockCheck) Fix(ctx *CheckContext) error {
+	in := &plugin.Installer{TownRoot: ctx.TownRoot}
+	_, err := in.Sync()
+	return err
+}
@@ -0,0 +1,45 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/plugin"
+)
+
+func TestPluginLockCheck(t *testing.T) {
+	townRoot := t.TempDir()
+	check := NewPluginLockCheck()
+	ctx := &CheckContext{TownRoot: townRoot}
+
+	if result := check.Run(ctx); result.Status != StatusOK {
+		t.Errorf("no lockfile: Status = %v (%s)", result.Status, result.Message)
+	}
+
+	src := filepath.Join(t.TempDir(), "lint")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "plugin.md"), []byte("+++\nname = \"lint\"\n+++\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := plugin.TreeHash(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock := &plugin.Lock{Plugins: map[string]*plugin.LockEntry{
+		"lint": {Name: "lint", Source: src, SourceType: plugin.SourceTarball, SHA256: hash},
+	}}
+	if err := lock.Save(townRoot); err != nil {
+		t.Fatal(err)
+	}
+
+	result := check.Run(ctx)
+	if result.Status != StatusWarning || len(result.Details) != 1 {
+		t.Errorf("missing plugin: Status = %v, Details = %v", result.Status, result.Details)
+	}
+	if !check.CanFix() {
+		t.Error("PluginLockCheck should be fixable")
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/cli"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
@@ -46,6 +48,14 @@ type SessionStartOptions struct {
 
 	// AgentOverride specifies an alternate agent (e.g., "gemini", "claude-haiku").
 	AgentOverride string
+
+	// Env adds environment variables to the session (plugin config values).
+	Env map[string]string
+
+	// SecretEnv adds environment variables that must not appear on the
+	// command line or in the tmux environment (plugin secrets). They are
+	// handed over in a private file the session deletes on startup.
+	SecretEnv map[string]string
 }
 
 // SessionInfo contains information about a running dog session.
@@ -112,6 +122,11 @@ func (m *SessionManager) Start(dogName string, opts SessionStartOptions) error {
 	}
 	instructions := fmt.Sprintf("I am Dog %s.%s Check mail for work: `"+cli.Name()+" mail inbox`. Execute the instructions from your mail. When done, run `"+cli.Name()+" dog done` — this clears your work and auto-terminates the session.", dogName, workInfo)
 
+	envFile, err := m.writeSecretEnv(dogName, opts.SecretEnv)
+	if err != nil {
+		return err
+	}
+
 	// Use unified session lifecycle.
 	theme := tmux.DogTheme()
 	_, err = session.StartSession(m.tmux, session.SessionConfig{
@@ -127,6 +142,8 @@ func (m *SessionManager) Start(dogName string, opts SessionStartOptions) error {
 		},
 		Instructions:   instructions,
 		AgentOverride:  opts.AgentOverride,
+		ExtraEnv:       opts.Env,
+		EnvFile:        envFile,
 		Theme:          &theme,
 		WaitForAgent:   true,
 		WaitFatal:      true,
@@ -136,6 +153,9 @@ func (m *SessionManager) Start(dogName string, opts SessionStartOptions) error {
 		TrackPID:       true,
 	})
 	if err != nil {
+		if envFile != "" {
+			_ = os.Remove(envFile)
+		}
 		return err
 	}
 
@@ -150,6 +170,32 @@ func (m *SessionManager) Start(dogName string, opts SessionStartOptions) error {
 	return nil
 }
 
+// writeSecretEnv writes env as a private shell file of exports for the dog's
+// startup command to source. Returns "" when there is nothing to write.
+func (m *SessionManager) writeSecretEnv(dogName string, env map[string]string) (string, error) {
+	if len(env) == 0 {
+		return "", nil
+	}
+	dir := filepath.Join(m.townRoot, constants.DirRuntime, "secrets")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("creating secrets dir: %w", err)
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "export %s=%s\n", k, config.ShellQuote(env[k]))
+	}
+	path := filepath.Join(dir, "dog-"+dogName+".env")
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		return "", fmt.Errorf("writing secret env: %w", err)
+	}
+	return path, nil
+}
+
 // Stop terminates a dog session.
 func (m *SessionManager) Stop(dogName string, force bool) error {
 	sessionID := m.SessionName(dogName)
@@ -0,0 +1,35 @@
+package dog
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestWriteSecretEnv(t *testing.T) {
+	m := NewSessionManager(nil, t.TempDir(), nil)
+
+	if path, err := m.writeSecretEnv("alpha", nil); path != "" || err != nil {
+		t.Errorf("empty env = %q, %v; want no file", path, err)
+	}
+
+	path, err := m.writeSecretEnv("alpha", map[string]string{"GH_TOKEN": "it's $ecret", "API_KEY": "k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("env file mode = %v, %v", info.Mode(), err)
+	}
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	out, err := exec.Command("sh", "-c", ". "+path+" && printf '%s|%s' \"$GH_TOKEN\" \"$API_KEY\"").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "it's $ecret|k" {
+		t.Errorf("sourced env = %q", out)
+	}
+}
@@ -233,6 +233,16 @@ type Config struct {
 	// Default is "warning" to suppress connection open/close noise. Override with
 	// GT_DOLT_LOGLEVEL=info (or debug) for diagnostics.
 	LogLevel string
+
+	// FailoverPort, when non-zero, is the port of the hot-standby replica the
+	// daemon has promoted after the primary breached its health SLOs.
+	// Clients (HostPort, SQLArgs, connection strings) use it instead of Port;
+	// Port remains the primary's port for lifecycle (start, stop, PID checks).
+	FailoverPort int
+
+	// Cluster, when set, adds a Dolt cluster section to the managed
+	// config.yaml so the server replicates to (or from) its hot standby.
+	Cluster *ClusterConfig
 }
 
 // DefaultConfig returns the default Dolt server configuration.
@@ -317,6 +327,14 @@ func DefaultConfig(townRoot string) *Config {
 		config.LogLevel = "warning"
 	}
 
+	// Follow a daemon failover to the hot-standby replica. The marker records
+	// which primary it replaced, so a stale one from another port is ignored.
+	if townRoot != "" && !config.IsRemote() {
+		if state, err := LoadFailoverState(townRoot); err == nil && state.Active && state.PrimaryPort == config.Port {
+			config.FailoverPort = state.ReplicaPort
+		}
+	}
+
 	return config
 }
 
@@ -331,14 +349,16 @@ func (c *Config) IsRemote() bool {
 }
 
 // SQLArgs returns the dolt CLI flags needed to connect to a remote server.
-// Returns nil for local servers (dolt auto-detects the running local server).
+// Returns nil for local servers (dolt auto-detects the running local server),
+// except after a failover: auto-detection would find the demoted primary, so
+// the promoted replica is addressed explicitly.
 func (c *Config) SQLArgs() []string {
-	if !c.IsRemote() {
+	if !c.IsRemote() && c.FailoverPort == 0 {
 		return nil
 	}
 	return []string{
-		"--host", c.Host,
-		"--port", strconv.Itoa(c.Port),
+		"--host", c.EffectiveHost(),
+		"--port", strconv.Itoa(c.EffectivePort()),
 		"--user", c.User,
 		"--no-tls",
 	}
@@ -360,13 +380,25 @@ func (c *Config) EffectiveHost() string {
 	return c.Host
 }
 
-// HostPort returns "host:port", defaulting host to "127.0.0.1" when empty.
-func (c *Config) HostPort() string {
-	host := c.Host
-	if host == "" {
-		host = "127.0.0.1"
+// EffectivePort returns the port clients should connect to: the promoted
+// replica's after a failover, otherwise Port.
+func (c *Config) EffectivePort() int {
+	if c.FailoverPort > 0 {
+		return c.FailoverPort
 	}
-	return fmt.Sprintf("%s:%d", host, c.Port)
+	return c.Port
+}
+
+// HostPort returns "host:port" for clients, defaulting host to "127.0.0.1"
+// when empty. After a failover this is the promoted replica.
+func (c *Config) HostPort() string {
+	return fmt.Sprintf("%s:%d", c.EffectiveHost(), c.EffectivePort())
+}
+
+// PrimaryHostPort returns "host:port" of the primary server regardless of
+// failover, for lifecycle checks.
+func (c *Config) PrimaryHostPort() string {
+	return fmt.Sprintf("%s:%d", c.EffectiveHost(), c.Port)
 }
 
 // buildDoltSQLCmd constructs a dolt sql command that works for both local and remote servers.
@@ -381,12 +413,16 @@ func buildDoltSQLCmd(ctx context.Context, config *Config, args ...string) *exec.
 
 	cmd := exec.CommandContext(ctx, "dolt", fullArgs...)
 
-	if !config.IsRemote() {
-		cmd.Dir = config.DataDir
-	}
-
-	if config.IsRemote() && config.Password != "" {
+	switch {
+	case config.IsRemote():
+		if config.Password != "" {
+			cmd.Env = append(os.Environ(), "DOLT_CLI_PASSWORD="+config.Password)
+		}
+	case config.FailoverPort > 0:
+		// Explicit --user without DOLT_CLI_PASSWORD prompts for a password.
 		cmd.Env = append(os.Environ(), "DOLT_CLI_PASSWORD="+config.Password)
+	default:
+		cmd.Dir = config.DataDir
 	}
 
 	return cmd
@@ -537,7 +573,7 @@ func IsRunning(townRoot string) (bool, int, error) {
 	// overrides the default port, to avoid false positives from other
 	// services on 3307.
 	if config.Port != DefaultPort {
-		conn, err := net.DialTimeout("tcp", config.HostPort(), 2*time.Second)
+		conn, err := net.DialTimeout("tcp", config.PrimaryHostPort(), 2*time.Second)
 		if err == nil {
 			_ = conn.Close()
 			return true, 0, nil
@@ -553,7 +589,11 @@ func IsRunning(townRoot string) (bool, int, error) {
 // Returns nil if reachable, error describing the problem otherwise.
 func CheckServerReachable(townRoot string) error {
 	config := DefaultConfig(townRoot)
-	addr := config.HostPort()
+	return checkReachable(config, config.HostPort())
+}
+
+// checkReachable dials addr, describing the failure for CheckServerReachable.
+func checkReachable(config *Config, addr string) error {
 	conn, err := net.DialTimeout("tcp", addr, 2*time.Second)
 	if err != nil {
 		hint := ""
@@ -1001,11 +1041,11 @@ func checkPortAvailable(port int) error {
 	return nil
 }
 
-// writeServerConfig writes a managed Dolt config.yaml from the Config struct.
+// WriteServerConfig writes a managed Dolt config.yaml from the Config struct.
 // This ensures all required settings (especially connection timeouts) are always
 // present when the server starts. The file is overwritten on each start to prevent
 // configuration drift.
-func writeServerConfig(config *Config, configPath string) error {
+func WriteServerConfig(config *Config, configPath string) error {
 	// Build the listener host entry. Omit it when empty to use Dolt's default
 	// (binds to all interfaces), which is the backward-compatible behavior.
 	hostLine := ""
@@ -1053,6 +1093,9 @@ behavior:
 		writeTimeoutLine,
 		config.DataDir,
 	)
+	if config.Cluster != nil {
+		content += config.Cluster.yaml()
+	}
 
 	return os.WriteFile(configPath, []byte(content), 0600)
 }
@@ -1222,8 +1265,13 @@ func Start(townRoot string) error {
 	// present, preventing CLOSE_WAIT accumulation from abandoned connections.
 	// The config file uses --config so all settings come from this file; CLI flags
 	// are ignored by dolt when --config is used.
+	// With a hot-standby replica configured, the primary also replicates
+	// every commit to it (see replica.go).
+	if rc := LoadReplicaConfig(townRoot, config.Port); rc != nil {
+		config.Cluster = rc.PrimaryCluster()
+	}
 	configPath := filepath.Join(config.DataDir, "config.yaml")
-	if err := writeServerConfig(config, configPath); err != nil {
+	if err := WriteServerConfig(config, configPath); err != nil {
 		logFile.Close()
 		return fmt.Errorf("writing Dolt config: %w", err)
 	}
@@ -1286,7 +1334,8 @@ func Start(townRoot string) error {
 			return fmt.Errorf("Dolt server failed to start (check logs with 'gt dolt logs')")
 		}
 
-		if err := CheckServerReachable(townRoot); err == nil {
+		// Check the primary itself: after a failover, clients' address is the replica.
+		if err := checkReachable(config, config.PrimaryHostPort()); err == nil {
 			return nil // Server is up and accepting connections
 		} else {
 			lastErr = err
@@ -2646,7 +2695,7 @@ func GetActiveConnectionCount(townRoot string) (int, error) {
 	// BEFORE the "sql" subcommand.
 	fullArgs := []string{
 		"--host", config.EffectiveHost(),
-		"--port", strconv.Itoa(config.Port),
+		"--port", strconv.Itoa(config.EffectivePort()),
 		"--user", config.User,
 		"--no-tls",
 		"sql",
@@ -2927,7 +2976,7 @@ func doltSQLWithRecovery(townRoot, rigDB, query string) error {
 func MeasureQueryLatency(townRoot string) (time.Duration, error) {
 	config := DefaultConfig(townRoot)
 
-	dsn := fmt.Sprintf("%s@tcp(127.0.0.1:%d)/", config.User, config.Port)
+	dsn := fmt.Sprintf("%s@tcp(127.0.0.1:%d)/", config.User, config.EffectivePort())
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
 		return 0, fmt.Errorf("opening mysql connection: %w", err)
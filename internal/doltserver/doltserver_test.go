@@ -3616,7 +3616,7 @@ func TestCollectDatabaseOwners_UnknownDB(t *testing.T) {
 }
 
 // =============================================================================
-// writeServerConfig tests
+// WriteServerConfig tests
 // =============================================================================
 
 func TestWriteServerConfig_Defaults(t *testing.T) {
@@ -3632,8 +3632,8 @@ func TestWriteServerConfig_Defaults(t *testing.T) {
 		LogLevel:       "warning",
 	}
 
-	if err := writeServerConfig(config, configPath); err != nil {
-		t.Fatalf("writeServerConfig: %v", err)
+	if err := WriteServerConfig(config, configPath); err != nil {
+		t.Fatalf("WriteServerConfig: %v", err)
 	}
 
 	data, err := os.ReadFile(configPath)
@@ -3667,7 +3667,7 @@ func TestWriteServerConfig_NoHost(t *testing.T) {
 		DataDir: dir,
 		// Host is empty — should not appear in config
 	}
-	if err := writeServerConfig(config, configPath); err != nil {
+	if err := WriteServerConfig(config, configPath); err != nil {
 		t.Fatal(err)
 	}
 
@@ -3686,7 +3686,7 @@ func TestWriteServerConfig_WithHost(t *testing.T) {
 		Host:    "127.0.0.1",
 		DataDir: dir,
 	}
-	if err := writeServerConfig(config, configPath); err != nil {
+	if err := WriteServerConfig(config, configPath); err != nil {
 		t.Fatal(err)
 	}
 
@@ -3706,7 +3706,7 @@ func TestWriteServerConfig_ZeroTimeoutsOmitted(t *testing.T) {
 		ReadTimeoutMs:  0, // zero = use Dolt default
 		WriteTimeoutMs: 0,
 	}
-	if err := writeServerConfig(config, configPath); err != nil {
+	if err := WriteServerConfig(config, configPath); err != nil {
 		t.Fatal(err)
 	}
 
@@ -3730,13 +3730,13 @@ func TestWriteServerConfig_Overwrites(t *testing.T) {
 	}
 
 	config := &Config{Port: 3307, DataDir: dir, LogLevel: "info"}
-	if err := writeServerConfig(config, configPath); err != nil {
+	if err := WriteServerConfig(config, configPath); err != nil {
 		t.Fatal(err)
 	}
 
 	data, _ := os.ReadFile(configPath)
 	if strings.Contains(string(data), "old content") {
-		t.Error("writeServerConfig should overwrite existing file")
+		t.Error("WriteServerConfig should overwrite existing file")
 	}
 	if !strings.Contains(string(data), "log_level: info") {
 		t.Error("new config should have updated log level")
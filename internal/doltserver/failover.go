@@ -0,0 +1,488 @@
+package doltserver
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// FailoverState is the failover marker at daemon/dolt-failover.json. While it
+// is active, DefaultConfig points clients at the promoted replica, bd calls
+// made through gt do the same (see config.DoltFailoverPort), and each rig's
+// metadata.json names the replica for bare bd calls (see repointMetadata).
+type FailoverState struct {
+	// Active is true while the replica serves as primary.
+	Active bool `json:"active"`
+
+	// PrimaryPort is the SQL port of the primary that was failed away from.
+	PrimaryPort int `json:"primary_port"`
+
+	// ReplicaPort is the SQL port of the promoted replica.
+	ReplicaPort int `json:"replica_port"`
+
+	// Epoch is the cluster epoch the replica was promoted at.
+	Epoch int `json:"epoch"`
+
+	// Reason records why the failover happened (SLO breach, manual).
+	Reason string `json:"reason,omitempty"`
+
+	// Since is when the failover happened.
+	Since time.Time `json:"since"`
+
+	// GracefulStepDown is true when the primary stepped down itself, which
+	// guarantees the replica had every commit before it was promoted.
+	GracefulStepDown bool `json:"graceful_step_down"`
+
+	// UnreplicatedLagMs is the replication lag last seen before a primary
+	// that was down got replaced. Commits in that window are discarded when
+	// the primary rejoins as a standby. Zero after a graceful step-down.
+	UnreplicatedLagMs int64 `json:"unreplicated_lag_ms,omitempty"`
+
+	// ReplicationCheckedAt is when that lag was measured.
+	ReplicationCheckedAt time.Time `json:"replication_checked_at,omitempty"`
+}
+
+// FailoverFile returns the path to the failover marker.
+func FailoverFile(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "dolt-failover.json")
+}
+
+// LoadFailoverState loads the failover marker. A missing file is an inactive
+// state, not an error.
+func LoadFailoverState(townRoot string) (*FailoverState, error) {
+	data, err := os.ReadFile(FailoverFile(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &FailoverState{}, nil
+		}
+		return nil, err
+	}
+	var state FailoverState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SaveFailoverState writes the failover marker using atomic write.
+func SaveFailoverState(townRoot string, state *FailoverState) error {
+	path := FailoverFile(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(path, state)
+}
+
+// ClearFailoverState removes the failover marker.
+func ClearFailoverState(townRoot string) error {
+	if err := os.Remove(FailoverFile(townRoot)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// openServer opens a connection pool to one of the town's servers.
+func openServer(config *Config, port int, timeout time.Duration) (*sql.DB, error) {
+	dsn := fmt.Sprintf("%s@tcp(%s:%d)/?timeout=%s&parseTime=true", config.userDSN(), config.EffectiveHost(), port, timeout)
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening mysql connection: %w", err)
+	}
+	db.SetConnMaxLifetime(timeout)
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// ProbeLatency runs a trivial query against the server on port and returns
+// how long it took. The SLO probe uses it to measure whichever server is
+// currently serving clients.
+func ProbeLatency(townRoot string, port int, timeout time.Duration) (time.Duration, error) {
+	config := DefaultConfig(townRoot)
+	db, err := openServer(config, port, timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	var branch string
+	if err := db.QueryRowContext(ctx, "SELECT active_branch()").Scan(&branch); err != nil {
+		return time.Since(start), fmt.Errorf("SELECT active_branch() failed: %w", err)
+	}
+	return time.Since(start), nil
+}
+
+// ClusterRole returns the cluster role and epoch of the server on port.
+func ClusterRole(townRoot string, port int) (string, int, error) {
+	config := DefaultConfig(townRoot)
+	db, err := openServer(config, port, 5*time.Second)
+	if err != nil {
+		return "", 0, err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var role string
+	var epoch int
+	err = db.QueryRowContext(ctx, "SELECT @@GLOBAL.dolt_cluster_role, @@GLOBAL.dolt_cluster_role_epoch").Scan(&role, &epoch)
+	if err != nil {
+		return "", 0, fmt.Errorf("reading cluster role: %w", err)
+	}
+	return role, epoch, nil
+}
+
+// assumeClusterRole tells the server on port to take role at epoch. Moving a
+// primary to standby is graceful: Dolt blocks writes and waits for the
+// standby to catch up, and fails the call if it cannot.
+func assumeClusterRole(config *Config, port int, role string, epoch int, timeout time.Duration) error {
+	db, err := openServer(config, port, timeout)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if _, err := db.ExecContext(ctx, "CALL dolt_assume_cluster_role(?, ?)", role, epoch); err != nil {
+		return fmt.Errorf("assuming %s role at epoch %d on port %d: %w", role, epoch, port, err)
+	}
+	return nil
+}
+
+// ReplicationStatus is one row of dolt_cluster.dolt_cluster_status.
+type ReplicationStatus struct {
+	Database   string
+	Role       string
+	Epoch      int
+	LagMillis  sql.NullInt64
+	LastUpdate sql.NullTime
+	Error      sql.NullString
+}
+
+// GetReplicationStatus returns the per-database replication status reported
+// by the server on port.
+func GetReplicationStatus(townRoot string, port int) ([]ReplicationStatus, error) {
+	return getReplicationStatus(DefaultConfig(townRoot), port, 5*time.Second)
+}
+
+func getReplicationStatus(config *Config, port int, timeout time.Duration) ([]ReplicationStatus, error) {
+	db, err := openServer(config, port, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, "SELECT `database`, role, epoch, replication_lag_millis, last_update, current_error FROM dolt_cluster.dolt_cluster_status")
+	if err != nil {
+		return nil, fmt.Errorf("reading replication status: %w", err)
+	}
+	defer rows.Close()
+
+	var statuses []ReplicationStatus
+	for rows.Next() {
+		var s ReplicationStatus
+		if err := rows.Scan(&s.Database, &s.Role, &s.Epoch, &s.LagMillis, &s.LastUpdate, &s.Error); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, rows.Err()
+}
+
+// replicationSnapshotMaxAge is how old the last replication check may be
+// for Failover to trust it when the primary is down.
+const replicationSnapshotMaxAge = time.Minute
+
+// ReplicationSnapshot is the last replication check of the primary, kept at
+// daemon/dolt-replication.json. A primary that is down cannot report how
+// far behind the replica is, so Failover falls back to this record.
+type ReplicationSnapshot struct {
+	CheckedAt time.Time `json:"checked_at"`
+
+	// Port is the SQL port of the primary that was checked.
+	Port int `json:"port"`
+
+	// MaxLagMillis is the highest replication lag across databases.
+	MaxLagMillis int64 `json:"max_lag_ms"`
+
+	// Error is set when any database had no known lag or a replication
+	// error; such a snapshot never vouches for a failover.
+	Error string `json:"error,omitempty"`
+}
+
+// ReplicationSnapshotFile returns the path to the last replication check.
+func ReplicationSnapshotFile(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "dolt-replication.json")
+}
+
+// LoadReplicationSnapshot reads the last replication check. Returns nil if
+// none was recorded.
+func LoadReplicationSnapshot(townRoot string) *ReplicationSnapshot {
+	data, err := os.ReadFile(ReplicationSnapshotFile(townRoot))
+	if err != nil {
+		return nil
+	}
+	var snapshot ReplicationSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil
+	}
+	return &snapshot
+}
+
+// CheckReplication reads the replication lag reported by the primary on
+// port and records it for Failover. The daemon calls it on every SLO probe
+// while the primary is healthy.
+func CheckReplication(townRoot string, port int, timeout time.Duration) (*ReplicationSnapshot, error) {
+	statuses, err := getReplicationStatus(DefaultConfig(townRoot), port, timeout)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := summarizeReplication(statuses, port, time.Now())
+	path := ReplicationSnapshotFile(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return snapshot, err
+	}
+	return snapshot, util.AtomicWriteJSON(path, snapshot)
+}
+
+// summarizeReplication reduces the primary's per-database status rows to a
+// snapshot.
+func summarizeReplication(statuses []ReplicationStatus, port int, now time.Time) *ReplicationSnapshot {
+	snapshot := &ReplicationSnapshot{CheckedAt: now, Port: port}
+	replicated := 0
+	for _, s := range statuses {
+		if s.Role != "primary" {
+			continue
+		}
+		replicated++
+		switch {
+		case s.Error.Valid && s.Error.String != "":
+			snapshot.Error = fmt.Sprintf("%s: %s", s.Database, s.Error.String)
+		case !s.LagMillis.Valid:
+			snapshot.Error = fmt.Sprintf("%s: replication lag unknown", s.Database)
+		case s.LagMillis.Int64 > snapshot.MaxLagMillis:
+			snapshot.MaxLagMillis = s.LagMillis.Int64
+		}
+	}
+	if replicated == 0 && snapshot.Error == "" {
+		snapshot.Error = "no databases are replicating"
+	}
+	return snapshot
+}
+
+// caughtUp returns an error unless the snapshot shows that the replica of
+// the primary on port was at most maxLag behind, recently enough to trust.
+func (s *ReplicationSnapshot) caughtUp(port int, maxLag time.Duration, now time.Time) error {
+	switch {
+	case s == nil:
+		return fmt.Errorf("no replication check on record")
+	case s.Port != port:
+		return fmt.Errorf("last replication check was of port %d", s.Port)
+	case s.Error != "":
+		return fmt.Errorf("replication was unhealthy at %s: %s", s.CheckedAt.Format(time.RFC3339), s.Error)
+	case now.Sub(s.CheckedAt) > replicationSnapshotMaxAge:
+		return fmt.Errorf("last replication check is %v old", now.Sub(s.CheckedAt).Round(time.Second))
+	case time.Duration(s.MaxLagMillis)*time.Millisecond > maxLag:
+		return fmt.Errorf("replication lag was %v (max_failover_lag %v)",
+			time.Duration(s.MaxLagMillis)*time.Millisecond, maxLag)
+	}
+	return nil
+}
+
+// Failover promotes the town's replica to primary and writes the failover
+// marker so every client switches to it. The primary is asked to step down
+// first, which drains every commit to the replica. A primary that won't step
+// down is only replaced when it is confirmed down and the last replication
+// check shows the replica within max_failover_lag of it; a primary that is
+// still running could keep taking writes, so Failover refuses instead.
+func Failover(townRoot, reason string) (*FailoverState, error) {
+	config := DefaultConfig(townRoot)
+	if config.IsRemote() {
+		return nil, fmt.Errorf("failover is not supported for a remote Dolt server")
+	}
+	if config.FailoverPort > 0 {
+		return nil, fmt.Errorf("already failed over to the replica on port %d", config.FailoverPort)
+	}
+	rc := LoadReplicaConfig(townRoot, config.Port)
+	if rc == nil {
+		return nil, fmt.Errorf("no replica configured (set patrols.dolt_server.replica.enabled in mayor/daemon.json)")
+	}
+	if running, _ := replicaPID(ReplicaServerConfig(townRoot, rc)); !running {
+		return nil, fmt.Errorf("Dolt replica is not running on port %d", rc.Port)
+	}
+
+	_, epoch, err := ClusterRole(townRoot, rc.Port)
+	if err != nil {
+		return nil, fmt.Errorf("replica: %w", err)
+	}
+
+	state := &FailoverState{
+		Active:      true,
+		PrimaryPort: config.Port,
+		ReplicaPort: rc.Port,
+		Reason:      reason,
+	}
+
+	// A graceful step-down blocks writes on the primary and waits for the
+	// replica to catch up; Dolt fails the call if it cannot.
+	stepErr := assumeClusterRole(config, config.Port, "standby", epoch+1, 10*time.Second)
+	if stepErr == nil {
+		state.GracefulStepDown = true
+	} else {
+		if running, _, _ := IsRunning(townRoot); running || checkReachable(config, config.PrimaryHostPort()) == nil {
+			return nil, fmt.Errorf("primary on port %d did not step down and is still running, so it could keep taking writes; not failing over: %w",
+				config.Port, stepErr)
+		}
+		snapshot := LoadReplicationSnapshot(townRoot)
+		if err := snapshot.caughtUp(config.Port, rc.MaxFailoverLag(), time.Now()); err != nil {
+			return nil, fmt.Errorf("primary on port %d is down, but the replica may be missing its commits (%v); not failing over", config.Port, err)
+		}
+		state.UnreplicatedLagMs = snapshot.MaxLagMillis
+		state.ReplicationCheckedAt = snapshot.CheckedAt
+	}
+
+	epoch += 2
+	if err := assumeClusterRole(config, rc.Port, "primary", epoch, 10*time.Second); err != nil {
+		return nil, err
+	}
+
+	state.Epoch = epoch
+	state.Since = time.Now()
+	if err := SaveFailoverState(townRoot, state); err != nil {
+		return nil, fmt.Errorf("writing failover marker: %w", err)
+	}
+	if err := repointMetadata(townRoot, state); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: pointing bd at the replica: %v\n", err)
+	}
+	return state, nil
+}
+
+// DemoteFormerPrimary makes sure the primary that was failed away from is a
+// standby, so it replicates from the promoted replica instead of accepting
+// writes of its own. It is a no-op when no failover is active or the primary
+// has already stepped down.
+func DemoteFormerPrimary(townRoot string) error {
+	state, err := LoadFailoverState(townRoot)
+	if err != nil || !state.Active {
+		return err
+	}
+	config := DefaultConfig(townRoot)
+	role, epoch, err := ClusterRole(townRoot, state.PrimaryPort)
+	if err != nil {
+		return err
+	}
+	if role == "standby" && epoch >= state.Epoch {
+		return nil
+	}
+	return assumeClusterRole(config, state.PrimaryPort, "standby", state.Epoch, 10*time.Second)
+}
+
+// Failback hands the primary role back from the replica once the original
+// primary has caught up, and clears the failover marker. The replica steps
+// down gracefully, so no committed write is lost.
+func Failback(townRoot string) (int, error) {
+	state, err := LoadFailoverState(townRoot)
+	if err != nil {
+		return 0, err
+	}
+	if !state.Active {
+		return 0, fmt.Errorf("no failover is active")
+	}
+	config := DefaultConfig(townRoot)
+
+	if err := DemoteFormerPrimary(townRoot); err != nil {
+		return 0, fmt.Errorf("primary on port %d is not ready: %w", state.PrimaryPort, err)
+	}
+
+	// Each step takes a fresh epoch: the graceful hand-off replicates the
+	// replica's epoch to the primary along with its data.
+	if err := assumeClusterRole(config, state.ReplicaPort, "standby", state.Epoch+1, 30*time.Second); err != nil {
+		return 0, err
+	}
+	epoch := state.Epoch + 2
+	if err := assumeClusterRole(config, state.PrimaryPort, "primary", epoch, 10*time.Second); err != nil {
+		return 0, err
+	}
+	if err := ClearFailoverState(townRoot); err != nil {
+		return 0, fmt.Errorf("clearing failover marker: %w", err)
+	}
+	state.Active = false
+	if err := repointMetadata(townRoot, state); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: pointing bd back at the primary: %v\n", err)
+	}
+	return epoch, nil
+}
+
+// repointMetadata makes bd follow a failover or failback. bd reads its
+// server port from the rig's metadata.json on every call, so rewriting
+// dolt_server_port moves running sessions along without a restart. While
+// state is active every server-mode rig names the replica; once it is not,
+// rigs naming the replica get the primary back, and the default port is
+// left implicit again so tracked metadata.json files return to their
+// original content.
+func repointMetadata(townRoot string, state *FailoverState) error {
+	var errs []error
+	for _, rigName := range HasServerModeMetadata(townRoot) {
+		path := filepath.Join(FindRigBeadsDir(townRoot, rigName), "metadata.json")
+		if err := repointMetadataFile(path, state); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", rigName, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func repointMetadataFile(path string, state *FailoverState) error {
+	mu := getMetadataMu(path)
+	mu.Lock()
+	defer mu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	metadata := make(map[string]interface{})
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	// Rigs on another server are not part of this failover.
+	if host, _ := metadata["dolt_server_host"].(string); (&Config{Host: host}).IsRemote() {
+		return nil
+	}
+
+	port, _ := metadata["dolt_server_port"].(float64)
+	switch {
+	case state.Active:
+		if int(port) == state.ReplicaPort {
+			return nil
+		}
+		metadata["dolt_server_port"] = state.ReplicaPort
+	case int(port) != state.ReplicaPort:
+		return nil
+	case state.PrimaryPort == DefaultPort:
+		delete(metadata, "dolt_server_port")
+	default:
+		metadata["dolt_server_port"] = state.PrimaryPort
+	}
+
+	data, err = json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling metadata: %w", err)
+	}
+	return util.AtomicWriteFile(path, append(data, '\n'), 0600)
+}
@@ -0,0 +1,314 @@
+package doltserver
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func writeDaemonJSON(t *testing.T, townRoot, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "daemon.json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadReplicaConfig(t *testing.T) {
+	townRoot := t.TempDir()
+
+	if rc := LoadReplicaConfig(townRoot, 3307); rc != nil {
+		t.Errorf("no daemon.json: got %+v, want nil", rc)
+	}
+
+	writeDaemonJSON(t, townRoot, `{"patrols": {"dolt_server": {"replica": {"enabled": false, "port": 4000}}}}`)
+	if rc := LoadReplicaConfig(townRoot, 3307); rc != nil {
+		t.Errorf("disabled replica: got %+v, want nil", rc)
+	}
+
+	writeDaemonJSON(t, townRoot, `{"patrols": {"dolt_server": {"replica": {"enabled": true}}}}`)
+	rc := LoadReplicaConfig(townRoot, 3307)
+	if rc == nil {
+		t.Fatal("enabled replica: got nil")
+	}
+	if rc.Port != 3308 {
+		t.Errorf("Port = %d, want 3308 (primary + 1)", rc.Port)
+	}
+	if rc.DataDir != filepath.Join(townRoot, ".dolt-replica") {
+		t.Errorf("DataDir = %q", rc.DataDir)
+	}
+	if rc.RemotesAPIPort != DefaultReplicaRemotesAPIPort || rc.PrimaryRemotesAPIPort != DefaultPrimaryRemotesAPIPort {
+		t.Errorf("remotesapi ports = %d/%d", rc.RemotesAPIPort, rc.PrimaryRemotesAPIPort)
+	}
+
+	writeDaemonJSON(t, townRoot, `{"patrols": {"dolt_server": {"replica": {"enabled": true, "port": 4000, "remotesapi_port": 6000}}}}`)
+	rc = LoadReplicaConfig(townRoot, 3307)
+	if rc.Port != 4000 || rc.RemotesAPIPort != 6000 {
+		t.Errorf("overrides not kept: %+v", rc)
+	}
+}
+
+func TestWriteServerConfig_Cluster(t *testing.T) {
+	dir := t.TempDir()
+	rc := &ReplicaConfig{Enabled: true}
+	rc.ApplyDefaults(dir, 3307)
+
+	path := filepath.Join(dir, "config.yaml")
+	cfg := &Config{Port: 3308, DataDir: dir, LogLevel: "warning", Cluster: rc.StandbyCluster()}
+	if err := WriteServerConfig(cfg, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{
+		"cluster:",
+		"remote_url_template: http://127.0.0.1:50051/{database}",
+		"bootstrap_role: standby",
+		"bootstrap_epoch: 1",
+		"remotesapi:\n    port: 50052",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("config.yaml missing %q:\n%s", want, content)
+		}
+	}
+
+	cfg.Cluster = nil
+	if err := WriteServerConfig(cfg, path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "cluster:") {
+		t.Errorf("config.yaml without a replica has a cluster section:\n%s", data)
+	}
+}
+
+func TestDefaultConfig_FollowsFailover(t *testing.T) {
+	townRoot := t.TempDir()
+	t.Setenv("GT_DOLT_HOST", "")
+	t.Setenv("GT_DOLT_PORT", "")
+
+	cfg := DefaultConfig(townRoot)
+	if cfg.FailoverPort != 0 || cfg.SQLArgs() != nil {
+		t.Fatalf("no failover: FailoverPort=%d SQLArgs=%v", cfg.FailoverPort, cfg.SQLArgs())
+	}
+
+	state := &FailoverState{Active: true, PrimaryPort: DefaultPort, ReplicaPort: 3308, Epoch: 3, Since: time.Now()}
+	if err := SaveFailoverState(townRoot, state); err != nil {
+		t.Fatal(err)
+	}
+	cfg = DefaultConfig(townRoot)
+	if got := cfg.HostPort(); got != "127.0.0.1:3308" {
+		t.Errorf("HostPort() = %q, want the replica", got)
+	}
+	if got := cfg.PrimaryHostPort(); got != "127.0.0.1:3307" {
+		t.Errorf("PrimaryHostPort() = %q, want the primary", got)
+	}
+	if args := cfg.SQLArgs(); !slices.Contains(args, "3308") {
+		t.Errorf("SQLArgs() = %v, want explicit replica port", args)
+	}
+	cmd := buildDoltSQLCmd(t.Context(), cfg, "-q", "SELECT 1")
+	if cmd.Dir != "" {
+		t.Errorf("cmd.Dir = %q; a data dir would auto-detect the primary", cmd.Dir)
+	}
+	if !slices.Contains(cmd.Env, "DOLT_CLI_PASSWORD=") {
+		t.Error("cmd.Env missing DOLT_CLI_PASSWORD; dolt would prompt for a password")
+	}
+
+	// A marker for a different primary (e.g. left over from a port change) is ignored.
+	state.PrimaryPort = 4000
+	if err := SaveFailoverState(townRoot, state); err != nil {
+		t.Fatal(err)
+	}
+	if cfg := DefaultConfig(townRoot); cfg.FailoverPort != 0 {
+		t.Errorf("stale marker followed: FailoverPort = %d", cfg.FailoverPort)
+	}
+
+	if err := ClearFailoverState(townRoot); err != nil {
+		t.Fatal(err)
+	}
+	if cfg := DefaultConfig(townRoot); cfg.FailoverPort != 0 {
+		t.Errorf("cleared marker followed: FailoverPort = %d", cfg.FailoverPort)
+	}
+}
+
+func TestFailoverState_SharedWithConfig(t *testing.T) {
+	townRoot := t.TempDir()
+	if FailoverFile(townRoot) != config.DoltFailoverPath(townRoot) {
+		t.Fatalf("FailoverFile = %q, config.DoltFailoverPath = %q", FailoverFile(townRoot), config.DoltFailoverPath(townRoot))
+	}
+	state := &FailoverState{Active: true, PrimaryPort: 3307, ReplicaPort: 3308, Epoch: 2, Reason: "manual", Since: time.Now()}
+	if err := SaveFailoverState(townRoot, state); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadFailoverState(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Active || got.ReplicaPort != 3308 || got.Epoch != 2 || got.Reason != "manual" {
+		t.Errorf("round trip = %+v", got)
+	}
+	if port := config.DoltFailoverPort(townRoot); port != 3308 {
+		t.Errorf("config.DoltFailoverPort = %d, want 3308", port)
+	}
+}
+
+// TestRepointMetadata checks that bare bd calls follow a failover and a
+// failback through the dolt_server_port in each rig's metadata.json.
+func TestRepointMetadata(t *testing.T) {
+	townRoot := t.TempDir()
+	write := func(rel, content string) string {
+		t.Helper()
+		path := filepath.Join(townRoot, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	port := func(path string) any {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var metadata map[string]any
+		if err := json.Unmarshal(data, &metadata); err != nil {
+			t.Fatal(err)
+		}
+		return metadata["dolt_server_port"]
+	}
+
+	write("mayor/rigs.json", `{"rigs": {"gastown": {}, "remote": {}, "embedded": {}}}`)
+	hq := write(".beads/metadata.json", `{"dolt_mode": "server", "dolt_database": "hq"}`)
+	rig := write("gastown/mayor/rig/.beads/metadata.json", `{"dolt_mode": "server", "dolt_server_port": 3307}`)
+	remote := write("remote/.beads/metadata.json", `{"dolt_mode": "server", "dolt_server_host": "db.example.com", "dolt_server_port": 3307}`)
+	embedded := write("embedded/.beads/metadata.json", `{"dolt_mode": "embedded"}`)
+
+	state := &FailoverState{Active: true, PrimaryPort: DefaultPort, ReplicaPort: 3308}
+	if err := repointMetadata(townRoot, state); err != nil {
+		t.Fatalf("repointMetadata(active): %v", err)
+	}
+	for path, want := range map[string]any{hq: 3308.0, rig: 3308.0, remote: 3307.0, embedded: nil} {
+		if got := port(path); got != want {
+			t.Errorf("failed over: %s port = %v, want %v", path, got, want)
+		}
+	}
+
+	state.Active = false
+	if err := repointMetadata(townRoot, state); err != nil {
+		t.Fatalf("repointMetadata(inactive): %v", err)
+	}
+	for path, want := range map[string]any{hq: nil, rig: nil, remote: 3307.0, embedded: nil} {
+		if got := port(path); got != want {
+			t.Errorf("failed back: %s port = %v, want %v", path, got, want)
+		}
+	}
+
+	// A primary on a custom port gets its port back explicitly.
+	state = &FailoverState{Active: true, PrimaryPort: 4000, ReplicaPort: 4001}
+	_ = repointMetadata(townRoot, state)
+	state.Active = false
+	_ = repointMetadata(townRoot, state)
+	if got := port(rig); got != 4000.0 {
+		t.Errorf("custom primary: port = %v, want 4000", got)
+	}
+}
+
+func TestExpectedPorts(t *testing.T) {
+	townRoot := t.TempDir()
+	t.Setenv("GT_DOLT_HOST", "")
+	t.Setenv("GT_DOLT_PORT", "")
+
+	if got := ExpectedPorts(townRoot); !slices.Equal(got, []int{3307}) {
+		t.Errorf("without replica = %v", got)
+	}
+	writeDaemonJSON(t, townRoot, `{"patrols": {"dolt_server": {"replica": {"enabled": true}}}}`)
+	if got := ExpectedPorts(townRoot); !slices.Equal(got, []int{3307, 3308, 50052, 50051}) {
+		t.Errorf("with replica = %v", got)
+	}
+}
+
+func TestSummarizeReplication(t *testing.T) {
+	now := time.Now()
+	lag := func(ms int64) sql.NullInt64 { return sql.NullInt64{Int64: ms, Valid: true} }
+
+	snapshot := summarizeReplication([]ReplicationStatus{
+		{Database: "hq", Role: "primary", LagMillis: lag(0)},
+		{Database: "gastown", Role: "primary", LagMillis: lag(250)},
+	}, 3307, now)
+	if snapshot.Error != "" || snapshot.MaxLagMillis != 250 {
+		t.Errorf("healthy: %+v", snapshot)
+	}
+
+	snapshot = summarizeReplication([]ReplicationStatus{
+		{Database: "hq", Role: "primary", LagMillis: lag(0)},
+		{Database: "gastown", Role: "primary"},
+	}, 3307, now)
+	if !strings.Contains(snapshot.Error, "gastown") {
+		t.Errorf("unknown lag not reported: %+v", snapshot)
+	}
+
+	snapshot = summarizeReplication([]ReplicationStatus{
+		{Database: "hq", Role: "primary", LagMillis: lag(0), Error: sql.NullString{String: "connection refused", Valid: true}},
+	}, 3307, now)
+	if !strings.Contains(snapshot.Error, "connection refused") {
+		t.Errorf("replication error not reported: %+v", snapshot)
+	}
+
+	if snapshot := summarizeReplication(nil, 3307, now); snapshot.Error == "" {
+		t.Error("no replicating databases should not vouch for a failover")
+	}
+}
+
+func TestReplicationSnapshot_CaughtUp(t *testing.T) {
+	now := time.Now()
+	fresh := &ReplicationSnapshot{CheckedAt: now.Add(-10 * time.Second), Port: 3307}
+	if err := fresh.caughtUp(3307, 0, now); err != nil {
+		t.Errorf("zero lag, fresh: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		snapshot *ReplicationSnapshot
+		maxLag   time.Duration
+	}{
+		{"none recorded", nil, time.Minute},
+		{"other port", &ReplicationSnapshot{CheckedAt: now, Port: 4000}, time.Minute},
+		{"unhealthy", &ReplicationSnapshot{CheckedAt: now, Port: 3307, Error: "hq: replication lag unknown"}, time.Minute},
+		{"stale", &ReplicationSnapshot{CheckedAt: now.Add(-2 * time.Minute), Port: 3307}, time.Minute},
+		{"lagging", &ReplicationSnapshot{CheckedAt: now, Port: 3307, MaxLagMillis: 1500}, time.Second},
+	}
+	for _, tt := range tests {
+		if err := tt.snapshot.caughtUp(3307, tt.maxLag, now); err == nil {
+			t.Errorf("%s: caughtUp = nil, want an error", tt.name)
+		}
+	}
+
+	lagging := &ReplicationSnapshot{CheckedAt: now, Port: 3307, MaxLagMillis: 1500}
+	if err := lagging.caughtUp(3307, 2*time.Second, now); err != nil {
+		t.Errorf("lag within max_failover_lag: %v", err)
+	}
+}
+
+func TestReplicaConfig_MaxFailoverLag(t *testing.T) {
+	if got := (&ReplicaConfig{}).MaxFailoverLag(); got != 0 {
+		t.Errorf("default = %v, want 0", got)
+	}
+	if got := (&ReplicaConfig{MaxFailoverLagStr: "5s"}).MaxFailoverLag(); got != 5*time.Second {
+		t.Errorf("5s = %v", got)
+	}
+}
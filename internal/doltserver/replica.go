@@ -0,0 +1,317 @@
+package doltserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Hot-standby replication uses Dolt's direct-to-standby cluster mode: the
+// primary pushes every commit to the standby's remotesapi endpoint, and
+// either side can be told to assume the other role with
+// dolt_assume_cluster_role. Roles are only bootstrapped from config.yaml on
+// the first start; after that each server remembers its role and epoch.
+
+const (
+	// DefaultPrimaryRemotesAPIPort is the remotesapi port on the primary,
+	// which the replica pushes to once it has been promoted.
+	DefaultPrimaryRemotesAPIPort = 50051
+
+	// DefaultReplicaRemotesAPIPort is the remotesapi port on the replica,
+	// which the primary pushes to.
+	DefaultReplicaRemotesAPIPort = 50052
+)
+
+// ReplicaConfig configures the hot-standby Dolt replica. It lives under
+// patrols.dolt_server.replica in mayor/daemon.json.
+type ReplicaConfig struct {
+	// Enabled runs a standby replica next to the primary.
+	Enabled bool `json:"enabled"`
+
+	// Port is the replica's SQL port. Default: primary port + 1.
+	Port int `json:"port,omitempty"`
+
+	// DataDir is the replica's data directory. Default: <town>/.dolt-replica.
+	DataDir string `json:"data_dir,omitempty"`
+
+	// RemotesAPIPort is the port the replica receives replication on.
+	RemotesAPIPort int `json:"remotesapi_port,omitempty"`
+
+	// PrimaryRemotesAPIPort is the port the primary receives replication on
+	// after a failover, when the roles are reversed.
+	PrimaryRemotesAPIPort int `json:"primary_remotesapi_port,omitempty"`
+
+	// MaxFailoverLagStr is how far behind the replica may have been when a
+	// primary that is down gets replaced, as a string (e.g., "5s"). Commits
+	// in that window are lost. Default: "0s" — fail over a down primary only
+	// when the replica was fully caught up.
+	MaxFailoverLagStr string `json:"max_failover_lag,omitempty"`
+}
+
+// MaxFailoverLag returns the parsed max_failover_lag, or 0 when unset or
+// invalid.
+func (rc *ReplicaConfig) MaxFailoverLag() time.Duration {
+	if d, err := time.ParseDuration(rc.MaxFailoverLagStr); err == nil && d > 0 {
+		return d
+	}
+	return 0
+}
+
+// ApplyDefaults fills in unset fields for a town whose primary listens on
+// primaryPort.
+func (rc *ReplicaConfig) ApplyDefaults(townRoot string, primaryPort int) {
+	if rc.Port == 0 {
+		rc.Port = primaryPort + 1
+	}
+	if rc.DataDir == "" {
+		rc.DataDir = filepath.Join(townRoot, ".dolt-replica")
+	}
+	if rc.RemotesAPIPort == 0 {
+		rc.RemotesAPIPort = DefaultReplicaRemotesAPIPort
+	}
+	if rc.PrimaryRemotesAPIPort == 0 {
+		rc.PrimaryRemotesAPIPort = DefaultPrimaryRemotesAPIPort
+	}
+}
+
+// PrimaryCluster returns the cluster section for the primary's config.yaml.
+func (rc *ReplicaConfig) PrimaryCluster() *ClusterConfig {
+	return &ClusterConfig{
+		Role:               "primary",
+		RemotesAPIPort:     rc.PrimaryRemotesAPIPort,
+		PeerRemotesAPIPort: rc.RemotesAPIPort,
+	}
+}
+
+// StandbyCluster returns the cluster section for the replica's config.yaml.
+func (rc *ReplicaConfig) StandbyCluster() *ClusterConfig {
+	return &ClusterConfig{
+		Role:               "standby",
+		RemotesAPIPort:     rc.RemotesAPIPort,
+		PeerRemotesAPIPort: rc.PrimaryRemotesAPIPort,
+	}
+}
+
+// LoadReplicaConfig reads the replica settings from mayor/daemon.json and
+// applies defaults. Returns nil when no replica is enabled.
+// We cannot import the daemon package here (circular: daemon→doltserver),
+// so we parse the minimal JSON structure directly.
+func LoadReplicaConfig(townRoot string, primaryPort int) *ReplicaConfig {
+	if townRoot == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(townRoot, "mayor", "daemon.json"))
+	if err != nil {
+		return nil
+	}
+	var daemonJSON struct {
+		Patrols struct {
+			DoltServer struct {
+				Replica *ReplicaConfig `json:"replica"`
+			} `json:"dolt_server"`
+		} `json:"patrols"`
+	}
+	if err := json.Unmarshal(data, &daemonJSON); err != nil {
+		return nil
+	}
+	rc := daemonJSON.Patrols.DoltServer.Replica
+	if rc == nil || !rc.Enabled {
+		return nil
+	}
+	rc.ApplyDefaults(townRoot, primaryPort)
+	return rc
+}
+
+// ClusterConfig is the cluster section of a Dolt server's config.yaml.
+type ClusterConfig struct {
+	// Role is the bootstrap role, "primary" or "standby".
+	Role string
+
+	// RemotesAPIPort is where this server receives replication.
+	RemotesAPIPort int
+
+	// PeerRemotesAPIPort is where the other server receives replication.
+	PeerRemotesAPIPort int
+}
+
+func (c *ClusterConfig) yaml() string {
+	return fmt.Sprintf(`
+cluster:
+  standby_remotes:
+  - name: peer
+    remote_url_template: http://127.0.0.1:%d/{database}
+  bootstrap_role: %s
+  bootstrap_epoch: 1
+  remotesapi:
+    port: %d
+`, c.PeerRemotesAPIPort, c.Role, c.RemotesAPIPort)
+}
+
+// ReplicaServerConfig returns the server config for the town's replica: the
+// primary's settings with the replica's port, data directory, log and PID
+// files.
+func ReplicaServerConfig(townRoot string, rc *ReplicaConfig) *Config {
+	config := DefaultConfig(townRoot)
+	daemonDir := filepath.Join(townRoot, "daemon")
+	config.Port = rc.Port
+	config.FailoverPort = 0
+	config.DataDir = rc.DataDir
+	config.LogFile = filepath.Join(daemonDir, "dolt-replica.log")
+	config.PidFile = filepath.Join(daemonDir, "dolt-replica.pid")
+	config.Cluster = rc.StandbyCluster()
+	return config
+}
+
+// ExpectedPorts returns every port the town's own Dolt servers listen on:
+// the primary's SQL port and, with a replica enabled, the replica's SQL port
+// and both remotesapi ports. Zombie scans should not report these.
+func ExpectedPorts(townRoot string) []int {
+	config := DefaultConfig(townRoot)
+	ports := []int{config.Port}
+	if rc := LoadReplicaConfig(townRoot, config.Port); rc != nil {
+		ports = append(ports, rc.Port, rc.RemotesAPIPort, rc.PrimaryRemotesAPIPort)
+	}
+	return ports
+}
+
+// IsReplicaRunning reports whether the town's replica is running, by its PID
+// file and SQL port.
+func IsReplicaRunning(townRoot string) (bool, int) {
+	config := DefaultConfig(townRoot)
+	rc := LoadReplicaConfig(townRoot, config.Port)
+	if rc == nil {
+		return false, 0
+	}
+	return replicaPID(ReplicaServerConfig(townRoot, rc))
+}
+
+func replicaPID(config *Config) (bool, int) {
+	data, err := os.ReadFile(config.PidFile)
+	if err != nil {
+		return false, 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return false, 0
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false, 0
+	}
+	// On Unix, FindProcess always succeeds. Send signal 0 to check if alive.
+	if err := process.Signal(syscall.Signal(0)); err != nil {
+		_ = os.Remove(config.PidFile)
+		return false, 0
+	}
+	if !isDoltServerOnPort(config.Port) {
+		return false, pid
+	}
+	return true, pid
+}
+
+// StartReplica starts the town's hot-standby replica if it is not already
+// running. The replica is an ordinary dolt sql-server in the standby cluster
+// role; the primary populates it as soon as both are up.
+func StartReplica(townRoot string) error {
+	primary := DefaultConfig(townRoot)
+	if primary.IsRemote() {
+		return fmt.Errorf("hot-standby replica is not supported for a remote Dolt server")
+	}
+	rc := LoadReplicaConfig(townRoot, primary.Port)
+	if rc == nil {
+		return fmt.Errorf("no replica configured (set patrols.dolt_server.replica.enabled in mayor/daemon.json)")
+	}
+	config := ReplicaServerConfig(townRoot, rc)
+	if running, pid := replicaPID(config); running || pid > 0 {
+		return nil // already running (or still starting)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(config.LogFile), 0755); err != nil {
+		return fmt.Errorf("creating daemon directory: %w", err)
+	}
+	if err := os.MkdirAll(config.DataDir, 0755); err != nil {
+		return fmt.Errorf("creating replica data directory: %w", err)
+	}
+	if err := checkPortAvailable(config.Port); err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(config.DataDir, "config.yaml")
+	if err := WriteServerConfig(config, configPath); err != nil {
+		return fmt.Errorf("writing replica config: %w", err)
+	}
+
+	logFile, err := os.OpenFile(config.LogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening replica log file: %w", err)
+	}
+	cmd := exec.Command("dolt", "sql-server", "--config", configPath)
+	cmd.Dir = config.DataDir
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.Stdin = nil
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		_ = logFile.Close()
+		return fmt.Errorf("starting Dolt replica: %w", err)
+	}
+	_ = logFile.Close()
+
+	if err := os.WriteFile(config.PidFile, []byte(strconv.Itoa(cmd.Process.Pid)), 0644); err != nil {
+		_ = cmd.Process.Kill()
+		return fmt.Errorf("writing replica PID file: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < 10; attempt++ {
+		time.Sleep(500 * time.Millisecond)
+		if lastErr = checkReachable(config, config.PrimaryHostPort()); lastErr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("Dolt replica started (PID %d) but not accepting connections after 5s: %w\nCheck logs: %s",
+		cmd.Process.Pid, lastErr, config.LogFile)
+}
+
+// StopReplica stops the town's replica. Stopping the replica while it is
+// serving as the promoted primary is refused; fail back first.
+func StopReplica(townRoot string) error {
+	primary := DefaultConfig(townRoot)
+	if primary.FailoverPort > 0 {
+		return fmt.Errorf("replica is serving as primary after a failover; run 'gt dolt failback' first")
+	}
+	rc := LoadReplicaConfig(townRoot, primary.Port)
+	if rc == nil {
+		return fmt.Errorf("no replica configured")
+	}
+	config := ReplicaServerConfig(townRoot, rc)
+	_, pid := replicaPID(config)
+	if pid == 0 {
+		return fmt.Errorf("Dolt replica is not running")
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("finding process: %w", err)
+	}
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		return fmt.Errorf("sending SIGTERM: %w", err)
+	}
+	for i := 0; i < 10; i++ {
+		time.Sleep(500 * time.Millisecond)
+		if err := process.Signal(syscall.Signal(0)); err != nil {
+			break
+		}
+	}
+	if err := process.Signal(syscall.Signal(0)); err == nil {
+		_ = process.Signal(syscall.SIGKILL)
+		time.Sleep(100 * time.Millisecond)
+	}
+	_ = os.Remove(config.PidFile)
+	return nil
+}
@@ -69,11 +69,30 @@ const (
 	TypeMergeFailed  = "merge_failed"
 	TypeMergeSkipped = "merge_skipped"
 
+	// Convoy events
+	TypeConvoyLanded = "convoy_landed" // Convoy closed with all tracked work done
+
 	// Scheduler events
 	TypeSchedulerEnqueue        = "scheduler_enqueue"         // Bead scheduled for deferred dispatch
 	TypeSchedulerDispatch       = "scheduler_dispatch"        // Bead dispatched from scheduler
 	TypeSchedulerDispatchFailed = "scheduler_dispatch_failed" // Bead dispatch failed (requeued)
 	TypeSchedulerCloseRetry     = "scheduler_close_retry"     // Context close needed last-resort attempt
+
+	// Approval gate events
+	TypeApprovalRequested = "approval_requested"
+	TypeApprovalDecided   = "approval_decided"
+
+	// Sandbox events
+	TypeSandboxViolation = "sandbox_violation" // Sandboxed polecat hit a resource limit
+
+	// Resource accounting events (emitted by the daemon's resource sampler)
+	TypeRunawayAgent = "runaway_agent" // Session's process tree stayed over a resource threshold
+
+	// Proxy events (emitted by gt-proxy-server)
+	TypePushRejected = "push_rejected" // Polecat push refused by the rig's push policy
+
+	// Dolt events (emitted by the daemon's Dolt SLO probe)
+	TypeDoltFailover = "dolt_failover" // Hot-standby replica promoted after an SLO breach
 )
 
 // EventsFile is the name of the raw events log.
@@ -94,6 +113,20 @@ func Log(eventType, actor string, payload map[string]interface{}, visibility str
 	return write(event)
 }
 
+// LogAt writes a feed-visible event to the events log of an explicit town.
+// Use it from long-running processes whose working directory isn't in the
+// town, such as gt-proxy-server.
+func LogAt(townRoot, eventType, actor string, payload map[string]interface{}) error {
+	return writeTo(townRoot, Event{
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Source:     "gt",
+		Type:       eventType,
+		Actor:      actor,
+		Payload:    payload,
+		Visibility: VisibilityFeed,
+	})
+}
+
 // LogFeed is a convenience wrapper for feed-visible events.
 func LogFeed(eventType, actor string, payload map[string]interface{}) error {
 	return Log(eventType, actor, payload, VisibilityFeed)
@@ -114,7 +147,11 @@ func write(event Event) error {
 		// Silently ignore - we're not in a Gas Town workspace
 		return nil
 	}
+	return writeTo(townRoot, event)
+}
 
+// writeTo appends an event to a town's events file.
+func writeTo(townRoot string, event Event) error {
 	eventsPath := filepath.Join(townRoot, EventsFile)
 
 	// Marshal event to JSON
@@ -368,3 +405,65 @@ func SchedulerDispatchFailedPayload(beadID, rig, errMsg string) map[string]inter
 		"error": errMsg,
 	}
 }
+
+// ApprovalPayload creates a payload for approval gate events.
+func ApprovalPayload(id, beadID, step, status string) map[string]interface{} {
+	return map[string]interface{}{
+		"approval": id,
+		"bead":     beadID,
+		"step":     step,
+		"status":   status,
+	}
+}
+
+// SandboxViolationPayload creates a payload for sandbox violation events.
+// kind: the limit that was hit ("memory", "pids")
+func SandboxViolationPayload(rig, polecat, kind, detail string, count int) map[string]interface{} {
+	return map[string]interface{}{
+		"rig":     rig,
+		"polecat": polecat,
+		"kind":    kind,
+		"detail":  detail,
+		"count":   count,
+	}
+}
+
+// RunawayAgentPayload creates a payload for runaway agent events.
+// detail: the thresholds exceeded, e.g. "412 processes (limit 256)"
+func RunawayAgentPayload(session string, pid int, detail string) map[string]interface{} {
+	return map[string]interface{}{
+		"session": session,
+		"pid":     pid,
+		"detail":  detail,
+	}
+}
+
+// PushRejectedPayload creates a payload for push rejected events.
+// reason: the policy rule that failed, e.g. "go.mod is a protected path"
+func PushRejectedPayload(rig, polecat, ref, reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"rig":     rig,
+		"polecat": polecat,
+		"ref":     ref,
+		"reason":  reason,
+	}
+}
+
+// DoltFailoverPayload creates a payload for Dolt failover events.
+// reason: the SLO that was breached, e.g. "p95 latency 3.2s > 2s"
+func DoltFailoverPayload(fromPort, toPort, epoch int, reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"from_port": fromPort,
+		"to_port":   toPort,
+		"epoch":     epoch,
+		"reason":    reason,
+	}
+}
+
+// ConvoyLandedPayload creates a payload for convoy landed events.
+func ConvoyLandedPayload(convoyID, title string) map[string]interface{} {
+	return map[string]interface{}{
+		"convoy": convoyID,
+		"title":  title,
+	}
+}
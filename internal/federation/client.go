@@ -0,0 +1,158 @@
+package federation
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// maxResponseBytes caps what a client reads from a peer.
+const maxResponseBytes = 4 << 20 // 4 MiB
+
+// Client talks to one peer town's federation server.
+type Client struct {
+	peer string
+	url  string
+	http *http.Client
+}
+
+// NewClient returns a client for the peer named peer in the federation config
+// of the town at townRoot.
+func NewClient(townRoot, peer string) (*Client, error) {
+	fc, err := config.LoadOrCreateFederationConfig(config.FederationConfigPath(townRoot))
+	if err != nil {
+		return nil, err
+	}
+	p, ok := fc.Peers[peer]
+	if !ok {
+		return nil, fmt.Errorf("unknown peer town %q (add it with: gt federation peer add %s <url> --ca <file>)", peer, peer)
+	}
+	id, err := LoadIdentity(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(id, peer, p)
+}
+
+func newClient(id *Identity, peer string, p *config.FederationPeer) (*Client, error) {
+	ca, err := parsePeerCA(p.CA)
+	if err != nil {
+		return nil, fmt.Errorf("peer %s CA: %w", peer, err)
+	}
+	cert, err := id.clientCert()
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	return &Client{
+		peer: peer,
+		url:  strings.TrimRight(p.URL, "/"),
+		http: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{
+				RootCAs:      roots,
+				Certificates: []tls.Certificate{cert},
+				MinVersion:   tls.VersionTLS13,
+			}},
+		},
+	}, nil
+}
+
+// Peer returns the name of the peer town.
+func (c *Client) Peer() string {
+	return c.peer
+}
+
+// SendMail delivers m to an agent in the peer town and returns the ID the
+// peer assigned.
+func (c *Client) SendMail(ctx context.Context, m *Mail) (string, error) {
+	var receipt MailReceipt
+	if err := c.do(ctx, http.MethodPost, "/v1/mail", m, &receipt); err != nil {
+		return "", err
+	}
+	return receipt.ID, nil
+}
+
+// Convoy fetches a read-only view of one of the peer's convoys.
+func (c *Client) Convoy(ctx context.Context, id string) (*ConvoyView, error) {
+	var v ConvoyView
+	if err := c.do(ctx, http.MethodGet, "/v1/convoys/"+url.PathEscape(id), nil, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Issue fetches a read-only view of an issue this town delegated to the peer.
+func (c *Client) Issue(ctx context.Context, id string) (*IssueView, error) {
+	var v IssueView
+	if err := c.do(ctx, http.MethodGet, "/v1/issues/"+url.PathEscape(id), nil, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Delegate asks the peer to create a child issue for req.Parent.
+func (c *Client) Delegate(ctx context.Context, req *DelegateRequest) (*DelegateResponse, error) {
+	var resp DelegateResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/delegations", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// do sends a JSON request and decodes the JSON response into out.
+// 404s are returned as ErrNotFound and 400s as ErrInvalid, wrapped with the
+// peer's message.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("peer %s: %w", c.peer, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // best-effort close on response body
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return fmt.Errorf("peer %s: reading response: %w", c.peer, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		msg := strings.TrimSpace(string(data))
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return fmt.Errorf("peer %s: %w: %s", c.peer, ErrNotFound, msg)
+		case http.StatusBadRequest:
+			return fmt.Errorf("peer %s: %w: %s", c.peer, ErrInvalid, msg)
+		}
+		return fmt.Errorf("peer %s: %s: %s", c.peer, resp.Status, msg)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("peer %s: parsing response: %w", c.peer, err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Package federation connects towns to each other over mTLS.
+//
+// Each town already has a CA (the one gt-proxy-server uses for polecat
+// certificates). Peering two towns means each records the other's CA
+// certificate and URL in settings/federation.json. A town's federation server
+// then accepts a client certificate only if its CN is "town:<peer>" and it
+// chains to that peer's CA, so one peer cannot speak for another.
+//
+// Peers can:
+//   - deliver mail to local agents, addressed from the sender's side as
+//     town:rig/agent (a connection.Address whose machine is the town);
+//   - read convoys, and the issues they delegated, without modifying them;
+//   - delegate work: the receiving town creates an issue in one of its rigs
+//     and records a beads.Delegation whose parent is the sender's issue.
+//
+// What a town does with those requests is up to its Backend; the server only
+// authenticates the peer and speaks the wire protocol.
+package federation
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/connection"
+)
+
+// ErrNotFound is returned by a Backend (and surfaced by Client) when the
+// requested convoy, issue or rig doesn't exist or isn't visible to the peer.
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalid is returned by a Backend for requests it refuses as malformed,
+// e.g. mail to an unknown agent.
+var ErrInvalid = errors.New("invalid request")
+
+// townNameRe matches names usable as the town part of an address.
+var townNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// reservedPrefixes are "prefix:" forms that mean something else in addresses
+// and bead references, so they are never treated as town names.
+var reservedPrefixes = map[string]bool{
+	"list":     true,
+	"queue":    true,
+	"announce": true,
+	"channel":  true,
+	"external": true,
+	"local":    true,
+	"hop":      true,
+	"http":     true,
+	"https":    true,
+}
+
+// IsAddress reports whether addr names an agent in another town, i.e. has
+// the form town:rig/agent (or town:mayor/ and the like).
+func IsAddress(addr string) bool {
+	town, rest, ok := strings.Cut(addr, ":")
+	if !ok || !ValidTownName(town) || reservedPrefixes[town] {
+		return false
+	}
+	return strings.Contains(rest, "/")
+}
+
+// SplitAddress splits a federated address into the town name and the
+// address within that town ("bartertown:gastown/max" → "bartertown",
+// "gastown/max").
+func SplitAddress(addr string) (town, local string, err error) {
+	if !IsAddress(addr) {
+		return "", "", fmt.Errorf("not a federated address (want town:rig/agent): %q", addr)
+	}
+	a, err := connection.ParseAddress(addr)
+	if err != nil {
+		return "", "", err
+	}
+	_, local, _ = strings.Cut(addr, ":")
+	return a.Machine, local, nil
+}
+
+// SplitRef splits a federated issue or convoy reference ("bartertown:hq-cv-abc")
+// into the town and the ID within it.
+func SplitRef(ref string) (town, id string, err error) {
+	town, id, ok := strings.Cut(ref, ":")
+	if !ok || !ValidTownName(town) || reservedPrefixes[town] || id == "" || strings.Contains(id, "/") {
+		return "", "", fmt.Errorf("not a federated reference (want town:id): %q", ref)
+	}
+	return town, id, nil
+}
+
+// IsRef reports whether ref is a federated issue or convoy reference.
+func IsRef(ref string) bool {
+	_, _, err := SplitRef(ref)
+	return err == nil
+}
+
+// ValidTownName reports whether name can be used as a town in addresses.
+func ValidTownName(name string) bool {
+	return townNameRe.MatchString(name)
+}
+
+// TownName returns the name of the town at townRoot (mayor/town.json).
+func TownName(townRoot string) (string, error) {
+	tc, err := config.LoadTownConfig(filepath.Join(townRoot, "mayor", "town.json"))
+	if err != nil {
+		return "", fmt.Errorf("loading town config: %w", err)
+	}
+	if !ValidTownName(tc.Name) {
+		return "", fmt.Errorf("town name %q cannot be used for federation (letters, digits, '-' and '_' only)", tc.Name)
+	}
+	return tc.Name, nil
+}
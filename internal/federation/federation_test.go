@@ -0,0 +1,50 @@
+package federation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"bartertown:gastown/max", true},
+		{"bartertown:mayor/", true},
+		{"gastown/max", false},
+		{"mayor/", false},
+		{"queue:work/gastown", false},
+		{"list:oncall", false},
+		{"channel:alerts", false},
+		{"bartertown:hq-abc", false}, // a reference, not an address
+		{"bad town:gastown/max", false},
+		{":gastown/max", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsAddress(tt.addr), "IsAddress(%q)", tt.addr)
+	}
+}
+
+func TestSplitAddress(t *testing.T) {
+	town, local, err := SplitAddress("bartertown:gastown/max")
+	require.NoError(t, err)
+	assert.Equal(t, "bartertown", town)
+	assert.Equal(t, "gastown/max", local)
+
+	_, _, err = SplitAddress("gastown/max")
+	assert.Error(t, err)
+}
+
+func TestSplitRef(t *testing.T) {
+	town, id, err := SplitRef("bartertown:hq-cv-abc")
+	require.NoError(t, err)
+	assert.Equal(t, "bartertown", town)
+	assert.Equal(t, "hq-cv-abc", id)
+
+	for _, ref := range []string{"hq-cv-abc", "external:gt:gt-abc", "bartertown:", "bartertown:gastown/max"} {
+		assert.False(t, IsRef(ref), "IsRef(%q)", ref)
+	}
+}
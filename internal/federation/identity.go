@@ -0,0 +1,100 @@
+package federation
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/proxy"
+)
+
+// certTTL is how long the certificates a town issues itself for federation
+// are valid. They are reissued on every process start, so this only bounds
+// the life of a leaked key.
+const certTTL = 7 * 24 * time.Hour
+
+// Identity is a town's federation identity: its name and its CA.
+type Identity struct {
+	Town string
+	CA   *proxy.CA
+}
+
+// CADir returns the directory holding a town's CA, shared with
+// gt-proxy-server (<town>/.runtime/ca).
+func CADir(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "ca")
+}
+
+// LoadIdentity returns the federation identity of the town at townRoot,
+// generating the town CA on first use.
+func LoadIdentity(townRoot string) (*Identity, error) {
+	town, err := TownName(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := proxy.LoadOrGenerateCA(CADir(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading town CA: %w", err)
+	}
+	return &Identity{Town: town, CA: ca}, nil
+}
+
+// clientCert issues the certificate this town presents to its peers.
+func (id *Identity) clientCert() (tls.Certificate, error) {
+	certPEM, keyPEM, err := id.CA.IssueTown(id.Town, certTTL)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("issue town cert: %w", err)
+	}
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// parsePeerCA parses a peer's PEM CA certificate.
+func parsePeerCA(caPEM string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(caPEM))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if !cert.IsCA {
+		return nil, errors.New("certificate is not a CA")
+	}
+	return cert, nil
+}
+
+// ValidatePeerCA checks that caPEM holds a CA certificate, for commands that
+// add peers.
+func ValidatePeerCA(caPEM string) error {
+	_, err := parsePeerCA(caPEM)
+	return err
+}
+
+// peerFromChains returns the town a verified client certificate speaks for,
+// provided the chain ends at that town's own CA.
+func peerFromChains(chains [][]*x509.Certificate, peerCAs map[string]*x509.Certificate) (string, error) {
+	if len(chains) == 0 || len(chains[0]) == 0 {
+		return "", errors.New("no verified client certificate")
+	}
+	chain := chains[0]
+	cn := chain[0].Subject.CommonName
+	town, ok := strings.CutPrefix(cn, proxy.TownCNPrefix)
+	if !ok {
+		return "", fmt.Errorf("certificate %q is not a town certificate", cn)
+	}
+	ca, ok := peerCAs[town]
+	if !ok {
+		return "", fmt.Errorf("town %q is not a peer", town)
+	}
+	if !bytes.Equal(chain[len(chain)-1].Raw, ca.Raw) {
+		return "", fmt.Errorf("certificate for %q is not signed by that town's CA", town)
+	}
+	return town, nil
+}
@@ -0,0 +1,348 @@
+package federation
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/proxy"
+)
+
+// maxRequestBytes caps a peer's request body.
+const maxRequestBytes = 1 << 20 // 1 MiB
+
+// Backend carries out authenticated peer requests against the local town.
+// peer is the requesting town's name, already verified from its certificate.
+type Backend interface {
+	// DeliverMail delivers m to a local agent. m.From is already prefixed
+	// with the peer's town name.
+	DeliverMail(ctx context.Context, peer string, m *Mail) (string, error)
+
+	// Convoy returns a read-only view of a local convoy.
+	Convoy(ctx context.Context, peer, id string) (*ConvoyView, error)
+
+	// Issue returns a read-only view of an issue peer delegated to this town;
+	// other issues should be reported as ErrNotFound.
+	Issue(ctx context.Context, peer, id string) (*IssueView, error)
+
+	// Delegate creates the child issue for a delegation and returns its ID.
+	Delegate(ctx context.Context, peer string, req *DelegateRequest) (string, error)
+}
+
+// Config configures a federation Server.
+type Config struct {
+	// TownRoot is the town the server speaks for.
+	TownRoot string
+
+	// ListenAddr overrides the listen_addr in settings/federation.json.
+	ListenAddr string
+
+	// Logger receives audit and error logs; slog.Default() if nil.
+	Logger *slog.Logger
+}
+
+// Server accepts mTLS requests from peer towns.
+type Server struct {
+	cfg        Config
+	id         *Identity
+	backend    Backend
+	log        *slog.Logger
+	configPath string
+
+	peersMu  sync.Mutex
+	peersMod time.Time
+	peerCAs  map[string]*x509.Certificate
+
+	lnMu sync.Mutex
+	ln   net.Listener
+}
+
+// New creates a Server for the town at cfg.TownRoot.
+func New(cfg Config, backend Backend) (*Server, error) {
+	id, err := LoadIdentity(cfg.TownRoot)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		cfg:        cfg,
+		id:         id,
+		backend:    backend,
+		log:        cfg.Logger,
+		configPath: config.FederationConfigPath(cfg.TownRoot),
+	}
+	if s.log == nil {
+		s.log = slog.Default()
+	}
+	fc, err := config.LoadOrCreateFederationConfig(s.configPath)
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.ListenAddr == "" {
+		s.cfg.ListenAddr = fc.ListenAddr
+	}
+	if s.cfg.ListenAddr == "" {
+		s.cfg.ListenAddr = config.DefaultFederationListenAddr
+	}
+	return s, nil
+}
+
+// Addr returns the address the server is listening on, or nil before Start
+// has bound its listener.
+func (s *Server) Addr() net.Addr {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	if s.ln == nil {
+		return nil
+	}
+	return s.ln.Addr()
+}
+
+// peers returns the CA of each peer town, re-reading settings/federation.json
+// when it changes so peers can be added or removed without a restart.
+// Broken config keeps the last good peer set.
+func (s *Server) peers() map[string]*x509.Certificate {
+	s.peersMu.Lock()
+	defer s.peersMu.Unlock()
+
+	info, err := os.Stat(s.configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			s.peerCAs, s.peersMod = nil, time.Time{}
+		}
+		return s.peerCAs
+	}
+	if s.peerCAs != nil && info.ModTime().Equal(s.peersMod) {
+		return s.peerCAs
+	}
+	fc, err := config.LoadFederationConfig(s.configPath)
+	if err != nil {
+		s.log.Error("federation config unreadable, keeping previous peers", "err", err)
+		return s.peerCAs
+	}
+	cas := make(map[string]*x509.Certificate, len(fc.Peers))
+	for name, p := range fc.Peers {
+		ca, err := parsePeerCA(p.CA)
+		if err != nil {
+			s.log.Warn("ignoring peer with bad CA", "peer", name, "err", err)
+			continue
+		}
+		cas[name] = ca
+	}
+	s.peerCAs, s.peersMod = cas, info.ModTime()
+	return cas
+}
+
+// Start listens and serves until ctx is canceled.
+func (s *Server) Start(ctx context.Context) error {
+	fc, err := config.LoadOrCreateFederationConfig(s.configPath)
+	if err != nil {
+		return err
+	}
+	ips := proxy.ListenSANIPs(s.cfg.ListenAddr)
+	for _, v := range fc.ExtraSANIPs {
+		if ip := net.ParseIP(strings.TrimSpace(v)); ip != nil {
+			ips = append(ips, ip)
+		} else {
+			s.log.Warn("extra_san_ips: invalid IP address — skipping", "entry", v)
+		}
+	}
+	certPEM, keyPEM, err := s.id.CA.IssueServer("gt-federation", ips, fc.ExtraSANHosts, certTTL)
+	if err != nil {
+		return fmt.Errorf("issue server cert: %w", err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("load server cert: %w", err)
+	}
+
+	base := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS13,
+	}
+	tlsCfg := base.Clone()
+	// Build the trusted client CAs per handshake from the current peer list.
+	tlsCfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		pool := x509.NewCertPool()
+		for _, ca := range s.peers() {
+			pool.AddCert(ca)
+		}
+		c := base.Clone()
+		c.ClientCAs = pool
+		return c, nil
+	}
+
+	srv := &http.Server{
+		Handler:      s.handler(),
+		TLSConfig:    tlsCfg,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 2 * time.Minute,
+		IdleTimeout:  2 * time.Minute,
+	}
+
+	ln, err := net.Listen("tcp", s.cfg.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	s.lnMu.Lock()
+	s.ln = ln
+	s.lnMu.Unlock()
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.log.Info("federation: listening", "addr", ln.Addr(), "town", s.id.Town)
+		if err := srv.ServeTLS(ln, "", ""); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutCtx)
+	case err := <-errCh:
+		return err
+	}
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/mail", s.authenticated(s.handleMail))
+	mux.HandleFunc("GET /v1/convoys/{id}", s.authenticated(s.handleConvoy))
+	mux.HandleFunc("GET /v1/issues/{id}", s.authenticated(s.handleIssue))
+	mux.HandleFunc("POST /v1/delegations", s.authenticated(s.handleDelegate))
+	return mux
+}
+
+// authenticated resolves the requesting peer from its client certificate and
+// rejects requests that don't come from a current peer.
+func (s *Server) authenticated(h func(http.ResponseWriter, *http.Request, string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil {
+			http.Error(w, "client certificate required", http.StatusUnauthorized)
+			return
+		}
+		peer, err := peerFromChains(r.TLS.VerifiedChains, s.peers())
+		if err != nil {
+			s.log.Warn("federation request rejected", "remote", r.RemoteAddr, "err", err)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBytes)
+		h(w, r, peer)
+	}
+}
+
+func (s *Server) handleMail(w http.ResponseWriter, r *http.Request, peer string) {
+	var m Mail
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case m.From == "" || m.To == "" || m.Subject == "":
+		http.Error(w, "from, to and subject are required", http.StatusBadRequest)
+		return
+	case strings.Contains(m.From, ":"):
+		http.Error(w, "from must be an address within the sending town", http.StatusBadRequest)
+		return
+	case IsAddress(m.To):
+		http.Error(w, "relaying mail to another town is not allowed", http.StatusBadRequest)
+		return
+	}
+
+	// Sender addresses are relative to the peer; qualify them so replies and
+	// CCs route back through the federation.
+	m.From = peer + ":" + m.From
+	for i, cc := range m.CC {
+		if !IsAddress(cc) {
+			m.CC[i] = peer + ":" + cc
+		}
+	}
+
+	id, err := s.backend.DeliverMail(r.Context(), peer, &m)
+	if err != nil {
+		s.writeError(w, "mail", peer, err)
+		return
+	}
+	s.log.Info("federation mail", "peer", peer, "from", m.From, "to", m.To, "id", id)
+	writeJSON(w, http.StatusAccepted, MailReceipt{ID: id})
+}
+
+func (s *Server) handleConvoy(w http.ResponseWriter, r *http.Request, peer string) {
+	id := r.PathValue("id")
+	v, err := s.backend.Convoy(r.Context(), peer, id)
+	if err != nil {
+		s.writeError(w, "convoy", peer, err)
+		return
+	}
+	v.Town = s.id.Town
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (s *Server) handleIssue(w http.ResponseWriter, r *http.Request, peer string) {
+	id := r.PathValue("id")
+	v, err := s.backend.Issue(r.Context(), peer, id)
+	if err != nil {
+		s.writeError(w, "issue", peer, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (s *Server) handleDelegate(w http.ResponseWriter, r *http.Request, peer string) {
+	var req DelegateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Parent == "" || req.Rig == "" || req.Title == "" || req.DelegatedBy == "" {
+		http.Error(w, "parent, rig, title and delegated_by are required", http.StatusBadRequest)
+		return
+	}
+	if !ValidTownName(req.Rig) {
+		http.Error(w, "invalid rig name", http.StatusBadRequest)
+		return
+	}
+
+	child, err := s.backend.Delegate(r.Context(), peer, &req)
+	if err != nil {
+		s.writeError(w, "delegate", peer, err)
+		return
+	}
+	s.log.Info("federation delegation", "peer", peer, "parent", peer+":"+req.Parent,
+		"rig", req.Rig, "child", child)
+	writeJSON(w, http.StatusCreated, DelegateResponse{Child: child})
+}
+
+// writeError maps backend errors to status codes. Unexpected errors are
+// logged but not echoed to the peer.
+func (s *Server) writeError(w http.ResponseWriter, op, peer string, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		s.log.Error("federation request failed", "op", op, "peer", peer, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
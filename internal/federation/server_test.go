@@ -0,0 +1,274 @@
+package federation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// fakeBackend records what peers asked of a town.
+type fakeBackend struct {
+	mu          sync.Mutex
+	mail        []Mail
+	delegations []DelegateRequest
+	convoys     map[string]*ConvoyView
+	delegated   map[string]string // child ID → delegating peer
+}
+
+func (b *fakeBackend) DeliverMail(_ context.Context, _ string, m *Mail) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if m.To == "nobody/" {
+		return "", fmt.Errorf("%w: no such agent", ErrInvalid)
+	}
+	b.mail = append(b.mail, *m)
+	return fmt.Sprintf("hq-%d", len(b.mail)), nil
+}
+
+func (b *fakeBackend) Convoy(_ context.Context, _, id string) (*ConvoyView, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if v, ok := b.convoys[id]; ok {
+		return v, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (b *fakeBackend) Issue(_ context.Context, peer, id string) (*IssueView, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.delegated[id] != peer {
+		return nil, ErrNotFound
+	}
+	return &IssueView{ID: id, Title: "delegated", Status: "open"}, nil
+}
+
+func (b *fakeBackend) Delegate(_ context.Context, peer string, req *DelegateRequest) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.delegations = append(b.delegations, *req)
+	child := fmt.Sprintf("gt-%d", len(b.delegations))
+	if b.delegated == nil {
+		b.delegated = map[string]string{}
+	}
+	b.delegated[child] = peer
+	return child, nil
+}
+
+// testTown is a town root with a running federation server.
+type testTown struct {
+	name    string
+	root    string
+	backend *fakeBackend
+	url     string
+}
+
+func newTestTown(t *testing.T, name string) *testTown {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "mayor"), 0755))
+	require.NoError(t, config.SaveTownConfig(filepath.Join(root, "mayor", "town.json"),
+		&config.TownConfig{Type: "town", Version: 1, Name: name}))
+
+	tt := &testTown{name: name, root: root, backend: &fakeBackend{}}
+	srv, err := New(Config{TownRoot: root, ListenAddr: "127.0.0.1:0", Logger: slog.New(slog.DiscardHandler)}, tt.backend)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = srv.Start(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	require.Eventually(t, func() bool { return srv.Addr() != nil }, 5*time.Second, 10*time.Millisecond)
+	tt.url = "https://" + srv.Addr().String()
+	return tt
+}
+
+// addPeer records other as a peer of tt, as `gt federation peer add` would.
+func (tt *testTown) addPeer(t *testing.T, other *testTown) {
+	t.Helper()
+	caPEM, err := os.ReadFile(filepath.Join(CADir(other.root), "ca.crt"))
+	require.NoError(t, err)
+	path := config.FederationConfigPath(tt.root)
+	fc, err := config.LoadOrCreateFederationConfig(path)
+	require.NoError(t, err)
+	fc.Peers[other.name] = &config.FederationPeer{URL: other.url, CA: string(caPEM)}
+	require.NoError(t, config.SaveFederationConfig(path, fc))
+}
+
+// peerTowns starts two towns on this machine that peer with each other.
+func peerTowns(t *testing.T) (a, b *testTown) {
+	a = newTestTown(t, "gastown")
+	b = newTestTown(t, "bartertown")
+	a.addPeer(t, b)
+	b.addPeer(t, a)
+	return a, b
+}
+
+func TestFederationMail(t *testing.T) {
+	a, b := peerTowns(t)
+	client, err := NewClient(a.root, "bartertown")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	id, err := client.SendMail(ctx, &Mail{
+		From:    "gastown/max",
+		To:      "rockatansky/witness",
+		Subject: "hello",
+		Body:    "from the other town",
+		CC:      []string{"mayor/"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hq-1", id)
+
+	require.Len(t, b.backend.mail, 1)
+	got := b.backend.mail[0]
+	assert.Equal(t, "gastown:gastown/max", got.From, "sender is qualified with the peer's town")
+	assert.Equal(t, "rockatansky/witness", got.To)
+	assert.Equal(t, []string{"gastown:mayor/"}, got.CC)
+
+	t.Run("relaying to a third town is refused", func(t *testing.T) {
+		_, err := client.SendMail(ctx, &Mail{From: "mayor/", To: "thunderdome:mayor/", Subject: "x"})
+		assert.ErrorIs(t, err, ErrInvalid)
+	})
+
+	t.Run("backend rejection comes back as ErrInvalid", func(t *testing.T) {
+		_, err := client.SendMail(ctx, &Mail{From: "mayor/", To: "nobody/", Subject: "x"})
+		assert.ErrorIs(t, err, ErrInvalid)
+	})
+}
+
+func TestFederationConvoyView(t *testing.T) {
+	a, b := peerTowns(t)
+	b.backend.convoys = map[string]*ConvoyView{
+		"hq-cv-1": {ID: "hq-cv-1", Title: "Fuel run", Status: "open", Total: 1,
+			Tracked: []IssueView{{ID: "bt-1", Title: "Refine", Status: "closed"}}, Completed: 1},
+	}
+	client, err := NewClient(a.root, "bartertown")
+	require.NoError(t, err)
+
+	v, err := client.Convoy(context.Background(), "hq-cv-1")
+	require.NoError(t, err)
+	assert.Equal(t, "bartertown", v.Town)
+	assert.Equal(t, "Fuel run", v.Title)
+	assert.Equal(t, 1, v.Completed)
+
+	_, err = client.Convoy(context.Background(), "hq-cv-404")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFederationDelegation(t *testing.T) {
+	a, b := peerTowns(t)
+	ctx := context.Background()
+	client, err := NewClient(a.root, "bartertown")
+	require.NoError(t, err)
+
+	resp, err := client.Delegate(ctx, &DelegateRequest{
+		Parent:      "gt-parent",
+		Rig:         "refinery",
+		Title:       "Port the pump",
+		DelegatedBy: "gastown/crew/max",
+		Terms:       &beads.DelegationTerms{Portion: "pump", CreditShare: 40},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "gt-1", resp.Child)
+	require.Len(t, b.backend.delegations, 1)
+	assert.Equal(t, 40, b.backend.delegations[0].Terms.CreditShare)
+
+	// The delegating town can follow the child; the child's own town can't
+	// read it back through the federation as if it were a peer's.
+	v, err := client.Issue(ctx, "gt-1")
+	require.NoError(t, err)
+	assert.Equal(t, "open", v.Status)
+
+	reverse, err := NewClient(b.root, "gastown")
+	require.NoError(t, err)
+	_, err = reverse.Issue(ctx, "gt-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	t.Run("missing fields are rejected", func(t *testing.T) {
+		_, err := client.Delegate(ctx, &DelegateRequest{Parent: "gt-parent", Rig: "refinery"})
+		assert.ErrorIs(t, err, ErrInvalid)
+	})
+}
+
+func TestFederationRejectsNonPeers(t *testing.T) {
+	a, b := peerTowns(t)
+	stranger := newTestTown(t, "thunderdome")
+	ctx := context.Background()
+
+	t.Run("town the server doesn't peer with", func(t *testing.T) {
+		stranger.addPeer(t, b) // stranger trusts b, but b doesn't know stranger
+		client, err := NewClient(stranger.root, "bartertown")
+		require.NoError(t, err)
+		_, err = client.SendMail(ctx, &Mail{From: "mayor/", To: "mayor/", Subject: "x"})
+		assert.Error(t, err)
+		assert.Empty(t, b.backend.mail)
+	})
+
+	t.Run("cert from one peer's CA claiming to be another peer", func(t *testing.T) {
+		// b trusts both a and stranger, and stranger mints a cert for a's name.
+		b.addPeer(t, stranger)
+		id, err := LoadIdentity(stranger.root)
+		require.NoError(t, err)
+		id.Town = "gastown"
+		fc, err := config.LoadFederationConfig(config.FederationConfigPath(stranger.root))
+		require.NoError(t, err)
+		client, err := newClient(id, "bartertown", fc.Peers["bartertown"])
+		require.NoError(t, err)
+
+		_, err = client.SendMail(ctx, &Mail{From: "mayor/", To: "mayor/", Subject: "x"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "403")
+		assert.Empty(t, b.backend.mail)
+	})
+
+	t.Run("unknown peer name on the client side", func(t *testing.T) {
+		_, err := NewClient(a.root, "thunderdome")
+		assert.Error(t, err)
+	})
+
+	t.Run("removed peer is refused without a restart", func(t *testing.T) {
+		client, err := NewClient(a.root, "bartertown")
+		require.NoError(t, err)
+		_, err = client.SendMail(ctx, &Mail{From: "mayor/", To: "mayor/", Subject: "before"})
+		require.NoError(t, err)
+
+		path := config.FederationConfigPath(b.root)
+		fc, err := config.LoadFederationConfig(path)
+		require.NoError(t, err)
+		delete(fc.Peers, "gastown")
+		// Make sure the mtime moves even on coarse-grained filesystems.
+		require.NoError(t, config.SaveFederationConfig(path, fc))
+		future := time.Now().Add(2 * time.Second)
+		require.NoError(t, os.Chtimes(path, future, future))
+
+		client, err = NewClient(a.root, "bartertown") // fresh connection
+		require.NoError(t, err)
+		_, err = client.SendMail(ctx, &Mail{From: "mayor/", To: "mayor/", Subject: "after"})
+		assert.Error(t, err)
+		var last Mail
+		if n := len(b.backend.mail); n > 0 {
+			last = b.backend.mail[n-1]
+		}
+		assert.Equal(t, "before", last.Subject)
+		assert.False(t, errors.Is(err, ErrNotFound))
+	})
+}
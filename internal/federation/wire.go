@@ -0,0 +1,71 @@
+package federation
+
+import "github.com/steveyegge/gastown/internal/beads"
+
+// Mail is a message delivered to a peer (POST /v1/mail). From is the sender
+// within its own town; the receiving server prefixes it with the
+// authenticated peer's name, so replies route back across the federation.
+type Mail struct {
+	From     string   `json:"from"`
+	To       string   `json:"to"` // address within the receiving town
+	Subject  string   `json:"subject"`
+	Body     string   `json:"body,omitempty"`
+	Priority string   `json:"priority,omitempty"`
+	Type     string   `json:"type,omitempty"`
+	ThreadID string   `json:"thread_id,omitempty"`
+	ReplyTo  string   `json:"reply_to,omitempty"`
+	CC       []string `json:"cc,omitempty"`
+}
+
+// MailReceipt acknowledges delivered mail.
+type MailReceipt struct {
+	ID string `json:"id"`
+}
+
+// IssueView is the read-only summary of an issue shared with peers.
+type IssueView struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Assignee string `json:"assignee,omitempty"`
+
+	// DelegatedFrom is the peer's parent issue (town:id) for delegated work.
+	DelegatedFrom string `json:"delegated_from,omitempty"`
+}
+
+// ConvoyView is the read-only summary of a convoy shared with peers
+// (GET /v1/convoys/{id}).
+type ConvoyView struct {
+	Town      string      `json:"town"`
+	ID        string      `json:"id"`
+	Title     string      `json:"title"`
+	Status    string      `json:"status"`
+	Tracked   []IssueView `json:"tracked"`
+	Completed int         `json:"completed"`
+	Total     int         `json:"total"`
+}
+
+// DelegateRequest asks a peer to take on part of an issue
+// (POST /v1/delegations).
+type DelegateRequest struct {
+	// Parent is the delegating town's issue ID (without the town prefix).
+	Parent string `json:"parent"`
+
+	// Rig is the receiving town's rig to create the child issue in.
+	Rig string `json:"rig"`
+
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+
+	// DelegatedBy is the delegating agent within its town; DelegatedTo is an
+	// optional agent in the receiving town (defaults to the rig).
+	DelegatedBy string `json:"delegated_by"`
+	DelegatedTo string `json:"delegated_to,omitempty"`
+
+	Terms *beads.DelegationTerms `json:"terms,omitempty"`
+}
+
+// DelegateResponse names the issue the peer created.
+type DelegateResponse struct {
+	Child string `json:"child"`
+}
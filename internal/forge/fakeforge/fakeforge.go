@@ -0,0 +1,295 @@
+// Package fakeforge is an in-process stand-in for a GitHub-style forge,
+// backed by a local bare repository. It serves the subset of the GitHub REST
+// API that forge.New("github") uses, and performs merges with git against
+// the bare repo, so refinery landing can be exercised end to end offline.
+package fakeforge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PullRequest is a PR held by the fake forge.
+type PullRequest struct {
+	Number   int
+	Head     string
+	Base     string
+	Title    string
+	Body     string
+	Open     bool
+	Merged   bool
+	Method   string // Merge method used
+	MergeSHA string // Resulting base tip
+}
+
+// Server is a fake forge. Point a forge.Config of type github at URL with
+// any owner/name repo.
+type Server struct {
+	*httptest.Server
+
+	repo string // bare repository path
+
+	mu  sync.Mutex
+	prs []*PullRequest
+
+	// conclusions maps a head branch to the conclusion of its "ci" check
+	// run; "" means still running. Heads not listed report defaultConclusion.
+	conclusions       map[string]string
+	defaultConclusion string
+}
+
+// New starts a fake forge serving the bare repository at repo. Checks pass
+// by default. Call Close when done.
+func New(repo string) *Server {
+	s := &Server{repo: repo, conclusions: map[string]string{}, defaultConclusion: "success"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/{owner}/{name}/pulls", s.listPulls)
+	mux.HandleFunc("POST /repos/{owner}/{name}/pulls", s.createPull)
+	mux.HandleFunc("GET /repos/{owner}/{name}/pulls/{n}", s.getPull)
+	mux.HandleFunc("PATCH /repos/{owner}/{name}/pulls/{n}", s.updatePull)
+	mux.HandleFunc("PUT /repos/{owner}/{name}/pulls/{n}/merge", s.mergePull)
+	mux.HandleFunc("GET /repos/{owner}/{name}/commits/{sha}/check-runs", s.checkRuns)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// SetConclusion sets the CI conclusion reported for a head branch:
+// "success", "failure", or "" for a check that is still running. An empty
+// head sets the default for all heads.
+func (s *Server) SetConclusion(head, conclusion string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if head == "" {
+		s.defaultConclusion = conclusion
+		return
+	}
+	s.conclusions[head] = conclusion
+}
+
+// PullRequests returns a snapshot of all PRs.
+func (s *Server) PullRequests() []PullRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]PullRequest, len(s.prs))
+	for i, pr := range s.prs {
+		out[i] = *pr
+	}
+	return out
+}
+
+// prJSON renders a PR the way the GitHub API does (the fields forge reads).
+func (s *Server) prJSON(pr *PullRequest) map[string]interface{} {
+	sha, _ := s.git("", "rev-parse", "refs/heads/"+pr.Head)
+	state := "open"
+	if !pr.Open {
+		state = "closed"
+	}
+	return map[string]interface{}{
+		"number":           pr.Number,
+		"html_url":         fmt.Sprintf("%s/pull/%d", s.URL, pr.Number),
+		"state":            state,
+		"title":            pr.Title,
+		"body":             pr.Body,
+		"merged":           pr.Merged,
+		"merge_commit_sha": pr.MergeSHA,
+		"head":             map[string]string{"ref": pr.Head, "sha": sha},
+		"base":             map[string]string{"ref": pr.Base},
+	}
+}
+
+func (s *Server) listPulls(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	_, head, _ := strings.Cut(q.Get("head"), ":")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []map[string]interface{}{}
+	for _, pr := range s.prs {
+		if q.Get("state") == "open" && !pr.Open {
+			continue
+		}
+		if (head != "" && pr.Head != head) || (q.Get("base") != "" && pr.Base != q.Get("base")) {
+			continue
+		}
+		out = append(out, s.prJSON(pr))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) createPull(w http.ResponseWriter, r *http.Request) {
+	var req struct{ Head, Base, Title, Body string }
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := s.git("", "rev-parse", "--verify", "refs/heads/"+req.Head); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "head branch not found: "+req.Head)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr := &PullRequest{Number: len(s.prs) + 1, Head: req.Head, Base: req.Base, Title: req.Title, Body: req.Body, Open: true}
+	s.prs = append(s.prs, pr)
+	writeJSON(w, http.StatusCreated, s.prJSON(pr))
+}
+
+// pull looks up the PR named in the path; the caller holds s.mu.
+func (s *Server) pull(w http.ResponseWriter, r *http.Request) *PullRequest {
+	n, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil || n < 1 || n > len(s.prs) {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return nil
+	}
+	return s.prs[n-1]
+}
+
+func (s *Server) getPull(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pr := s.pull(w, r); pr != nil {
+		writeJSON(w, http.StatusOK, s.prJSON(pr))
+	}
+}
+
+func (s *Server) updatePull(w http.ResponseWriter, r *http.Request) {
+	var req struct{ Title, Body string }
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pr := s.pull(w, r); pr != nil {
+		pr.Title, pr.Body = req.Title, req.Body
+		writeJSON(w, http.StatusOK, s.prJSON(pr))
+	}
+}
+
+func (s *Server) checkRuns(w http.ResponseWriter, r *http.Request) {
+	sha := r.PathValue("sha")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conclusion := s.defaultConclusion
+	for _, pr := range s.prs {
+		if tip, _ := s.git("", "rev-parse", "refs/heads/"+pr.Head); tip == sha {
+			if c, ok := s.conclusions[pr.Head]; ok {
+				conclusion = c
+			}
+			break
+		}
+	}
+	run := map[string]interface{}{"name": "ci", "status": "completed", "conclusion": conclusion}
+	if conclusion == "" {
+		run = map[string]interface{}{"name": "ci", "status": "in_progress", "conclusion": nil}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"total_count": 1, "check_runs": []interface{}{run}})
+}
+
+func (s *Server) mergePull(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		MergeMethod string `json:"merge_method"`
+		SHA         string `json:"sha"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr := s.pull(w, r)
+	if pr == nil {
+		return
+	}
+	if !pr.Open {
+		writeError(w, http.StatusMethodNotAllowed, "Pull Request is not mergeable")
+		return
+	}
+	if tip, _ := s.git("", "rev-parse", "refs/heads/"+pr.Head); req.SHA != "" && tip != req.SHA {
+		writeError(w, http.StatusConflict, "Head branch was modified. Review and try the merge again.")
+		return
+	}
+	if req.MergeMethod == "" {
+		req.MergeMethod = "merge"
+	}
+
+	sha, err := s.merge(pr, req.MergeMethod)
+	if err != nil {
+		writeError(w, http.StatusMethodNotAllowed, err.Error())
+		return
+	}
+	pr.Open, pr.Merged, pr.Method, pr.MergeSHA = false, true, req.MergeMethod, sha
+	writeJSON(w, http.StatusOK, map[string]interface{}{"sha": sha, "merged": true, "message": "Pull Request successfully merged"})
+}
+
+// merge lands pr on its base in a scratch clone and pushes the result back
+// to the bare repo.
+func (s *Server) merge(pr *PullRequest, method string) (string, error) {
+	dir, err := os.MkdirTemp("", "fakeforge-merge-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	steps := [][]string{{"clone", "-q", "-b", pr.Base, s.repo, dir}}
+	switch method {
+	case "merge":
+		steps = append(steps, []string{"merge", "--no-ff", "-m", fmt.Sprintf("Merge pull request #%d from %s", pr.Number, pr.Head), "origin/" + pr.Head})
+	case "squash":
+		steps = append(steps,
+			[]string{"merge", "--squash", "origin/" + pr.Head},
+			[]string{"commit", "-m", fmt.Sprintf("%s (#%d)", pr.Title, pr.Number)})
+	case "rebase":
+		steps = append(steps,
+			[]string{"checkout", "-q", "-B", "fakeforge-rebase", "origin/" + pr.Head},
+			[]string{"rebase", pr.Base},
+			[]string{"checkout", "-q", pr.Base},
+			[]string{"merge", "--ff-only", "fakeforge-rebase"})
+	default:
+		return "", fmt.Errorf("unsupported merge method %q", method)
+	}
+	steps = append(steps, []string{"push", "-q", "origin", pr.Base})
+
+	for i, args := range steps {
+		wd := dir
+		if i == 0 {
+			wd = ""
+		}
+		if _, err := s.git(wd, args...); err != nil {
+			return "", err
+		}
+	}
+	return s.git("", "rev-parse", "refs/heads/"+pr.Base)
+}
+
+// git runs git in dir, or against the bare repo when dir is empty.
+func (s *Server) git(dir string, args ...string) (string, error) {
+	if dir == "" && args[0] != "clone" {
+		args = append([]string{"--git-dir", s.repo}, args...)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=fakeforge", "GIT_AUTHOR_EMAIL=fakeforge@example.invalid",
+		"GIT_COMMITTER_NAME=fakeforge", "GIT_COMMITTER_EMAIL=fakeforge@example.invalid")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"message": msg})
+}
@@ -0,0 +1,295 @@
+// Package forge lands merges through a code forge's pull request API, for
+// rigs whose upstream doesn't accept direct pushes to the target branch.
+//
+// The refinery pushes the verified merge result to a head branch, opens (or
+// updates) a PR against the target, waits for the forge's checks, and merges
+// the PR through the API. GitHub, GitLab and Gitea are supported; the
+// fakeforge subpackage serves the GitHub API over a local bare repo so the
+// whole flow runs offline in tests.
+package forge
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Forge types for Config.Type.
+const (
+	TypeGitHub = "github"
+	TypeGitLab = "gitlab"
+	TypeGitea  = "gitea"
+)
+
+// Merge methods for Config.MergeMethod.
+const (
+	MethodMerge  = "merge"
+	MethodSquash = "squash"
+	MethodRebase = "rebase"
+)
+
+const (
+	defaultCheckTimeout = 30 * time.Minute
+	defaultPollInterval = 15 * time.Second
+)
+
+// Config configures landing through a forge
+// ("merge_queue": {"forge": {...}} in the rig's config.json).
+type Config struct {
+	// Type is the forge API: github, gitlab or gitea.
+	Type string `json:"type"`
+
+	// URL is the API base URL. Defaults to https://api.github.com for
+	// GitHub and https://gitlab.com/api/v4 for GitLab; required for Gitea
+	// (e.g. https://gitea.example.com/api/v1).
+	URL string `json:"url,omitempty"`
+
+	// Repo is the repository as owner/name (GitLab: the project path).
+	Repo string `json:"repo"`
+
+	// TokenEnv names the environment variable holding the API token.
+	// Defaults to GITHUB_TOKEN, GITLAB_TOKEN or GITEA_TOKEN.
+	TokenEnv string `json:"token_env,omitempty"`
+
+	// MergeMethod is how the PR is merged: rebase (default), squash or
+	// merge. The head already holds one squash commit per MR, so rebase
+	// lands the same history a direct push would.
+	MergeMethod string `json:"merge_method,omitempty"`
+
+	// RequiredChecks names checks that must report success, and is strongly
+	// recommended: checks register asynchronously, so without it the
+	// refinery can only wait for MinChecks of them to appear. When empty,
+	// every reported check must pass.
+	RequiredChecks []string `json:"required_checks,omitempty"`
+
+	// MinChecks is how many checks must be reported before a PR without
+	// RequiredChecks can merge. Fewer count as pending. Defaults to 1, so
+	// a PR nothing reports on never merges unchecked.
+	MinChecks int `json:"min_checks,omitempty"`
+
+	// NoChecksGrace lets a PR no check reports on merge after this long
+	// (e.g. "5m"), for repos without CI. Empty waits until CheckTimeout,
+	// which fails the landing. Ignored with RequiredChecks.
+	NoChecksGrace string `json:"no_checks_grace,omitempty"`
+
+	// CheckTimeout bounds the wait for checks (e.g. "30m").
+	CheckTimeout string `json:"check_timeout,omitempty"`
+
+	// PollInterval is how often checks are polled (e.g. "15s").
+	PollInterval string `json:"poll_interval,omitempty"`
+
+	checkTimeout  time.Duration
+	pollInterval  time.Duration
+	noChecksGrace time.Duration
+}
+
+// Validate checks the forge configuration and fills in defaults.
+func (c *Config) Validate() error {
+	switch c.Type {
+	case TypeGitHub:
+		if c.URL == "" {
+			c.URL = "https://api.github.com"
+		}
+	case TypeGitLab:
+		if c.URL == "" {
+			c.URL = "https://gitlab.com/api/v4"
+		}
+	case TypeGitea:
+		if c.URL == "" {
+			return fmt.Errorf("gitea needs url (the API base, e.g. https://gitea.example.com/api/v1)")
+		}
+	default:
+		return fmt.Errorf("unknown forge type %q (want github, gitlab or gitea)", c.Type)
+	}
+	c.URL = strings.TrimRight(c.URL, "/")
+	if owner, name, ok := strings.Cut(c.Repo, "/"); !ok || owner == "" || name == "" {
+		return fmt.Errorf("repo must be owner/name, got %q", c.Repo)
+	}
+	if c.TokenEnv == "" {
+		c.TokenEnv = strings.ToUpper(c.Type) + "_TOKEN"
+	}
+	switch c.MergeMethod {
+	case "":
+		c.MergeMethod = MethodRebase
+	case MethodMerge, MethodSquash, MethodRebase:
+	default:
+		return fmt.Errorf("unknown merge_method %q (want rebase, squash or merge)", c.MergeMethod)
+	}
+
+	var err error
+	if c.checkTimeout, err = parseDuration(c.CheckTimeout, defaultCheckTimeout); err != nil {
+		return fmt.Errorf("check_timeout: %w", err)
+	}
+	if c.pollInterval, err = parseDuration(c.PollInterval, defaultPollInterval); err != nil {
+		return fmt.Errorf("poll_interval: %w", err)
+	}
+	if c.noChecksGrace, err = parseDuration(c.NoChecksGrace, 0); err != nil {
+		return fmt.Errorf("no_checks_grace: %w", err)
+	}
+	switch {
+	case c.MinChecks < 0:
+		return fmt.Errorf("min_checks must not be negative, got %d", c.MinChecks)
+	case c.MinChecks == 0:
+		c.MinChecks = 1
+	}
+	return nil
+}
+
+func parseDuration(s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive, got %v", d)
+	}
+	return d, nil
+}
+
+// PRSpec describes the pull request to open or update.
+type PRSpec struct {
+	Head  string // Branch holding the changes
+	Base  string // Target branch
+	Title string
+	Body  string
+}
+
+// PR is an open pull (or merge) request.
+type PR struct {
+	Number  int    // PR number (GitLab: the MR iid)
+	URL     string // Web URL
+	Head    string
+	Base    string
+	HeadSHA string // Commit the forge sees at the head; merges are pinned to it
+}
+
+// CheckState is the summarized state of a PR's checks.
+type CheckState string
+
+// Check states.
+const (
+	CheckPending CheckState = "pending"
+	CheckSuccess CheckState = "success"
+	CheckFailure CheckState = "failure"
+)
+
+// Check is a single CI check reported on a PR's head commit.
+type Check struct {
+	Name  string
+	State CheckState
+}
+
+// Forge is a code forge's pull request API.
+type Forge interface {
+	// EnsurePR opens a PR from spec.Head into spec.Base, or updates the
+	// title and body of the one already open.
+	EnsurePR(ctx context.Context, spec PRSpec) (*PR, error)
+
+	// Checks lists the checks reported on the PR's head commit.
+	Checks(ctx context.Context, pr *PR) ([]Check, error)
+
+	// Merge merges the PR with the given method, provided its head is still
+	// pr.HeadSHA, and returns the resulting commit on the base branch.
+	Merge(ctx context.Context, pr *PR, method string) (string, error)
+}
+
+// New returns a client for the configured forge. cfg must be validated.
+func New(cfg *Config) (Forge, error) {
+	token := os.Getenv(cfg.TokenEnv)
+	switch cfg.Type {
+	case TypeGitHub:
+		return newGitHub(cfg.URL, cfg.Repo, token), nil
+	case TypeGitLab:
+		return newGitLab(cfg.URL, cfg.Repo, token), nil
+	case TypeGitea:
+		return newGitea(cfg.URL, cfg.Repo, token), nil
+	}
+	return nil, fmt.Errorf("unknown forge type %q", cfg.Type)
+}
+
+// Summarize reduces checks to one state. With required names, each must be
+// reported and pass (missing ones are pending); otherwise all reported
+// checks must pass, and fewer than minChecks reported is pending.
+func Summarize(checks []Check, required []string, minChecks int) CheckState {
+	if len(required) > 0 {
+		byName := make(map[string]CheckState, len(checks))
+		for _, c := range checks {
+			// A check can run more than once; any failure sticks.
+			if byName[c.Name] != CheckFailure {
+				byName[c.Name] = c.State
+			}
+		}
+		checks = checks[:0:0]
+		for _, name := range required {
+			state, ok := byName[name]
+			if !ok {
+				state = CheckPending
+			}
+			checks = append(checks, Check{Name: name, State: state})
+		}
+	} else if len(checks) < minChecks {
+		// Not every check has registered yet.
+		checks = append(checks[:len(checks):len(checks)], Check{State: CheckPending})
+	}
+	state := CheckSuccess
+	for _, c := range checks {
+		switch c.State {
+		case CheckFailure:
+			return CheckFailure
+		case CheckPending:
+			state = CheckPending
+		}
+	}
+	return state
+}
+
+// ErrChecksFailed is returned by WaitForChecks when a check fails.
+type ErrChecksFailed struct {
+	Failed []string
+}
+
+func (e *ErrChecksFailed) Error() string {
+	return "forge checks failed: " + strings.Join(e.Failed, ", ")
+}
+
+// WaitForChecks polls the PR's checks until they pass, one fails, or the
+// configured timeout expires. A PR no check reports on is pending, unless
+// it stays that way for the configured no_checks_grace.
+func WaitForChecks(ctx context.Context, f Forge, pr *PR, cfg *Config) error {
+	ctx, cancel := context.WithTimeout(ctx, cfg.checkTimeout)
+	defer cancel()
+	start := time.Now()
+	for {
+		checks, err := f.Checks(ctx, pr)
+		if err != nil {
+			return err
+		}
+		switch Summarize(checks, cfg.RequiredChecks, cfg.MinChecks) {
+		case CheckSuccess:
+			return nil
+		case CheckPending:
+			if len(checks) == 0 && len(cfg.RequiredChecks) == 0 &&
+				cfg.noChecksGrace > 0 && time.Since(start) >= cfg.noChecksGrace {
+				return nil
+			}
+		case CheckFailure:
+			var failed []string
+			for _, c := range checks {
+				if c.State == CheckFailure {
+					failed = append(failed, c.Name)
+				}
+			}
+			return &ErrChecksFailed{Failed: failed}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for checks on %s: %w", pr.URL, ctx.Err())
+		case <-time.After(cfg.pollInterval):
+		}
+	}
+}
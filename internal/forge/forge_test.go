@@ -0,0 +1,288 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/forge/fakeforge"
+)
+
+func gitRun(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// testRemote creates a bare repo with a main branch and a feature branch
+// one commit ahead, and returns the bare repo path.
+func testRemote(t *testing.T) string {
+	t.Helper()
+	tmp := t.TempDir()
+	bare := filepath.Join(tmp, "origin.git")
+	work := filepath.Join(tmp, "work")
+	gitRun(t, tmp, "init", "-q", "--bare", "--initial-branch=main", bare)
+	gitRun(t, tmp, "clone", "-q", bare, work)
+	gitRun(t, work, "checkout", "-q", "-b", "main")
+	if err := os.WriteFile(filepath.Join(work, "README.md"), []byte("# test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, work, "add", ".")
+	gitRun(t, work, "commit", "-q", "-m", "initial")
+	gitRun(t, work, "checkout", "-q", "-b", "feature")
+	if err := os.WriteFile(filepath.Join(work, "feature.txt"), []byte("feature\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, work, "add", ".")
+	gitRun(t, work, "commit", "-q", "-m", "feat: add feature")
+	gitRun(t, work, "push", "-q", "origin", "main", "feature")
+	return bare
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := &Config{Type: TypeGitHub, Repo: "acme/widgets"}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if c.URL != "https://api.github.com" || c.TokenEnv != "GITHUB_TOKEN" || c.MergeMethod != MethodRebase {
+		t.Errorf("defaults not applied: %+v", c)
+	}
+	if c.checkTimeout != defaultCheckTimeout || c.pollInterval != defaultPollInterval {
+		t.Errorf("duration defaults not applied: %v %v", c.checkTimeout, c.pollInterval)
+	}
+	if c.MinChecks != 1 || c.noChecksGrace != 0 {
+		t.Errorf("unchecked PRs must not merge by default: min_checks %d, grace %v", c.MinChecks, c.noChecksGrace)
+	}
+
+	for _, bad := range []*Config{
+		{Type: "bitbucket", Repo: "a/b"},
+		{Type: TypeGitHub, Repo: "widgets"},
+		{Type: TypeGitea, Repo: "a/b"},
+		{Type: TypeGitHub, Repo: "a/b", MergeMethod: "octopus"},
+		{Type: TypeGitHub, Repo: "a/b", CheckTimeout: "soon"},
+		{Type: TypeGitHub, Repo: "a/b", MinChecks: -1},
+		{Type: TypeGitHub, Repo: "a/b", NoChecksGrace: "0s"},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", bad)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	pass := Check{Name: "ci", State: CheckSuccess}
+	fail := Check{Name: "lint", State: CheckFailure}
+	pending := Check{Name: "e2e", State: CheckPending}
+
+	tests := []struct {
+		name      string
+		checks    []Check
+		required  []string
+		minChecks int
+		want      CheckState
+	}{
+		{"no checks", nil, nil, 1, CheckPending},
+		{"all pass", []Check{pass}, nil, 1, CheckSuccess},
+		{"one pending", []Check{pass, pending}, nil, 1, CheckPending},
+		{"failure wins", []Check{pending, fail}, nil, 1, CheckFailure},
+		{"too few reported", []Check{pass}, nil, 2, CheckPending},
+		{"too few but failed", []Check{fail}, nil, 2, CheckFailure},
+		{"required missing", []Check{pass}, []string{"ci", "e2e"}, 1, CheckPending},
+		{"unrequired failure ignored", []Check{pass, fail}, []string{"ci"}, 1, CheckSuccess},
+	}
+	for _, tt := range tests {
+		if got := Summarize(tt.checks, tt.required, tt.minChecks); got != tt.want {
+			t.Errorf("%s: Summarize = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGitHub_AgainstFakeForge(t *testing.T) {
+	bare := testRemote(t)
+	srv := fakeforge.New(bare)
+	defer srv.Close()
+
+	cfg := &Config{Type: TypeGitHub, URL: srv.URL, Repo: "acme/widgets", PollInterval: "10ms", CheckTimeout: "5s"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	pr, err := f.EnsurePR(ctx, PRSpec{Head: "feature", Base: "main", Title: "first"})
+	if err != nil {
+		t.Fatalf("EnsurePR: %v", err)
+	}
+	again, err := f.EnsurePR(ctx, PRSpec{Head: "feature", Base: "main", Title: "second"})
+	if err != nil || again.Number != pr.Number {
+		t.Fatalf("EnsurePR should update PR #%d, got %+v, %v", pr.Number, again, err)
+	}
+	if pr.HeadSHA != gitRun(t, bare, "rev-parse", "feature") {
+		t.Errorf("HeadSHA = %s, want feature tip", pr.HeadSHA)
+	}
+
+	if err := WaitForChecks(ctx, f, pr, cfg); err != nil {
+		t.Fatalf("WaitForChecks: %v", err)
+	}
+	sha, err := f.Merge(ctx, pr, MethodRebase)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if main := gitRun(t, bare, "rev-parse", "main"); sha != main {
+		t.Errorf("Merge returned %s, main is %s", sha, main)
+	}
+	prs := srv.PullRequests()
+	if len(prs) != 1 || !prs[0].Merged || prs[0].Title != "second" {
+		t.Errorf("fake PRs = %+v", prs)
+	}
+}
+
+func TestWaitForChecks_Failure(t *testing.T) {
+	bare := testRemote(t)
+	srv := fakeforge.New(bare)
+	defer srv.Close()
+	srv.SetConclusion("feature", "failure")
+
+	cfg := &Config{Type: TypeGitHub, URL: srv.URL, Repo: "acme/widgets", PollInterval: "10ms"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	f, _ := New(cfg)
+	pr, err := f.EnsurePR(context.Background(), PRSpec{Head: "feature", Base: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failed *ErrChecksFailed
+	if err := WaitForChecks(context.Background(), f, pr, cfg); !errors.As(err, &failed) || failed.Failed[0] != "ci" {
+		t.Errorf("WaitForChecks = %v, want ci failure", err)
+	}
+
+	srv.SetConclusion("feature", "")
+	cfg.checkTimeout = 50 * time.Millisecond
+	if err := WaitForChecks(context.Background(), f, pr, cfg); err == nil || !strings.Contains(err.Error(), "deadline") {
+		t.Errorf("pending checks: WaitForChecks = %v, want timeout", err)
+	}
+}
+
+// uncheckedForge is a Forge whose PRs never get a check reported.
+type uncheckedForge struct{ Forge }
+
+func (uncheckedForge) Checks(context.Context, *PR) ([]Check, error) { return nil, nil }
+
+func TestWaitForChecks_NoChecksReported(t *testing.T) {
+	cfg := &Config{Type: TypeGitHub, Repo: "acme/widgets", PollInterval: "10ms", CheckTimeout: "100ms"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	pr := &PR{URL: "https://github.com/acme/widgets/pull/1"}
+	if err := WaitForChecks(context.Background(), uncheckedForge{}, pr, cfg); err == nil || !strings.Contains(err.Error(), "deadline") {
+		t.Errorf("WaitForChecks = %v, want timeout: a PR without checks must not merge", err)
+	}
+
+	cfg.NoChecksGrace = "20ms"
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := WaitForChecks(context.Background(), uncheckedForge{}, pr, cfg); err != nil {
+		t.Errorf("WaitForChecks with no_checks_grace = %v, want merge after the grace", err)
+	}
+}
+
+func TestGitLab_Requests(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.EscapedPath())
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mr := map[string]interface{}{"iid": 7, "web_url": "https://gitlab/mr/7", "source_branch": "gt/merge/x", "target_branch": "main", "sha": "abc"}
+		switch {
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/merge_requests"):
+			_ = json.NewEncoder(w).Encode([]interface{}{})
+		case r.Method == "GET":
+			mr["head_pipeline"] = map[string]string{"status": "failed"}
+			_ = json.NewEncoder(w).Encode(mr)
+		case strings.HasSuffix(r.URL.Path, "/merge"):
+			mr["merge_commit_sha"] = "def"
+			_ = json.NewEncoder(w).Encode(mr)
+		default:
+			_ = json.NewEncoder(w).Encode(mr)
+		}
+	}))
+	defer srv.Close()
+
+	f := newGitLab(srv.URL, "group/widgets", "secret")
+	ctx := context.Background()
+	pr, err := f.EnsurePR(ctx, PRSpec{Head: "gt/merge/x", Base: "main", Title: "t"})
+	if err != nil || pr.Number != 7 || pr.HeadSHA != "abc" {
+		t.Fatalf("EnsurePR = %+v, %v", pr, err)
+	}
+	checks, err := f.Checks(ctx, pr)
+	if err != nil || Summarize(checks, nil, 1) != CheckFailure {
+		t.Errorf("Checks = %+v, %v; want failed pipeline", checks, err)
+	}
+	if sha, err := f.Merge(ctx, pr, MethodSquash); err != nil || sha != "def" {
+		t.Errorf("Merge = %q, %v", sha, err)
+	}
+	if calls[1] != "POST /projects/group%2Fwidgets/merge_requests" {
+		t.Errorf("create call = %q, want escaped project path", calls[1])
+	}
+}
+
+func TestGitea_Requests(t *testing.T) {
+	merged := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		pr := map[string]interface{}{
+			"number": 3, "html_url": "https://gitea/pr/3", "merged": merged, "merge_commit_sha": "fed",
+			"head": map[string]string{"ref": "gt/merge/x", "sha": "abc"}, "base": map[string]string{"ref": "main"},
+		}
+		switch {
+		case r.URL.Path == "/repos/acme/widgets/pulls" && r.Method == "GET":
+			_ = json.NewEncoder(w).Encode([]interface{}{pr})
+		case strings.HasSuffix(r.URL.Path, "/status"):
+			_, _ = w.Write([]byte(`{"statuses":[{"context":"ci","status":"success"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/merge"):
+			merged = true
+		default:
+			_ = json.NewEncoder(w).Encode(pr)
+		}
+	}))
+	defer srv.Close()
+
+	f := newGitea(srv.URL, "acme/widgets", "secret")
+	ctx := context.Background()
+	pr, err := f.EnsurePR(ctx, PRSpec{Head: "gt/merge/x", Base: "main"})
+	if err != nil || pr.Number != 3 {
+		t.Fatalf("EnsurePR = %+v, %v; want existing PR #3", pr, err)
+	}
+	if checks, err := f.Checks(ctx, pr); err != nil || Summarize(checks, []string{"ci"}, 1) != CheckSuccess {
+		t.Errorf("Checks = %+v, %v", checks, err)
+	}
+	if sha, err := f.Merge(ctx, pr, MethodRebase); err != nil || sha != "fed" {
+		t.Errorf("Merge = %q, %v", sha, err)
+	}
+}
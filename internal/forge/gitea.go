@@ -0,0 +1,105 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+)
+
+// gitea is the Gitea (and Forgejo) REST API (v1) client.
+type gitea struct {
+	api  *apiClient
+	repo string // owner/name
+}
+
+func newGitea(base, repo, token string) *gitea {
+	auth := ""
+	if token != "" {
+		auth = "token " + token
+	}
+	return &gitea{api: newAPIClient(base, "Authorization", auth), repo: repo}
+}
+
+type giteaPR struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Merged         bool   `json:"merged"`
+	MergeCommitSHA string `json:"merge_commit_sha"`
+}
+
+func (p *giteaPR) toPR() *PR {
+	return &PR{Number: p.Number, URL: p.HTMLURL, Head: p.Head.Ref, Base: p.Base.Ref, HeadSHA: p.Head.SHA}
+}
+
+func (g *gitea) EnsurePR(ctx context.Context, spec PRSpec) (*PR, error) {
+	// Gitea can't filter the PR list by head branch; scan the open ones.
+	var open []giteaPR
+	if err := g.api.do(ctx, "GET", "/repos/"+g.repo+"/pulls?state=open&limit=50", nil, &open); err != nil {
+		return nil, err
+	}
+
+	var pr giteaPR
+	fields := map[string]string{"title": spec.Title, "body": spec.Body}
+	for _, p := range open {
+		if p.Head.Ref == spec.Head && p.Base.Ref == spec.Base {
+			path := fmt.Sprintf("/repos/%s/pulls/%d", g.repo, p.Number)
+			if err := g.api.do(ctx, "PATCH", path, fields, &pr); err != nil {
+				return nil, err
+			}
+			return pr.toPR(), nil
+		}
+	}
+	fields["head"], fields["base"] = spec.Head, spec.Base
+	if err := g.api.do(ctx, "POST", "/repos/"+g.repo+"/pulls", fields, &pr); err != nil {
+		return nil, err
+	}
+	return pr.toPR(), nil
+}
+
+// Checks reports the commit statuses on the head, one per context.
+func (g *gitea) Checks(ctx context.Context, pr *PR) ([]Check, error) {
+	var resp struct {
+		Statuses []struct {
+			Context string `json:"context"`
+			Status  string `json:"status"`
+		} `json:"statuses"`
+	}
+	if err := g.api.do(ctx, "GET", fmt.Sprintf("/repos/%s/commits/%s/status", g.repo, pr.HeadSHA), nil, &resp); err != nil {
+		return nil, err
+	}
+	checks := make([]Check, 0, len(resp.Statuses))
+	for _, s := range resp.Statuses {
+		state := CheckPending
+		switch s.Status {
+		case "success", "warning":
+			state = CheckSuccess
+		case "failure", "error":
+			state = CheckFailure
+		}
+		checks = append(checks, Check{Name: s.Context, State: state})
+	}
+	return checks, nil
+}
+
+func (g *gitea) Merge(ctx context.Context, pr *PR, method string) (string, error) {
+	path := fmt.Sprintf("/repos/%s/pulls/%d", g.repo, pr.Number)
+	req := map[string]string{"Do": method, "head_commit_id": pr.HeadSHA}
+	if err := g.api.do(ctx, "POST", path+"/merge", req, nil); err != nil {
+		return "", err
+	}
+	// The merge endpoint returns no body; read the result back.
+	var merged giteaPR
+	if err := g.api.do(ctx, "GET", path, nil, &merged); err != nil {
+		return "", err
+	}
+	if !merged.Merged {
+		return "", fmt.Errorf("PR #%d was not merged", pr.Number)
+	}
+	return merged.MergeCommitSHA, nil
+}
@@ -0,0 +1,104 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// github is the GitHub REST API (v3) client.
+type github struct {
+	api  *apiClient
+	repo string // owner/name
+}
+
+func newGitHub(base, repo, token string) *github {
+	auth := ""
+	if token != "" {
+		auth = "Bearer " + token
+	}
+	return &github{api: newAPIClient(base, "Authorization", auth), repo: repo}
+}
+
+type githubPR struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+func (p *githubPR) toPR() *PR {
+	return &PR{Number: p.Number, URL: p.HTMLURL, Head: p.Head.Ref, Base: p.Base.Ref, HeadSHA: p.Head.SHA}
+}
+
+func (g *github) EnsurePR(ctx context.Context, spec PRSpec) (*PR, error) {
+	owner, _, _ := strings.Cut(g.repo, "/")
+	q := url.Values{"state": {"open"}, "head": {owner + ":" + spec.Head}, "base": {spec.Base}}
+	var open []githubPR
+	if err := g.api.do(ctx, "GET", "/repos/"+g.repo+"/pulls?"+q.Encode(), nil, &open); err != nil {
+		return nil, err
+	}
+
+	var pr githubPR
+	fields := map[string]string{"title": spec.Title, "body": spec.Body}
+	if len(open) > 0 {
+		path := fmt.Sprintf("/repos/%s/pulls/%d", g.repo, open[0].Number)
+		if err := g.api.do(ctx, "PATCH", path, fields, &pr); err != nil {
+			return nil, err
+		}
+		return pr.toPR(), nil
+	}
+	fields["head"], fields["base"] = spec.Head, spec.Base
+	if err := g.api.do(ctx, "POST", "/repos/"+g.repo+"/pulls", fields, &pr); err != nil {
+		return nil, err
+	}
+	return pr.toPR(), nil
+}
+
+func (g *github) Checks(ctx context.Context, pr *PR) ([]Check, error) {
+	var resp struct {
+		CheckRuns []struct {
+			Name       string `json:"name"`
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	if err := g.api.do(ctx, "GET", fmt.Sprintf("/repos/%s/commits/%s/check-runs", g.repo, pr.HeadSHA), nil, &resp); err != nil {
+		return nil, err
+	}
+	checks := make([]Check, 0, len(resp.CheckRuns))
+	for _, run := range resp.CheckRuns {
+		state := CheckPending
+		if run.Status == "completed" {
+			switch run.Conclusion {
+			case "success", "neutral", "skipped":
+				state = CheckSuccess
+			default:
+				state = CheckFailure
+			}
+		}
+		checks = append(checks, Check{Name: run.Name, State: state})
+	}
+	return checks, nil
+}
+
+func (g *github) Merge(ctx context.Context, pr *PR, method string) (string, error) {
+	var resp struct {
+		SHA    string `json:"sha"`
+		Merged bool   `json:"merged"`
+	}
+	req := map[string]string{"merge_method": method, "sha": pr.HeadSHA}
+	if err := g.api.do(ctx, "PUT", fmt.Sprintf("/repos/%s/pulls/%d/merge", g.repo, pr.Number), req, &resp); err != nil {
+		return "", err
+	}
+	if !resp.Merged {
+		return "", fmt.Errorf("PR #%d was not merged", pr.Number)
+	}
+	return resp.SHA, nil
+}
@@ -0,0 +1,95 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// gitlab is the GitLab REST API (v4) client. GitLab calls PRs merge
+// requests; PR.Number holds the MR's iid.
+type gitlab struct {
+	api     *apiClient
+	project string // URL-escaped project path
+}
+
+func newGitLab(base, repo, token string) *gitlab {
+	return &gitlab{api: newAPIClient(base, "PRIVATE-TOKEN", token), project: url.PathEscape(repo)}
+}
+
+type gitlabMR struct {
+	IID          int    `json:"iid"`
+	WebURL       string `json:"web_url"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	SHA          string `json:"sha"`
+	HeadPipeline *struct {
+		Status string `json:"status"`
+	} `json:"head_pipeline"`
+	MergeCommitSHA  string `json:"merge_commit_sha"`
+	SquashCommitSHA string `json:"squash_commit_sha"`
+}
+
+func (m *gitlabMR) toPR() *PR {
+	return &PR{Number: m.IID, URL: m.WebURL, Head: m.SourceBranch, Base: m.TargetBranch, HeadSHA: m.SHA}
+}
+
+func (g *gitlab) EnsurePR(ctx context.Context, spec PRSpec) (*PR, error) {
+	q := url.Values{"state": {"opened"}, "source_branch": {spec.Head}, "target_branch": {spec.Base}}
+	var open []gitlabMR
+	if err := g.api.do(ctx, "GET", "/projects/"+g.project+"/merge_requests?"+q.Encode(), nil, &open); err != nil {
+		return nil, err
+	}
+
+	var mr gitlabMR
+	fields := map[string]string{"title": spec.Title, "description": spec.Body}
+	if len(open) > 0 {
+		path := fmt.Sprintf("/projects/%s/merge_requests/%d", g.project, open[0].IID)
+		if err := g.api.do(ctx, "PUT", path, fields, &mr); err != nil {
+			return nil, err
+		}
+		return mr.toPR(), nil
+	}
+	fields["source_branch"], fields["target_branch"] = spec.Head, spec.Base
+	if err := g.api.do(ctx, "POST", "/projects/"+g.project+"/merge_requests", fields, &mr); err != nil {
+		return nil, err
+	}
+	return mr.toPR(), nil
+}
+
+// Checks reports the head pipeline as a single check named "pipeline".
+func (g *gitlab) Checks(ctx context.Context, pr *PR) ([]Check, error) {
+	var mr gitlabMR
+	if err := g.api.do(ctx, "GET", fmt.Sprintf("/projects/%s/merge_requests/%d", g.project, pr.Number), nil, &mr); err != nil {
+		return nil, err
+	}
+	if mr.HeadPipeline == nil {
+		return nil, nil
+	}
+	state := CheckPending
+	switch mr.HeadPipeline.Status {
+	case "success", "skipped":
+		state = CheckSuccess
+	case "failed", "canceled":
+		state = CheckFailure
+	}
+	return []Check{{Name: "pipeline", State: state}}, nil
+}
+
+// Merge merges the MR. GitLab has no API-level rebase merge; rebase and
+// merge both use the project's merge method, squash squashes.
+func (g *gitlab) Merge(ctx context.Context, pr *PR, method string) (string, error) {
+	req := map[string]interface{}{"sha": pr.HeadSHA, "squash": method == MethodSquash}
+	var mr gitlabMR
+	if err := g.api.do(ctx, "PUT", fmt.Sprintf("/projects/%s/merge_requests/%d/merge", g.project, pr.Number), req, &mr); err != nil {
+		return "", err
+	}
+	if mr.MergeCommitSHA != "" {
+		return mr.MergeCommitSHA, nil
+	}
+	if mr.SquashCommitSHA != "" {
+		return mr.SquashCommitSHA, nil
+	}
+	// Fast-forward merges leave the head as the new base tip.
+	return mr.SHA, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -131,6 +132,9 @@ func (f *Formula) validateWorkflow() error {
 			return fmt.Errorf("duplicate step id: %s", step.ID)
 		}
 		seen[step.ID] = true
+		if err := step.validateGate(); err != nil {
+			return err
+		}
 	}
 
 	// Validate step needs references
@@ -150,6 +154,34 @@ func (f *Formula) validateWorkflow() error {
 	return nil
 }
 
+// validateGate checks the gate fields of a workflow step.
+func (s *Step) validateGate() error {
+	if s.Gate == "" {
+		if len(s.Approvers) > 0 || s.Timeout != "" || s.OnTimeout != "" {
+			return fmt.Errorf("step %q sets approval fields without gate = %q", s.ID, GateApproval)
+		}
+		return nil
+	}
+	if s.Gate != GateApproval {
+		return fmt.Errorf("step %q has unknown gate %q (want %q)", s.ID, s.Gate, GateApproval)
+	}
+	if s.Timeout != "" {
+		d, err := time.ParseDuration(s.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("step %q has invalid timeout %q", s.ID, s.Timeout)
+		}
+	}
+	switch s.OnTimeout {
+	case "", TimeoutReject, TimeoutApprove, TimeoutEscalate:
+	default:
+		return fmt.Errorf("step %q has invalid on_timeout %q (want reject, approve, or escalate)", s.ID, s.OnTimeout)
+	}
+	if s.OnTimeout != "" && s.Timeout == "" {
+		return fmt.Errorf("step %q sets on_timeout without timeout", s.ID)
+	}
+	return nil
+}
+
 func (f *Formula) validateExpansion() error {
 	if len(f.Template) == 0 {
 		return fmt.Errorf("expansion formula requires at least one template")
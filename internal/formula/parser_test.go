@@ -282,6 +282,57 @@ title = "Step 1 duplicate"
 	}
 }
 
+func TestParse_ApprovalGate(t *testing.T) {
+	data := []byte(`
+formula = "release"
+version = 1
+[[steps]]
+id = "build"
+title = "Build"
+[[steps]]
+id = "signoff"
+title = "Release sign-off"
+needs = ["build"]
+gate = "approval"
+approvers = ["overseer", "mayor/"]
+timeout = "24h"
+on_timeout = "escalate"
+`)
+
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	step := f.GetStep("signoff")
+	if step == nil || !step.IsApprovalGate() {
+		t.Fatalf("signoff step = %+v, want approval gate", step)
+	}
+	if len(step.Approvers) != 2 || step.Timeout != "24h" || step.OnTimeout != TimeoutEscalate {
+		t.Errorf("gate fields = %+v", step)
+	}
+	if f.GetStep("build").IsApprovalGate() {
+		t.Error("build should not be a gate")
+	}
+}
+
+func TestValidate_ApprovalGateErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown gate":        `gate = "vote"`,
+		"bad timeout":         "gate = \"approval\"\ntimeout = \"soon\"",
+		"bad on_timeout":      "gate = \"approval\"\ntimeout = \"1h\"\non_timeout = \"ignore\"",
+		"on_timeout alone":    "gate = \"approval\"\non_timeout = \"approve\"",
+		"approvers sans gate": `approvers = ["overseer"]`,
+	}
+	for name, fields := range tests {
+		t.Run(name, func(t *testing.T) {
+			data := []byte("formula = \"test\"\nversion = 1\n[[steps]]\nid = \"s\"\ntitle = \"S\"\n" + fields + "\n")
+			if _, err := Parse(data); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
 func TestValidate_UnknownDependency(t *testing.T) {
 	data := []byte(`
 formula = "test"
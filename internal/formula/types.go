@@ -100,6 +100,28 @@ type Step struct {
 	Needs       []string `toml:"needs"`
 	Parallel    bool     `toml:"parallel"`   // If true, this step can run concurrently with other parallel steps that share the same needs
 	Acceptance  string   `toml:"acceptance"` // Exit criteria for this step (used by Ralph loop mode)
+
+	// Gate fields. A step with gate = "approval" parks the molecule until a
+	// human approves or rejects it (see gt approve).
+	Gate      string   `toml:"gate"`
+	Approvers []string `toml:"approvers"`  // Mail addresses notified of the request (default: overseer)
+	Timeout   string   `toml:"timeout"`    // Go duration after which the request expires, e.g. "24h"
+	OnTimeout string   `toml:"on_timeout"` // "reject" (default), "approve", or "escalate"
+}
+
+// GateApproval is the gate type for human approval steps.
+const GateApproval = "approval"
+
+// Valid on_timeout actions for approval gates.
+const (
+	TimeoutReject   = "reject"
+	TimeoutApprove  = "approve"
+	TimeoutEscalate = "escalate"
+)
+
+// IsApprovalGate reports whether the step waits for human approval.
+func (s *Step) IsApprovalGate() bool {
+	return s.Gate == GateApproval
 }
 
 // Template represents a template step in an expansion formula.
@@ -716,6 +716,21 @@ func (g *Git) MergeSquash(branch, message string) error {
 	return err
 }
 
+// MergeSquashStaged stages a squash merge of branch without committing.
+// On conflict the working tree is left with the conflicted files for the
+// caller to resolve; callers clean up with ResetHard("HEAD").
+func (g *Git) MergeSquashStaged(branch string) error {
+	_, err := g.runMergeCheck("merge", "--squash", branch)
+	return err
+}
+
+// CheckoutOurs replaces conflicted paths with the current branch's version.
+func (g *Git) CheckoutOurs(paths ...string) error {
+	args := append([]string{"checkout", "--ours", "--"}, paths...)
+	_, err := g.run(args...)
+	return err
+}
+
 // GetBranchCommitMessage returns the commit message of the HEAD commit on the given branch.
 // This is useful for preserving the original conventional commit message (feat:/fix:) when
 // performing squash merges.
@@ -723,6 +738,12 @@ func (g *Git) GetBranchCommitMessage(branch string) (string, error) {
 	return g.run("log", "-1", "--format=%B", branch)
 }
 
+// CommitSubjects returns the subjects of the commits in base..head, oldest
+// first, one per line.
+func (g *Git) CommitSubjects(base, head string) (string, error) {
+	return g.run("log", "--reverse", "--format=%s", base+".."+head)
+}
+
 // RecentCommits returns the last n commits as one-line summaries (hash + subject).
 // Returns empty string if there are no commits or the repo is empty.
 func (g *Git) RecentCommits(n int) (string, error) {
@@ -782,6 +803,13 @@ func (g *Git) Rebase(onto string) error {
 	return err
 }
 
+// RebaseOnto replays the commits of the current branch after upstream onto
+// newBase (git rebase --onto newBase upstream).
+func (g *Git) RebaseOnto(newBase, upstream string) error {
+	_, err := g.run("rebase", "--onto", newBase, upstream)
+	return err
+}
+
 // AbortMerge aborts a merge in progress.
 func (g *Git) AbortMerge() error {
 	_, err := g.run("merge", "--abort")
@@ -1017,6 +1045,19 @@ func (g *Git) Rev(ref string) (string, error) {
 	return g.run("rev-parse", ref)
 }
 
+// MergeBase returns the best common ancestor of two refs.
+func (g *Git) MergeBase(a, b string) (string, error) {
+	return g.run("merge-base", a, b)
+}
+
+// UpdateRef points ref at newValue, but only if it currently points at
+// oldValue. Unlike ResetBranch, this works on branches checked out in
+// another worktree.
+func (g *Git) UpdateRef(ref, newValue, oldValue string) error {
+	_, err := g.run("update-ref", ref, newValue, oldValue)
+	return err
+}
+
 // IsAncestor checks if ancestor is an ancestor of descendant.
 func (g *Git) IsAncestor(ancestor, descendant string) (bool, error) {
 	_, err := g.run("merge-base", "--is-ancestor", ancestor, descendant)
@@ -1689,8 +1730,9 @@ func InitSubmodules(repoPath string) error {
 // SubmoduleChanges detects submodule pointer changes between two refs.
 // Returns nil if no submodules changed or if the repo has no submodules.
 func (g *Git) SubmoduleChanges(base, head string) ([]SubmoduleChange, error) {
-	// git diff --raw shows mode 160000 for gitlink (submodule) entries
-	out, err := g.run("diff", "--raw", base, head)
+	// git diff --raw shows mode 160000 for gitlink (submodule) entries; full
+	// SHAs are needed to push the submodule commits.
+	out, err := g.run("diff", "--raw", "--no-abbrev", base, head)
 	if err != nil {
 		return nil, fmt.Errorf("diffing for submodule changes: %w", err)
 	}
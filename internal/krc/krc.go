@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/recording"
 )
 
 // Config defines TTL settings for ephemeral records.
@@ -63,6 +64,9 @@ func DefaultConfig() *Config {
 			"session_start": 3 * 24 * time.Hour, // 3 days
 			"session_end":   3 * 24 * time.Hour, // 3 days
 
+			// Pane recordings (.runtime/recordings) - large, short-lived
+			RecordingTTLKey: 3 * 24 * time.Hour, // 3 days
+
 			// Operational events - moderate TTL
 			"nudge":    3 * 24 * time.Hour,  // 3 days
 			"handoff":  7 * 24 * time.Hour,  // 7 days
@@ -84,6 +88,10 @@ func DefaultConfig() *Config {
 	}
 }
 
+// RecordingTTLKey is the TTL pattern governing session recordings. It is
+// matched like an event type, so "session_*" also applies.
+const RecordingTTLKey = "session_recording"
+
 // ConfigFile returns the path to the KRC config file.
 func ConfigFile(townRoot string) string {
 	return filepath.Join(townRoot, ".krc.yaml")
@@ -171,6 +179,10 @@ type PruneResult struct {
 	BytesAfter      int64          `json:"bytes_after"`
 	PrunedByType    map[string]int `json:"pruned_by_type"`
 	Duration        time.Duration  `json:"duration"`
+
+	// RecordingsPruned counts session recordings removed. Their size is
+	// included in BytesBefore/BytesAfter.
+	RecordingsPruned int `json:"recordings_pruned,omitempty"`
 }
 
 // Pruner handles the pruning of expired events.
@@ -223,6 +235,18 @@ func (p *Pruner) Prune() (*PruneResult, error) {
 		result.PrunedByType[k] += v
 	}
 
+	// Prune session recordings by file age. A failure here shouldn't
+	// discard the event pruning already done, so warn and report what
+	// was removed.
+	recResult, err := recording.Prune(p.townRoot, p.config.GetTTL(RecordingTTLKey), time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: pruning recordings: %v\n", err)
+	}
+	if recResult != nil {
+		result.RecordingsPruned = recResult.Removed
+		result.BytesBefore += recResult.BytesFreed
+	}
+
 	result.Duration = time.Since(start)
 	return result, nil
 }
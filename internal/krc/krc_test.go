@@ -215,6 +215,39 @@ func TestPruner_Prune(t *testing.T) {
 	}
 }
 
+func TestPruner_PruneRecordings(t *testing.T) {
+	tmpDir := t.TempDir()
+	dir := filepath.Join(tmpDir, ".runtime", "recordings", "gastown", "Toast")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	oldPath := filepath.Join(dir, "20260301T000000Z.cast")
+	newPath := filepath.Join(dir, "20260310T000000Z.cast")
+	for _, p := range []string{oldPath, newPath} {
+		if err := os.WriteFile(p, []byte("{\"version\":2}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-4 * 24 * time.Hour)
+	if err := os.Chtimes(oldPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewPruner(tmpDir, DefaultConfig()).Prune()
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if result.RecordingsPruned != 1 {
+		t.Errorf("expected 1 recording pruned, got %d", result.RecordingsPruned)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("expired recording should be removed")
+	}
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("fresh recording should be kept: %v", err)
+	}
+}
+
 func TestGetStats(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "krc-test-*")
 	if err != nil {
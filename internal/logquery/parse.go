@@ -0,0 +1,350 @@
+// Package logquery implements a small query language over Gas Town's
+// event logs (logs/town.log and .events.jsonl).
+//
+// A query is a filter expression followed by optional pipeline stages:
+//
+//	type=spawn and actor=gastown/* | count by actor
+//	since=2h (type=done or type=crash) rig!=beads | tail 20
+//	payload.bead~gt-abc | head 5
+//
+// Predicates compare a field with a value:
+//
+//	=  !=    equality, or glob match when the value contains * or ?
+//	~  !~    case-insensitive substring
+//	<  <= > >=  ordered comparison (time for ts, numeric when both parse)
+//
+// Fields are ts, source, type, actor, rig, message and visibility; any
+// other name (optionally prefixed "payload.") reads the event payload.
+// since=<dur|time> and until=<dur|time> restrict the time range, where a
+// duration like 90m or 2d means that long ago.
+//
+// Predicates separated by whitespace are ANDed; and, or, not and
+// parentheses work as usual. Stages are "count", "count by f1,f2",
+// "head N" and "tail N".
+package logquery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// tokenKind classifies lexer tokens.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokPipe
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits a query into tokens.
+func lex(input string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			toks = append(toks, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			toks = append(toks, token{tokRParen, ")", i})
+			i++
+		case c == '|':
+			toks = append(toks, token{tokPipe, "|", i})
+			i++
+		case c == ',':
+			toks = append(toks, token{tokComma, ",", i})
+			i++
+		case c == '"' || c == '\'':
+			start := i
+			i++
+			var b strings.Builder
+			for i < len(input) && input[i] != c {
+				if input[i] == '\\' && i+1 < len(input) {
+					i++
+				}
+				b.WriteByte(input[i])
+				i++
+			}
+			if i >= len(input) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			toks = append(toks, token{tokString, b.String(), start})
+		case strings.ContainsRune("=!~<>", rune(c)):
+			start := i
+			op := string(c)
+			if i+1 < len(input) && (input[i+1] == '=' || (c == '!' && input[i+1] == '~')) {
+				op += string(input[i+1])
+			}
+			switch op {
+			case "=", "!=", "~", "!~", "<", "<=", ">", ">=":
+			default:
+				return nil, fmt.Errorf("unknown operator %q at position %d", op, start)
+			}
+			i += len(op)
+			toks = append(toks, token{tokOp, op, start})
+		default:
+			start := i
+			for i < len(input) && isWordByte(input[i]) {
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, start)
+			}
+			toks = append(toks, token{tokWord, input[start:i], start})
+		}
+	}
+	toks = append(toks, token{tokEOF, "", len(input)})
+	return toks, nil
+}
+
+func isWordByte(c byte) bool {
+	if c >= 0x80 {
+		return true // part of a UTF-8 sequence
+	}
+	r := rune(c)
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("/*?._-:@+[]", r)
+}
+
+// parser is a recursive-descent parser over lexed tokens.
+type parser struct {
+	toks []token
+	pos  int
+	now  time.Time
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(word string) bool {
+	t := p.peek()
+	return t.kind == tokWord && strings.EqualFold(t.text, word)
+}
+
+// Parse compiles a query string. Relative times (since=2h) are resolved
+// against the current time.
+func Parse(input string) (*Query, error) {
+	return parseAt(input, time.Now())
+}
+
+// parseAt compiles a query, resolving relative times against now.
+func parseAt(input string, now time.Time) (*Query, error) {
+	toks, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks, now: now}
+	q := &Query{text: strings.TrimSpace(input)}
+
+	if k := p.peek().kind; k != tokPipe && k != tokEOF {
+		q.filter, err = p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+	}
+	for p.peek().kind == tokPipe {
+		p.next()
+		st, err := p.parseStage()
+		if err != nil {
+			return nil, err
+		}
+		if st.kind == stageCount && q.countIndex() >= 0 {
+			return nil, fmt.Errorf("only one count stage is allowed")
+		}
+		q.stages = append(q.stages, st)
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+	return q, nil
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if p.isKeyword("and") {
+			p.next()
+		} else if t := p.peek(); t.kind == tokEOF || t.kind == tokPipe || t.kind == tokRParen || p.isKeyword("or") {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.isKeyword("not") {
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{inner}, nil
+	}
+	if p.peek().kind == tokLParen {
+		open := p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, fmt.Errorf("unclosed parenthesis at position %d", open.pos)
+		}
+		p.next()
+		return inner, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *parser) parsePredicate() (expr, error) {
+	field := p.next()
+	if field.kind != tokWord {
+		return nil, fmt.Errorf("expected field name at position %d, got %q", field.pos, field.text)
+	}
+	op := p.next()
+	if op.kind != tokOp {
+		return nil, fmt.Errorf("expected operator after %q at position %d", field.text, op.pos)
+	}
+	val := p.next()
+	if val.kind != tokWord && val.kind != tokString {
+		return nil, fmt.Errorf("expected value after %s%s at position %d", field.text, op.text, val.pos)
+	}
+
+	name := strings.ToLower(field.text)
+	switch name {
+	case "since", "until":
+		if op.text != "=" {
+			return nil, fmt.Errorf("%s only supports '=' (e.g. %s=1h)", name, name)
+		}
+		t, err := parseTimeValue(val.text, p.now)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if name == "since" {
+			return timeExpr{op: ">=", t: t}, nil
+		}
+		return timeExpr{op: "<", t: t}, nil
+	case "ts", "time":
+		if op.text == "~" || op.text == "!~" {
+			return nil, fmt.Errorf("ts does not support %s", op.text)
+		}
+		t, err := parseTimeValue(val.text, p.now)
+		if err != nil {
+			return nil, fmt.Errorf("ts: %w", err)
+		}
+		return timeExpr{op: op.text, t: t}, nil
+	}
+	return newPredicate(field.text, op.text, val.text), nil
+}
+
+func (p *parser) parseStage() (stage, error) {
+	t := p.next()
+	if t.kind != tokWord {
+		return stage{}, fmt.Errorf("expected stage after '|' at position %d", t.pos)
+	}
+	switch strings.ToLower(t.text) {
+	case "count":
+		st := stage{kind: stageCount}
+		if p.isKeyword("by") {
+			p.next()
+			for {
+				f := p.next()
+				if f.kind != tokWord {
+					return stage{}, fmt.Errorf("expected field name after 'count by' at position %d", f.pos)
+				}
+				st.by = append(st.by, f.text)
+				if p.peek().kind != tokComma {
+					break
+				}
+				p.next()
+			}
+		}
+		return st, nil
+	case "head", "limit", "tail":
+		n := p.next()
+		v, err := strconv.Atoi(n.text)
+		if n.kind != tokWord || err != nil || v < 0 {
+			return stage{}, fmt.Errorf("%s needs a non-negative count at position %d", t.text, n.pos)
+		}
+		kind := stageHead
+		if strings.EqualFold(t.text, "tail") {
+			kind = stageTail
+		}
+		return stage{kind: kind, n: v}, nil
+	default:
+		return stage{}, fmt.Errorf("unknown stage %q (want count, head or tail)", t.text)
+	}
+}
+
+// parseTimeValue parses an absolute timestamp or a duration meaning "that
+// long before now". Durations accept a d suffix for days.
+func parseTimeValue(s string, now time.Time) (time.Time, error) {
+	if d, err := parseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (want a duration like 2h or a date like 2006-01-02)", s)
+}
+
+// parseDuration extends time.ParseDuration with a "d" (day) unit.
+func parseDuration(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(s, "d"), 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days * float64(24*time.Hour)), nil
+	}
+	return time.ParseDuration(s)
+}
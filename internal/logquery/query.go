@@ -0,0 +1,394 @@
+package logquery
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a compiled log query.
+type Query struct {
+	text   string
+	filter expr // nil matches everything
+	stages []stage
+}
+
+// String returns the query source text.
+func (q *Query) String() string { return q.text }
+
+// Match reports whether a record passes the query's filter expression.
+// Pipeline stages are not applied.
+func (q *Query) Match(r Record) bool {
+	return q.filter == nil || q.filter.match(r)
+}
+
+// Aggregated reports whether the query ends in a count stage.
+func (q *Query) Aggregated() bool { return q.countIndex() >= 0 }
+
+func (q *Query) countIndex() int {
+	for i, st := range q.stages {
+		if st.kind == stageCount {
+			return i
+		}
+	}
+	return -1
+}
+
+// Group is one row of a count aggregation.
+type Group struct {
+	Keys  []string // values of the count-by fields, "" when missing
+	Count int
+}
+
+// Aggregate is the result of a count stage.
+type Aggregate struct {
+	By     []string // count-by field names; empty for a plain count
+	Groups []Group  // sorted by count descending, then keys
+	Total  int      // records counted across all groups
+}
+
+// Exec streams records from it through the query. For non-aggregating
+// queries each surviving record is passed to emit in time order and the
+// returned Aggregate is nil. Aggregating queries return their groups and
+// never call emit. Records are not buffered except as needed for tail.
+func (q *Query) Exec(it Iterator, emit func(Record) error) (*Aggregate, error) {
+	countAt := q.countIndex()
+	pre := q.stages
+	var post []stage
+	if countAt >= 0 {
+		pre = q.stages[:countAt]
+		post = q.stages[countAt+1:]
+	}
+
+	var agg *aggregator
+	if countAt >= 0 {
+		agg = newAggregator(q.stages[countAt].by)
+	}
+	sink := func(r Record) error {
+		if agg != nil {
+			agg.add(r)
+			return nil
+		}
+		return emit(r)
+	}
+
+	p := newPipeline(pre, sink)
+	for !p.done() {
+		rec, err := it.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !q.Match(rec) {
+			continue
+		}
+		if err := p.push(rec); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.flush(); err != nil {
+		return nil, err
+	}
+
+	if agg == nil {
+		return nil, nil
+	}
+	result := agg.result()
+	for _, st := range post {
+		switch st.kind {
+		case stageHead:
+			if len(result.Groups) > st.n {
+				result.Groups = result.Groups[:st.n]
+			}
+		case stageTail:
+			if len(result.Groups) > st.n {
+				result.Groups = result.Groups[len(result.Groups)-st.n:]
+			}
+		}
+	}
+	return result, nil
+}
+
+// Collect runs a non-aggregating query and returns the matching records.
+func (q *Query) Collect(it Iterator) ([]Record, *Aggregate, error) {
+	var out []Record
+	agg, err := q.Exec(it, func(r Record) error {
+		out = append(out, r)
+		return nil
+	})
+	return out, agg, err
+}
+
+// --- filter expressions ---
+
+type expr interface {
+	match(r Record) bool
+}
+
+type andExpr struct{ left, right expr }
+
+func (e andExpr) match(r Record) bool { return e.left.match(r) && e.right.match(r) }
+
+type orExpr struct{ left, right expr }
+
+func (e orExpr) match(r Record) bool { return e.left.match(r) || e.right.match(r) }
+
+type notExpr struct{ inner expr }
+
+func (e notExpr) match(r Record) bool { return !e.inner.match(r) }
+
+// timeExpr compares the record timestamp with a fixed time.
+type timeExpr struct {
+	op string
+	t  time.Time
+}
+
+func (e timeExpr) match(r Record) bool {
+	if r.Time.IsZero() {
+		return false
+	}
+	switch e.op {
+	case "=":
+		return r.Time.Equal(e.t)
+	case "!=":
+		return !r.Time.Equal(e.t)
+	case "<":
+		return r.Time.Before(e.t)
+	case "<=":
+		return !r.Time.After(e.t)
+	case ">":
+		return r.Time.After(e.t)
+	case ">=":
+		return !r.Time.Before(e.t)
+	}
+	return false
+}
+
+// predicate compares a record field with a literal value.
+type predicate struct {
+	field string
+	op    string
+	value string
+	glob  *regexp.Regexp // set for =/!= when value has glob metacharacters
+	lower string         // lowercased value for ~ and !~
+}
+
+func newPredicate(field, op, value string) predicate {
+	p := predicate{field: field, op: op, value: value, lower: strings.ToLower(value)}
+	if (op == "=" || op == "!=") && strings.ContainsAny(value, "*?") {
+		p.glob = globToRegexp(value)
+	}
+	return p
+}
+
+func (p predicate) match(r Record) bool {
+	got, ok := r.Field(p.field)
+	if !ok {
+		// A missing field never equals anything.
+		return p.op == "!=" || p.op == "!~"
+	}
+	switch p.op {
+	case "=":
+		if p.glob != nil {
+			return p.glob.MatchString(got)
+		}
+		return got == p.value
+	case "!=":
+		if p.glob != nil {
+			return !p.glob.MatchString(got)
+		}
+		return got != p.value
+	case "~":
+		return strings.Contains(strings.ToLower(got), p.lower)
+	case "!~":
+		return !strings.Contains(strings.ToLower(got), p.lower)
+	}
+	c := compare(got, p.value)
+	switch p.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+// compare orders two values numerically when both parse as numbers,
+// otherwise lexically.
+func compare(a, b string) int {
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// globToRegexp converts a glob where * matches any run of characters
+// (including /) and ? matches one character.
+func globToRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// --- pipeline stages ---
+
+type stageKind int
+
+const (
+	stageCount stageKind = iota
+	stageHead
+	stageTail
+)
+
+type stage struct {
+	kind stageKind
+	n    int      // head/tail
+	by   []string // count by
+}
+
+// pipeline applies head/tail stages to a record stream.
+type pipeline struct {
+	stages []stage
+	sink   func(Record) error
+	seen   []int      // records passed through each head stage
+	buf    [][]Record // ring buffers for tail stages
+}
+
+func newPipeline(stages []stage, sink func(Record) error) *pipeline {
+	return &pipeline{
+		stages: stages,
+		sink:   sink,
+		seen:   make([]int, len(stages)),
+		buf:    make([][]Record, len(stages)),
+	}
+}
+
+// done reports whether no further input can reach the sink: some head
+// stage before any tail stage has been satisfied.
+func (p *pipeline) done() bool {
+	for i, st := range p.stages {
+		if st.kind == stageTail {
+			return false
+		}
+		if st.kind == stageHead && p.seen[i] >= st.n {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *pipeline) push(r Record) error { return p.pushFrom(0, r) }
+
+func (p *pipeline) pushFrom(i int, r Record) error {
+	for ; i < len(p.stages); i++ {
+		st := p.stages[i]
+		switch st.kind {
+		case stageHead:
+			if p.seen[i] >= st.n {
+				return nil
+			}
+			p.seen[i]++
+		case stageTail:
+			if st.n == 0 {
+				return nil
+			}
+			if len(p.buf[i]) == st.n {
+				p.buf[i] = p.buf[i][1:]
+			}
+			p.buf[i] = append(p.buf[i], r)
+			return nil
+		}
+	}
+	return p.sink(r)
+}
+
+// flush releases buffered tail records downstream, in stage order.
+func (p *pipeline) flush() error {
+	for i, st := range p.stages {
+		if st.kind != stageTail {
+			continue
+		}
+		buffered := p.buf[i]
+		p.buf[i] = nil
+		for _, r := range buffered {
+			if err := p.pushFrom(i+1, r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// aggregator counts records grouped by field values.
+type aggregator struct {
+	by     []string
+	counts map[string]*Group
+	total  int
+}
+
+func newAggregator(by []string) *aggregator {
+	return &aggregator{by: by, counts: make(map[string]*Group)}
+}
+
+func (a *aggregator) add(r Record) {
+	keys := make([]string, len(a.by))
+	for i, f := range a.by {
+		keys[i], _ = r.Field(f)
+	}
+	id := strings.Join(keys, "\x00")
+	g, ok := a.counts[id]
+	if !ok {
+		g = &Group{Keys: keys}
+		a.counts[id] = g
+	}
+	g.Count++
+	a.total++
+}
+
+func (a *aggregator) result() *Aggregate {
+	out := &Aggregate{By: a.by, Total: a.total}
+	if len(a.by) == 0 {
+		out.Groups = []Group{{Count: a.total}}
+		return out
+	}
+	for _, g := range a.counts {
+		out.Groups = append(out.Groups, *g)
+	}
+	sort.Slice(out.Groups, func(i, j int) bool {
+		gi, gj := out.Groups[i], out.Groups[j]
+		if gi.Count != gj.Count {
+			return gi.Count > gj.Count
+		}
+		return strings.Join(gi.Keys, "\x00") < strings.Join(gj.Keys, "\x00")
+	})
+	return out
+}
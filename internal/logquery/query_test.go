@@ -0,0 +1,211 @@
+package logquery
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func testRecords() []Record {
+	at := func(minsAgo int) time.Time { return testNow.Add(-time.Duration(minsAgo) * time.Minute) }
+	return []Record{
+		{Time: at(300), Source: SourceTown, Type: "spawn", Actor: "gastown/polecats/Toast", Rig: "gastown", Message: "spawned for gt-1"},
+		{Time: at(120), Source: SourceEvents, Type: "sling", Actor: "mayor", Payload: map[string]interface{}{"bead": "gt-2", "rig": "gastown"}, Rig: "gastown"},
+		{Time: at(90), Source: SourceTown, Type: "crash", Actor: "beads/polecats/Nux", Rig: "beads", Message: "exited unexpectedly"},
+		{Time: at(30), Source: SourceTown, Type: "spawn", Actor: "gastown/crew/max", Rig: "gastown"},
+		{Time: at(10), Source: SourceTown, Type: "done", Actor: "gastown/polecats/Toast", Rig: "gastown", Message: "completed gt-1"},
+	}
+}
+
+func run(t *testing.T, query string) ([]Record, *Aggregate) {
+	t.Helper()
+	q, err := parseAt(query, testNow)
+	if err != nil {
+		t.Fatalf("parse %q: %v", query, err)
+	}
+	recs, agg, err := q.Collect(NewSliceIterator(testRecords()))
+	if err != nil {
+		t.Fatalf("exec %q: %v", query, err)
+	}
+	return recs, agg
+}
+
+func types(recs []Record) []string {
+	var out []string
+	for _, r := range recs {
+		out = append(out, r.Type)
+	}
+	return out
+}
+
+func TestFilterPredicates(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"spawn", "sling", "crash", "spawn", "done"}},
+		{"type=spawn", []string{"spawn", "spawn"}},
+		{"type!=spawn", []string{"sling", "crash", "done"}},
+		{"actor=gastown/*", []string{"spawn", "spawn", "done"}},
+		{"actor=*/polecats/*", []string{"spawn", "crash", "done"}},
+		{"message~GT-1", []string{"spawn", "done"}},
+		{"type=spawn rig=gastown actor!=*crew*", []string{"spawn"}},
+		{"type=crash or type=done", []string{"crash", "done"}},
+		{"not (type=spawn or type=sling)", []string{"crash", "done"}},
+		{"since=2h", []string{"sling", "crash", "spawn", "done"}},
+		{"since=2h until=1h", []string{"sling", "crash"}},
+		{"ts>=2026-03-10T11:00:00Z", []string{"spawn", "done"}},
+		{"payload.bead=gt-2", []string{"sling"}},
+		{"bead=gt-2", []string{"sling"}},
+		{"source=events", []string{"sling"}},
+		{`message="completed gt-1"`, []string{"done"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			recs, agg := run(t, tt.query)
+			if agg != nil {
+				t.Fatalf("unexpected aggregate for %q", tt.query)
+			}
+			if got := types(recs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeadTail(t *testing.T) {
+	recs, _ := run(t, "| head 2")
+	if got := types(recs); !reflect.DeepEqual(got, []string{"spawn", "sling"}) {
+		t.Errorf("head = %v", got)
+	}
+	recs, _ = run(t, "rig=gastown | tail 2")
+	if got := types(recs); !reflect.DeepEqual(got, []string{"spawn", "done"}) {
+		t.Errorf("tail = %v", got)
+	}
+	recs, _ = run(t, "| tail 3 | head 1")
+	if got := types(recs); !reflect.DeepEqual(got, []string{"crash"}) {
+		t.Errorf("tail|head = %v", got)
+	}
+}
+
+func TestHeadStopsReading(t *testing.T) {
+	q, err := Parse("| head 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	it := &countingIterator{inner: NewSliceIterator(testRecords())}
+	if _, _, err := q.Collect(it); err != nil {
+		t.Fatal(err)
+	}
+	if it.reads != 1 {
+		t.Errorf("read %d records, want 1", it.reads)
+	}
+}
+
+type countingIterator struct {
+	inner Iterator
+	reads int
+}
+
+func (c *countingIterator) Next() (Record, error) {
+	r, err := c.inner.Next()
+	if err == nil {
+		c.reads++
+	}
+	return r, err
+}
+
+func TestCountBy(t *testing.T) {
+	_, agg := run(t, "| count")
+	if agg == nil || agg.Total != 5 || len(agg.Groups) != 1 || agg.Groups[0].Count != 5 {
+		t.Fatalf("count = %+v", agg)
+	}
+
+	_, agg = run(t, "type=spawn or type=done | count by rig, type")
+	want := []Group{
+		{Keys: []string{"gastown", "spawn"}, Count: 2},
+		{Keys: []string{"gastown", "done"}, Count: 1},
+	}
+	if !reflect.DeepEqual(agg.Groups, want) {
+		t.Errorf("groups = %+v, want %+v", agg.Groups, want)
+	}
+
+	_, agg = run(t, "| count by actor | head 1")
+	if len(agg.Groups) != 1 || agg.Groups[0].Keys[0] != "gastown/polecats/Toast" {
+		t.Errorf("top actor = %+v", agg.Groups)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, q := range []string{
+		"type",
+		"type=",
+		"(type=spawn",
+		"type=spawn | bogus",
+		"type=spawn | head x",
+		"since>1h",
+		"since=yesterday",
+		`message="open`,
+		"| count | count",
+		"type=spawn )",
+	} {
+		if _, err := Parse(q); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", q)
+		}
+	}
+}
+
+func TestOpenTownMergesSources(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "logs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	townLog := "2026-03-10 10:00:00 [spawn] gastown/polecats/Toast spawned for gt-1\n" +
+		"not a log line\n" +
+		"2026-03-10 10:20:00 [done] gastown/polecats/Toast completed gt-1\n"
+	eventsLog := `{"ts":"2026-03-10T10:10:00Z","source":"gt","type":"sling","actor":"mayor","payload":{"bead":"gt-1","rig":"gastown"},"visibility":"feed"}` + "\n" +
+		"{broken json\n"
+	if err := os.WriteFile(filepath.Join(townRoot, "logs", "town.log"), []byte(townLog), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, ".events.jsonl"), []byte(eventsLog), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	it, closeFn, err := OpenTown(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = closeFn() }()
+
+	q, err := Parse("rig=gastown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs, _, err := q.Collect(it)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := types(recs); !reflect.DeepEqual(got, []string{"spawn", "sling", "done"}) {
+		t.Fatalf("merged = %v", got)
+	}
+	if recs[0].Message != "spawned for gt-1" || recs[1].Source != SourceEvents {
+		t.Errorf("unexpected records: %+v", recs)
+	}
+}
+
+func TestOpenTownEmpty(t *testing.T) {
+	it, closeFn, err := OpenTown(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = closeFn() }()
+	if _, err := it.Next(); err == nil || !strings.Contains(err.Error(), "EOF") {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
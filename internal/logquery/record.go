@@ -0,0 +1,286 @@
+package logquery
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/townlog"
+)
+
+// Record sources.
+const (
+	SourceTown   = "town"   // logs/town.log lifecycle events
+	SourceEvents = "events" // .events.jsonl activity events
+	SourceFeed   = "feed"   // events shown in the feed TUI
+)
+
+// maxLineSize bounds a single log line; .events.jsonl payloads can be large.
+const maxLineSize = 1024 * 1024
+
+// Record is a log entry normalized across town.log and .events.jsonl so
+// a single query can run over both.
+type Record struct {
+	Time       time.Time
+	Source     string
+	Type       string
+	Actor      string
+	Rig        string
+	Message    string
+	Visibility string
+	Payload    map[string]interface{}
+}
+
+// Field returns the string value of a named field. Unknown names (and
+// names prefixed with "payload.") are looked up in the payload.
+func (r Record) Field(name string) (string, bool) {
+	switch strings.ToLower(name) {
+	case "ts", "time":
+		if r.Time.IsZero() {
+			return "", false
+		}
+		return r.Time.Format(time.RFC3339), true
+	case "source":
+		return r.Source, r.Source != ""
+	case "type":
+		return r.Type, r.Type != ""
+	case "actor", "agent":
+		return r.Actor, r.Actor != ""
+	case "rig":
+		return r.Rig, r.Rig != ""
+	case "message", "msg":
+		return r.Message, r.Message != ""
+	case "visibility":
+		return r.Visibility, r.Visibility != ""
+	}
+	key := strings.TrimPrefix(name, "payload.")
+	v, ok := r.Payload[key]
+	if !ok || v == nil {
+		return "", false
+	}
+	if s, ok := v.(string); ok {
+		return s, true
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v), true
+	}
+	return string(b), true
+}
+
+// rigFromActor derives the rig from an agent address like
+// "gastown/polecats/Toast". Town-level agents (mayor, deacon) have no rig.
+func rigFromActor(actor string) string {
+	if i := strings.Index(actor, "/"); i > 0 {
+		return actor[:i]
+	}
+	return ""
+}
+
+// Iterator yields records in time order. Next returns io.EOF when exhausted.
+type Iterator interface {
+	Next() (Record, error)
+}
+
+// lineIterator reads one record per line, skipping lines parse rejects.
+type lineIterator struct {
+	scanner *bufio.Scanner
+	parse   func(line string) (Record, bool)
+}
+
+func newLineIterator(r io.Reader, parse func(string) (Record, bool)) *lineIterator {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	return &lineIterator{scanner: scanner, parse: parse}
+}
+
+func (it *lineIterator) Next() (Record, error) {
+	for it.scanner.Scan() {
+		if rec, ok := it.parse(it.scanner.Text()); ok {
+			return rec, nil
+		}
+	}
+	if err := it.scanner.Err(); err != nil {
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
+
+// NewTownLogIterator streams records from a town.log reader.
+// Malformed lines are skipped.
+func NewTownLogIterator(r io.Reader) Iterator {
+	return newLineIterator(r, func(line string) (Record, bool) {
+		e, detail, err := townlog.ParseLogLine(line)
+		if err != nil {
+			return Record{}, false
+		}
+		return Record{
+			Time:    e.Timestamp,
+			Source:  SourceTown,
+			Type:    string(e.Type),
+			Actor:   e.Agent,
+			Rig:     rigFromActor(e.Agent),
+			Message: detail,
+		}, true
+	})
+}
+
+// NewEventsIterator streams records from a .events.jsonl reader.
+// Malformed lines are skipped.
+func NewEventsIterator(r io.Reader) Iterator {
+	return newLineIterator(r, func(line string) (Record, bool) {
+		if strings.TrimSpace(line) == "" {
+			return Record{}, false
+		}
+		var e events.Event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return Record{}, false
+		}
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			return Record{}, false
+		}
+		rec := Record{
+			Time:       ts,
+			Source:     SourceEvents,
+			Type:       e.Type,
+			Actor:      e.Actor,
+			Rig:        rigFromActor(e.Actor),
+			Visibility: e.Visibility,
+			Payload:    e.Payload,
+		}
+		if rig, ok := e.Payload["rig"].(string); ok && rig != "" {
+			rec.Rig = rig
+		}
+		if msg, ok := e.Payload["message"].(string); ok {
+			rec.Message = msg
+		}
+		return rec, true
+	})
+}
+
+// SliceIterator iterates over in-memory records.
+type SliceIterator struct {
+	records []Record
+	pos     int
+}
+
+// NewSliceIterator returns an iterator over records, which should already
+// be in time order.
+func NewSliceIterator(records []Record) *SliceIterator {
+	return &SliceIterator{records: records}
+}
+
+// Next returns the next record.
+func (it *SliceIterator) Next() (Record, error) {
+	if it.pos >= len(it.records) {
+		return Record{}, io.EOF
+	}
+	rec := it.records[it.pos]
+	it.pos++
+	return rec, nil
+}
+
+// mergeIterator interleaves time-ordered iterators into one time-ordered
+// stream, holding at most one pending record per input.
+type mergeIterator struct {
+	inputs  []Iterator
+	pending []*Record
+	started bool
+}
+
+// Merge combines time-ordered iterators. Ties go to the earlier input.
+func Merge(its ...Iterator) Iterator {
+	if len(its) == 1 {
+		return its[0]
+	}
+	return &mergeIterator{inputs: its, pending: make([]*Record, len(its))}
+}
+
+func (m *mergeIterator) fill(i int) error {
+	rec, err := m.inputs[i].Next()
+	if errors.Is(err, io.EOF) {
+		m.pending[i] = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	m.pending[i] = &rec
+	return nil
+}
+
+func (m *mergeIterator) Next() (Record, error) {
+	if !m.started {
+		m.started = true
+		for i := range m.inputs {
+			if err := m.fill(i); err != nil {
+				return Record{}, err
+			}
+		}
+	}
+	best := -1
+	for i, rec := range m.pending {
+		if rec != nil && (best < 0 || rec.Time.Before(m.pending[best].Time)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return Record{}, io.EOF
+	}
+	rec := *m.pending[best]
+	if err := m.fill(best); err != nil {
+		return Record{}, err
+	}
+	return rec, nil
+}
+
+// OpenTown opens the town's town.log and .events.jsonl as a single merged
+// stream. Missing files are skipped. The returned close function releases
+// the underlying files.
+func OpenTown(townRoot string) (Iterator, func() error, error) {
+	var files []*os.File
+	closeAll := func() error {
+		var firstErr error
+		for _, f := range files {
+			if err := f.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+
+	var its []Iterator
+	open := func(path string, wrap func(io.Reader) Iterator) error {
+		f, err := os.Open(path) //nolint:gosec // G304: path is constructed from trusted townRoot
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		files = append(files, f)
+		its = append(its, wrap(f))
+		return nil
+	}
+
+	if err := open(filepath.Join(townRoot, "logs", "town.log"), NewTownLogIterator); err != nil {
+		_ = closeAll()
+		return nil, nil, fmt.Errorf("opening town log: %w", err)
+	}
+	if err := open(filepath.Join(townRoot, events.EventsFile), NewEventsIterator); err != nil {
+		_ = closeAll()
+		return nil, nil, fmt.Errorf("opening events file: %w", err)
+	}
+	if len(its) == 0 {
+		return NewSliceIterator(nil), closeAll, nil
+	}
+	return Merge(its...), closeAll, nil
+}
@@ -18,6 +18,7 @@ import (
 
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/federation"
 )
 
 // ErrUnknownRecipient indicates the address does not match any known agent.
@@ -59,7 +60,7 @@ func NewResolver(b *beads.Beads, townRoot string) *Resolver {
 // Resolution order:
 // 1. Contains '/' → agent address or pattern (direct delivery)
 // 2. Starts with '@' → special pattern (@town, @crew, etc.)
-// 3. Starts with explicit prefix → use that type (group:, queue:, channel:)
+// 3. Starts with explicit prefix → use that type (group:, queue:, channel:, town:)
 // 4. Otherwise → lookup by name: group → queue → channel
 func (r *Resolver) Resolve(address string) ([]Recipient, error) {
 	return r.resolveWithVisited(address, make(map[string]bool))
@@ -87,6 +88,12 @@ func (r *Resolver) resolveWithVisited(address string, visited map[string]bool) (
 		return []Recipient{{Address: address, Type: RecipientAgent}}, nil
 	}
 
+	// Another town's agent (town:rig/agent) - pass through; the peer town
+	// validates the recipient when the router delivers it.
+	if federation.IsAddress(address) {
+		return []Recipient{{Address: address, Type: RecipientAgent}}, nil
+	}
+
 	// 2. Starts with '@' → special pattern (check before '/' since @rig/X contains '/')
 	if strings.HasPrefix(address, "@") {
 		return r.resolveAtPatternWithVisited(address, visited)
@@ -1,6 +1,7 @@
 package mail
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/steveyegge/gastown/internal/beads"
@@ -127,6 +128,24 @@ func TestResolverResolve_DirectAddresses(t *testing.T) {
 	}
 }
 
+func TestResolverResolve_FederatedAddress(t *testing.T) {
+	// With a town root, local addresses are validated against workspaces;
+	// another town's agents are left for that town to validate.
+	resolver := NewResolver(nil, t.TempDir())
+
+	got, err := resolver.Resolve("bartertown:gastown/max")
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	if len(got) != 1 || got[0].Address != "bartertown:gastown/max" || got[0].Type != RecipientAgent {
+		t.Errorf("Resolve = %+v, want the address passed through as an agent", got)
+	}
+
+	if _, err := resolver.Resolve("gastown/max"); !errors.Is(err, ErrUnknownRecipient) {
+		t.Errorf("Resolve(local unknown) error = %v, want ErrUnknownRecipient", err)
+	}
+}
+
 func TestResolverResolve_AtPatterns(t *testing.T) {
 	// Without beads, @patterns are passed through for existing router
 	resolver := NewResolver(nil, "")
@@ -14,6 +14,7 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/federation"
 	"github.com/steveyegge/gastown/internal/nudge"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/telemetry"
@@ -847,10 +848,56 @@ func (r *Router) Send(msg *Message) error {
 		return r.sendToGroup(msg)
 	}
 
+	// Check for town:rig/agent address - deliver to a peer town
+	if federation.IsAddress(msg.To) {
+		return r.sendToPeer(msg)
+	}
+
 	// Single recipient - send directly
 	return r.sendToSingle(msg)
 }
 
+// sendToPeer delivers a message addressed to town:rig/agent through the
+// federation. Addresses naming this town are delivered locally. The peer
+// validates the recipient and stores the message; nothing is written here.
+func (r *Router) sendToPeer(msg *Message) error {
+	town, local, err := federation.SplitAddress(msg.To)
+	if err != nil {
+		return err
+	}
+	if r.townRoot == "" {
+		return fmt.Errorf("cannot send to %s: not in a Gas Town workspace", msg.To)
+	}
+	if self, err := federation.TownName(r.townRoot); err == nil && self == town {
+		msgCopy := *msg
+		msgCopy.To = local
+		return r.Send(&msgCopy)
+	}
+
+	client, err := federation.NewClient(r.townRoot, town)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	id, err := client.SendMail(ctx, &federation.Mail{
+		From:     msg.From,
+		To:       local,
+		Subject:  msg.Subject,
+		Body:     msg.Body,
+		Priority: string(msg.Priority),
+		Type:     string(msg.Type),
+		ThreadID: msg.ThreadID,
+		ReplyTo:  msg.ReplyTo,
+		CC:       msg.CC,
+	})
+	if err != nil {
+		return fmt.Errorf("sending message to %s: %w", town, err)
+	}
+	msg.ID = id
+	return nil
+}
+
 // sendToGroup resolves a @group address and sends individual messages to each member.
 func (r *Router) sendToGroup(msg *Message) error {
 	group := parseGroupAddress(msg.To)
@@ -1,9 +1,11 @@
 package mail
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,6 +16,8 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/federation"
 	"github.com/steveyegge/gastown/internal/nudge"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/testutil"
@@ -1590,3 +1594,105 @@ func TestNotifyRecipient_BusyAgent(t *testing.T) {
 		t.Errorf("expected 1 queued nudge for busy agent, got %d", pending)
 	}
 }
+
+// peerMailBackend is the receiving side of TestSendToPeerTown.
+type peerMailBackend struct {
+	got []federation.Mail
+}
+
+func (b *peerMailBackend) DeliverMail(_ context.Context, _ string, m *federation.Mail) (string, error) {
+	b.got = append(b.got, *m)
+	return "hq-remote-1", nil
+}
+func (b *peerMailBackend) Convoy(context.Context, string, string) (*federation.ConvoyView, error) {
+	return nil, federation.ErrNotFound
+}
+func (b *peerMailBackend) Issue(context.Context, string, string) (*federation.IssueView, error) {
+	return nil, federation.ErrNotFound
+}
+func (b *peerMailBackend) Delegate(context.Context, string, *federation.DelegateRequest) (string, error) {
+	return "", federation.ErrInvalid
+}
+
+func TestSendToPeerTown(t *testing.T) {
+	newTown := func(name string) string {
+		root := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(root, "mayor"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := config.SaveTownConfig(filepath.Join(root, "mayor", "town.json"),
+			&config.TownConfig{Type: "town", Version: 1, Name: name}); err != nil {
+			t.Fatal(err)
+		}
+		return root
+	}
+	peer := func(root, name, url, otherRoot string) {
+		caPEM, err := os.ReadFile(filepath.Join(federation.CADir(otherRoot), "ca.crt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fc := config.NewFederationConfig()
+		fc.Peers[name] = &config.FederationPeer{URL: url, CA: string(caPEM)}
+		if err := config.SaveFederationConfig(config.FederationConfigPath(root), fc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	localRoot := newTown("gastown")
+	remoteRoot := newTown("bartertown")
+	backend := &peerMailBackend{}
+	srv, err := federation.New(federation.Config{
+		TownRoot:   remoteRoot,
+		ListenAddr: "127.0.0.1:0",
+		Logger:     slog.New(slog.DiscardHandler),
+	}, backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = srv.Start(ctx) }()
+	for i := 0; srv.Addr() == nil; i++ {
+		if i > 500 {
+			t.Fatal("federation server did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := federation.LoadIdentity(localRoot); err != nil { // creates the local CA
+		t.Fatal(err)
+	}
+	peer(localRoot, "bartertown", "https://"+srv.Addr().String(), remoteRoot)
+	peer(remoteRoot, "gastown", "https://unused.invalid", localRoot)
+
+	router := NewRouterWithTownRoot(localRoot, localRoot)
+	msg := &Message{
+		From:     "gastown/max",
+		To:       "bartertown:gastown/furiosa",
+		Subject:  "fuel",
+		Body:     "need 40 gallons",
+		Priority: PriorityHigh,
+		Type:     TypeTask,
+	}
+	if err := router.Send(msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if msg.ID != "hq-remote-1" {
+		t.Errorf("msg.ID = %q, want the peer's ID", msg.ID)
+	}
+	if len(backend.got) != 1 {
+		t.Fatalf("peer received %d messages, want 1", len(backend.got))
+	}
+	got := backend.got[0]
+	if got.From != "gastown:gastown/max" || got.To != "gastown/furiosa" {
+		t.Errorf("peer got from=%q to=%q", got.From, got.To)
+	}
+	if got.Priority != string(PriorityHigh) || got.Body != "need 40 gallons" {
+		t.Errorf("peer got priority=%q body=%q", got.Priority, got.Body)
+	}
+
+	// An unknown town is an error, not local delivery.
+	err = router.Send(&Message{From: "gastown/max", To: "thunderdome:mayor/", Subject: "x"})
+	if err == nil || !strings.Contains(err.Error(), "unknown peer town") {
+		t.Errorf("Send to unknown town: err = %v", err)
+	}
+}
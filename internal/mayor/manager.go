@@ -40,6 +40,12 @@ func (m *Manager) SessionName() string {
 	return SessionName()
 }
 
+// sessions returns the town's session backend: tmux, or the daemon's PTY
+// supervisor for headless towns.
+func (m *Manager) sessions() session.SessionBackend {
+	return session.NewBackend(m.townRoot, nil)
+}
+
 // mayorDir returns the working directory for the mayor.
 func (m *Manager) mayorDir() string {
 	return filepath.Join(m.townRoot, "mayor")
@@ -53,7 +59,7 @@ func (m *Manager) Start(agentOverride string) error {
 
 	// Kill any existing zombie session (tmux alive but agent dead).
 	// Returns error if session is healthy and already running.
-	_, err := session.KillExistingSession(t, sessionID, true)
+	_, err := session.KillExistingSession(m.sessions(), sessionID, true)
 	if err != nil {
 		return ErrAlreadyRunning
 	}
@@ -95,11 +101,11 @@ func (m *Manager) Start(agentOverride string) error {
 
 // Stop stops the mayor session.
 func (m *Manager) Stop() error {
-	t := tmux.NewTmux()
+	b := m.sessions()
 	sessionID := m.SessionName()
 
 	// Check if session exists
-	running, err := t.HasSession(sessionID)
+	running, err := b.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -108,11 +114,11 @@ func (m *Manager) Stop() error {
 	}
 
 	// Try graceful shutdown first (best-effort interrupt)
-	_ = t.SendKeysRaw(sessionID, "C-c")
+	_ = b.SendKeysRaw(sessionID, "C-c")
 	time.Sleep(100 * time.Millisecond)
 
 	// Kill the session and all its processes
-	if err := t.KillSessionWithProcesses(sessionID); err != nil {
+	if err := b.KillSessionWithProcesses(sessionID); err != nil {
 		return fmt.Errorf("killing session: %w", err)
 	}
 
@@ -121,16 +127,15 @@ func (m *Manager) Stop() error {
 
 // IsRunning checks if the mayor session is active.
 func (m *Manager) IsRunning() (bool, error) {
-	t := tmux.NewTmux()
-	return t.HasSession(m.SessionName())
+	return m.sessions().HasSession(m.SessionName())
 }
 
 // Status returns information about the mayor session.
 func (m *Manager) Status() (*tmux.SessionInfo, error) {
-	t := tmux.NewTmux()
+	b := m.sessions()
 	sessionID := m.SessionName()
 
-	running, err := t.HasSession(sessionID)
+	running, err := b.HasSession(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("checking session: %w", err)
 	}
@@ -138,5 +143,5 @@ func (m *Manager) Status() (*tmux.SessionInfo, error) {
 		return nil, ErrNotRunning
 	}
 
-	return t.GetSessionInfo(sessionID)
+	return session.GetSessionInfo(b, sessionID)
 }
@@ -0,0 +1,355 @@
+// Package memory models agent memories stored in the beads key-value store.
+//
+// A memory is a short insight an agent wants future sessions to know. Each
+// one is scoped (town, rig, role, or a single agent identity), records where
+// it came from (author and source bead), may expire, and is ranked against
+// the work at hand so gt prime injects only the most relevant few within a
+// token budget. Memories written before scoping existed are plain strings;
+// they decode as town-scoped memories with no provenance.
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// KeyPrefix namespaces memories in the beads kv store.
+const KeyPrefix = "memory."
+
+// Bounds on what gt prime injects.
+const (
+	DefaultPrimeLimit  = 10
+	DefaultPrimeTokens = 800
+)
+
+// ScopeKind is the breadth of a memory's audience.
+type ScopeKind string
+
+const (
+	ScopeTown  ScopeKind = "town"  // every agent
+	ScopeRig   ScopeKind = "rig"   // agents in one rig
+	ScopeRole  ScopeKind = "role"  // agents with one role, e.g. refinery
+	ScopeAgent ScopeKind = "agent" // one identity, e.g. gastown/polecats/Toast
+)
+
+// Scope says who sees a memory.
+type Scope struct {
+	Kind ScopeKind
+	Name string // rig, role, or agent identity; empty for town
+}
+
+// Town is the scope visible to every agent.
+var Town = Scope{Kind: ScopeTown}
+
+// ParseScope parses "town", "rig:<name>", "role:<role>" or "agent:<identity>".
+func ParseScope(s string) (Scope, error) {
+	if s == "" || s == string(ScopeTown) {
+		return Town, nil
+	}
+	kind, name, ok := strings.Cut(s, ":")
+	if !ok || name == "" {
+		return Scope{}, fmt.Errorf("invalid scope %q (want town, rig:<name>, role:<role> or agent:<identity>)", s)
+	}
+	switch ScopeKind(kind) {
+	case ScopeRig, ScopeRole, ScopeAgent:
+		return Scope{Kind: ScopeKind(kind), Name: name}, nil
+	}
+	return Scope{}, fmt.Errorf("invalid scope kind %q (want town, rig, role or agent)", kind)
+}
+
+func (s Scope) String() string {
+	if s.Kind == ScopeTown || s.Kind == "" {
+		return string(ScopeTown)
+	}
+	return string(s.Kind) + ":" + s.Name
+}
+
+// keyPart is the scope's segment of a kv key. Town memories have none, so
+// they keep the key layout used before scopes existed.
+func (s Scope) keyPart() string {
+	if s.Kind == ScopeTown || s.Kind == "" {
+		return ""
+	}
+	return string(s.Kind) + "-" + Slug(s.Name)
+}
+
+// specificity ranks narrower scopes higher when scores tie.
+func (s Scope) specificity() int {
+	switch s.Kind {
+	case ScopeAgent:
+		return 3
+	case ScopeRole:
+		return 2
+	case ScopeRig:
+		return 1
+	}
+	return 0
+}
+
+// Memory is one stored insight.
+type Memory struct {
+	// Key is the kv key without KeyPrefix, e.g. "refinery-worktree" or
+	// "rig-gastown.refinery-worktree".
+	Key       string     `json:"-"`
+	Content   string     `json:"content"`
+	Scope     Scope      `json:"-"`
+	Bead      string     `json:"bead,omitempty"`   // bead the insight came from
+	Author    string     `json:"author,omitempty"` // agent address that stored it
+	Tags      []string   `json:"tags,omitempty"`
+	CreatedAt time.Time  `json:"created_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// record is the stored JSON form. The version field tells structured
+// values apart from legacy plain-text ones.
+type record struct {
+	Version int    `json:"gt_memory"`
+	Scope   string `json:"scope,omitempty"`
+	Memory
+}
+
+// Key returns the kv key (without KeyPrefix) for a slug in scope.
+func Key(scope Scope, slug string) string {
+	if part := scope.keyPart(); part != "" {
+		return part + "." + slug
+	}
+	return slug
+}
+
+// Encode returns the kv value for m.
+func (m *Memory) Encode() (string, error) {
+	data, err := json.Marshal(record{Version: 1, Scope: m.Scope.String(), Memory: *m})
+	if err != nil {
+		return "", fmt.Errorf("encoding memory: %w", err)
+	}
+	return string(data), nil
+}
+
+// Decode parses a kv entry. key is the full kv key including KeyPrefix.
+// ok is false for keys outside the memory namespace.
+func Decode(key, value string) (m *Memory, ok bool) {
+	short, ok := strings.CutPrefix(key, KeyPrefix)
+	if !ok || short == "" {
+		return nil, false
+	}
+	var rec record
+	if strings.HasPrefix(value, "{") && json.Unmarshal([]byte(value), &rec) == nil && rec.Version > 0 {
+		m := rec.Memory
+		m.Key = short
+		if scope, err := ParseScope(rec.Scope); err == nil {
+			m.Scope = scope
+		} else {
+			m.Scope = Town
+		}
+		return &m, true
+	}
+	return &Memory{Key: short, Content: value, Scope: Town}, true
+}
+
+// Expired reports whether m has passed its expiry at now.
+func (m *Memory) Expired(now time.Time) bool {
+	return m.ExpiresAt != nil && !now.Before(*m.ExpiresAt)
+}
+
+// Viewer identifies the agent memories are selected for.
+type Viewer struct {
+	Rig   string
+	Role  string
+	Agent string // full identity, e.g. gastown/polecats/Toast
+}
+
+// VisibleTo reports whether v is in m's audience.
+func (m *Memory) VisibleTo(v Viewer) bool {
+	switch m.Scope.Kind {
+	case ScopeRig:
+		return m.Scope.Name == v.Rig
+	case ScopeRole:
+		return m.Scope.Name == v.Role
+	case ScopeAgent:
+		return m.Scope.Name == v.Agent
+	}
+	return true
+}
+
+// Tokens estimates how many model tokens m costs when injected, at roughly
+// four characters per token.
+func (m *Memory) Tokens() int {
+	return (len(m.Key) + len(m.Content) + 8) / 4
+}
+
+// Query is what memories are ranked against.
+type Query struct {
+	Text string // free text, e.g. the hooked bead's title and description
+	Bead string // hooked bead ID; memories from this bead rank first
+}
+
+// Score rates m against q; zero means no overlap. Matching terms count once
+// each, weighted by where they appear.
+func Score(m *Memory, q Query) float64 {
+	score := 0.0
+	if q.Bead != "" && m.Bead == q.Bead {
+		score += 10
+	}
+	terms := Terms(q.Text)
+	if len(terms) == 0 {
+		return score
+	}
+	content := termSet(m.Content)
+	key := termSet(strings.ReplaceAll(m.Key, "-", " "))
+	tags := map[string]bool{}
+	for _, t := range m.Tags {
+		tags[strings.ToLower(t)] = true
+	}
+	for _, t := range terms {
+		switch {
+		case tags[t]:
+			score += 3
+		case key[t]:
+			score += 2
+		case content[t]:
+			score++
+		}
+	}
+	return score
+}
+
+// Search returns memories matching every term of text, best first.
+// An empty text matches everything, newest first.
+func Search(mems []*Memory, text string) []*Memory {
+	terms := Terms(text)
+	var out []*Memory
+	for _, m := range mems {
+		hay := termSet(m.Key + " " + strings.ReplaceAll(m.Key, "-", " ") + " " + m.Content + " " + strings.Join(m.Tags, " ") + " " + m.Bead + " " + m.Author)
+		all := true
+		for _, t := range terms {
+			if !hay[t] && !prefixMatch(hay, t) {
+				all = false
+				break
+			}
+		}
+		if all {
+			out = append(out, m)
+		}
+	}
+	q := Query{Text: text}
+	sort.SliceStable(out, func(i, j int) bool {
+		si, sj := Score(out[i], q), Score(out[j], q)
+		if si != sj {
+			return si > sj
+		}
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// Select picks the memories to inject for v working on q: visible, unexpired,
+// ranked by relevance then scope specificity then recency, at most limit of
+// them and no more than budget estimated tokens. A zero limit or budget means
+// no bound on that axis.
+func Select(mems []*Memory, v Viewer, q Query, limit, budget int, now time.Time) []*Memory {
+	type ranked struct {
+		m     *Memory
+		score float64
+	}
+	var cands []ranked
+	for _, m := range mems {
+		if m.Expired(now) || !m.VisibleTo(v) {
+			continue
+		}
+		cands = append(cands, ranked{m, Score(m, q)})
+	}
+	sort.SliceStable(cands, func(i, j int) bool {
+		a, b := cands[i], cands[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if sa, sb := a.m.Scope.specificity(), b.m.Scope.specificity(); sa != sb {
+			return sa > sb
+		}
+		if !a.m.CreatedAt.Equal(b.m.CreatedAt) {
+			return a.m.CreatedAt.After(b.m.CreatedAt)
+		}
+		return a.m.Key < b.m.Key
+	})
+
+	var out []*Memory
+	used := 0
+	for _, c := range cands {
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+		cost := c.m.Tokens()
+		if budget > 0 && used+cost > budget {
+			continue // a shorter memory further down may still fit
+		}
+		used += cost
+		out = append(out, c.m)
+	}
+	return out
+}
+
+// Slug normalizes s into a key segment: lowercase alphanumerics and single
+// hyphens.
+func Slug(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			hyphen = false
+		} else if !hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
+
+// stopwords are dropped from queries so ranking keys on content words.
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "in": true, "is": true,
+	"it": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"this": true, "to": true, "with": true,
+}
+
+// Terms splits text into lowercase search terms, dropping stopwords,
+// single characters and duplicates.
+func Terms(text string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, f := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(f) < 2 || stopwords[f] || seen[f] {
+			continue
+		}
+		seen[f] = true
+		out = append(out, f)
+	}
+	return out
+}
+
+func termSet(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, t := range Terms(text) {
+		set[t] = true
+	}
+	return set
+}
+
+func prefixMatch(set map[string]bool, prefix string) bool {
+	for t := range set {
+		if strings.HasPrefix(t, prefix) {
+			return true
+		}
+	}
+	return false
+}
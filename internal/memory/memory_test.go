@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScopeRoundTrip(t *testing.T) {
+	for _, s := range []string{"town", "rig:gastown", "role:refinery", "agent:gastown/polecats/Toast"} {
+		scope, err := ParseScope(s)
+		if err != nil {
+			t.Fatalf("ParseScope(%q): %v", s, err)
+		}
+		if scope.String() != s {
+			t.Errorf("ParseScope(%q).String() = %q", s, scope.String())
+		}
+	}
+	for _, s := range []string{"rig", "rig:", "team:x"} {
+		if _, err := ParseScope(s); err == nil {
+			t.Errorf("ParseScope(%q) should fail", s)
+		}
+	}
+	if got := Key(Scope{Kind: ScopeAgent, Name: "gastown/polecats/Toast"}, "notes"); got != "agent-gastown-polecats-toast.notes" {
+		t.Errorf("Key = %q", got)
+	}
+	if got := Key(Town, "notes"); got != "notes" {
+		t.Errorf("town Key = %q", got)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	exp := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	m := &Memory{
+		Content:   "Refinery uses a worktree; never checkout main there",
+		Scope:     Scope{Kind: ScopeRole, Name: "refinery"},
+		Bead:      "gt-abc",
+		Author:    "gastown/Toast",
+		Tags:      []string{"git"},
+		ExpiresAt: &exp,
+	}
+	val, err := m.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := Decode(KeyPrefix+"role-refinery.worktree", val)
+	if !ok {
+		t.Fatal("Decode rejected memory key")
+	}
+	if got.Key != "role-refinery.worktree" || got.Content != m.Content || got.Scope != m.Scope ||
+		got.Bead != "gt-abc" || got.Author != "gastown/Toast" || !got.ExpiresAt.Equal(exp) {
+		t.Errorf("decoded = %+v", got)
+	}
+
+	legacy, ok := Decode(KeyPrefix+"old-note", "Always use --stdin for multi-line mail")
+	if !ok || legacy.Scope != Town || legacy.Content != "Always use --stdin for multi-line mail" {
+		t.Errorf("legacy = %+v, %v", legacy, ok)
+	}
+	if _, ok := Decode("other.key", "x"); ok {
+		t.Error("non-memory key should be rejected")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	mems := []*Memory{
+		{Key: "town-general", Content: "Run make test before gt done", Scope: Town},
+		{Key: "rig-other.x", Content: "Other rig uses pnpm for builds", Scope: Scope{Kind: ScopeRig, Name: "other"}},
+		{Key: "rig-gastown.dolt", Content: "Dolt server port is 3307 in this rig", Scope: Scope{Kind: ScopeRig, Name: "gastown"}},
+		{Key: "role-polecat.expired", Content: "Dolt migration in progress", Scope: Scope{Kind: ScopeRole, Name: "polecat"}, ExpiresAt: &past},
+		{Key: "agent-toast.bead", Content: "Half-done refactor of the parser", Scope: Scope{Kind: ScopeAgent, Name: "gastown/polecats/Toast"}, Bead: "gt-42"},
+	}
+	v := Viewer{Rig: "gastown", Role: "polecat", Agent: "gastown/polecats/Toast"}
+
+	got := Select(mems, v, Query{Text: "fix dolt connection errors", Bead: "gt-42"}, 0, 0, now)
+	var keys []string
+	for _, m := range got {
+		keys = append(keys, m.Key)
+	}
+	want := []string{"agent-toast.bead", "rig-gastown.dolt", "town-general"}
+	if len(keys) != len(want) {
+		t.Fatalf("Select = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("Select = %v, want %v", keys, want)
+		}
+	}
+
+	if got := Select(mems, v, Query{}, 1, 0, now); len(got) != 1 || got[0].Key != "agent-toast.bead" {
+		t.Errorf("limit 1 = %+v", got)
+	}
+	budget := mems[2].Tokens()
+	if got := Select(mems, v, Query{Text: "dolt"}, 0, budget, now); len(got) != 1 || got[0].Key != "rig-gastown.dolt" {
+		t.Errorf("budget-limited = %+v", got)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	mems := []*Memory{
+		{Key: "refinery-worktree", Content: "Refinery uses worktree, cannot checkout main"},
+		{Key: "mail-stdin", Content: "Always use --stdin for multi-line mail", Tags: []string{"mail"}},
+	}
+	if got := Search(mems, "refin work"); len(got) != 1 || got[0].Key != "refinery-worktree" {
+		t.Errorf("prefix search = %+v", got)
+	}
+	if got := Search(mems, "mail"); len(got) != 1 || got[0].Key != "mail-stdin" {
+		t.Errorf("search = %+v", got)
+	}
+	if got := Search(mems, ""); len(got) != 2 {
+		t.Errorf("empty search = %d results", len(got))
+	}
+}
@@ -0,0 +1,221 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// ConfigValuesFile is the name of the plugin config values file in a plugins
+// directory (<town>/plugins or <rig>/plugins).
+const ConfigValuesFile = "config.json"
+
+// configValuesVersion is the current config values format version.
+const configValuesVersion = 1
+
+// ConfigValues holds the non-secret config values set for the plugins in one
+// scope (the town or a rig), keyed by plugin name and then config key.
+type ConfigValues struct {
+	Version int                          `json:"version"`
+	Plugins map[string]map[string]string `json:"plugins"`
+}
+
+// ConfigValuesPath returns the config values file for the town (rig == "")
+// or a rig.
+func ConfigValuesPath(townRoot, rig string) string {
+	if rig == "" {
+		return filepath.Join(townRoot, "plugins", ConfigValuesFile)
+	}
+	return filepath.Join(townRoot, rig, "plugins", ConfigValuesFile)
+}
+
+// LoadConfigValues reads a scope's config values. A missing file is empty.
+func LoadConfigValues(townRoot, rig string) (*ConfigValues, error) {
+	cv := &ConfigValues{Version: configValuesVersion, Plugins: make(map[string]map[string]string)}
+	data, err := os.ReadFile(ConfigValuesPath(townRoot, rig)) //nolint:gosec // G304: path is constructed from trusted town root
+	if os.IsNotExist(err) {
+		return cv, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading plugin config: %w", err)
+	}
+	if err := json.Unmarshal(data, cv); err != nil {
+		return nil, fmt.Errorf("parsing plugin config: %w", err)
+	}
+	if cv.Plugins == nil {
+		cv.Plugins = make(map[string]map[string]string)
+	}
+	return cv, nil
+}
+
+// Save writes the scope's config values atomically.
+func (cv *ConfigValues) Save(townRoot, rig string) error {
+	cv.Version = configValuesVersion
+	return util.EnsureDirAndWriteJSON(ConfigValuesPath(townRoot, rig), cv)
+}
+
+// Get returns a plugin's value for key and whether it is set.
+func (cv *ConfigValues) Get(plugin, key string) (string, bool) {
+	v, ok := cv.Plugins[plugin][key]
+	return v, ok
+}
+
+// Set sets a plugin's value for key.
+func (cv *ConfigValues) Set(plugin, key, value string) {
+	if cv.Plugins[plugin] == nil {
+		cv.Plugins[plugin] = make(map[string]string)
+	}
+	cv.Plugins[plugin][key] = value
+}
+
+// Unset removes a plugin's value for key and reports whether it was set.
+func (cv *ConfigValues) Unset(plugin, key string) bool {
+	if _, ok := cv.Plugins[plugin][key]; !ok {
+		return false
+	}
+	delete(cv.Plugins[plugin], key)
+	if len(cv.Plugins[plugin]) == 0 {
+		delete(cv.Plugins, plugin)
+	}
+	return true
+}
+
+// SecretName returns the secrets store name for a plugin's secret value in
+// the town (rig == "") or a rig.
+func SecretName(rig, plugin, key string) string {
+	return "plugin/" + LockKey(rig, plugin) + "/" + key
+}
+
+// SecretLookup fetches a secret by name; usually (*secrets.Store).Get.
+type SecretLookup func(name string) (string, bool, error)
+
+// ConfigSource says where a resolved config value came from.
+type ConfigSource string
+
+const (
+	SourceRigValue  ConfigSource = "rig"
+	SourceTownValue ConfigSource = "town"
+	SourceDefault   ConfigSource = "default"
+	SourceUnset     ConfigSource = "unset"
+)
+
+// ResolvedValue is one config value as the plugin will see it.
+type ResolvedValue struct {
+	Key    string
+	Env    string
+	Value  string
+	Secret bool
+	Source ConfigSource
+}
+
+// ResolvedConfig is a plugin's full configuration for a run.
+type ResolvedConfig struct {
+	Values []ResolvedValue
+
+	// Missing lists required keys with no value.
+	Missing []string
+
+	// Invalid lists values that don't match their declared type.
+	Invalid []string
+}
+
+// Problems returns why the plugin can't run with this configuration.
+func (rc *ResolvedConfig) Problems() []string {
+	var problems []string
+	for _, key := range rc.Missing {
+		problems = append(problems, fmt.Sprintf("%s is required but not set", key))
+	}
+	return append(problems, rc.Invalid...)
+}
+
+// Env returns the values to inject into the dog, split into plain values and
+// secrets. Unset values are left out.
+func (rc *ResolvedConfig) Env() (plain, secret map[string]string) {
+	plain = make(map[string]string)
+	secret = make(map[string]string)
+	for _, v := range rc.Values {
+		if v.Source == SourceUnset {
+			continue
+		}
+		if v.Secret {
+			secret[v.Env] = v.Value
+		} else {
+			plain[v.Env] = v.Value
+		}
+	}
+	return plain, secret
+}
+
+// ResolveConfig resolves every declared config value for a plugin. Rig-level
+// plugins see their rig's values first, then the town's; defaults fill the
+// rest. Secrets come from lookupSecret under the same precedence; a nil
+// lookupSecret leaves them unset.
+func ResolveConfig(townRoot string, p *Plugin, lookupSecret SecretLookup) (*ResolvedConfig, error) {
+	rc := &ResolvedConfig{}
+	if len(p.Config) == 0 {
+		return rc, nil
+	}
+
+	town, err := LoadConfigValues(townRoot, "")
+	if err != nil {
+		return nil, err
+	}
+	rig := &ConfigValues{}
+	if p.RigName != "" {
+		if rig, err = LoadConfigValues(townRoot, p.RigName); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range p.ConfigKeys() {
+		f := p.Config[key]
+		v := ResolvedValue{Key: key, Env: f.EnvName(key), Secret: f.Secret, Source: SourceUnset}
+		if f.Secret {
+			if lookupSecret != nil {
+				if err := resolveSecret(&v, p, lookupSecret); err != nil {
+					return nil, err
+				}
+			}
+		} else if val, ok := rig.Get(p.Name, key); ok && p.RigName != "" {
+			v.Value, v.Source = val, SourceRigValue
+		} else if val, ok := town.Get(p.Name, key); ok {
+			v.Value, v.Source = val, SourceTownValue
+		} else if f.Default != "" {
+			v.Value, v.Source = f.Default, SourceDefault
+		}
+
+		if v.Source == SourceUnset {
+			if f.Required {
+				rc.Missing = append(rc.Missing, key)
+			}
+		} else if err := f.Check(v.Value); err != nil {
+			rc.Invalid = append(rc.Invalid, fmt.Sprintf("%s: %v", key, err))
+		}
+		rc.Values = append(rc.Values, v)
+	}
+	return rc, nil
+}
+
+func resolveSecret(v *ResolvedValue, p *Plugin, lookupSecret SecretLookup) error {
+	if p.RigName != "" {
+		val, ok, err := lookupSecret(SecretName(p.RigName, p.Name, v.Key))
+		if err != nil {
+			return fmt.Errorf("reading secret %s: %w", v.Key, err)
+		}
+		if ok {
+			v.Value, v.Source = val, SourceRigValue
+			return nil
+		}
+	}
+	val, ok, err := lookupSecret(SecretName("", p.Name, v.Key))
+	if err != nil {
+		return fmt.Errorf("reading secret %s: %w", v.Key, err)
+	}
+	if ok {
+		v.Value, v.Source = val, SourceTownValue
+	}
+	return nil
+}
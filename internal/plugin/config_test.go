@@ -0,0 +1,147 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigValues(t *testing.T) {
+	town := t.TempDir()
+	cv, err := LoadConfigValues(town, "gastown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cv.Set("lint", "threshold", "5")
+	cv.Set("lint", "repo", "acme/app")
+	if err := cv.Save(town, "gastown"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(town, "gastown", "plugins", ConfigValuesFile)); err != nil {
+		t.Fatalf("rig config not written: %v", err)
+	}
+
+	cv, err = LoadConfigValues(town, "gastown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := cv.Get("lint", "threshold"); v != "5" || !ok {
+		t.Errorf("Get = %q, %v", v, ok)
+	}
+	if !cv.Unset("lint", "threshold") || cv.Unset("lint", "threshold") {
+		t.Error("Unset should report whether the value was set")
+	}
+	cv.Unset("lint", "repo")
+	if len(cv.Plugins) != 0 {
+		t.Errorf("empty plugin entry left behind: %v", cv.Plugins)
+	}
+}
+
+func TestResolveConfig(t *testing.T) {
+	town := t.TempDir()
+	p := &Plugin{
+		Name:    "lint",
+		RigName: "gastown",
+		Config: map[string]*ConfigField{
+			"threshold": {Type: ConfigInt, Default: "10"},
+			"repo":      {Required: true},
+			"mode":      {Default: "fast"},
+			"token":     {Secret: true, Required: true, Env: "GH_TOKEN"},
+			"webhook":   {Secret: true},
+		},
+	}
+
+	townValues, _ := LoadConfigValues(town, "")
+	townValues.Set("lint", "threshold", "20")
+	townValues.Set("lint", "repo", "acme/app")
+	if err := townValues.Save(town, ""); err != nil {
+		t.Fatal(err)
+	}
+	rigValues, _ := LoadConfigValues(town, "gastown")
+	rigValues.Set("lint", "threshold", "30")
+	if err := rigValues.Save(town, "gastown"); err != nil {
+		t.Fatal(err)
+	}
+	secretStore := map[string]string{SecretName("", "lint", "token"): "ghp_town"}
+	lookup := func(name string) (string, bool, error) {
+		v, ok := secretStore[name]
+		return v, ok, nil
+	}
+
+	rc, err := ResolveConfig(town, p, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems := rc.Problems(); problems != nil {
+		t.Errorf("Problems = %v", problems)
+	}
+	plain, secret := rc.Env()
+	wantPlain := map[string]string{"GT_PLUGIN_THRESHOLD": "30", "GT_PLUGIN_REPO": "acme/app", "GT_PLUGIN_MODE": "fast"}
+	if !reflect.DeepEqual(plain, wantPlain) {
+		t.Errorf("plain env = %v, want %v", plain, wantPlain)
+	}
+	if !reflect.DeepEqual(secret, map[string]string{"GH_TOKEN": "ghp_town"}) {
+		t.Errorf("secret env = %v", secret)
+	}
+
+	// Rig secrets win; invalid and missing values are reported.
+	secretStore[SecretName("gastown", "lint", "token")] = "ghp_rig"
+	rigValues.Set("lint", "threshold", "lots")
+	if err := rigValues.Save(town, "gastown"); err != nil {
+		t.Fatal(err)
+	}
+	townValues.Unset("lint", "repo")
+	if err := townValues.Save(town, ""); err != nil {
+		t.Fatal(err)
+	}
+	rc, err = ResolveConfig(town, p, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, secret := rc.Env(); secret["GH_TOKEN"] != "ghp_rig" {
+		t.Errorf("rig secret not preferred: %v", secret)
+	}
+	problems := strings.Join(rc.Problems(), "\n")
+	if !strings.Contains(problems, "repo is required") || !strings.Contains(problems, `threshold: "lots" is not an integer`) {
+		t.Errorf("Problems = %s", problems)
+	}
+
+	// Without a secrets lookup, required secrets are missing.
+	rc, _ = ResolveConfig(town, p, nil)
+	if !reflect.DeepEqual(rc.Missing, []string{"repo", "token"}) {
+		t.Errorf("Missing = %v", rc.Missing)
+	}
+}
+
+func TestConfigFieldEnvAndValidate(t *testing.T) {
+	if got := (&ConfigField{}).EnvName("max-files.v2"); got != "GT_PLUGIN_MAX_FILES_V2" {
+		t.Errorf("EnvName = %q", got)
+	}
+	for _, f := range []*ConfigField{
+		{Secret: true, Default: "hunter2"},
+		{Env: "1BAD"},
+	} {
+		if err := f.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded", f)
+		}
+	}
+}
+
+func TestFormatMailBodyConfig(t *testing.T) {
+	p := &Plugin{
+		Name:         "lint",
+		Instructions: "Lint.",
+		Config: map[string]*ConfigField{
+			"repo":  {Description: "Repository to lint"},
+			"token": {Secret: true, Env: "GH_TOKEN"},
+		},
+	}
+	body := p.FormatMailBody()
+	for _, want := range []string{"## Config\n", "- `$GT_PLUGIN_REPO` (repo): Repository to lint\n", "- `$GH_TOKEN` (token) secret\n"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package plugin
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Event kinds an event gate can react to (the gate's On field).
+// Everything except EventStartup is observed by the daemon.
+const (
+	// EventStartup fires when the Deacon starts.
+	EventStartup = "startup"
+
+	// EventBeadClosed fires when an issue is closed in any beads store.
+	EventBeadClosed = "bead.closed"
+
+	// EventMRMerged fires when a rig's refinery merges a merge request.
+	EventMRMerged = "mr.merged"
+
+	// EventConvoyLanded fires when a convoy closes with all work done.
+	EventConvoyLanded = "convoy.landed"
+
+	// EventEscalation fires when an agent raises (or re-raises) an escalation.
+	EventEscalation = "escalation"
+
+	// EventCommits fires when new commits appear on a watched branch of a
+	// rig's origin.
+	EventCommits = "commits"
+)
+
+// TownEventKinds lists the event kinds dispatched by the daemon.
+var TownEventKinds = []string{
+	EventBeadClosed,
+	EventMRMerged,
+	EventConvoyLanded,
+	EventEscalation,
+	EventCommits,
+}
+
+// IsTownEvent reports whether kind is dispatched by the daemon.
+func IsTownEvent(kind string) bool {
+	for _, k := range TownEventKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is a town occurrence that can open an event gate.
+type Event struct {
+	// Kind is one of the Event* constants.
+	Kind string `json:"kind"`
+
+	// Rig is the rig the event happened in; empty for town-level events
+	// (e.g., hq beads, convoys).
+	Rig string `json:"rig,omitempty"`
+
+	// Actor is the agent that caused the event (e.g., "gastown/polecats/nux").
+	Actor string `json:"actor,omitempty"`
+
+	// Labels are the labels on the subject (closed bead) or, for
+	// escalations, "severity:<level>".
+	Labels []string `json:"labels,omitempty"`
+
+	// Branch is the branch involved: the merge target for mr.merged, the
+	// watched branch for commits.
+	Branch string `json:"branch,omitempty"`
+
+	// Subject identifies what the event is about: a bead, MR, convoy or
+	// escalation ID, or the new head commit.
+	Subject string `json:"subject,omitempty"`
+
+	// Summary is a short human-readable description.
+	Summary string `json:"summary,omitempty"`
+
+	// Time is when the event happened.
+	Time time.Time `json:"time"`
+}
+
+// String formats the event for the Trigger section of a dog's mail.
+func (e Event) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Kind)
+	if e.Rig != "" {
+		sb.WriteString(" " + e.Rig)
+	}
+	if e.Branch != "" {
+		sb.WriteString(" " + e.Branch)
+	}
+	if e.Subject != "" {
+		sb.WriteString(" " + e.Subject)
+	}
+	if e.Summary != "" {
+		sb.WriteString(": " + e.Summary)
+	}
+	if len(e.Labels) > 0 {
+		sb.WriteString(fmt.Sprintf(" [%s]", strings.Join(e.Labels, ", ")))
+	}
+	if e.Actor != "" {
+		sb.WriteString(" (by " + e.Actor + ")")
+	}
+	return sb.String()
+}
+
+// Matches reports whether an event opens the gate. pluginRig is the rig of
+// a rig-level plugin: unless the gate sets Rig explicitly, such a plugin
+// only sees events from its own rig (and town-level events).
+func (g *Gate) Matches(e Event, pluginRig string) bool {
+	if g == nil || g.Type != GateEvent || g.On != e.Kind {
+		return false
+	}
+	switch {
+	case g.Rig != "":
+		if !MatchPattern(g.Rig, e.Rig) {
+			return false
+		}
+	case pluginRig != "" && e.Rig != "" && e.Rig != pluginRig:
+		return false
+	}
+	if g.Actor != "" && !MatchPattern(g.Actor, e.Actor) {
+		return false
+	}
+	if g.Branch != "" && e.Branch != "" && !MatchPattern(g.Branch, e.Branch) {
+		return false
+	}
+	if g.Label != "" {
+		for _, l := range e.Labels {
+			if MatchPattern(g.Label, l) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Validate checks an event gate's kind and patterns.
+func (g *Gate) Validate() error {
+	if g == nil || g.Type != GateEvent {
+		return nil
+	}
+	if g.On != EventStartup && !IsTownEvent(g.On) {
+		return fmt.Errorf("unknown event %q (want %s or %s)", g.On, EventStartup, strings.Join(TownEventKinds, ", "))
+	}
+	for field, pattern := range map[string]string{"rig": g.Rig, "label": g.Label, "actor": g.Actor, "branch": g.Branch} {
+		if _, err := path.Match(globEscape(pattern), ""); err != nil {
+			return fmt.Errorf("bad %s pattern %q: %w", field, pattern, err)
+		}
+	}
+	if g.Duration != "" {
+		if _, err := time.ParseDuration(g.Duration); err != nil {
+			return fmt.Errorf("bad duration %q: %w", g.Duration, err)
+		}
+	}
+	return nil
+}
+
+// MatchPattern matches name against a glob in path.Match syntax, except that
+// '*' also matches '/' so "polecat/*" covers "polecat/nux/gt-abc@123".
+// A malformed pattern matches nothing.
+func MatchPattern(pattern, name string) bool {
+	ok, err := path.Match(globEscape(pattern), globEscape(name))
+	return err == nil && ok
+}
+
+// globEscape hides '/' from path.Match so wildcards cross it.
+func globEscape(s string) string {
+	return strings.ReplaceAll(s, "/", "\x1f")
+}
@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGateMatches(t *testing.T) {
+	merged := Event{Kind: EventMRMerged, Rig: "gastown", Actor: "gastown/refinery", Branch: "main"}
+	closed := Event{Kind: EventBeadClosed, Rig: "gastown", Actor: "gastown/polecats/nux", Labels: []string{"bug", "area:ci"}}
+	landed := Event{Kind: EventConvoyLanded, Actor: "mayor"}
+
+	tests := []struct {
+		name      string
+		gate      *Gate
+		event     Event
+		pluginRig string
+		want      bool
+	}{
+		{"kind matches", &Gate{Type: GateEvent, On: EventMRMerged}, merged, "", true},
+		{"kind differs", &Gate{Type: GateEvent, On: EventConvoyLanded}, merged, "", false},
+		{"not an event gate", &Gate{Type: GateCooldown, On: EventMRMerged}, merged, "", false},
+		{"nil gate", nil, merged, "", false},
+		{"rig glob", &Gate{Type: GateEvent, On: EventMRMerged, Rig: "gas*"}, merged, "", true},
+		{"rig mismatch", &Gate{Type: GateEvent, On: EventMRMerged, Rig: "beads"}, merged, "", false},
+		{"rig plugin sees own rig", &Gate{Type: GateEvent, On: EventMRMerged}, merged, "gastown", true},
+		{"rig plugin ignores other rigs", &Gate{Type: GateEvent, On: EventMRMerged}, merged, "beads", false},
+		{"rig plugin with explicit rig", &Gate{Type: GateEvent, On: EventMRMerged, Rig: "*"}, merged, "beads", true},
+		{"rig plugin sees town events", &Gate{Type: GateEvent, On: EventConvoyLanded}, landed, "beads", true},
+		{"branch", &Gate{Type: GateEvent, On: EventMRMerged, Branch: "release/*"}, merged, "", false},
+		{"actor crosses slashes", &Gate{Type: GateEvent, On: EventBeadClosed, Actor: "gastown/*"}, closed, "", true},
+		{"actor mismatch", &Gate{Type: GateEvent, On: EventBeadClosed, Actor: "*/crew/*"}, closed, "", false},
+		{"any label", &Gate{Type: GateEvent, On: EventBeadClosed, Label: "area:*"}, closed, "", true},
+		{"no label", &Gate{Type: GateEvent, On: EventBeadClosed, Label: "security"}, closed, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.gate.Matches(tt.event, tt.pluginRig); got != tt.want {
+				t.Errorf("Matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGateValidate(t *testing.T) {
+	valid := []*Gate{
+		nil,
+		{Type: GateCooldown, Duration: "1h"},
+		{Type: GateEvent, On: EventStartup},
+		{Type: GateEvent, On: EventCommits, Branch: "polecat/*", Duration: "5m"},
+	}
+	for _, g := range valid {
+		if err := g.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v", g, err)
+		}
+	}
+	invalid := []*Gate{
+		{Type: GateEvent, On: "bead.opened"},
+		{Type: GateEvent, On: EventBeadClosed, Label: "[oops"},
+		{Type: GateEvent, On: EventMRMerged, Duration: "soon"},
+	}
+	for _, g := range invalid {
+		if err := g.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded", g)
+		}
+	}
+}
+
+func TestFormatTriggeredMailBody(t *testing.T) {
+	p := &Plugin{Name: "quality-review", Description: "Review merges", Instructions: "Do it."}
+	if got := p.FormatTriggeredMailBody(nil); got != p.FormatMailBody() || strings.Contains(got, "## Trigger") {
+		t.Errorf("untriggered body has a Trigger section:\n%s", got)
+	}
+
+	body := p.FormatTriggeredMailBody([]Event{
+		{Kind: EventMRMerged, Rig: "gastown", Branch: "main", Subject: "gt-mr1", Summary: "polecat/nux from nux", Actor: "gastown/refinery"},
+		{Kind: EventEscalation, Subject: "hq-esc1", Labels: []string{"severity:high"}},
+	})
+	for _, want := range []string{
+		"## Trigger\n\n- mr.merged gastown main gt-mr1: polecat/nux from nux (by gastown/refinery)\n",
+		"- escalation hq-esc1 [severity:high]\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Index(body, "## Trigger") > strings.Index(body, "## Instructions") {
+		t.Error("Trigger section should precede the instructions")
+	}
+}
@@ -0,0 +1,296 @@
+package plugin
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func pluginMD(name, version string) string {
+	return "+++\nname = \"" + name + "\"\n\n[package]\nversion = \"" + version + "\"\n+++\n\nDo the thing.\n"
+}
+
+// testPluginRepo creates a git repo holding a plugin at version and returns
+// the repo path and a function that commits a new version.
+func testPluginRepo(t *testing.T, name, version string) (string, func(version string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := filepath.Join(t.TempDir(), name)
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	commit := func(version string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, "plugin.md"), []byte(pluginMD(name, version)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", "-A")
+		git("commit", "-m", "v"+version)
+		git("tag", "v"+version)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, "scripts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "scripts", "run.sh"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-b", "main")
+	commit(version)
+	return repo, commit
+}
+
+func testInstaller(t *testing.T) *Installer {
+	t.Helper()
+	return &Installer{
+		TownRoot:  t.TempDir(),
+		GTVersion: "0.11.0",
+		LookPath:  func(string) (string, error) { return "", nil },
+	}
+}
+
+func TestInstallUpgradeRemove(t *testing.T) {
+	repo, commit := testPluginRepo(t, "lint", "1.0.0")
+	in := testInstaller(t)
+
+	res, err := in.Install(InstallOptions{Source: repo})
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	dir := filepath.Join(in.TownRoot, "plugins", "lint")
+	if res.Plugin.Path != dir || res.Entry.Version != "1.0.0" || res.Entry.Commit == "" || res.Entry.SourceType != SourceGit {
+		t.Errorf("Install result = %+v, entry %+v", res.Plugin, res.Entry)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); !os.IsNotExist(err) {
+		t.Error("installed plugin should not carry .git")
+	}
+	if info, err := os.Stat(filepath.Join(dir, "scripts", "run.sh")); err != nil || info.Mode()&0100 == 0 {
+		t.Errorf("script not installed executable: %v", err)
+	}
+	if _, err := in.Install(InstallOptions{Source: repo}); err == nil {
+		t.Error("second install should point at upgrade")
+	}
+
+	lock, err := LoadLock(in.TownRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := lock.Plugins["lint"]; e == nil || e.SHA256 != res.Entry.SHA256 {
+		t.Fatalf("lock = %+v", lock.Plugins)
+	}
+
+	commit("1.1.0")
+	res, err = in.Upgrade("lint", InstallOptions{})
+	if err != nil {
+		t.Fatalf("Upgrade: %v", err)
+	}
+	if res.Entry.Version != "1.1.0" || res.Previous.Version != "1.0.0" {
+		t.Errorf("Upgrade = %+v (previous %+v)", res.Entry, res.Previous)
+	}
+
+	if _, err := in.Upgrade("lint", InstallOptions{Ref: "v1.0.0"}); err == nil || !strings.Contains(err.Error(), "downgrade") {
+		t.Errorf("downgrade without --force: %v", err)
+	}
+	if res, err = in.Upgrade("lint", InstallOptions{Ref: "v1.0.0", Force: true}); err != nil || res.Entry.Version != "1.0.0" {
+		t.Errorf("forced downgrade: %v", err)
+	}
+
+	if _, err := in.Remove("lint", ""); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("plugin dir survived remove")
+	}
+	if lock, _ := LoadLock(in.TownRoot); len(lock.Plugins) != 0 {
+		t.Errorf("lock after remove = %+v", lock.Plugins)
+	}
+}
+
+func TestInstallChecks(t *testing.T) {
+	repo, _ := testPluginRepo(t, "lint", "1.0.0")
+	in := testInstaller(t)
+
+	if _, err := in.Install(InstallOptions{Source: repo, SHA256: strings.Repeat("0", 64)}); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("bad pin: %v", err)
+	}
+	if _, err := in.Install(InstallOptions{Source: repo, Rig: "nope"}); err == nil {
+		t.Error("install into a missing rig should fail")
+	}
+
+	// A hand-written plugin with the same name is not replaced silently.
+	if err := os.MkdirAll(filepath.Join(in.TownRoot, "plugins", "lint"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Install(InstallOptions{Source: repo}); err == nil || !strings.Contains(err.Error(), "not managed") {
+		t.Errorf("unmanaged plugin: %v", err)
+	}
+	if _, err := in.Install(InstallOptions{Source: repo, Force: true}); err != nil {
+		t.Errorf("forced install: %v", err)
+	}
+
+	tarball := filepath.Join(t.TempDir(), "new.tgz")
+	writeTestTarball(t, tarball, map[string]string{"plugin.md": "+++\nname = \"new\"\n[package]\nmin_gt_version = \"0.12.0\"\n+++\n"})
+	if _, err := in.Install(InstallOptions{Source: tarball}); err == nil || !strings.Contains(err.Error(), "needs gt >= 0.12.0") {
+		t.Errorf("too-old gt: %v", err)
+	}
+	if _, err := in.Install(InstallOptions{Source: tarball, Ref: "main"}); err == nil {
+		t.Error("--ref on a tarball should be rejected")
+	}
+}
+
+func TestGitCheckoutRefs(t *testing.T) {
+	repo, commit := testPluginRepo(t, "lint", "1.0.0")
+	commit("1.1.0")
+
+	for _, ref := range []string{"--upload-pack=touch /tmp/pwned", "-b"} {
+		if _, err := gitCheckout(repo, ref, filepath.Join(t.TempDir(), "c")); err == nil || !strings.Contains(err.Error(), "invalid ref") {
+			t.Errorf("gitCheckout(%q) = %v, want invalid ref", ref, err)
+		}
+	}
+	if _, err := gitCheckout(repo, "nope", filepath.Join(t.TempDir(), "c")); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("missing ref: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "c")
+	got, err := gitCheckout(repo, "v1.0.0", dir)
+	if err != nil {
+		t.Fatalf("gitCheckout(v1.0.0): %v", err)
+	}
+	want, _ := runGit(repo, "rev-parse", "v1.0.0^{commit}")
+	if got != want {
+		t.Errorf("gitCheckout(v1.0.0) = %s, want %s", got, want)
+	}
+
+	if out, err := runGit(repo, "branch", "stable", "v1.0.0"); err != nil {
+		t.Fatal(out)
+	}
+	if got, err := gitCheckout(repo, "stable", filepath.Join(t.TempDir(), "c")); err != nil || got != want {
+		t.Errorf("gitCheckout(stable) = %s, %v; want %s", got, err, want)
+	}
+}
+
+func TestInstallTarballAndSync(t *testing.T) {
+	in := testInstaller(t)
+	if err := os.MkdirAll(filepath.Join(in.TownRoot, "gastown"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tarball := filepath.Join(t.TempDir(), "lint.tar.gz")
+	writeTestTarball(t, tarball, map[string]string{
+		"lint-1.0.0/plugin.md": pluginMD("lint", "1.0.0"),
+		"lint-1.0.0/README.md": "hi\n",
+	})
+
+	res, err := in.Install(InstallOptions{Source: tarball, Rig: "gastown"})
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	dir := filepath.Join(in.TownRoot, "gastown", "plugins", "lint")
+	if res.Entry.SourceType != SourceTarball || res.Plugin.Location != LocationRig || res.Plugin.Path != dir {
+		t.Errorf("Install = %+v, %+v", res.Plugin, res.Entry)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "README.md")); err != nil {
+		t.Errorf("wrapped tarball not unpacked at the plugin root: %v", err)
+	}
+
+	// Drift: one plugin edited locally, then deleted entirely.
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := in.Status()
+	if err != nil || len(statuses) != 1 || statuses[0].Key != "gastown/lint" || statuses[0].State != LockModified {
+		t.Fatalf("Status = %+v, %v", statuses, err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if statuses, _ := in.Status(); statuses[0].State != LockMissing {
+		t.Errorf("Status after delete = %+v", statuses)
+	}
+
+	restored, err := in.Sync()
+	if err != nil || len(restored) != 1 {
+		t.Fatalf("Sync = %v, %v", restored, err)
+	}
+	if statuses, _ := in.Status(); statuses[0].State != LockOK {
+		t.Errorf("Status after sync = %+v", statuses)
+	}
+
+	// A source that no longer matches its pin is refused.
+	writeTestTarball(t, tarball, map[string]string{"plugin.md": pluginMD("lint", "1.0.1")})
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Sync(); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Sync against a changed tarball: %v", err)
+	}
+}
+
+func TestExtractTarballRejectsEscape(t *testing.T) {
+	tarball := filepath.Join(t.TempDir(), "evil.tar.gz")
+	writeTestTarball(t, tarball, map[string]string{"../escape.md": "x"})
+	if err := extractTarball(tarball, t.TempDir()); err == nil {
+		t.Error("expected a ../ entry to be rejected")
+	}
+}
+
+func TestTreeHash(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	for _, dir := range []string{a, b} {
+		if err := os.WriteFile(filepath.Join(dir, "plugin.md"), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(b, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(b, ".git", "HEAD"), []byte("ref"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ha, _ := TreeHash(a)
+	hb, _ := TreeHash(b)
+	if ha != hb {
+		t.Error(".git should not affect the hash")
+	}
+	if err := os.Chmod(filepath.Join(b, "plugin.md"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if hb, _ = TreeHash(b); ha == hb {
+		t.Error("executable bit should affect the hash")
+	}
+}
+
+func writeTestTarball(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
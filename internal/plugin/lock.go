@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// LockFile is the name of the plugin lockfile in the town's plugins directory.
+const LockFile = "plugins.lock.json"
+
+// lockVersion is the current lockfile format version.
+const lockVersion = 1
+
+// SourceType is where an installed plugin came from.
+type SourceType string
+
+const (
+	SourceGit     SourceType = "git"
+	SourceTarball SourceType = "tarball"
+)
+
+// Lock pins the installed version of every managed plugin in a town so that
+// every machine running the town installs identical plugin contents.
+type Lock struct {
+	Version int                   `json:"version"`
+	Plugins map[string]*LockEntry `json:"plugins"`
+}
+
+// LockEntry records one installed plugin.
+type LockEntry struct {
+	// Name is the plugin name; Rig is set for rig-level installs.
+	Name string `json:"name"`
+	Rig  string `json:"rig,omitempty"`
+
+	// Source is the git URL or tarball path the plugin was installed from.
+	Source     string     `json:"source"`
+	SourceType SourceType `json:"source_type"`
+
+	// Ref is the requested git ref; Commit is what it resolved to.
+	Ref    string `json:"ref,omitempty"`
+	Commit string `json:"commit,omitempty"`
+
+	// Version is the plugin's [package] version at install time.
+	Version string `json:"version,omitempty"`
+
+	// SHA256 is the content checksum of the installed plugin directory
+	// (see TreeHash).
+	SHA256 string `json:"sha256"`
+
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// LockKey returns the lockfile key for a plugin: its name, or "<rig>/<name>"
+// for rig-level plugins.
+func LockKey(rig, name string) string {
+	if rig == "" {
+		return name
+	}
+	return rig + "/" + name
+}
+
+// LockPath returns the path of the town's plugin lockfile.
+func LockPath(townRoot string) string {
+	return filepath.Join(townRoot, "plugins", LockFile)
+}
+
+// LoadLock reads the town's plugin lockfile. A missing file is an empty lock.
+func LoadLock(townRoot string) (*Lock, error) {
+	lock := &Lock{Version: lockVersion, Plugins: make(map[string]*LockEntry)}
+	data, err := os.ReadFile(LockPath(townRoot)) //nolint:gosec // G304: path is constructed from trusted town root
+	if os.IsNotExist(err) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading plugin lock: %w", err)
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("parsing plugin lock: %w", err)
+	}
+	if lock.Version > lockVersion {
+		return nil, fmt.Errorf("plugin lock version %d is newer than this gt supports (%d)", lock.Version, lockVersion)
+	}
+	if lock.Plugins == nil {
+		lock.Plugins = make(map[string]*LockEntry)
+	}
+	return lock, nil
+}
+
+// Save writes the lockfile atomically.
+func (l *Lock) Save(townRoot string) error {
+	l.Version = lockVersion
+	if err := os.MkdirAll(filepath.Dir(LockPath(townRoot)), 0755); err != nil {
+		return fmt.Errorf("creating plugins directory: %w", err)
+	}
+	return util.AtomicWriteJSON(LockPath(townRoot), l)
+}
+
+// Keys returns the lock's keys in sorted order.
+func (l *Lock) Keys() []string {
+	keys := make([]string, 0, len(l.Plugins))
+	for k := range l.Plugins {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,184 @@
+package plugin
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/deps"
+)
+
+// Package is the [package] section of plugin.md: release metadata used by
+// gt plugin install/upgrade.
+type Package struct {
+	// Version is the plugin release version (semver, e.g. "1.2.0").
+	Version string `json:"version,omitempty" toml:"version,omitempty"`
+
+	// MinGTVersion is the oldest gt release the plugin works with.
+	MinGTVersion string `json:"min_gt_version,omitempty" toml:"min_gt_version,omitempty"`
+
+	// Requires lists executables the plugin's instructions call (e.g. "gh").
+	Requires []string `json:"requires,omitempty" toml:"requires,omitempty"`
+}
+
+// ConfigType is the type of a plugin configuration value.
+type ConfigType string
+
+const (
+	ConfigString   ConfigType = "string"
+	ConfigInt      ConfigType = "int"
+	ConfigBool     ConfigType = "bool"
+	ConfigDuration ConfigType = "duration"
+	ConfigList     ConfigType = "list" // comma-separated strings
+)
+
+// ConfigField declares one configuration value in a [config.<key>] section.
+type ConfigField struct {
+	// Type is the value type. Default: string.
+	Type ConfigType `json:"type,omitempty" toml:"type,omitempty"`
+
+	// Description says what the value is for.
+	Description string `json:"description,omitempty" toml:"description,omitempty"`
+
+	// Required values must be set before the plugin can run.
+	Required bool `json:"required,omitempty" toml:"required,omitempty"`
+
+	// Default is used when no value is set.
+	Default string `json:"default,omitempty" toml:"default,omitempty"`
+
+	// Secret values are kept in the local encrypted secrets store instead
+	// of plugins/config.json, and are never printed.
+	Secret bool `json:"secret,omitempty" toml:"secret,omitempty"`
+
+	// Env is the environment variable the dog sees the value in.
+	// Default: GT_PLUGIN_<KEY>.
+	Env string `json:"env,omitempty" toml:"env,omitempty"`
+}
+
+// semverPattern matches the X.Y.Z versions gt compares.
+var semverPattern = regexp.MustCompile(`^v?\d+(\.\d+){0,2}$`)
+
+// envNamePattern matches portable environment variable names.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// PackageVersion returns the plugin's release version, or "".
+func (p *Plugin) PackageVersion() string {
+	if p.Package == nil {
+		return ""
+	}
+	return p.Package.Version
+}
+
+// CheckRequirements reports why the plugin can't run on this machine: a gt
+// older than MinGTVersion, or missing required tools. gtVersion may be empty
+// to skip the version check; lookPath is usually exec.LookPath.
+func (p *Plugin) CheckRequirements(gtVersion string, lookPath func(string) (string, error)) []string {
+	if p.Package == nil {
+		return nil
+	}
+	var problems []string
+	if min := p.Package.MinGTVersion; min != "" && gtVersion != "" {
+		if deps.CompareVersions(trimV(gtVersion), trimV(min)) < 0 {
+			problems = append(problems, fmt.Sprintf("needs gt >= %s (have %s)", min, gtVersion))
+		}
+	}
+	for _, tool := range p.Package.Requires {
+		if _, err := lookPath(tool); err != nil {
+			problems = append(problems, fmt.Sprintf("needs %s on PATH", tool))
+		}
+	}
+	return problems
+}
+
+// Validate checks the package metadata.
+func (pkg *Package) Validate() error {
+	if pkg == nil {
+		return nil
+	}
+	for field, v := range map[string]string{"version": pkg.Version, "min_gt_version": pkg.MinGTVersion} {
+		if v != "" && !semverPattern.MatchString(v) {
+			return fmt.Errorf("%s %q is not X.Y.Z", field, v)
+		}
+	}
+	return nil
+}
+
+// Validate checks the field's type and default.
+func (f *ConfigField) Validate() error {
+	if f == nil {
+		return fmt.Errorf("empty declaration")
+	}
+	if f.Env != "" && !envNamePattern.MatchString(f.Env) {
+		return fmt.Errorf("env %q is not a valid variable name", f.Env)
+	}
+	if f.Secret && f.Default != "" {
+		return fmt.Errorf("secret values can't have a default")
+	}
+	if f.Default == "" {
+		switch f.Type {
+		case "", ConfigString, ConfigInt, ConfigBool, ConfigDuration, ConfigList:
+			return nil
+		}
+	}
+	if err := f.Check(f.Default); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+	return nil
+}
+
+// Check reports whether value is valid for the field's type.
+func (f *ConfigField) Check(value string) error {
+	switch f.Type {
+	case "", ConfigString, ConfigList:
+		return nil
+	case ConfigInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+	case ConfigBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+	case ConfigDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("%q is not a duration", value)
+		}
+	default:
+		return fmt.Errorf("unknown type %q (want string, int, bool, duration or list)", f.Type)
+	}
+	return nil
+}
+
+// EnvName returns the environment variable key's value is injected as.
+func (f *ConfigField) EnvName(key string) string {
+	if f.Env != "" {
+		return f.Env
+	}
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+	return "GT_PLUGIN_" + name
+}
+
+// ConfigKeys returns the plugin's config keys in sorted order.
+func (p *Plugin) ConfigKeys() []string {
+	keys := make([]string, 0, len(p.Config))
+	for k := range p.Config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func trimV(v string) string {
+	return strings.TrimPrefix(v, "v")
+}
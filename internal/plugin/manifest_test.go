@@ -0,0 +1,52 @@
+package plugin
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheckRequirements(t *testing.T) {
+	p := &Plugin{Package: &Package{MinGTVersion: "0.12.0", Requires: []string{"git", "gh"}}}
+	lookPath := func(name string) (string, error) {
+		if name == "git" {
+			return "/usr/bin/git", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	got := p.CheckRequirements("0.11.0", lookPath)
+	want := []string{"needs gt >= 0.12.0 (have 0.11.0)", "needs gh on PATH"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckRequirements = %v, want %v", got, want)
+	}
+	if got := p.CheckRequirements("v0.12.1", func(string) (string, error) { return "", nil }); got != nil {
+		t.Errorf("satisfied requirements reported %v", got)
+	}
+	if got := (&Plugin{}).CheckRequirements("0.1.0", lookPath); got != nil {
+		t.Errorf("plugin without [package] reported %v", got)
+	}
+}
+
+func TestConfigFieldCheck(t *testing.T) {
+	tests := []struct {
+		typ   ConfigType
+		value string
+		ok    bool
+	}{
+		{"", "anything", true},
+		{ConfigList, "a,b", true},
+		{ConfigInt, "42", true},
+		{ConfigInt, "4.2", false},
+		{ConfigBool, "true", true},
+		{ConfigBool, "yes", false},
+		{ConfigDuration, "90s", true},
+		{ConfigDuration, "90", false},
+	}
+	for _, tt := range tests {
+		err := (&ConfigField{Type: tt.typ}).Check(tt.value)
+		if (err == nil) != tt.ok {
+			t.Errorf("Check(%s, %q) = %v, want ok=%v", tt.typ, tt.value, err, tt.ok)
+		}
+	}
+}
@@ -173,6 +173,17 @@ func parsePluginMD(content []byte, pluginDir string, location Location, rigName
 	if fm.Name == "" {
 		return nil, fmt.Errorf("missing required field: name")
 	}
+	if err := fm.Gate.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid gate: %w", err)
+	}
+	if err := fm.Package.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid package: %w", err)
+	}
+	for key, field := range fm.Config {
+		if err := field.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid config %q: %w", key, err)
+		}
+	}
 
 	plugin := &Plugin{
 		Name:         fm.Name,
@@ -184,6 +195,8 @@ func parsePluginMD(content []byte, pluginDir string, location Location, rigName
 		Gate:         fm.Gate,
 		Tracking:     fm.Tracking,
 		Execution:    fm.Execution,
+		Package:      fm.Package,
+		Config:       fm.Config,
 		Instructions: body,
 	}
 
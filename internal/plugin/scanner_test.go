@@ -509,3 +509,74 @@ version = 1
 		t.Errorf("expected location 'rig', got %q", plugins[0].Location)
 	}
 }
+
+func TestParsePluginMD_EventGate(t *testing.T) {
+	content := []byte(`+++
+name = "ci-watch"
+
+[gate]
+type = "event"
+on = "bead.closed"
+rig = "gastown"
+label = "ci-*"
+duration = "10m"
++++
+
+Look at the closed bead.
+`)
+	p, err := parsePluginMD(content, "/test/ci-watch", LocationTown, "")
+	if err != nil {
+		t.Fatalf("parsePluginMD failed: %v", err)
+	}
+	if p.Gate.On != EventBeadClosed || p.Gate.Rig != "gastown" || p.Gate.Label != "ci-*" {
+		t.Errorf("gate = %+v", p.Gate)
+	}
+
+	bad := []byte("+++\nname = \"x\"\n[gate]\ntype = \"event\"\non = \"tuesday\"\n+++\n")
+	if _, err := parsePluginMD(bad, "/test/x", LocationTown, ""); err == nil {
+		t.Error("expected an unknown event kind to be rejected")
+	}
+}
+
+func TestParsePluginMD_Package(t *testing.T) {
+	content := []byte(`+++
+name = "lint"
+
+[package]
+version = "1.2.0"
+min_gt_version = "0.9.0"
+requires = ["gh"]
+
+[config.max_files]
+type = "int"
+default = "50"
+description = "Files to lint per run"
+
+[config.token]
+required = true
++++
+
+Lint things.
+`)
+	p, err := parsePluginMD(content, "/test/lint", LocationTown, "")
+	if err != nil {
+		t.Fatalf("parsePluginMD failed: %v", err)
+	}
+	if p.PackageVersion() != "1.2.0" || p.Package.MinGTVersion != "0.9.0" {
+		t.Errorf("package = %+v", p.Package)
+	}
+	if keys := p.ConfigKeys(); len(keys) != 2 || keys[0] != "max_files" || !p.Config["token"].Required {
+		t.Errorf("config = %v", keys)
+	}
+
+	for _, bad := range []string{
+		"[package]\nversion = \"one\"",
+		"[config.n]\ntype = \"int\"\ndefault = \"many\"",
+		"[config.n]\ntype = \"float\"",
+	} {
+		content := []byte("+++\nname = \"x\"\n" + bad + "\n+++\n")
+		if _, err := parsePluginMD(content, "/test/x", LocationTown, ""); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
@@ -24,6 +24,12 @@ type Plugin struct {
 	// Version is the schema version (for future evolution).
 	Version int `json:"version"`
 
+	// Package holds release metadata for installable plugins.
+	Package *Package `json:"package,omitempty"`
+
+	// Config declares the configuration values the plugin accepts.
+	Config map[string]*ConfigField `json:"config,omitempty"`
+
 	// Location indicates where the plugin was discovered.
 	Location Location `json:"location"`
 
@@ -71,8 +77,18 @@ type Gate struct {
 	// Check is for condition gates (command that returns exit 0 to run).
 	Check string `json:"check,omitempty" toml:"check,omitempty"`
 
-	// On is for event gates (e.g., "startup").
+	// On is for event gates: "startup" or a town event kind such as
+	// "bead.closed" or "mr.merged" (see the Event* constants).
 	On string `json:"on,omitempty" toml:"on,omitempty"`
+
+	// Rig, Label, Actor and Branch narrow an event gate to matching events.
+	// Each is a glob (path.Match syntax); empty matches anything. For
+	// "commits" gates Branch names the watched branch (default: the rig's
+	// default branch).
+	Rig    string `json:"rig,omitempty" toml:"rig,omitempty"`
+	Label  string `json:"label,omitempty" toml:"label,omitempty"`
+	Actor  string `json:"actor,omitempty" toml:"actor,omitempty"`
+	Branch string `json:"branch,omitempty" toml:"branch,omitempty"`
 }
 
 // GateType is the type of gate that controls plugin execution.
@@ -88,7 +104,7 @@ const (
 	// GateCondition runs if a check command returns exit 0.
 	GateCondition GateType = "condition"
 
-	// GateEvent runs on specific events (startup, etc).
+	// GateEvent runs on specific events (startup, bead closes, merges, etc).
 	GateEvent GateType = "event"
 
 	// GateManual never auto-runs, must be triggered explicitly.
@@ -124,16 +140,20 @@ type PluginFrontmatter struct {
 	Gate        *Gate      `toml:"gate,omitempty"`
 	Tracking    *Tracking  `toml:"tracking,omitempty"`
 	Execution   *Execution `toml:"execution,omitempty"`
+	Package     *Package   `toml:"package,omitempty"`
+
+	Config map[string]*ConfigField `toml:"config,omitempty"`
 }
 
 // PluginSummary provides a concise overview of a plugin.
 type PluginSummary struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Location    Location `json:"location"`
-	RigName     string   `json:"rig_name,omitempty"`
-	GateType    GateType `json:"gate_type,omitempty"`
-	Path        string   `json:"path"`
+	Name           string   `json:"name"`
+	Description    string   `json:"description"`
+	Location       Location `json:"location"`
+	RigName        string   `json:"rig_name,omitempty"`
+	GateType       GateType `json:"gate_type,omitempty"`
+	PackageVersion string   `json:"package_version,omitempty"`
+	Path           string   `json:"path"`
 }
 
 // Summary returns a PluginSummary for this plugin.
@@ -146,12 +166,13 @@ func (p *Plugin) Summary() PluginSummary {
 	}
 
 	return PluginSummary{
-		Name:        p.Name,
-		Description: p.Description,
-		Location:    p.Location,
-		RigName:     p.RigName,
-		GateType:    gateType,
-		Path:        p.Path,
+		Name:           p.Name,
+		Description:    p.Description,
+		Location:       p.Location,
+		RigName:        p.RigName,
+		GateType:       gateType,
+		PackageVersion: p.PackageVersion(),
+		Path:           p.Path,
 	}
 }
 
@@ -159,6 +180,13 @@ func (p *Plugin) Summary() PluginSummary {
 // This is the canonical formatting used by both the daemon dispatcher
 // and the gt dog dispatch command.
 func (p *Plugin) FormatMailBody() string {
+	return p.FormatTriggeredMailBody(nil)
+}
+
+// FormatTriggeredMailBody formats the plugin instructions with a Trigger
+// section listing the events that opened its event gate. With no triggers
+// it is identical to FormatMailBody.
+func (p *Plugin) FormatTriggeredMailBody(triggers []Event) string {
 	var sb strings.Builder
 
 	sb.WriteString("Execute the following plugin:\n\n")
@@ -171,6 +199,29 @@ func (p *Plugin) FormatMailBody() string {
 		sb.WriteString(fmt.Sprintf("**Timeout**: %s\n", p.Execution.Timeout))
 	}
 	sb.WriteString("\n---\n\n")
+	if len(triggers) > 0 {
+		sb.WriteString("## Trigger\n\n")
+		for _, e := range triggers {
+			sb.WriteString(fmt.Sprintf("- %s\n", e))
+		}
+		sb.WriteString("\n")
+	}
+	if len(p.Config) > 0 {
+		sb.WriteString("## Config\n\n")
+		sb.WriteString("Configured values are in your environment (never echo secrets):\n\n")
+		for _, key := range p.ConfigKeys() {
+			f := p.Config[key]
+			line := fmt.Sprintf("- `$%s` (%s)", f.EnvName(key), key)
+			if f.Secret {
+				line += " secret"
+			}
+			if f.Description != "" {
+				line += ": " + f.Description
+			}
+			sb.WriteString(line + "\n")
+		}
+		sb.WriteString("\n")
+	}
 	sb.WriteString("## Instructions\n\n")
 	sb.WriteString(p.Instructions)
 	sb.WriteString("\n\n---\n\n")
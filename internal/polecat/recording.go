@@ -0,0 +1,50 @@
+package polecat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/recording"
+)
+
+// recordingEnabled reports whether the rig has opted into session recording.
+func (m *SessionManager) recordingEnabled() bool {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(m.rig.Path))
+	if err != nil || settings == nil || settings.Recording == nil {
+		return false
+	}
+	return settings.Recording.Enabled
+}
+
+// startRecording pipes the polecat's pane into "gt session record" when the
+// rig has recording enabled. Failures are non-fatal: the session works the
+// same without a recording.
+func (m *SessionManager) startRecording(sessionID, polecat, townRoot string) error {
+	if !m.recordingEnabled() {
+		return nil
+	}
+	path := recording.PathFor(townRoot, m.rig.Name, polecat, time.Now())
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating recordings dir: %w", err)
+	}
+	width, height, err := m.tmux.GetPaneSize(sessionID)
+	if err != nil {
+		width, height = 0, 0 // recorder falls back to a default size
+	}
+	title := fmt.Sprintf("%s/polecats/%s", m.rig.Name, polecat)
+	return m.tmux.PipePane(sessionID, RecordCommand(path, width, height, title))
+}
+
+// RecordCommand returns the shell command tmux pipe-pane runs to append a
+// pane's output to the asciicast file at path.
+func RecordCommand(path string, width, height int, title string) string {
+	cmd := "exec gt session record --output " + config.ShellQuote(path) +
+		" --title " + config.ShellQuote(title)
+	if width > 0 && height > 0 {
+		cmd += fmt.Sprintf(" --width %d --height %d", width, height)
+	}
+	return cmd
+}
@@ -0,0 +1,35 @@
+package polecat
+
+import (
+	"runtime"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/sandbox"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// sandboxConfig returns the rig's sandbox settings, or nil when the rig
+// hasn't opted into sandboxing.
+func (m *SessionManager) sandboxConfig() *config.SandboxConfig {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(m.rig.Path))
+	if err != nil || settings == nil || settings.Sandbox == nil || !settings.Sandbox.Enabled {
+		return nil
+	}
+	return settings.Sandbox
+}
+
+// wrapSandbox wraps the polecat's startup command in "gt sandbox exec" when
+// the rig has sandboxing enabled. The runtime config dir (if any) stays
+// writable so the agent can keep its session state.
+func (m *SessionManager) wrapSandbox(command, polecat, workDir, townRoot, runtimeConfigDir string) string {
+	cfg := m.sandboxConfig()
+	if cfg == nil {
+		return command
+	}
+	if runtime.GOOS != "linux" {
+		style.PrintWarning("sandbox is enabled for rig %s but only supported on Linux; starting %s unsandboxed", m.rig.Name, polecat)
+		return command
+	}
+	spec := sandbox.SpecFromConfig(cfg, townRoot, m.rig.Name, polecat, workDir, runtimeConfigDir)
+	return sandbox.WrapCommand(spec, command)
+}
@@ -43,13 +43,20 @@ var (
 type SessionManager struct {
 	tmux *tmux.Tmux
 	rig  *rig.Rig
+
+	// backend is tmux, or the PTY supervisor client when the town is headless.
+	backend  session.SessionBackend
+	headless bool
 }
 
 // NewSessionManager creates a new polecat session manager for a rig.
 func NewSessionManager(t *tmux.Tmux, r *rig.Rig) *SessionManager {
+	townRoot := filepath.Dir(r.Path)
 	return &SessionManager{
-		tmux: t,
-		rig:  r,
+		tmux:     t,
+		rig:      r,
+		backend:  session.NewBackend(townRoot, t),
+		headless: session.IsHeadless(townRoot),
 	}
 }
 
@@ -216,12 +223,12 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	// Check if session already exists.
 	// If an existing session's pane process has died, kill the stale session
 	// and proceed rather than returning ErrSessionRunning (gt-jn40ft).
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
 	if running {
-		if m.isSessionStale(sessionID) {
+		if !m.headless && m.isSessionStale(sessionID) {
 			if err := m.tmux.KillSessionWithProcesses(sessionID); err != nil {
 				return fmt.Errorf("killing stale session %s: %w", sessionID, err)
 			}
@@ -343,6 +350,11 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 		envVarsToInject["GT_BRANCH"] = polecatGitBranch
 	}
 	command = config.PrependEnv(command, envVarsToInject)
+	command = m.wrapSandbox(command, polecat, workDir, townRoot, opts.RuntimeConfigDir)
+
+	if m.headless {
+		return m.startHeadless(polecat, sessionID, workDir, command, beacon, runID, envVarsToInject, runtimeConfig, fallbackInfo, opts)
+	}
 
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/gastown/issues/280
@@ -420,6 +432,9 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	agentID := fmt.Sprintf("%s/%s", m.rig.Name, polecat)
 	debugSession("SetPaneDiedHook", m.tmux.SetPaneDiedHook(sessionID, agentID))
 
+	// Record the pane when the rig has opted in (non-fatal)
+	debugSession("StartRecording", m.startRecording(sessionID, polecat, townRoot))
+
 	// Wait for Claude to start (non-fatal)
 	debugSession("WaitForCommand", m.tmux.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout))
 
@@ -432,29 +447,9 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	debugSession("WaitForRuntimeReady", m.tmux.WaitForRuntimeReady(sessionID, runtimeConfig, constants.ClaudeStartTimeout))
 
 	// Handle fallback nudges for non-hook agents.
-	// See StartupFallbackInfo in runtime package for the fallback matrix.
-	if fallbackInfo.SendBeaconNudge && fallbackInfo.SendStartupNudge && fallbackInfo.StartupNudgeDelayMs == 0 {
-		// Hooks + no prompt: Single combined nudge (hook already ran gt prime synchronously)
-		combined := beacon + "\n\n" + runtime.StartupNudgeContent()
-		debugSession("SendCombinedNudge", m.tmux.NudgeSession(sessionID, combined))
-	} else {
-		if fallbackInfo.SendBeaconNudge {
-			// Agent doesn't support CLI prompt - send beacon via nudge
-			debugSession("SendBeaconNudge", m.tmux.NudgeSession(sessionID, beacon))
-		}
-
-		if fallbackInfo.StartupNudgeDelayMs > 0 {
-			// Wait for agent to finish processing beacon + gt prime before sending work instructions.
-			// Uses prompt-based detection where available; falls back to max(ReadyDelayMs, StartupNudgeDelayMs).
-			primeWaitRC := runtime.RuntimeConfigWithMinDelay(runtimeConfig, fallbackInfo.StartupNudgeDelayMs)
-			debugSession("WaitForPrimeReady", m.tmux.WaitForRuntimeReady(sessionID, primeWaitRC, constants.ClaudeStartTimeout))
-		}
-
-		if fallbackInfo.SendStartupNudge {
-			// Send work instructions via nudge
-			debugSession("SendStartupNudge", m.tmux.NudgeSession(sessionID, runtime.StartupNudgeContent()))
-		}
-	}
+	m.sendStartupNudges(sessionID, beacon, runtimeConfig, fallbackInfo, func(rc *config.RuntimeConfig) error {
+		return m.tmux.WaitForRuntimeReady(sessionID, rc, constants.ClaudeStartTimeout)
+	})
 
 	// Verify startup nudge was delivered: poll for idle prompt and retry if lost.
 	// This fixes the Mode B race where the nudge arrives before Claude Code is ready,
@@ -510,6 +505,90 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	return nil
 }
 
+// startHeadless finishes Start for towns on the PTY backend. The session
+// environment is passed at creation, and tmux-only steps (theme, pane-died
+// hook, recording, pane ID, PID tracking) are skipped: the supervisor owns
+// the process group and drops the session when the agent exits.
+func (m *SessionManager) startHeadless(polecat, sessionID, workDir, command, beacon, runID string, env map[string]string, runtimeConfig *config.RuntimeConfig, fallbackInfo *runtime.StartupFallbackInfo, opts SessionStartOptions) error {
+	townRoot := filepath.Dir(m.rig.Path)
+	for k, v := range config.AgentEnv(config.AgentEnvConfig{
+		Role:             "polecat",
+		Rig:              m.rig.Name,
+		AgentName:        polecat,
+		TownRoot:         townRoot,
+		RuntimeConfigDir: opts.RuntimeConfigDir,
+		Agent:            opts.Agent,
+		SessionName:      sessionID,
+	}) {
+		env[k] = v
+	}
+	env = session.MergeRuntimeLivenessEnv(env, runtimeConfig)
+	env["BD_DOLT_AUTO_COMMIT"] = "off"
+	if env["GT_AGENT"] == "" {
+		return fmt.Errorf("GT_AGENT not resolved for session %s (command=%q)", sessionID, runtimeConfig.Command)
+	}
+
+	if err := m.backend.NewSessionWithCommandAndEnv(sessionID, workDir, command, env); err != nil {
+		return fmt.Errorf("creating session: %w", err)
+	}
+
+	if opts.Issue != "" {
+		agentID := fmt.Sprintf("%s/polecats/%s", m.rig.Name, polecat)
+		if err := m.hookIssue(opts.Issue, agentID, workDir); err != nil {
+			style.PrintWarning("could not hook issue %s: %v", opts.Issue, err)
+		}
+	}
+
+	session.AcceptHeadlessDialogs(m.backend, sessionID)
+	debugSession("WaitForHeadlessReady", session.WaitForHeadlessReady(m.backend, sessionID, runtimeConfig, constants.ClaudeStartTimeout))
+	m.sendStartupNudges(sessionID, beacon, runtimeConfig, fallbackInfo, func(rc *config.RuntimeConfig) error {
+		return session.WaitForHeadlessReady(m.backend, sessionID, rc, constants.ClaudeStartTimeout)
+	})
+
+	running, err := m.backend.HasSession(sessionID)
+	if err != nil {
+		return fmt.Errorf("verifying session: %w", err)
+	}
+	if !running {
+		return fmt.Errorf("session %s died during startup (agent command may have failed)", sessionID)
+	}
+
+	TouchSessionHeartbeat(townRoot, sessionID)
+	session.RecordAgentInstantiateFromDir(context.Background(), runID, runtimeConfig.ResolvedAgent,
+		"polecat", polecat, sessionID, m.rig.Name, townRoot, opts.Issue, workDir)
+	return nil
+}
+
+// sendStartupNudges delivers the beacon and work instructions to agents that
+// can't take them on the command line. waitReady blocks until the agent is
+// back at its prompt. See StartupFallbackInfo in runtime package for the
+// fallback matrix.
+func (m *SessionManager) sendStartupNudges(sessionID, beacon string, runtimeConfig *config.RuntimeConfig, fallbackInfo *runtime.StartupFallbackInfo, waitReady func(*config.RuntimeConfig) error) {
+	if fallbackInfo.SendBeaconNudge && fallbackInfo.SendStartupNudge && fallbackInfo.StartupNudgeDelayMs == 0 {
+		// Hooks + no prompt: Single combined nudge (hook already ran gt prime synchronously)
+		combined := beacon + "\n\n" + runtime.StartupNudgeContent()
+		debugSession("SendCombinedNudge", m.backend.NudgeSession(sessionID, combined))
+		return
+	}
+
+	if fallbackInfo.SendBeaconNudge {
+		// Agent doesn't support CLI prompt - send beacon via nudge
+		debugSession("SendBeaconNudge", m.backend.NudgeSession(sessionID, beacon))
+	}
+
+	if fallbackInfo.StartupNudgeDelayMs > 0 {
+		// Wait for agent to finish processing beacon + gt prime before sending work instructions.
+		// Uses prompt-based detection where available; falls back to max(ReadyDelayMs, StartupNudgeDelayMs).
+		primeWaitRC := runtime.RuntimeConfigWithMinDelay(runtimeConfig, fallbackInfo.StartupNudgeDelayMs)
+		debugSession("WaitForPrimeReady", waitReady(primeWaitRC))
+	}
+
+	if fallbackInfo.SendStartupNudge {
+		// Send work instructions via nudge
+		debugSession("SendStartupNudge", m.backend.NudgeSession(sessionID, runtime.StartupNudgeContent()))
+	}
+}
+
 // isSessionStale checks if a tmux session's pane process has died.
 // A stale session exists in tmux but its main process (the agent) is no longer running.
 // This happens when the agent crashes during startup but tmux keeps the dead pane.
@@ -522,7 +601,7 @@ func (m *SessionManager) isSessionStale(sessionID string) bool {
 func (m *SessionManager) Stop(polecat string, force bool) error {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -532,13 +611,13 @@ func (m *SessionManager) Stop(polecat string, force bool) error {
 
 	// Try graceful shutdown first
 	if !force {
-		_ = m.tmux.SendKeysRaw(sessionID, "C-c")
-		session.WaitForSessionExit(m.tmux, sessionID, constants.GracefulShutdownTimeout)
+		_ = m.backend.SendKeysRaw(sessionID, "C-c")
+		session.WaitForSessionExit(m.backend, sessionID, constants.GracefulShutdownTimeout)
 	}
 
 	// Use KillSessionWithProcesses to ensure all descendant processes are killed.
 	// This prevents orphan bash processes from Claude's Bash tool surviving session termination.
-	if err := m.tmux.KillSessionWithProcesses(sessionID); err != nil {
+	if err := m.backend.KillSessionWithProcesses(sessionID); err != nil {
 		return fmt.Errorf("killing session: %w", err)
 	}
 
@@ -550,6 +629,10 @@ func (m *SessionManager) Stop(polecat string, force bool) error {
 // reporting zombie sessions (tmux alive but Claude dead) as "running".
 func (m *SessionManager) IsRunning(polecat string) (bool, error) {
 	sessionID := m.SessionName(polecat)
+	if m.headless {
+		// The supervisor drops sessions whose agent exited.
+		return m.backend.HasSession(sessionID)
+	}
 	status := m.tmux.CheckSessionHealth(sessionID, 0)
 	return status == tmux.SessionHealthy, nil
 }
@@ -558,7 +641,7 @@ func (m *SessionManager) IsRunning(polecat string) (bool, error) {
 func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("checking session: %w", err)
 	}
@@ -570,7 +653,7 @@ func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 		RigName:   m.rig.Name,
 	}
 
-	if !running {
+	if !running || m.headless {
 		return info, nil
 	}
 
@@ -612,7 +695,7 @@ func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 // This includes polecats, witness, refinery, and crew sessions.
 // Use ListPolecats() to get only polecat sessions.
 func (m *SessionManager) List() ([]SessionInfo, error) {
-	sessions, err := m.tmux.ListSessions()
+	sessions, err := m.backend.ListSessions()
 	if err != nil {
 		return nil, err
 	}
@@ -661,7 +744,7 @@ func (m *SessionManager) ListPolecats() ([]SessionInfo, error) {
 func (m *SessionManager) Attach(polecat string) error {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -669,14 +752,14 @@ func (m *SessionManager) Attach(polecat string) error {
 		return ErrSessionNotFound
 	}
 
-	return m.tmux.AttachSession(sessionID)
+	return m.backend.AttachSession(sessionID)
 }
 
 // Capture returns the recent output from a polecat session.
 func (m *SessionManager) Capture(polecat string, lines int) (string, error) {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return "", fmt.Errorf("checking session: %w", err)
 	}
@@ -684,12 +767,12 @@ func (m *SessionManager) Capture(polecat string, lines int) (string, error) {
 		return "", ErrSessionNotFound
 	}
 
-	return m.tmux.CapturePane(sessionID, lines)
+	return m.backend.CapturePane(sessionID, lines)
 }
 
 // CaptureSession returns the recent output from a session by raw session ID.
 func (m *SessionManager) CaptureSession(sessionID string, lines int) (string, error) {
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return "", fmt.Errorf("checking session: %w", err)
 	}
@@ -697,14 +780,14 @@ func (m *SessionManager) CaptureSession(sessionID string, lines int) (string, er
 		return "", ErrSessionNotFound
 	}
 
-	return m.tmux.CapturePane(sessionID, lines)
+	return m.backend.CapturePane(sessionID, lines)
 }
 
 // Inject sends a message to a polecat session.
 func (m *SessionManager) Inject(polecat, message string) error {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -712,6 +795,10 @@ func (m *SessionManager) Inject(polecat, message string) error {
 		return ErrSessionNotFound
 	}
 
+	if m.headless {
+		return m.backend.NudgeSession(sessionID, message)
+	}
+
 	debounceMs := 200 + (len(message)/1024)*100
 	if debounceMs > 1500 {
 		debounceMs = 1500
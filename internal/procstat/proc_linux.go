@@ -0,0 +1,129 @@
+//go:build linux
+
+package procstat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported reports whether process trees can be sampled on this platform.
+func Supported() bool { return true }
+
+// ReadTable snapshots every process visible in /proc. Processes that exit
+// while the snapshot is taken are skipped.
+func ReadTable() (*Table, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	pageKB := int64(os.Getpagesize() / 1024)
+	procs := make([]Proc, 0, len(entries))
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join("/proc", e.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		p, err := parseStat(string(data), pageKB)
+		if err != nil || p.PID != pid {
+			continue
+		}
+		procs = append(procs, p)
+	}
+	return NewTable(procs, countOpenFiles), nil
+}
+
+// ProcessCPU returns the user+system CPU time a running process and its
+// reaped children have used so far, the live counterpart of the rusage a
+// Wait reports.
+func ProcessCPU(pid int) (time.Duration, error) {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0, err
+	}
+	p, err := parseStat(string(data), 0)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(p.CPUTicks) * time.Second / clockTicks, nil
+}
+
+// parseStat parses the content of /proc/<pid>/stat. The command name is
+// parenthesized and may itself contain spaces and parentheses, so fields
+// are counted from the last ')'.
+func parseStat(content string, pageKB int64) (Proc, error) {
+	open := strings.IndexByte(content, '(')
+	end := strings.LastIndexByte(content, ')')
+	if open < 0 || end < open {
+		return Proc{}, fmt.Errorf("malformed stat: %q", content)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(content[:open]))
+	if err != nil {
+		return Proc{}, fmt.Errorf("malformed stat pid: %w", err)
+	}
+	// fields[0] is the state (field 3 in proc(5)).
+	fields := strings.Fields(content[end+1:])
+	if len(fields) < 22 {
+		return Proc{}, fmt.Errorf("malformed stat: %d fields", len(fields))
+	}
+	num := func(i int) uint64 {
+		n, _ := strconv.ParseUint(fields[i], 10, 64)
+		return n
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	return Proc{
+		PID:      pid,
+		PPID:     ppid,
+		CPUTicks: num(11) + num(12) + num(13) + num(14), // utime stime cutime cstime
+		Threads:  int(num(17)),
+		RSSKB:    int64(num(21)) * pageKB,
+	}, nil
+}
+
+// countOpenFiles counts a process's open descriptors. Processes of other
+// users can't be inspected and count as zero.
+func countOpenFiles(pid int) int {
+	entries, err := os.ReadDir(filepath.Join("/proc", strconv.Itoa(pid), "fd"))
+	if err != nil {
+		return 0
+	}
+	return len(entries)
+}
+
+// AncestorEnv walks up from the calling process's parent and returns the
+// first ancestor whose environment sets one of keys, and the key it set.
+// A process can scrub its own environment but not its parents', so this
+// tells whether a command was started from inside an agent session.
+// Ancestors whose environment can't be read are skipped.
+func AncestorEnv(keys ...string) (pid int, key string) {
+	for pid, depth := os.Getppid(), 0; pid > 1 && depth < 64; depth++ {
+		dir := filepath.Join("/proc", strconv.Itoa(pid))
+		if data, err := os.ReadFile(filepath.Join(dir, "environ")); err == nil {
+			for _, kv := range strings.Split(string(data), "\x00") {
+				for _, k := range keys {
+					if v, ok := strings.CutPrefix(kv, k+"="); ok && v != "" {
+						return pid, k
+					}
+				}
+			}
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "stat"))
+		if err != nil {
+			break
+		}
+		p, err := parseStat(string(data), 1)
+		if err != nil {
+			break
+		}
+		pid = p.PPID
+	}
+	return 0, ""
+}
@@ -0,0 +1,82 @@
+//go:build linux
+
+package procstat
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestParseStat(t *testing.T) {
+	// The comm field may hold spaces and parentheses.
+	line := "4242 (tmux: (server) x) S 1 4242 4242 0 -1 4194560 1234 0 0 0 " +
+		"700 300 40 60 20 0 3 0 123456 10485760 2560 18446744073709551615 " +
+		"1 1 0 0 0 0 0 0 0 0 0 0 17 2 0 0 0 0 0\n"
+	p, err := parseStat(line, 4)
+	if err != nil {
+		t.Fatalf("parseStat: %v", err)
+	}
+	want := Proc{PID: 4242, PPID: 1, Threads: 3, RSSKB: 2560 * 4, CPUTicks: 700 + 300 + 40 + 60}
+	if p != want {
+		t.Errorf("parseStat = %+v, want %+v", p, want)
+	}
+
+	for _, bad := range []string{"", "12 (x", "12 (x) S 1"} {
+		if _, err := parseStat(bad, 4); err == nil {
+			t.Errorf("parseStat(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestReadTableSelf(t *testing.T) {
+	table, err := ReadTable()
+	if err != nil {
+		t.Fatalf("ReadTable: %v", err)
+	}
+	u := table.Tree(os.Getpid())
+	if u.Procs < 1 || u.RSSKB <= 0 || u.Threads < 1 || u.OpenFiles < 1 {
+		t.Errorf("Tree(self) = %+v, want a live process", u)
+	}
+}
+
+func TestProcessCPU(t *testing.T) {
+	// Burn a few ticks so the reading is non-zero.
+	deadline := time.Now().Add(50 * time.Millisecond)
+	for n := 0; time.Now().Before(deadline); n++ {
+		_ = n * n
+	}
+	if cpu, err := ProcessCPU(os.Getpid()); err != nil || cpu <= 0 {
+		t.Errorf("ProcessCPU(self) = %v, %v; want > 0", cpu, err)
+	}
+	if _, err := ProcessCPU(-1); err == nil {
+		t.Error("ProcessCPU(-1) succeeded")
+	}
+}
+
+func TestAncestorEnv(t *testing.T) {
+	const mark = "GT_TEST_ANCESTOR_MARK"
+	if os.Getenv("GT_TEST_ANCESTOR_CHILD") == "1" {
+		if os.Getenv(mark) != "" {
+			t.Fatalf("%s leaked into the child", mark)
+		}
+		if pid, key := AncestorEnv("GT_TEST_UNSET_MARK", mark); pid != os.Getppid() || key != mark {
+			t.Fatalf("AncestorEnv = %d, %q; want parent %d, %q", pid, key, os.Getppid(), mark)
+		}
+		return
+	}
+
+	// The shell carries the mark and runs this test again without it; the
+	// trailing "true" keeps the shell from exec'ing into the child.
+	script := `env -u ` + mark + ` GT_TEST_ANCESTOR_CHILD=1 "$0" -test.run='^TestAncestorEnv$'; rc=$?; true; exit $rc`
+	cmd := exec.Command("sh", "-c", script, os.Args[0])
+	cmd.Env = append(os.Environ(), mark+"=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("child: %v\n%s", err, out)
+	}
+
+	if pid, _ := AncestorEnv("GT_TEST_UNSET_MARK"); pid != 0 {
+		t.Errorf("AncestorEnv found unset key in %d", pid)
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !linux
+
+package procstat
+
+import (
+	"errors"
+	"time"
+)
+
+// Supported reports whether process trees can be sampled on this platform.
+func Supported() bool { return false }
+
+// ReadTable is only implemented on Linux, where /proc exposes per-process
+// CPU, memory and descriptor counts without a ps call per process.
+func ReadTable() (*Table, error) {
+	return nil, errors.New("process sampling is only supported on Linux")
+}
+
+// ProcessCPU reads /proc, which only exists on Linux.
+func ProcessCPU(pid int) (time.Duration, error) {
+	return 0, errors.New("process sampling is only supported on Linux")
+}
+
+// AncestorEnv needs /proc to read other processes' environments; elsewhere
+// it finds nothing.
+func AncestorEnv(keys ...string) (pid int, key string) {
+	return 0, ""
+}
@@ -0,0 +1,261 @@
+// Package procstat samples the resource usage of agent process trees.
+//
+// The daemon walks each agent session's process tree (the pane or PTY
+// process and all its descendants) on a fixed interval, records CPU, RSS,
+// open file and process counts as a per-session time series under
+// .runtime/vitals, and flags sessions whose usage stays over configured
+// thresholds. gt vitals and the dashboard read the series back.
+package procstat
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// clockTicks is USER_HZ, the unit of CPU times in /proc/<pid>/stat. It is
+// fixed at 100 in the kernel ABI regardless of the kernel's internal HZ.
+const clockTicks = 100
+
+// Proc is one process in a Table.
+type Proc struct {
+	PID     int
+	PPID    int
+	Threads int
+	RSSKB   int64
+
+	// CPUTicks is user+system time of the process plus that of its reaped
+	// children. Summed over a live tree, every tick the tree used is
+	// counted once, so the total only grows while the tree runs.
+	CPUTicks uint64
+}
+
+// Table is a point-in-time snapshot of the host's processes, indexed for
+// walking process trees.
+type Table struct {
+	procs    map[int]Proc
+	children map[int][]int
+
+	// openFiles counts a process's open descriptors. It is only called
+	// for processes in a sampled tree, not for the whole host.
+	openFiles func(pid int) int
+}
+
+// NewTable builds a table from procs. openFiles may be nil.
+func NewTable(procs []Proc, openFiles func(pid int) int) *Table {
+	t := &Table{
+		procs:     make(map[int]Proc, len(procs)),
+		children:  make(map[int][]int),
+		openFiles: openFiles,
+	}
+	for _, p := range procs {
+		t.procs[p.PID] = p
+		t.children[p.PPID] = append(t.children[p.PPID], p.PID)
+	}
+	return t
+}
+
+// Usage is the aggregate of a process tree at the time of a snapshot.
+type Usage struct {
+	Procs     int
+	Threads   int
+	RSSKB     int64
+	OpenFiles int
+	CPUTicks  uint64
+}
+
+// Tree sums usage over root and all its descendants. It returns a zero
+// Usage if root is not in the table.
+func (t *Table) Tree(root int) Usage {
+	var u Usage
+	if _, ok := t.procs[root]; !ok {
+		return u
+	}
+	seen := map[int]bool{}
+	stack := []int{root}
+	for len(stack) > 0 {
+		pid := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[pid] {
+			continue
+		}
+		seen[pid] = true
+		p := t.procs[pid]
+		u.Procs++
+		u.Threads += p.Threads
+		u.RSSKB += p.RSSKB
+		u.CPUTicks += p.CPUTicks
+		if t.openFiles != nil {
+			u.OpenFiles += t.openFiles(pid)
+		}
+		stack = append(stack, t.children[pid]...)
+	}
+	return u
+}
+
+// Sample is one observation of a session's process tree.
+type Sample struct {
+	Time       time.Time `json:"t"`
+	Session    string    `json:"session"`
+	PID        int       `json:"pid"`
+	Procs      int       `json:"procs"`
+	Threads    int       `json:"threads"`
+	CPUPercent float64   `json:"cpu_pct"` // over the interval since the previous sample; 100 = one core
+	RSSKB      int64     `json:"rss_kb"`
+	OpenFiles  int       `json:"open_files"`
+}
+
+// Sampler turns table snapshots into samples, keeping the previous CPU
+// reading per session to derive an interval CPU percentage. It is not safe
+// for concurrent use.
+type Sampler struct {
+	prev map[string]cpuReading
+}
+
+type cpuReading struct {
+	pid   int
+	ticks uint64
+	at    time.Time
+}
+
+// NewSampler creates a sampler with no history.
+func NewSampler() *Sampler {
+	return &Sampler{prev: make(map[string]cpuReading)}
+}
+
+// Sample measures session's tree rooted at pid in table. The first sample
+// of a session (or of a new root PID) reports zero CPU.
+func (s *Sampler) Sample(table *Table, session string, pid int, now time.Time) Sample {
+	u := table.Tree(pid)
+	sample := Sample{
+		Time:      now,
+		Session:   session,
+		PID:       pid,
+		Procs:     u.Procs,
+		Threads:   u.Threads,
+		RSSKB:     u.RSSKB,
+		OpenFiles: u.OpenFiles,
+	}
+	if prev, ok := s.prev[session]; ok && prev.pid == pid && u.CPUTicks >= prev.ticks {
+		if elapsed := now.Sub(prev.at).Seconds(); elapsed > 0 {
+			sample.CPUPercent = float64(u.CPUTicks-prev.ticks) / clockTicks / elapsed * 100
+		}
+	}
+	s.prev[session] = cpuReading{pid: pid, ticks: u.CPUTicks, at: now}
+	return sample
+}
+
+// Retain forgets sessions not in live, so a recycled session name starts
+// with fresh CPU history.
+func (s *Sampler) Retain(live map[string]bool) {
+	for name := range s.prev {
+		if !live[name] {
+			delete(s.prev, name)
+		}
+	}
+}
+
+// Thresholds define a runaway process tree. Zero disables a check.
+type Thresholds struct {
+	MaxProcs      int
+	MaxRSSMB      int
+	MaxCPUPercent float64
+	MaxOpenFiles  int
+}
+
+// Exceeded returns a description of each threshold the sample is over.
+func (t Thresholds) Exceeded(s Sample) []string {
+	var out []string
+	if t.MaxProcs > 0 && s.Procs > t.MaxProcs {
+		out = append(out, fmt.Sprintf("%d processes (limit %d)", s.Procs, t.MaxProcs))
+	}
+	if t.MaxRSSMB > 0 && s.RSSKB > int64(t.MaxRSSMB)*1024 {
+		out = append(out, fmt.Sprintf("%s RSS (limit %d MiB)", FormatKB(s.RSSKB), t.MaxRSSMB))
+	}
+	if t.MaxCPUPercent > 0 && s.CPUPercent > t.MaxCPUPercent {
+		out = append(out, fmt.Sprintf("%.0f%% CPU (limit %.0f%%)", s.CPUPercent, t.MaxCPUPercent))
+	}
+	if t.MaxOpenFiles > 0 && s.OpenFiles > t.MaxOpenFiles {
+		out = append(out, fmt.Sprintf("%d open files (limit %d)", s.OpenFiles, t.MaxOpenFiles))
+	}
+	return out
+}
+
+// Detector flags sessions that stay over their thresholds for Sustain
+// consecutive samples, so one busy interval (a build, a test run) doesn't
+// raise an alarm. A flagged session is flagged again only after it has
+// dropped back under every threshold. It is not safe for concurrent use.
+type Detector struct {
+	Thresholds Thresholds
+	Sustain    int
+
+	over    map[string]int
+	flagged map[string]bool
+}
+
+// NewDetector creates a detector. sustain below 1 is treated as 1.
+func NewDetector(t Thresholds, sustain int) *Detector {
+	if sustain < 1 {
+		sustain = 1
+	}
+	return &Detector{
+		Thresholds: t,
+		Sustain:    sustain,
+		over:       make(map[string]int),
+		flagged:    make(map[string]bool),
+	}
+}
+
+// Observe records a sample and returns the exceeded thresholds when the
+// session has just become a runaway, or nil otherwise.
+func (d *Detector) Observe(s Sample) []string {
+	reasons := d.Thresholds.Exceeded(s)
+	if len(reasons) == 0 {
+		delete(d.over, s.Session)
+		delete(d.flagged, s.Session)
+		return nil
+	}
+	d.over[s.Session]++
+	if d.flagged[s.Session] || d.over[s.Session] < d.Sustain {
+		return nil
+	}
+	d.flagged[s.Session] = true
+	return reasons
+}
+
+// Retain forgets sessions not in live.
+func (d *Detector) Retain(live map[string]bool) {
+	for name := range d.over {
+		if !live[name] {
+			delete(d.over, name)
+			delete(d.flagged, name)
+		}
+	}
+}
+
+// Peak returns the per-field maximum over samples, with the session and
+// time of the last sample.
+func Peak(samples []Sample) Sample {
+	var p Sample
+	for _, s := range samples {
+		p.Session, p.PID, p.Time = s.Session, s.PID, s.Time
+		p.Procs = max(p.Procs, s.Procs)
+		p.Threads = max(p.Threads, s.Threads)
+		p.CPUPercent = max(p.CPUPercent, s.CPUPercent)
+		p.RSSKB = max(p.RSSKB, s.RSSKB)
+		p.OpenFiles = max(p.OpenFiles, s.OpenFiles)
+	}
+	return p
+}
+
+// FormatKB renders a KiB count as a short human-readable size.
+func FormatKB(kb int64) string {
+	switch {
+	case kb >= 1<<20:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(kb)/(1<<20)), ".0") + "G"
+	case kb >= 1<<10:
+		return fmt.Sprintf("%dM", kb>>10)
+	default:
+		return fmt.Sprintf("%dK", kb)
+	}
+}
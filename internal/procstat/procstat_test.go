@@ -0,0 +1,124 @@
+package procstat
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testTable() *Table {
+	// 10 → 11 → 13, 10 → 12; 20 is unrelated.
+	return NewTable([]Proc{
+		{PID: 10, PPID: 1, Threads: 4, RSSKB: 1000, CPUTicks: 100},
+		{PID: 11, PPID: 10, Threads: 1, RSSKB: 200, CPUTicks: 50},
+		{PID: 12, PPID: 10, Threads: 2, RSSKB: 300, CPUTicks: 25},
+		{PID: 13, PPID: 11, Threads: 1, RSSKB: 100, CPUTicks: 25},
+		{PID: 20, PPID: 1, Threads: 8, RSSKB: 9999, CPUTicks: 9999},
+	}, func(pid int) int { return 3 })
+}
+
+func TestTree(t *testing.T) {
+	got := testTable().Tree(10)
+	want := Usage{Procs: 4, Threads: 8, RSSKB: 1600, OpenFiles: 12, CPUTicks: 200}
+	if got != want {
+		t.Errorf("Tree(10) = %+v, want %+v", got, want)
+	}
+	if got := testTable().Tree(99); got != (Usage{}) {
+		t.Errorf("Tree(missing) = %+v, want zero", got)
+	}
+}
+
+func TestSamplerCPUPercent(t *testing.T) {
+	s := NewSampler()
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	first := s.Sample(testTable(), "gt-a", 10, t0)
+	if first.CPUPercent != 0 || first.Procs != 4 {
+		t.Errorf("first sample = %+v, want 0%% CPU and 4 procs", first)
+	}
+
+	// 200 more ticks (2 CPU-seconds) over 10s is 20% of a core.
+	busier := NewTable([]Proc{{PID: 10, PPID: 1, CPUTicks: 400}}, nil)
+	second := s.Sample(busier, "gt-a", 10, t0.Add(10*time.Second))
+	if second.CPUPercent != 20 {
+		t.Errorf("CPUPercent = %v, want 20", second.CPUPercent)
+	}
+
+	// A new root PID (restarted agent) starts fresh.
+	restarted := NewTable([]Proc{{PID: 30, PPID: 1, CPUTicks: 5000}}, nil)
+	if got := s.Sample(restarted, "gt-a", 30, t0.Add(20*time.Second)); got.CPUPercent != 0 {
+		t.Errorf("CPUPercent after restart = %v, want 0", got.CPUPercent)
+	}
+
+	s.Retain(map[string]bool{})
+	if len(s.prev) != 0 {
+		t.Errorf("Retain kept %v", s.prev)
+	}
+}
+
+func TestThresholdsExceeded(t *testing.T) {
+	th := Thresholds{MaxProcs: 100, MaxRSSMB: 1024, MaxCPUPercent: 200, MaxOpenFiles: 500}
+	if got := th.Exceeded(Sample{Procs: 100, RSSKB: 1024 * 1024, CPUPercent: 200, OpenFiles: 500}); got != nil {
+		t.Errorf("at the limits: %v, want none", got)
+	}
+	got := th.Exceeded(Sample{Procs: 412, RSSKB: 2 << 20, CPUPercent: 350, OpenFiles: 501})
+	want := []string{
+		"412 processes (limit 100)",
+		"2G RSS (limit 1024 MiB)",
+		"350% CPU (limit 200%)",
+		"501 open files (limit 500)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Exceeded = %q, want %q", got, want)
+	}
+	if got := (Thresholds{}).Exceeded(Sample{Procs: 1 << 20}); got != nil {
+		t.Errorf("zero thresholds: %v, want none", got)
+	}
+}
+
+func TestDetectorSustain(t *testing.T) {
+	d := NewDetector(Thresholds{MaxProcs: 10}, 2)
+	over := Sample{Session: "gt-a", Procs: 50}
+	under := Sample{Session: "gt-a", Procs: 5}
+
+	if got := d.Observe(over); got != nil {
+		t.Errorf("first breach flagged: %v", got)
+	}
+	if got := d.Observe(over); len(got) != 1 {
+		t.Errorf("sustained breach = %v, want flagged", got)
+	}
+	if got := d.Observe(over); got != nil {
+		t.Errorf("flagged twice: %v", got)
+	}
+	if got := d.Observe(under); got != nil {
+		t.Errorf("recovery flagged: %v", got)
+	}
+	d.Observe(over)
+	if got := d.Observe(over); len(got) != 1 {
+		t.Errorf("second episode = %v, want flagged", got)
+	}
+}
+
+func TestPeak(t *testing.T) {
+	p := Peak([]Sample{
+		{Session: "gt-a", Procs: 3, CPUPercent: 90, RSSKB: 100},
+		{Session: "gt-a", Procs: 9, CPUPercent: 10, RSSKB: 50, OpenFiles: 7},
+	})
+	if p.Procs != 9 || p.CPUPercent != 90 || p.RSSKB != 100 || p.OpenFiles != 7 || p.Session != "gt-a" {
+		t.Errorf("Peak = %+v", p)
+	}
+}
+
+func TestFormatKB(t *testing.T) {
+	tests := map[int64]string{
+		512:               "512K",
+		2048:              "2M",
+		1 << 20:           "1G",
+		3*(1<<20) + 1<<19: "3.5G",
+	}
+	for kb, want := range tests {
+		if got := FormatKB(kb); got != want {
+			t.Errorf("FormatKB(%d) = %q, want %q", kb, got, want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package procstat
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// maxSeriesBytes bounds a session's series file. When an append takes the
+// file past it, the oldest half is dropped. At a 30s interval this keeps
+// roughly a day of samples.
+const maxSeriesBytes = 512 << 10
+
+// SeriesDir returns the directory holding per-session series files.
+func SeriesDir(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "vitals")
+}
+
+func seriesFile(townRoot, session string) string {
+	return filepath.Join(SeriesDir(townRoot), session+".jsonl")
+}
+
+// Append adds a sample to its session's series.
+func Append(townRoot string, s Sample) error {
+	if err := os.MkdirAll(SeriesDir(townRoot), 0755); err != nil {
+		return err
+	}
+	line, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	path := seriesFile(townRoot, s.Session)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G304: path is under the town's .runtime
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > maxSeriesBytes {
+		return trimSeries(path)
+	}
+	return nil
+}
+
+// trimSeries keeps the newer half of a series file.
+func trimSeries(path string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is under the town's .runtime
+	if err != nil {
+		return err
+	}
+	cut := bytes.IndexByte(data[len(data)/2:], '\n')
+	if cut < 0 {
+		return nil
+	}
+	return util.AtomicWriteFile(path, data[len(data)/2+cut+1:], 0644)
+}
+
+// ReadSeries returns a session's samples taken at or after since, oldest
+// first. A session with no series has no samples.
+func ReadSeries(townRoot, session string, since time.Time) ([]Sample, error) {
+	f, err := os.Open(seriesFile(townRoot, session))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var out []Sample
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var s Sample
+		if json.Unmarshal(sc.Bytes(), &s) != nil {
+			continue // torn write from a crashed daemon
+		}
+		if !s.Time.Before(since) {
+			out = append(out, s)
+		}
+	}
+	return out, sc.Err()
+}
+
+// Sessions returns the sessions with a series updated within maxAge, so
+// sessions that ended long ago don't show up as current.
+func Sessions(townRoot string, maxAge time.Duration) ([]string, error) {
+	entries, err := os.ReadDir(SeriesDir(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	cutoff := time.Now().Add(-maxAge)
+	var out []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".jsonl")
+		if !ok || e.IsDir() {
+			continue
+		}
+		if info, err := e.Info(); err == nil && info.ModTime().After(cutoff) {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// Latest returns the most recent sample of every session sampled within
+// maxAge, keyed by session.
+func Latest(townRoot string, maxAge time.Duration) (map[string]Sample, error) {
+	names, err := Sessions(townRoot, maxAge)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]Sample, len(names))
+	for _, name := range names {
+		series, err := ReadSeries(townRoot, name, time.Now().Add(-maxAge))
+		if err != nil || len(series) == 0 {
+			continue
+		}
+		out[name] = series[len(series)-1]
+	}
+	return out, nil
+}
+
+// PruneSeries removes series files not written within maxAge.
+func PruneSeries(townRoot string, maxAge time.Duration) int {
+	entries, err := os.ReadDir(SeriesDir(townRoot))
+	if err != nil {
+		return 0
+	}
+	cutoff := time.Now().Add(-maxAge)
+	pruned := 0
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".jsonl") {
+			continue
+		}
+		if info, err := e.Info(); err == nil && info.ModTime().Before(cutoff) {
+			if os.Remove(filepath.Join(SeriesDir(townRoot), e.Name())) == nil {
+				pruned++
+			}
+		}
+	}
+	return pruned
+}
@@ -0,0 +1,90 @@
+package procstat
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSeriesAppendRead(t *testing.T) {
+	town := t.TempDir()
+	now := time.Now().Truncate(time.Second)
+	for i := 0; i < 3; i++ {
+		s := Sample{Time: now.Add(time.Duration(i-2) * time.Minute), Session: "gt-a", Procs: i + 1}
+		if err := Append(town, s); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if err := Append(town, Sample{Time: now, Session: "gt-b", Procs: 7}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	series, err := ReadSeries(town, "gt-a", now.Add(-90*time.Second))
+	if err != nil {
+		t.Fatalf("ReadSeries: %v", err)
+	}
+	if len(series) != 2 || series[0].Procs != 2 || series[1].Procs != 3 {
+		t.Errorf("ReadSeries = %+v, want the last two samples", series)
+	}
+
+	latest, err := Latest(town, time.Hour)
+	if err != nil {
+		t.Fatalf("Latest: %v", err)
+	}
+	if len(latest) != 2 || latest["gt-a"].Procs != 3 || latest["gt-b"].Procs != 7 {
+		t.Errorf("Latest = %+v", latest)
+	}
+
+	if s, err := ReadSeries(town, "gt-none", time.Time{}); err != nil || s != nil {
+		t.Errorf("ReadSeries(missing) = %v, %v", s, err)
+	}
+}
+
+func TestSeriesTrim(t *testing.T) {
+	town := t.TempDir()
+	s := Sample{Time: time.Now(), Session: "gt-a", Procs: 1}
+	var n int
+	for {
+		if err := Append(town, s); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+		n++
+		info, err := os.Stat(seriesFile(town, "gt-a"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() < int64(n)*20 {
+			break // trimmed
+		}
+	}
+	series, err := ReadSeries(town, "gt-a", time.Time{})
+	if err != nil {
+		t.Fatalf("ReadSeries after trim: %v", err)
+	}
+	if len(series) == 0 || len(series) >= n {
+		t.Errorf("after trim kept %d of %d samples", len(series), n)
+	}
+}
+
+func TestPruneSeries(t *testing.T) {
+	town := t.TempDir()
+	for _, name := range []string{"gt-old", "gt-new"} {
+		if err := Append(town, Sample{Time: time.Now(), Session: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(seriesFile(town, "gt-old"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if names, _ := Sessions(town, time.Hour); len(names) != 1 || names[0] != "gt-new" {
+		t.Errorf("Sessions = %v, want [gt-new]", names)
+	}
+	if n := PruneSeries(town, 24*time.Hour); n != 1 {
+		t.Errorf("PruneSeries = %d, want 1", n)
+	}
+	if _, err := os.Stat(seriesFile(town, "gt-old")); !os.IsNotExist(err) {
+		t.Errorf("old series still present: %v", err)
+	}
+}
@@ -144,14 +144,32 @@ func NewReworkRequestMessage(rig, polecat, branch, issue, targetBranch string, c
 		ConflictFiles: conflictFiles,
 		Instructions:  formatRebaseInstructions(targetBranch),
 	}
+	return newReworkRequestMessage(payload)
+}
 
-	body := formatReworkRequestBody(payload)
+// NewReviewReworkMessage creates a REWORK_REQUEST protocol message for
+// changes requested in a pre-merge review. The reviewer's feedback replaces
+// the rebase instructions.
+func NewReviewReworkMessage(rig, polecat, branch, issue, targetBranch, reviewer, feedback string) *mail.Message {
+	payload := ReworkRequestPayload{
+		Branch:       branch,
+		Issue:        issue,
+		Polecat:      polecat,
+		Rig:          rig,
+		RequestedAt:  time.Now(),
+		TargetBranch: targetBranch,
+		Reviewer:     reviewer,
+		Instructions: formatReviewInstructions(reviewer, feedback),
+	}
+	return newReworkRequestMessage(payload)
+}
 
+func newReworkRequestMessage(payload ReworkRequestPayload) *mail.Message {
 	msg := mail.NewMessage(
-		fmt.Sprintf("%s/refinery", rig),
-		fmt.Sprintf("%s/witness", rig),
-		fmt.Sprintf("REWORK_REQUEST %s", polecat),
-		body,
+		fmt.Sprintf("%s/refinery", payload.Rig),
+		fmt.Sprintf("%s/witness", payload.Rig),
+		fmt.Sprintf("REWORK_REQUEST %s", payload.Polecat),
+		formatReworkRequestBody(payload),
 	)
 	msg.Priority = mail.PriorityHigh
 	msg.Type = mail.TypeTask
@@ -172,6 +190,9 @@ func formatReworkRequestBody(p ReworkRequestPayload) string {
 	if len(p.ConflictFiles) > 0 {
 		sb.WriteString(fmt.Sprintf("Conflict-Files: %s\n", strings.Join(p.ConflictFiles, ", ")))
 	}
+	if p.Reviewer != "" {
+		sb.WriteString(fmt.Sprintf("Reviewer: %s\n", p.Reviewer))
+	}
 
 	sb.WriteString("\n")
 	sb.WriteString(p.Instructions)
@@ -191,6 +212,19 @@ func formatRebaseInstructions(targetBranch string) string {
 The Refinery will retry the merge after rebase is complete.`, targetBranch, targetBranch)
 }
 
+// formatReviewInstructions returns the reviewer's feedback with resubmit steps.
+func formatReviewInstructions(reviewer, feedback string) string {
+	if strings.TrimSpace(feedback) == "" {
+		feedback = "(no comments given - ask the reviewer)"
+	}
+	return fmt.Sprintf(`Changes requested by %s:
+
+%s
+
+Address the feedback, push your branch, and resubmit with 'gt done'.
+The MR goes back to review once resubmitted.`, reviewer, strings.TrimSpace(feedback))
+}
+
 // NewConvoyNeedsFeedingMessage creates a CONVOY_NEEDS_FEEDING protocol message.
 // Sent by Refinery to Deacon after a convoy-eligible merge completes, so the
 // deacon can immediately feed the convoy instead of waiting for the next patrol.
@@ -379,6 +413,10 @@ func ParseReworkRequestPayload(body string) (*ReworkRequestPayload, error) {
 	if files := parseField(body, "Conflict-Files"); files != "" {
 		payload.ConflictFiles = strings.Split(files, ", ")
 	}
+	payload.Reviewer = parseField(body, "Reviewer")
+	if _, instructions, ok := strings.Cut(body, "\n\n"); ok {
+		payload.Instructions = strings.TrimSpace(instructions)
+	}
 
 	var errs []string
 	if payload.Branch == "" {
@@ -157,6 +157,29 @@ func TestNewReworkRequestMessage(t *testing.T) {
 	}
 }
 
+func TestNewReviewReworkMessage(t *testing.T) {
+	msg := NewReviewReworkMessage("gastown", "nux", "polecat/nux/gt-abc", "gt-abc", "main",
+		"gastown/crew/max", "Missing test for the retry path.\nRename fooBar.")
+
+	if msg.Subject != "REWORK_REQUEST nux" {
+		t.Errorf("Subject = %q, want %q", msg.Subject, "REWORK_REQUEST nux")
+	}
+	if strings.Contains(msg.Body, "git rebase") {
+		t.Errorf("review rework should not carry rebase instructions: %s", msg.Body)
+	}
+
+	payload, err := ParseReworkRequestPayload(msg.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload.Reviewer != "gastown/crew/max" {
+		t.Errorf("Reviewer = %q, want %q", payload.Reviewer, "gastown/crew/max")
+	}
+	if !strings.Contains(payload.Instructions, "Missing test for the retry path.\nRename fooBar.") {
+		t.Errorf("Instructions missing feedback: %q", payload.Instructions)
+	}
+}
+
 func TestParseMergeReadyPayload(t *testing.T) {
 	body := `Branch: polecat/nux/gt-abc
 Issue: gt-abc
@@ -146,7 +146,8 @@ type MergeFailedPayload struct {
 }
 
 // ReworkRequestPayload contains the data for a REWORK_REQUEST message.
-// Sent by Refinery when a polecat's branch has conflicts requiring rebase.
+// Sent by Refinery when a polecat's branch has conflicts requiring rebase,
+// or when a pre-merge review requested changes.
 type ReworkRequestPayload struct {
 	// Branch is the source branch that needs rebasing.
 	Branch string `json:"branch"`
@@ -169,7 +170,12 @@ type ReworkRequestPayload struct {
 	// ConflictFiles lists files with conflicts (if known).
 	ConflictFiles []string `json:"conflict_files,omitempty"`
 
-	// Instructions provides specific rebase instructions.
+	// Reviewer is set when the rework was requested by a pre-merge review
+	// rather than a merge conflict.
+	Reviewer string `json:"reviewer,omitempty"`
+
+	// Instructions provides specific rebase instructions, or the
+	// reviewer's feedback for a review rework.
 	Instructions string `json:"instructions,omitempty"`
 }
 
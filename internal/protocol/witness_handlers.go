@@ -157,12 +157,20 @@ func (h *DefaultWitnessHandler) HandleReworkRequest(payload *ReworkRequestPayloa
 		fmt.Fprintf(h.Output, "  Conflicts in: %v\n", payload.ConflictFiles)
 	}
 
+	if payload.Reviewer != "" {
+		fmt.Fprintf(h.Output, "  Review: changes requested by %s\n", payload.Reviewer)
+	}
+
 	// Notify the polecat about the rebase requirement
 	if err := h.notifyPolecatRebase(payload); err != nil {
 		fmt.Fprintf(h.Output, "[Witness] Warning: failed to notify polecat: %v\n", err)
 		// Continue - notification is best-effort, no cleanup to fail
 	}
 
+	if payload.Reviewer != "" {
+		fmt.Fprintf(h.Output, "[Witness] ⚠ Polecat %s needs to address review feedback\n", payload.Polecat)
+		return nil
+	}
 	fmt.Fprintf(h.Output, "[Witness] ⚠ Polecat %s needs to rebase onto %s\n", payload.Polecat, payload.TargetBranch)
 
 	return nil
@@ -220,6 +228,10 @@ Please fix the issue and resubmit your work with 'gt done'.`,
 
 // notifyPolecatRebase sends a rebase request notification to a polecat.
 func (h *DefaultWitnessHandler) notifyPolecatRebase(payload *ReworkRequestPayload) error {
+	if payload.Reviewer != "" {
+		return h.notifyPolecatReview(payload)
+	}
+
 	conflictInfo := ""
 	if len(payload.ConflictFiles) > 0 {
 		conflictInfo = fmt.Sprintf("\nConflicting files:\n")
@@ -258,5 +270,20 @@ Then run 'gt done' to resubmit for merge.`,
 	return h.Router.Send(msg)
 }
 
+// notifyPolecatReview relays review feedback to a polecat whose MR had
+// changes requested.
+func (h *DefaultWitnessHandler) notifyPolecatReview(payload *ReworkRequestPayload) error {
+	msg := mail.NewMessage(
+		fmt.Sprintf("%s/witness", h.Rig),
+		fmt.Sprintf("%s/%s", h.Rig, payload.Polecat),
+		"Changes requested in review",
+		fmt.Sprintf("Branch: %s\nIssue: %s\n\n%s", payload.Branch, payload.Issue, payload.Instructions),
+	)
+	msg.Priority = mail.PriorityHigh
+	msg.Type = mail.TypeTask
+
+	return h.Router.Send(msg)
+}
+
 // Ensure DefaultWitnessHandler implements WitnessHandler.
 var _ WitnessHandler = (*DefaultWitnessHandler)(nil)
@@ -15,6 +15,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -153,6 +154,21 @@ func (ca *CA) IssuePolecat(cn string, ttl time.Duration) (certPEM, keyPEM []byte
 	return ca.issue(cn, nil, nil, ttl, x509.ExtKeyUsageClientAuth)
 }
 
+// TownCNPrefix prefixes the Common Name of the client certificates a town
+// presents to its federation peers. It cannot collide with polecat CNs, which
+// must start with "gt-".
+const TownCNPrefix = "town:"
+
+// IssueTown issues a client certificate identifying this CA's town to
+// federation peers. The CN is TownCNPrefix + townName; IssuePolecat never
+// issues CNs of that form, so a polecat cert cannot stand in for its town.
+func (ca *CA) IssueTown(townName string, ttl time.Duration) (certPEM, keyPEM []byte, err error) {
+	if townName == "" || strings.ContainsAny(townName, ":/ ") {
+		return nil, nil, fmt.Errorf("invalid town name %q", townName)
+	}
+	return ca.issue(TownCNPrefix+townName, nil, nil, ttl, x509.ExtKeyUsageClientAuth)
+}
+
 // issue creates and signs a leaf certificate. dnsNames and ipAddrs are added as SANs
 // for server certs so that modern TLS stacks (Go 1.15+) accept them without relying on CN.
 func (ca *CA) issue(cn string, dnsNames []string, ipAddrs []net.IP, ttl time.Duration, eku x509.ExtKeyUsage) (certPEM, keyPEM []byte, err error) {
@@ -347,6 +347,25 @@ func TestIssuePolecat(t *testing.T) {
 	})
 }
 
+func TestIssueTown(t *testing.T) {
+	ca, err := GenerateCA(t.TempDir())
+	require.NoError(t, err)
+
+	certPEM, _, err := ca.IssueTown("bartertown", time.Hour)
+	require.NoError(t, err)
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, "town:bartertown", cert.Subject.CommonName)
+	assert.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}, cert.ExtKeyUsage)
+	assert.Empty(t, cnToIdentity(cert.Subject.CommonName), "town certs must not pass as polecats")
+
+	for _, name := range []string{"", "a:b", "a/b"} {
+		_, _, err := ca.IssueTown(name, time.Hour)
+		assert.Error(t, err, "expected error for town name %q", name)
+	}
+}
+
 func TestCertEdgeCases(t *testing.T) {
 	dir := t.TempDir()
 	ca, err := GenerateCA(dir)
@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/time/rate"
 )
@@ -41,38 +45,87 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(req.Argv) == 0 {
-		http.Error(w, "argv is empty", http.StatusBadRequest)
+	argv, status, msg := s.checkArgv(req.Argv)
+	if status != 0 {
+		http.Error(w, msg, status)
 		return
 	}
 
+	release, ok := s.admitExec(w, identity)
+	if !ok {
+		return
+	}
+	defer release()
+
+	execCtx := r.Context()
+	if s.execTimeout > 0 {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(execCtx, s.execTimeout)
+		defer cancel()
+	}
+	out, errOut, exitCode, cpu := runCommand(execCtx, argv, identity)
+	s.chargeCPU(identity, cpu)
+
+	// Audit log (do not log full argv — it may contain tokens or secrets).
+	if exitCode == 0 {
+		s.log.Info("exec", "identity", identity, "cmd", req.Argv[0],
+			"sub", subForLog(req.Argv), "exit", exitCode, "cpu", cpu)
+	} else {
+		s.log.Warn("exec failed", "identity", identity, "cmd", req.Argv[0],
+			"sub", subForLog(req.Argv), "exit", exitCode, "cpu", cpu)
+	}
+
+	// The handler always returns HTTP 200 even when the subprocess exits
+	// non-zero. This is intentional: the RPC call itself succeeded (the request was
+	// well-formed, the command was allowed, and the subprocess ran). The subprocess's
+	// outcome is reported in the JSON body via exitCode. Callers must inspect exitCode
+	// rather than the HTTP status to determine whether the command succeeded.
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(execResponse{
+		Stdout:   out,
+		Stderr:   errOut,
+		ExitCode: exitCode,
+	})
+}
+
+// checkArgv validates argv against the command and subcommand allowlists and
+// returns a copy with argv[0] resolved to its absolute path. On failure it
+// returns the HTTP status and message to send instead.
+func (s *Server) checkArgv(reqArgv []string) (argv []string, status int, msg string) {
+	if len(reqArgv) == 0 {
+		return nil, http.StatusBadRequest, "argv is empty"
+	}
+
 	// Validate argv[0] is in the allowlist.
-	cmd0 := req.Argv[0]
+	cmd0 := reqArgv[0]
 	if !s.isAllowed(cmd0) {
-		http.Error(w, fmt.Sprintf("command not allowed: %q", cmd0), http.StatusForbidden)
-		return
+		return nil, http.StatusForbidden, fmt.Sprintf("command not allowed: %q", cmd0)
 	}
 
 	// Validate argv[1] (subcommand) if this command has a subcommand allowlist.
 	if subs, ok := s.allowedSubs[cmd0]; ok {
-		if len(req.Argv) < 2 {
-			http.Error(w, "subcommand required", http.StatusForbidden)
-			return
+		if len(reqArgv) < 2 {
+			return nil, http.StatusForbidden, "subcommand required"
 		}
-		sub := req.Argv[1]
+		sub := reqArgv[1]
 		if !subs[sub] {
-			http.Error(w, fmt.Sprintf("subcommand not allowed: %q %q", cmd0, sub), http.StatusForbidden)
-			return
+			return nil, http.StatusForbidden, fmt.Sprintf("subcommand not allowed: %q %q", cmd0, sub)
 		}
 	}
 
-	// Build argv as a copy of req.Argv to avoid mutating the decoded request.
-	argv := append([]string(nil), req.Argv...)
+	// Build argv as a copy of reqArgv to avoid mutating the decoded request.
+	argv = append([]string(nil), reqArgv...)
 	// Use the resolved absolute binary path to prevent PATH hijacking after startup.
 	if resolved, ok := s.resolvedPaths[cmd0]; ok {
 		argv[0] = resolved
 	}
+	return argv, 0, ""
+}
 
+// admitExec applies the per-client rate limit and CPU quota and takes a
+// global concurrency slot. On rejection it writes the response and returns
+// false; otherwise the caller must call release when the command is done.
+func (s *Server) admitExec(w http.ResponseWriter, identity string) (release func(), ok bool) {
 	// Per-client rate limiting: identified by cert CN (or "unknown" if absent).
 	rateKey := identity
 	if rateKey == "" {
@@ -81,47 +134,26 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 	if !s.limiterFor(rateKey).Allow() {
 		s.log.Warn("exec rate limit exceeded", "identity", identity)
 		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
-		return
+		return nil, false
+	}
+
+	// Per-client CPU quota: commands already run have used up the budget.
+	if wait := s.cpuWait(rateKey); wait > 0 {
+		s.log.Warn("exec cpu quota exceeded", "identity", identity, "retry_after", wait)
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		http.Error(w, "cpu quota exceeded", http.StatusTooManyRequests)
+		return nil, false
 	}
 
 	// Global concurrency cap: reject immediately if all slots are busy.
 	select {
 	case s.execSem <- struct{}{}:
-		defer func() { <-s.execSem }()
+		return func() { <-s.execSem }, true
 	default:
 		s.log.Warn("exec concurrency limit exceeded", "identity", identity)
 		http.Error(w, "server busy", http.StatusServiceUnavailable)
-		return
-	}
-
-	execCtx := r.Context()
-	if s.execTimeout > 0 {
-		var cancel context.CancelFunc
-		execCtx, cancel = context.WithTimeout(execCtx, s.execTimeout)
-		defer cancel()
+		return nil, false
 	}
-	out, errOut, exitCode := runCommand(execCtx, argv, identity)
-
-	// Audit log (do not log full argv — it may contain tokens or secrets).
-	if exitCode == 0 {
-		s.log.Info("exec", "identity", identity, "cmd", cmd0,
-			"sub", subForLog(req.Argv), "exit", exitCode)
-	} else {
-		s.log.Warn("exec failed", "identity", identity, "cmd", cmd0,
-			"sub", subForLog(req.Argv), "exit", exitCode)
-	}
-
-	// The handler always returns HTTP 200 even when the subprocess exits
-	// non-zero. This is intentional: the RPC call itself succeeded (the request was
-	// well-formed, the command was allowed, and the subprocess ran). The subprocess's
-	// outcome is reported in the JSON body via exitCode. Callers must inspect exitCode
-	// rather than the HTTP status to determine whether the command succeeded.
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(execResponse{
-		Stdout:   out,
-		Stderr:   errOut,
-		ExitCode: exitCode,
-	})
 }
 
 // subForLog returns a truncated argv[1] if present, otherwise "".
@@ -203,11 +235,83 @@ func (s *Server) limiterFor(identity string) *rate.Limiter {
 	return v.(*rate.Limiter)
 }
 
-func runCommand(ctx context.Context, argv []string, identity string) (stdout, stderr string, exitCode int) {
+// cpuBudget is one client's CPU-time allowance. It holds up to the quota,
+// refills at quota per window, and is charged after each command with the
+// user+system time the command used, so it can go negative.
+type cpuBudget struct {
+	mu        sync.Mutex
+	available time.Duration
+	updated   time.Time
+}
+
+// refill tops the budget up for the time since the last update. Callers hold mu.
+func (b *cpuBudget) refill(now time.Time, quota, window time.Duration) {
+	if b.updated.IsZero() {
+		b.available = quota
+	} else {
+		b.available += time.Duration(float64(now.Sub(b.updated)) * float64(quota) / float64(window))
+		if b.available > quota {
+			b.available = quota
+		}
+	}
+	b.updated = now
+}
+
+// cpuBudgetFor returns the CPU budget for the given client identity, creating
+// one if it does not exist. Like limiterFor, entries are never evicted.
+func (s *Server) cpuBudgetFor(identity string) *cpuBudget {
+	if v, ok := s.cpuBudgets.Load(identity); ok {
+		return v.(*cpuBudget)
+	}
+	v, _ := s.cpuBudgets.LoadOrStore(identity, &cpuBudget{})
+	return v.(*cpuBudget)
+}
+
+// cpuWait returns how long the client must wait before its CPU budget is
+// positive again, or 0 if it may run a command now (or there is no quota).
+// A running command is not stopped when it overdraws the budget; ExecTimeout
+// bounds how far over it can go.
+func (s *Server) cpuWait(identity string) time.Duration {
+	if s.cpuQuota <= 0 {
+		return 0
+	}
+	b := s.cpuBudgetFor(identity)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now(), s.cpuQuota, s.cpuWindow)
+	if b.available > 0 {
+		return 0
+	}
+	return time.Duration(float64(-b.available)*float64(s.cpuWindow)/float64(s.cpuQuota)) + time.Millisecond
+}
+
+// chargeCPU deducts a finished command's CPU time from the client's budget.
+func (s *Server) chargeCPU(identity string, used time.Duration) {
+	if s.cpuQuota <= 0 {
+		return
+	}
+	if identity == "" {
+		identity = "unknown"
+	}
+	b := s.cpuBudgetFor(identity)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now(), s.cpuQuota, s.cpuWindow)
+	b.available -= used
+}
+
+// newExecCmd builds the subprocess for an exec request. Canceling ctx (client
+// disconnect, cancel frame or ExecTimeout) interrupts the command so gt/bd can
+// clean up, and kills it if it is still running execKillDelay later.
+func newExecCmd(ctx context.Context, argv []string, identity string) *exec.Cmd {
 	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
-	var outBuf, errBuf strings.Builder
-	cmd.Stdout = &outBuf
-	cmd.Stderr = &errBuf
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = execKillDelay
 	// Restrict the subprocess environment to prevent server credentials from
 	// leaking into gt/bd calls. Pass identity via env var so commands can
 	// optionally use it without requiring a --identity CLI flag on every command.
@@ -216,13 +320,37 @@ func runCommand(ctx context.Context, argv []string, identity string) (stdout, st
 		env = append(env, "GT_PROXY_IDENTITY="+identity)
 	}
 	cmd.Env = env
+	return cmd
+}
+
+// execKillDelay is how long a canceled command gets to exit after SIGINT.
+const execKillDelay = 5 * time.Second
+
+func runCommand(ctx context.Context, argv []string, identity string) (stdout, stderr string, exitCode int, cpu time.Duration) {
+	cmd := newExecCmd(ctx, argv, identity)
+	var outBuf, errBuf strings.Builder
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
 	err := cmd.Run()
-	if err != nil {
-		if exit, ok := err.(*exec.ExitError); ok {
-			exitCode = exit.ExitCode()
-		} else {
-			exitCode = 1
-		}
+	return outBuf.String(), errBuf.String(), exitStatus(err), cpuTime(cmd.ProcessState)
+}
+
+// exitStatus maps a cmd.Run/Wait error to an exit code: the process's own
+// code, or 1 if it could not be started or waited for.
+func exitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+	if exit, ok := err.(*exec.ExitError); ok {
+		return exit.ExitCode()
+	}
+	return 1
+}
+
+// cpuTime returns the user+system CPU time of a finished process.
+func cpuTime(ps *os.ProcessState) time.Duration {
+	if ps == nil {
+		return 0
 	}
-	return outBuf.String(), errBuf.String(), exitCode
+	return ps.UserTime() + ps.SystemTime()
 }
@@ -0,0 +1,354 @@
+// Streaming exec
+//
+// POST /v1/exec/stream runs the same allowlisted commands as /v1/exec, under
+// the same rate limit, CPU quota and concurrency cap, but as a full-duplex
+// conversation of newline-delimited JSON frames (execFrame) instead of one
+// buffered request and response.  Clients should use HTTP/2, which carries a
+// request body and a response body concurrently; HTTP/1.1 full duplex is
+// enabled where the server supports it.
+//
+//	client → server
+//	  {"type":"start","argv":["gt","mail","send",...]}   first frame, required
+//	  {"type":"stdin","data":"<base64>"}                 bytes for the command's stdin
+//	  {"type":"eof"}                                     close the command's stdin
+//	  {"type":"cancel"}                                  interrupt the command
+//
+//	server → client
+//	  {"type":"stdout","data":"<base64>"}                as the command writes it
+//	  {"type":"stderr","data":"<base64>"}
+//	  {"type":"exit","exitCode":0}                       last frame
+//
+// Validation failures before the command starts are plain HTTP errors, as
+// for /v1/exec.  Once the 200 and headers are sent, every outcome is an exit
+// frame; its "error" is "canceled" or "timeout" when the proxy stopped the
+// command, or the reason the command could not start.
+//
+// Closing the request (or losing the connection) cancels the command just
+// like a cancel frame.  Ending the request body without an eof frame closes
+// stdin.  Cancellation sends SIGINT and kills the command if it is still
+// running execKillDelay later.
+//
+// Unlike /v1/exec, a stream's CPU time is charged to the client's quota every
+// cpuMeterInterval while the command runs, since a stream can outlive many
+// short commands.
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/procstat"
+)
+
+// Frame types for /v1/exec/stream.
+const (
+	frameStart  = "start"
+	frameStdin  = "stdin"
+	frameEOF    = "eof"
+	frameCancel = "cancel"
+	frameStdout = "stdout"
+	frameStderr = "stderr"
+	frameExit   = "exit"
+)
+
+// maxStreamBody caps everything a client sends on one stream, stdin included.
+const maxStreamBody = 64 << 20 // 64 MiB
+
+// cpuMeterInterval is how often a running stream's CPU time is charged to
+// its client's quota.
+const cpuMeterInterval = time.Second
+
+// execFrame is one newline-delimited JSON message on /v1/exec/stream.
+type execFrame struct {
+	Type     string   `json:"type"`
+	Argv     []string `json:"argv,omitempty"`
+	Data     []byte   `json:"data,omitempty"`
+	ExitCode int      `json:"exitCode,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// frameSender serializes frames from the stdout and stderr copiers and the
+// handler onto the response, flushing each one so the client sees output as
+// it is produced.
+type frameSender struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	rc  *http.ResponseController
+	err error
+}
+
+func (fs *frameSender) send(f execFrame) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.err != nil {
+		return fs.err
+	}
+	if fs.err = fs.enc.Encode(f); fs.err == nil {
+		fs.err = fs.rc.Flush()
+	}
+	return fs.err
+}
+
+// frameWriter is an io.Writer that sends each write as a frame of one type.
+type frameWriter struct {
+	fs  *frameSender
+	typ string
+}
+
+func (fw frameWriter) Write(p []byte) (int, error) {
+	if err := fw.fs.send(execFrame{Type: fw.typ, Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// stdinFeeder writes a stream's stdin frames to the command from its own
+// goroutine, so the frame reader never waits on a command that isn't reading
+// its stdin and always sees a cancel. The queue is unbounded; maxStreamBody
+// caps what a client can put in it.
+type stdinFeeder struct {
+	mu     sync.Mutex
+	queue  [][]byte
+	closed bool
+	wake   chan struct{}
+}
+
+func newStdinFeeder() *stdinFeeder {
+	return &stdinFeeder{wake: make(chan struct{}, 1)}
+}
+
+// write queues data for the command's stdin.
+func (f *stdinFeeder) write(data []byte) {
+	f.mu.Lock()
+	if !f.closed {
+		f.queue = append(f.queue, data)
+	}
+	f.mu.Unlock()
+	f.signal()
+}
+
+// close closes the command's stdin once the queued data is written.
+func (f *stdinFeeder) close() {
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+	f.signal()
+}
+
+func (f *stdinFeeder) signal() {
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run writes queued data to stdin until the feeder is closed. Once a write
+// fails (the command closed its stdin or exited), the rest is discarded.
+func (f *stdinFeeder) run(stdin io.WriteCloser) {
+	defer stdin.Close() //nolint:errcheck // also closed by Wait
+	broken := false
+	for {
+		f.mu.Lock()
+		queue, closed := f.queue, f.closed
+		f.queue = nil
+		f.mu.Unlock()
+		for _, data := range queue {
+			if !broken {
+				_, err := stdin.Write(data)
+				broken = err != nil
+			}
+		}
+		if closed {
+			return
+		}
+		if len(queue) == 0 {
+			<-f.wake
+		}
+	}
+}
+
+// meterCPU charges a running command's CPU time to the client's budget as it
+// accrues, so a long stream counts against the quota when the client's next
+// command is admitted, not only once it exits. The returned func stops the
+// meter after the command has been waited for, charges the remainder and
+// returns the command's total CPU time.
+func (s *Server) meterCPU(identity string, cmd *exec.Cmd) func() time.Duration {
+	var charged time.Duration
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if s.cpuQuota <= 0 {
+			return
+		}
+		ticker := time.NewTicker(cpuMeterInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if used, err := procstat.ProcessCPU(cmd.Process.Pid); err == nil && used > charged {
+					s.chargeCPU(identity, used-charged)
+					charged = used
+				}
+			}
+		}
+	}()
+	return func() time.Duration {
+		close(stop)
+		<-done
+		cpu := cpuTime(cmd.ProcessState)
+		if cpu > charged {
+			s.chargeCPU(identity, cpu-charged)
+		}
+		return cpu
+	}
+}
+
+func (s *Server) handleExecStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxStreamBody)
+	identity := extractIdentity(r)
+
+	dec := json.NewDecoder(r.Body)
+	var start execFrame
+	if err := dec.Decode(&start); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if start.Type != frameStart {
+		http.Error(w, `bad request: first frame must be "start"`, http.StatusBadRequest)
+		return
+	}
+
+	argv, status, msg := s.checkArgv(start.Argv)
+	if status != 0 {
+		http.Error(w, msg, status)
+		return
+	}
+
+	release, ok := s.admitExec(w, identity)
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	if s.execTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, s.execTimeout)
+		defer cancelTimeout()
+	}
+
+	// The server's read and write timeouts are sized for short requests; a
+	// stream lives as long as its command. Errors mean the connection doesn't
+	// support the control (e.g. in tests) and are ignored.
+	rc := http.NewResponseController(w)
+	_ = rc.EnableFullDuplex()
+	var deadline time.Time
+	if s.execTimeout > 0 {
+		deadline = time.Now().Add(s.execTimeout + execKillDelay + 10*time.Second)
+	}
+	_ = rc.SetReadDeadline(deadline)
+	_ = rc.SetWriteDeadline(deadline)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fs := &frameSender{enc: json.NewEncoder(w), rc: rc}
+	_ = rc.Flush()
+
+	cmd := newExecCmd(ctx, argv, identity)
+	cmd.Stdout = frameWriter{fs, frameStdout}
+	cmd.Stderr = frameWriter{fs, frameStderr}
+	stdin, err := cmd.StdinPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		s.log.Warn("exec failed", "identity", identity, "cmd", start.Argv[0],
+			"sub", subForLog(start.Argv), "stream", true, "err", err)
+		_ = fs.send(execFrame{Type: frameExit, ExitCode: 1, Error: err.Error()})
+		return
+	}
+
+	finishCPU := s.meterCPU(identity, cmd)
+	feeder := newStdinFeeder()
+	stdinDone := make(chan struct{})
+	go func() {
+		defer close(stdinDone)
+		feeder.run(stdin)
+	}()
+
+	// Read frames until the client stops sending, handing stdin to the
+	// feeder so a cancel is seen however far behind the command is.
+	var canceled atomic.Bool
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		defer feeder.close()
+		for {
+			var f execFrame
+			if err := dec.Decode(&f); err != nil {
+				return
+			}
+			switch f.Type {
+			case frameStdin:
+				feeder.write(f.Data)
+			case frameEOF:
+				feeder.close()
+			case frameCancel:
+				canceled.Store(true)
+				cancel()
+				return
+			}
+		}
+	}()
+	// Neither goroutine may outlive the handler: the request body is
+	// invalid once it returns. The read deadline unblocks a pending read
+	// on HTTP/1.1, where closing the body would wait for it; on HTTP/2
+	// closing the body does.
+	defer func() {
+		_ = rc.SetReadDeadline(time.Now())
+		_ = r.Body.Close()
+		<-readerDone
+		feeder.close()
+		<-stdinDone
+	}()
+
+	err = cmd.Wait()
+	exitCode := exitStatus(err)
+	cpu := finishCPU()
+
+	exit := execFrame{Type: frameExit, ExitCode: exitCode}
+	switch {
+	case canceled.Load():
+		exit.Error = "canceled"
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		exit.Error = "timeout"
+	}
+
+	// Audit log (do not log full argv — it may contain tokens or secrets).
+	if exitCode == 0 {
+		s.log.Info("exec", "identity", identity, "cmd", start.Argv[0],
+			"sub", subForLog(start.Argv), "exit", exitCode, "cpu", cpu, "stream", true)
+	} else {
+		s.log.Warn("exec failed", "identity", identity, "cmd", start.Argv[0],
+			"sub", subForLog(start.Argv), "exit", exitCode, "cpu", cpu, "stream", true, "reason", exit.Error)
+	}
+
+	_ = fs.send(exit)
+}
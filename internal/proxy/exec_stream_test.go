@@ -0,0 +1,240 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// execStream is a test client for /v1/exec/stream over HTTP/2.
+type execStream struct {
+	t      *testing.T
+	enc    *json.Encoder
+	pw     *io.PipeWriter
+	resp   *http.Response
+	frames *bufio.Scanner
+}
+
+// startExecStream opens a stream and sends the start frame. The returned
+// stream's resp is set once the server has answered.
+func startExecStream(t *testing.T, ctx context.Context, ts *httptest.Server, argv ...string) *execStream {
+	t.Helper()
+	pr, pw := io.Pipe()
+	req, err := http.NewRequestWithContext(ctx, "POST", ts.URL+"/v1/exec/stream", pr)
+	require.NoError(t, err)
+
+	st := &execStream{t: t, enc: json.NewEncoder(pw), pw: pw}
+	go func() { _ = st.enc.Encode(execFrame{Type: frameStart, Argv: argv}) }()
+
+	st.resp, err = ts.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = pw.Close()
+		_ = st.resp.Body.Close()
+	})
+	st.frames = bufio.NewScanner(st.resp.Body)
+	return st
+}
+
+func (st *execStream) send(f execFrame) {
+	st.t.Helper()
+	require.NoError(st.t, st.enc.Encode(f))
+}
+
+// next returns the next frame from the server.
+func (st *execStream) next() execFrame {
+	st.t.Helper()
+	require.True(st.t, st.frames.Scan(), "stream ended early: %v", st.frames.Err())
+	var f execFrame
+	require.NoError(st.t, json.Unmarshal(st.frames.Bytes(), &f))
+	return f
+}
+
+// collect reads frames up to and including exit.
+func (st *execStream) collect() (stdout, stderr string, exit execFrame) {
+	st.t.Helper()
+	var out, errOut strings.Builder
+	for {
+		f := st.next()
+		switch f.Type {
+		case frameStdout:
+			out.Write(f.Data)
+		case frameStderr:
+			errOut.Write(f.Data)
+		case frameExit:
+			return out.String(), errOut.String(), f
+		}
+	}
+}
+
+func newExecStreamServer(t *testing.T, cfg Config) (*Server, *httptest.Server) {
+	t.Helper()
+	srv := newExecTestServer(t, cfg)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(srv.handleExecStream))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+	return srv, ts
+}
+
+func TestHandleExecStream(t *testing.T) {
+	_, ts := newExecStreamServer(t, Config{AllowedCommands: []string{"sh", "cat"}})
+	ctx := context.Background()
+
+	t.Run("streams stdout, stderr and exit code", func(t *testing.T) {
+		st := startExecStream(t, ctx, ts, "sh", "-c", "echo out; echo err >&2; exit 3")
+		require.Equal(t, http.StatusOK, st.resp.StatusCode)
+		assert.Equal(t, "application/x-ndjson", st.resp.Header.Get("Content-Type"))
+
+		stdout, stderr, exit := st.collect()
+		assert.Equal(t, "out\n", stdout)
+		assert.Equal(t, "err\n", stderr)
+		assert.Equal(t, 3, exit.ExitCode)
+		assert.Empty(t, exit.Error)
+	})
+
+	t.Run("stdin is forwarded", func(t *testing.T) {
+		st := startExecStream(t, ctx, ts, "cat")
+		st.send(execFrame{Type: frameStdin, Data: []byte("hello ")})
+		st.send(execFrame{Type: frameStdin, Data: []byte("world")})
+		st.send(execFrame{Type: frameEOF})
+
+		stdout, _, exit := st.collect()
+		assert.Equal(t, "hello world", stdout)
+		assert.Equal(t, 0, exit.ExitCode)
+	})
+
+	t.Run("output arrives before the command exits and cancel stops it", func(t *testing.T) {
+		st := startExecStream(t, ctx, ts, "sh", "-c", "echo started; exec sleep 10")
+
+		first := make(chan execFrame, 1)
+		go func() { first <- st.next() }()
+		select {
+		case f := <-first:
+			assert.Equal(t, frameStdout, f.Type)
+			assert.Equal(t, "started\n", string(f.Data))
+		case <-time.After(5 * time.Second):
+			t.Fatal("no output while the command was running")
+		}
+
+		begin := time.Now()
+		st.send(execFrame{Type: frameCancel})
+		_, _, exit := st.collect()
+		assert.Less(t, time.Since(begin), execKillDelay, "SIGINT should stop sleep promptly")
+		assert.Equal(t, "canceled", exit.Error)
+		assert.NotZero(t, exit.ExitCode)
+	})
+
+	t.Run("first frame must be start", func(t *testing.T) {
+		resp, err := ts.Client().Post(ts.URL+"/v1/exec/stream", "application/x-ndjson",
+			strings.NewReader(`{"type":"stdin","data":"eA=="}`+"\n"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("disallowed command returns 403", func(t *testing.T) {
+		resp, err := ts.Client().Post(ts.URL+"/v1/exec/stream", "application/x-ndjson",
+			strings.NewReader(`{"type":"start","argv":["curl","http://evil.com"]}`+"\n"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func TestHandleExecStreamTimeout(t *testing.T) {
+	_, ts := newExecStreamServer(t, Config{
+		AllowedCommands: []string{"sleep"},
+		ExecTimeout:     200 * time.Millisecond,
+	})
+	st := startExecStream(t, context.Background(), ts, "sleep", "10")
+	_, _, exit := st.collect()
+	assert.Equal(t, "timeout", exit.Error)
+	assert.NotZero(t, exit.ExitCode)
+}
+
+// TestHandleExecStreamClientDisconnect verifies that losing the client's
+// connection kills the subprocess.
+func TestHandleExecStreamClientDisconnect(t *testing.T) {
+	lc := &logCapture{}
+	_, ts := newExecStreamServer(t, Config{
+		AllowedCommands: []string{"sleep"},
+		Logger:          slog.New(lc),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	startExecStream(t, ctx, ts, "sleep", "10")
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	ts.CloseClientConnections()
+
+	require.Eventually(t, func() bool {
+		_, ok := lc.findEntry(slog.LevelWarn, "exec failed")
+		return ok
+	}, execKillDelay, 20*time.Millisecond, "subprocess was not stopped after the client went away")
+}
+
+// TestHandleExecStreamCancelWhileStdinBlocked verifies that a cancel frame
+// is seen while the command isn't reading the stdin the client sends.
+func TestHandleExecStreamCancelWhileStdinBlocked(t *testing.T) {
+	_, ts := newExecStreamServer(t, Config{AllowedCommands: []string{"sleep"}})
+	st := startExecStream(t, context.Background(), ts, "sleep", "10")
+
+	// Well past the pipe buffer, so writing it to sleep's stdin blocks.
+	sent := make(chan error, 1)
+	go func() {
+		chunk := []byte(strings.Repeat("x", 64<<10))
+		for range 16 {
+			if err := st.enc.Encode(execFrame{Type: frameStdin, Data: chunk}); err != nil {
+				sent <- err
+				return
+			}
+		}
+		sent <- st.enc.Encode(execFrame{Type: frameCancel})
+	}()
+
+	exited := make(chan execFrame, 1)
+	go func() {
+		_, _, exit := st.collect()
+		exited <- exit
+	}()
+	select {
+	case exit := <-exited:
+		assert.Equal(t, "canceled", exit.Error)
+	case <-time.After(execKillDelay):
+		t.Fatal("cancel was not seen while stdin was blocked")
+	}
+	require.NoError(t, <-sent)
+}
+
+// TestHandleExecStreamChargesRunningCPU verifies that a stream's CPU time
+// counts against the client's quota while the command is still running.
+func TestHandleExecStreamChargesRunningCPU(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("no /proc")
+	}
+	srv, ts := newExecStreamServer(t, Config{
+		AllowedCommands: []string{"sh"},
+		ExecCPUQuota:    100 * time.Millisecond,
+	})
+	st := startExecStream(t, context.Background(), ts, "sh", "-c", "while :; do :; done")
+
+	require.Eventually(t, func() bool {
+		return srv.cpuWait("unknown") > 0
+	}, 5*time.Second, 50*time.Millisecond, "running command was not charged to the quota")
+
+	st.send(execFrame{Type: frameCancel})
+	_, _, exit := st.collect()
+	assert.Equal(t, "canceled", exit.Error)
+}
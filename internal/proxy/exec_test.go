@@ -288,26 +288,26 @@ func TestHandleExec(t *testing.T) {
 
 func TestRunCommand(t *testing.T) {
 	t.Run("echo world produces expected stdout", func(t *testing.T) {
-		stdout, stderr, code := runCommand(context.Background(), []string{"echo", "world"}, "")
+		stdout, stderr, code, _ := runCommand(context.Background(), []string{"echo", "world"}, "")
 		assert.Equal(t, "world\n", stdout)
 		assert.Equal(t, "", stderr)
 		assert.Equal(t, 0, code)
 	})
 
 	t.Run("sh exit 42 returns exitCode 42", func(t *testing.T) {
-		_, _, code := runCommand(context.Background(), []string{"sh", "-c", "exit 42"}, "")
+		_, _, code, _ := runCommand(context.Background(), []string{"sh", "-c", "exit 42"}, "")
 		assert.Equal(t, 42, code)
 	})
 
 	t.Run("stderr is captured separately", func(t *testing.T) {
-		stdout, stderr, code := runCommand(context.Background(), []string{"sh", "-c", "echo err >&2"}, "")
+		stdout, stderr, code, _ := runCommand(context.Background(), []string{"sh", "-c", "echo err >&2"}, "")
 		assert.Equal(t, "", stdout)
 		assert.Equal(t, "err\n", stderr)
 		assert.Equal(t, 0, code)
 	})
 
 	t.Run("non-existent binary returns exitCode 1", func(t *testing.T) {
-		_, _, code := runCommand(context.Background(), []string{"/no/such/binary/xyzzy"}, "")
+		_, _, code, _ := runCommand(context.Background(), []string{"/no/such/binary/xyzzy"}, "")
 		assert.Equal(t, 1, code)
 	})
 
@@ -315,7 +315,7 @@ func TestRunCommand(t *testing.T) {
 		// Set a sentinel in the test process env; the subprocess must not see it.
 		t.Setenv("PROXY_TEST_SENTINEL", "super_secret_sentinel_12345")
 
-		stdout, _, code := runCommand(context.Background(), []string{"sh", "-c", "echo ${PROXY_TEST_SENTINEL:-NOT_SET}"}, "")
+		stdout, _, code, _ := runCommand(context.Background(), []string{"sh", "-c", "echo ${PROXY_TEST_SENTINEL:-NOT_SET}"}, "")
 		assert.Equal(t, 0, code)
 		assert.NotContains(t, stdout, "super_secret_sentinel_12345",
 			"subprocess should not inherit test env vars")
@@ -552,6 +552,52 @@ func TestExecDefaultLimits(t *testing.T) {
 	srv := newExecTestServer(t, Config{AllowedCommands: []string{"echo"}})
 	assert.Equal(t, 32, cap(srv.execSem), "default MaxConcurrentExec should be 32")
 	assert.Equal(t, 60*time.Second, srv.execTimeout, "default ExecTimeout should be 60s")
+	assert.Equal(t, time.Duration(0), srv.cpuQuota, "CPU quota should be off by default")
+	assert.Equal(t, time.Hour, srv.cpuWindow, "default ExecCPUQuotaWindow should be 1h")
+}
+
+// TestExecCPUQuota verifies that a client which has used its CPU quota is
+// rejected with 429 while other clients are unaffected.
+func TestExecCPUQuota(t *testing.T) {
+	lc := &logCapture{}
+	srv := newExecTestServer(t, Config{
+		AllowedCommands: []string{"sh"},
+		ExecRateBurst:   100,
+		ExecCPUQuota:    time.Millisecond,
+		Logger:          slog.New(lc),
+	})
+
+	// Burn well over 1ms of CPU so the budget is overdrawn.
+	busy := `{"argv":["sh","-c","i=0; while [ $i -lt 200000 ]; do i=$((i+1)); done"]}`
+	rec := httptest.NewRecorder()
+	srv.handleExec(rec, makeFakeRequest("POST", "/v1/exec", busy, "gt-gastown-hog"))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	srv.handleExec(rec, makeFakeRequest("POST", "/v1/exec", `{"argv":["sh","-c","true"]}`, "gt-gastown-hog"))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Contains(t, rec.Body.String(), "cpu quota exceeded")
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	_, ok := lc.findEntry(slog.LevelWarn, "exec cpu quota exceeded")
+	assert.True(t, ok, "expected WARN 'exec cpu quota exceeded' log entry")
+
+	rec = httptest.NewRecorder()
+	srv.handleExec(rec, makeFakeRequest("POST", "/v1/exec", `{"argv":["sh","-c","true"]}`, "gt-gastown-other"))
+	assert.Equal(t, http.StatusOK, rec.Code, "quotas are per client")
+}
+
+func TestCPUBudgetRefill(t *testing.T) {
+	now := time.Now()
+	b := &cpuBudget{}
+	b.refill(now, time.Minute, time.Hour)
+	assert.Equal(t, time.Minute, b.available, "new budgets start full")
+
+	b.available = -time.Minute
+	b.refill(now.Add(30*time.Minute), time.Minute, time.Hour)
+	assert.Equal(t, -30*time.Second, b.available, "refills at quota per window")
+
+	b.refill(now.Add(10*time.Hour), time.Minute, time.Hour)
+	assert.Equal(t, time.Minute, b.available, "refill is capped at the quota")
 }
 
 // TestExecTimeout verifies that a per-command timeout kills a slow subprocess.
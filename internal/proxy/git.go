@@ -33,6 +33,8 @@
 //     (format: "gt-<rig>-<name>") is parsed to extract the polecat name.
 //     Every pushed ref must match refs/heads/polecat/<name>-*; any other
 //     ref is rejected with 403 before git ever sees the request body.
+//   - The rig's push_policy, if any, is then applied (see policy.go) and
+//     violations are reported to the client as rejected refs.
 //   - git-upload-pack is unrestricted for any authenticated client (read-only).
 //   - Subprocesses inherit only HOME and PATH from the server environment;
 //     no credentials, tokens, or secrets are visible to git.
@@ -181,6 +183,12 @@ func (s *Server) handlePack(w http.ResponseWriter, r *http.Request, repoPath, se
 			s.log.Warn("git push denied", "identity", identity, "rig", rig, "refs", refs)
 			return
 		}
+		// Then the rig's push policy (ref rules, protected paths, pack size).
+		ok, cleanup := s.enforcePushPolicy(w, r, repoPath, rig, clientCN)
+		defer cleanup()
+		if !ok {
+			return
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/x-"+service+"-result")
@@ -219,52 +227,56 @@ func (s *Server) authorizeReceivePack(w http.ResponseWriter, r *http.Request, cl
 	// Read only the pkt-line ref-update section (up to the flush packet),
 	// then stream the remaining pack data through to git untouched.
 	// This avoids loading the entire (potentially large) pack into memory.
+	pktBytes, err := readPktLineSection(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false, nil
+	}
+
+	// Collect refs before validation so they are available for audit logging even
+	// when authorization is denied.
+	refs := collectReceivePackRefs(pktBytes)
+
+	if err := validateReceivePackRefs(pktBytes, cnName); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return false, refs
+	}
+
+	// Reconstruct body: pkt-line prefix + remaining pack data (streamed).
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(pktBytes), r.Body))
+	return true, refs
+}
+
+// readPktLineSection reads pkt-lines from body up to and including the flush
+// packet ("0000") and returns them verbatim. The pack data that follows is
+// left unread.
+func readPktLineSection(body io.Reader) ([]byte, error) {
 	const maxPktLineSection = 256 << 10 // 256 KiB: more than enough for ref lines
 	var pktBuf bytes.Buffer
-	limited := io.LimitReader(r.Body, maxPktLineSection)
+	limited := io.LimitReader(body, maxPktLineSection)
 
 	// Read pkt-lines until the flush packet ("0000") or limit.
 	var tmp [4]byte
 	for {
-		_, err := io.ReadFull(limited, tmp[:])
-		if err != nil {
-			http.Error(w, "read pkt-line header: "+err.Error(), http.StatusBadRequest)
-			return false, nil
+		if _, err := io.ReadFull(limited, tmp[:]); err != nil {
+			return nil, fmt.Errorf("read pkt-line header: %w", err)
 		}
 		pktBuf.Write(tmp[:])
 		// Flush packet terminates the ref-update section.
 		if bytes.Equal(tmp[:], []byte("0000")) {
-			break
+			return pktBuf.Bytes(), nil
 		}
 		var pktLen int
-		_, err = fmt.Sscanf(string(tmp[:]), "%x", &pktLen)
+		_, err := fmt.Sscanf(string(tmp[:]), "%x", &pktLen)
 		if err != nil || pktLen < 4 {
-			http.Error(w, "malformed pkt-line length", http.StatusBadRequest)
-			return false, nil
+			return nil, errors.New("malformed pkt-line length")
 		}
 		payload := make([]byte, pktLen-4)
-		_, err = io.ReadFull(limited, payload)
-		if err != nil {
-			http.Error(w, "read pkt-line payload: "+err.Error(), http.StatusBadRequest)
-			return false, nil
+		if _, err := io.ReadFull(limited, payload); err != nil {
+			return nil, fmt.Errorf("read pkt-line payload: %w", err)
 		}
 		pktBuf.Write(payload)
 	}
-
-	pktBytes := pktBuf.Bytes()
-
-	// Collect refs before validation so they are available for audit logging even
-	// when authorization is denied.
-	refs := collectReceivePackRefs(pktBytes)
-
-	if err := validateReceivePackRefs(pktBytes, cnName); err != nil {
-		http.Error(w, err.Error(), http.StatusForbidden)
-		return false, refs
-	}
-
-	// Reconstruct body: pkt-line prefix + remaining pack data (streamed).
-	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(pktBytes), r.Body))
-	return true, refs
 }
 
 // collectReceivePackRefs parses the git-receive-pack pkt-line stream and returns
@@ -0,0 +1,493 @@
+// Push policy
+//
+// A rig may declare a push_policy in <rig>/settings/config.json (see
+// config.PushPolicyConfig). It is enforced on git-receive-pack after the
+// CN-scoped branch check, and before git-receive-pack ever runs:
+//
+//   - Ref rules (allowed_refs, no_delete) are checked against the pkt-line
+//     ref-update commands.
+//   - Pack rules (max_pack_bytes, no_force_push, protected_paths) need the
+//     pushed objects.  The pack is spooled to a temp file and indexed into a
+//     throwaway object directory that borrows the bare repo's objects as an
+//     alternate, so the new commits can be inspected with ordinary git
+//     plumbing without touching the repo.  If the push is accepted the
+//     spooled pack is replayed to git-receive-pack unchanged.
+//
+// A push is all-or-nothing: if any ref fails, every ref is rejected.
+// Rejections are answered with a receive-pack report-status ("ng <ref>
+// <reason>"), plus side-band progress lines when the client asked for them,
+// so git prints the reason as "! [remote rejected] <ref> (<reason>)" rather
+// than an opaque HTTP error.  Each rejected ref is logged and recorded as a
+// push_rejected event in the town's events log.
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// pushCommand is one ref-update command from a receive-pack request.
+type pushCommand struct {
+	oldSHA string
+	newSHA string
+	ref    string
+}
+
+func (c pushCommand) isCreate() bool { return isZeroSHA(c.oldSHA) }
+func (c pushCommand) isDelete() bool { return isZeroSHA(c.newSHA) }
+
+func isZeroSHA(sha string) bool {
+	return sha != "" && strings.Trim(sha, "0") == ""
+}
+
+// parsePushCommands parses the pkt-line ref-update section of a receive-pack
+// request into its commands and the capabilities sent with the first one.
+func parsePushCommands(body []byte) ([]pushCommand, []string) {
+	var cmds []pushCommand
+	var caps []string
+	offset := 0
+	for offset+4 <= len(body) {
+		lenHex := body[offset : offset+4]
+		if bytes.Equal(lenHex, []byte("0000")) {
+			break
+		}
+		var pktLen int
+		_, err := fmt.Sscanf(string(lenHex), "%x", &pktLen)
+		if err != nil || pktLen < 4 || offset+pktLen > len(body) {
+			break
+		}
+		line := bytes.TrimRight(body[offset+4:offset+pktLen], "\n")
+		offset += pktLen
+
+		if idx := bytes.IndexByte(line, 0); idx >= 0 {
+			if len(cmds) == 0 {
+				caps = strings.Fields(string(line[idx+1:]))
+			}
+			line = line[:idx]
+		}
+		parts := strings.Fields(string(line))
+		if len(parts) < 3 {
+			continue
+		}
+		cmds = append(cmds, pushCommand{oldSHA: parts[0], newSHA: parts[1], ref: parts[2]})
+	}
+	return cmds, caps
+}
+
+func hasCapability(caps []string, name string) bool {
+	for _, c := range caps {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// loadPushPolicy returns the rig's push policy, or nil if it has none.
+func (s *Server) loadPushPolicy(rig string) (*config.PushPolicyConfig, error) {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(filepath.Join(s.cfg.TownRoot, rig)))
+	if errors.Is(err, config.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return settings.PushPolicy, nil
+}
+
+// enforcePushPolicy applies the rig's push policy to a receive-pack request
+// that has already passed authorizeReceivePack. On rejection it writes the
+// response and returns false. On success it rewinds r.Body for git; the
+// returned cleanup func (never nil) must be called once git is done with it.
+func (s *Server) enforcePushPolicy(w http.ResponseWriter, r *http.Request, repoPath, rig, clientCN string) (bool, func()) {
+	noop := func() {}
+	policy, err := s.loadPushPolicy(rig)
+	if err != nil {
+		// Fail closed: a broken policy must not silently allow everything.
+		s.log.Error("load push policy failed", "rig", rig, "err", err)
+		http.Error(w, "cannot load rig push policy", http.StatusInternalServerError)
+		return false, noop
+	}
+	if policy == nil {
+		return true, noop
+	}
+
+	pktBytes, err := readPktLineSection(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false, noop
+	}
+	cmds, caps := parsePushCommands(pktBytes)
+	rejections := checkRefRules(policy, polecatName(clientCN), cmds)
+
+	var pack io.Reader = r.Body
+	cleanup := noop
+	if len(rejections) == 0 && (policy.MaxPackBytes > 0 || policy.NoForcePush || len(policy.ProtectedPaths) > 0) {
+		dir, err := os.MkdirTemp("", "gt-push-")
+		if err != nil {
+			s.log.Error("create push quarantine failed", "rig", rig, "err", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return false, noop
+		}
+		cleanup = func() { _ = os.RemoveAll(dir) }
+
+		packPath := filepath.Join(dir, "incoming.pack")
+		n, err := spoolPack(r.Body, packPath, policy.MaxPackBytes)
+		if err != nil {
+			cleanup()
+			http.Error(w, "read pack: "+err.Error(), http.StatusBadRequest)
+			return false, noop
+		}
+		if policy.MaxPackBytes > 0 && n > policy.MaxPackBytes {
+			rejections = rejectAll(cmds, fmt.Sprintf("pack exceeds the rig's %d-byte limit", policy.MaxPackBytes))
+		} else {
+			rejections, err = inspectPushedCommits(r.Context(), repoPath, dir, packPath, n, policy, cmds)
+			if err != nil {
+				cleanup()
+				s.log.Error("inspect push failed", "rig", rig, "err", err)
+				http.Error(w, "internal error inspecting push", http.StatusInternalServerError)
+				return false, noop
+			}
+		}
+
+		f, err := os.Open(packPath) //nolint:gosec // G304: path is inside our own temp dir
+		if err != nil {
+			cleanup()
+			s.log.Error("reopen spooled pack failed", "rig", rig, "err", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return false, noop
+		}
+		removeDir := cleanup
+		cleanup = func() {
+			_ = f.Close()
+			removeDir()
+		}
+		pack = f
+	}
+
+	if len(rejections) > 0 {
+		cleanup()
+		s.rejectPush(w, rig, clientCN, cmds, caps, rejections)
+		return false, noop
+	}
+
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(pktBytes), pack))
+	return true, cleanup
+}
+
+// checkRefRules applies the policy's ref rules and returns the rejected refs
+// with their reasons.
+func checkRefRules(policy *config.PushPolicyConfig, polecat string, cmds []pushCommand) map[string]string {
+	var allowed []*regexp.Regexp
+	for _, p := range policy.AllowedRefs {
+		allowed = append(allowed, compileGlob(strings.ReplaceAll(p, "{polecat}", polecat), false))
+	}
+
+	rejections := make(map[string]string)
+	for _, c := range cmds {
+		if c.isDelete() {
+			if policy.NoDelete {
+				rejections[c.ref] = "deleting refs is not allowed"
+			}
+			continue
+		}
+		if len(allowed) > 0 && !matchAny(allowed, c.ref) {
+			rejections[c.ref] = "ref does not match allowed_refs (" + strings.Join(policy.AllowedRefs, ", ") + ")"
+		}
+	}
+	return rejections
+}
+
+func rejectAll(cmds []pushCommand, reason string) map[string]string {
+	rejections := make(map[string]string, len(cmds))
+	for _, c := range cmds {
+		rejections[c.ref] = reason
+	}
+	return rejections
+}
+
+// spoolPack copies the pack data to path, stopping one byte past limit
+// (when limit > 0) so oversized packs are detected without reading them in
+// full. It returns the number of bytes written.
+func spoolPack(body io.Reader, path string, limit int64) (int64, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600) //nolint:gosec // G304: path is inside our own temp dir
+	if err != nil {
+		return 0, err
+	}
+	if limit > 0 {
+		body = io.LimitReader(body, limit+1)
+	}
+	n, err := io.Copy(f, body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return n, err
+}
+
+// inspectPushedCommits indexes the spooled pack into a quarantine object
+// directory under dir and applies the policy's commit rules to each updated
+// ref.
+func inspectPushedCommits(ctx context.Context, repoPath, dir, packPath string, packSize int64, policy *config.PushPolicyConfig, cmds []pushCommand) (map[string]string, error) {
+	objDir := filepath.Join(dir, "objects")
+	if err := os.MkdirAll(filepath.Join(objDir, "pack"), 0700); err != nil {
+		return nil, err
+	}
+	env := append(minimalEnv(),
+		"GIT_OBJECT_DIRECTORY="+objDir,
+		"GIT_ALTERNATE_OBJECT_DIRECTORIES="+filepath.Join(repoPath, "objects"),
+	)
+	git := func(stdin io.Reader, args ...string) ([]byte, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "git", append([]string{"--git-dir=" + repoPath}, args...)...)
+		cmd.Stdin = stdin
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		cmd.Env = env
+		err := cmd.Run()
+		return stdout.Bytes(), err
+	}
+
+	// Deletion-only pushes carry no pack.
+	if packSize > 0 {
+		f, err := os.Open(packPath) //nolint:gosec // G304: path is inside our own temp dir
+		if err != nil {
+			return nil, err
+		}
+		_, err = git(f, "index-pack", "--stdin", "--fix-thin")
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("index-pack: %w", err)
+		}
+	}
+
+	var protected []*regexp.Regexp
+	for _, p := range policy.ProtectedPaths {
+		protected = append(protected, compileGlob(strings.Trim(p, "/"), true))
+	}
+
+	// Merges are also checked by their net change against the default
+	// branch (see below); an empty repo has nothing to protect yet.
+	var target string
+	if len(protected) > 0 {
+		if out, err := git(nil, "rev-parse", "--verify", "--quiet", "HEAD^{commit}"); err == nil {
+			target = strings.TrimSpace(string(out))
+		}
+	}
+
+	rejections := make(map[string]string)
+	for _, c := range cmds {
+		if c.isDelete() {
+			continue
+		}
+		if policy.NoForcePush && !c.isCreate() {
+			_, err := git(nil, "merge-base", "--is-ancestor", c.oldSHA, c.newSHA)
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+				rejections[c.ref] = "force pushes are not allowed"
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("merge-base %s: %w", c.ref, err)
+			}
+		}
+		if len(protected) > 0 {
+			// New commits only: anything already reachable from a ref in the
+			// repo was checked (or trusted) when it arrived. -c limits merges
+			// to paths that differ from every parent, so merging main in
+			// doesn't count as touching main's files.
+			out, err := git(nil, "log", "--format=%x01%H", "-z", "--name-only", "--no-renames", "-c",
+				c.newSHA, "--not", "--all")
+			if err != nil {
+				return nil, fmt.Errorf("log %s: %w", c.ref, err)
+			}
+			if commit, path := findProtectedPath(out, protected); path != "" {
+				rejections[c.ref] = fmt.Sprintf("commit %s touches protected path %s", shortSHA(commit), path)
+				continue
+			}
+			// -c misses a merge that resolves a protected path to one
+			// side's older version: it differs from the other parent only.
+			// The net change against the default branch still shows it.
+			if target == "" {
+				continue
+			}
+			merges, err := git(nil, "rev-list", "--merges", c.newSHA, "--not", "--all")
+			if err != nil {
+				return nil, fmt.Errorf("rev-list %s: %w", c.ref, err)
+			}
+			if len(bytes.TrimSpace(merges)) == 0 {
+				continue
+			}
+			base, err := git(nil, "merge-base", target, c.newSHA)
+			if err != nil {
+				// Unrelated histories: every path is new, and the per-commit
+				// check above already saw them.
+				continue
+			}
+			out, err = git(nil, "diff", "--name-only", "-z", "--no-renames", strings.TrimSpace(string(base)), c.newSHA)
+			if err != nil {
+				return nil, fmt.Errorf("diff %s: %w", c.ref, err)
+			}
+			if _, path := findProtectedPath(out, protected); path != "" {
+				rejections[c.ref] = fmt.Sprintf("merge in %s changes protected path %s against the default branch", shortSHA(c.newSHA), path)
+			}
+		}
+	}
+	return rejections, nil
+}
+
+// findProtectedPath scans "git log --format=%x01%H -z --name-only" output for
+// the first path matching a protected pattern.
+func findProtectedPath(out []byte, protected []*regexp.Regexp) (commit, path string) {
+	for _, tok := range strings.Split(string(out), "\x00") {
+		tok = strings.TrimPrefix(tok, "\n")
+		if strings.HasPrefix(tok, "\x01") {
+			commit = tok[1:]
+			continue
+		}
+		if tok != "" && matchAny(protected, tok) {
+			return commit, tok
+		}
+	}
+	return "", ""
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
+
+func matchAny(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileGlob turns a policy glob into an anchored regexp. For refs, "*"
+// matches anything. For paths, "*" and "?" stay within a segment, "**"
+// spans segments, and a match on a directory covers everything below it.
+func compileGlob(pattern string, isPath bool) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for rest := pattern; rest != ""; {
+		switch {
+		case isPath && strings.HasPrefix(rest, "**/"):
+			b.WriteString("(?:.*/)?")
+			rest = rest[3:]
+		case isPath && strings.HasPrefix(rest, "**"):
+			b.WriteString(".*")
+			rest = rest[2:]
+		case rest[0] == '*':
+			if isPath {
+				b.WriteString("[^/]*")
+			} else {
+				b.WriteString(".*")
+			}
+			rest = rest[1:]
+		case isPath && rest[0] == '?':
+			b.WriteString("[^/]")
+			rest = rest[1:]
+		default:
+			r, size := utf8.DecodeRuneInString(rest)
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			rest = rest[size:]
+		}
+	}
+	if isPath {
+		b.WriteString("(?:/.*)?")
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// rejectPush logs and records each rejected ref, then answers the client.
+func (s *Server) rejectPush(w http.ResponseWriter, rig, clientCN string, cmds []pushCommand, caps []string, rejections map[string]string) {
+	identity := cnToIdentity(clientCN)
+	for _, c := range cmds {
+		reason, ok := rejections[c.ref]
+		if !ok {
+			continue
+		}
+		s.log.Warn("git push rejected by policy", "identity", identity, "rig", rig, "ref", c.ref, "reason", reason)
+		_ = events.LogAt(s.cfg.TownRoot, events.TypePushRejected, identity,
+			events.PushRejectedPayload(rig, polecatName(clientCN), c.ref, reason))
+	}
+	writeReceivePackRejection(w, cmds, caps, rejections)
+}
+
+// writeReceivePackRejection answers a rejected push in the receive-pack
+// result format so git reports each ref as "[remote rejected]" with its
+// reason. Clients that didn't ask for report-status get a plain 403.
+func writeReceivePackRejection(w http.ResponseWriter, cmds []pushCommand, caps []string, rejections map[string]string) {
+	var messages []string
+	for _, c := range cmds {
+		if reason, ok := rejections[c.ref]; ok {
+			messages = append(messages, fmt.Sprintf("push policy: %s: %s", c.ref, reason))
+		}
+	}
+	if !hasCapability(caps, "report-status") && !hasCapability(caps, "report-status-v2") {
+		http.Error(w, strings.Join(messages, "\n"), http.StatusForbidden)
+		return
+	}
+
+	var report bytes.Buffer
+	report.WriteString(encodePktLine("unpack ok\n"))
+	for _, c := range cmds {
+		reason, ok := rejections[c.ref]
+		if !ok {
+			reason = "rejected with the rest of the push"
+		}
+		report.WriteString(encodePktLine("ng " + c.ref + " " + reason + "\n"))
+	}
+	report.WriteString("0000")
+
+	w.Header().Set("Content-Type", "application/x-git-receive-pack-result")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	maxData := 0
+	switch {
+	case hasCapability(caps, "side-band-64k"):
+		maxData = 65520 - 5
+	case hasCapability(caps, "side-band"):
+		maxData = 1000 - 5
+	}
+	if maxData == 0 {
+		_, _ = w.Write(report.Bytes())
+		return
+	}
+
+	// Band 2 is progress text, shown by git as "remote: ..."; band 1
+	// carries the report itself.
+	for _, msg := range messages {
+		_, _ = io.WriteString(w, encodePktLine("\x02"+msg+"\n"))
+	}
+	data := report.Bytes()
+	for len(data) > 0 {
+		n := min(len(data), maxData)
+		_, _ = io.WriteString(w, encodePktLine("\x01"+string(data[:n])))
+		data = data[n:]
+	}
+	_, _ = io.WriteString(w, "0000")
+}
+
+// encodePktLine frames s as a single git pkt-line.
+func encodePktLine(s string) string {
+	return fmt.Sprintf("%04x%s", len(s)+4, s)
+}
@@ -0,0 +1,361 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// ---- parsing and matching ----
+
+func TestParsePushCommands(t *testing.T) {
+	zero := strings.Repeat("0", 40)
+	sha := strings.Repeat("a", 40)
+	body := pktLine(zero+" "+sha+" refs/heads/polecat/furiosa-1\x00report-status side-band-64k\n") +
+		pktLine(sha+" "+zero+" refs/heads/polecat/furiosa-2\n") +
+		"0000PACK..."
+
+	cmds, caps := parsePushCommands([]byte(body))
+	require.Len(t, cmds, 2)
+	assert.Equal(t, "refs/heads/polecat/furiosa-1", cmds[0].ref)
+	assert.True(t, cmds[0].isCreate())
+	assert.True(t, cmds[1].isDelete())
+	assert.Equal(t, []string{"report-status", "side-band-64k"}, caps)
+}
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		isPath  bool
+		input   string
+		want    bool
+	}{
+		{"refs/heads/polecat/*", false, "refs/heads/polecat/furiosa-1/x", true},
+		{"refs/heads/polecat/furiosa-gt-*", false, "refs/heads/polecat/furiosa-abc", false},
+		{"go.mod", true, "go.mod", true},
+		{"go.mod", true, "sub/go.mod", false},
+		{"**/go.sum", true, "go.sum", true},
+		{"**/go.sum", true, "a/b/go.sum", true},
+		{".github", true, ".github/workflows/ci.yml", true},
+		{".github/workflows/*.yml", true, ".github/workflows/ci.yml", true},
+		{".github/workflows/*.yml", true, ".github/workflows/sub/ci.yaml", false},
+		{"docs/**", true, "docs/a/b.md", true},
+		{"v?.txt", true, "v1.txt", true},
+		{"a+b", true, "aab", false},
+	}
+	for _, tt := range tests {
+		got := compileGlob(tt.pattern, tt.isPath).MatchString(tt.input)
+		assert.Equal(t, tt.want, got, "compileGlob(%q).Match(%q)", tt.pattern, tt.input)
+	}
+}
+
+func TestCheckRefRules(t *testing.T) {
+	zero := strings.Repeat("0", 40)
+	sha := strings.Repeat("a", 40)
+	cmds := []pushCommand{
+		{oldSHA: zero, newSHA: sha, ref: "refs/heads/polecat/furiosa-gt-12"},
+		{oldSHA: zero, newSHA: sha, ref: "refs/heads/polecat/furiosa-scratch"},
+		{oldSHA: sha, newSHA: zero, ref: "refs/heads/polecat/furiosa-old"},
+	}
+
+	got := checkRefRules(&config.PushPolicyConfig{}, "furiosa", cmds)
+	assert.Empty(t, got, "empty policy allows everything")
+
+	got = checkRefRules(&config.PushPolicyConfig{
+		AllowedRefs: []string{"refs/heads/polecat/{polecat}-gt-*"},
+		NoDelete:    true,
+	}, "furiosa", cmds)
+	assert.NotContains(t, got, "refs/heads/polecat/furiosa-gt-12")
+	assert.Contains(t, got["refs/heads/polecat/furiosa-scratch"], "allowed_refs")
+	assert.Equal(t, "deleting refs is not allowed", got["refs/heads/polecat/furiosa-old"])
+}
+
+func TestFindProtectedPath(t *testing.T) {
+	out := []byte("\x01aaaa\x00\nREADME\x00\x01bbbb\x00\nsrc/main.go\x00.github/workflows/ci.yml\x00")
+	commit, path := findProtectedPath(out, []*regexp.Regexp{compileGlob(".github", true)})
+	assert.Equal(t, "bbbb", commit)
+	assert.Equal(t, ".github/workflows/ci.yml", path)
+
+	_, path = findProtectedPath(out, []*regexp.Regexp{compileGlob("go.mod", true)})
+	assert.Empty(t, path)
+}
+
+// ---- rejection response ----
+
+func TestWriteReceivePackRejection(t *testing.T) {
+	cmds := []pushCommand{{ref: "refs/heads/a"}, {ref: "refs/heads/b"}}
+	rejections := map[string]string{"refs/heads/a": "go.mod is protected"}
+
+	t.Run("side-band wraps report and sends progress", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		writeReceivePackRejection(rec, cmds, []string{"report-status", "side-band-64k"}, rejections)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/x-git-receive-pack-result", rec.Header().Get("Content-Type"))
+
+		report := pktLine("unpack ok\n") +
+			pktLine("ng refs/heads/a go.mod is protected\n") +
+			pktLine("ng refs/heads/b rejected with the rest of the push\n") + "0000"
+		want := pktLine("\x02push policy: refs/heads/a: go.mod is protected\n") +
+			pktLine("\x01"+report) + "0000"
+		assert.Equal(t, want, rec.Body.String())
+	})
+
+	t.Run("plain report-status", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		writeReceivePackRejection(rec, cmds, []string{"report-status"}, rejections)
+		assert.True(t, strings.HasPrefix(rec.Body.String(), pktLine("unpack ok\n")))
+	})
+
+	t.Run("no report-status falls back to 403", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		writeReceivePackRejection(rec, cmds, nil, rejections)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "go.mod is protected")
+	})
+}
+
+// ---- enforcement ----
+
+func writePushPolicy(t *testing.T, townRoot, rig string, policy *config.PushPolicyConfig) {
+	t.Helper()
+	path := config.RigSettingsPath(filepath.Join(townRoot, rig))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	data, err := json.Marshal(&config.RigSettings{Type: "rig-settings", Version: 1, PushPolicy: policy})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+}
+
+func TestEnforcePushPolicyBrokenSettingsFailsClosed(t *testing.T) {
+	srv, townRoot := newGitServer(t)
+	path := config.RigSettingsPath(filepath.Join(townRoot, "testrip"))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0644))
+
+	req := fakeGitRequest("POST", "/v1/git/testrip/git-receive-pack",
+		receivePackBody("refs/heads/polecat/furiosa-abc"), "gt-gastown-furiosa")
+	rec := httptest.NewRecorder()
+	srv.handleGit(rec, req)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+// TestPushPolicyIntegration pushes with a real git client through handleGit
+// and checks each policy rule is enforced and reported to the client.
+func TestPushPolicyIntegration(t *testing.T) {
+	gitPath := requireGit(t)
+
+	lc := &logCapture{}
+	townRoot := t.TempDir()
+	srv, err := New(Config{TownRoot: townRoot, Logger: slog.New(lc)}, nil)
+	require.NoError(t, err)
+	rig := makeBareRepo(t, gitPath, townRoot)
+
+	// Plain HTTP with the polecat's cert identity injected, so the real git
+	// client can drive handleGit without an mTLS setup.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{
+			{Subject: pkix.Name{CommonName: "gt-gastown-raider"}},
+		}}
+		srv.handleGit(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	repoURL := ts.URL + "/v1/git/" + rig
+
+	localRepo := t.TempDir()
+	baseEnv := append(os.Environ(),
+		"GIT_CONFIG_NOSYSTEM=1",
+		"HOME="+t.TempDir(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_AUTHOR_NAME=Test Polecat",
+		"GIT_AUTHOR_EMAIL=test@gt.local",
+		"GIT_COMMITTER_NAME=Test Polecat",
+		"GIT_COMMITTER_EMAIL=test@gt.local",
+	)
+	git := func(args ...string) (string, error) {
+		cmd := exec.Command(gitPath, args...)
+		cmd.Dir = localRepo
+		cmd.Env = baseEnv
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+	mustGit := func(args ...string) {
+		t.Helper()
+		out, err := git(args...)
+		require.NoError(t, err, "git %v: %s", args, out)
+	}
+	commitFile := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(localRepo, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(localRepo, name), []byte(content), 0644))
+		mustGit("add", name)
+		mustGit("commit", "-m", "change "+name)
+	}
+
+	mustGit("init")
+	commitFile("README", "hello")
+
+	writePushPolicy(t, townRoot, rig, &config.PushPolicyConfig{
+		NoDelete:       true,
+		NoForcePush:    true,
+		ProtectedPaths: []string{"go.mod", ".github"},
+	})
+
+	t.Run("allowed push succeeds", func(t *testing.T) {
+		out, err := git("push", repoURL, "HEAD:refs/heads/polecat/raider-1")
+		assert.NoError(t, err, out)
+	})
+
+	t.Run("protected path is rejected with reason", func(t *testing.T) {
+		commitFile(".github/workflows/ci.yml", "on: push")
+		commitFile("main.go", "package main")
+		out, err := git("push", repoURL, "HEAD:refs/heads/polecat/raider-1")
+		require.Error(t, err, out)
+		assert.Contains(t, out, "[remote rejected]")
+		assert.Contains(t, out, "touches protected path .github/workflows/ci.yml")
+		assert.Contains(t, out, "remote: push policy:")
+		mustGit("reset", "--hard", "HEAD~2")
+	})
+
+	t.Run("force push is rejected", func(t *testing.T) {
+		mustGit("commit", "--amend", "-m", "rewritten")
+		out, err := git("push", "--force", repoURL, "HEAD:refs/heads/polecat/raider-1")
+		require.Error(t, err, out)
+		assert.Contains(t, out, "force pushes are not allowed")
+	})
+
+	t.Run("delete is rejected", func(t *testing.T) {
+		out, err := git("push", repoURL, ":refs/heads/polecat/raider-1")
+		require.Error(t, err, out)
+		assert.Contains(t, out, "deleting refs is not allowed")
+	})
+
+	t.Run("oversized pack is rejected", func(t *testing.T) {
+		writePushPolicy(t, townRoot, rig, &config.PushPolicyConfig{MaxPackBytes: 64})
+		commitFile("big.txt", strings.Repeat("x", 4096))
+		out, err := git("push", repoURL, "HEAD:refs/heads/polecat/raider-2")
+		require.Error(t, err, out)
+		assert.Contains(t, out, "pack exceeds the rig's 64-byte limit")
+	})
+
+	t.Run("rejections are logged and recorded as events", func(t *testing.T) {
+		e, ok := lc.findEntry(slog.LevelWarn, "git push rejected by policy")
+		require.True(t, ok)
+		assert.Equal(t, "gastown/raider", e.attrs["identity"])
+
+		data, err := os.ReadFile(filepath.Join(townRoot, events.EventsFile))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"type":"push_rejected"`)
+		assert.Contains(t, string(data), `"reason":"deleting refs is not allowed"`)
+	})
+
+	// The rejected pushes never reached the repo.
+	cmd := exec.Command(gitPath, "--git-dir="+filepath.Join(townRoot, rig, ".repo.git"),
+		"ls-tree", "-r", "--name-only", "refs/heads/polecat/raider-1")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Equal(t, "README\n", string(out))
+}
+
+// TestPushPolicyEvilMerge pushes a merge that resolves a protected path back
+// to the older version on its branch. No commit in the push touches the
+// path on its own, but the merge reverts the default branch's change to it.
+func TestPushPolicyEvilMerge(t *testing.T) {
+	gitPath := requireGit(t)
+
+	townRoot := t.TempDir()
+	srv, err := New(Config{TownRoot: townRoot}, nil)
+	require.NoError(t, err)
+	rig := makeBareRepo(t, gitPath, townRoot)
+	bare := filepath.Join(townRoot, rig, ".repo.git")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{
+			{Subject: pkix.Name{CommonName: "gt-gastown-raider"}},
+		}}
+		srv.handleGit(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	repoURL := ts.URL + "/v1/git/" + rig
+
+	localRepo := t.TempDir()
+	env := append(os.Environ(),
+		"GIT_CONFIG_NOSYSTEM=1",
+		"HOME="+t.TempDir(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_AUTHOR_NAME=Test Polecat",
+		"GIT_AUTHOR_EMAIL=test@gt.local",
+		"GIT_COMMITTER_NAME=Test Polecat",
+		"GIT_COMMITTER_EMAIL=test@gt.local",
+	)
+	git := func(args ...string) (string, error) {
+		cmd := exec.Command(gitPath, args...)
+		cmd.Dir = localRepo
+		cmd.Env = env
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+	mustGit := func(args ...string) {
+		t.Helper()
+		out, err := git(args...)
+		require.NoError(t, err, "git %v: %s", args, out)
+	}
+	commitFile := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(localRepo, name), []byte(content), 0644))
+		mustGit("add", name)
+		mustGit("commit", "-m", "change "+name)
+	}
+
+	// main changes go.mod after feature branched off. Polecats can't push
+	// main, so it goes straight into the rig's repo.
+	mustGit("init", "-b", "main")
+	commitFile("go.mod", "module v1")
+	mustGit("branch", "feature")
+	commitFile("go.mod", "module v2")
+	mustGit("push", bare, "main:refs/heads/main")
+	cmd := exec.Command(gitPath, "--git-dir="+bare, "symbolic-ref", "HEAD", "refs/heads/main")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	mustGit("checkout", "feature")
+	commitFile("feature.txt", "work")
+	writePushPolicy(t, townRoot, rig, &config.PushPolicyConfig{ProtectedPaths: []string{"go.mod"}})
+
+	t.Run("clean merge of main is allowed", func(t *testing.T) {
+		mustGit("checkout", "-b", "clean")
+		mustGit("merge", "--no-edit", "main")
+		out, err := git("push", repoURL, "HEAD:refs/heads/polecat/raider-clean")
+		assert.NoError(t, err, out)
+	})
+
+	t.Run("merge reverting a protected path is rejected", func(t *testing.T) {
+		mustGit("checkout", "-b", "evil", "feature")
+		mustGit("merge", "--no-commit", "main")
+		mustGit("checkout", "feature", "--", "go.mod")
+		mustGit("commit", "--no-edit")
+
+		// The per-commit check alone can't see it.
+		out, err := git("log", "--name-only", "--format=", "-c", "main..HEAD")
+		require.NoError(t, err, out)
+		require.NotContains(t, out, "go.mod")
+
+		out, err = git("push", repoURL, "HEAD:refs/heads/polecat/raider-evil")
+		require.Error(t, err, out)
+		assert.Contains(t, out, "changes protected path go.mod")
+	})
+}
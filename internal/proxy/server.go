@@ -57,7 +57,15 @@ type Config struct {
 	ExecRateBurst int
 	// ExecTimeout is the maximum duration a single exec subprocess may run.
 	// 0 uses the default (60s). Use a negative value to disable the timeout.
+	// It applies to streaming exec too.
 	ExecTimeout time.Duration
+	// ExecCPUQuota is the CPU time (user+system) each client may use per
+	// ExecCPUQuotaWindow across all its exec commands. A client that has used
+	// its quota gets 429 until enough of it refills. 0 disables the quota.
+	ExecCPUQuota time.Duration
+	// ExecCPUQuotaWindow is the period over which ExecCPUQuota refills.
+	// 0 uses the default (1h).
+	ExecCPUQuotaWindow time.Duration
 }
 
 // Server is an mTLS HTTP proxy server.
@@ -78,6 +86,10 @@ type Server struct {
 	rateLimiters sync.Map
 	rateLimit    rate.Limit
 	rateBurst    int
+	// cpuBudgets holds a *cpuBudget per client identity when cpuQuota > 0.
+	cpuBudgets sync.Map
+	cpuQuota   time.Duration
+	cpuWindow  time.Duration
 
 	lnMu    sync.Mutex
 	ln      net.Listener
@@ -154,6 +166,10 @@ func New(cfg Config, ca *CA) (*Server, error) {
 	if et == 0 {
 		et = 60 * time.Second
 	}
+	cw := cfg.ExecCPUQuotaWindow
+	if cw <= 0 {
+		cw = time.Hour
+	}
 
 	return &Server{
 		cfg:           cfg,
@@ -167,6 +183,8 @@ func New(cfg Config, ca *CA) (*Server, error) {
 		execTimeout:   et,
 		rateLimit:     rate.Limit(rl),
 		rateBurst:     rb,
+		cpuQuota:      cfg.ExecCPUQuota,
+		cpuWindow:     cw,
 	}, nil
 }
 
@@ -228,6 +246,7 @@ func (s *Server) Start(ctx context.Context) error {
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/v1/exec", s.handleExec)
+	mux.HandleFunc("/v1/exec/stream", s.handleExecStream)
 	mux.HandleFunc("/v1/git/", s.handleGit)
 
 	srv := &http.Server{
@@ -324,6 +343,13 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 }
 
+// ListenSANIPs returns the IP addresses a server certificate for listenAddr
+// should carry: loopback, plus every interface address when listening on an
+// unspecified address. Other mTLS servers built on this CA use it too.
+func ListenSANIPs(listenAddr string) []net.IP {
+	return serverListenIPs(listenAddr)
+}
+
 // serverListenIPs returns the IP addresses that should be included as IP SANs in the
 // server certificate. It parses the host portion of listenAddr and:
 //   - If it is a specific non-loopback IP, returns [that IP, 127.0.0.1, ::1].
@@ -0,0 +1,289 @@
+package ptyd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// ErrNotRunning is returned when no supervisor is listening on the socket.
+var ErrNotRunning = errors.New("pty supervisor not running (start the daemon with gt daemon start)")
+
+// detachKey ends an attach (Ctrl-]).
+const detachKey = 0x1d
+
+// Client talks to a Supervisor over its socket. Its methods mirror the
+// tmux.Tmux methods agent lifecycle code uses, so it can stand in for tmux.
+type Client struct {
+	socket  string
+	timeout time.Duration
+}
+
+// NewClient returns a client for the supervisor listening on socket.
+func NewClient(socket string) *Client {
+	return &Client{socket: socket, timeout: 10 * time.Second}
+}
+
+func (c *Client) dial() (net.Conn, error) {
+	conn, err := net.DialTimeout("unix", c.socket, c.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotRunning, err)
+	}
+	return conn, nil
+}
+
+// call sends req and decodes the response. Kill waits for the session to
+// exit, so calls get a deadline well past the kill grace period.
+func (c *Client) call(req request) (*response, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(c.timeout + 2*killGracePeriod))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("reading supervisor response: %w", err)
+	}
+	if resp.Error != "" {
+		return &resp, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+// IsAvailable reports whether the supervisor is reachable.
+func (c *Client) IsAvailable() bool {
+	_, err := c.call(request{Op: opList})
+	return err == nil
+}
+
+// NewSessionWithCommandAndEnv starts a session running command in workDir.
+func (c *Client) NewSessionWithCommandAndEnv(name, workDir, command string, env map[string]string) error {
+	_, err := c.call(request{Op: opNew, Session: name, WorkDir: workDir, Command: command, Env: env})
+	return err
+}
+
+// HasSession reports whether a session is running. A supervisor that isn't
+// running has no sessions.
+func (c *Client) HasSession(name string) (bool, error) {
+	resp, err := c.call(request{Op: opHas, Session: name})
+	if errors.Is(err, ErrNotRunning) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return resp.Exists, nil
+}
+
+// IsAgentAlive reports whether a session's agent is running. The
+// supervisor drops a session when its command exits, so a session that
+// exists has a live agent.
+func (c *Client) IsAgentAlive(session string) bool {
+	alive, _ := c.HasSession(session)
+	return alive
+}
+
+// ListSessions returns the running sessions.
+func (c *Client) ListSessions() ([]string, error) {
+	resp, err := c.call(request{Op: opList})
+	if errors.Is(err, ErrNotRunning) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.Sessions, nil
+}
+
+// KillSessionWithProcesses terminates a session and its process group.
+func (c *Client) KillSessionWithProcesses(name string) error {
+	_, err := c.call(request{Op: opKill, Session: name})
+	return err
+}
+
+// SetEnvironment records a session environment variable.
+func (c *Client) SetEnvironment(session, key, value string) error {
+	_, err := c.call(request{Op: opSetEnv, Session: session, Key: key, Value: value})
+	return err
+}
+
+// GetEnvironment reads a session environment variable.
+func (c *Client) GetEnvironment(session, key string) (string, error) {
+	resp, err := c.call(request{Op: opGetEnv, Session: session, Key: key})
+	if err != nil {
+		return "", err
+	}
+	return resp.Value, nil
+}
+
+// CapturePane returns the last lines of session output as plain text.
+func (c *Client) CapturePane(session string, lines int) (string, error) {
+	resp, err := c.call(request{Op: opCapture, Session: session, Lines: lines})
+	if err != nil {
+		return "", err
+	}
+	return resp.Value, nil
+}
+
+// GetPanePID returns the PID of the session's command, matching the
+// string form tmux reports for a pane.
+func (c *Client) GetPanePID(session string) (string, error) {
+	resp, err := c.call(request{Op: opPID, Session: session})
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(resp.PID), nil
+}
+
+func (c *Client) send(session, data string) error {
+	_, err := c.call(request{Op: opSend, Session: session, Data: data})
+	return err
+}
+
+// SendKeysRaw sends a tmux-style key (e.g. "C-c", "Enter", "Escape"), or
+// the text itself when it isn't a key name.
+func (c *Client) SendKeysRaw(session, keys string) error {
+	return c.send(session, keyBytes(keys))
+}
+
+// nudgeLocks serializes nudges per session, as tmux.NudgeSession does.
+var nudgeLocks sync.Map // map[string]*sync.Mutex
+
+// NudgeSession types message into the session and submits it, with the same
+// pacing tmux.NudgeSession uses so TUIs see the text, an Escape, then Enter.
+func (c *Client) NudgeSession(session, message string) error {
+	mu, _ := nudgeLocks.LoadOrStore(session, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	if err := c.send(session, sanitizeNudge(message)); err != nil {
+		return err
+	}
+	time.Sleep(500 * time.Millisecond)
+	_ = c.send(session, "\x1b")
+	time.Sleep(600 * time.Millisecond)
+	return c.send(session, "\r")
+}
+
+// AttachSession connects the current terminal to a session until the
+// session exits or the user presses Ctrl-].
+func (c *Client) AttachSession(session string) error {
+	conn, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	req := request{Op: opAttach, Session: session}
+	fd := int(os.Stdin.Fd())
+	if w, h, err := term.GetSize(fd); err == nil {
+		req.Rows, req.Cols = uint16(h), uint16(w) //nolint:gosec // G115: terminal sizes fit
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return err
+	}
+	dec := json.NewDecoder(conn)
+	var resp response
+	if err := dec.Decode(&resp); err != nil {
+		return fmt.Errorf("reading supervisor response: %w", err)
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+
+	if term.IsTerminal(fd) {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("setting raw mode: %w", err)
+		}
+		defer func() { _ = term.Restore(fd, state) }()
+	}
+	fmt.Fprintf(os.Stdout, "[attached to %s — Ctrl-] to detach]\r\n", session)
+
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if n > 0 {
+				if i := strings.IndexByte(string(buf[:n]), detachKey); i >= 0 {
+					_, _ = conn.Write(buf[:i])
+					_ = conn.Close()
+					return
+				}
+				if _, werr := conn.Write(buf[:n]); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	_, _ = io.Copy(os.Stdout, io.MultiReader(dec.Buffered(), conn))
+	fmt.Fprint(os.Stdout, "\r\n[detached]\r\n")
+	return nil
+}
+
+// keyBytes translates tmux key names to the bytes a terminal would send.
+func keyBytes(key string) string {
+	switch key {
+	case "Enter":
+		return "\r"
+	case "Escape":
+		return "\x1b"
+	case "Tab":
+		return "\t"
+	case "BSpace":
+		return "\x7f"
+	case "Space":
+		return " "
+	case "Up":
+		return "\x1b[A"
+	case "Down":
+		return "\x1b[B"
+	case "Right":
+		return "\x1b[C"
+	case "Left":
+		return "\x1b[D"
+	}
+	if len(key) == 3 && strings.HasPrefix(key, "C-") {
+		if ch := key[2] | 0x20; ch >= 'a' && ch <= 'z' {
+			return string(rune(ch - 'a' + 1))
+		}
+	}
+	return key
+}
+
+// sanitizeNudge drops control characters that would act as keystrokes
+// (the same rules tmux nudges apply): tabs become spaces, newlines stay.
+func sanitizeNudge(msg string) string {
+	var b strings.Builder
+	b.Grow(len(msg))
+	for _, r := range msg {
+		switch {
+		case r == '\t':
+			b.WriteRune(' ')
+		case r == '\n':
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
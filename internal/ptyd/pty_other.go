@@ -0,0 +1,25 @@
+//go:build !linux
+
+package ptyd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// errUnsupported is returned when the PTY supervisor can't run on this OS.
+var errUnsupported = errors.New("the pty session backend is only supported on Linux")
+
+func startInPTY(cmd *exec.Cmd, rows, cols uint16) (*os.File, error) {
+	return nil, errUnsupported
+}
+
+func setSize(master *os.File, rows, cols uint16) error {
+	return errUnsupported
+}
+
+func signalGroup(pid int, sig syscall.Signal) error {
+	return errUnsupported
+}
@@ -0,0 +1,113 @@
+package ptyd
+
+import (
+	"bytes"
+	"strings"
+)
+
+// scrollback keeps the most recent output of a session, up to max bytes.
+// It stores raw terminal output so attaching clients can replay it, and
+// renders plain text for captures.
+type scrollback struct {
+	buf []byte
+	max int
+}
+
+func newScrollback(max int) *scrollback {
+	return &scrollback{max: max}
+}
+
+// Write appends p, dropping the oldest output (whole lines where possible)
+// once the buffer exceeds its limit.
+func (s *scrollback) Write(p []byte) {
+	s.buf = append(s.buf, p...)
+	if over := len(s.buf) - s.max; over > 0 {
+		if i := bytes.IndexByte(s.buf[over:], '\n'); i >= 0 && i < s.max/4 {
+			over += i + 1
+		}
+		s.buf = append(s.buf[:0], s.buf[over:]...)
+	}
+}
+
+// Bytes returns a copy of the raw buffered output.
+func (s *scrollback) Bytes() []byte {
+	return append([]byte(nil), s.buf...)
+}
+
+// Lines renders the last n lines as plain text (all lines when n <= 0),
+// the way tmux capture-pane does: escape sequences are dropped, carriage
+// returns overwrite the line, and trailing blank lines are trimmed.
+func (s *scrollback) Lines(n int) string {
+	lines := strings.Split(renderText(s.buf), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderText strips terminal control sequences from raw output.
+func renderText(raw []byte) string {
+	var out strings.Builder
+	var line []byte
+	flush := func() {
+		out.Write(line)
+		line = line[:0]
+	}
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == 0x1b:
+			i = skipEscape(raw, i)
+		case c == '\n':
+			flush()
+			out.WriteByte('\n')
+		case c == '\r':
+			if i+1 < len(raw) && raw[i+1] == '\n' {
+				continue
+			}
+			line = line[:0]
+		case c == '\b':
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+			}
+		case c == '\t' || c >= 0x20:
+			line = append(line, c)
+		}
+	}
+	flush()
+	return out.String()
+}
+
+// skipEscape returns the index of the last byte of the escape sequence
+// starting at raw[i].
+func skipEscape(raw []byte, i int) int {
+	if i+1 >= len(raw) {
+		return i
+	}
+	switch raw[i+1] {
+	case '[': // CSI: parameters, then a final byte in 0x40–0x7e
+		for j := i + 2; j < len(raw); j++ {
+			if raw[j] >= 0x40 && raw[j] <= 0x7e {
+				return j
+			}
+		}
+		return len(raw) - 1
+	case ']', 'P', '_': // OSC/DCS/APC: terminated by BEL or ST
+		for j := i + 2; j < len(raw); j++ {
+			if raw[j] == 0x07 {
+				return j
+			}
+			if raw[j] == 0x1b && j+1 < len(raw) && raw[j+1] == '\\' {
+				return j + 1
+			}
+		}
+		return len(raw) - 1
+	case '(', ')', '#': // charset selection takes one more byte
+		return min(i+2, len(raw)-1)
+	default:
+		return i + 1
+	}
+}
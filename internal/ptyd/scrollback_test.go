@@ -0,0 +1,59 @@
+package ptyd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScrollbackLines(t *testing.T) {
+	s := newScrollback(1024)
+	s.Write([]byte("one\r\n\x1b[1;32mtwo\x1b[0m\r\nprogress 10%\rprogress 100%\r\n\x1b]0;title\x07three\r\n\r\n"))
+
+	if got, want := s.Lines(0), "one\ntwo\nprogress 100%\nthree"; got != want {
+		t.Errorf("Lines(0) = %q, want %q", got, want)
+	}
+	if got, want := s.Lines(2), "progress 100%\nthree"; got != want {
+		t.Errorf("Lines(2) = %q, want %q", got, want)
+	}
+}
+
+func TestScrollbackTrimsOldestLines(t *testing.T) {
+	s := newScrollback(64)
+	for i := 0; i < 20; i++ {
+		s.Write([]byte("line-of-output\n"))
+	}
+	s.Write([]byte("last\n"))
+
+	if n := len(s.Bytes()); n > 64 {
+		t.Errorf("buffer holds %d bytes, want <= 64", n)
+	}
+	lines := strings.Split(s.Lines(0), "\n")
+	if lines[0] != "line-of-output" {
+		t.Errorf("first line = %q, want a whole line after trimming", lines[0])
+	}
+	if lines[len(lines)-1] != "last" {
+		t.Errorf("last line = %q, want %q", lines[len(lines)-1], "last")
+	}
+}
+
+func TestKeyBytes(t *testing.T) {
+	tests := map[string]string{
+		"Enter":  "\r",
+		"Escape": "\x1b",
+		"Down":   "\x1b[B",
+		"C-c":    "\x03",
+		"C-D":    "\x04",
+		"hello":  "hello",
+	}
+	for key, want := range tests {
+		if got := keyBytes(key); got != want {
+			t.Errorf("keyBytes(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestSanitizeNudge(t *testing.T) {
+	if got, want := sanitizeNudge("a\tb\x1b[Ac\nd\x7f"), "a b[Ac\nd"; got != want {
+		t.Errorf("sanitizeNudge() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,160 @@
+package ptyd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// SocketPath returns the supervisor socket for a town.
+func SocketPath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "pty.sock")
+}
+
+// Operations understood by the supervisor socket.
+const (
+	opNew     = "new"
+	opHas     = "has"
+	opList    = "list"
+	opKill    = "kill"
+	opSend    = "send"
+	opCapture = "capture"
+	opSetEnv  = "setenv"
+	opGetEnv  = "getenv"
+	opAttach  = "attach"
+	opPID     = "pid"
+)
+
+// request is one call on the supervisor socket. Each connection carries a
+// single request; after a successful attach the connection becomes a raw
+// terminal stream.
+type request struct {
+	Op      string            `json:"op"`
+	Session string            `json:"session,omitempty"`
+	WorkDir string            `json:"work_dir,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Key     string            `json:"key,omitempty"`
+	Value   string            `json:"value,omitempty"`
+	Data    string            `json:"data,omitempty"`
+	Lines   int               `json:"lines,omitempty"`
+	Rows    uint16            `json:"rows,omitempty"`
+	Cols    uint16            `json:"cols,omitempty"`
+}
+
+type response struct {
+	Error    string   `json:"error,omitempty"`
+	Exists   bool     `json:"exists,omitempty"`
+	Sessions []string `json:"sessions,omitempty"`
+	Value    string   `json:"value,omitempty"`
+	PID      int      `json:"pid,omitempty"`
+}
+
+// Listen removes a stale socket at path and listens on it, readable only by
+// the owner.
+func Listen(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	_ = os.Remove(path)
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// Serve handles connections on ln until it is closed.
+func (s *Supervisor) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Supervisor) handle(conn net.Conn) {
+	dec := json.NewDecoder(conn)
+	var req request
+	if err := dec.Decode(&req); err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	if req.Op == opAttach {
+		s.handleAttach(conn, io.MultiReader(dec.Buffered(), conn), req)
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	var resp response
+	var err error
+	switch req.Op {
+	case opNew:
+		err = s.Start(req.Session, req.WorkDir, req.Command, req.Env)
+	case opHas:
+		resp.Exists = s.Has(req.Session)
+	case opList:
+		resp.Sessions = s.List()
+	case opKill:
+		err = s.Kill(req.Session)
+	case opSend:
+		err = s.Write(req.Session, []byte(req.Data))
+	case opCapture:
+		resp.Value, err = s.Capture(req.Session, req.Lines)
+	case opSetEnv:
+		err = s.SetEnv(req.Session, req.Key, req.Value)
+	case opGetEnv:
+		resp.Value, err = s.GetEnv(req.Session, req.Key)
+	case opPID:
+		resp.PID, err = s.PID(req.Session)
+	default:
+		err = errors.New("unknown op: " + req.Op)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	_ = json.NewEncoder(conn).Encode(resp)
+}
+
+// handleAttach acknowledges the attach, then streams session output to the
+// client and client input to the session until either side goes away.
+func (s *Supervisor) handleAttach(conn net.Conn, in io.Reader, req request) {
+	defer func() { _ = conn.Close() }()
+	if !s.Has(req.Session) {
+		_ = json.NewEncoder(conn).Encode(response{Error: "session not found: " + req.Session})
+		return
+	}
+	if err := json.NewEncoder(conn).Encode(response{}); err != nil {
+		return
+	}
+	if err := s.Attach(req.Session, conn, req.Rows, req.Cols); err != nil {
+		return
+	}
+	defer s.Detach(req.Session, conn)
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			if werr := s.Write(req.Session, buf[:n]); werr != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
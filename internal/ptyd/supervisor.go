@@ -0,0 +1,289 @@
+// Package ptyd is the headless session backend: a PTY supervisor that runs
+// agent sessions without tmux. The daemon owns the Supervisor and serves it
+// on a Unix socket; gt commands reach it through Client, which implements
+// the same session operations as tmux.Tmux.
+package ptyd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultScrollback is how much output each session keeps, in bytes.
+	DefaultScrollback = 1 << 20
+
+	// Sessions start at a fixed size; attaching resizes to the client terminal.
+	defaultRows = 50
+	defaultCols = 200
+
+	// killGracePeriod is how long a session gets after SIGTERM before SIGKILL.
+	killGracePeriod = 2 * time.Second
+)
+
+// Supervisor runs sessions on pseudo-terminals and keeps their scrollback.
+// A session is removed when its process exits.
+type Supervisor struct {
+	scrollback int
+
+	mu       sync.Mutex
+	sessions map[string]*ptySession
+}
+
+type ptySession struct {
+	name    string
+	cmd     *exec.Cmd
+	pty     *os.File
+	created time.Time
+	done    chan struct{}
+
+	mu      sync.Mutex
+	env     map[string]string
+	out     *scrollback
+	clients map[io.WriteCloser]struct{}
+}
+
+// NewSupervisor creates a supervisor keeping scrollback bytes of output per
+// session (DefaultScrollback when <= 0).
+func NewSupervisor(scrollback int) *Supervisor {
+	if scrollback <= 0 {
+		scrollback = DefaultScrollback
+	}
+	return &Supervisor{scrollback: scrollback, sessions: make(map[string]*ptySession)}
+}
+
+// Start runs command through sh in workDir on a new PTY. env is added to the
+// supervisor's environment and recorded as the session environment.
+func (s *Supervisor) Start(name, workDir, command string, env map[string]string) error {
+	if name == "" {
+		return fmt.Errorf("session name is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[name]; ok {
+		return fmt.Errorf("duplicate session: %s", name)
+	}
+
+	cmd := exec.Command("sh", "-c", command) //nolint:gosec // G204: command is the agent startup command
+	cmd.Dir = workDir
+	cmd.Env = os.Environ()
+	sessEnv := make(map[string]string, len(env))
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+		sessEnv[k] = v
+	}
+	master, err := startInPTY(cmd, defaultRows, defaultCols)
+	if err != nil {
+		return fmt.Errorf("starting session %s: %w", name, err)
+	}
+
+	sess := &ptySession{
+		name:    name,
+		cmd:     cmd,
+		pty:     master,
+		created: time.Now(),
+		done:    make(chan struct{}),
+		env:     sessEnv,
+		out:     newScrollback(s.scrollback),
+		clients: make(map[io.WriteCloser]struct{}),
+	}
+	s.sessions[name] = sess
+	go s.pump(sess)
+	return nil
+}
+
+// pump copies PTY output to the scrollback and attached clients until the
+// process exits, then removes the session.
+func (s *Supervisor) pump(sess *ptySession) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := sess.pty.Read(buf)
+		if n > 0 {
+			sess.mu.Lock()
+			sess.out.Write(buf[:n])
+			for c := range sess.clients {
+				if _, werr := c.Write(buf[:n]); werr != nil {
+					delete(sess.clients, c)
+					_ = c.Close()
+				}
+			}
+			sess.mu.Unlock()
+		}
+		if err != nil {
+			// EIO once the last process holding the slave side exits.
+			break
+		}
+	}
+	_ = sess.cmd.Wait()
+	_ = sess.pty.Close()
+
+	s.mu.Lock()
+	if s.sessions[sess.name] == sess {
+		delete(s.sessions, sess.name)
+	}
+	s.mu.Unlock()
+
+	sess.mu.Lock()
+	for c := range sess.clients {
+		_ = c.Close()
+	}
+	sess.clients = nil
+	sess.mu.Unlock()
+	close(sess.done)
+}
+
+func (s *Supervisor) get(name string) (*ptySession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[name]
+	if !ok {
+		return nil, fmt.Errorf("session not found: %s", name)
+	}
+	return sess, nil
+}
+
+// Has reports whether a session is running.
+func (s *Supervisor) Has(name string) bool {
+	_, err := s.get(name)
+	return err == nil
+}
+
+// List returns the names of running sessions, sorted.
+func (s *Supervisor) List() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.sessions))
+	for name := range s.sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PID returns the process ID of a session's command.
+func (s *Supervisor) PID(name string) (int, error) {
+	sess, err := s.get(name)
+	if err != nil {
+		return 0, err
+	}
+	return sess.cmd.Process.Pid, nil
+}
+
+// Kill terminates a session's process group: SIGTERM, then SIGKILL if it
+// hasn't exited within the grace period. It returns once the session is gone.
+func (s *Supervisor) Kill(name string) error {
+	sess, err := s.get(name)
+	if err != nil {
+		return err
+	}
+	pid := sess.cmd.Process.Pid
+	_ = signalGroup(pid, syscall.SIGTERM)
+	select {
+	case <-sess.done:
+		return nil
+	case <-time.After(killGracePeriod):
+	}
+	_ = signalGroup(pid, syscall.SIGKILL)
+	select {
+	case <-sess.done:
+		return nil
+	case <-time.After(killGracePeriod):
+		return fmt.Errorf("session %s did not exit after SIGKILL", name)
+	}
+}
+
+// Close kills every session.
+func (s *Supervisor) Close() {
+	for _, name := range s.List() {
+		_ = s.Kill(name)
+	}
+}
+
+// Write sends input to a session as if typed.
+func (s *Supervisor) Write(name string, data []byte) error {
+	sess, err := s.get(name)
+	if err != nil {
+		return err
+	}
+	_, err = sess.pty.Write(data)
+	return err
+}
+
+// Capture returns the last lines of a session's output as plain text.
+func (s *Supervisor) Capture(name string, lines int) (string, error) {
+	sess, err := s.get(name)
+	if err != nil {
+		return "", err
+	}
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.out.Lines(lines), nil
+}
+
+// SetEnv records a session environment variable. Like tmux set-environment,
+// it doesn't affect the running process; it's read back by GetEnv.
+func (s *Supervisor) SetEnv(name, key, value string) error {
+	sess, err := s.get(name)
+	if err != nil {
+		return err
+	}
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.env[key] = value
+	return nil
+}
+
+// GetEnv returns a session environment variable.
+func (s *Supervisor) GetEnv(name, key string) (string, error) {
+	sess, err := s.get(name)
+	if err != nil {
+		return "", err
+	}
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	v, ok := sess.env[key]
+	if !ok {
+		return "", fmt.Errorf("unknown variable: %s", key)
+	}
+	return v, nil
+}
+
+// Attach replays the scrollback to w and then streams live output to it
+// until the session exits (w is closed) or Detach is called. Non-zero rows
+// and cols resize the PTY to the client's terminal.
+func (s *Supervisor) Attach(name string, w io.WriteCloser, rows, cols uint16) error {
+	sess, err := s.get(name)
+	if err != nil {
+		return err
+	}
+	if rows > 0 && cols > 0 {
+		_ = setSize(sess.pty, rows, cols)
+	}
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.clients == nil {
+		return fmt.Errorf("session not found: %s", name)
+	}
+	if _, err := w.Write(sess.out.Bytes()); err != nil {
+		return err
+	}
+	sess.clients[w] = struct{}{}
+	return nil
+}
+
+// Detach stops streaming output to w.
+func (s *Supervisor) Detach(name string, w io.WriteCloser) {
+	sess, err := s.get(name)
+	if err != nil {
+		return
+	}
+	sess.mu.Lock()
+	delete(sess.clients, w)
+	sess.mu.Unlock()
+}
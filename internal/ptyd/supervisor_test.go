@@ -0,0 +1,134 @@
+//go:build linux
+
+package ptyd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTestSupervisor serves a supervisor on a temp socket and returns a
+// client for it.
+func startTestSupervisor(t *testing.T) *Client {
+	t.Helper()
+	if _, err := os.Stat("/dev/ptmx"); err != nil {
+		t.Skip("no /dev/ptmx")
+	}
+	// Unix socket paths are length-limited; t.TempDir() can be too long.
+	dir, err := os.MkdirTemp("", "ptyd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	sock := filepath.Join(dir, "pty.sock")
+	ln, err := Listen(sock)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	s := NewSupervisor(0)
+	go func() { _ = s.Serve(ln) }()
+	t.Cleanup(func() {
+		_ = ln.Close()
+		s.Close()
+	})
+	return NewClient(sock)
+}
+
+func waitForOutput(t *testing.T, c *Client, session, want string) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	var out string
+	for time.Now().Before(deadline) {
+		out, _ = c.CapturePane(session, 0)
+		if strings.Contains(out, want) {
+			return out
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("output of %s never contained %q; got %q", session, want, out)
+	return ""
+}
+
+func TestSupervisorSessionLifecycle(t *testing.T) {
+	c := startTestSupervisor(t)
+	dir := t.TempDir()
+
+	err := c.NewSessionWithCommandAndEnv("gt-test", dir, `echo "agent=$GT_ROLE in $(pwd)"; cat`, map[string]string{"GT_ROLE": "polecat"})
+	if err != nil {
+		t.Fatalf("NewSessionWithCommandAndEnv: %v", err)
+	}
+	if err := c.NewSessionWithCommandAndEnv("gt-test", dir, "true", nil); err == nil {
+		t.Error("starting a duplicate session succeeded")
+	}
+
+	if ok, err := c.HasSession("gt-test"); err != nil || !ok {
+		t.Fatalf("HasSession = %v, %v; want true", ok, err)
+	}
+	if !c.IsAgentAlive("gt-test") {
+		t.Error("IsAgentAlive = false for a running session")
+	}
+	if names, _ := c.ListSessions(); len(names) != 1 || names[0] != "gt-test" {
+		t.Errorf("ListSessions = %v", names)
+	}
+	waitForOutput(t, c, "gt-test", "agent=polecat in "+dir)
+
+	if pid, err := c.GetPanePID("gt-test"); err != nil || pid == "" || pid == "0" {
+		t.Errorf("GetPanePID = %q, %v", pid, err)
+	}
+
+	if v, err := c.GetEnvironment("gt-test", "GT_ROLE"); err != nil || v != "polecat" {
+		t.Errorf("GetEnvironment(GT_ROLE) = %q, %v", v, err)
+	}
+	if err := c.SetEnvironment("gt-test", "GT_HOOK", "gt-abc"); err != nil {
+		t.Fatalf("SetEnvironment: %v", err)
+	}
+	if v, _ := c.GetEnvironment("gt-test", "GT_HOOK"); v != "gt-abc" {
+		t.Errorf("GetEnvironment(GT_HOOK) = %q", v)
+	}
+
+	// cat echoes typed input back through the terminal.
+	if err := c.SendKeysRaw("gt-test", "ping"); err != nil {
+		t.Fatalf("SendKeysRaw: %v", err)
+	}
+	_ = c.SendKeysRaw("gt-test", "Enter")
+	waitForOutput(t, c, "gt-test", "ping")
+
+	if err := c.KillSessionWithProcesses("gt-test"); err != nil {
+		t.Fatalf("KillSessionWithProcesses: %v", err)
+	}
+	if ok, _ := c.HasSession("gt-test"); ok {
+		t.Error("session still present after kill")
+	}
+}
+
+func TestSupervisorDropsExitedSessions(t *testing.T) {
+	c := startTestSupervisor(t)
+	if err := c.NewSessionWithCommandAndEnv("gt-short", t.TempDir(), "exit 0", nil); err != nil {
+		t.Fatalf("NewSessionWithCommandAndEnv: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if ok, _ := c.HasSession("gt-short"); !ok {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Error("exited session was never removed")
+}
+
+func TestClientWithoutSupervisor(t *testing.T) {
+	c := NewClient(filepath.Join(t.TempDir(), "missing.sock"))
+	if ok, err := c.HasSession("gt-any"); ok || err != nil {
+		t.Errorf("HasSession = %v, %v; want false, nil", ok, err)
+	}
+	if c.IsAvailable() {
+		t.Error("IsAvailable() = true with no supervisor")
+	}
+	if err := c.NewSessionWithCommandAndEnv("gt-any", "", "true", nil); err == nil {
+		t.Error("NewSessionWithCommandAndEnv succeeded with no supervisor")
+	}
+}
@@ -0,0 +1,287 @@
+// Package recording captures agent tmux panes as asciicast v2 files and
+// plays or searches them afterwards.
+//
+// Recording is opt-in per rig (settings/config.json "recording"). When
+// enabled, polecat sessions pipe their pane output through tmux pipe-pane
+// into "gt session record", which timestamps each chunk and appends it to
+// <town>/.runtime/recordings/<rig>/<polecat>/<start>.cast. The files are
+// plain asciicast v2, so "asciinema play" works on them too. Old recordings
+// are removed by the KRC pruner using the session_recording TTL.
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Header is the first line of an asciicast v2 file.
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"` // unix seconds at start
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Start returns the recording start time from the header.
+func (h Header) Start() time.Time {
+	return time.Unix(h.Timestamp, 0)
+}
+
+// Event is one output chunk, Offset after the recording started.
+type Event struct {
+	Offset time.Duration
+	Data   string
+}
+
+// Writer appends asciicast v2 events to an underlying writer.
+type Writer struct {
+	w       *bufio.Writer
+	start   time.Time
+	pending []byte // trailing bytes of an incomplete UTF-8 sequence
+}
+
+// NewWriter writes the header and returns a Writer whose event offsets are
+// relative to start.
+func NewWriter(w io.Writer, hdr Header, start time.Time) (*Writer, error) {
+	hdr.Version = 2
+	if hdr.Timestamp == 0 {
+		hdr.Timestamp = start.Unix()
+	}
+	line, err := json.Marshal(hdr)
+	if err != nil {
+		return nil, err
+	}
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(append(line, '\n')); err != nil {
+		return nil, err
+	}
+	return &Writer{w: bw, start: start}, bw.Flush()
+}
+
+// WriteOutput records data as pane output at time t. Incomplete UTF-8
+// sequences at the end of data are held until the next call, since
+// asciicast events must be valid strings.
+func (w *Writer) WriteOutput(t time.Time, data []byte) error {
+	buf := append(w.pending, data...)
+	cut := len(buf)
+	// Hold back at most one partial rune (up to 3 bytes).
+	for i := len(buf) - 1; i >= 0 && i >= len(buf)-3; i-- {
+		if utf8.RuneStart(buf[i]) {
+			if !utf8.FullRune(buf[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	w.pending = append([]byte(nil), buf[cut:]...)
+	if cut == 0 {
+		return nil
+	}
+	return w.writeEvent(t, strings.ToValidUTF8(string(buf[:cut]), "�"))
+}
+
+func (w *Writer) writeEvent(t time.Time, data string) error {
+	offset := t.Sub(w.start).Seconds()
+	if offset < 0 {
+		offset = 0
+	}
+	line, err := json.Marshal([]interface{}{offset, "o", data})
+	if err != nil {
+		return err
+	}
+	if _, err := w.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// Close flushes any held-back bytes.
+func (w *Writer) Close(t time.Time) error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+	data := strings.ToValidUTF8(string(w.pending), "�")
+	w.pending = nil
+	return w.writeEvent(t, data)
+}
+
+// Record copies r into a new cast until EOF, timestamping each read with
+// now. It is the body of "gt session record", fed by tmux pipe-pane.
+func Record(r io.Reader, w io.Writer, hdr Header, now func() time.Time) error {
+	cw, err := NewWriter(w, hdr, now())
+	if err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			if err := cw.WriteOutput(now(), buf[:n]); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return cw.Close(now())
+		}
+		if readErr != nil {
+			_ = cw.Close(now())
+			return readErr
+		}
+	}
+}
+
+// Read parses an asciicast v2 stream. Input events and malformed lines are
+// skipped; a truncated final line (recording still in progress) is ignored.
+func Read(r io.Reader) (Header, []Event, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var hdr Header
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return hdr, nil, err
+		}
+		return hdr, nil, fmt.Errorf("empty recording")
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &hdr); err != nil {
+		return hdr, nil, fmt.Errorf("parsing header: %w", err)
+	}
+	if hdr.Version != 2 {
+		return hdr, nil, fmt.Errorf("unsupported asciicast version %d", hdr.Version)
+	}
+
+	var events []Event
+	for scanner.Scan() {
+		var raw []interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &raw); err != nil || len(raw) != 3 {
+			continue
+		}
+		offset, ok1 := raw[0].(float64)
+		kind, ok2 := raw[1].(string)
+		data, ok3 := raw[2].(string)
+		if !ok1 || !ok2 || !ok3 || kind != "o" {
+			continue
+		}
+		events = append(events, Event{
+			Offset: time.Duration(offset * float64(time.Second)),
+			Data:   data,
+		})
+	}
+	return hdr, events, scanner.Err()
+}
+
+// PlayOptions controls playback timing.
+type PlayOptions struct {
+	Speed   float64       // playback speed multiplier; <= 0 means 1
+	MaxIdle time.Duration // cap on pauses between events; 0 means no cap
+	From    time.Duration // output before this offset is written without delay
+	Sleep   func(time.Duration)
+}
+
+// Play writes events to w, pausing between them to reproduce the original
+// timing. Events before opts.From are written immediately so the terminal
+// state at that point is reconstructed before real-time playback starts.
+func Play(w io.Writer, events []Event, opts PlayOptions) error {
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	sleep := opts.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	last := opts.From
+	for _, e := range events {
+		if e.Offset > opts.From {
+			delay := e.Offset - last
+			if opts.MaxIdle > 0 && delay > opts.MaxIdle {
+				delay = opts.MaxIdle
+			}
+			if delay > 0 {
+				sleep(time.Duration(float64(delay) / speed))
+			}
+			last = e.Offset
+		}
+		if _, err := io.WriteString(w, e.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Match is a search hit within a recording.
+type Match struct {
+	Offset time.Duration
+	Line   string
+}
+
+// Search returns the output lines containing text (case-insensitive),
+// with ANSI escape sequences removed. Each line is reported at the offset
+// of the event that completed it.
+func Search(events []Event, text string) []Match {
+	needle := strings.ToLower(text)
+	var matches []Match
+	var line strings.Builder
+	flush := func(offset time.Duration) {
+		s := strings.TrimRight(line.String(), " \t")
+		line.Reset()
+		if s != "" && strings.Contains(strings.ToLower(s), needle) {
+			matches = append(matches, Match{Offset: offset, Line: s})
+		}
+	}
+	var last time.Duration
+	for _, e := range events {
+		last = e.Offset
+		for _, r := range StripANSI(e.Data) {
+			switch r {
+			case '\n':
+				flush(e.Offset)
+			case '\r':
+				// Carriage returns redraw the line; keep what follows.
+			default:
+				line.WriteRune(r)
+			}
+		}
+	}
+	flush(last)
+	return matches
+}
+
+// StripANSI removes CSI and OSC escape sequences from s.
+func StripANSI(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\033' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 >= len(s) {
+			break
+		}
+		switch s[i+1] {
+		case '[': // CSI: ESC [ params final-byte
+			i += 2
+			for i < len(s) && (s[i] < 0x40 || s[i] > 0x7e) {
+				i++
+			}
+		case ']': // OSC: ESC ] ... (BEL | ESC \)
+			i += 2
+			for i < len(s) && s[i] != '\a' && !(s[i] == '\033' && i+1 < len(s) && s[i+1] == '\\') {
+				i++
+			}
+			if i < len(s) && s[i] == '\033' {
+				i++
+			}
+		default: // two-byte sequence
+			i++
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package recording
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileExt is the extension of recording files.
+const FileExt = ".cast"
+
+// fileTimeLayout names recording files by their UTC start time so they sort
+// chronologically.
+const fileTimeLayout = "20060102T150405Z"
+
+// Dir returns the root directory holding all recordings for a town.
+func Dir(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "recordings")
+}
+
+// AgentDir returns the directory holding recordings for one polecat.
+func AgentDir(townRoot, rigName, polecat string) string {
+	return filepath.Join(Dir(townRoot), rigName, polecat)
+}
+
+// PathFor returns the file a recording of rigName/polecat starting at start
+// is written to.
+func PathFor(townRoot, rigName, polecat string, start time.Time) string {
+	return filepath.Join(AgentDir(townRoot, rigName, polecat), start.UTC().Format(fileTimeLayout)+FileExt)
+}
+
+// Info describes a recording on disk.
+type Info struct {
+	Path    string
+	Start   time.Time
+	ModTime time.Time // last write, i.e. roughly when the recording ended
+	Size    int64
+}
+
+// List returns the recordings for rigName/polecat, oldest first.
+func List(townRoot, rigName, polecat string) ([]Info, error) {
+	dir := AgentDir(townRoot, rigName, polecat)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []Info
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, FileExt) {
+			continue
+		}
+		start, err := time.Parse(fileTimeLayout, strings.TrimSuffix(name, FileExt))
+		if err != nil {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		out = append(out, Info{
+			Path:    filepath.Join(dir, name),
+			Start:   start,
+			ModTime: fi.ModTime(),
+			Size:    fi.Size(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out, nil
+}
+
+// FindAt returns the recording covering t: the latest one that started at
+// or before t. ok is false when every recording started after t.
+func FindAt(recs []Info, t time.Time) (Info, bool) {
+	for i := len(recs) - 1; i >= 0; i-- {
+		if !recs[i].Start.After(t) {
+			return recs[i], true
+		}
+	}
+	return Info{}, false
+}
+
+// PruneResult reports what Prune removed.
+type PruneResult struct {
+	Removed    int
+	BytesFreed int64
+}
+
+// Prune removes recordings last written more than ttl before now, then
+// removes agent directories left empty. A missing recordings directory is
+// not an error. Prune keeps going past files it cannot remove and returns
+// the first such error alongside the counts of what it did remove.
+func Prune(townRoot string, ttl time.Duration, now time.Time) (*PruneResult, error) {
+	result := &PruneResult{}
+	root := Dir(townRoot)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return result, nil
+	}
+
+	var dirs []string
+	var firstErr error
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != root {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), FileExt) {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if now.Sub(fi.ModTime()) <= ttl {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("removing %s: %w", path, err)
+			}
+			return nil
+		}
+		result.Removed++
+		result.BytesFreed += fi.Size()
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// Deepest first so rig directories empty out after their polecats.
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	for _, d := range dirs {
+		_ = os.Remove(d) // fails harmlessly when not empty
+	}
+	return result, firstErr
+}
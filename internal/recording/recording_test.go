@@ -0,0 +1,167 @@
+package recording
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chunkReader returns one chunk per Read call, like a pipe.
+type chunkReader struct{ chunks [][]byte }
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func TestRecordAndRead(t *testing.T) {
+	start := time.Date(2026, 3, 10, 3, 0, 0, 0, time.UTC)
+	clock := start
+	now := func() time.Time {
+		t := clock
+		clock = clock.Add(500 * time.Millisecond)
+		return t
+	}
+
+	// "é" is split across two reads; the writer must not emit half a rune.
+	e := []byte("é")
+	in := &chunkReader{chunks: [][]byte{
+		[]byte("hello\r\n"),
+		append([]byte("caf"), e[0]),
+		append([]byte{e[1]}, []byte("\r\n")...),
+	}}
+	var buf bytes.Buffer
+	if err := Record(in, &buf, Header{Width: 80, Height: 24, Title: "gastown/polecats/Toast"}, now); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	hdr, events, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if hdr.Version != 2 || hdr.Width != 80 || !hdr.Start().Equal(start) {
+		t.Errorf("header = %+v", hdr)
+	}
+	var out strings.Builder
+	for _, ev := range events {
+		out.WriteString(ev.Data)
+	}
+	if out.String() != "hello\r\ncafé\r\n" {
+		t.Errorf("output = %q", out.String())
+	}
+	if events[0].Offset != 500*time.Millisecond {
+		t.Errorf("first offset = %v", events[0].Offset)
+	}
+}
+
+func TestReadSkipsTruncatedLine(t *testing.T) {
+	cast := `{"version":2,"width":80,"height":24,"timestamp":1773111600}
+[0.5,"o","one\r\n"]
+[1.0,"i","typed"]
+[1.5,"o","two`
+	_, events, err := Read(strings.NewReader(cast))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Data != "one\r\n" {
+		t.Errorf("events = %+v", events)
+	}
+}
+
+func TestPlaySeeksAndCapsIdle(t *testing.T) {
+	events := []Event{
+		{Offset: 1 * time.Second, Data: "a"},
+		{Offset: 5 * time.Second, Data: "b"},
+		{Offset: 6 * time.Second, Data: "c"},
+		{Offset: 60 * time.Second, Data: "d"},
+	}
+	var slept []time.Duration
+	var out bytes.Buffer
+	err := Play(&out, events, PlayOptions{
+		Speed:   2,
+		MaxIdle: 10 * time.Second,
+		From:    5 * time.Second,
+		Sleep:   func(d time.Duration) { slept = append(slept, d) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "abcd" {
+		t.Errorf("output = %q", out.String())
+	}
+	// a and b are replayed instantly; c waits 1s/2, d waits min(54s,10s)/2.
+	want := []time.Duration{500 * time.Millisecond, 5 * time.Second}
+	if len(slept) != len(want) || slept[0] != want[0] || slept[1] != want[1] {
+		t.Errorf("sleeps = %v, want %v", slept, want)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	events := []Event{
+		{Offset: time.Second, Data: "\x1b[32mok\x1b[0m build\r\n"},
+		{Offset: 2 * time.Second, Data: "panic: nil "},
+		{Offset: 3 * time.Second, Data: "map\r\n\x1b]0;title\x07done"},
+	}
+	got := Search(events, "PANIC")
+	if len(got) != 1 || got[0].Line != "panic: nil map" || got[0].Offset != 3*time.Second {
+		t.Errorf("Search = %+v", got)
+	}
+	if got := Search(events, "done"); len(got) != 1 || got[0].Line != "done" {
+		t.Errorf("Search trailing = %+v", got)
+	}
+}
+
+func TestListFindAndPrune(t *testing.T) {
+	townRoot := t.TempDir()
+	early := time.Date(2026, 3, 9, 22, 0, 0, 0, time.UTC)
+	late := time.Date(2026, 3, 10, 2, 0, 0, 0, time.UTC)
+	for _, start := range []time.Time{late, early} {
+		path := PathFor(townRoot, "gastown", "Toast", start)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, start, start); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recs, err := List(townRoot, "gastown", "Toast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 || !recs[0].Start.Equal(early) || !recs[1].Start.Equal(late) {
+		t.Fatalf("List = %+v", recs)
+	}
+	if r, ok := FindAt(recs, late.Add(-time.Hour)); !ok || !r.Start.Equal(early) {
+		t.Errorf("FindAt(01:00) = %+v, %v", r, ok)
+	}
+	if _, ok := FindAt(recs, early.Add(-time.Hour)); ok {
+		t.Error("FindAt before first recording should fail")
+	}
+
+	result, err := Prune(townRoot, 2*time.Hour, late.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Removed != 1 {
+		t.Errorf("Prune removed %d, want 1", result.Removed)
+	}
+	result, err = Prune(townRoot, 0, late.Add(time.Hour))
+	if err != nil || result.Removed != 1 {
+		t.Fatalf("second Prune = %+v, %v", result, err)
+	}
+	if _, err := os.Stat(filepath.Join(Dir(townRoot), "gastown")); !os.IsNotExist(err) {
+		t.Error("empty rig directory should be removed")
+	}
+}
@@ -115,6 +115,13 @@ func (e *Engineer) BuildRebaseStack(ctx context.Context, batch []*MRInfo, target
 
 	// Try to stack each MR via squash-merge
 	for _, mr := range batch {
+		// A stacked MR whose base didn't make it onto the stack waits for
+		// the base; it is not at fault.
+		if mr.BlockedBy != "" && !hasMR(stacked, mr.BlockedBy) {
+			_, _ = fmt.Fprintf(e.output, "[Batch] MR %s: base %s not stacked, deferring\n", mr.ID, mr.BlockedBy)
+			continue
+		}
+
 		_, _ = fmt.Fprintf(e.output, "[Batch] Stacking MR %s (branch %s)...\n", mr.ID, mr.Branch)
 
 		// Check branch exists
@@ -127,6 +134,11 @@ func (e *Engineer) BuildRebaseStack(ctx context.Context, batch []*MRInfo, target
 
 		// Check for conflicts before merging
 		conflictFiles, conflictErr := e.git.CheckConflicts(mr.Branch, target)
+		if conflictErr == nil && len(conflictFiles) > 0 && e.autoResolveConflicts(ctx, mr) {
+			// Resolved onto the stack; gates on the stack tip verify it.
+			stacked = append(stacked, mr)
+			continue
+		}
 		if conflictErr != nil || len(conflictFiles) > 0 {
 			_, _ = fmt.Fprintf(e.output, "[Batch] MR %s: conflicts detected, removing from batch\n", mr.ID)
 			conflicts = append(conflicts, mr)
@@ -137,8 +149,7 @@ func (e *Engineer) BuildRebaseStack(ctx context.Context, batch []*MRInfo, target
 			}
 			// Rebuild the stack with MRs stacked so far (minus the conflicting one)
 			for _, prev := range stacked {
-				msg := e.getMergeMessage(prev)
-				if mergeErr := e.git.MergeSquash(prev.Branch, msg); mergeErr != nil {
+				if mergeErr := e.squashOntoStack(ctx, prev); mergeErr != nil {
 					return nil, nil, fmt.Errorf("rebuild stack for %s: %w", prev.ID, mergeErr)
 				}
 			}
@@ -156,8 +167,7 @@ func (e *Engineer) BuildRebaseStack(ctx context.Context, batch []*MRInfo, target
 				return nil, nil, fmt.Errorf("reset after merge failure: %w", resetErr)
 			}
 			for _, prev := range stacked {
-				prevMsg := e.getMergeMessage(prev)
-				if rebuildErr := e.git.MergeSquash(prev.Branch, prevMsg); rebuildErr != nil {
+				if rebuildErr := e.squashOntoStack(ctx, prev); rebuildErr != nil {
 					return nil, nil, fmt.Errorf("rebuild stack for %s: %w", prev.ID, rebuildErr)
 				}
 			}
@@ -194,17 +204,30 @@ func (e *Engineer) getMergeMessage(mr *MRInfo) string {
 //  4. If red and RetryBatchOnFlaky: retry the full batch once
 //  5. If still red: bisect to isolate the culprit
 //  6. Re-batch good MRs for the next cycle
-func (e *Engineer) ProcessBatch(ctx context.Context, batch []*MRInfo, target string, batchCfg *BatchConfig) *BatchResult {
+func (e *Engineer) ProcessBatch(ctx context.Context, batch []*MRInfo, target string, batchCfg *BatchConfig) (result *BatchResult) {
 	if batchCfg == nil {
 		batchCfg = DefaultBatchConfig()
 	}
 
-	result := &BatchResult{}
+	result = &BatchResult{}
 
 	if len(batch) == 0 {
 		return result
 	}
 
+	e.processMu.Lock()
+	defer e.processMu.Unlock()
+
+	// Rebuild stacked MRs on reworked bases before stacking the batch.
+	for _, mr := range batch {
+		if mr.StackOn != "" {
+			e.syncStack(mr)
+		}
+	}
+
+	e.beginBatchState(batch, target)
+	defer func() { e.finishBatchState(result) }()
+
 	// Single MR: use existing doMerge path (no batch overhead)
 	if len(batch) == 1 {
 		return e.processSingleMR(ctx, batch[0], target)
@@ -219,6 +242,10 @@ func (e *Engineer) ProcessBatch(ctx context.Context, batch []*MRInfo, target str
 		return result
 	}
 	result.Conflicts = conflicts
+	e.updateBatchState(func(s *BatchState) {
+		s.Stacked = mrIDs(stacked)
+		s.Conflicts = mrIDs(conflicts)
+	})
 
 	if len(stacked) == 0 {
 		_, _ = fmt.Fprintln(e.output, "[Batch] No MRs could be stacked (all conflicted)")
@@ -234,6 +261,7 @@ func (e *Engineer) ProcessBatch(ctx context.Context, batch []*MRInfo, target str
 
 	// Step 2: Run gates on the stack tip
 	_, _ = fmt.Fprintf(e.output, "[Batch] Running gates on stack tip (%d MRs)...\n", len(stacked))
+	e.setBatchPhase(BatchPhaseGating)
 	gateResult := e.runBatchGates(ctx)
 
 	// Step 3: Happy path — all green
@@ -244,9 +272,10 @@ func (e *Engineer) ProcessBatch(ctx context.Context, batch []*MRInfo, target str
 	// Step 4: Retry if flaky test handling is enabled
 	if batchCfg.RetryBatchOnFlaky {
 		_, _ = fmt.Fprintln(e.output, "[Batch] Gates failed, retrying full batch (flaky test check)...")
+		e.setBatchPhase(BatchPhaseRetrying)
 
 		// Rebuild the stack from scratch for a clean retry
-		if resetErr := e.resetAndRebuildStack(stacked, target); resetErr != nil {
+		if resetErr := e.resetAndRebuildStack(ctx, stacked, target); resetErr != nil {
 			result.Error = fmt.Errorf("rebuild for retry: %w", resetErr)
 			return result
 		}
@@ -261,18 +290,32 @@ func (e *Engineer) ProcessBatch(ctx context.Context, batch []*MRInfo, target str
 
 	// Step 5: Bisect to find the culprit
 	_, _ = fmt.Fprintf(e.output, "[Batch] Bisecting %d MRs to isolate failure...\n", len(stacked))
+	e.updateBatchState(func(s *BatchState) {
+		s.Phase = BatchPhaseBisecting
+		s.Bisect = &BisectState{}
+	})
 	good, culprits := e.bisectBatch(ctx, stacked, target)
+	// MRs stacked on a culprit can't land without it.
+	good = withoutOrphans(good)
 
 	result.Culprits = culprits
+	e.updateBatchState(func(s *BatchState) {
+		if s.Bisect != nil {
+			s.Bisect.Testing = nil
+			s.Bisect.KnownGood = mrIDs(good)
+			s.Bisect.Culprits = mrIDs(culprits)
+		}
+	})
 
 	// Step 6: If we found good MRs, merge them
 	if len(good) > 0 {
 		_, _ = fmt.Fprintf(e.output, "[Batch] Merging %d good MRs after bisection\n", len(good))
-		if resetErr := e.resetAndRebuildStack(good, target); resetErr != nil {
+		if resetErr := e.resetAndRebuildStack(ctx, good, target); resetErr != nil {
 			result.Error = fmt.Errorf("rebuild good MRs: %w", resetErr)
 			return result
 		}
 		// Verify the good subset actually passes
+		e.setBatchPhase(BatchPhaseGating)
 		verifyResult := e.runBatchGates(ctx)
 		if verifyResult.Success {
 			return e.fastForwardBatch(ctx, good, target, result)
@@ -304,6 +347,7 @@ func (e *Engineer) processSingleMR(ctx context.Context, mr *MRInfo, target strin
 
 // runBatchGates runs quality gates (or legacy tests) on the current working tree.
 func (e *Engineer) runBatchGates(ctx context.Context) ProcessResult {
+	e.updateBatchState(func(s *BatchState) { s.Gates = nil })
 	if len(e.config.Gates) > 0 {
 		return e.runGates(ctx)
 	}
@@ -370,14 +414,29 @@ func (e *Engineer) fastForwardBatch(ctx context.Context, stacked []*MRInfo, targ
 		}()
 	}
 
-	// Push to origin
-	_, _ = fmt.Fprintf(e.output, "[Batch] Pushing %d merged MRs to origin/%s...\n", len(stacked), target)
-	if pushErr := e.git.Push("origin", target, false); pushErr != nil {
-		if resetErr := e.git.ResetHard("origin/" + target); resetErr != nil {
-			_, _ = fmt.Fprintf(e.output, "[Batch] Warning: failed to reset %s after push failure: %v\n", target, resetErr)
+	// Push to origin, or land through the forge's PR flow when the
+	// upstream requires it
+	e.setBatchPhase(BatchPhasePushing)
+	if e.config.Forge != nil {
+		_, _ = fmt.Fprintf(e.output, "[Batch] Landing %d merged MRs on %s via forge...\n", len(stacked), target)
+		sha, landErr := e.landViaForge(ctx, target, "batch-"+tipSHA[:min(8, len(tipSHA))])
+		if landErr != nil {
+			if resetErr := e.git.ResetHard("origin/" + target); resetErr != nil {
+				_, _ = fmt.Fprintf(e.output, "[Batch] Warning: failed to reset %s after forge failure: %v\n", target, resetErr)
+			}
+			result.Error = fmt.Errorf("landing via forge: %w", landErr)
+			return result
+		}
+		tipSHA = sha
+	} else {
+		_, _ = fmt.Fprintf(e.output, "[Batch] Pushing %d merged MRs to origin/%s...\n", len(stacked), target)
+		if pushErr := e.git.Push("origin", target, false); pushErr != nil {
+			if resetErr := e.git.ResetHard("origin/" + target); resetErr != nil {
+				_, _ = fmt.Fprintf(e.output, "[Batch] Warning: failed to reset %s after push failure: %v\n", target, resetErr)
+			}
+			result.Error = fmt.Errorf("push to origin: %w", pushErr)
+			return result
 		}
-		result.Error = fmt.Errorf("push to origin: %w", pushErr)
-		return result
 	}
 
 	ids := make([]string, len(stacked))
@@ -407,11 +466,12 @@ func (e *Engineer) bisectBatch(ctx context.Context, batch []*MRInfo, target stri
 	_, _ = fmt.Fprintf(e.output, "[Bisect] Testing left half (%d MRs)...\n", len(left))
 
 	// Test the left half
-	if resetErr := e.resetAndRebuildStack(left, target); resetErr != nil {
+	if resetErr := e.resetAndRebuildStack(ctx, left, target); resetErr != nil {
 		_, _ = fmt.Fprintf(e.output, "[Bisect] Error rebuilding left half: %v, treating all as culprits\n", resetErr)
 		return nil, batch
 	}
 
+	e.recordBisectRound(left)
 	leftResult := e.runBatchGates(ctx)
 
 	if leftResult.Success {
@@ -432,10 +492,11 @@ func (e *Engineer) bisectBatch(ctx context.Context, batch []*MRInfo, target stri
 	// Test right half in context of leftGood
 	if len(leftGood) > 0 {
 		combined := append(leftGood, right...)
-		if resetErr := e.resetAndRebuildStack(combined, target); resetErr != nil {
+		if resetErr := e.resetAndRebuildStack(ctx, combined, target); resetErr != nil {
 			_, _ = fmt.Fprintf(e.output, "[Bisect] Error testing right with good left: %v\n", resetErr)
 			return leftGood, append(leftCulprits, right...)
 		}
+		e.recordBisectRound(combined)
 		combinedResult := e.runBatchGates(ctx)
 		if combinedResult.Success {
 			return append(leftGood, right...), leftCulprits
@@ -446,9 +507,10 @@ func (e *Engineer) bisectBatch(ctx context.Context, batch []*MRInfo, target stri
 	}
 
 	// No good MRs in left half, test right half alone
-	if resetErr := e.resetAndRebuildStack(right, target); resetErr != nil {
+	if resetErr := e.resetAndRebuildStack(ctx, right, target); resetErr != nil {
 		return nil, batch
 	}
+	e.recordBisectRound(right)
 	rightResult := e.runBatchGates(ctx)
 	if rightResult.Success {
 		return right, leftCulprits
@@ -471,11 +533,12 @@ func (e *Engineer) bisectRight(ctx context.Context, knownGood []*MRInfo, right [
 
 	// Test knownGood + rLeft
 	testBatch := append(append([]*MRInfo{}, knownGood...), rLeft...)
-	if resetErr := e.resetAndRebuildStack(testBatch, target); resetErr != nil {
+	if resetErr := e.resetAndRebuildStack(ctx, testBatch, target); resetErr != nil {
 		_, _ = fmt.Fprintf(e.output, "[Bisect] Error rebuilding for right bisection: %v\n", resetErr)
 		return nil, right
 	}
 
+	e.recordBisectRound(testBatch)
 	result := e.runBatchGates(ctx)
 	if result.Success {
 		// rLeft is fine in context of knownGood — culprit is in rRight
@@ -493,9 +556,10 @@ func (e *Engineer) bisectRight(ctx context.Context, knownGood []*MRInfo, right [
 	// Test rRight with knownGood + rLeftGood
 	_, _ = fmt.Fprintf(e.output, "[Bisect-R] Testing rRight=%v with knownGood+rLeftGood=%v\n", mrIDs(rRight), mrIDs(append(append([]*MRInfo{}, knownGood...), rLeftGood...)))
 	testBatch2 := append(append(append([]*MRInfo{}, knownGood...), rLeftGood...), rRight...)
-	if resetErr := e.resetAndRebuildStack(testBatch2, target); resetErr != nil {
+	if resetErr := e.resetAndRebuildStack(ctx, testBatch2, target); resetErr != nil {
 		return rLeftGood, append(rLeftCulprits, rRight...)
 	}
+	e.recordBisectRound(testBatch2)
 	result2 := e.runBatchGates(ctx)
 	if result2.Success {
 		_, _ = fmt.Fprintf(e.output, "[Bisect-R] rRight passed → good=%v, culprits=%v\n", mrIDs(append(rLeftGood, rRight...)), mrIDs(rLeftCulprits))
@@ -505,6 +569,22 @@ func (e *Engineer) bisectRight(ctx context.Context, knownGood []*MRInfo, right [
 	return append(rLeftGood, rRightGood...), append(rLeftCulprits, rRightCulprits...)
 }
 
+// setBatchPhase records the current batch phase.
+func (e *Engineer) setBatchPhase(phase BatchPhase) {
+	e.updateBatchState(func(s *BatchState) { s.Phase = phase })
+}
+
+// recordBisectRound records the subset about to be tested during bisection.
+func (e *Engineer) recordBisectRound(testing []*MRInfo) {
+	e.updateBatchState(func(s *BatchState) {
+		if s.Bisect == nil {
+			s.Bisect = &BisectState{}
+		}
+		s.Bisect.Round++
+		s.Bisect.Testing = mrIDs(testing)
+	})
+}
+
 // mrIDs returns the IDs of a slice of MRInfo for logging.
 func mrIDs(mrs []*MRInfo) []string {
 	ids := make([]string, len(mrs))
@@ -515,7 +595,7 @@ func mrIDs(mrs []*MRInfo) []string {
 }
 
 // resetAndRebuildStack resets the target branch and rebuilds the squash-merge stack.
-func (e *Engineer) resetAndRebuildStack(mrs []*MRInfo, target string) error {
+func (e *Engineer) resetAndRebuildStack(ctx context.Context, mrs []*MRInfo, target string) error {
 	// Reset target to origin
 	if err := e.git.Checkout(target); err != nil {
 		return fmt.Errorf("checkout %s: %w", target, err)
@@ -526,10 +606,26 @@ func (e *Engineer) resetAndRebuildStack(mrs []*MRInfo, target string) error {
 
 	// Rebuild the stack
 	for _, mr := range mrs {
-		msg := e.getMergeMessage(mr)
-		if err := e.git.MergeSquash(mr.Branch, msg); err != nil {
+		if err := e.squashOntoStack(ctx, mr); err != nil {
 			return fmt.Errorf("squash merge %s: %w", mr.ID, err)
 		}
 	}
 	return nil
 }
+
+// squashOntoStack squash-merges mr onto the current stack. Conflicts are
+// auto-resolved when the rig configures it, so rebuilding a stack replays
+// resolutions made while it was first built.
+func (e *Engineer) squashOntoStack(ctx context.Context, mr *MRInfo) error {
+	err := e.git.MergeSquash(mr.Branch, e.getMergeMessage(mr))
+	if err == nil {
+		return nil
+	}
+	if files, _ := e.git.GetConflictingFiles(); len(files) > 0 {
+		_ = e.git.ResetHard("HEAD")
+		if e.autoResolveConflicts(ctx, mr) {
+			return nil
+		}
+	}
+	return err
+}
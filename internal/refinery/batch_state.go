@@ -0,0 +1,171 @@
+package refinery
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// batchStateFileName is the runtime file that mirrors the engineer's in-flight
+// batch so observers (gt mq tui) can show stack, gate and bisect progress.
+const batchStateFileName = "refinery-batch.json"
+
+// BatchPhase describes what the engineer is currently doing with a batch.
+type BatchPhase string
+
+const (
+	// BatchPhaseStacking means MRs are being squash-merged onto the target.
+	BatchPhaseStacking BatchPhase = "stacking"
+
+	// BatchPhaseGating means quality gates are running on the stack tip.
+	BatchPhaseGating BatchPhase = "gating"
+
+	// BatchPhaseRetrying means gates failed once and the full batch is being re-run.
+	BatchPhaseRetrying BatchPhase = "retrying"
+
+	// BatchPhaseBisecting means the engineer is isolating the culprit MR(s).
+	BatchPhaseBisecting BatchPhase = "bisecting"
+
+	// BatchPhasePushing means the verified stack is being pushed to the target.
+	BatchPhasePushing BatchPhase = "pushing"
+
+	// BatchPhaseDone means processing finished; the state describes the outcome.
+	BatchPhaseDone BatchPhase = "done"
+)
+
+// Gate progress statuses recorded in GateProgress.Status.
+const (
+	GateStatusRunning = "running"
+	GateStatusPassed  = "passed"
+	GateStatusFailed  = "failed"
+)
+
+// GateProgress is the latest known state of a single quality gate.
+type GateProgress struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	ElapsedMs int64     `json:"elapsed_ms,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// BisectState tracks the binary search for culprit MRs.
+type BisectState struct {
+	Round     int      `json:"round"`
+	Testing   []string `json:"testing,omitempty"`
+	KnownGood []string `json:"known_good,omitempty"`
+	Culprits  []string `json:"culprits,omitempty"`
+}
+
+// BatchState is a snapshot of the batch currently (or most recently) processed.
+// It is observability only: beads remain the source of truth for MR state, and
+// a missing or stale file never affects merge decisions.
+type BatchState struct {
+	Phase     BatchPhase     `json:"phase"`
+	Target    string         `json:"target"`
+	MRs       []string       `json:"mrs"`
+	Stacked   []string       `json:"stacked,omitempty"`
+	Conflicts []string       `json:"conflicts,omitempty"`
+	Gates     []GateProgress `json:"gates,omitempty"`
+	Bisect    *BisectState   `json:"bisect,omitempty"`
+	Merged    []string       `json:"merged,omitempty"`
+	Culprits  []string       `json:"culprits,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	StartedAt time.Time      `json:"started_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// BatchStatePath returns the path of the batch state file for a rig.
+func BatchStatePath(rigPath string) string {
+	return filepath.Join(rigPath, constants.DirRuntime, batchStateFileName)
+}
+
+// LoadBatchState reads the batch state for a rig.
+// Returns nil with no error if the refinery has never recorded a batch.
+func LoadBatchState(rigPath string) (*BatchState, error) {
+	data, err := os.ReadFile(BatchStatePath(rigPath)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var state BatchState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// beginBatchState starts tracking a new batch and persists the initial snapshot.
+func (e *Engineer) beginBatchState(batch []*MRInfo, target string) {
+	now := time.Now().UTC()
+	e.batchMu.Lock()
+	e.batchState = &BatchState{
+		Phase:     BatchPhaseStacking,
+		Target:    target,
+		MRs:       mrIDs(batch),
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+	e.saveBatchStateLocked()
+	e.batchMu.Unlock()
+}
+
+// updateBatchState applies fn to the in-flight batch state and persists it.
+// No-op when no batch is being tracked (e.g. ProcessMRInfo outside a batch).
+func (e *Engineer) updateBatchState(fn func(s *BatchState)) {
+	e.batchMu.Lock()
+	defer e.batchMu.Unlock()
+	if e.batchState == nil {
+		return
+	}
+	fn(e.batchState)
+	e.batchState.UpdatedAt = time.Now().UTC()
+	e.saveBatchStateLocked()
+}
+
+// finishBatchState records the batch outcome and stops tracking.
+func (e *Engineer) finishBatchState(result *BatchResult) {
+	e.updateBatchState(func(s *BatchState) {
+		s.Phase = BatchPhaseDone
+		s.Merged = mrIDs(result.Merged)
+		s.Culprits = mrIDs(result.Culprits)
+		s.Conflicts = mrIDs(result.Conflicts)
+		if result.Error != nil {
+			s.Error = result.Error.Error()
+		}
+	})
+	e.batchMu.Lock()
+	e.batchState = nil
+	e.batchMu.Unlock()
+}
+
+// setGateProgress records the status of a named gate in the batch state.
+func (e *Engineer) setGateProgress(gp GateProgress) {
+	e.updateBatchState(func(s *BatchState) {
+		for i := range s.Gates {
+			if s.Gates[i].Name == gp.Name {
+				if gp.StartedAt.IsZero() {
+					gp.StartedAt = s.Gates[i].StartedAt
+				}
+				s.Gates[i] = gp
+				return
+			}
+		}
+		s.Gates = append(s.Gates, gp)
+	})
+}
+
+// saveBatchStateLocked writes the batch state to disk. Best-effort: a failed
+// write only degrades observability. Caller must hold e.batchMu.
+func (e *Engineer) saveBatchStateLocked() {
+	if e.rig == nil || e.rig.Path == "" {
+		return
+	}
+	_ = util.EnsureDirAndWriteJSON(BatchStatePath(e.rig.Path), e.batchState)
+}
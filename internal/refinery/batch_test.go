@@ -498,6 +498,62 @@ func TestProcessBatch_GateFailure_BisectsToFindCulprit(t *testing.T) {
 	}
 }
 
+func TestProcessBatch_RecordsBatchState(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	createFeatureBranch(t, workDir, "feature-a", "a.txt", "hello a\n")
+	createFeatureBranch(t, workDir, "feature-b", "FAIL_MARKER", "this causes test failure\n")
+
+	e := newTestEngineer(t, workDir, g)
+	e.config.Gates = map[string]*GateConfig{
+		"check": {Cmd: fmt.Sprintf("test ! -f %s/FAIL_MARKER", workDir)},
+	}
+	e.config.GatesParallel = false
+
+	batch := []*MRInfo{
+		makeMR("mr-a", "feature-a", "main"),
+		makeMR("mr-b", "feature-b", "main"),
+	}
+	e.ProcessBatch(context.Background(), batch, "main", &BatchConfig{MaxBatchSize: 5})
+
+	state, err := LoadBatchState(workDir)
+	if err != nil {
+		t.Fatalf("LoadBatchState: %v", err)
+	}
+	if state == nil {
+		t.Fatal("expected batch state to be recorded")
+	}
+	if state.Phase != BatchPhaseDone {
+		t.Errorf("phase = %q, want %q", state.Phase, BatchPhaseDone)
+	}
+	if strings.Join(state.MRs, ",") != "mr-a,mr-b" {
+		t.Errorf("MRs = %v, want [mr-a mr-b]", state.MRs)
+	}
+	if strings.Join(state.Culprits, ",") != "mr-b" {
+		t.Errorf("Culprits = %v, want [mr-b]", state.Culprits)
+	}
+	if strings.Join(state.Merged, ",") != "mr-a" {
+		t.Errorf("Merged = %v, want [mr-a]", state.Merged)
+	}
+	if state.Bisect == nil || state.Bisect.Round == 0 {
+		t.Errorf("expected bisect rounds to be recorded, got %+v", state.Bisect)
+	}
+	if len(state.Gates) != 1 || state.Gates[0].Status != GateStatusPassed {
+		t.Errorf("expected final gate run to pass, got %+v", state.Gates)
+	}
+}
+
+func TestLoadBatchState_Missing(t *testing.T) {
+	state, err := LoadBatchState(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state != nil {
+		t.Errorf("expected nil state, got %+v", state)
+	}
+}
+
 func TestProcessBatch_RetryOnFlaky(t *testing.T) {
 	workDir, g, cleanup := testGitRepo(t)
 	defer cleanup()
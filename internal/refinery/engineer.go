@@ -18,8 +18,10 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/crew"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/forge"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/rig"
@@ -54,6 +56,11 @@ type GateConfig struct {
 	// Timeout is the maximum time the gate command may run.
 	// Zero means no timeout (inherits context deadline).
 	Timeout time.Duration `json:"timeout"`
+
+	// Results names the per-test result format the gate produces, for flaky
+	// test tracking: "go-test-json" (stdout) or "junit:<glob>". Empty
+	// disables tracking.
+	Results string `json:"results,omitempty"`
 }
 
 // GateResult holds the outcome of a single gate execution.
@@ -62,6 +69,10 @@ type GateResult struct {
 	Success bool
 	Error   string
 	Elapsed time.Duration
+
+	// Quarantined lists failed tests that were ignored because they are
+	// quarantined as flaky.
+	Quarantined []string
 }
 
 // MergeQueueConfig holds configuration for the merge queue processor.
@@ -127,6 +138,22 @@ type MergeQueueConfig struct {
 	// Batch holds configuration for the batch-then-bisect merge queue.
 	// When nil or MaxBatchSize <= 1, batching is disabled and MRs process sequentially.
 	Batch *BatchConfig `json:"batch,omitempty"`
+
+	// Review configures the optional pre-merge review stage.
+	// When nil or disabled, MRs go straight to gates and merge.
+	Review *ReviewConfig `json:"review,omitempty"`
+
+	// Conflicts configures automatic conflict resolution tried before an
+	// MR is sent back for rework. When nil, conflicts always go to rework.
+	Conflicts *ConflictConfig `json:"conflicts,omitempty"`
+
+	// Flakes configures quarantine of flaky tests reported by gates with
+	// Results set. When nil, DefaultFlakeConfig applies.
+	Flakes *FlakeConfig `json:"flakes,omitempty"`
+
+	// Forge lands merges through a forge's pull requests instead of pushing
+	// to the target branch. When nil, the refinery pushes directly.
+	Forge *forge.Config `json:"forge,omitempty"`
 }
 
 // DefaultMergeQueueConfig returns sensible defaults for merge queue configuration.
@@ -165,6 +192,9 @@ type MRInfo struct {
 	ConvoyCreatedAt *time.Time // Convoy creation time
 	CreatedAt       time.Time  // MR creation time
 	BlockedBy       string     // Task ID blocking this MR
+	ReviewStatus    string     // Pre-merge review status (empty when no review stage)
+	StackOn         string     // MR this one is stacked on (gt mq submit --on)
+	StackBase       string     // Base branch tip the stacked branch was built on
 
 	// Pre-verification fields (Phase 3: polecat-owned rebasing)
 	// When set, the refinery can skip gates if VerifiedBase matches target HEAD.
@@ -216,6 +246,24 @@ type Engineer struct {
 	mergeSlotRelease      func(holder string) error
 	mergeSlotMaxRetries   int           // Max retries for slot acquisition (0 = no retry)
 	mergeSlotRetryBackoff time.Duration // Initial backoff between retries
+	reviewDispatch        func(taskID, target, agent, formula string) error
+	polecatAgent          func() string // Agent preset polecats author with
+
+	// batchMu guards batchState, which mirrors the in-flight batch to
+	// .runtime/refinery-batch.json for gt mq tui. Gates may run in parallel.
+	batchMu    sync.Mutex
+	batchState *BatchState
+
+	// flakeMu serializes updates to .runtime/refinery-flakes.json.
+	flakeMu sync.Mutex
+
+	// processMu serializes MR processing. Restacking rewrites and
+	// force-pushes branches, so it only happens under processMu and never
+	// from the read-only queue listings.
+	processMu sync.Mutex
+
+	// forge is the PR client when config.Forge is set (see forgeClient).
+	forge forge.Forge
 }
 
 // NewEngineer creates a new Engineer for the given rig.
@@ -231,7 +279,7 @@ func NewEngineer(r *rig.Rig) *Engineer {
 	}
 	beadsClient := beads.New(r.Path)
 
-	return &Engineer{
+	e := &Engineer{
 		rig:     r,
 		beads:   beadsClient,
 		git:     git.NewGit(gitDir),
@@ -251,6 +299,12 @@ func NewEngineer(r *rig.Rig) *Engineer {
 		mergeSlotMaxRetries:   10,
 		mergeSlotRetryBackoff: 500 * time.Millisecond,
 	}
+	e.reviewDispatch = e.slingReview
+	e.polecatAgent = func() string {
+		name, _ := config.ResolveRoleAgentName("polecat", filepath.Dir(r.Path), r.Path)
+		return name
+	}
+	return e
 }
 
 // SetOutput sets the output writer for user-facing messages.
@@ -298,7 +352,13 @@ func (e *Engineer) LoadConfig() error {
 		StaleClaimTimeout    *string                    `json:"stale_claim_timeout"`
 		Gates                map[string]*gateConfigRaw  `json:"gates"`
 		GatesParallel        *bool                      `json:"gates_parallel"`
+		Review               *ReviewConfig              `json:"review"`
+		Conflicts            *ConflictConfig            `json:"conflicts"`
+		Flakes               *FlakeConfig               `json:"flakes"`
+		Forge                *forge.Config              `json:"forge"`
 	}
+	// Decode flakes over the defaults so omitted fields keep them.
+	mqRaw.Flakes = DefaultFlakeConfig()
 
 	if err := json.Unmarshal(rawConfig.MergeQueue, &mqRaw); err != nil {
 		return fmt.Errorf("parsing merge_queue config: %w", err)
@@ -348,7 +408,10 @@ func (e *Engineer) LoadConfig() error {
 	if mqRaw.Gates != nil {
 		e.config.Gates = make(map[string]*GateConfig, len(mqRaw.Gates))
 		for name, raw := range mqRaw.Gates {
-			gc := &GateConfig{Cmd: raw.Cmd}
+			if raw.Results != "" && raw.Results != GateResultsGoTestJSON && !strings.HasPrefix(raw.Results, GateResultsJUnitPrefix) {
+				return fmt.Errorf("gate %q: unknown results format %q", name, raw.Results)
+			}
+			gc := &GateConfig{Cmd: raw.Cmd, Results: raw.Results}
 			if raw.Timeout != "" {
 				dur, err := time.ParseDuration(raw.Timeout)
 				if err != nil {
@@ -365,6 +428,30 @@ func (e *Engineer) LoadConfig() error {
 	if mqRaw.GatesParallel != nil {
 		e.config.GatesParallel = *mqRaw.GatesParallel
 	}
+	if mqRaw.Review != nil {
+		if err := mqRaw.Review.Validate(); err != nil {
+			return fmt.Errorf("invalid merge_queue.review: %w", err)
+		}
+		e.config.Review = mqRaw.Review
+	}
+	if mqRaw.Conflicts != nil {
+		if err := mqRaw.Conflicts.Validate(); err != nil {
+			return fmt.Errorf("invalid merge_queue.conflicts: %w", err)
+		}
+		e.config.Conflicts = mqRaw.Conflicts
+	}
+	if mqRaw.Flakes != nil {
+		if err := mqRaw.Flakes.Validate(); err != nil {
+			return fmt.Errorf("invalid merge_queue.flakes: %w", err)
+		}
+		e.config.Flakes = mqRaw.Flakes
+	}
+	if mqRaw.Forge != nil {
+		if err := mqRaw.Forge.Validate(); err != nil {
+			return fmt.Errorf("invalid merge_queue.forge: %w", err)
+		}
+		e.config.Forge = mqRaw.Forge
+	}
 
 	return nil
 }
@@ -374,6 +461,7 @@ func (e *Engineer) LoadConfig() error {
 type gateConfigRaw struct {
 	Cmd     string `json:"cmd"`
 	Timeout string `json:"timeout"`
+	Results string `json:"results"`
 }
 
 // Config returns the current merge queue configuration.
@@ -434,28 +522,47 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 			Error:    fmt.Sprintf("conflict check failed: %v", err),
 		}
 	}
+	// Submodule changes are read before any auto-resolution: once the resolved
+	// merge is committed on target, target..branch no longer shows them.
+	subChanges, err := e.git.SubmoduleChanges(target, branch)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not check submodule changes: %v\n", err)
+	}
+
+	// Conflicts get one auto-resolution attempt before rework. A resolved
+	// merge is already committed on target and must pass gates; every failure
+	// after that goes through failResolved to drop the local commit.
+	resolved := false
+	failResolved := func(r ProcessResult) ProcessResult {
+		if resolved {
+			if resetErr := e.git.ResetHard("origin/" + target); resetErr != nil {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to reset %s after failure: %v\n", target, resetErr)
+			}
+		}
+		return r
+	}
 	if len(conflicts) > 0 {
-		return ProcessResult{
-			Success:  false,
-			Conflict: true,
-			Error:    fmt.Sprintf("merge conflicts in: %v", conflicts),
+		mr := &MRInfo{Branch: branch, Target: target, SourceIssue: sourceIssue, ID: branch}
+		if !e.autoResolveConflicts(ctx, mr) {
+			return ProcessResult{
+				Success:  false,
+				Conflict: true,
+				Error:    fmt.Sprintf("merge conflicts in: %v", conflicts),
+			}
 		}
+		resolved = true
 	}
 
 	// Step 3.5: Push submodule commits if the branch changes submodule pointers.
 	// The refinery owns all remote pushes — submodule commits must land before the
 	// parent pointer is merged, otherwise main gets dangling submodule references.
-	subChanges, err := e.git.SubmoduleChanges(target, branch)
-	if err != nil {
-		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not check submodule changes: %v\n", err)
-	}
 	if len(subChanges) > 0 {
 		// Ensure submodules are initialized in the refinery worktree
 		if initErr := git.InitSubmodules(e.git.WorkDir()); initErr != nil {
-			return ProcessResult{
+			return failResolved(ProcessResult{
 				Success: false,
 				Error:   fmt.Sprintf("failed to init submodules in refinery worktree: %v", initErr),
-			}
+			})
 		}
 		for _, sc := range subChanges {
 			if sc.NewSHA == "" {
@@ -463,10 +570,10 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 			}
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Pushing submodule %s (commit %s)...\n", sc.Path, sc.NewSHA[:8])
 			if pushErr := e.git.PushSubmoduleCommit(sc.Path, sc.NewSHA, "origin"); pushErr != nil {
-				return ProcessResult{
+				return failResolved(ProcessResult{
 					Success: false,
 					Error:   fmt.Sprintf("failed to push submodule %s: %v", sc.Path, pushErr),
-				}
+				})
 			}
 		}
 		_, _ = fmt.Fprintf(e.output, "[Engineer] Pushed %d submodule(s)\n", len(subChanges))
@@ -475,69 +582,72 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 	// Step 4: Run quality gates (or legacy tests) if configured.
 	// Phase 3 fast-path: if skipGates is true (pre-verified MR with matching base),
 	// skip all gate execution — the polecat already ran gates after rebasing.
-	shouldSkipGates := len(skipGates) > 0 && skipGates[0]
+	shouldSkipGates := len(skipGates) > 0 && skipGates[0] && !resolved
 	if shouldSkipGates {
 		_, _ = fmt.Fprintln(e.output, "[Engineer] Skipping gates (pre-verified by polecat)")
 	} else if len(e.config.Gates) > 0 {
 		// New gates system: run configured quality gates
 		gateResult := e.runGates(ctx)
 		if !gateResult.Success {
-			return gateResult
+			return failResolved(gateResult)
 		}
 	} else if e.config.RunTests && e.config.TestCommand != "" {
 		// Legacy test command path (backward compatible)
 		_, _ = fmt.Fprintf(e.output, "[Engineer] Running tests: %s\n", e.config.TestCommand)
 		result := e.runTests(ctx)
 		if !result.Success {
-			return ProcessResult{
+			return failResolved(ProcessResult{
 				Success:     false,
 				TestsFailed: true,
 				Error:       result.Error,
-			}
+			})
 		}
 		_, _ = fmt.Fprintln(e.output, "[Engineer] Tests passed")
 	}
 
-	// Step 5: Perform the actual merge using squash merge
+	// Step 5: Perform the actual merge using squash merge (already done
+	// when conflicts were auto-resolved).
 	// Get the original commit message from the polecat branch to preserve the
 	// conventional commit format (feat:/fix:) instead of creating redundant merge commits
-	originalMsg, err := e.git.GetBranchCommitMessage(branch)
-	if err != nil {
-		// Fallback to a descriptive message if we can't get the original
-		originalMsg = fmt.Sprintf("Squash merge %s into %s", branch, target)
-		if sourceIssue != "" {
-			originalMsg = fmt.Sprintf("Squash merge %s into %s (%s)", branch, target, sourceIssue)
-		}
-		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not get original commit message: %v\n", err)
-	}
-	_, _ = fmt.Fprintf(e.output, "[Engineer] Squash merging with message: %s\n", strings.TrimSpace(originalMsg))
-	if err := e.git.MergeSquash(branch, originalMsg); err != nil {
-		// ZFC: Use git's porcelain output to detect conflicts instead of parsing stderr.
-		// GetConflictingFiles() uses `git diff --diff-filter=U` which is proper.
-		conflicts, conflictErr := e.git.GetConflictingFiles()
-		if conflictErr == nil && len(conflicts) > 0 {
+	if !resolved {
+		originalMsg, err := e.git.GetBranchCommitMessage(branch)
+		if err != nil {
+			// Fallback to a descriptive message if we can't get the original
+			originalMsg = fmt.Sprintf("Squash merge %s into %s", branch, target)
+			if sourceIssue != "" {
+				originalMsg = fmt.Sprintf("Squash merge %s into %s (%s)", branch, target, sourceIssue)
+			}
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not get original commit message: %v\n", err)
+		}
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Squash merging with message: %s\n", strings.TrimSpace(originalMsg))
+		if err := e.git.MergeSquash(branch, originalMsg); err != nil {
+			// ZFC: Use git's porcelain output to detect conflicts instead of parsing stderr.
+			// GetConflictingFiles() uses `git diff --diff-filter=U` which is proper.
+			conflicts, conflictErr := e.git.GetConflictingFiles()
+			if conflictErr == nil && len(conflicts) > 0 {
+				_ = e.git.AbortMerge()
+				return ProcessResult{
+					Success:  false,
+					Conflict: true,
+					Error:    "merge conflict during actual merge",
+				}
+			}
+			// Non-conflict failure: still need to abort to clean up dirty merge state
 			_ = e.git.AbortMerge()
 			return ProcessResult{
-				Success:  false,
-				Conflict: true,
-				Error:    "merge conflict during actual merge",
+				Success: false,
+				Error:   fmt.Sprintf("merge failed: %v", err),
 			}
 		}
-		// Non-conflict failure: still need to abort to clean up dirty merge state
-		_ = e.git.AbortMerge()
-		return ProcessResult{
-			Success: false,
-			Error:   fmt.Sprintf("merge failed: %v", err),
-		}
 	}
 
 	// Step 6: Get the merge commit SHA
 	mergeCommit, err := e.git.Rev("HEAD")
 	if err != nil {
-		return ProcessResult{
+		return failResolved(ProcessResult{
 			Success: false,
 			Error:   fmt.Sprintf("failed to get merge commit SHA: %v", err),
-		}
+		})
 	}
 
 	// Step 7: Acquire merge slot before push to serialize writes to the default branch.
@@ -573,6 +683,19 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		}()
 	}
 
+	// Step 8: Land through the forge's PR flow when the upstream requires it
+	if e.config.Forge != nil {
+		sha, err := e.landViaForge(ctx, target, branch)
+		if err != nil {
+			if resetErr := e.git.ResetHard("origin/" + target); resetErr != nil {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to reset %s after forge failure: %v\n", target, resetErr)
+			}
+			return landResult(err)
+		}
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Successfully merged: %s\n", sha[:min(8, len(sha))])
+		return ProcessResult{Success: true, MergeCommit: sha}
+	}
+
 	// Step 8: Push to origin
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Pushing to origin/%s...\n", target)
 	if err := e.git.Push("origin", target, false); err != nil {
@@ -730,20 +853,69 @@ func (e *Engineer) runGate(ctx context.Context, name string, gate *GateConfig) G
 		defer cancel()
 	}
 
-	cmd := exec.CommandContext(gateCtx, "sh", "-c", gate.Cmd) //nolint:gosec // G204: Gate commands are from trusted rig config
-	cmd.Dir = e.workDir
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	// Gates that report per-test results are rerun on the same tree when
+	// tests fail, which is how flaky tests are told apart from broken ones.
+	attempts := 1
+	var tree string
+	if gate.Results != "" {
+		if cfg := e.config.Flakes; cfg != nil {
+			attempts += cfg.Retries
+		} else {
+			attempts += DefaultFlakeConfig().Retries
+		}
+		tree, _ = git.NewGit(e.workDir).Rev("HEAD^{tree}")
+	}
+
+	var err error
+	var stderr bytes.Buffer
+	var quarantined []string
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %s: rerunning on the same tree (attempt %d/%d)\n", name, attempt, attempts)
+		}
+		attemptStart := time.Now()
+		cmd := exec.CommandContext(gateCtx, "sh", "-c", gate.Cmd) //nolint:gosec // G204: Gate commands are from trusted rig config
+		cmd.Dir = e.workDir
+		var stdout bytes.Buffer
+		stderr.Reset()
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err = cmd.Run()
+		if gate.Results == "" {
+			break
+		}
 
-	err := cmd.Run()
+		// Record per-test results. A failure made up only of tests that
+		// were quarantined before this gate started does not block the
+		// merge; package-level failures, timeouts and any exit other than
+		// the test runner's plain failure (1) still do.
+		outcomes := e.gateTestOutcomes(gate, stdout.Bytes(), attemptStart)
+		failed, blocking := e.recordGateTests(name, outcomes, tree, start)
+		if err == nil || gateCtx.Err() != nil {
+			break
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 || hasPackageFailure(outcomes) {
+			break
+		}
+		if len(failed) > 0 && len(blocking) == 0 {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %s: failures only in quarantined tests: %s\n", name, strings.Join(failed, ", "))
+			err = nil
+			quarantined = failed
+			break
+		}
+		if len(blocking) == 0 || attempt >= attempts {
+			break
+		}
+	}
 	elapsed := time.Since(start)
 
 	if err == nil {
 		return GateResult{
-			Name:    name,
-			Success: true,
-			Elapsed: elapsed,
+			Name:        name,
+			Success:     true,
+			Elapsed:     elapsed,
+			Quarantined: quarantined,
 		}
 	}
 
@@ -767,6 +939,23 @@ func (e *Engineer) runGate(ctx context.Context, name string, gate *GateConfig) G
 	}
 }
 
+// runTrackedGate runs a gate and records its progress in the batch state.
+func (e *Engineer) runTrackedGate(ctx context.Context, name string, gate *GateConfig) GateResult {
+	e.setGateProgress(GateProgress{Name: name, Status: GateStatusRunning, StartedAt: time.Now().UTC()})
+	result := e.runGate(ctx, name, gate)
+	status := GateStatusPassed
+	if !result.Success {
+		status = GateStatusFailed
+	}
+	e.setGateProgress(GateProgress{
+		Name:      name,
+		Status:    status,
+		ElapsedMs: result.Elapsed.Milliseconds(),
+		Error:     result.Error,
+	})
+	return result
+}
+
 // runGates executes all configured quality gates and returns a ProcessResult.
 // Gates run in parallel if GatesParallel is true; otherwise sequentially.
 // Any single gate failure means overall failure.
@@ -795,14 +984,14 @@ func (e *Engineer) runGates(ctx context.Context) ProcessResult {
 			go func(idx int, gateName string) {
 				defer wg.Done()
 				_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: starting (%s)\n", gateName, gates[gateName].Cmd)
-				results[idx] = e.runGate(ctx, gateName, gates[gateName])
+				results[idx] = e.runTrackedGate(ctx, gateName, gates[gateName])
 			}(i, name)
 		}
 		wg.Wait()
 	} else {
 		for _, name := range names {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: starting (%s)\n", name, gates[name].Cmd)
-			result := e.runGate(ctx, name, gates[name])
+			result := e.runTrackedGate(ctx, name, gates[name])
 			results = append(results, result)
 			if !result.Success {
 				// Sequential mode: stop on first failure
@@ -868,6 +1057,9 @@ func (e *Engineer) syncCrewWorkspaces() {
 
 // ProcessMRInfo processes a merge request from MRInfo.
 func (e *Engineer) ProcessMRInfo(ctx context.Context, mr *MRInfo) ProcessResult {
+	e.processMu.Lock()
+	defer e.processMu.Unlock()
+
 	// MR fields are directly on the struct
 	_, _ = fmt.Fprintln(e.output, "[Engineer] Processing MR:")
 	_, _ = fmt.Fprintf(e.output, "  Branch: %s\n", mr.Branch)
@@ -894,12 +1086,41 @@ func (e *Engineer) ProcessMRInfo(ctx context.Context, mr *MRInfo) ProcessResult
 		}
 	}
 
+	// Under the review stage an MR merges only once approved; one that was
+	// never routed is routed now.
+	if e.reviewEnabled() && mr.ReviewStatus != beads.ReviewApproved {
+		if mr.ReviewStatus == "" {
+			if _, err := e.routeForReview(mr); err != nil {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not route %s for review: %v\n", mr.ID, err)
+			}
+		}
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("awaiting review (review status %q)", mr.ReviewStatus),
+		}
+	}
+
+	// A stacked MR carries its base's commits; it can't land before the base.
+	// Keep it current with a reworked base meanwhile.
+	if mr.StackOn != "" {
+		if open, _ := e.IsBeadOpen(mr.StackOn); open {
+			e.syncStack(mr)
+			return ProcessResult{
+				Success: false,
+				Error:   fmt.Sprintf("stacked on %s, which has not merged yet", mr.StackOn),
+			}
+		}
+	}
+
 	// Use the shared merge logic
 	return e.doMerge(ctx, mr.Branch, mr.Target, mr.SourceIssue, skipGates)
 }
 
 // HandleMRInfoSuccess handles a successful merge from MRInfo.
 func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
+	e.processMu.Lock()
+	defer e.processMu.Unlock()
+
 	// Release merge slot if this was a conflict resolution
 	// The slot is held while conflict resolution is in progress
 	holder := e.rig.Name + "/refinery"
@@ -964,6 +1185,12 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 		}
 	}
 
+	// 1.6. Move MRs stacked on this one onto the target (needs the branch,
+	// so before it is deleted)
+	if mr.ID != "" {
+		e.restackDependents(mr)
+	}
+
 	// 2. Delete source branch if configured (local and remote)
 	if e.config.DeleteMergedBranches && mr.Branch != "" {
 		if err := e.git.DeleteBranch(mr.Branch, true); err != nil {
@@ -984,6 +1211,7 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 	e.postMergeConvoyCheck(mr)
 
 	// 4. Log success
+	LogMerged(filepath.Dir(e.rig.Path), e.rig.Name, mr.ID, mr.Worker, mr.Branch, mr.Target, result.MergeCommit)
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✓ Merged: %s (commit: %s)\n", mr.ID, result.MergeCommit)
 }
 
@@ -1011,16 +1239,21 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 	} else if result.TestsFailed {
 		failureType = "tests"
 	}
+	// With agent-assisted resolution, conflicts go to a resolver polecat
+	// instead of back to the author.
+	agentResolve := result.Conflict && e.config.Conflicts != nil && e.config.Conflicts.Agent
 	polecatName := strings.TrimPrefix(mr.Worker, "polecats/")
-	nudgeTarget := fmt.Sprintf("%s/%s", e.rig.Name, polecatName)
-	nudgeMsg := fmt.Sprintf("MERGE_FAILED: branch=%s issue=%s type=%s error=%s — fix and resubmit with 'gt done'",
-		mr.Branch, mr.SourceIssue, failureType, result.Error)
-	nudgeCmd := exec.Command("gt", "nudge", nudgeTarget, nudgeMsg)
-	nudgeCmd.Dir = e.workDir
-	if err := nudgeCmd.Run(); err != nil {
-		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to nudge %s about merge failure: %v\n", polecatName, err)
-	} else {
-		_, _ = fmt.Fprintf(e.output, "[Engineer] Nudged %s about merge failure (%s)\n", polecatName, failureType)
+	if !agentResolve {
+		nudgeTarget := fmt.Sprintf("%s/%s", e.rig.Name, polecatName)
+		nudgeMsg := fmt.Sprintf("MERGE_FAILED: branch=%s issue=%s type=%s error=%s — fix and resubmit with 'gt done'",
+			mr.Branch, mr.SourceIssue, failureType, result.Error)
+		nudgeCmd := exec.Command("gt", "nudge", nudgeTarget, nudgeMsg)
+		nudgeCmd.Dir = e.workDir
+		if err := nudgeCmd.Run(); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to nudge %s about merge failure: %v\n", polecatName, err)
+		} else {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Nudged %s about merge failure (%s)\n", polecatName, failureType)
+		}
 	}
 
 	// If this was a conflict, create a conflict-resolution task for dispatch
@@ -1037,6 +1270,13 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 			} else {
 				_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s blocked on conflict task %s (non-blocking delegation)\n", mr.ID, taskID)
 			}
+			if agentResolve {
+				if err := e.dispatchConflictAgent(taskID); err != nil {
+					_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to dispatch conflict resolver for %s: %v\n", taskID, err)
+				} else {
+					_, _ = fmt.Fprintf(e.output, "[Engineer] Dispatched %s to a resolver polecat (%s)\n", taskID, e.config.Conflicts.Formula)
+				}
+			}
 		}
 	}
 
@@ -1232,6 +1472,9 @@ func issueToMRInfo(issue *beads.Issue, fields *beads.MRFields) *MRInfo {
 		PreVerified:     fields.PreVerified,
 		PreVerifiedAt:   preVerifiedAt,
 		PreVerifiedBase: fields.PreVerifiedBase,
+		ReviewStatus:    fields.ReviewStatus,
+		StackOn:         fields.StackOn,
+		StackBase:       fields.StackBase,
 		CreatedAt:       createdAt,
 		UpdatedAt:       updatedAt,
 		Assignee:        issue.Assignee,
@@ -1255,6 +1498,9 @@ func (e *Engineer) firstOpenBlocker(issue *beads.Issue) string {
 // - Not blocked by an open task (checked via firstOpenBlocker)
 // Sorted by priority (highest first).
 //
+// ListReadyMRs is read-only: it never routes reviews or rewrites branches,
+// since gt refinery ready and the witness patrol poll it.
+//
 // Uses bd list instead of bd ready because MRs are ephemeral beads and
 // bd ready filters out ephemeral issues (see gt-t5t6y). This matches the
 // pattern used by ListBlockedMRs and ListAllOpenMRs.
@@ -1279,9 +1525,14 @@ func (e *Engineer) ListReadyMRs() ([]*MRInfo, error) {
 			continue
 		}
 
-		// Skip blocked MRs (replaces bd ready's blocker filtering)
+		// Skip blocked MRs (replaces bd ready's blocker filtering). An MR
+		// blocked only by the MR it is stacked on stays eligible; it must
+		// land with or after its base (see orderStacks).
+		stackOn := ""
 		if blockedBy := e.firstOpenBlocker(issue); blockedBy != "" {
-			continue
+			if stackOn = e.stackBaseOf(issue, beads.ParseMRFields(issue)); stackOn == "" {
+				continue
+			}
 		}
 
 		// Belt-and-suspenders: skip MRs labeled gt:owned-direct.
@@ -1297,6 +1548,12 @@ func (e *Engineer) ListReadyMRs() ([]*MRInfo, error) {
 			continue // Skip issues without MR fields
 		}
 
+		// Review stage: MRs are held until approved. Unreviewed MRs are
+		// routed by the processing path, not here.
+		if e.reviewEnabled() && !reviewApproved(fields) {
+			continue
+		}
+
 		// Skip if already assigned, unless claim is stale (allows re-claim after crash).
 		// NOTE: Only one refinery runs per rig (enforced by ErrAlreadyRunning in
 		// manager.go), so concurrent re-claim race conditions are not a concern.
@@ -1313,10 +1570,16 @@ func (e *Engineer) ListReadyMRs() ([]*MRInfo, error) {
 				issue.ID, issue.Assignee, issue.UpdatedAt)
 		}
 
-		mrs = append(mrs, issueToMRInfo(issue, fields))
+		mr := issueToMRInfo(issue, fields)
+		if stackOn != "" {
+			mr.BlockedBy = stackOn
+		}
+		mrs = append(mrs, mr)
 	}
 
-	return mrs, nil
+	// Stacked MRs follow their base, and are dropped when the base isn't
+	// ready itself.
+	return withoutOrphans(orderStacks(mrs)), nil
 }
 
 // ListBlockedMRs returns MRs that are blocked by open tasks.
@@ -1685,8 +1948,11 @@ func (e *Engineer) checkAndCloseCompletedConvoys(townRoot, townBeads string) []c
 	return closed
 }
 
-// notifyConvoyCompletion sends notifications to convoy owner and notify addresses.
+// notifyConvoyCompletion logs the landing to the feed and sends notifications
+// to convoy owner and notify addresses.
 func (e *Engineer) notifyConvoyCompletion(townRoot, convoyID, title, description string) {
+	_ = events.LogFeed(events.TypeConvoyLanded, e.rig.Name+"/refinery", events.ConvoyLandedPayload(convoyID, title))
+
 	// ZFC: Use typed accessor instead of parsing description text
 	fields := beads.ParseConvoyFields(&beads.Issue{Description: description})
 	for _, addr := range fields.NotificationAddresses() {
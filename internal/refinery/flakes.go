@@ -0,0 +1,425 @@
+package refinery
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// flakeHistoryFileName is the runtime file holding per-test gate history.
+const flakeHistoryFileName = "refinery-flakes.json"
+
+// flakeHistoryLimit is how many runs are kept per test.
+const flakeHistoryLimit = 30
+
+// Gate result formats for GateConfig.Results.
+const (
+	// GateResultsGoTestJSON parses the gate's stdout as `go test -json`.
+	GateResultsGoTestJSON = "go-test-json"
+
+	// GateResultsJUnitPrefix reads JUnit XML reports matching the glob after
+	// the prefix (relative to the merge worktree), e.g. "junit:reports/*.xml".
+	GateResultsJUnitPrefix = "junit:"
+)
+
+// FlakeConfig controls flaky test quarantine for gates that report
+// per-test results.
+type FlakeConfig struct {
+	// Quarantine stops known-flaky tests from blocking merges. Default: true.
+	Quarantine bool `json:"quarantine"`
+
+	// Threshold is the flakiness score at or above which a test is
+	// quarantined. Default: 0.2.
+	Threshold float64 `json:"threshold"`
+
+	// MinRuns is how many recorded runs a test needs before it can be
+	// quarantined. Default: 5.
+	MinRuns int `json:"min_runs"`
+
+	// Retries is how many times a gate that fails on tests is rerun on the
+	// same tree. A test that fails and then passes on the same tree is what
+	// marks it flaky; a gate whose rerun passes passes. Default: 1.
+	Retries int `json:"retries"`
+}
+
+// DefaultFlakeConfig returns the flake quarantine defaults.
+func DefaultFlakeConfig() *FlakeConfig {
+	return &FlakeConfig{Quarantine: true, Threshold: 0.2, MinRuns: 5, Retries: 1}
+}
+
+// Validate checks the flake configuration.
+func (c *FlakeConfig) Validate() error {
+	if c.Threshold <= 0 || c.Threshold > 1 {
+		return fmt.Errorf("threshold must be in (0, 1], got %v", c.Threshold)
+	}
+	if c.MinRuns < 2 {
+		return fmt.Errorf("min_runs must be at least 2, got %d", c.MinRuns)
+	}
+	if c.Retries < 0 || c.Retries > 5 {
+		return fmt.Errorf("retries must be between 0 and 5, got %d", c.Retries)
+	}
+	return nil
+}
+
+// TestOutcome is one test's result from a gate run.
+type TestOutcome struct {
+	Name   string
+	Failed bool
+
+	// Package marks a package-level failure that no failed test accounts
+	// for: a build error, a failing TestMain, a panic outside a test. It
+	// always blocks and is never recorded or quarantined.
+	Package bool
+}
+
+// TestRun is a recorded test result.
+type TestRun struct {
+	At     time.Time `json:"at"`
+	Passed bool      `json:"passed"`
+
+	// Tree is the git tree the gate ran on. Flakiness is only judged
+	// between runs on the same tree.
+	Tree string `json:"tree,omitempty"`
+}
+
+// TestHistory is the recorded history of a single test.
+type TestHistory struct {
+	Gate             string    `json:"gate"`
+	Runs             []TestRun `json:"runs"`
+	Quarantined      bool      `json:"quarantined,omitempty"`
+	QuarantinedAt    time.Time `json:"quarantined_at,omitempty"`
+	QuarantineReason string    `json:"quarantine_reason,omitempty"`
+}
+
+// Score is the test's flakiness: the fraction of trees it ran on where it
+// both passed and failed. Only a rerun of the same tree tells flaky from
+// broken — a test that fails on one MR and passes on the next was broken
+// and fixed, so runs on different trees never count. A test that always
+// fails scores 0.
+func (t *TestHistory) Score() float64 {
+	type outcomes struct{ passed, failed bool }
+	trees := map[string]*outcomes{}
+	for _, r := range t.Runs {
+		if r.Tree == "" {
+			continue
+		}
+		o := trees[r.Tree]
+		if o == nil {
+			o = &outcomes{}
+			trees[r.Tree] = o
+		}
+		if r.Passed {
+			o.passed = true
+		} else {
+			o.failed = true
+		}
+	}
+	if len(trees) == 0 {
+		return 0
+	}
+	flaky := 0
+	for _, o := range trees {
+		if o.passed && o.failed {
+			flaky++
+		}
+	}
+	return float64(flaky) / float64(len(trees))
+}
+
+// Failures returns how many recorded runs failed.
+func (t *TestHistory) Failures() int {
+	n := 0
+	for _, r := range t.Runs {
+		if !r.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// FlakeHistory is the per-rig record of gate test results.
+type FlakeHistory struct {
+	Tests map[string]*TestHistory `json:"tests"`
+}
+
+// FlakeHistoryPath returns the path of the flake history file for a rig.
+func FlakeHistoryPath(rigPath string) string {
+	return filepath.Join(rigPath, constants.DirRuntime, flakeHistoryFileName)
+}
+
+// LoadFlakeHistory reads a rig's flake history. A missing file yields an
+// empty history.
+func LoadFlakeHistory(rigPath string) (*FlakeHistory, error) {
+	h := &FlakeHistory{Tests: map[string]*TestHistory{}}
+	data, err := os.ReadFile(FlakeHistoryPath(rigPath)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", flakeHistoryFileName, err)
+	}
+	if h.Tests == nil {
+		h.Tests = map[string]*TestHistory{}
+	}
+	return h, nil
+}
+
+// Save writes the flake history for a rig.
+func (h *FlakeHistory) Save(rigPath string) error {
+	return util.EnsureDirAndWriteJSON(FlakeHistoryPath(rigPath), h)
+}
+
+// Record appends a gate run's outcomes on tree, keeping the most recent
+// runs. Package-level failures are not recorded.
+func (h *FlakeHistory) Record(gate string, outcomes []TestOutcome, tree string, at time.Time) {
+	for _, o := range outcomes {
+		if o.Package {
+			continue
+		}
+		t := h.Tests[o.Name]
+		if t == nil {
+			t = &TestHistory{}
+			h.Tests[o.Name] = t
+		}
+		t.Gate = gate
+		t.Runs = append(t.Runs, TestRun{At: at, Passed: !o.Failed, Tree: tree})
+		if len(t.Runs) > flakeHistoryLimit {
+			t.Runs = t.Runs[len(t.Runs)-flakeHistoryLimit:]
+		}
+	}
+}
+
+// UpdateQuarantine quarantines tests whose score reaches the threshold and
+// returns the names newly quarantined.
+func (h *FlakeHistory) UpdateQuarantine(cfg *FlakeConfig, now time.Time) []string {
+	var added []string
+	for name, t := range h.Tests {
+		if t.Quarantined || len(t.Runs) < cfg.MinRuns {
+			continue
+		}
+		if score := t.Score(); score >= cfg.Threshold {
+			t.Quarantined = true
+			t.QuarantinedAt = now
+			t.QuarantineReason = fmt.Sprintf("flakiness %.2f over %d runs", score, len(t.Runs))
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	return added
+}
+
+// Unquarantine lets a test block merges again. Its history is cleared so
+// the test has to prove itself flaky anew. Returns false if the test is
+// unknown.
+func (h *FlakeHistory) Unquarantine(name string) bool {
+	t := h.Tests[name]
+	if t == nil {
+		return false
+	}
+	t.Quarantined = false
+	t.QuarantinedAt = time.Time{}
+	t.QuarantineReason = ""
+	t.Runs = nil
+	return true
+}
+
+// ParseGoTestJSON extracts per-test results from `go test -json` output.
+// A package that failed without a failed test (build errors, TestMain,
+// panics outside a test) is reported as a Package outcome. Lines that are
+// not test events are ignored.
+func ParseGoTestJSON(r io.Reader) []TestOutcome {
+	var outcomes []TestOutcome
+	var failedPkgs []string
+	buildFailed := map[string]bool{}
+	testFailed := map[string]bool{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var ev struct {
+			Action      string
+			Package     string
+			ImportPath  string
+			Test        string
+			FailedBuild string
+		}
+		if json.Unmarshal(sc.Bytes(), &ev) != nil {
+			continue
+		}
+		switch {
+		case ev.Action == "build-fail":
+			failedPkgs = append(failedPkgs, ev.ImportPath)
+			buildFailed[ev.ImportPath] = true
+		case ev.Test == "" && ev.Action == "fail":
+			failedPkgs = append(failedPkgs, ev.Package)
+			if ev.FailedBuild != "" {
+				buildFailed[ev.Package] = true
+			}
+		case ev.Test != "" && (ev.Action == "pass" || ev.Action == "fail"):
+			if ev.Action == "fail" {
+				testFailed[ev.Package] = true
+			}
+			outcomes = append(outcomes, TestOutcome{Name: ev.Package + "." + ev.Test, Failed: ev.Action == "fail"})
+		}
+	}
+	seen := map[string]bool{}
+	for _, pkg := range failedPkgs {
+		if seen[pkg] || (testFailed[pkg] && !buildFailed[pkg]) {
+			continue
+		}
+		seen[pkg] = true
+		outcomes = append(outcomes, TestOutcome{Name: pkg, Failed: true, Package: true})
+	}
+	return outcomes
+}
+
+// hasPackageFailure reports whether outcomes include a package-level
+// failure.
+func hasPackageFailure(outcomes []TestOutcome) bool {
+	for _, o := range outcomes {
+		if o.Package {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseJUnit extracts per-test results from a JUnit XML report, with
+// either a <testsuites> or a <testsuite> root. Skipped tests are omitted.
+func ParseJUnit(data []byte) ([]TestOutcome, error) {
+	var outcomes []TestOutcome
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return outcomes, nil
+		}
+		if err != nil {
+			return outcomes, err
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "testcase" {
+			continue
+		}
+		var tc struct {
+			Name      string    `xml:"name,attr"`
+			Classname string    `xml:"classname,attr"`
+			Failure   *struct{} `xml:"failure"`
+			Error     *struct{} `xml:"error"`
+			Skipped   *struct{} `xml:"skipped"`
+		}
+		if err := dec.DecodeElement(&tc, &se); err != nil {
+			return outcomes, err
+		}
+		if tc.Skipped != nil {
+			continue
+		}
+		name := tc.Name
+		if tc.Classname != "" {
+			name = tc.Classname + "." + tc.Name
+		}
+		outcomes = append(outcomes, TestOutcome{Name: name, Failed: tc.Failure != nil || tc.Error != nil})
+	}
+}
+
+// gateTestOutcomes collects per-test results for a gate that reports them.
+// JUnit reports older than the gate run are ignored.
+func (e *Engineer) gateTestOutcomes(gate *GateConfig, stdout []byte, started time.Time) []TestOutcome {
+	switch {
+	case gate.Results == GateResultsGoTestJSON:
+		return ParseGoTestJSON(bytes.NewReader(stdout))
+	case strings.HasPrefix(gate.Results, GateResultsJUnitPrefix):
+		pattern := filepath.Join(e.workDir, strings.TrimPrefix(gate.Results, GateResultsJUnitPrefix))
+		files, _ := filepath.Glob(pattern)
+		var outcomes []TestOutcome
+		for _, f := range files {
+			if info, err := os.Stat(f); err != nil || info.ModTime().Before(started) {
+				continue
+			}
+			data, err := os.ReadFile(f) //nolint:gosec // G304: report path comes from trusted rig config
+			if err != nil {
+				continue
+			}
+			parsed, err := ParseJUnit(data)
+			if err != nil {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: parsing JUnit report %s: %v\n", f, err)
+			}
+			outcomes = append(outcomes, parsed...)
+		}
+		return outcomes
+	}
+	return nil
+}
+
+// recordGateTests records a gate run's test outcomes on tree in the rig's
+// flake history and returns the failed tests and which of them block: all
+// package-level failures, and failed tests that were not already
+// quarantined before the gate started. A test quarantined by this very run
+// still blocks it.
+func (e *Engineer) recordGateTests(gate string, outcomes []TestOutcome, tree string, gateStart time.Time) (failed, blocking []string) {
+	cfg := e.config.Flakes
+	if cfg == nil {
+		cfg = DefaultFlakeConfig()
+	}
+
+	e.flakeMu.Lock()
+	defer e.flakeMu.Unlock()
+
+	// A history that can't be loaded is moved aside rather than
+	// overwritten, so the quarantine record can be recovered by hand. If it
+	// can't be moved either, this run is evaluated against a fresh history
+	// but not saved.
+	save := true
+	history, err := LoadFlakeHistory(e.rig.Path)
+	if err != nil {
+		history = &FlakeHistory{Tests: map[string]*TestHistory{}}
+		path := FlakeHistoryPath(e.rig.Path)
+		aside := path + ".corrupt-" + time.Now().UTC().Format("20060102T150405Z")
+		if mvErr := os.Rename(path, aside); mvErr != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: flake history unreadable (%v) and could not be moved aside (%v); not recording this run\n", err, mvErr)
+			save = false
+		} else {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: flake history unreadable (%v); moved to %s, starting fresh\n", err, aside)
+		}
+	}
+	now := time.Now().UTC()
+	history.Record(gate, outcomes, tree, now)
+	if cfg.Quarantine {
+		for _, name := range history.UpdateQuarantine(cfg, now) {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Quarantined flaky test %s (%s)\n", name, history.Tests[name].QuarantineReason)
+		}
+	}
+	if save {
+		if err := history.Save(e.rig.Path); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: saving flake history: %v\n", err)
+		}
+	}
+
+	for _, o := range outcomes {
+		if !o.Failed {
+			continue
+		}
+		failed = append(failed, o.Name)
+		if o.Package || !cfg.Quarantine {
+			blocking = append(blocking, o.Name)
+			continue
+		}
+		if t := history.Tests[o.Name]; !t.Quarantined || !t.QuarantinedAt.Before(gateStart) {
+			blocking = append(blocking, o.Name)
+		}
+	}
+	return failed, blocking
+}
@@ -0,0 +1,258 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestParseGoTestJSON(t *testing.T) {
+	out := `{"Action":"run","Package":"ex/a","Test":"TestOK"}
+{"Action":"pass","Package":"ex/a","Test":"TestOK","Elapsed":0.01}
+{"Action":"fail","Package":"ex/a","Test":"TestBad","Elapsed":0.02}
+{"Action":"skip","Package":"ex/a","Test":"TestSkip"}
+not json
+{"Action":"fail","Package":"ex/a","Elapsed":0.05}
+`
+	got := ParseGoTestJSON(strings.NewReader(out))
+	want := []TestOutcome{{Name: "ex/a.TestOK"}, {Name: "ex/a.TestBad", Failed: true}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("ParseGoTestJSON = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseGoTestJSON_PackageFailures(t *testing.T) {
+	out := `{"Action":"fail","Package":"ex/a","Test":"TestBad"}
+{"Action":"fail","Package":"ex/a"}
+{"Action":"build-fail","ImportPath":"ex/b [ex/b.test]"}
+{"Action":"fail","Package":"ex/b","FailedBuild":"ex/b [ex/b.test]"}
+{"Action":"output","Package":"ex/c","Output":"panic in TestMain"}
+{"Action":"fail","Package":"ex/c"}
+`
+	got := ParseGoTestJSON(strings.NewReader(out))
+	var pkgs []string
+	for _, o := range got {
+		if o.Package {
+			pkgs = append(pkgs, o.Name)
+		}
+	}
+	want := []string{"ex/b [ex/b.test]", "ex/b", "ex/c"}
+	if strings.Join(pkgs, ",") != strings.Join(want, ",") {
+		t.Errorf("package failures = %v, want %v (ex/a is explained by TestBad)", pkgs, want)
+	}
+}
+
+func TestParseJUnit(t *testing.T) {
+	report := `<?xml version="1.0"?>
+<testsuites>
+  <testsuite name="suite">
+    <testcase classname="pkg.Foo" name="ok"/>
+    <testcase classname="pkg.Foo" name="bad"><failure message="boom"/></testcase>
+    <testcase classname="pkg.Foo" name="err"><error/></testcase>
+    <testcase classname="pkg.Foo" name="skip"><skipped/></testcase>
+  </testsuite>
+</testsuites>`
+	got, err := ParseJUnit([]byte(report))
+	if err != nil {
+		t.Fatalf("ParseJUnit: %v", err)
+	}
+	want := []TestOutcome{{Name: "pkg.Foo.ok"}, {Name: "pkg.Foo.bad", Failed: true}, {Name: "pkg.Foo.err", Failed: true}}
+	if len(got) != len(want) {
+		t.Fatalf("ParseJUnit = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("outcome %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFlakeHistory_Quarantine(t *testing.T) {
+	h := &FlakeHistory{Tests: map[string]*TestHistory{}}
+	cfg := DefaultFlakeConfig()
+	now := time.Now()
+	runs := []struct {
+		tree                      string
+		flaky, regressed, unknown bool
+	}{
+		// "flaky" fails and passes on the rerun of tree t2; "regressed"
+		// is broken by t4's MR and fixed by t5's.
+		{"t1", false, false, false},
+		{"t2", true, false, true},
+		{"t2", false, false, false},
+		{"t3", false, false, true},
+		{"t4", false, true, false},
+		{"t4", false, true, false},
+		{"t5", false, false, true},
+	}
+	for i, r := range runs {
+		h.Record("test", []TestOutcome{
+			{Name: "flaky", Failed: r.flaky},
+			{Name: "regressed", Failed: r.regressed},
+			{Name: "broken", Failed: true},
+			{Name: "stable"},
+			{Name: "ex/pkg", Failed: true, Package: true},
+		}, r.tree, now.Add(time.Duration(i)*time.Minute))
+		// Runs without a tree (not a git worktree) never count.
+		h.Record("test", []TestOutcome{{Name: "unknown", Failed: r.unknown}}, "", now)
+	}
+
+	added := h.UpdateQuarantine(cfg, now)
+	if len(added) != 1 || added[0] != "flaky" {
+		t.Fatalf("quarantined %v, want [flaky]", added)
+	}
+	for _, name := range []string{"broken", "regressed", "unknown"} {
+		if s := h.Tests[name].Score(); s != 0 {
+			t.Errorf("%s scored %v, want 0", name, s)
+		}
+	}
+	if h.Tests["ex/pkg"] != nil {
+		t.Error("package failure recorded as a test")
+	}
+	if !h.Tests["flaky"].Quarantined || h.Tests["flaky"].QuarantineReason == "" {
+		t.Errorf("flaky not quarantined: %+v", h.Tests["flaky"])
+	}
+
+	if !h.Unquarantine("flaky") || h.Tests["flaky"].Quarantined || len(h.Tests["flaky"].Runs) != 0 {
+		t.Errorf("Unquarantine did not reset: %+v", h.Tests["flaky"])
+	}
+	if h.Unquarantine("missing") {
+		t.Error("Unquarantine of unknown test reported success")
+	}
+}
+
+func TestRunGate_QuarantinedFailuresDoNotBlock(t *testing.T) {
+	rigPath := t.TempDir()
+	var out bytes.Buffer
+	e := NewEngineer(&rig.Rig{Name: "gastown", Path: rigPath})
+	e.workDir = rigPath
+	e.SetOutput(&out)
+
+	history := &FlakeHistory{Tests: map[string]*TestHistory{
+		"ex/a.TestFlaky": {Quarantined: true},
+	}}
+	if err := history.Save(rigPath); err != nil {
+		t.Fatal(err)
+	}
+
+	flakyFail := `echo '{"Action":"fail","Package":"ex/a","Test":"TestFlaky"}'; exit 1`
+	res := e.runGate(context.Background(), "test", &GateConfig{Cmd: flakyFail, Results: GateResultsGoTestJSON})
+	if !res.Success || len(res.Quarantined) != 1 {
+		t.Errorf("quarantined failure blocked gate: %+v", res)
+	}
+
+	// A real failure is rerun once on the same tree before it blocks.
+	realFail := `echo '{"Action":"fail","Package":"ex/a","Test":"TestFlaky"}'; echo '{"Action":"fail","Package":"ex/a","Test":"TestReal"}'; exit 1`
+	if res := e.runGate(context.Background(), "test", &GateConfig{Cmd: realFail, Results: GateResultsGoTestJSON}); res.Success {
+		t.Error("non-quarantined failure passed the gate")
+	}
+
+	if res := e.runGate(context.Background(), "test", &GateConfig{Cmd: "exit 1", Results: GateResultsGoTestJSON}); res.Success {
+		t.Error("failure without parsed tests passed the gate")
+	}
+
+	// A broken build in another package is not forgiven by a quarantined
+	// test failure, nor is an exit the test runner doesn't use for failures.
+	buildFail := `echo '{"Action":"fail","Package":"ex/a","Test":"TestFlaky"}'; echo '{"Action":"fail","Package":"ex/b","FailedBuild":"ex/b"}'; exit 1`
+	if res := e.runGate(context.Background(), "test", &GateConfig{Cmd: buildFail, Results: GateResultsGoTestJSON}); res.Success {
+		t.Error("package build failure forgiven")
+	}
+	oddExit := `echo '{"Action":"fail","Package":"ex/a","Test":"TestFlaky"}'; exit 2`
+	if res := e.runGate(context.Background(), "test", &GateConfig{Cmd: oddExit, Results: GateResultsGoTestJSON}); res.Success {
+		t.Error("exit 2 forgiven")
+	}
+
+	got, err := LoadFlakeHistory(rigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs := len(got.Tests["ex/a.TestFlaky"].Runs); runs != 5 {
+		t.Errorf("recorded %d runs for TestFlaky, want 5 (one rerun of the real failure)", runs)
+	}
+	if got.Tests["ex/a.TestReal"] == nil {
+		t.Error("TestReal not recorded")
+	}
+}
+
+func TestRunGate_RerunPassesFlakyTest(t *testing.T) {
+	rigPath := t.TempDir()
+	e := NewEngineer(&rig.Rig{Name: "gastown", Path: rigPath})
+	e.workDir = rigPath
+	e.SetOutput(&bytes.Buffer{})
+
+	// Fails the first time, passes on the rerun.
+	marker := filepath.Join(t.TempDir(), "ran")
+	cmd := `if [ -f ` + marker + ` ]; then echo '{"Action":"pass","Package":"ex/a","Test":"TestFlaky"}'; else touch ` + marker + `; echo '{"Action":"fail","Package":"ex/a","Test":"TestFlaky"}'; exit 1; fi`
+	if res := e.runGate(context.Background(), "test", &GateConfig{Cmd: cmd, Results: GateResultsGoTestJSON}); !res.Success {
+		t.Errorf("rerun passed but gate failed: %+v", res)
+	}
+	got, err := LoadFlakeHistory(rigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs := got.Tests["ex/a.TestFlaky"].Runs; len(runs) != 2 || runs[0].Passed || !runs[1].Passed {
+		t.Errorf("runs = %+v, want a failure then a pass", runs)
+	}
+}
+
+func TestRecordGateTests_SameRunQuarantineStillBlocks(t *testing.T) {
+	rigPath := t.TempDir()
+	e := NewEngineer(&rig.Rig{Name: "gastown", Path: rigPath})
+	e.SetOutput(&bytes.Buffer{})
+	e.config.Flakes = &FlakeConfig{Quarantine: true, Threshold: 0.2, MinRuns: 2, Retries: 1}
+
+	// One earlier pass on the same tree: this failure makes the test flaky
+	// and quarantines it, but must not forgive the run that did so.
+	history := &FlakeHistory{Tests: map[string]*TestHistory{
+		"ex/a.TestFlaky": {Runs: []TestRun{{At: time.Now(), Passed: true, Tree: "t1"}}},
+	}}
+	if err := history.Save(rigPath); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	failed, blocking := e.recordGateTests("test", []TestOutcome{{Name: "ex/a.TestFlaky", Failed: true}}, "t1", start)
+	if len(failed) != 1 || len(blocking) != 1 {
+		t.Errorf("failed = %v, blocking = %v; want the newly quarantined test to block", failed, blocking)
+	}
+	got, _ := LoadFlakeHistory(rigPath)
+	if !got.Tests["ex/a.TestFlaky"].Quarantined {
+		t.Error("test not quarantined")
+	}
+}
+
+func TestRecordGateTests_CorruptHistoryMovedAside(t *testing.T) {
+	rigPath := t.TempDir()
+	e := NewEngineer(&rig.Rig{Name: "gastown", Path: rigPath})
+	e.SetOutput(&bytes.Buffer{})
+
+	path := FlakeHistoryPath(rigPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e.recordGateTests("test", []TestOutcome{{Name: "ex/a.TestOK"}}, "t1", time.Now())
+
+	aside, _ := filepath.Glob(path + ".corrupt-*")
+	if len(aside) != 1 {
+		t.Fatalf("moved-aside files = %v, want one", aside)
+	}
+	if data, _ := os.ReadFile(aside[0]); string(data) != "{not json" {
+		t.Errorf("moved-aside content = %q, want the original", data)
+	}
+	got, err := LoadFlakeHistory(rigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Tests["ex/a.TestOK"] == nil {
+		t.Error("run not recorded in the fresh history")
+	}
+}
@@ -0,0 +1,107 @@
+package refinery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/forge"
+)
+
+// forgeHeadPrefix prefixes the head branches the refinery opens PRs from.
+const forgeHeadPrefix = "gt/merge/"
+
+// forgeClient returns the client for the rig's forge, creating it on first use.
+func (e *Engineer) forgeClient() (forge.Forge, error) {
+	if e.forge == nil {
+		f, err := forge.New(e.config.Forge)
+		if err != nil {
+			return nil, err
+		}
+		e.forge = f
+	}
+	return e.forge, nil
+}
+
+// landViaForge lands the checked-out target tip through a pull request:
+// the tip is pushed to a head branch, a PR is opened (or updated) against
+// target, and once the forge's checks pass the PR is merged through the API.
+// The local target is then reset to the forge's result, whose SHA is
+// returned. On failure the local target is left for the caller to reset.
+func (e *Engineer) landViaForge(ctx context.Context, target, name string) (string, error) {
+	cfg := e.config.Forge
+	f, err := e.forgeClient()
+	if err != nil {
+		return "", err
+	}
+
+	head := forgeHeadPrefix + name
+	if err := e.git.Push("origin", "HEAD:refs/heads/"+head, true); err != nil {
+		return "", fmt.Errorf("pushing %s: %w", head, err)
+	}
+
+	title, body := e.forgePRText(target)
+	pr, err := f.EnsurePR(ctx, forge.PRSpec{Head: head, Base: target, Title: title, Body: body})
+	if err != nil {
+		return "", fmt.Errorf("opening PR for %s: %w", head, err)
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] PR %s open (%s → %s), waiting for checks...\n", pr.URL, head, target)
+
+	if err := forge.WaitForChecks(ctx, f, pr, cfg); err != nil {
+		return "", err
+	}
+	sha, err := f.Merge(ctx, pr, cfg.MergeMethod)
+	if err != nil {
+		return "", fmt.Errorf("merging %s: %w", pr.URL, err)
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Merged %s via %s\n", pr.URL, cfg.MergeMethod)
+
+	// The forge wrote the target; follow it.
+	if err := e.git.FetchBranch("origin", target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: fetching origin/%s after merge: %v\n", target, err)
+	} else if err := e.git.ResetHard("origin/" + target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: syncing %s after merge: %v\n", target, err)
+	}
+	if err := e.git.DeleteRemoteBranch("origin", head); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: deleting %s: %v\n", head, err)
+	}
+	return sha, nil
+}
+
+// forgePRText builds the PR title and body from the commits being landed.
+func (e *Engineer) forgePRText(target string) (string, string) {
+	log, err := e.git.CommitSubjects("origin/"+target, "HEAD")
+	subjects := strings.Split(strings.TrimSpace(log), "\n")
+	if err != nil || log == "" {
+		subjects = nil
+	}
+
+	title := fmt.Sprintf("Merge queue: land on %s", target)
+	if len(subjects) == 1 {
+		title = subjects[0]
+	} else if len(subjects) > 1 {
+		title = fmt.Sprintf("Merge queue: %d changes", len(subjects))
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Landed by the %s refinery after its quality gates passed.\n", e.rig.Name)
+	if len(subjects) > 1 {
+		body.WriteString("\n")
+		for _, s := range subjects {
+			fmt.Fprintf(&body, "- %s\n", s)
+		}
+	}
+	return title, body.String()
+}
+
+// landResult turns a failed forge landing into a ProcessResult. Failed
+// forge checks count as test failures so the MR goes back to its author.
+func landResult(err error) ProcessResult {
+	var checksFailed *forge.ErrChecksFailed
+	return ProcessResult{
+		Success:     false,
+		TestsFailed: errors.As(err, &checksFailed),
+		Error:       fmt.Sprintf("landing via forge: %v", err),
+	}
+}
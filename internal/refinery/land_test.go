@@ -0,0 +1,75 @@
+package refinery
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/forge"
+	"github.com/steveyegge/gastown/internal/forge/fakeforge"
+	gitpkg "github.com/steveyegge/gastown/internal/git"
+)
+
+// forgeEngineer returns a test engineer that lands through a fake forge
+// serving the test repo's origin.
+func forgeEngineer(t *testing.T, workDir string, g *gitpkg.Git) (*Engineer, *fakeforge.Server) {
+	t.Helper()
+	srv := fakeforge.New(filepath.Join(filepath.Dir(workDir), "origin.git"))
+	t.Cleanup(srv.Close)
+
+	e := newTestEngineer(t, workDir, g)
+	e.config.Forge = &forge.Config{Type: forge.TypeGitHub, URL: srv.URL, Repo: "acme/widgets", PollInterval: "10ms", CheckTimeout: "5s"}
+	if err := e.config.Forge.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return e, srv
+}
+
+func TestDoMerge_LandsViaForge(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+	createFeatureBranch(t, workDir, "feature-a", "a.txt", "hello a\n")
+
+	e, srv := forgeEngineer(t, workDir, g)
+
+	result := e.doMerge(context.Background(), "feature-a", "main", "")
+	if !result.Success {
+		t.Fatalf("doMerge failed: %s", result.Error)
+	}
+
+	prs := srv.PullRequests()
+	if len(prs) != 1 || !prs[0].Merged || prs[0].Method != forge.MethodRebase {
+		t.Fatalf("fake forge PRs = %+v, want one rebase-merged PR", prs)
+	}
+	if prs[0].Head != forgeHeadPrefix+"feature-a" || prs[0].Title != "feat: add a.txt" {
+		t.Errorf("PR head/title = %q/%q", prs[0].Head, prs[0].Title)
+	}
+	if tip := run(t, workDir, "git", "ls-remote", "origin", "refs/heads/main"); !strings.HasPrefix(tip, result.MergeCommit) {
+		t.Errorf("origin main = %q, want %s", tip, result.MergeCommit)
+	}
+	if heads := run(t, workDir, "git", "ls-remote", "origin", "refs/heads/"+forgeHeadPrefix+"*"); heads != "" {
+		t.Errorf("head branch not cleaned up: %s", heads)
+	}
+}
+
+func TestDoMerge_ForgeChecksFail(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+	createFeatureBranch(t, workDir, "feature-a", "a.txt", "hello a\n")
+	before := run(t, workDir, "git", "rev-parse", "main")
+
+	e, srv := forgeEngineer(t, workDir, g)
+	srv.SetConclusion("", "failure")
+
+	result := e.doMerge(context.Background(), "feature-a", "main", "")
+	if result.Success || !result.TestsFailed {
+		t.Fatalf("doMerge = %+v, want TestsFailed", result)
+	}
+	if tip := run(t, workDir, "git", "ls-remote", "origin", "refs/heads/main"); !strings.HasPrefix(tip, before) {
+		t.Errorf("origin main moved to %q after failed checks", tip)
+	}
+	if local := run(t, workDir, "git", "rev-parse", "main"); local != before {
+		t.Errorf("local main = %s, want reset to %s", local, before)
+	}
+}
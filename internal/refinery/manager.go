@@ -17,6 +17,7 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/session"
@@ -69,9 +70,7 @@ func (m *Manager) SessionName() string {
 // ZFC: tmux session existence is the source of truth for session state,
 // but agent liveness determines if the session is actually functional.
 func (m *Manager) IsRunning() (bool, error) {
-	t := tmux.NewTmux()
-	sessionName := m.SessionName()
-	status := t.CheckSessionHealth(sessionName, 0)
+	status := session.CheckSessionHealth(m.sessions(), m.SessionName(), 0)
 	return status == tmux.SessionHealthy, nil
 }
 
@@ -81,17 +80,22 @@ func (m *Manager) IsRunning() (bool, error) {
 // Returns the detailed ZombieStatus for callers that need to distinguish
 // between different failure modes.
 func (m *Manager) IsHealthy(maxInactivity time.Duration) tmux.ZombieStatus {
-	t := tmux.NewTmux()
-	return t.CheckSessionHealth(m.SessionName(), maxInactivity)
+	return session.CheckSessionHealth(m.sessions(), m.SessionName(), maxInactivity)
+}
+
+// sessions returns the town's session backend: tmux, or the daemon's PTY
+// supervisor for headless towns.
+func (m *Manager) sessions() session.SessionBackend {
+	return session.NewBackend(filepath.Dir(m.rig.Path), nil)
 }
 
 // Status returns information about the refinery session.
 // ZFC-compliant: tmux session is the source of truth.
 func (m *Manager) Status() (*tmux.SessionInfo, error) {
-	t := tmux.NewTmux()
+	b := m.sessions()
 	sessionID := m.SessionName()
 
-	running, err := t.HasSession(sessionID)
+	running, err := b.HasSession(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("checking session: %w", err)
 	}
@@ -99,7 +103,7 @@ func (m *Manager) Status() (*tmux.SessionInfo, error) {
 		return nil, ErrNotRunning
 	}
 
-	return t.GetSessionInfo(sessionID)
+	return session.GetSessionInfo(b, sessionID)
 }
 
 // Start starts the refinery.
@@ -108,7 +112,7 @@ func (m *Manager) Status() (*tmux.SessionInfo, error) {
 // The agentOverride parameter allows specifying an agent alias to use instead of the town default.
 // ZFC-compliant: no state file, tmux session is source of truth.
 func (m *Manager) Start(foreground bool, agentOverride string) error {
-	t := tmux.NewTmux()
+	b := m.sessions()
 	sessionID := m.SessionName()
 
 	if foreground {
@@ -117,15 +121,15 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 	}
 
 	// Check if session already exists
-	running, _ := t.HasSession(sessionID)
+	running, _ := b.HasSession(sessionID)
 	if running {
 		// Session exists - check if agent is actually running (healthy vs zombie)
-		if t.IsAgentAlive(sessionID) {
+		if b.IsAgentAlive(sessionID) {
 			return ErrAlreadyRunning
 		}
 		// Zombie - tmux alive but agent dead. Kill and recreate.
 		_, _ = fmt.Fprintln(m.output, "⚠ Detected zombie session (tmux alive, agent dead). Recreating...")
-		if err := t.KillSession(sessionID); err != nil {
+		if err := b.KillSessionWithProcesses(sessionID); err != nil {
 			return fmt.Errorf("killing zombie session: %w", err)
 		}
 	}
@@ -178,13 +182,6 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 	// Generate the GASTA run ID for this refinery session.
 	runID := uuid.New().String()
 
-	// Create session with command directly to avoid send-keys race condition.
-	// See: https://github.com/anthropics/gastown/issues/280
-	if err := t.NewSessionWithCommand(sessionID, refineryRigDir, command); err != nil {
-		return fmt.Errorf("creating tmux session: %w", err)
-	}
-
-	// Set environment variables (non-fatal: session works without these)
 	// Use centralized AgentEnv for consistency across all role startup paths
 	envVars := config.AgentEnv(config.AgentEnvConfig{
 		Role:        "refinery",
@@ -197,30 +194,44 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 
 	// Add refinery-specific flag
 	envVars["GT_REFINERY"] = "1"
+	envVars["GT_RUN"] = runID
 
-	// Set all env vars in tmux session (for debugging) and they'll also be exported to Claude
-	for k, v := range envVars {
-		_ = t.SetEnvironment(sessionID, k, v)
-	}
-	_ = t.SetEnvironment(sessionID, "GT_RUN", runID)
+	if session.IsHeadless(townRoot) {
+		if err := session.StartHeadlessAgent(b, sessionID, refineryRigDir, command, envVars, runtimeConfig); err != nil {
+			return fmt.Errorf("waiting for refinery to start: %w", err)
+		}
+	} else {
+		t := tmux.NewTmux()
 
-	// Apply theme (non-fatal: theming failure doesn't affect operation)
-	theme := tmux.AssignTheme(m.rig.Name)
-	_ = t.ConfigureGasTownSession(sessionID, theme, m.rig.Name, "refinery", "refinery")
+		// Create session with command directly to avoid send-keys race condition.
+		// See: https://github.com/anthropics/gastown/issues/280
+		if err := t.NewSessionWithCommand(sessionID, refineryRigDir, command); err != nil {
+			return fmt.Errorf("creating tmux session: %w", err)
+		}
+
+		// Set all env vars in tmux session (for debugging) and they'll also be exported to Claude
+		for k, v := range envVars {
+			_ = t.SetEnvironment(sessionID, k, v)
+		}
+
+		// Apply theme (non-fatal: theming failure doesn't affect operation)
+		theme := tmux.AssignTheme(m.rig.Name)
+		_ = t.ConfigureGasTownSession(sessionID, theme, m.rig.Name, "refinery", "refinery")
 
-	// Accept startup dialogs (workspace trust + bypass permissions) if they appear.
-	// Must be before WaitForRuntimeReady to avoid race where dialog blocks prompt detection.
-	_ = t.AcceptStartupDialogs(sessionID)
+		// Accept startup dialogs (workspace trust + bypass permissions) if they appear.
+		// Must be before WaitForRuntimeReady to avoid race where dialog blocks prompt detection.
+		_ = t.AcceptStartupDialogs(sessionID)
 
-	// Wait for Claude to start and show its prompt - fatal if Claude fails to launch
-	// WaitForRuntimeReady waits for the runtime to be ready
-	if err := t.WaitForRuntimeReady(sessionID, runtimeConfig, constants.ClaudeStartTimeout); err != nil {
-		// Kill the zombie session before returning error
-		_ = t.KillSessionWithProcesses(sessionID)
-		return fmt.Errorf("waiting for refinery to start: %w", err)
+		// Wait for Claude to start and show its prompt - fatal if Claude fails to launch
+		// WaitForRuntimeReady waits for the runtime to be ready
+		if err := t.WaitForRuntimeReady(sessionID, runtimeConfig, constants.ClaudeStartTimeout); err != nil {
+			// Kill the zombie session before returning error
+			_ = t.KillSessionWithProcesses(sessionID)
+			return fmt.Errorf("waiting for refinery to start: %w", err)
+		}
 	}
 
-	_ = runtime.RunStartupFallback(t, sessionID, "refinery", runtimeConfig)
+	_ = runtime.RunStartupFallback(b, sessionID, "refinery", runtimeConfig)
 
 	// Stream refinery's Claude Code JSONL conversation log to VictoriaLogs (opt-in).
 	if os.Getenv("GT_LOG_AGENT_OUTPUT") == "true" && os.Getenv("GT_OTEL_LOGS_URL") != "" {
@@ -239,17 +250,17 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 // Stop stops the refinery.
 // ZFC-compliant: tmux session is the source of truth.
 func (m *Manager) Stop() error {
-	t := tmux.NewTmux()
+	b := m.sessions()
 	sessionID := m.SessionName()
 
-	// Check if tmux session exists
-	running, _ := t.HasSession(sessionID)
+	// Check if session exists
+	running, _ := b.HasSession(sessionID)
 	if !running {
 		return ErrNotRunning
 	}
 
-	// Kill the tmux session
-	return t.KillSession(sessionID)
+	// Kill the session and all its processes
+	return b.KillSessionWithProcesses(sessionID)
 }
 
 // Queue returns the current merge queue.
@@ -538,6 +549,7 @@ func (m *Manager) PostMerge(idOrBranch string) (*PostMergeResult, error) {
 			_, _ = fmt.Fprintf(m.output, "Warning: failed to update MR state: %v\n", closeErr)
 		}
 		result.MRClosed = true
+		LogMerged(filepath.Dir(m.rig.Path), m.rig.Name, mr.ID, mr.Worker, mr.Branch, mr.TargetBranch, "")
 	}
 
 	// Close the source issue with reason and --force to bypass dependency checks.
@@ -563,6 +575,19 @@ func (m *Manager) PostMerge(idOrBranch string) (*PostMergeResult, error) {
 	return result, nil
 }
 
+// LogMerged records a merged event in the town feed. The daemon's
+// plugin_events patrol opens mr.merged gates from it, matching on rig and
+// target. commit may be empty when the caller doesn't know the merge commit.
+func LogMerged(townRoot, rigName, mrID, worker, branch, target, commit string) {
+	payload := events.MergePayload(mrID, worker, branch, "")
+	payload["rig"] = rigName
+	payload["target"] = target
+	if commit != "" {
+		payload["commit"] = commit
+	}
+	_ = events.LogAt(townRoot, events.TypeMerged, rigName+"/refinery", payload)
+}
+
 // notifyWorkerRejected sends a rejection notification to a polecat.
 func (m *Manager) notifyWorkerRejected(mr *MergeRequest, reason string) {
 	// Nudge polecat about rejection instead of sending permanent mail.
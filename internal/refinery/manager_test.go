@@ -1,13 +1,16 @@
 package refinery
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/testutil"
@@ -263,6 +266,22 @@ func TestManager_PostMerge_ClosesMRAndSourceIssue(t *testing.T) {
 	if result.MR.Branch != "polecat/test/gt-xyz" {
 		t.Errorf("PostMerge() MR.Branch = %s, want polecat/test/gt-xyz", result.MR.Branch)
 	}
+
+	// The merged event is what opens mr.merged plugin gates.
+	feed, err := os.ReadFile(filepath.Join(filepath.Dir(rigPath), events.EventsFile))
+	if err != nil {
+		t.Fatalf("reading events: %v", err)
+	}
+	var merged events.Event
+	for _, line := range strings.Split(strings.TrimSpace(string(feed)), "\n") {
+		var e events.Event
+		if json.Unmarshal([]byte(line), &e) == nil && e.Type == events.TypeMerged {
+			merged = e
+		}
+	}
+	if merged.Actor != "testrig/refinery" || merged.Payload["mr"] != mrIssue.ID || merged.Payload["target"] != "main" || merged.Payload["rig"] != "testrig" {
+		t.Errorf("merged event = %+v", merged)
+	}
 }
 
 func TestManager_PostMerge_AlreadyClosedMR(t *testing.T) {
@@ -0,0 +1,338 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// DefaultConflictFormula is the formula agent-assisted resolution runs.
+const DefaultConflictFormula = "mol-polecat-conflict-resolve"
+
+// defaultRegenerateTimeout bounds a lockfile regeneration command.
+const defaultRegenerateTimeout = 5 * time.Minute
+
+// ConflictConfig configures automatic conflict resolution, tried before an
+// MR with conflicts is sent back to its author for rework.
+type ConflictConfig struct {
+	// Union lists path globs resolved by keeping both sides of each
+	// conflict (changelogs, import lists, registries).
+	Union []string `json:"union,omitempty"`
+
+	// Regenerate lists lockfiles rebuilt with a rig-defined command
+	// instead of being merged.
+	Regenerate []*RegenerateRule `json:"regenerate,omitempty"`
+
+	// Agent dispatches conflicts the mechanical strategies cannot resolve
+	// to a fresh polecat running Formula in its own worktree, instead of
+	// asking the author to rework.
+	Agent bool `json:"agent,omitempty"`
+
+	// Formula is the formula for agent-assisted resolution.
+	// Defaults to DefaultConflictFormula.
+	Formula string `json:"formula,omitempty"`
+}
+
+// RegenerateRule regenerates matching lockfiles with a command.
+type RegenerateRule struct {
+	// Paths are globs of the lockfiles this rule owns (e.g. "go.sum").
+	Paths []string `json:"paths"`
+
+	// Cmd runs in the merge worktree (e.g. "go mod tidy").
+	Cmd string `json:"cmd"`
+
+	// Timeout bounds Cmd (e.g. "2m"). Defaults to 5m.
+	Timeout string `json:"timeout,omitempty"`
+
+	timeout time.Duration
+}
+
+// Validate checks the conflict configuration and fills in defaults.
+func (c *ConflictConfig) Validate() error {
+	if c.Formula == "" {
+		c.Formula = DefaultConflictFormula
+	}
+	for _, p := range c.Union {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid union pattern %q: %w", p, err)
+		}
+	}
+	for i, r := range c.Regenerate {
+		if r == nil || len(r.Paths) == 0 || strings.TrimSpace(r.Cmd) == "" {
+			return fmt.Errorf("regenerate[%d] needs paths and cmd", i)
+		}
+		r.timeout = defaultRegenerateTimeout
+		if r.Timeout != "" {
+			d, err := time.ParseDuration(r.Timeout)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("regenerate[%d]: invalid timeout %q", i, r.Timeout)
+			}
+			r.timeout = d
+		}
+	}
+	return nil
+}
+
+// ConflictStrategy resolves conflicted files of a staged squash merge.
+// Resolve works in the merge worktree dir and returns the files it fully
+// resolved; files it cannot handle are left for the next strategy.
+type ConflictStrategy interface {
+	Name() string
+	Resolve(ctx context.Context, dir string, files []string) ([]string, error)
+}
+
+// conflictStrategies returns the mechanical strategies configured for the
+// rig, in the order they are tried.
+func (c *ConflictConfig) conflictStrategies() []ConflictStrategy {
+	var strategies []ConflictStrategy
+	if len(c.Union) > 0 {
+		strategies = append(strategies, &unionStrategy{patterns: c.Union})
+	}
+	for _, r := range c.Regenerate {
+		strategies = append(strategies, &regenerateStrategy{rule: r})
+	}
+	return strategies
+}
+
+// matchesAny reports whether file matches any glob, either as a whole path
+// or by base name, so "CHANGELOG.md" also matches "docs/CHANGELOG.md".
+func matchesAny(patterns []string, file string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, file); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, path.Base(file)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// unionStrategy resolves conflicts by keeping both sides.
+type unionStrategy struct {
+	patterns []string
+}
+
+func (s *unionStrategy) Name() string { return "union" }
+
+func (s *unionStrategy) Resolve(_ context.Context, dir string, files []string) ([]string, error) {
+	var resolved []string
+	for _, f := range files {
+		if !matchesAny(s.patterns, f) {
+			continue
+		}
+		p := filepath.Join(dir, f)
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return resolved, err
+		}
+		merged, ok := unionMerge(data)
+		if !ok {
+			continue
+		}
+		if err := os.WriteFile(p, merged, 0644); err != nil {
+			return resolved, err
+		}
+		resolved = append(resolved, f)
+	}
+	return resolved, nil
+}
+
+// unionMerge resolves every conflict hunk in data by keeping our lines
+// followed by their lines, dropping lines of theirs that ours already has
+// (so identical additions to an import list or changelog appear once).
+// Returns false if the conflict markers are malformed.
+func unionMerge(data []byte) ([]byte, bool) {
+	const (
+		outside = iota
+		inOurs
+		inBase
+		inTheirs
+	)
+	var out bytes.Buffer
+	var ours, theirs []string
+	state := outside
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		marker := strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(marker, "<<<<<<<"):
+			if state != outside {
+				return nil, false
+			}
+			state, ours, theirs = inOurs, nil, nil
+		case strings.HasPrefix(marker, "|||||||") && state == inOurs:
+			state = inBase
+		case marker == "=======" && (state == inOurs || state == inBase):
+			state = inTheirs
+		case strings.HasPrefix(marker, ">>>>>>>") && state == inTheirs:
+			seen := make(map[string]bool, len(ours))
+			for _, l := range ours {
+				seen[strings.TrimRight(l, "\r\n")] = true
+				out.WriteString(l)
+			}
+			for _, l := range theirs {
+				if !seen[strings.TrimRight(l, "\r\n")] {
+					out.WriteString(l)
+				}
+			}
+			state = outside
+		default:
+			switch state {
+			case outside:
+				out.WriteString(line)
+			case inOurs:
+				ours = append(ours, line)
+			case inTheirs:
+				theirs = append(theirs, line)
+			}
+		}
+	}
+	if state != outside {
+		return nil, false
+	}
+	return out.Bytes(), true
+}
+
+// regenerateStrategy resolves lockfile conflicts by taking the target's
+// version and re-running the rig's regeneration command over the merge.
+type regenerateStrategy struct {
+	rule *RegenerateRule
+}
+
+func (s *regenerateStrategy) Name() string { return "regenerate" }
+
+func (s *regenerateStrategy) Resolve(ctx context.Context, dir string, files []string) ([]string, error) {
+	var owned []string
+	for _, f := range files {
+		if matchesAny(s.rule.Paths, f) {
+			owned = append(owned, f)
+		}
+	}
+	if len(owned) == 0 {
+		return nil, nil
+	}
+
+	if err := git.NewGit(dir).CheckoutOurs(owned...); err != nil {
+		return nil, fmt.Errorf("taking target lockfiles: %w", err)
+	}
+
+	timeout := s.rule.timeout
+	if timeout <= 0 {
+		timeout = defaultRegenerateTimeout
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, "sh", "-c", s.rule.Cmd)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", s.rule.Cmd, err, strings.TrimSpace(string(out)))
+	}
+	return owned, nil
+}
+
+// autoResolveConflicts squash-merges mr onto the checked-out target and
+// tries the configured strategies on the conflicts. When every conflicted
+// file is resolved the merge is committed and true is returned; the result
+// still has to pass gates like any other merge. Otherwise the worktree is
+// reset and the MR goes on to rework.
+func (e *Engineer) autoResolveConflicts(ctx context.Context, mr *MRInfo) bool {
+	cc := e.config.Conflicts
+	if cc == nil {
+		return false
+	}
+	strategies := cc.conflictStrategies()
+	if len(strategies) == 0 {
+		return false
+	}
+
+	if err := e.git.MergeSquashStaged(mr.Branch); err == nil {
+		// Merged cleanly after all (e.g. the stack moved); nothing to resolve.
+		return e.commitResolved(mr, nil, nil)
+	}
+	remaining, err := e.git.GetConflictingFiles()
+	if err != nil || len(remaining) == 0 {
+		_ = e.git.ResetHard("HEAD")
+		return false
+	}
+
+	var applied, resolvedPaths []string
+	for _, s := range strategies {
+		if len(remaining) == 0 {
+			break
+		}
+		resolved, err := s.Resolve(ctx, e.workDir, remaining)
+		if err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s: %s resolution failed: %v\n", mr.ID, s.Name(), err)
+			continue
+		}
+		if len(resolved) == 0 {
+			continue
+		}
+		applied = append(applied, fmt.Sprintf("%s (%s)", s.Name(), strings.Join(resolved, ", ")))
+		resolvedPaths = append(resolvedPaths, resolved...)
+		done := make(map[string]bool, len(resolved))
+		for _, f := range resolved {
+			done[f] = true
+		}
+		var left []string
+		for _, f := range remaining {
+			if !done[f] {
+				left = append(left, f)
+			}
+		}
+		remaining = left
+	}
+
+	if len(remaining) > 0 {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s: could not auto-resolve %v\n", mr.ID, remaining)
+		_ = e.git.ResetHard("HEAD")
+		return false
+	}
+	return e.commitResolved(mr, applied, resolvedPaths)
+}
+
+// commitResolved stages the resolved paths on top of the squash merge and
+// commits it with the MR's merge message, noting which strategies were
+// applied. Anything else a strategy touched (e.g. files a regeneration
+// command rewrote) is left out of the commit and discarded.
+func (e *Engineer) commitResolved(mr *MRInfo, applied, resolved []string) bool {
+	msg := e.getMergeMessage(mr)
+	if len(applied) > 0 {
+		msg = strings.TrimRight(msg, "\n") + "\n\nConflicts auto-resolved: " + strings.Join(applied, "; ")
+	}
+	if len(resolved) > 0 {
+		if err := e.git.Add(append([]string{"--"}, resolved...)...); err != nil {
+			_ = e.git.ResetHard("HEAD")
+			return false
+		}
+	}
+	if err := e.git.Commit(msg); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s: committing resolution failed: %v\n", mr.ID, err)
+		_ = e.git.ResetHard("HEAD")
+		return false
+	}
+	_ = e.git.ResetHard("HEAD")
+	if len(applied) > 0 {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s: conflicts auto-resolved via %s\n", mr.ID, strings.Join(applied, "; "))
+	}
+	return true
+}
+
+// dispatchConflictAgent slings a conflict-resolution task to a fresh polecat
+// running the resolution formula.
+func (e *Engineer) dispatchConflictAgent(taskID string) error {
+	cmd := exec.Command("gt", "sling", taskID, e.rig.Name, "--formula", e.config.Conflicts.Formula, "--no-convoy")
+	cmd.Dir = e.workDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("gt sling: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
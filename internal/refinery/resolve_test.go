@@ -0,0 +1,224 @@
+package refinery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnionMerge(t *testing.T) {
+	in := `package x
+
+import (
+	"fmt"
+<<<<<<< HEAD
+	"os"
+	"strings"
+=======
+	"os"
+	"time"
+>>>>>>> feature
+)
+`
+	want := `package x
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+`
+	got, ok := unionMerge([]byte(in))
+	if !ok || string(got) != want {
+		t.Errorf("unionMerge = %q, %v\nwant %q", got, ok, want)
+	}
+
+	diff3 := "a\n<<<<<<< ours\nb\n||||||| base\nold\n=======\nc\n>>>>>>> theirs\n"
+	if got, ok := unionMerge([]byte(diff3)); !ok || string(got) != "a\nb\nc\n" {
+		t.Errorf("diff3 unionMerge = %q, %v", got, ok)
+	}
+
+	if _, ok := unionMerge([]byte("<<<<<<< ours\nb\n=======\n")); ok {
+		t.Error("unterminated conflict should not resolve")
+	}
+}
+
+func TestConflictConfig_Validate(t *testing.T) {
+	c := &ConflictConfig{Union: []string{"CHANGELOG.md"}, Regenerate: []*RegenerateRule{{Paths: []string{"go.sum"}, Cmd: "go mod tidy", Timeout: "2m"}}}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if c.Formula != DefaultConflictFormula || c.Regenerate[0].timeout.Minutes() != 2 {
+		t.Errorf("defaults not applied: %+v %+v", c, c.Regenerate[0])
+	}
+
+	for _, bad := range []*ConflictConfig{
+		{Union: []string{"["}},
+		{Regenerate: []*RegenerateRule{{Paths: []string{"go.sum"}}}},
+		{Regenerate: []*RegenerateRule{{Paths: []string{"go.sum"}, Cmd: "x", Timeout: "soon"}}},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", bad)
+		}
+	}
+
+	if !matchesAny([]string{"CHANGELOG.md"}, "docs/CHANGELOG.md") || matchesAny([]string{"*.lock"}, "go.sum") {
+		t.Error("matchesAny mismatch")
+	}
+}
+
+func TestBuildRebaseStack_AutoResolvesConflicts(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	writeFile(t, workDir, "CHANGELOG.md", "# Changes\n")
+	writeFile(t, workDir, "deps.lock", "base\n")
+	run(t, workDir, "git", "add", ".")
+	run(t, workDir, "git", "commit", "-m", "add changelog and lockfile")
+
+	run(t, workDir, "git", "checkout", "-b", "feature-a", "main")
+	writeFile(t, workDir, "CHANGELOG.md", "# Changes\n- feature a\n")
+	writeFile(t, workDir, "deps.lock", "a\n")
+	run(t, workDir, "git", "commit", "-am", "feat: a")
+	run(t, workDir, "git", "checkout", "-b", "feature-b", "main")
+	writeFile(t, workDir, "CHANGELOG.md", "# Changes\n- feature b\n")
+	writeFile(t, workDir, "deps.lock", "b\n")
+	run(t, workDir, "git", "commit", "-am", "feat: b")
+	run(t, workDir, "git", "checkout", "main")
+
+	e := newTestEngineer(t, workDir, g)
+	e.config.Conflicts = &ConflictConfig{
+		Union:      []string{"CHANGELOG.md"},
+		Regenerate: []*RegenerateRule{{Paths: []string{"*.lock"}, Cmd: "echo regenerated > deps.lock"}},
+	}
+	if err := e.config.Conflicts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	stacked, conflicts, err := e.BuildRebaseStack(context.Background(),
+		[]*MRInfo{makeMR("mr-a", "feature-a", "main"), makeMR("mr-b", "feature-b", "main")}, "main")
+	if err != nil {
+		t.Fatalf("BuildRebaseStack: %v", err)
+	}
+	if len(stacked) != 2 || len(conflicts) != 0 {
+		t.Fatalf("stacked %v, conflicts %d; want both stacked", stackedIDs(stacked), len(conflicts))
+	}
+
+	changelog, _ := os.ReadFile(filepath.Join(workDir, "CHANGELOG.md"))
+	if string(changelog) != "# Changes\n- feature a\n- feature b\n" {
+		t.Errorf("CHANGELOG.md = %q", changelog)
+	}
+	lock, _ := os.ReadFile(filepath.Join(workDir, "deps.lock"))
+	if strings.TrimSpace(string(lock)) != "regenerated" {
+		t.Errorf("deps.lock = %q", lock)
+	}
+	msg := run(t, workDir, "git", "log", "-1", "--format=%B")
+	if !strings.Contains(msg, "Conflicts auto-resolved: union (CHANGELOG.md); regenerate (deps.lock)") {
+		t.Errorf("resolution not recorded in commit message: %q", msg)
+	}
+}
+
+func TestAutoResolveConflicts_StagesOnlyResolvedPaths(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	writeFile(t, workDir, "deps.lock", "base\n")
+	writeFile(t, workDir, "notes.txt", "base\n")
+	run(t, workDir, "git", "add", ".")
+	run(t, workDir, "git", "commit", "-m", "add lockfile and notes")
+	run(t, workDir, "git", "checkout", "-b", "feature-a", "main")
+	writeFile(t, workDir, "deps.lock", "a\n")
+	run(t, workDir, "git", "commit", "-am", "feat: a")
+	run(t, workDir, "git", "checkout", "-b", "feature-b", "main")
+	writeFile(t, workDir, "deps.lock", "b\n")
+	run(t, workDir, "git", "commit", "-am", "feat: b")
+	run(t, workDir, "git", "checkout", "main")
+	run(t, workDir, "git", "merge", "--squash", "feature-a")
+	run(t, workDir, "git", "commit", "-m", "a")
+
+	// The regeneration command also rewrites a file that was not in conflict.
+	e := newTestEngineer(t, workDir, g)
+	e.config.Conflicts = &ConflictConfig{
+		Regenerate: []*RegenerateRule{{Paths: []string{"*.lock"}, Cmd: "echo regenerated > deps.lock && echo stray > notes.txt"}},
+	}
+	if err := e.config.Conflicts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if !e.autoResolveConflicts(context.Background(), makeMR("mr-b", "feature-b", "main")) {
+		t.Fatal("lockfile conflict not resolved")
+	}
+
+	if got := run(t, workDir, "git", "show", "HEAD:deps.lock"); got != "regenerated" {
+		t.Errorf("committed deps.lock = %q, want regenerated", got)
+	}
+	if got := run(t, workDir, "git", "show", "HEAD:notes.txt"); got != "base" {
+		t.Errorf("committed notes.txt = %q, want the stray rewrite left out", got)
+	}
+	if status := run(t, workDir, "git", "status", "--porcelain"); status != "" {
+		t.Errorf("worktree not clean after commit:\n%s", status)
+	}
+}
+
+func TestAutoResolveConflicts_LeavesUnhandledForRework(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	createFeatureBranch(t, workDir, "feature-a", "shared.go", "package a\n")
+	createConflictingBranch(t, workDir, "feature-b", "shared.go", "package b\n")
+	run(t, workDir, "git", "merge", "--squash", "feature-a")
+	run(t, workDir, "git", "commit", "-m", "a")
+	head := run(t, workDir, "git", "rev-parse", "HEAD")
+
+	e := newTestEngineer(t, workDir, g)
+	e.config.Conflicts = &ConflictConfig{Union: []string{"CHANGELOG.md"}}
+	if e.autoResolveConflicts(context.Background(), makeMR("mr-b", "feature-b", "main")) {
+		t.Fatal("resolved a conflict no strategy handles")
+	}
+	if got := run(t, workDir, "git", "rev-parse", "HEAD"); got != head {
+		t.Errorf("HEAD moved to %s", got)
+	}
+	if status := run(t, workDir, "git", "status", "--porcelain"); status != "" {
+		t.Errorf("worktree not reset:\n%s", status)
+	}
+}
+
+func TestDoMerge_ResolvedSubmoduleFailureResets(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	writeFile(t, workDir, "CHANGELOG.md", "# Changes\n")
+	run(t, workDir, "git", "add", ".")
+	run(t, workDir, "git", "commit", "-m", "add changelog")
+	run(t, workDir, "git", "push", "origin", "main")
+
+	// The branch bumps a submodule pointer that can't be pushed, and
+	// conflicts with main only in the changelog.
+	run(t, workDir, "git", "checkout", "-b", "feature-a", "main")
+	writeFile(t, workDir, "CHANGELOG.md", "# Changes\n- feature a\n")
+	run(t, workDir, "git", "update-index", "--add", "--cacheinfo",
+		"160000,"+run(t, workDir, "git", "rev-parse", "HEAD")+",vendor/lib")
+	run(t, workDir, "git", "add", "CHANGELOG.md")
+	run(t, workDir, "git", "commit", "-m", "feat: a")
+	run(t, workDir, "git", "checkout", "main")
+	writeFile(t, workDir, "CHANGELOG.md", "# Changes\n- fix b\n")
+	run(t, workDir, "git", "commit", "-am", "fix: b")
+	run(t, workDir, "git", "push", "origin", "main")
+	before := run(t, workDir, "git", "rev-parse", "main")
+
+	e := newTestEngineer(t, workDir, g)
+	e.config.Conflicts = &ConflictConfig{Union: []string{"CHANGELOG.md"}}
+	if err := e.config.Conflicts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	result := e.doMerge(context.Background(), "feature-a", "main", "")
+	if result.Success || !strings.Contains(result.Error, "submodule vendor/lib") {
+		t.Fatalf("doMerge = %+v, want submodule push failure", result)
+	}
+	if local := run(t, workDir, "git", "rev-parse", "main"); local != before {
+		t.Errorf("local main = %s, want reset to %s", local, before)
+	}
+}
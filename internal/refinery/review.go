@@ -0,0 +1,318 @@
+package refinery
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/protocol"
+)
+
+// DefaultReviewFormula is the molecule agent reviewers run.
+const DefaultReviewFormula = "mol-polecat-code-review"
+
+// ReviewConfig configures the optional pre-merge review stage. When enabled,
+// the refinery routes each MR to a reviewer and will not merge it until the
+// review is approved.
+type ReviewConfig struct {
+	// Enabled turns the review stage on for the rig.
+	Enabled bool `json:"enabled"`
+
+	// Reviewer is "agent" (the default) to dispatch a polecat running
+	// Formula, or a crew member as "crew/<name>".
+	Reviewer string `json:"reviewer,omitempty"`
+
+	// Agent is the agent preset agent reviewers run with. It must differ
+	// from the preset polecats author work with.
+	Agent string `json:"agent,omitempty"`
+
+	// Formula is the review formula for agent reviewers.
+	// Defaults to DefaultReviewFormula.
+	Formula string `json:"formula,omitempty"`
+}
+
+// Validate checks the review configuration and fills in defaults.
+func (c *ReviewConfig) Validate() error {
+	if c.Reviewer == "" {
+		c.Reviewer = "agent"
+	}
+	if c.Formula == "" {
+		c.Formula = DefaultReviewFormula
+	}
+	if !c.Enabled {
+		return nil
+	}
+	if c.Reviewer == "agent" {
+		if c.Agent == "" {
+			return fmt.Errorf("review.agent is required for agent reviewers (a preset other than the polecats')")
+		}
+		return nil
+	}
+	name, ok := strings.CutPrefix(c.Reviewer, "crew/")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid review.reviewer %q: want \"agent\" or \"crew/<name>\"", c.Reviewer)
+	}
+	return nil
+}
+
+// IsCrew reports whether reviews go to a crew member rather than an agent.
+func (c *ReviewConfig) IsCrew() bool {
+	return strings.HasPrefix(c.Reviewer, "crew/")
+}
+
+// reviewEnabled reports whether the rig has the review stage turned on.
+func (e *Engineer) reviewEnabled() bool {
+	return e.config.Review != nil && e.config.Review.Enabled
+}
+
+// reviewApproved reports whether an MR may proceed to merge under the
+// review stage. It only reads the MR; unreviewed MRs are routed by the
+// processing path (RouteUnreviewed, ProcessMRInfo).
+func reviewApproved(fields *beads.MRFields) bool {
+	return fields.ReviewStatus == beads.ReviewApproved
+}
+
+// RouteUnreviewed routes every open MR that has not been routed for review
+// yet. It is the review step of the processing loop. Returns the number of
+// MRs routed.
+func (e *Engineer) RouteUnreviewed() (int, error) {
+	if !e.reviewEnabled() {
+		return 0, nil
+	}
+	e.processMu.Lock()
+	defer e.processMu.Unlock()
+
+	issues, err := e.beads.List(beads.ListOptions{
+		Status:   "open",
+		Label:    "gt:merge-request",
+		Priority: -1,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("querying beads for merge-requests: %w", err)
+	}
+	routed := 0
+	for _, issue := range issues {
+		fields := beads.ParseMRFields(issue)
+		if issue.Status != "open" || fields == nil || fields.ReviewStatus != "" {
+			continue
+		}
+		if _, err := e.routeForReview(issueToMRInfo(issue, fields)); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not route %s for review: %v\n", issue.ID, err)
+			continue
+		}
+		routed++
+	}
+	return routed, nil
+}
+
+// RouteForReview creates a review task for an MR, blocks the MR on it, and
+// dispatches the task to the configured reviewer. Returns the task ID.
+//
+// Routing is idempotent: the task ID is recorded on the MR before anything
+// else, and a later call reuses a recorded task that is still open, so a
+// partial failure never leaves a second review task behind.
+func (e *Engineer) RouteForReview(mr *MRInfo) (string, error) {
+	e.processMu.Lock()
+	defer e.processMu.Unlock()
+	return e.routeForReview(mr)
+}
+
+// routeForReview is RouteForReview with e.processMu held.
+func (e *Engineer) routeForReview(mr *MRInfo) (string, error) {
+	rc := e.config.Review
+	if rc == nil || !rc.Enabled {
+		return "", fmt.Errorf("review stage is not enabled for rig %s", e.rig.Name)
+	}
+
+	target, reviewer, agent := e.rig.Name+"/"+rc.Reviewer, rc.Reviewer, ""
+	if !rc.IsCrew() {
+		if author := e.polecatAgent(); author == rc.Agent {
+			return "", fmt.Errorf("review agent %q is the preset polecats author with; pick a different one", rc.Agent)
+		}
+		target, reviewer, agent = e.rig.Name, "agent/"+rc.Agent, rc.Agent
+	}
+
+	issue, err := e.beads.Show(mr.ID)
+	if err != nil {
+		return "", fmt.Errorf("getting MR %s: %w", mr.ID, err)
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		fields = &beads.MRFields{}
+	}
+
+	// Reuse the task a previous, interrupted routing recorded.
+	var task *beads.Issue
+	if fields.ReviewTaskID != "" {
+		if existing, err := e.beads.Show(fields.ReviewTaskID); err == nil && existing.Status == "open" {
+			task = existing
+		}
+	}
+	if task == nil {
+		task, err = e.beads.Create(beads.CreateOptions{
+			Title:       fmt.Sprintf("Review: %s", mr.Title),
+			Labels:      []string{"gt:task", "gt:review"},
+			Priority:    mr.Priority,
+			Description: reviewTaskDescription(e.rig.Name, mr),
+			Actor:       e.rig.Name + "/refinery",
+		})
+		if err != nil {
+			return "", fmt.Errorf("creating review task: %w", err)
+		}
+		if err := e.updateReviewFields(mr.ID, func(f *beads.MRFields) {
+			f.ReviewTaskID = task.ID
+		}); err != nil {
+			if closeErr := e.beads.CloseWithReason("review routing failed", task.ID); closeErr != nil {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to close orphaned review task %s: %v\n", task.ID, closeErr)
+			}
+			return "", err
+		}
+	}
+
+	// Block the MR on the review task, same as conflict resolution.
+	if !slices.Contains(issue.BlockedBy, task.ID) {
+		if err := e.beads.AddDependency(mr.ID, task.ID); err != nil {
+			return task.ID, fmt.Errorf("blocking MR on review task: %w", err)
+		}
+	}
+	if task.Assignee == "" {
+		if err := e.reviewDispatch(task.ID, target, agent, rc.Formula); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: review task %s created but not dispatched: %v\n", task.ID, err)
+		}
+	}
+	if err := e.updateReviewFields(mr.ID, func(f *beads.MRFields) {
+		f.ReviewStatus = beads.ReviewPending
+		f.ReviewTaskID = task.ID
+		f.Reviewer = reviewer
+	}); err != nil {
+		return task.ID, err
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s routed for review to %s (task %s)\n", mr.ID, reviewer, task.ID)
+	return task.ID, nil
+}
+
+// reviewTaskDescription is the body of the review task for mr.
+func reviewTaskDescription(rigName string, mr *MRInfo) string {
+	return fmt.Sprintf(`Review merge request %s before it merges.
+
+## Metadata
+- MR: %s
+- Branch: %s
+- Target: %s
+- Original issue: %s
+- Author: %s
+
+## Instructions
+1. Review the diff: git diff origin/%s...origin/%s
+2. Record a verdict:
+   gt mq review approve %s %s
+   gt mq review request-changes %s %s -m "<what to change>"
+
+The MR stays blocked until the verdict is recorded.`,
+		mr.ID,
+		mr.ID, mr.Branch, mr.Target, mr.SourceIssue, mr.Worker,
+		mr.Target, mr.Branch,
+		rigName, mr.ID,
+		rigName, mr.ID,
+	)
+}
+
+// RecordReview records a review verdict on an MR. Approval unblocks the MR
+// for merging. Requesting changes holds the MR until the author resubmits
+// and sends the feedback to the author via the witness as a REWORK_REQUEST.
+func (e *Engineer) RecordReview(mrID string, approve bool, reviewer, feedback string) error {
+	issue, err := e.beads.Show(mrID)
+	if err != nil {
+		return fmt.Errorf("getting MR %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		return fmt.Errorf("%s is not a merge request", mrID)
+	}
+	if fields.ReviewStatus != beads.ReviewPending {
+		return fmt.Errorf("MR %s is not awaiting review (review status %q)", mrID, fields.ReviewStatus)
+	}
+
+	polecat := strings.TrimPrefix(fields.Worker, "polecats/")
+	if polecat != "" && (reviewer == e.rig.Name+"/"+polecat || reviewer == e.rig.Name+"/polecats/"+polecat) {
+		return fmt.Errorf("%s authored MR %s and cannot review it", reviewer, mrID)
+	}
+
+	status, reason := beads.ReviewApproved, "approved by "+reviewer
+	if !approve {
+		status, reason = beads.ReviewChangesRequested, "changes requested by "+reviewer
+	}
+	fields.ReviewStatus = status
+	fields.Reviewer = reviewer
+	newDesc := beads.SetMRFields(issue, fields)
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		return fmt.Errorf("updating MR %s: %w", mrID, err)
+	}
+
+	// Closing the review task unblocks the MR. With changes requested the
+	// review status keeps it out of the ready queue until resubmission.
+	if fields.ReviewTaskID != "" {
+		if err := e.beads.CloseWithReason(reason, fields.ReviewTaskID); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to close review task %s: %v\n", fields.ReviewTaskID, err)
+		}
+	}
+
+	if !approve {
+		msg := protocol.NewReviewReworkMessage(e.rig.Name, polecat, fields.Branch, fields.SourceIssue, fields.Target, reviewer, feedback)
+		if err := e.router.Send(msg); err != nil {
+			return fmt.Errorf("sending rework request: %w", err)
+		}
+	}
+	return nil
+}
+
+// ResetReview clears a changes-requested verdict after the author
+// resubmits, so the MR is routed for review again.
+func ResetReview(b *beads.Beads, mr *beads.Issue) (bool, error) {
+	fields := beads.ParseMRFields(mr)
+	if fields == nil || fields.ReviewStatus != beads.ReviewChangesRequested {
+		return false, nil
+	}
+	fields.ReviewStatus = ""
+	fields.ReviewTaskID = ""
+	newDesc := beads.SetMRFields(mr, fields)
+	if err := b.Update(mr.ID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		return false, fmt.Errorf("resetting review on %s: %w", mr.ID, err)
+	}
+	return true, nil
+}
+
+func (e *Engineer) updateReviewFields(mrID string, update func(*beads.MRFields)) error {
+	issue, err := e.beads.Show(mrID)
+	if err != nil {
+		return fmt.Errorf("getting MR %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		fields = &beads.MRFields{}
+	}
+	update(fields)
+	newDesc := beads.SetMRFields(issue, fields)
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		return fmt.Errorf("updating MR %s: %w", mrID, err)
+	}
+	return nil
+}
+
+// slingReview dispatches a review task with gt sling: to a fresh polecat
+// running the review formula for agent reviewers, or onto a crew member's hook.
+func (e *Engineer) slingReview(taskID, target, agent, formula string) error {
+	args := []string{"sling", taskID, target}
+	if agent != "" {
+		args = append(args, "--agent", agent, "--formula", formula, "--no-merge", "--no-convoy")
+	}
+	cmd := exec.Command("gt", args...)
+	cmd.Dir = e.workDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("gt sling: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
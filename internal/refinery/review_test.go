@@ -0,0 +1,106 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestEngineer_LoadConfig_Review(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := `{"merge_queue": {"review": {"enabled": true, "agent": "codex"}}}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	rc := e.Config().Review
+	if rc == nil || !rc.Enabled {
+		t.Fatalf("review not enabled: %+v", rc)
+	}
+	if rc.Reviewer != "agent" || rc.Formula != DefaultReviewFormula || rc.IsCrew() {
+		t.Errorf("defaults not applied: %+v", rc)
+	}
+}
+
+func TestReviewConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ReviewConfig
+		wantErr bool
+	}{
+		{"disabled needs nothing", ReviewConfig{}, false},
+		{"agent with preset", ReviewConfig{Enabled: true, Agent: "codex"}, false},
+		{"agent without preset", ReviewConfig{Enabled: true}, true},
+		{"crew member", ReviewConfig{Enabled: true, Reviewer: "crew/max"}, false},
+		{"crew without name", ReviewConfig{Enabled: true, Reviewer: "crew/"}, true},
+		{"unknown reviewer", ReviewConfig{Enabled: true, Reviewer: "mayor"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRouteForReview_RejectsAuthorPreset(t *testing.T) {
+	e := NewEngineer(&rig.Rig{Name: "gastown", Path: t.TempDir()})
+	e.config.Review = &ReviewConfig{Enabled: true, Reviewer: "agent", Agent: "claude"}
+	e.polecatAgent = func() string { return "claude" }
+	e.reviewDispatch = func(taskID, target, agent, formula string) error {
+		t.Fatal("review dispatched to the author's preset")
+		return nil
+	}
+
+	_, err := e.RouteForReview(&MRInfo{ID: "gt-mr1", Branch: "polecat/nux/gt-abc"})
+	if err == nil || !strings.Contains(err.Error(), "preset polecats author with") {
+		t.Errorf("RouteForReview error = %v, want same-preset rejection", err)
+	}
+}
+
+func TestReviewApproved(t *testing.T) {
+	for status, want := range map[string]bool{
+		beads.ReviewApproved:         true,
+		beads.ReviewPending:          false,
+		beads.ReviewChangesRequested: false,
+		"":                           false,
+	} {
+		if got := reviewApproved(&beads.MRFields{ReviewStatus: status}); got != want {
+			t.Errorf("reviewApproved(%q) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestProcessMRInfo_RoutesUnreviewed(t *testing.T) {
+	var out bytes.Buffer
+	e := NewEngineer(&rig.Rig{Name: "gastown", Path: t.TempDir()})
+	e.SetOutput(&out)
+	e.config.Review = &ReviewConfig{Enabled: true, Reviewer: "agent", Agent: "claude"}
+	e.polecatAgent = func() string { return "claude" } // routing fails before touching beads
+
+	result := e.ProcessMRInfo(context.Background(), &MRInfo{ID: "gt-mr1", Branch: "polecat/nux/gt-abc", Target: "main"})
+	if result.Success || !strings.Contains(result.Error, "awaiting review") {
+		t.Errorf("ProcessMRInfo = %+v, want held for review", result)
+	}
+	if !strings.Contains(out.String(), "could not route gt-mr1 for review") {
+		t.Errorf("unrouted MR should attempt routing, output: %q", out.String())
+	}
+
+	out.Reset()
+	result = e.ProcessMRInfo(context.Background(), &MRInfo{ID: "gt-mr2", ReviewStatus: beads.ReviewPending})
+	if result.Success || strings.Contains(out.String(), "route") {
+		t.Errorf("pending MR: result %+v, output %q; want held without routing", result, out.String())
+	}
+}
@@ -0,0 +1,213 @@
+package refinery
+
+import (
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Stacked MRs are submitted with `gt mq submit --on <mr>`: the branch builds
+// on another open MR's branch and records that MR (stack_on) plus the base
+// branch tip it was built on (stack_base). The stacked MR depends on its
+// base, and is only merged in the same batch as the base or after it. When
+// the base is reworked or merged, the refinery rebases the stacked branch
+// so it carries only its own commits on top of the new base. Restacking
+// force-pushes, so it only runs while processing MRs (under processMu);
+// listing the queue never touches branches.
+
+// stackBaseOf returns the MR an issue is stacked on when that MR is its only
+// open blocker, or "" if the issue is not stacked or is blocked by something
+// else as well.
+func (e *Engineer) stackBaseOf(issue *beads.Issue, fields *beads.MRFields) string {
+	if fields == nil || fields.StackOn == "" {
+		return ""
+	}
+	for _, blockerID := range issue.BlockedBy {
+		if blockerID == fields.StackOn {
+			continue
+		}
+		if isOpen, err := e.IsBeadOpen(blockerID); err == nil && isOpen {
+			return ""
+		}
+	}
+	return fields.StackOn
+}
+
+// orderStacks reorders mrs so every stacked MR comes after its base,
+// otherwise keeping the given order. The base moves up to where its
+// first dependent was.
+func orderStacks(mrs []*MRInfo) []*MRInfo {
+	byID := make(map[string]*MRInfo, len(mrs))
+	for _, mr := range mrs {
+		byID[mr.ID] = mr
+	}
+	ordered := make([]*MRInfo, 0, len(mrs))
+	placed := make(map[string]bool, len(mrs))
+	var place func(mr *MRInfo)
+	place = func(mr *MRInfo) {
+		if placed[mr.ID] {
+			return
+		}
+		placed[mr.ID] = true // set first so a cycle can't recurse forever
+		if base := byID[mr.BlockedBy]; base != nil {
+			place(base)
+		}
+		ordered = append(ordered, mr)
+	}
+	for _, mr := range mrs {
+		place(mr)
+	}
+	return ordered
+}
+
+// withoutOrphans drops MRs blocked by an MR that does not precede them in
+// mrs. A stacked MR can't land without its base: its branch carries the
+// base's commits.
+func withoutOrphans(mrs []*MRInfo) []*MRInfo {
+	kept := make([]*MRInfo, 0, len(mrs))
+	seen := make(map[string]bool, len(mrs))
+	for _, mr := range mrs {
+		if mr.BlockedBy != "" && !seen[mr.BlockedBy] {
+			continue
+		}
+		seen[mr.ID] = true
+		kept = append(kept, mr)
+	}
+	return kept
+}
+
+// hasMR reports whether mrs contains the MR with the given ID.
+func hasMR(mrs []*MRInfo, id string) bool {
+	for _, mr := range mrs {
+		if mr.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// restackBranch rebases the commits of branch after upstream onto onto,
+// moves the branch to the result, and force-pushes it. It works detached,
+// so the branch may be checked out in a polecat's worktree. Returns the new
+// branch tip. On conflict the rebase is aborted and nothing changes.
+func (e *Engineer) restackBranch(branch, upstream, onto string) (string, error) {
+	oldTip, err := e.git.Rev(branch)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", branch, err)
+	}
+	current, err := e.git.CurrentBranch()
+	if err != nil {
+		return "", fmt.Errorf("getting current branch: %w", err)
+	}
+	if err := e.git.Checkout(oldTip); err != nil {
+		return "", fmt.Errorf("checking out %s: %w", branch, err)
+	}
+	defer func() { _ = e.git.Checkout(current) }()
+
+	if err := e.git.RebaseOnto(onto, upstream); err != nil {
+		_ = e.git.AbortRebase()
+		return "", fmt.Errorf("rebasing %s onto %s: %w", branch, onto, err)
+	}
+	newTip, err := e.git.Rev("HEAD")
+	if err != nil {
+		return "", err
+	}
+	if err := e.git.UpdateRef("refs/heads/"+branch, newTip, oldTip); err != nil {
+		return "", fmt.Errorf("moving %s: %w", branch, err)
+	}
+	if err := e.git.Push("origin", branch, true); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: pushing restacked %s: %v\n", branch, err)
+	}
+	return newTip, nil
+}
+
+// syncStack restacks mr when its base MR's branch has moved since mr was
+// built on it (the base was reworked). Failures are logged; the MR then
+// goes through the normal conflict path when it is merged. Caller must hold
+// e.processMu.
+func (e *Engineer) syncStack(mr *MRInfo) {
+	base, err := e.beads.Show(mr.StackOn)
+	if err != nil {
+		return
+	}
+	baseFields := beads.ParseMRFields(base)
+	if baseFields == nil || baseFields.Branch == "" {
+		return
+	}
+	baseTip, err := e.git.Rev(baseFields.Branch)
+	if err != nil || baseTip == mr.StackBase {
+		return
+	}
+
+	// Already rebuilt on the new base (e.g. by its author).
+	if ok, _ := e.git.IsAncestor(baseTip, mr.Branch); ok {
+		e.setStackFields(mr, mr.StackOn, baseTip)
+		return
+	}
+	if mr.StackBase == "" {
+		// Unknown build point: nothing safe to replay from.
+		_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s: base %s moved but stack_base is unset; restack %s manually\n", mr.ID, mr.StackOn, mr.Branch)
+		return
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s: base %s was reworked, restacking %s\n", mr.ID, mr.StackOn, mr.Branch)
+	if _, err := e.restackBranch(mr.Branch, mr.StackBase, baseTip); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: MR %s: %v\n", mr.ID, err)
+		return
+	}
+	e.setStackFields(mr, mr.StackOn, baseTip)
+}
+
+// restackDependents moves MRs stacked on a just-merged MR onto the target
+// branch, so they carry only their own commits and are no longer stacked.
+// Called before the merged branch is deleted, with e.processMu held.
+func (e *Engineer) restackDependents(merged *MRInfo) {
+	issues, err := e.beads.List(beads.ListOptions{
+		Status:   "open",
+		Label:    "gt:merge-request",
+		Priority: -1,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: listing stacked MRs: %v\n", err)
+		return
+	}
+	for _, issue := range issues {
+		fields := beads.ParseMRFields(issue)
+		if issue.Status != "open" || fields == nil || fields.StackOn != merged.ID {
+			continue
+		}
+		child := issueToMRInfo(issue, fields)
+		upstream := child.StackBase
+		if upstream == "" {
+			upstream = merged.Branch
+		}
+		onto := "origin/" + child.Target
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Restacking %s (%s) onto %s\n", child.ID, child.Branch, onto)
+		if _, err := e.restackBranch(child.Branch, upstream, onto); err != nil {
+			// Left stacked on a closed MR: the squash merge replays the base's
+			// commits, which usually apply cleanly on top of their own merge.
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: MR %s: %v\n", child.ID, err)
+			continue
+		}
+		e.setStackFields(child, "", "")
+	}
+}
+
+// setStackFields records an MR's stack position in its bead.
+func (e *Engineer) setStackFields(mr *MRInfo, stackOn, stackBase string) {
+	mr.StackOn, mr.StackBase = stackOn, stackBase
+	issue, err := e.beads.Show(mr.ID)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: updating stack fields of %s: %v\n", mr.ID, err)
+		return
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		fields = &beads.MRFields{}
+	}
+	fields.StackOn, fields.StackBase = stackOn, stackBase
+	desc := beads.SetMRFields(issue, fields)
+	if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &desc}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: updating stack fields of %s: %v\n", mr.ID, err)
+	}
+}
@@ -0,0 +1,92 @@
+package refinery
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func stackedMR(id, base string) *MRInfo {
+	mr := makeMR(id, "polecat/"+id, "main")
+	mr.BlockedBy = base
+	mr.StackOn = base
+	return mr
+}
+
+func TestOrderStacks(t *testing.T) {
+	// Queue order puts the stacked MRs ahead of their bases.
+	mrs := []*MRInfo{stackedMR("c", "b"), makeMR("x", "polecat/x", "main"), stackedMR("b", "a"), makeMR("a", "polecat/a", "main")}
+	got := strings.Join(mrIDs(orderStacks(mrs)), ",")
+	if got != "a,b,c,x" {
+		t.Errorf("orderStacks = %s, want a,b,c,x", got)
+	}
+}
+
+func TestWithoutOrphans(t *testing.T) {
+	mrs := []*MRInfo{makeMR("a", "polecat/a", "main"), stackedMR("b", "a"), stackedMR("c", "b"), stackedMR("z", "missing")}
+	if got := strings.Join(mrIDs(withoutOrphans(mrs)), ","); got != "a,b,c" {
+		t.Errorf("withoutOrphans = %s, want a,b,c", got)
+	}
+	if got := withoutOrphans(mrs[1:]); len(got) != 0 {
+		t.Errorf("stack without its base kept %v", mrIDs(got))
+	}
+}
+
+func TestRestackBranch_OntoReworkedBase(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	createFeatureBranch(t, workDir, "base", "base.txt", "v1\n")
+	oldBase := run(t, workDir, "git", "rev-parse", "base")
+	run(t, workDir, "git", "checkout", "-b", "child", "base")
+	writeFile(t, workDir, "child.txt", "child\n")
+	run(t, workDir, "git", "add", ".")
+	run(t, workDir, "git", "commit", "-m", "feat: child")
+
+	// Rework the base: amend its only commit.
+	run(t, workDir, "git", "checkout", "base")
+	writeFile(t, workDir, "base.txt", "v2\n")
+	run(t, workDir, "git", "commit", "-a", "--amend", "-m", "feat: base v2")
+	newBase := run(t, workDir, "git", "rev-parse", "base")
+	run(t, workDir, "git", "checkout", "main")
+
+	e := newTestEngineer(t, workDir, g)
+	tip, err := e.restackBranch("child", oldBase, newBase)
+	if err != nil {
+		t.Fatalf("restackBranch: %v", err)
+	}
+	if got := run(t, workDir, "git", "rev-parse", "child"); got != tip {
+		t.Errorf("child at %s, want %s", got, tip)
+	}
+	if parent := run(t, workDir, "git", "rev-parse", "child~1"); parent != newBase {
+		t.Errorf("child not on reworked base: parent %s, want %s", parent, newBase)
+	}
+	if content := run(t, workDir, "git", "show", "child:base.txt"); content != "v2" {
+		t.Errorf("child has base.txt %q, want v2", content)
+	}
+	if branch := run(t, workDir, "git", "branch", "--show-current"); branch != "main" {
+		t.Errorf("left worktree on %q, want main", branch)
+	}
+}
+
+func TestBuildRebaseStack_DefersStackWithoutBase(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	createFeatureBranch(t, workDir, "polecat/a", "a.txt", "a\n")
+	createFeatureBranch(t, workDir, "polecat/b", "b.txt", "b\n")
+	createFeatureBranch(t, workDir, "polecat/c", "c.txt", "c\n")
+
+	e := newTestEngineer(t, workDir, g)
+	batch := []*MRInfo{makeMR("a", "polecat/a", "main"), stackedMR("b", "gone"), stackedMR("c", "a")}
+	stacked, conflicts, err := e.BuildRebaseStack(context.Background(), batch, "main")
+	if err != nil {
+		t.Fatalf("BuildRebaseStack: %v", err)
+	}
+	if got := strings.Join(stackedIDs(stacked), ","); got != "a,c" {
+		t.Errorf("stacked %s, want a,c", got)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("deferred stacked MR reported as conflict: %v", mrIDs(conflicts))
+	}
+}
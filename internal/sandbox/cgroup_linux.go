@@ -0,0 +1,105 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// cgroupRoot is where the cgroup v2 hierarchy is mounted.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroup is a leaf cgroup v2 directory holding one sandbox.
+type cgroup struct {
+	dir string
+}
+
+// newCgroup creates name under parent with the given limits. The parent
+// must be delegated to the user and must not hold processes of its own, or
+// enabling controllers fails with EBUSY.
+func newCgroup(parent, name string, l Limits) (*cgroup, error) {
+	var controllers []string
+	if l.CPUs > 0 {
+		controllers = append(controllers, "+cpu")
+	}
+	if l.MemoryMB > 0 {
+		controllers = append(controllers, "+memory")
+	}
+	if l.Pids > 0 {
+		controllers = append(controllers, "+pids")
+	}
+	if err := os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte(strings.Join(controllers, " ")), 0); err != nil {
+		if errors.Is(err, syscall.EBUSY) {
+			return nil, fmt.Errorf("enabling controllers in %s: cgroup has its own processes; set sandbox.cgroup_parent to an empty delegated cgroup", parent)
+		}
+		return nil, fmt.Errorf("enabling controllers in %s: %w", parent, err)
+	}
+
+	cg := &cgroup{dir: filepath.Join(parent, name)}
+	// A crashed predecessor may have left its (now empty) cgroup behind.
+	_ = os.Remove(cg.dir)
+	if err := os.Mkdir(cg.dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating cgroup: %w", err)
+	}
+
+	limits := map[string]string{}
+	if l.CPUs > 0 {
+		const period = 100000
+		limits["cpu.max"] = fmt.Sprintf("%d %d", int64(l.CPUs*period), period)
+	}
+	if l.MemoryMB > 0 {
+		limits["memory.max"] = strconv.FormatInt(int64(l.MemoryMB)<<20, 10)
+	}
+	if l.Pids > 0 {
+		limits["pids.max"] = strconv.Itoa(l.Pids)
+	}
+	for file, value := range limits {
+		if err := os.WriteFile(filepath.Join(cg.dir, file), []byte(value), 0); err != nil {
+			_ = os.Remove(cg.dir)
+			return nil, fmt.Errorf("setting %s: %w", file, err)
+		}
+	}
+	// Without this the memory limit only moves the overflow to swap.
+	_ = os.WriteFile(filepath.Join(cg.dir, "memory.swap.max"), []byte("0"), 0)
+	return cg, nil
+}
+
+// open returns a directory fd for starting a process inside the cgroup.
+func (c *cgroup) open() (*os.File, error) {
+	return os.Open(c.dir)
+}
+
+// counters returns the cgroup's limit-hit counters, keyed by violation kind.
+func (c *cgroup) counters() map[string]int {
+	return map[string]int{
+		ViolationMemory: readEventCounter(filepath.Join(c.dir, "memory.events"), "oom_kill"),
+		ViolationPids:   readEventCounter(filepath.Join(c.dir, "pids.events"), "max"),
+	}
+}
+
+// remove deletes the cgroup once its processes are gone.
+func (c *cgroup) remove() {
+	for i := 0; i < 20; i++ {
+		if err := os.Remove(c.dir); err == nil || os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// readEventCounter reads one "key value" line of a cgroup events file.
+// Missing files and keys read as zero.
+func readEventCounter(path, key string) int {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is inside our cgroup
+	if err != nil {
+		return 0
+	}
+	return parseEventCounter(string(data), key)
+}
@@ -0,0 +1,66 @@
+package sandbox
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// listenRelay listens on the unix socket at path and relays each connection
+// to the TCP address addr. It runs outside the sandbox's network namespace.
+func listenRelay(path, addr string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	_ = os.Remove(path)
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	go relay(ln, func() (net.Conn, error) { return net.Dial("tcp", addr) })
+	return ln, nil
+}
+
+// relay accepts connections on ln until it is closed, piping each to a new
+// connection from dial.
+func relay(ln net.Listener, dial func() (net.Conn, error)) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer func() { _ = conn.Close() }()
+			upstream, err := dial()
+			if err != nil {
+				return
+			}
+			defer func() { _ = upstream.Close() }()
+			pipe(conn, upstream)
+		}()
+	}
+}
+
+// pipe copies between a and b until both directions finish.
+func pipe(a, b net.Conn) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	copyHalf := func(dst, src net.Conn) {
+		defer wg.Done()
+		_, _ = io.Copy(dst, src)
+		if cw, ok := dst.(interface{ CloseWrite() error }); ok {
+			_ = cw.CloseWrite()
+		} else {
+			_ = dst.Close()
+		}
+	}
+	go copyHalf(a, b)
+	go copyHalf(b, a)
+	wg.Wait()
+}
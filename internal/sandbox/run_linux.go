@@ -0,0 +1,443 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// violationPollInterval is how often the outer process checks the cgroup's
+// event counters while the agent runs.
+const violationPollInterval = 5 * time.Second
+
+// Run runs spec.Command in the sandbox and returns its exit code. It must
+// be called from the gt binary: the sandbox is entered by re-executing gt
+// as "gt sandbox init". onViolation is called for each limit the agent
+// hits while it runs; it may be nil.
+func Run(spec *Spec, onViolation func(Violation)) (int, error) {
+	if err := spec.Validate(); err != nil {
+		return 0, err
+	}
+	gitWritable, gitProtected := gitDirs(spec.WorkDir)
+	spec.Writable = append(spec.Writable, gitWritable...)
+	spec.Protected = append(spec.Protected, gitProtected...)
+
+	var cg *cgroup
+	if !spec.Limits.IsZero() {
+		var err error
+		name := fmt.Sprintf("gt-sandbox-%s-%s", spec.Rig, spec.Polecat)
+		if cg, err = newCgroup(spec.CgroupParent, name, spec.Limits); err != nil {
+			return 0, fmt.Errorf("sandbox: %w", err)
+		}
+		defer cg.remove()
+	}
+
+	if spec.Network == NetworkProxy {
+		ln, err := listenRelay(spec.relaySocket(), spec.ProxyAddr)
+		if err != nil {
+			return 0, fmt.Errorf("sandbox: proxy relay: %w", err)
+		}
+		defer func() {
+			_ = ln.Close()
+			_ = os.Remove(spec.relaySocket())
+		}()
+	}
+
+	encoded, err := encodeSpec(spec)
+	if err != nil {
+		return 0, err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("sandbox: locating gt: %w", err)
+	}
+
+	cmd := exec.Command(self, "sandbox", "init") //nolint:gosec // G204: re-executing ourselves
+	cmd.Dir = spec.WorkDir
+	cmd.Env = append(os.Environ(), specEnv+"="+encoded)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cloneFlags := uintptr(syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID)
+	if spec.isolatedNetwork() {
+		cloneFlags |= syscall.CLONE_NEWNET
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:                 cloneFlags,
+		UidMappings:                []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}},
+		GidMappings:                []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}},
+		GidMappingsEnableSetgroups: false,
+		Pdeathsig:                  syscall.SIGKILL,
+	}
+	if cg != nil {
+		dir, err := cg.open()
+		if err != nil {
+			return 0, fmt.Errorf("sandbox: opening cgroup: %w", err)
+		}
+		defer func() { _ = dir.Close() }()
+		cmd.SysProcAttr.UseCgroupFD = true
+		cmd.SysProcAttr.CgroupFD = int(dir.Fd())
+	}
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("sandbox: entering namespaces: %w", err)
+	}
+
+	stopSignals := forwardSignals(cmd.Process)
+	defer stopSignals()
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	seen := map[string]int{}
+	check := func() {
+		if cg == nil || onViolation == nil {
+			return
+		}
+		cur := cg.counters()
+		for _, v := range diffViolations(seen, cur, spec.Limits) {
+			onViolation(v)
+		}
+		seen = cur
+	}
+	ticker := time.NewTicker(violationPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			check()
+		case err := <-done:
+			check()
+			return exitCode(cmd.ProcessState, err)
+		}
+	}
+}
+
+// Init is the "gt sandbox init" stage, running as PID 1 of the sandbox's
+// namespaces. It locks down the filesystem, brings up loopback and the
+// proxy relay when the network is isolated, then runs the agent and reaps
+// orphans until the agent exits. Returns the agent's exit code.
+func Init() (int, error) {
+	spec, err := decodeSpec(os.Getenv(specEnv))
+	if err != nil {
+		return 0, err
+	}
+	_ = os.Unsetenv(specEnv)
+
+	if err := setupMounts(spec); err != nil {
+		return 0, fmt.Errorf("sandbox: %w", err)
+	}
+
+	env := append(os.Environ(), "GT_SANDBOX=1")
+	if spec.isolatedNetwork() {
+		if err := loopbackUp(); err != nil {
+			return 0, fmt.Errorf("sandbox: bringing up loopback: %w", err)
+		}
+	}
+	if spec.Network == NetworkProxy {
+		port, _ := proxyPort(spec.ProxyAddr)
+		ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+		if err != nil {
+			return 0, fmt.Errorf("sandbox: proxy relay: %w", err)
+		}
+		socket := spec.relaySocket()
+		go relay(ln, func() (net.Conn, error) { return net.Dial("unix", socket) })
+		env = append(env, fmt.Sprintf("GT_PROXY_URL=https://127.0.0.1:%d", port))
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", spec.Command) //nolint:gosec // G204: command is the agent startup command
+	cmd.Dir = spec.WorkDir
+	cmd.Env = env
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("sandbox: starting agent: %w", err)
+	}
+	stopSignals := forwardSignals(cmd.Process)
+	defer stopSignals()
+
+	// As PID 1 we inherit every orphan in the namespace, so reap them all
+	// and return when the agent itself exits. The kernel kills whatever is
+	// left once we're gone.
+	for {
+		var ws unix.WaitStatus
+		pid, err := unix.Wait4(-1, &ws, 0, nil)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("sandbox: waiting for agent: %w", err)
+		}
+		if pid != cmd.Process.Pid {
+			continue
+		}
+		if ws.Signaled() {
+			return 128 + int(ws.Signal()), nil
+		}
+		return ws.ExitStatus(), nil
+	}
+}
+
+// setupMounts makes the mount namespace private, mounts a /proc for the new
+// pid namespace and a private /tmp, hides the host's control sockets, then
+// makes everything read-only except the worktree and the writable paths.
+func setupMounts(spec *Spec) error {
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("making mounts private: %w", err)
+	}
+	if err := unix.Mount("proc", "/proc", "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, ""); err != nil {
+		// Containers that mask parts of /proc forbid new proc mounts; the
+		// agent still works, it just sees host pids.
+		fmt.Fprintf(os.Stderr, "sandbox: warning: mounting /proc: %v\n", err)
+	}
+
+	// Bind each writable path onto itself so it is a mount of its own that
+	// can be made writable again after the recursive read-only pass.
+	writable := writablePaths(spec)
+	var carry []string
+	for _, p := range writable {
+		if underTmp(p) {
+			carry = append(carry, p)
+			continue
+		}
+		if err := unix.Mount(p, p, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+			return fmt.Errorf("binding %s: %w", p, err)
+		}
+	}
+	if err := mountTmpfs("/tmp", "mode=1777", carry); err != nil {
+		return err
+	}
+	writable = append(writable, "/tmp")
+
+	// Pathname sockets stay connectable through read-only mounts, and tmux,
+	// ptyd and the daemon all run commands outside the sandbox on request,
+	// so their socket directories are covered with empty tmpfs mounts. Only
+	// the proxy relay socket is carried over.
+	for dir, keep := range maskedDirs(spec) {
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		if err := mountTmpfs(dir, "mode=0755", keep); err != nil {
+			return err
+		}
+	}
+
+	if err := unix.MountSetattr(-1, "/", unix.AT_RECURSIVE, &unix.MountAttr{Attr_set: unix.MOUNT_ATTR_RDONLY}); err != nil {
+		return fmt.Errorf("making / read-only: %w", err)
+	}
+	for _, p := range writable {
+		if err := unix.MountSetattr(-1, p, unix.AT_RECURSIVE, &unix.MountAttr{Attr_clr: unix.MOUNT_ATTR_RDONLY}); err != nil {
+			return fmt.Errorf("making %s writable: %w", p, err)
+		}
+	}
+
+	// Protected paths stay read-only even inside a writable path.
+	for _, p := range spec.Protected {
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		if err := unix.Mount(p, p, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+			return fmt.Errorf("binding %s: %w", p, err)
+		}
+		if err := unix.MountSetattr(-1, p, unix.AT_RECURSIVE, &unix.MountAttr{Attr_set: unix.MOUNT_ATTR_RDONLY}); err != nil {
+			return fmt.Errorf("making %s read-only: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// maskedDirs returns the directories holding host control sockets, each
+// with the paths inside it to carry over.
+func maskedDirs(spec *Spec) map[string][]string {
+	dirs := map[string][]string{}
+	// The default tmux socket directory is under /tmp and already hidden.
+	if tmuxDir := tmuxSocketDir(); !underTmp(tmuxDir) {
+		dirs[tmuxDir] = nil
+	}
+	if spec.TownRoot != "" {
+		dirs[filepath.Join(spec.TownRoot, "daemon")] = nil
+		var keep []string
+		if spec.Network == NetworkProxy {
+			keep = append(keep, spec.relaySocket())
+		}
+		dirs[filepath.Join(spec.TownRoot, ".runtime")] = keep
+	}
+	return dirs
+}
+
+// mountTmpfs mounts a fresh tmpfs on dir with the given options, carrying
+// over the given paths inside it.
+func mountTmpfs(dir, opts string, carry []string) error {
+	type kept struct {
+		path string
+		f    *os.File
+		dir  bool
+	}
+	var keep []kept
+	defer func() {
+		for _, k := range keep {
+			_ = k.f.Close()
+		}
+	}()
+	for _, p := range carry {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		f, err := os.OpenFile(p, unix.O_PATH, 0)
+		if err != nil {
+			continue
+		}
+		keep = append(keep, kept{path: p, f: f, dir: info.IsDir()})
+	}
+
+	if err := unix.Mount("tmpfs", dir, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, opts); err != nil {
+		return fmt.Errorf("mounting %s: %w", dir, err)
+	}
+	for _, k := range keep {
+		if k.dir {
+			if err := os.MkdirAll(k.path, 0700); err != nil {
+				return fmt.Errorf("creating %s: %w", k.path, err)
+			}
+		} else {
+			if err := os.MkdirAll(filepath.Dir(k.path), 0700); err != nil {
+				return fmt.Errorf("creating %s: %w", filepath.Dir(k.path), err)
+			}
+			if err := os.WriteFile(k.path, nil, 0600); err != nil {
+				return fmt.Errorf("creating %s: %w", k.path, err)
+			}
+		}
+		src := fmt.Sprintf("/proc/self/fd/%d", k.f.Fd())
+		if err := unix.Mount(src, k.path, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+			return fmt.Errorf("binding %s: %w", k.path, err)
+		}
+	}
+	return nil
+}
+
+// underTmp reports whether p is inside /tmp.
+func underTmp(p string) bool {
+	return strings.HasPrefix(p, "/tmp/")
+}
+
+// tmuxSocketDir returns tmux's socket directory for this user.
+func tmuxSocketDir() string {
+	base := os.Getenv("TMUX_TMPDIR")
+	if base == "" {
+		base = "/tmp"
+	}
+	return filepath.Join(base, fmt.Sprintf("tmux-%d", os.Getuid()))
+}
+
+// writablePaths returns the existing, de-duplicated paths that stay
+// writable: the worktree, /dev/shm and the spec's writable paths.
+func writablePaths(spec *Spec) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, p := range append([]string{spec.WorkDir, "/dev/shm"}, spec.Writable...) {
+		p = filepath.Clean(p)
+		if seen[p] || p == "/" || p == "/tmp" {
+			continue
+		}
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		seen[p] = true
+		out = append(out, p)
+	}
+	return out
+}
+
+// gitDirs returns the git paths a worktree writes to and, for linked
+// worktrees, the shared repository paths that must stay read-only. A linked
+// worktree gets its own git dir and the shared object store, refs and
+// reflogs; the shared hooks and config stay read-only, since they run in
+// the refinery and in every other polecat.
+func gitDirs(workDir string) (writable, protected []string) {
+	out, err := exec.Command("git", "-C", workDir, "rev-parse", "--absolute-git-dir", "--git-common-dir").Output()
+	if err != nil {
+		return nil, nil
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 {
+		return nil, nil
+	}
+	gitDir, common := lines[0], lines[1]
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(workDir, common)
+	}
+	common = filepath.Clean(common)
+	if common == filepath.Clean(gitDir) {
+		// A plain clone: its git dir is the agent's own.
+		return []string{gitDir}, nil
+	}
+
+	writable = []string{gitDir}
+	for _, name := range []string{"objects", "refs", "logs"} {
+		p := filepath.Join(common, name)
+		// Reflogs are written on every ref update; make sure the directory
+		// exists before the shared repository turns read-only.
+		if name == "logs" {
+			_ = os.MkdirAll(p, 0755)
+		}
+		writable = append(writable, p)
+	}
+	protected = []string{filepath.Join(common, "hooks"), filepath.Join(common, "config")}
+	return writable, protected
+}
+
+// loopbackUp brings up lo in a fresh network namespace.
+func loopbackUp() error {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = unix.Close(fd) }()
+	ifr, err := unix.NewIfreq("lo")
+	if err != nil {
+		return err
+	}
+	if err := unix.IoctlIfreq(fd, unix.SIOCGIFFLAGS, ifr); err != nil {
+		return err
+	}
+	ifr.SetUint16(ifr.Uint16() | unix.IFF_UP | unix.IFF_RUNNING)
+	return unix.IoctlIfreq(fd, unix.SIOCSIFFLAGS, ifr)
+}
+
+// forwardSignals relays termination signals to p. SIGINT isn't relayed:
+// Ctrl-C in the pane already reaches the whole foreground process group,
+// and a second copy would look like a double Ctrl-C to the agent.
+func forwardSignals(p *os.Process) (stop func()) {
+	signal.Ignore(syscall.SIGINT)
+	ch := make(chan os.Signal, 4)
+	signal.Notify(ch, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	go func() {
+		for sig := range ch {
+			_ = p.Signal(sig)
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(ch)
+	}
+}
+
+// exitCode maps a finished process to a shell-style exit code.
+func exitCode(state *os.ProcessState, waitErr error) (int, error) {
+	if state == nil {
+		return 0, waitErr
+	}
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal()), nil
+	}
+	return state.ExitCode(), nil
+}
@@ -0,0 +1,247 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain lets the test binary stand in for gt: Run re-executes
+// os.Executable() as "gt sandbox init", which here lands back in TestMain.
+func TestMain(m *testing.M) {
+	if os.Getenv(specEnv) != "" {
+		code, err := Init()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(code)
+	}
+	os.Exit(m.Run())
+}
+
+// runOrSkip runs spec, skipping the test when the host doesn't allow
+// unprivileged namespaces.
+func runOrSkip(t *testing.T, spec *Spec) int {
+	t.Helper()
+	code, err := Run(spec, nil)
+	if err != nil && strings.Contains(err.Error(), "entering namespaces") {
+		t.Skipf("namespaces unavailable: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	return code
+}
+
+func TestRunFilesystemIsolation(t *testing.T) {
+	work := t.TempDir()
+	// Outside /tmp, so the agent's private /tmp doesn't hide it.
+	outside, err := os.MkdirTemp(".", "outside")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(outside)
+	if outside, err = filepath.Abs(outside); err != nil {
+		t.Fatal(err)
+	}
+	spec := &Spec{
+		Rig:     "testrig",
+		Polecat: "Toast",
+		WorkDir: work,
+		Command: fmt.Sprintf(`cat /proc/1/comm > init && touch %s/escaped; echo tmp > /tmp/scratch`, outside),
+	}
+	if code := runOrSkip(t, spec); code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+
+	// In the new pid namespace, PID 1 is the sandbox init (this binary).
+	self := filepath.Base(os.Args[0])
+	if len(self) > 15 {
+		self = self[:15] // comm is truncated to 15 bytes
+	}
+	if data, err := os.ReadFile(filepath.Join(work, "init")); err != nil {
+		t.Errorf("worktree not writable: %v", err)
+	} else if comm := strings.TrimSpace(string(data)); comm != self {
+		t.Errorf("pid 1 = %q, want the sandbox init %q", comm, self)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "escaped")); err == nil {
+		t.Error("agent wrote outside the worktree")
+	}
+	if _, err := os.Stat("/tmp/scratch"); err == nil {
+		t.Error("agent's /tmp is not private")
+	}
+}
+
+func TestRunExitCode(t *testing.T) {
+	spec := &Spec{Rig: "testrig", Polecat: "Toast", WorkDir: t.TempDir(), Command: "exit 3"}
+	if code := runOrSkip(t, spec); code != 3 {
+		t.Errorf("exit code = %d, want 3", code)
+	}
+}
+
+func TestRunIsolatedNetwork(t *testing.T) {
+	work := t.TempDir()
+	spec := &Spec{
+		Rig:     "testrig",
+		Polecat: "Toast",
+		WorkDir: work,
+		Network: NetworkNone,
+		Command: "tail -n +3 /proc/net/dev | cut -d: -f1 > ifaces",
+	}
+	if code := runOrSkip(t, spec); code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	data, err := os.ReadFile(filepath.Join(work, "ifaces"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Fields(string(data)); len(got) != 1 || got[0] != "lo" {
+		t.Errorf("interfaces = %v, want only lo", got)
+	}
+}
+
+// outsideTmp returns a temporary directory outside /tmp, which the agent's
+// private /tmp would hide anyway.
+func outsideTmp(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp(".", "outside")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	if dir, err = filepath.Abs(dir); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// listenUnix creates a listening socket at path.
+func listenUnix(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+}
+
+func TestRunHidesControlSockets(t *testing.T) {
+	town := outsideTmp(t)
+	tmuxTmp := outsideTmp(t)
+	t.Setenv("TMUX_TMPDIR", tmuxTmp)
+	tmuxSock := filepath.Join(tmuxTmp, fmt.Sprintf("tmux-%d", os.Getuid()), "default")
+	listenUnix(t, tmuxSock)
+	ptySock := filepath.Join(town, "daemon", "pty.sock")
+	listenUnix(t, ptySock)
+	if err := os.WriteFile(filepath.Join(town, "daemon", "dolt-state.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	work := t.TempDir()
+	spec := &Spec{
+		Rig:      "testrig",
+		Polecat:  "Toast",
+		TownRoot: town,
+		WorkDir:  work,
+		Command: fmt.Sprintf(`for p in %s %s %s/.runtime/sandbox; do test -e "$p" && echo "$p"; done > visible; true`,
+			tmuxSock, ptySock, town),
+	}
+	if code := runOrSkip(t, spec); code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	data, err := os.ReadFile(filepath.Join(work, "visible"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if visible := strings.TrimSpace(string(data)); visible != "" {
+		t.Errorf("control sockets visible in the sandbox:\n%s", visible)
+	}
+}
+
+func TestRunProxyRelaySurvivesRuntimeMask(t *testing.T) {
+	town := outsideTmp(t)
+	work := t.TempDir()
+	spec := &Spec{
+		Rig:       "testrig",
+		Polecat:   "Toast",
+		TownRoot:  town,
+		WorkDir:   work,
+		Network:   NetworkProxy,
+		ProxyAddr: "127.0.0.1:1",
+		Command:   fmt.Sprintf(`ls -A %s/.runtime/sandbox > relay; ls -A %s/.runtime > runtime`, town, town),
+	}
+	if err := os.MkdirAll(filepath.Join(town, ".runtime"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(town, ".runtime", "secrets.json"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := runOrSkip(t, spec); code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	relay, _ := os.ReadFile(filepath.Join(work, "relay"))
+	if got := strings.TrimSpace(string(relay)); got != "testrig-Toast.sock" {
+		t.Errorf("relay socket dir = %q, want only the relay socket", got)
+	}
+	runtime, _ := os.ReadFile(filepath.Join(work, "runtime"))
+	if got := strings.TrimSpace(string(runtime)); got != "sandbox" {
+		t.Errorf(".runtime = %q, want only the relay socket dir", got)
+	}
+}
+
+func TestRunSharedRepoHooksAndConfigReadOnly(t *testing.T) {
+	root := outsideTmp(t)
+	repo := filepath.Join(root, "repo")
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git(repo, "init", "-q", "-b", "main")
+	git(repo, "commit", "-q", "--allow-empty", "-m", "init")
+	work := filepath.Join(root, "polecat")
+	git(repo, "worktree", "add", "-q", "-b", "polecat/toast", work)
+	common := filepath.Join(repo, ".git")
+
+	spec := &Spec{
+		Rig:     "testrig",
+		Polecat: "Toast",
+		WorkDir: work,
+		Command: fmt.Sprintf(`echo evil > %[1]s/hooks/post-commit
+git config core.hooksPath /evil
+git config core.fsmonitor /evil
+echo change > file && git add file && git -c user.name=t -c user.email=t@t commit -q -m change`, common),
+	}
+	if code := runOrSkip(t, spec); code != 0 {
+		t.Fatalf("exit code = %d, want 0 (commit in the worktree must work)", code)
+	}
+	if _, err := os.Stat(filepath.Join(common, "hooks", "post-commit")); err == nil {
+		t.Error("agent planted a hook in the shared repository")
+	}
+	cfg, err := os.ReadFile(filepath.Join(common, "config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(cfg), "/evil") {
+		t.Errorf("agent changed the shared config:\n%s", cfg)
+	}
+	out, err := exec.Command("git", "-C", repo, "log", "-1", "--format=%s", "polecat/toast").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "change" {
+		t.Errorf("polecat branch tip = %q, %v; want the agent's commit", out, err)
+	}
+}
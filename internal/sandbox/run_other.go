@@ -0,0 +1,18 @@
+//go:build !linux
+
+package sandbox
+
+import "errors"
+
+// errUnsupported is returned when sandboxing isn't available on this OS.
+var errUnsupported = errors.New("polecat sandboxing is only supported on Linux")
+
+// Run is only supported on Linux.
+func Run(spec *Spec, onViolation func(Violation)) (int, error) {
+	return 0, errUnsupported
+}
+
+// Init is only supported on Linux.
+func Init() (int, error) {
+	return 0, errUnsupported
+}
@@ -0,0 +1,266 @@
+// Package sandbox runs polecat agents inside Linux namespaces with cgroup v2
+// resource limits.
+//
+// A sandboxed polecat's startup command is wrapped in "gt sandbox exec",
+// which re-executes gt as "gt sandbox init" in new user, mount and pid (and
+// optionally network) namespaces. When limits are set, init starts in a new
+// leaf cgroup under the configured cgroup parent; exec itself stays where
+// it was started. The init stage makes the filesystem read-only except for
+// the worktree and a few configured paths, then runs the agent as its
+// child. The outer process watches the cgroup's event counters and reports
+// limit hits as violations.
+package sandbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Network modes for a sandbox.
+const (
+	// NetworkHost shares the host network (the default).
+	NetworkHost = "host"
+
+	// NetworkNone gives the agent a private network namespace with only
+	// loopback.
+	NetworkNone = "none"
+
+	// NetworkProxy is NetworkNone plus a relay from loopback to the gt
+	// proxy server, so gt and bd calls through gt-proxy-client still work.
+	NetworkProxy = "proxy"
+)
+
+// specEnv carries the Spec from "gt sandbox exec" to "gt sandbox init".
+const specEnv = "GT_SANDBOX_SPEC"
+
+// Limits are cgroup v2 resource limits. Zero means unlimited.
+type Limits struct {
+	CPUs     float64 `json:"cpus,omitempty"`      // cpu.max, in cores
+	MemoryMB int     `json:"memory_mb,omitempty"` // memory.max
+	Pids     int     `json:"pids,omitempty"`      // pids.max
+}
+
+// IsZero reports whether no limit is set.
+func (l Limits) IsZero() bool {
+	return l.CPUs <= 0 && l.MemoryMB <= 0 && l.Pids <= 0
+}
+
+// Spec describes one sandboxed agent.
+type Spec struct {
+	Rig     string `json:"rig"`
+	Polecat string `json:"polecat"`
+
+	// TownRoot holds the proxy relay socket (under .runtime/sandbox).
+	TownRoot string `json:"town_root"`
+
+	// WorkDir is the polecat worktree: the agent's cwd, always writable.
+	WorkDir string `json:"work_dir"`
+
+	// Writable lists extra paths that stay writable. Missing paths are
+	// skipped.
+	Writable []string `json:"writable,omitempty"`
+
+	// Protected lists paths that stay read-only even inside a writable
+	// path, such as the shared repository's hooks and config.
+	Protected []string `json:"protected,omitempty"`
+
+	Network   string `json:"network,omitempty"`
+	ProxyAddr string `json:"proxy_addr,omitempty"` // host:port, for NetworkProxy
+
+	Limits Limits `json:"limits,omitempty"`
+
+	// CgroupParent is the delegated cgroup v2 directory sandbox cgroups are
+	// created under. Required when Limits are set.
+	CgroupParent string `json:"cgroup_parent,omitempty"`
+
+	// Command is the agent command, run with sh -c.
+	Command string `json:"command"`
+}
+
+// Violation is a sandbox limit the agent ran into.
+type Violation struct {
+	Kind   string // "memory" or "pids"
+	Detail string
+	Count  int // events since the last report
+}
+
+// Violation kinds.
+const (
+	ViolationMemory = "memory"
+	ViolationPids   = "pids"
+)
+
+// Validate checks that a spec can be run.
+func (s *Spec) Validate() error {
+	if s.WorkDir == "" {
+		return fmt.Errorf("sandbox: work dir is required")
+	}
+	if s.Command == "" {
+		return fmt.Errorf("sandbox: command is required")
+	}
+	switch s.Network {
+	case "", NetworkHost, NetworkNone:
+	case NetworkProxy:
+		if _, err := proxyPort(s.ProxyAddr); err != nil {
+			return fmt.Errorf("sandbox: network %q needs proxy_addr: %w", NetworkProxy, err)
+		}
+	default:
+		return fmt.Errorf("sandbox: unknown network mode %q", s.Network)
+	}
+	if !s.Limits.IsZero() && s.CgroupParent == "" {
+		return fmt.Errorf("sandbox: resource limits need a cgroup parent (sandbox.cgroup_parent)")
+	}
+	return nil
+}
+
+// isolatedNetwork reports whether the agent gets its own network namespace.
+func (s *Spec) isolatedNetwork() bool {
+	return s.Network == NetworkNone || s.Network == NetworkProxy
+}
+
+// relaySocket is where the outer process listens for proxy connections
+// from inside the sandbox. Pathname sockets cross network namespaces.
+func (s *Spec) relaySocket() string {
+	return filepath.Join(s.TownRoot, ".runtime", "sandbox", s.Rig+"-"+s.Polecat+".sock")
+}
+
+// SpecFromConfig builds the spec for a rig's polecat from its sandbox
+// settings. extraWritable (e.g. the runtime config dir) is added to the
+// configured writable paths; "~/" prefixes expand to the home directory.
+func SpecFromConfig(cfg *config.SandboxConfig, townRoot, rigName, polecat, workDir string, extraWritable ...string) *Spec {
+	spec := &Spec{
+		Rig:          rigName,
+		Polecat:      polecat,
+		TownRoot:     townRoot,
+		WorkDir:      workDir,
+		Network:      cfg.Network,
+		ProxyAddr:    cfg.ProxyAddr,
+		CgroupParent: cfg.CgroupParent,
+		Limits:       Limits{CPUs: cfg.CPUs, MemoryMB: cfg.MemoryMB, Pids: cfg.Pids},
+	}
+	home, _ := os.UserHomeDir()
+	for _, p := range append(append([]string(nil), cfg.WritablePaths...), extraWritable...) {
+		if p == "" {
+			continue
+		}
+		if strings.HasPrefix(p, "~/") && home != "" {
+			p = filepath.Join(home, p[2:])
+		}
+		spec.Writable = append(spec.Writable, p)
+	}
+	return spec
+}
+
+// WrapCommand returns the shell command that runs spec.Command (or command,
+// when given) inside the sandbox.
+func WrapCommand(spec *Spec, command string) string {
+	if command == "" {
+		command = spec.Command
+	}
+	args := []string{"exec", "gt", "sandbox", "exec",
+		"--rig", spec.Rig,
+		"--polecat", spec.Polecat,
+		"--town", spec.TownRoot,
+		"--workdir", spec.WorkDir,
+	}
+	for _, p := range spec.Writable {
+		args = append(args, "--writable", p)
+	}
+	if spec.Network != "" && spec.Network != NetworkHost {
+		args = append(args, "--network", spec.Network)
+	}
+	if spec.ProxyAddr != "" {
+		args = append(args, "--proxy", spec.ProxyAddr)
+	}
+	if spec.Limits.CPUs > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(spec.Limits.CPUs, 'f', -1, 64))
+	}
+	if spec.Limits.MemoryMB > 0 {
+		args = append(args, "--memory-mb", strconv.Itoa(spec.Limits.MemoryMB))
+	}
+	if spec.Limits.Pids > 0 {
+		args = append(args, "--pids", strconv.Itoa(spec.Limits.Pids))
+	}
+	if spec.CgroupParent != "" {
+		args = append(args, "--cgroup-parent", spec.CgroupParent)
+	}
+	args = append(args, "--", command)
+
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = config.ShellQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func encodeSpec(s *Spec) (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func decodeSpec(data string) (*Spec, error) {
+	if data == "" {
+		return nil, fmt.Errorf("sandbox: %s not set (gt sandbox init is started by gt sandbox exec)", specEnv)
+	}
+	var s Spec
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return nil, fmt.Errorf("sandbox: decoding spec: %w", err)
+	}
+	return &s, nil
+}
+
+// proxyPort returns the port of a host:port proxy address.
+func proxyPort(addr string) (int, error) {
+	_, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return 0, err
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil || port <= 0 || port > 65535 {
+		return 0, fmt.Errorf("invalid port in %q", addr)
+	}
+	return port, nil
+}
+
+// parseEventCounter returns the value of key in the content of a cgroup
+// events file ("key value" per line), or zero.
+func parseEventCounter(content, key string) int {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == key {
+			n, _ := strconv.Atoi(fields[1])
+			return n
+		}
+	}
+	return 0
+}
+
+// diffViolations returns a violation for each counter that grew since prev.
+func diffViolations(prev, cur map[string]int, l Limits) []Violation {
+	var out []Violation
+	if n := cur[ViolationMemory] - prev[ViolationMemory]; n > 0 {
+		out = append(out, Violation{
+			Kind:   ViolationMemory,
+			Detail: fmt.Sprintf("OOM killer ran at the %d MiB memory limit", l.MemoryMB),
+			Count:  n,
+		})
+	}
+	if n := cur[ViolationPids] - prev[ViolationPids]; n > 0 {
+		out = append(out, Violation{
+			Kind:   ViolationPids,
+			Detail: fmt.Sprintf("fork refused at the %d process limit", l.Pids),
+			Count:  n,
+		})
+	}
+	return out
+}
@@ -0,0 +1,188 @@
+package sandbox
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestWrapCommand(t *testing.T) {
+	spec := &Spec{
+		Rig:       "gastown",
+		Polecat:   "Toast",
+		TownRoot:  "/home/u/gt",
+		WorkDir:   "/home/u/gt/gastown/polecats/Toast/gastown",
+		Writable:  []string{"/home/u/.claude"},
+		Network:   NetworkProxy,
+		ProxyAddr: "127.0.0.1:9876",
+		Limits:    Limits{CPUs: 1.5, MemoryMB: 2048, Pids: 256},
+	}
+	got := WrapCommand(spec, "GT_RIG=gastown exec claude 'do the thing'")
+
+	for _, want := range []string{
+		"exec gt sandbox exec --rig gastown --polecat Toast --town /home/u/gt",
+		"--workdir /home/u/gt/gastown/polecats/Toast/gastown",
+		"--writable /home/u/.claude",
+		"--network proxy --proxy 127.0.0.1:9876",
+		"--cpus 1.5 --memory-mb 2048 --pids 256",
+		`-- 'GT_RIG=gastown exec claude '\''do the thing'\'''`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WrapCommand() = %s\nmissing %q", got, want)
+		}
+	}
+	if strings.Contains(got, "--cgroup-parent") {
+		t.Errorf("WrapCommand() included unset --cgroup-parent: %s", got)
+	}
+}
+
+func TestWrapCommandHostNetwork(t *testing.T) {
+	got := WrapCommand(&Spec{Rig: "r", Polecat: "p", WorkDir: "/w", Network: NetworkHost}, "claude")
+	if strings.Contains(got, "--network") || strings.Contains(got, "--cpus") {
+		t.Errorf("WrapCommand() = %s, want no network or limit flags", got)
+	}
+}
+
+func TestSpecFromConfig(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	cfg := &config.SandboxConfig{
+		Enabled:       true,
+		Network:       NetworkNone,
+		WritablePaths: []string{"~/.claude", "/srv/cache", ""},
+		MemoryMB:      1024,
+	}
+	spec := SpecFromConfig(cfg, "/gt", "gastown", "Toast", "/gt/gastown/polecats/Toast", "/gt/.accounts/a1", "")
+
+	want := []string{filepath.Join(home, ".claude"), "/srv/cache", "/gt/.accounts/a1"}
+	if strings.Join(spec.Writable, ",") != strings.Join(want, ",") {
+		t.Errorf("Writable = %v, want %v", spec.Writable, want)
+	}
+	if spec.Network != NetworkNone || spec.Limits.MemoryMB != 1024 {
+		t.Errorf("spec = %+v", spec)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    Spec
+		wantErr bool
+	}{
+		{"host", Spec{WorkDir: "/w", Command: "claude"}, false},
+		{"none", Spec{WorkDir: "/w", Command: "claude", Network: NetworkNone}, false},
+		{"proxy", Spec{WorkDir: "/w", Command: "claude", Network: NetworkProxy, ProxyAddr: "10.0.0.1:9876"}, false},
+		{"proxy without addr", Spec{WorkDir: "/w", Command: "claude", Network: NetworkProxy}, true},
+		{"proxy bad port", Spec{WorkDir: "/w", Command: "claude", Network: NetworkProxy, ProxyAddr: "10.0.0.1:x"}, true},
+		{"unknown network", Spec{WorkDir: "/w", Command: "claude", Network: "bridge"}, true},
+		{"no workdir", Spec{Command: "claude"}, true},
+		{"no command", Spec{WorkDir: "/w"}, true},
+		{"limits", Spec{WorkDir: "/w", Command: "claude", Limits: Limits{Pids: 10}, CgroupParent: "/sys/fs/cgroup/gt"}, false},
+		{"limits without cgroup parent", Spec{WorkDir: "/w", Command: "claude", Limits: Limits{Pids: 10}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.spec.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSpecRoundTrip(t *testing.T) {
+	in := &Spec{Rig: "r", Polecat: "p", WorkDir: "/w", Command: "claude", Limits: Limits{Pids: 10}}
+	data, err := encodeSpec(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := decodeSpec(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Rig != in.Rig || out.Command != in.Command || out.Limits != in.Limits {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+	if _, err := decodeSpec(""); err == nil {
+		t.Error("decodeSpec(\"\") succeeded")
+	}
+}
+
+func TestDiffViolations(t *testing.T) {
+	events := "low 0\nhigh 0\nmax 12\noom 2\noom_kill 2\n"
+	if got := parseEventCounter(events, "oom_kill"); got != 2 {
+		t.Errorf("parseEventCounter(oom_kill) = %d, want 2", got)
+	}
+	if got := parseEventCounter(events, "missing"); got != 0 {
+		t.Errorf("parseEventCounter(missing) = %d, want 0", got)
+	}
+
+	limits := Limits{MemoryMB: 512, Pids: 64}
+	prev := map[string]int{ViolationMemory: 1, ViolationPids: 3}
+	cur := map[string]int{ViolationMemory: 2, ViolationPids: 3}
+	got := diffViolations(prev, cur, limits)
+	if len(got) != 1 || got[0].Kind != ViolationMemory || got[0].Count != 1 {
+		t.Fatalf("diffViolations() = %+v, want one memory violation", got)
+	}
+	if !strings.Contains(got[0].Detail, "512 MiB") {
+		t.Errorf("Detail = %q", got[0].Detail)
+	}
+	if got := diffViolations(cur, cur, limits); len(got) != 0 {
+		t.Errorf("diffViolations() with no change = %+v", got)
+	}
+}
+
+func TestRelay(t *testing.T) {
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer upstream.Close()
+	go func() {
+		for {
+			c, err := upstream.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				_, _ = io.Copy(c, c)
+			}()
+		}
+	}()
+
+	// Unix socket paths are length-limited; t.TempDir() can be too long.
+	dir, err := os.MkdirTemp("", "sandbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sock := filepath.Join(dir, "relay.sock")
+	ln, err := listenRelay(sock, upstream.Addr().String())
+	if err != nil {
+		t.Fatalf("listenRelay: %v", err)
+	}
+	defer ln.Close()
+
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "ping" {
+		t.Errorf("relayed %q, want ping", buf)
+	}
+}
@@ -0,0 +1,531 @@
+// Package seance keeps a searchable archive of past agent sessions.
+//
+// Transcripts read through agentlog adapters are stored as one JSONL file of
+// turns per session, with an inverted term index and the bead IDs each
+// session mentioned. Searching returns matching sessions with excerpts, so
+// an agent can ask "why did we pin grpc" across every predecessor without
+// resuming each one.
+package seance
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limits on what a session contributes to the archive.
+const (
+	// MaxTurnChars caps each stored turn; tool inputs and outputs can be huge.
+	MaxTurnChars = 4000
+
+	// excerptChars is the approximate width of a search excerpt.
+	excerptChars = 240
+
+	// maxExcerpts is how many excerpts a result carries.
+	maxExcerpts = 3
+)
+
+const indexVersion = 1
+
+// Session describes one archived session.
+type Session struct {
+	ID        string    `json:"id"`              // agent-native session ID
+	Actor     string    `json:"actor,omitempty"` // e.g. gastown/crew/joe
+	Topic     string    `json:"topic,omitempty"`
+	WorkDir   string    `json:"cwd,omitempty"`
+	Agent     string    `json:"agent,omitempty"` // agentlog adapter type
+	Started   time.Time `json:"started"`
+	Ended     time.Time `json:"ended"`
+	Turns     int       `json:"turns"`
+	Beads     []string  `json:"beads,omitempty"` // bead IDs mentioned, sorted
+	Source    string    `json:"source,omitempty"`
+	SourceMod time.Time `json:"source_mod"` // transcript mtime when indexed
+}
+
+// Turn is one stored conversation block.
+type Turn struct {
+	Time time.Time `json:"ts"`
+	Role string    `json:"role"`
+	Type string    `json:"type"` // text, thinking, tool_use, tool_result
+	Text string    `json:"text"`
+}
+
+// Archive is an on-disk session archive rooted at a directory.
+type Archive struct {
+	dir   string
+	index archiveIndex
+}
+
+type archiveIndex struct {
+	Version  int                 `json:"version"`
+	Sessions map[string]*Session `json:"sessions"`
+	Terms    map[string][]string `json:"terms"` // term → session IDs, sorted
+}
+
+// Open loads the archive in dir. A missing archive opens empty.
+func Open(dir string) (*Archive, error) {
+	a := &Archive{dir: dir, index: archiveIndex{
+		Version:  indexVersion,
+		Sessions: map[string]*Session{},
+		Terms:    map[string][]string{},
+	}}
+	data, err := os.ReadFile(a.indexPath())
+	if os.IsNotExist(err) {
+		return a, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading archive index: %w", err)
+	}
+	var idx archiveIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parsing archive index: %w", err)
+	}
+	if idx.Version == indexVersion && idx.Sessions != nil && idx.Terms != nil {
+		a.index = idx
+	}
+	return a, nil
+}
+
+func (a *Archive) indexPath() string { return filepath.Join(a.dir, "index.json") }
+
+func (a *Archive) turnsPath(id string) string {
+	return filepath.Join(a.dir, "sessions", id+".jsonl")
+}
+
+// Len returns the number of archived sessions.
+func (a *Archive) Len() int { return len(a.index.Sessions) }
+
+// Session returns the archived session id, or nil.
+func (a *Archive) Session(id string) *Session { return a.index.Sessions[id] }
+
+// Fresh reports whether id is archived from a transcript no older than mod,
+// so re-indexing it can be skipped.
+func (a *Archive) Fresh(id string, mod time.Time) bool {
+	s := a.index.Sessions[id]
+	return s != nil && !s.SourceMod.Before(mod)
+}
+
+// Add stores a session and its turns, replacing any earlier copy. Turns are
+// capped at MaxTurnChars. Started, Ended, Turns and Beads are derived from
+// the turns when unset; beadPattern finds bead IDs (nil uses BeadPattern(nil)).
+// Call Save to persist the index.
+func (a *Archive) Add(s Session, turns []Turn, beadPattern *regexp.Regexp) error {
+	if s.ID == "" || strings.ContainsAny(s.ID, `/\`) {
+		return fmt.Errorf("invalid session id %q", s.ID)
+	}
+	if beadPattern == nil {
+		beadPattern = BeadPattern(nil)
+	}
+
+	a.remove(s.ID)
+
+	if err := os.MkdirAll(filepath.Dir(a.turnsPath(s.ID)), 0755); err != nil {
+		return fmt.Errorf("creating archive: %w", err)
+	}
+	f, err := os.Create(a.turnsPath(s.ID))
+	if err != nil {
+		return fmt.Errorf("writing session %s: %w", s.ID, err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+
+	terms := map[string]bool{}
+	beads := map[string]bool{}
+	for _, b := range s.Beads {
+		beads[b] = true
+	}
+	for _, t := range []string{s.Actor, s.Topic} {
+		for _, term := range Terms(t) {
+			terms[term] = true
+		}
+	}
+	s.Turns = 0
+	for _, t := range turns {
+		t.Text = strings.TrimSpace(t.Text)
+		if t.Text == "" {
+			continue
+		}
+		if len(t.Text) > MaxTurnChars {
+			cut := MaxTurnChars
+			for cut > 0 && !utf8.RuneStart(t.Text[cut]) {
+				cut--
+			}
+			t.Text = t.Text[:cut] + "…"
+		}
+		if err := enc.Encode(t); err != nil {
+			f.Close()
+			return fmt.Errorf("writing session %s: %w", s.ID, err)
+		}
+		s.Turns++
+		if !t.Time.IsZero() {
+			if s.Started.IsZero() || t.Time.Before(s.Started) {
+				s.Started = t.Time
+			}
+			if t.Time.After(s.Ended) {
+				s.Ended = t.Time
+			}
+		}
+		for _, term := range Terms(t.Text) {
+			terms[term] = true
+		}
+		for _, id := range beadPattern.FindAllString(t.Text, -1) {
+			beads[id] = true
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("writing session %s: %w", s.ID, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing session %s: %w", s.ID, err)
+	}
+
+	s.Beads = sortedKeys(beads)
+	a.index.Sessions[s.ID] = &s
+	for term := range terms {
+		a.index.Terms[term] = insertSorted(a.index.Terms[term], s.ID)
+	}
+	return nil
+}
+
+// remove drops a session's postings and turns.
+func (a *Archive) remove(id string) {
+	if _, ok := a.index.Sessions[id]; !ok {
+		return
+	}
+	delete(a.index.Sessions, id)
+	for term, ids := range a.index.Terms {
+		if i := sort.SearchStrings(ids, id); i < len(ids) && ids[i] == id {
+			ids = append(ids[:i], ids[i+1:]...)
+			if len(ids) == 0 {
+				delete(a.index.Terms, term)
+			} else {
+				a.index.Terms[term] = ids
+			}
+		}
+	}
+	_ = os.Remove(a.turnsPath(id))
+}
+
+// Save writes the index atomically.
+func (a *Archive) Save() error {
+	if err := os.MkdirAll(a.dir, 0755); err != nil {
+		return fmt.Errorf("creating archive: %w", err)
+	}
+	data, err := json.Marshal(a.index)
+	if err != nil {
+		return fmt.Errorf("encoding archive index: %w", err)
+	}
+	tmp := a.indexPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing archive index: %w", err)
+	}
+	return os.Rename(tmp, a.indexPath())
+}
+
+// Turns reads a session's stored turns.
+func (a *Archive) Turns(id string) ([]Turn, error) {
+	f, err := os.Open(a.turnsPath(id))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var turns []Turn
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for dec.More() {
+		var t Turn
+		if err := dec.Decode(&t); err != nil {
+			return turns, fmt.Errorf("reading session %s: %w", id, err)
+		}
+		turns = append(turns, t)
+	}
+	return turns, nil
+}
+
+// Query selects sessions from the archive.
+type Query struct {
+	Text  string    // every term must appear in the session; prefixes match
+	Bead  string    // only sessions that mention this bead
+	Actor string    // substring of the session's actor
+	Since time.Time // only sessions active at or after this time
+	Limit int       // maximum results; zero means no limit
+}
+
+// Excerpt is a snippet of a matching turn.
+type Excerpt struct {
+	Time time.Time `json:"ts"`
+	Role string    `json:"role"`
+	Text string    `json:"text"`
+}
+
+// Result is one matching session.
+type Result struct {
+	Session  *Session  `json:"session"`
+	Score    int       `json:"score"` // matching turns, weighted by terms matched
+	Excerpts []Excerpt `json:"excerpts,omitempty"`
+}
+
+// Search returns sessions matching q, best first, then most recent. With no
+// text, matching sessions are returned newest first with no excerpts, except
+// that a bead query excerpts the turns mentioning the bead.
+func (a *Archive) Search(q Query) ([]Result, error) {
+	terms := Terms(q.Text)
+	var cands []*Session
+	if len(terms) == 0 {
+		for _, s := range a.index.Sessions {
+			cands = append(cands, s)
+		}
+	} else {
+		ids := a.postings(terms[0])
+		for _, t := range terms[1:] {
+			ids = intersect(ids, a.postings(t))
+		}
+		for _, id := range ids {
+			if s := a.index.Sessions[id]; s != nil {
+				cands = append(cands, s)
+			}
+		}
+	}
+
+	var results []Result
+	for _, s := range cands {
+		if q.Bead != "" && !containsString(s.Beads, q.Bead) {
+			continue
+		}
+		if q.Actor != "" && !strings.Contains(strings.ToLower(s.Actor), strings.ToLower(q.Actor)) {
+			continue
+		}
+		if !q.Since.IsZero() && s.Ended.Before(q.Since) && s.Started.Before(q.Since) {
+			continue
+		}
+		r := Result{Session: s}
+		match := terms
+		if len(match) == 0 && q.Bead != "" {
+			match = []string{strings.ToLower(q.Bead)}
+		}
+		if len(match) > 0 {
+			turns, err := a.Turns(s.ID)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			r.Score, r.Excerpts = scoreTurns(turns, match)
+		}
+		results = append(results, r)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if !results[i].Session.Ended.Equal(results[j].Session.Ended) {
+			return results[i].Session.Ended.After(results[j].Session.Ended)
+		}
+		return results[i].Session.ID < results[j].Session.ID
+	})
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results, nil
+}
+
+// postings returns the sorted IDs of sessions containing term or a word
+// it prefixes ("pin" finds "pinned").
+func (a *Archive) postings(term string) []string {
+	set := map[string]bool{}
+	for t, ids := range a.index.Terms {
+		if strings.HasPrefix(t, term) {
+			for _, id := range ids {
+				set[id] = true
+			}
+		}
+	}
+	return sortedKeys(set)
+}
+
+// scoreTurns scores turns against terms: each turn scores the number of
+// terms it contains, squared, so turns matching the whole question outrank
+// scattered single-term mentions. The best turns become excerpts, in
+// conversation order.
+func scoreTurns(turns []Turn, terms []string) (int, []Excerpt) {
+	type hit struct {
+		idx, score, pos int
+	}
+	var hits []hit
+	total := 0
+	for i, t := range turns {
+		lower := strings.ToLower(t.Text)
+		n, first := 0, -1
+		for _, term := range terms {
+			if p := wordIndex(lower, term); p >= 0 {
+				n++
+				if first < 0 || p < first {
+					first = p
+				}
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		total += n * n
+		hits = append(hits, hit{i, n, first})
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	if len(hits) > maxExcerpts {
+		hits = hits[:maxExcerpts]
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].idx < hits[j].idx })
+
+	excerpts := make([]Excerpt, 0, len(hits))
+	for _, h := range hits {
+		t := turns[h.idx]
+		excerpts = append(excerpts, Excerpt{Time: t.Time, Role: t.Role, Text: excerpt(t.Text, h.pos)})
+	}
+	return total, excerpts
+}
+
+// wordIndex returns the byte offset of the first word in s starting with
+// term, or -1.
+func wordIndex(s, term string) int {
+	for off := 0; ; {
+		i := strings.Index(s[off:], term)
+		if i < 0 {
+			return -1
+		}
+		i += off
+		if i == 0 || !isWordRune(rune(s[i-1])) {
+			return i
+		}
+		off = i + len(term)
+	}
+}
+
+// excerpt cuts about excerptChars of text around pos, on word boundaries,
+// with whitespace collapsed.
+func excerpt(text string, pos int) string {
+	start := pos - excerptChars/3
+	if start < 0 {
+		start = 0
+	}
+	end := start + excerptChars
+	if end > len(text) {
+		end = len(text)
+	}
+	for start > 0 && isWordRune(rune(text[start-1])) && start < pos {
+		start++
+	}
+	for end < len(text) && isWordRune(rune(text[end])) && end-start < excerptChars+20 {
+		end++
+	}
+	out := strings.Join(strings.Fields(strings.ToValidUTF8(text[start:end], "")), " ")
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(text) {
+		out += "…"
+	}
+	return out
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r >= 0x80
+}
+
+// BeadPattern returns a regexp matching bead IDs with the given prefixes
+// (e.g. "gt-", "hq-"). With no prefixes it matches any short lowercase
+// prefix followed by an ID containing a digit, which catches most beads at
+// the cost of the odd false positive.
+func BeadPattern(prefixes []string) *regexp.Regexp {
+	id := `[a-z0-9]*[0-9][a-z0-9]*(?:\.[0-9]+)*`
+	if len(prefixes) == 0 {
+		return regexp.MustCompile(`\b[a-z]{2,5}-` + id + `\b`)
+	}
+	quoted := make([]string, 0, len(prefixes))
+	for _, p := range prefixes {
+		if p = strings.TrimSuffix(p, "-"); p != "" {
+			quoted = append(quoted, regexp.QuoteMeta(p))
+		}
+	}
+	return regexp.MustCompile(`\b(?:` + strings.Join(quoted, "|") + `)-` + id + `\b`)
+}
+
+// stopwords are dropped from queries and the index; questions like "why did
+// we pin grpc" should key on "pin" and "grpc".
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "did": true, "do": true, "does": true,
+	"for": true, "from": true, "had": true, "has": true, "have": true, "how": true,
+	"i": true, "in": true, "is": true, "it": true, "its": true, "of": true,
+	"on": true, "or": true, "our": true, "so": true, "that": true, "the": true,
+	"this": true, "to": true, "was": true, "we": true, "were": true, "what": true,
+	"when": true, "where": true, "which": true, "who": true, "why": true,
+	"with": true, "you": true,
+}
+
+// Terms splits text into lowercase index terms, dropping stopwords, single
+// characters, very long tokens and duplicates.
+func Terms(text string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, f := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !isWordRune(r) }) {
+		if len(f) < 2 || len(f) > 40 || stopwords[f] || seen[f] {
+			continue
+		}
+		seen[f] = true
+		out = append(out, f)
+	}
+	return out
+}
+
+func insertSorted(ids []string, id string) []string {
+	i := sort.SearchStrings(ids, id)
+	if i < len(ids) && ids[i] == id {
+		return ids
+	}
+	ids = append(ids, "")
+	copy(ids[i+1:], ids[i:])
+	ids[i] = id
+	return ids
+}
+
+func intersect(a, b []string) []string {
+	var out []string
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, a[i])
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return out
+}
+
+func sortedKeys(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package seance
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func testTurns(base time.Time, texts ...string) []Turn {
+	turns := make([]Turn, len(texts))
+	for i, text := range texts {
+		role := "assistant"
+		if i%2 == 0 {
+			role = "user"
+		}
+		turns[i] = Turn{Time: base.Add(time.Duration(i) * time.Minute), Role: role, Type: "text", Text: text}
+	}
+	return turns
+}
+
+func TestArchive_AddSearch(t *testing.T) {
+	dir := t.TempDir()
+	a, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	if err := a.Add(Session{ID: "s1", Actor: "gastown/crew/joe"}, testTurns(day1,
+		"Work on gt-abc12: the proxy build fails.",
+		"Pinned grpc to v1.62 in go.mod because v1.63 breaks streaming in the proxy.",
+		"Done, pushed.",
+	), BeadPattern([]string{"gt-"})); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Add(Session{ID: "s2", Actor: "gastown/polecats/Toast"}, testTurns(day2,
+		"Upgrade grpc across the repo.",
+		"Bumped dependencies; tests pass.",
+	), BeadPattern([]string{"gt-"})); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen from disk.
+	a, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", a.Len())
+	}
+	s1 := a.Session("s1")
+	if !s1.Started.Equal(day1) || !s1.Ended.Equal(day1.Add(2*time.Minute)) || s1.Turns != 3 {
+		t.Errorf("derived session fields wrong: %+v", s1)
+	}
+	if !reflect.DeepEqual(s1.Beads, []string{"gt-abc12"}) {
+		t.Errorf("Beads = %v", s1.Beads)
+	}
+
+	res, err := a.Search(Query{Text: "why did we pin grpc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Session.ID != "s1" {
+		t.Fatalf("search for pin+grpc = %+v, want s1 only", res)
+	}
+	if len(res[0].Excerpts) == 0 || !strings.Contains(res[0].Excerpts[0].Text, "Pinned grpc to v1.62") {
+		t.Errorf("excerpts = %+v", res[0].Excerpts)
+	}
+
+	res, _ = a.Search(Query{Text: "grpc"})
+	if len(res) != 2 {
+		t.Errorf("search grpc: %d results, want 2", len(res))
+	}
+
+	res, _ = a.Search(Query{Bead: "gt-abc12"})
+	if len(res) != 1 || res[0].Session.ID != "s1" || len(res[0].Excerpts) != 1 {
+		t.Errorf("bead lookup = %+v", res)
+	}
+
+	res, _ = a.Search(Query{Actor: "polecats"})
+	if len(res) != 1 || res[0].Session.ID != "s2" {
+		t.Errorf("actor filter = %+v", res)
+	}
+
+	res, _ = a.Search(Query{})
+	if len(res) != 2 || res[0].Session.ID != "s2" {
+		t.Errorf("empty query should list newest first: %+v", res)
+	}
+}
+
+func TestArchive_Replace(t *testing.T) {
+	a, _ := Open(t.TempDir())
+	now := time.Now()
+	_ = a.Add(Session{ID: "s1", SourceMod: now}, testTurns(now, "mentions kafka"), nil)
+	_ = a.Add(Session{ID: "s1", SourceMod: now.Add(time.Minute)}, testTurns(now, "mentions redis"), nil)
+
+	if res, _ := a.Search(Query{Text: "kafka"}); len(res) != 0 {
+		t.Errorf("stale postings survived re-add: %+v", res)
+	}
+	if res, _ := a.Search(Query{Text: "redis"}); len(res) != 1 {
+		t.Errorf("re-added session not found")
+	}
+	if !a.Fresh("s1", now) || a.Fresh("s1", now.Add(time.Hour)) || a.Fresh("s2", now) {
+		t.Error("Fresh mismatch")
+	}
+	if err := a.Add(Session{ID: "../x"}, nil, nil); err == nil {
+		t.Error("Add accepted a path-like session id")
+	}
+}
+
+func TestArchive_TruncatesOnRuneBoundary(t *testing.T) {
+	a, _ := Open(t.TempDir())
+	// A 3-byte rune straddles the MaxTurnChars cut.
+	text := strings.Repeat("a", MaxTurnChars-1) + strings.Repeat("€", 10)
+	if err := a.Add(Session{ID: "s1"}, testTurns(time.Now(), text), nil); err != nil {
+		t.Fatal(err)
+	}
+	turns, err := a.Turns("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(turns) != 1 {
+		t.Fatalf("got %d turns, want 1", len(turns))
+	}
+	got := turns[0].Text
+	if !utf8.ValidString(got) {
+		t.Errorf("truncated turn is not valid UTF-8: %q", got[len(got)-8:])
+	}
+	if want := strings.Repeat("a", MaxTurnChars-1) + "…"; got != want {
+		t.Errorf("truncated turn ends %q, want %q", got[len(got)-8:], want[len(want)-8:])
+	}
+}
+
+func TestBeadPattern(t *testing.T) {
+	text := "see gt-abc12 and hq-cv-9x, not pre-commit or gt-wisp.3 (gt-mol1.2)"
+	got := BeadPattern([]string{"gt-", "hq"}).FindAllString(text, -1)
+	want := []string{"gt-abc12", "gt-mol1.2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("prefixed = %v, want %v", got, want)
+	}
+	if got := BeadPattern(nil).FindAllString("pre-commit gt-abc12", -1); !reflect.DeepEqual(got, []string{"gt-abc12"}) {
+		t.Errorf("generic = %v", got)
+	}
+}
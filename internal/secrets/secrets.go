@@ -0,0 +1,237 @@
+// Package secrets provides a small encrypted key/value store for values that
+// must not be committed with the town, such as plugin API tokens.
+//
+// The store lives at <town>/.runtime/secrets.enc (gitignored) and is sealed
+// with AES-256-GCM. The key is kept outside the town, in the user's config
+// directory, so copying or backing up the town does not expose secrets.
+// GT_SECRETS_KEY (64 hex characters) overrides the key file.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// KeyEnv names the environment variable that overrides the key file.
+const KeyEnv = "GT_SECRETS_KEY"
+
+// StoreFile is the name of the encrypted store under <town>/.runtime.
+const StoreFile = "secrets.enc"
+
+// storeVersion is the current store format version.
+const storeVersion = 1
+
+// ErrNoKey is returned when the store exists but its key can't be found.
+var ErrNoKey = errors.New("secrets key not found (set " + KeyEnv + " or restore the key file)")
+
+// Store is a town's encrypted secrets store.
+type Store struct {
+	path    string
+	keyPath string
+}
+
+// sealed is the on-disk form of the store.
+type sealed struct {
+	Version    int    `json:"version"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Open returns the secrets store for a town. Nothing is read until a value
+// is requested.
+func Open(townRoot string) *Store {
+	return &Store{
+		path:    filepath.Join(townRoot, ".runtime", StoreFile),
+		keyPath: defaultKeyPath(),
+	}
+}
+
+// Path returns the store file's path.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Get returns the named secret and whether it is set.
+func (s *Store) Get(name string) (string, bool, error) {
+	values, err := s.load()
+	if err != nil {
+		return "", false, err
+	}
+	v, ok := values[name]
+	return v, ok, nil
+}
+
+// Set stores a secret, creating the store (and key) if needed.
+func (s *Store) Set(name, value string) error {
+	values, err := s.load()
+	if err != nil {
+		return err
+	}
+	values[name] = value
+	return s.save(values)
+}
+
+// Delete removes a secret and reports whether it was set.
+func (s *Store) Delete(name string) (bool, error) {
+	values, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := values[name]; !ok {
+		return false, nil
+	}
+	delete(values, name)
+	return true, s.save(values)
+}
+
+// Names returns the names of all stored secrets with the given prefix, sorted.
+func (s *Store) Names(prefix string) ([]string, error) {
+	values, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range values {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *Store) load() (map[string]string, error) {
+	values := make(map[string]string)
+	data, err := os.ReadFile(s.path) //nolint:gosec // G304: path is constructed from trusted town root
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading secrets: %w", err)
+	}
+	var box sealed
+	if err := json.Unmarshal(data, &box); err != nil {
+		return nil, fmt.Errorf("parsing secrets: %w", err)
+	}
+	if box.Version > storeVersion {
+		return nil, fmt.Errorf("secrets store version %d is newer than this gt supports (%d)", box.Version, storeVersion)
+	}
+
+	key, err := s.key(false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, box.Nonce, box.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting secrets (wrong key?): %w", err)
+	}
+	if err := json.Unmarshal(plain, &values); err != nil {
+		return nil, fmt.Errorf("parsing secrets: %w", err)
+	}
+	return values, nil
+}
+
+func (s *Store) save(values map[string]string) error {
+	key, err := s.key(true)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data, err := json.Marshal(sealed{
+		Version:    storeVersion,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plain, nil),
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("creating runtime directory: %w", err)
+	}
+	return util.AtomicWriteFile(s.path, data, 0600)
+}
+
+// key returns the 32-byte store key from KeyEnv or the key file, generating
+// the key file when create is set and neither exists.
+func (s *Store) key(create bool) ([]byte, error) {
+	if v := os.Getenv(KeyEnv); v != "" {
+		return decodeKey(v, KeyEnv)
+	}
+	if s.keyPath == "" {
+		return nil, ErrNoKey
+	}
+	data, err := os.ReadFile(s.keyPath) //nolint:gosec // G304: key path is under the user's config dir
+	if err == nil {
+		return decodeKey(strings.TrimSpace(string(data)), s.keyPath)
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading secrets key: %w", err)
+	}
+	if !create {
+		return nil, ErrNoKey
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.keyPath), 0700); err != nil {
+		return nil, fmt.Errorf("creating key directory: %w", err)
+	}
+	if err := util.AtomicWriteFile(s.keyPath, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("writing secrets key: %w", err)
+	}
+	return key, nil
+}
+
+func decodeKey(v, from string) ([]byte, error) {
+	key, err := hex.DecodeString(v)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%s: want 64 hex characters", from)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// defaultKeyPath returns ~/.config/gastown/secrets.key (or the platform
+// equivalent), or "" if there is no user config directory.
+func defaultKeyPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "gastown", "secrets.key")
+}
@@ -0,0 +1,84 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testStore(t *testing.T) *Store {
+	t.Helper()
+	t.Setenv(KeyEnv, "")
+	s := Open(t.TempDir())
+	s.keyPath = filepath.Join(t.TempDir(), "gastown", "secrets.key")
+	return s
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	s := testStore(t)
+
+	if _, ok, err := s.Get("plugin/lint/token"); ok || err != nil {
+		t.Fatalf("Get on empty store = %v, %v", ok, err)
+	}
+	if err := s.Set("plugin/lint/token", "ghp_secret"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := s.Set("plugin/gastown/lint/token", "ghp_rig"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if v, ok, err := s.Get("plugin/lint/token"); v != "ghp_secret" || !ok || err != nil {
+		t.Errorf("Get = %q, %v, %v", v, ok, err)
+	}
+
+	data, err := os.ReadFile(s.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("ghp_")) {
+		t.Error("store file contains plaintext")
+	}
+	for _, path := range []string{s.Path(), s.keyPath} {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("%s mode = %v, %v", path, info.Mode(), err)
+		}
+	}
+
+	if names, _ := s.Names("plugin/gastown/"); len(names) != 1 || names[0] != "plugin/gastown/lint/token" {
+		t.Errorf("Names = %v", names)
+	}
+	if ok, err := s.Delete("plugin/lint/token"); !ok || err != nil {
+		t.Errorf("Delete = %v, %v", ok, err)
+	}
+	if _, ok, _ := s.Get("plugin/lint/token"); ok {
+		t.Error("deleted secret still set")
+	}
+}
+
+func TestStoreKeys(t *testing.T) {
+	s := testStore(t)
+	if err := s.Set("a", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without its key the store can't be read.
+	if err := os.Remove(s.keyPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Get("a"); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Get without key = %v, want ErrNoKey", err)
+	}
+
+	// A different key fails to decrypt.
+	t.Setenv(KeyEnv, strings.Repeat("ab", 32))
+	if _, _, err := s.Get("a"); err == nil || !strings.Contains(err.Error(), "decrypting") {
+		t.Errorf("Get with wrong key = %v", err)
+	}
+
+	t.Setenv(KeyEnv, "short")
+	if err := s.Set("a", "1"); err == nil {
+		t.Error("expected a malformed key to be rejected")
+	}
+}
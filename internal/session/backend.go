@@ -0,0 +1,101 @@
+package session
+
+import (
+	"os"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/ptyd"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// Session backends, selected per town by settings/config.json
+// "session_backend" or the GT_SESSION_BACKEND environment variable.
+const (
+	// BackendTmux runs agents in tmux sessions (the default).
+	BackendTmux = "tmux"
+
+	// BackendPTY runs agents under the daemon's PTY supervisor, for hosts
+	// where tmux is unavailable (CI containers, minimal servers).
+	BackendPTY = "pty"
+)
+
+// SessionBackend is the set of session operations that agent lifecycle
+// paths need regardless of where the agent runs. *tmux.Tmux implements it;
+// *ptyd.Client is the headless implementation. tmux-only features (themes,
+// hooks, key bindings) stay on *tmux.Tmux.
+type SessionBackend interface {
+	NewSessionWithCommandAndEnv(name, workDir, command string, env map[string]string) error
+	HasSession(name string) (bool, error)
+	IsAgentAlive(session string) bool
+	ListSessions() ([]string, error)
+	KillSessionWithProcesses(name string) error
+	SetEnvironment(session, key, value string) error
+	GetEnvironment(session, key string) (string, error)
+	NudgeSession(session, message string) error
+	SendKeysRaw(session, keys string) error
+	CapturePane(session string, lines int) (string, error)
+	AttachSession(session string) error
+	GetPanePID(session string) (string, error)
+}
+
+var (
+	_ SessionBackend = (*tmux.Tmux)(nil)
+	_ SessionBackend = (*ptyd.Client)(nil)
+)
+
+// ResolveBackend returns the session backend configured for a town.
+// GT_SESSION_BACKEND overrides the town setting; anything unrecognized
+// means tmux.
+func ResolveBackend(townRoot string) string {
+	name := os.Getenv("GT_SESSION_BACKEND")
+	if name == "" && townRoot != "" {
+		if settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot)); err == nil {
+			name = settings.SessionBackend
+		}
+	}
+	if name == BackendPTY {
+		return BackendPTY
+	}
+	return BackendTmux
+}
+
+// IsHeadless reports whether a town runs agents under the PTY supervisor.
+func IsHeadless(townRoot string) bool {
+	return ResolveBackend(townRoot) == BackendPTY
+}
+
+// NewBackend returns the session backend for a town: a client for the
+// town's PTY supervisor when headless, otherwise t (or a new tmux client
+// when t is nil).
+func NewBackend(townRoot string, t *tmux.Tmux) SessionBackend {
+	if IsHeadless(townRoot) {
+		return ptyd.NewClient(ptyd.SocketPath(townRoot))
+	}
+	if t == nil {
+		t = tmux.NewTmux()
+	}
+	return t
+}
+
+// CheckSessionHealth is tmux.CheckSessionHealth for any backend. A PTY
+// session ends when its agent exits and keeps no activity clock, so it is
+// either healthy or dead.
+func CheckSessionHealth(b SessionBackend, name string, maxInactivity time.Duration) tmux.ZombieStatus {
+	if t, ok := b.(*tmux.Tmux); ok {
+		return t.CheckSessionHealth(name, maxInactivity)
+	}
+	if alive, err := b.HasSession(name); err != nil || !alive {
+		return tmux.SessionDead
+	}
+	return tmux.SessionHealthy
+}
+
+// GetSessionInfo is tmux.GetSessionInfo for any backend. PTY sessions only
+// report their name.
+func GetSessionInfo(b SessionBackend, name string) (*tmux.SessionInfo, error) {
+	if t, ok := b.(*tmux.Tmux); ok {
+		return t.GetSessionInfo(name)
+	}
+	return &tmux.SessionInfo{Name: name, Windows: 1}, nil
+}
@@ -0,0 +1,67 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/ptyd"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+func TestResolveBackend(t *testing.T) {
+	townRoot := t.TempDir()
+	settingsDir := filepath.Join(townRoot, "settings")
+	if err := os.MkdirAll(settingsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GT_SESSION_BACKEND", "")
+	if got := ResolveBackend(townRoot); got != BackendTmux {
+		t.Errorf("no settings: ResolveBackend() = %q, want %q", got, BackendTmux)
+	}
+
+	if err := os.WriteFile(filepath.Join(settingsDir, "config.json"), []byte(`{"session_backend":"pty"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := ResolveBackend(townRoot); got != BackendPTY {
+		t.Errorf("pty setting: ResolveBackend() = %q, want %q", got, BackendPTY)
+	}
+	if !IsHeadless(townRoot) {
+		t.Error("IsHeadless() = false for pty town")
+	}
+
+	t.Setenv("GT_SESSION_BACKEND", "tmux")
+	if got := ResolveBackend(townRoot); got != BackendTmux {
+		t.Errorf("env override: ResolveBackend() = %q, want %q", got, BackendTmux)
+	}
+
+	t.Setenv("GT_SESSION_BACKEND", "screen")
+	if got := ResolveBackend(townRoot); got != BackendTmux {
+		t.Errorf("unknown backend: ResolveBackend() = %q, want %q", got, BackendTmux)
+	}
+}
+
+func TestCheckSessionHealth_Headless(t *testing.T) {
+	b := ptyd.NewClient(filepath.Join(t.TempDir(), "missing.sock"))
+	if got := CheckSessionHealth(b, "gt-witness", 0); got != tmux.SessionDead {
+		t.Errorf("CheckSessionHealth() = %v, want SessionDead with no supervisor", got)
+	}
+	if _, err := KillExistingSession(b, "gt-witness", true); err != nil {
+		t.Errorf("KillExistingSession() = %v, want nil for a missing session", err)
+	}
+	info, err := GetSessionInfo(b, "gt-witness")
+	if err != nil || info.Name != "gt-witness" {
+		t.Errorf("GetSessionInfo() = %+v, %v", info, err)
+	}
+}
+
+func TestAfterDialog(t *testing.T) {
+	content := "Do you trust this folder?\n> Yes\nQuick safety check\nready\n❯ "
+	if got := afterDialog(content); got != "ready\n❯ " {
+		t.Errorf("afterDialog() = %q", got)
+	}
+	if got := afterDialog("❯ "); got != "❯ " {
+		t.Errorf("afterDialog() without dialog = %q", got)
+	}
+}
@@ -0,0 +1,162 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/ptyd"
+)
+
+// startHeadless is StartSession for towns on the PTY backend. The session
+// environment is passed at creation instead of set afterwards, and the
+// tmux-only steps (remain-on-exit, theme, respawn hooks, pane IDs, PID
+// tracking) are skipped: the supervisor owns the process group and drops
+// the session when it exits.
+func startHeadless(ctx context.Context, cfg SessionConfig, command, runID string, runtimeConfig *config.RuntimeConfig) (*StartResult, error) {
+	b := ptyd.NewClient(ptyd.SocketPath(cfg.TownRoot))
+
+	env := config.AgentEnv(config.AgentEnvConfig{
+		Role:             cfg.Role,
+		Rig:              cfg.RigName,
+		AgentName:        cfg.AgentName,
+		TownRoot:         cfg.TownRoot,
+		RuntimeConfigDir: cfg.RuntimeConfigDir,
+		Agent:            cfg.AgentOverride,
+		SessionName:      cfg.SessionID,
+	})
+	env = MergeRuntimeLivenessEnv(env, runtimeConfig)
+	env["GT_RUN"] = runID
+	for k, v := range cfg.ExtraEnv {
+		env[k] = v
+	}
+
+	if err := b.NewSessionWithCommandAndEnv(cfg.SessionID, cfg.WorkDir, command, env); err != nil {
+		return nil, fmt.Errorf("creating session: %w", err)
+	}
+
+	if cfg.AcceptBypass {
+		AcceptHeadlessDialogs(b, cfg.SessionID)
+	}
+	if cfg.ReadyDelay || cfg.WaitForAgent {
+		if err := WaitForHeadlessReady(b, cfg.SessionID, runtimeConfig, constants.ClaudeStartTimeout); err != nil {
+			if cfg.WaitForAgent && cfg.WaitFatal {
+				_ = b.KillSessionWithProcesses(cfg.SessionID)
+				return nil, fmt.Errorf("waiting for %s to start: %w", cfg.Role, err)
+			}
+			fmt.Fprintf(os.Stderr, "Warning: agent readiness detection timed out for %s: %v\n", cfg.SessionID, err)
+		}
+	}
+
+	if cfg.VerifySurvived {
+		running, err := b.HasSession(cfg.SessionID)
+		if err != nil {
+			_ = b.KillSessionWithProcesses(cfg.SessionID)
+			return nil, fmt.Errorf("verifying session: %w", err)
+		}
+		if !running {
+			return nil, fmt.Errorf("session %s died during startup (agent command may have failed)", cfg.SessionID)
+		}
+	}
+
+	RecordAgentInstantiateFromDir(ctx, runID, runtimeConfig.ResolvedAgent,
+		cfg.Role, cfg.AgentName, cfg.SessionID, cfg.RigName, cfg.TownRoot, "", cfg.WorkDir)
+
+	return &StartResult{RuntimeConfig: runtimeConfig, RunID: runID}, nil
+}
+
+// StartHeadlessAgent starts an agent on the PTY backend for role managers
+// that build their own startup command and environment (witness, refinery,
+// daemon restarts). It accepts the startup dialogs and waits for the agent's
+// prompt, killing the session if the agent never gets there.
+func StartHeadlessAgent(b SessionBackend, sessionID, workDir, command string, env map[string]string, rc *config.RuntimeConfig) error {
+	if err := b.NewSessionWithCommandAndEnv(sessionID, workDir, command, env); err != nil {
+		return fmt.Errorf("creating session: %w", err)
+	}
+	AcceptHeadlessDialogs(b, sessionID)
+	if err := WaitForHeadlessReady(b, sessionID, rc, constants.ClaudeStartTimeout); err != nil {
+		_ = b.KillSessionWithProcesses(sessionID)
+		return err
+	}
+	return nil
+}
+
+// WaitForHeadlessReady is tmux.WaitForRuntimeReady for any backend: it polls
+// captured output for the runtime's ready prompt, or sleeps the configured
+// ready delay for runtimes without prompt detection.
+func WaitForHeadlessReady(b SessionBackend, session string, rc *config.RuntimeConfig, timeout time.Duration) error {
+	if rc == nil || rc.Tmux == nil {
+		return nil
+	}
+	prefix := strings.TrimSpace(strings.ReplaceAll(rc.Tmux.ReadyPromptPrefix, "\u00a0", " "))
+	if prefix == "" {
+		if rc.Tmux.ReadyDelayMs > 0 {
+			time.Sleep(min(time.Duration(rc.Tmux.ReadyDelayMs)*time.Millisecond, timeout))
+		}
+		return nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if content, err := b.CapturePane(session, 10); err == nil {
+			for _, line := range strings.Split(content, "\n") {
+				if strings.HasPrefix(strings.TrimSpace(strings.ReplaceAll(line, "\u00a0", " ")), prefix) {
+					return nil
+				}
+			}
+		} else if running, _ := b.HasSession(session); !running {
+			return fmt.Errorf("session %s exited", session)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return fmt.Errorf("timeout waiting for runtime prompt")
+}
+
+// AcceptHeadlessDialogs dismisses Claude Code's startup dialogs (workspace
+// trust, then the bypass permissions warning) through any backend, the same
+// way tmux.AcceptStartupDialogs does. Captures are scrollback rather than a
+// screen, so a dismissed dialog's text stays visible: the trust dialog is
+// answered once, and only output after the last dialog line counts as the
+// agent prompt. Returns once the prompt shows or the dialog poll times out.
+func AcceptHeadlessDialogs(b SessionBackend, session string) {
+	trusted := false
+	deadline := time.Now().Add(constants.DialogPollTimeout)
+	for time.Now().Before(deadline) {
+		content, err := b.CapturePane(session, 30)
+		if err != nil {
+			time.Sleep(constants.DialogPollInterval)
+			continue
+		}
+		if strings.Contains(content, "Bypass Permissions mode") {
+			_ = b.SendKeysRaw(session, "Down")
+			time.Sleep(200 * time.Millisecond)
+			_ = b.SendKeysRaw(session, "Enter")
+			return
+		}
+		if !trusted && (strings.Contains(content, "trust this folder") || strings.Contains(content, "Quick safety check")) {
+			_ = b.SendKeysRaw(session, "Enter")
+			trusted = true
+			time.Sleep(500 * time.Millisecond)
+			continue
+		}
+		if strings.Contains(afterDialog(content), "❯") {
+			return
+		}
+		time.Sleep(constants.DialogPollInterval)
+	}
+}
+
+// afterDialog returns the captured lines after the last startup dialog line.
+func afterDialog(content string) string {
+	lines := strings.Split(content, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.Contains(lines[i], "trust this folder") || strings.Contains(lines[i], "Quick safety check") {
+			return strings.Join(lines[i+1:], "\n")
+		}
+	}
+	return content
+}
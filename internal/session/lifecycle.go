@@ -81,6 +81,11 @@ type SessionConfig struct {
 	// These are set in the tmux session environment after the standard vars.
 	ExtraEnv map[string]string
 
+	// EnvFile is a shell file of exports that the startup command sources and
+	// then deletes. Used for secrets, which must stay out of the command line
+	// and the tmux session environment.
+	EnvFile string
+
 	// Theme is the tmux theme to apply. Nil means no theme is applied.
 	Theme *tmux.Theme
 
@@ -196,6 +201,15 @@ func StartSession(t *tmux.Tmux, cfg SessionConfig) (_ *StartResult, retErr error
 	}
 	extraWithRun["GT_RUN"] = runID
 	command = config.PrependEnv(command, extraWithRun)
+	if cfg.EnvFile != "" {
+		q := config.ShellQuote(cfg.EnvFile)
+		command = ". " + q + " && rm -f " + q + " && " + command
+	}
+
+	// Headless towns run the session under the daemon's PTY supervisor.
+	if IsHeadless(cfg.TownRoot) {
+		return startHeadless(ctx, cfg, command, runID, runtimeConfig)
+	}
 
 	// 4. Create tmux session with command.
 	if err := t.NewSessionWithCommand(cfg.SessionID, cfg.WorkDir, command); err != nil {
@@ -341,7 +355,7 @@ func RecordAgentInstantiateFromDir(ctx context.Context, runID, resolvedAgent, ro
 //
 // If graceful is true, sends Ctrl-C first and waits for the session to exit
 // before force-killing. This allows the agent to clean up.
-func StopSession(t *tmux.Tmux, sessionID string, graceful bool) error {
+func StopSession(t SessionBackend, sessionID string, graceful bool) error {
 	running, err := t.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
@@ -424,7 +438,7 @@ func MergeRuntimeLivenessEnv(envVars map[string]string, runtimeConfig *config.Ru
 // If checkAlive is true, only kills zombie sessions (tmux alive but agent dead).
 // If the session exists and the agent is alive, returns ErrAlreadyRunning.
 // If checkAlive is false, kills any existing session unconditionally.
-func KillExistingSession(t *tmux.Tmux, sessionID string, checkAlive bool) (bool, error) {
+func KillExistingSession(t SessionBackend, sessionID string, checkAlive bool) (bool, error) {
 	running, err := t.HasSession(sessionID)
 	if err != nil {
 		return false, fmt.Errorf("checking session: %w", err)
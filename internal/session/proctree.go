@@ -0,0 +1,111 @@
+package session
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ProcInfo is one row of a process table snapshot.
+type ProcInfo struct {
+	PID        int
+	PPID       int
+	CPUPercent float64 // ps %cpu (lifetime average, not instantaneous)
+	RSSKB      int64
+}
+
+// ProcessTable is a point-in-time snapshot of the host's processes,
+// indexed for walking process trees without a ps call per PID.
+type ProcessTable struct {
+	procs    map[int]ProcInfo
+	children map[int][]int
+}
+
+// TreeUsage is the aggregate resource usage of a process and its descendants.
+type TreeUsage struct {
+	Procs      int
+	CPUPercent float64
+	RSSKB      int64
+}
+
+// ReadProcessTable snapshots all processes with a single ps call.
+func ReadProcessTable() (*ProcessTable, error) {
+	cmd := exec.Command("ps", "-eo", "pid=,ppid=,pcpu=,rss=")
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseProcessTable(string(out)), nil
+}
+
+// parseProcessTable parses "pid ppid pcpu rss" lines from ps.
+// Malformed lines are skipped.
+func parseProcessTable(out string) *ProcessTable {
+	pt := &ProcessTable{
+		procs:    make(map[int]ProcInfo),
+		children: make(map[int][]int),
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		cpu, err3 := strconv.ParseFloat(fields[2], 64)
+		rss, err4 := strconv.ParseInt(fields[3], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || pid <= 0 {
+			continue
+		}
+		pt.procs[pid] = ProcInfo{PID: pid, PPID: ppid, CPUPercent: cpu, RSSKB: rss}
+		pt.children[ppid] = append(pt.children[ppid], pid)
+	}
+	return pt
+}
+
+// Get returns the snapshot entry for pid.
+func (pt *ProcessTable) Get(pid int) (ProcInfo, bool) {
+	p, ok := pt.procs[pid]
+	return p, ok
+}
+
+// Descendants returns all descendant PIDs of root (not including root).
+func (pt *ProcessTable) Descendants(root int) []int {
+	var out []int
+	seen := map[int]bool{root: true}
+	stack := append([]int(nil), pt.children[root]...)
+	for len(stack) > 0 {
+		pid := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[pid] {
+			continue
+		}
+		seen[pid] = true
+		out = append(out, pid)
+		stack = append(stack, pt.children[pid]...)
+	}
+	return out
+}
+
+// TreeUsage sums CPU and RSS over root and all its descendants.
+// Returns a zero TreeUsage if root is not in the snapshot.
+func (pt *ProcessTable) TreeUsage(root int) TreeUsage {
+	var u TreeUsage
+	p, ok := pt.procs[root]
+	if !ok {
+		return u
+	}
+	u.add(p)
+	for _, pid := range pt.Descendants(root) {
+		u.add(pt.procs[pid])
+	}
+	return u
+}
+
+func (u *TreeUsage) add(p ProcInfo) {
+	u.Procs++
+	u.CPUPercent += p.CPUPercent
+	u.RSSKB += p.RSSKB
+}
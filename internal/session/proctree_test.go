@@ -0,0 +1,55 @@
+package session
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestParseProcessTable_TreeUsage(t *testing.T) {
+	out := `    1     0  0.0  1024
+  100     1  2.5  2000
+  101   100 10.0  3000
+  102   101  1.5   500
+  200     1 50.0  9999
+garbage line
+  103   100  x    100
+`
+	pt := parseProcessTable(out)
+
+	u := pt.TreeUsage(100)
+	if u.Procs != 3 {
+		t.Errorf("Procs = %d, want 3", u.Procs)
+	}
+	if u.CPUPercent != 14.0 {
+		t.Errorf("CPUPercent = %v, want 14.0", u.CPUPercent)
+	}
+	if u.RSSKB != 5500 {
+		t.Errorf("RSSKB = %d, want 5500", u.RSSKB)
+	}
+
+	if got := pt.TreeUsage(999); got != (TreeUsage{}) {
+		t.Errorf("TreeUsage(missing) = %+v, want zero", got)
+	}
+}
+
+func TestProcessTable_DescendantsCycle(t *testing.T) {
+	// A malformed snapshot with a ppid cycle must not loop forever.
+	pt := parseProcessTable("10 11 0 1\n11 10 0 1\n")
+	if got := len(pt.Descendants(10)); got != 1 {
+		t.Errorf("Descendants = %d, want 1", got)
+	}
+}
+
+func TestReadProcessTable_Self(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("ps not available on windows")
+	}
+	pt, err := ReadProcessTable()
+	if err != nil {
+		t.Skipf("ps unavailable: %v", err)
+	}
+	if _, ok := pt.Get(os.Getpid()); !ok {
+		t.Errorf("own pid %d missing from process table", os.Getpid())
+	}
+}
@@ -1693,6 +1693,38 @@ func (t *Tmux) GetPaneID(session string) (string, error) {
 	return result, nil
 }
 
+// GetPaneSize returns the width and height of a session's first pane.
+func (t *Tmux) GetPaneSize(session string) (width, height int, err error) {
+	out, err := t.run("display-message", "-t", session+":0.0", "-p", "#{pane_width} #{pane_height}")
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := fmt.Sscanf(strings.TrimSpace(out), "%d %d", &width, &height); err != nil {
+		return 0, 0, fmt.Errorf("parsing pane size %q: %w", out, err)
+	}
+	return width, height, nil
+}
+
+// PipePane starts piping a session's first pane output into shellCmd.
+// The -o flag makes this a no-op when the pane is already being piped, so
+// a restarted daemon won't stack a second recorder on the same pane.
+func (t *Tmux) PipePane(session, shellCmd string) error {
+	if err := validateSessionName(session); err != nil {
+		return err
+	}
+	_, err := t.run("pipe-pane", "-o", "-t", session+":0.0", shellCmd)
+	return err
+}
+
+// StopPipePane stops any pipe-pane running on a session's first pane.
+func (t *Tmux) StopPipePane(session string) error {
+	if err := validateSessionName(session); err != nil {
+		return err
+	}
+	_, err := t.run("pipe-pane", "-t", session+":0.0")
+	return err
+}
+
 // GetPaneWorkDir returns the current working directory of a pane.
 // Targets pane 0 explicitly to avoid returning the active pane's
 // working directory in multi-pane sessions.
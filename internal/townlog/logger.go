@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
@@ -258,6 +259,20 @@ func ParseLogLines(content string) ([]Event, error) {
 	return events, nil
 }
 
+// ParseLogLine parses a single log line into an Event, also returning the
+// human-readable detail that follows the agent (e.g. "spawned for gt-xyz").
+func ParseLogLine(line string) (Event, string, error) {
+	event, err := parseLogLine(line)
+	if err != nil {
+		return event, "", err
+	}
+	detail := ""
+	if idx := strings.Index(line, "] "+event.Agent+" "); idx >= 0 {
+		detail = line[idx+len(event.Agent)+3:]
+	}
+	return event, detail, nil
+}
+
 // parseLogLine parses a single log line into an Event.
 // Format: 2025-12-26 15:30:45 [spawn] gastown/crew/max spawned for gt-xyz
 func parseLogLine(line string) (Event, error) {
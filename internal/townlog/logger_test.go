@@ -133,6 +133,23 @@ func TestParseLogLine(t *testing.T) {
 	}
 }
 
+func TestParseLogLineDetail(t *testing.T) {
+	event, detail, err := ParseLogLine("2025-12-26 15:30:45 [spawn] gastown/crew/max spawned for gt-xyz")
+	if err != nil {
+		t.Fatalf("ParseLogLine() unexpected error: %v", err)
+	}
+	if event.Type != EventSpawn || event.Agent != "gastown/crew/max" {
+		t.Errorf("ParseLogLine() event = %+v", event)
+	}
+	if detail != "spawned for gt-xyz" {
+		t.Errorf("ParseLogLine() detail = %q, want %q", detail, "spawned for gt-xyz")
+	}
+
+	if _, _, err := ParseLogLine("short"); err == nil {
+		t.Error("ParseLogLine() expected error for short line")
+	}
+}
+
 func TestLoggerLogEvent(t *testing.T) {
 	// Create temp directory
 	tmpDir, err := os.MkdirTemp("", "townlog-test")
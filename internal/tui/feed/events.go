@@ -474,6 +474,38 @@ func buildEventMessage(eventType string, payload map[string]interface{}) string
 		}
 		return "escalation sent"
 
+	case "approval_requested":
+		bead := getPayloadString(payload, "bead")
+		step := getPayloadString(payload, "step")
+		if bead != "" && step != "" {
+			return fmt.Sprintf("awaiting approval: %s step %s", bead, step)
+		}
+		return "approval requested"
+
+	case "approval_decided":
+		bead := getPayloadString(payload, "bead")
+		status := getPayloadString(payload, "status")
+		if bead != "" && status != "" {
+			return fmt.Sprintf("approval %s: %s", status, bead)
+		}
+		return "approval decided"
+
+	case "sandbox_violation":
+		polecat := getPayloadString(payload, "polecat")
+		detail := getPayloadString(payload, "detail")
+		if polecat != "" && detail != "" {
+			return fmt.Sprintf("sandbox violation by %s: %s", polecat, detail)
+		}
+		return "sandbox violation"
+
+	case "runaway_agent":
+		sess := getPayloadString(payload, "session")
+		detail := getPayloadString(payload, "detail")
+		if sess != "" && detail != "" {
+			return fmt.Sprintf("runaway %s: %s", sess, detail)
+		}
+		return "runaway agent"
+
 	case "sling":
 		bead := getPayloadString(payload, "bead")
 		target := getPayloadString(payload, "target")
@@ -521,6 +553,14 @@ func buildEventMessage(eventType string, payload map[string]interface{}) string
 		}
 		return "merged"
 
+	case "convoy_landed":
+		convoy := getPayloadString(payload, "convoy")
+		title := getPayloadString(payload, "title")
+		if convoy != "" && title != "" {
+			return fmt.Sprintf("convoy landed %s: %s", convoy, title)
+		}
+		return "convoy landed"
+
 	case "merge_failed":
 		reason := getPayloadString(payload, "reason")
 		if reason != "" {
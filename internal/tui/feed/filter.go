@@ -0,0 +1,88 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/logquery"
+)
+
+// savedFilterFile stores the activity view's query filter across sessions.
+const savedFilterFile = "feed-filter.json"
+
+// savedFilter is the on-disk form of the feed filter.
+type savedFilter struct {
+	Query string `json:"query"`
+}
+
+// savedFilterPath returns <townRoot>/.runtime/feed-filter.json.
+func savedFilterPath(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", savedFilterFile)
+}
+
+// LoadSavedFilter returns the feed filter saved in townRoot, or "" if none.
+func LoadSavedFilter(townRoot string) (string, error) {
+	data, err := os.ReadFile(savedFilterPath(townRoot)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var sf savedFilter
+	if err := json.Unmarshal(data, &sf); err != nil {
+		return "", fmt.Errorf("parsing %s: %w", savedFilterFile, err)
+	}
+	return sf.Query, nil
+}
+
+// SaveFilter persists the feed filter for townRoot. An empty query removes
+// the saved filter.
+func SaveFilter(townRoot, query string) error {
+	path := savedFilterPath(townRoot)
+	if strings.TrimSpace(query) == "" {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(savedFilter{Query: query}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644) //nolint:gosec // G306: filter is not sensitive
+}
+
+// eventRecord converts a feed event into a query record. Target and role
+// are exposed as payload fields so queries like target=gt-abc work.
+func eventRecord(e Event) logquery.Record {
+	payload := make(map[string]interface{}, 2)
+	if e.Target != "" {
+		payload["target"] = e.Target
+	}
+	if e.Role != "" {
+		payload["role"] = e.Role
+	}
+	return logquery.Record{
+		Time:    e.Time,
+		Source:  logquery.SourceFeed,
+		Type:    e.Type,
+		Actor:   e.Actor,
+		Rig:     e.Rig,
+		Message: e.Message,
+		Payload: payload,
+	}
+}
+
+// matchesQuery reports whether an event passes a query's filter expression.
+// A nil query matches everything.
+func matchesQuery(q *logquery.Query, e *Event) bool {
+	return q == nil || q.Match(eventRecord(*e))
+}
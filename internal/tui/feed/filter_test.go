@@ -0,0 +1,112 @@
+package feed
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/steveyegge/gastown/internal/logquery"
+)
+
+func TestSavedFilterRoundTrip(t *testing.T) {
+	townRoot := t.TempDir()
+
+	got, err := LoadSavedFilter(townRoot)
+	if err != nil || got != "" {
+		t.Fatalf("LoadSavedFilter on empty town = %q, %v", got, err)
+	}
+
+	if err := SaveFilter(townRoot, "type=sling rig=gastown"); err != nil {
+		t.Fatal(err)
+	}
+	got, err = LoadSavedFilter(townRoot)
+	if err != nil || got != "type=sling rig=gastown" {
+		t.Fatalf("LoadSavedFilter = %q, %v", got, err)
+	}
+
+	if err := SaveFilter(townRoot, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(savedFilterPath(townRoot)); !os.IsNotExist(err) {
+		t.Errorf("clearing the filter should remove %s", savedFilterPath(townRoot))
+	}
+}
+
+func TestMatchesQuery(t *testing.T) {
+	e := &Event{
+		Time:    time.Now(),
+		Type:    "sling",
+		Actor:   "mayor",
+		Target:  "gt-abc",
+		Message: "slung gt-abc to Toast",
+		Rig:     "gastown",
+		Role:    "mayor",
+	}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"type=sling", true},
+		{"type=done", false},
+		{"target=gt-abc rig=gastown", true},
+		{"message~toast", true},
+		{"source=feed role=mayor", true},
+		{"since=1h", true},
+	}
+	for _, tt := range tests {
+		q, err := logquery.Parse(tt.query)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.query, err)
+		}
+		if got := matchesQuery(q, e); got != tt.want {
+			t.Errorf("matchesQuery(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+	if !matchesQuery(nil, e) {
+		t.Error("nil query should match everything")
+	}
+}
+
+func TestFilterPromptAppliesAndSaves(t *testing.T) {
+	townRoot := t.TempDir()
+	m := NewModel(nil)
+	m.SetTownRoot(townRoot)
+	m.addEvent(Event{Time: time.Now(), Type: "sling", Actor: "mayor", Target: "gt-1", Rig: "gastown", Message: "slung gt-1"})
+	m.addEvent(Event{Time: time.Now(), Type: "done", Actor: "gastown/polecats/Toast", Target: "gt-2", Rig: "gastown", Message: "finished gt-2"})
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if !m.filtering {
+		t.Fatal("f should open the filter prompt")
+	}
+
+	// A bad query keeps the prompt open and reports the error.
+	m.filterInput.SetValue("type=")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.filtering || m.filterErr == "" {
+		t.Fatalf("invalid query: filtering=%v err=%q", m.filtering, m.filterErr)
+	}
+
+	m.filterInput.SetValue("type=done")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.filtering || m.filter != "type=done" {
+		t.Fatalf("after enter: filtering=%v filter=%q", m.filtering, m.filter)
+	}
+	feed := m.renderFeed()
+	if !strings.Contains(feed, "finished gt-2") || strings.Contains(feed, "slung gt-1") {
+		t.Errorf("filtered feed = %q", feed)
+	}
+	if saved, _ := LoadSavedFilter(townRoot); saved != "type=done" {
+		t.Errorf("saved filter = %q, want type=done", saved)
+	}
+
+	// esc in the activity view clears the filter and the saved copy.
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.filter != "" || m.query != nil {
+		t.Errorf("esc should clear the filter, got %q", m.filter)
+	}
+	if saved, _ := LoadSavedFilter(townRoot); saved != "" {
+		t.Errorf("saved filter after clear = %q", saved)
+	}
+}
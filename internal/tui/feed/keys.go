@@ -116,7 +116,7 @@ func DefaultKeyMap() KeyMap {
 		),
 		Filter: key.NewBinding(
 			key.WithKeys("f"),
-			key.WithHelp("f", "filter"),
+			key.WithHelp("f", "filter (query)"),
 		),
 		ClearFilter: key.NewBinding(
 			key.WithKeys("esc"),
@@ -1,16 +1,20 @@
 package feed
 
 import (
+	"fmt"
 	"os/exec"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/logquery"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
 
@@ -94,6 +98,13 @@ type Model struct {
 	showHelp bool
 	filter   string
 
+	// Query filter for the event feed (see logquery). The active filter is
+	// saved under .runtime so it survives restarts.
+	query       *logquery.Query
+	filtering   bool
+	filterInput textinput.Model
+	filterErr   string
+
 	// View mode
 	viewMode ViewMode
 
@@ -126,6 +137,10 @@ func NewModel(bd *beads.Beads) *Model {
 	h := help.New()
 	h.ShowAll = false
 
+	ti := textinput.New()
+	ti.Placeholder = "type=sling rig=gastown"
+	ti.CharLimit = 200
+
 	return &Model{
 		focusedPanel:     PanelTree,
 		treeViewport:     viewport.New(0, 0),
@@ -137,6 +152,7 @@ func NewModel(bd *beads.Beads) *Model {
 		problemAgents:    make([]*ProblemAgent, 0),
 		keys:             DefaultKeyMap(),
 		help:             h,
+		filterInput:      ti,
 		done:             make(chan struct{}),
 		viewMode:         ViewActivity,
 		stuckDetector:    NewStuckDetector(bd),
@@ -160,6 +176,26 @@ func (m *Model) SetTownRoot(townRoot string) {
 	m.mu.Unlock()
 }
 
+// SetFilter sets the event feed's query filter. An empty string clears it.
+// The filter is not persisted; see LoadSavedFilter and SaveFilter.
+// Safe to call concurrently with the Bubble Tea event loop.
+func (m *Model) SetFilter(text string) error {
+	var q *logquery.Query
+	if text = strings.TrimSpace(text); text != "" {
+		var err error
+		if q, err = logquery.Parse(text); err != nil {
+			return err
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.query = q
+	m.filter = text
+	m.filterErr = ""
+	m.updateViewContentLocked()
+	return nil
+}
+
 // Init initializes the model
 func (m *Model) Init() tea.Cmd {
 	cmds := []tea.Cmd{
@@ -274,6 +310,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.isFiltering() {
+			return m.handleFilterKey(msg)
+		}
 		return m.handleKey(msg)
 
 	case tea.WindowSizeMsg:
@@ -376,6 +415,24 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.ToggleProblems):
 		return m.toggleProblemsView()
 
+	case key.Matches(msg, m.keys.Filter):
+		if m.viewMode == ViewActivity {
+			m.mu.Lock()
+			m.filtering = true
+			m.filterErr = ""
+			m.filterInput.SetValue(m.filter)
+			m.filterInput.CursorEnd()
+			m.mu.Unlock()
+			return m, m.filterInput.Focus()
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.ClearFilter):
+		if m.viewMode == ViewActivity && m.filter != "" {
+			m.applyFilter("")
+		}
+		return m, nil
+
 	case key.Matches(msg, m.keys.Tab):
 		return m.handleTabKey()
 
@@ -453,6 +510,58 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// isFiltering reports whether the filter prompt is open.
+func (m *Model) isFiltering() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.filtering
+}
+
+// handleFilterKey handles keys while the filter prompt is open.
+// Enter applies the query, esc cancels.
+func (m *Model) handleFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.mu.Lock()
+		m.filtering = false
+		m.filterErr = ""
+		m.filterInput.Blur()
+		m.mu.Unlock()
+		return m, nil
+	case tea.KeyEnter:
+		m.applyFilter(m.filterInput.Value())
+		return m, nil
+	}
+	m.mu.Lock()
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	m.mu.Unlock()
+	return m, cmd
+}
+
+// applyFilter sets the feed filter from the prompt and saves it. On a parse
+// error the prompt stays open and shows the error.
+func (m *Model) applyFilter(text string) {
+	if err := m.SetFilter(text); err != nil {
+		m.mu.Lock()
+		m.filterErr = err.Error()
+		m.mu.Unlock()
+		return
+	}
+	m.mu.Lock()
+	m.filtering = false
+	m.filterInput.Blur()
+	townRoot, filter := m.townRoot, m.filter
+	m.mu.Unlock()
+	if townRoot != "" {
+		if err := SaveFilter(townRoot, filter); err != nil {
+			m.mu.Lock()
+			m.filterErr = fmt.Sprintf("saving filter: %v", err)
+			m.mu.Unlock()
+		}
+	}
+}
+
 // toggleProblemsView switches between activity and problems view
 func (m *Model) toggleProblemsView() (tea.Model, tea.Cmd) {
 	m.mu.Lock()
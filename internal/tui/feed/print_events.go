@@ -10,16 +10,19 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/logquery"
 )
 
 // PrintOptions controls filtering and behavior for PrintGtEvents.
 type PrintOptions struct {
 	Limit  int
 	Follow bool
-	Since  string // duration string like "5m", "1h"
-	Mol    string // molecule/issue ID prefix filter
-	Type   string // event type filter
-	Rig    string // rig name filter (matches event's Rig field)
+	Since  string          // duration string like "5m", "1h"
+	Mol    string          // molecule/issue ID prefix filter
+	Type   string          // event type filter
+	Rig    string          // rig name filter (matches event's Rig field)
+	Query  *logquery.Query // optional: log query filter (pipeline stages are ignored)
 	Ctx    context.Context // optional: controls follow-mode lifecycle; nil uses signal.NotifyContext
 }
 
@@ -51,7 +54,7 @@ func PrintGtEvents(townRoot string, opts PrintOptions) error {
 	for scanner.Scan() {
 		line := scanner.Text()
 		if event := parseGtEventLine(line); event != nil {
-			if matchesFilters(event, sinceTime, opts.Mol, opts.Type, opts.Rig) {
+			if matchesFilters(event, sinceTime, opts.Mol, opts.Type, opts.Rig) && matchesQuery(opts.Query, event) {
 				events = append(events, *event)
 			}
 		}
@@ -113,7 +116,7 @@ func PrintGtEvents(townRoot string, opts PrintOptions) error {
 			for s.Scan() {
 				line := s.Text()
 				if event := parseGtEventLine(line); event != nil {
-					if matchesFilters(event, sinceTime, opts.Mol, opts.Type, opts.Rig) {
+					if matchesFilters(event, sinceTime, opts.Mol, opts.Type, opts.Rig) && matchesQuery(opts.Query, event) {
 						printEvent(*event)
 					}
 				}
@@ -176,11 +176,20 @@ var (
 		"polecat_checked": "·",
 		"polecat_nudged":  "⚡",
 		"escalation_sent": "⬆",
+		// Approval gate events
+		"approval_requested": "⏸",
+		"approval_decided":   "⚖",
+		// Sandbox events
+		"sandbox_violation": "⛔",
+		"runaway_agent":     "🔥",
+		// Dolt events
+		"dolt_failover": "⇄",
 		// Merge events
 		"merge_started": "⚙",
 		"merged":        "✓",
 		"merge_failed":  "✗",
 		"merge_skipped": "⊘",
+		"convoy_landed": "🚚",
 		// General gt events
 		"sling":   "🎯",
 		"hook":    "🪝",
@@ -407,9 +407,15 @@ func (m *Model) renderFeed() string {
 
 	for i := len(m.events) - 1; i >= start; i-- {
 		event := m.events[i]
+		if !matchesQuery(m.query, &event) {
+			continue
+		}
 		lines = append(lines, m.renderEvent(event))
 	}
 
+	if len(lines) == 0 {
+		return AgentIdleStyle.Render("No events match filter")
+	}
 	return strings.Join(lines, "\n")
 }
 
@@ -479,6 +485,14 @@ func (m *Model) renderEvent(e Event) string {
 
 // renderStatusBar renders the bottom status bar.
 func (m *Model) renderStatusBar() string {
+	if m.filtering {
+		line := "filter: " + m.filterInput.View()
+		if m.filterErr != "" {
+			line += "  " + EventFailStyle.Render(m.filterErr)
+		}
+		return StatusBarStyle.Width(m.width).Render(line)
+	}
+
 	var left string
 	if m.viewMode == ViewProblems {
 		// Problems view: show problem count and selected agent
@@ -504,6 +518,9 @@ func (m *Model) renderStatusBar() string {
 			panelName = "feed"
 		}
 		left = fmt.Sprintf("[%s] %d events", panelName, len(m.events))
+		if m.filterErr != "" {
+			left += " | " + m.filterErr
+		}
 	}
 
 	// Short help
@@ -537,6 +554,7 @@ func (m *Model) renderShortHelp() string {
 		HelpKeyStyle.Render("j/k") + HelpDescStyle.Render(":scroll"),
 		HelpKeyStyle.Render("tab") + HelpDescStyle.Render(":switch"),
 		HelpKeyStyle.Render("/") + HelpDescStyle.Render(":search"),
+		HelpKeyStyle.Render("f") + HelpDescStyle.Render(":filter"),
 		HelpKeyStyle.Render("q") + HelpDescStyle.Render(":quit"),
 		HelpKeyStyle.Render("?") + HelpDescStyle.Render(":help"),
 	}
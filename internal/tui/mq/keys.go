@@ -0,0 +1,97 @@
+package mq
+
+import "github.com/charmbracelet/bubbles/key"
+
+// KeyMap defines the key bindings for the merge queue TUI.
+type KeyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+	Retry    key.Binding
+	Reject   key.Binding
+	BumpUp   key.Binding // raise priority (P2 → P1)
+	BumpDown key.Binding // lower priority (P1 → P2)
+	Diff     key.Binding
+	Refresh  key.Binding
+	Confirm  key.Binding
+	Cancel   key.Binding
+	Help     key.Binding
+	Quit     key.Binding
+}
+
+// DefaultKeyMap returns the default key bindings.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		Top: key.NewBinding(
+			key.WithKeys("home", "g"),
+			key.WithHelp("g", "top"),
+		),
+		Bottom: key.NewBinding(
+			key.WithKeys("end", "G"),
+			key.WithHelp("G", "bottom"),
+		),
+		Retry: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "retry"),
+		),
+		Reject: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "reject"),
+		),
+		BumpUp: key.NewBinding(
+			key.WithKeys("+", "="),
+			key.WithHelp("+", "raise priority"),
+		),
+		BumpDown: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "lower priority"),
+		),
+		Diff: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "view diff"),
+		),
+		Refresh: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "refresh"),
+		),
+		Confirm: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "confirm"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+		Help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "help"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("q", "ctrl+c"),
+			key.WithHelp("q", "quit"),
+		),
+	}
+}
+
+// ShortHelp returns keybindings to show in the help view.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Retry, k.Reject, k.BumpUp, k.BumpDown, k.Diff, k.Quit}
+}
+
+// FullHelp returns keybindings for the expanded help view.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Top, k.Bottom},
+		{k.Retry, k.Reject, k.BumpUp, k.BumpDown, k.Diff},
+		{k.Refresh, k.Help, k.Quit},
+	}
+}
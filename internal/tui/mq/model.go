@@ -0,0 +1,375 @@
+// Package mq provides a TUI for watching and steering a rig's merge queue.
+package mq
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+// refreshInterval is how often the queue and batch state are re-read.
+const refreshInterval = 5 * time.Second
+
+// MRItem is a merge request row in the queue view.
+// Items are expected in queue order (highest score first).
+type MRItem struct {
+	ID        string
+	Branch    string
+	Target    string
+	Worker    string
+	Status    string // ready, blocked, active
+	Priority  int
+	Score     float64
+	BlockedBy string
+	Age       string
+}
+
+// Source supplies queue data and performs queue actions for the TUI.
+// The cmd layer implements it on top of beads and the refinery package so
+// this package stays free of rig discovery.
+type Source interface {
+	LoadQueue() ([]MRItem, error)
+	LoadBatch() (*refinery.BatchState, error)
+	Retry(id string) error
+	Reject(id, reason string) error
+	SetPriority(id string, priority int) error
+	DiffCommand(item MRItem) *exec.Cmd
+}
+
+// Model is the bubbletea model for the merge queue TUI.
+type Model struct {
+	rigName string
+	source  Source
+
+	items  []MRItem
+	batch  *refinery.BatchState
+	cursor int
+	err    error
+	status string // result of the last action
+
+	// Reject prompt state
+	rejecting   bool
+	rejectID    string // MR selected when the prompt opened
+	rejectInput textinput.Model
+
+	// UI state
+	keys     KeyMap
+	help     help.Model
+	showHelp bool
+	width    int
+	height   int
+
+	// mu protects all fields read by View() from concurrent access.
+	// Write lock is held during Update mutations; read lock during View/render.
+	mu sync.RWMutex
+}
+
+// New creates a new merge queue TUI model.
+func New(rigName string, source Source) *Model {
+	ti := textinput.New()
+	ti.Placeholder = "reason for rejection"
+	ti.CharLimit = 200
+	return &Model{
+		rigName:     rigName,
+		source:      source,
+		keys:        DefaultKeyMap(),
+		help:        help.New(),
+		rejectInput: ti,
+	}
+}
+
+// Init initializes the model.
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(m.fetch, m.refreshTick())
+}
+
+// fetchMsg is the result of reading the queue and batch state.
+type fetchMsg struct {
+	items []MRItem
+	batch *refinery.BatchState
+	err   error
+}
+
+// tickMsg triggers a periodic refresh.
+type tickMsg time.Time
+
+// refreshMsg triggers a one-off refresh outside the tick loop.
+type refreshMsg struct{}
+
+// actionMsg reports the outcome of a queue action.
+type actionMsg struct {
+	status string
+	err    error
+}
+
+// fetch loads the queue and the refinery batch state.
+func (m *Model) fetch() tea.Msg {
+	items, err := m.source.LoadQueue()
+	if err != nil {
+		return fetchMsg{err: err}
+	}
+	batch, err := m.source.LoadBatch()
+	return fetchMsg{items: items, batch: batch, err: err}
+}
+
+func (m *Model) refreshTick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// Update handles messages.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.mu.Lock()
+		m.width = msg.Width
+		m.height = msg.Height
+		m.help.Width = msg.Width
+		m.mu.Unlock()
+		return m, nil
+
+	case fetchMsg:
+		m.mu.Lock()
+		m.err = msg.err
+		if msg.err == nil {
+			m.setItemsLocked(msg.items)
+			m.batch = msg.batch
+		}
+		m.mu.Unlock()
+		return m, nil
+
+	case tickMsg:
+		return m, tea.Batch(m.fetch, m.refreshTick())
+
+	case refreshMsg:
+		return m, m.fetch
+
+	case actionMsg:
+		m.mu.Lock()
+		if msg.err != nil {
+			m.status = "✗ " + msg.err.Error()
+		} else {
+			m.status = "✓ " + msg.status
+		}
+		m.mu.Unlock()
+		return m, m.fetch
+
+	case tea.KeyMsg:
+		if m.isRejecting() {
+			return m.handleRejectKey(msg)
+		}
+		return m.handleKey(msg)
+	}
+
+	return m, nil
+}
+
+// handleKey handles key presses in the queue view.
+func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Help):
+		m.mu.Lock()
+		m.showHelp = !m.showHelp
+		m.mu.Unlock()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Up):
+		m.mu.Lock()
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		m.mu.Unlock()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		m.mu.Lock()
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+		m.mu.Unlock()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Top):
+		m.mu.Lock()
+		m.cursor = 0
+		m.mu.Unlock()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Bottom):
+		m.mu.Lock()
+		if len(m.items) > 0 {
+			m.cursor = len(m.items) - 1
+		}
+		m.mu.Unlock()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Refresh):
+		return m, m.fetch
+
+	case key.Matches(msg, m.keys.Retry):
+		item, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		return m, func() tea.Msg {
+			err := m.source.Retry(item.ID)
+			return actionMsg{status: fmt.Sprintf("%s released for retry", item.ID), err: err}
+		}
+
+	case key.Matches(msg, m.keys.Reject):
+		item, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		m.mu.Lock()
+		m.rejecting = true
+		m.rejectID = item.ID
+		m.rejectInput.SetValue("")
+		m.mu.Unlock()
+		return m, m.rejectInput.Focus()
+
+	case key.Matches(msg, m.keys.BumpUp):
+		return m, m.bumpPriority(-1)
+
+	case key.Matches(msg, m.keys.BumpDown):
+		return m, m.bumpPriority(1)
+
+	case key.Matches(msg, m.keys.Diff):
+		item, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		c := m.source.DiffCommand(item)
+		if c == nil {
+			return m, nil
+		}
+		return m, tea.ExecProcess(c, func(err error) tea.Msg {
+			if err != nil {
+				return actionMsg{err: fmt.Errorf("diff %s: %w", item.ID, err)}
+			}
+			return refreshMsg{}
+		})
+	}
+
+	return m, nil
+}
+
+// handleRejectKey handles key presses while the reject reason prompt is open.
+func (m *Model) handleRejectKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Cancel):
+		m.mu.Lock()
+		m.rejecting = false
+		m.rejectID = ""
+		m.rejectInput.Blur()
+		m.mu.Unlock()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Confirm):
+		m.mu.Lock()
+		reason := strings.TrimSpace(m.rejectInput.Value())
+		if reason == "" {
+			m.mu.Unlock()
+			return m, nil
+		}
+		id := m.rejectID
+		m.rejecting = false
+		m.rejectID = ""
+		m.rejectInput.Blur()
+		m.mu.Unlock()
+
+		return m, func() tea.Msg {
+			err := m.source.Reject(id, reason)
+			return actionMsg{status: fmt.Sprintf("%s rejected", id), err: err}
+		}
+	}
+
+	m.mu.Lock()
+	var cmd tea.Cmd
+	m.rejectInput, cmd = m.rejectInput.Update(msg)
+	m.mu.Unlock()
+	return m, cmd
+}
+
+// bumpPriority moves the selected MR's priority by delta (negative = more urgent).
+// Priorities are clamped to the beads range P0–P4.
+func (m *Model) bumpPriority(delta int) tea.Cmd {
+	item, ok := m.selected()
+	if !ok {
+		return nil
+	}
+	priority := item.Priority + delta
+	if priority < 0 {
+		priority = 0
+	}
+	if priority > 4 {
+		priority = 4
+	}
+	if priority == item.Priority {
+		return nil
+	}
+	return func() tea.Msg {
+		err := m.source.SetPriority(item.ID, priority)
+		return actionMsg{status: fmt.Sprintf("%s priority P%d → P%d", item.ID, item.Priority, priority), err: err}
+	}
+}
+
+// selected returns the MR under the cursor.
+func (m *Model) selected() (MRItem, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.cursor < 0 || m.cursor >= len(m.items) {
+		return MRItem{}, false
+	}
+	return m.items[m.cursor], true
+}
+
+func (m *Model) isRejecting() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.rejecting
+}
+
+// setItemsLocked replaces the queue, keeping the cursor on the same MR when
+// it is still queued so a refresh doesn't jump the selection.
+// Caller must hold m.mu write lock.
+func (m *Model) setItemsLocked(items []MRItem) {
+	selectedID := ""
+	if m.cursor >= 0 && m.cursor < len(m.items) {
+		selectedID = m.items[m.cursor].ID
+	}
+	m.items = items
+	for i, it := range items {
+		if it.ID == selectedID {
+			m.cursor = i
+			return
+		}
+	}
+	if m.cursor >= len(items) {
+		m.cursor = len(items) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// View renders the model.
+// Acquires read lock to safely access all View-visible fields.
+func (m *Model) View() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.renderView()
+}
@@ -0,0 +1,252 @@
+package mq
+
+import (
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+// fakeSource records actions and serves canned queue data.
+type fakeSource struct {
+	mu         sync.Mutex
+	items      []MRItem
+	batch      *refinery.BatchState
+	retried    []string
+	rejected   map[string]string
+	priorities map[string]int
+}
+
+func newFakeSource(items ...MRItem) *fakeSource {
+	return &fakeSource{
+		items:      items,
+		rejected:   make(map[string]string),
+		priorities: make(map[string]int),
+	}
+}
+
+func (f *fakeSource) LoadQueue() ([]MRItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]MRItem(nil), f.items...), nil
+}
+
+func (f *fakeSource) LoadBatch() (*refinery.BatchState, error) { return f.batch, nil }
+
+func (f *fakeSource) Retry(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.retried = append(f.retried, id)
+	return nil
+}
+
+func (f *fakeSource) Reject(id, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rejected[id] = reason
+	return nil
+}
+
+func (f *fakeSource) SetPriority(id string, priority int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.priorities[id] = priority
+	return nil
+}
+
+func (f *fakeSource) DiffCommand(MRItem) *exec.Cmd { return nil }
+
+func keyRunes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// loadedModel returns a model that has already applied one fetch.
+func loadedModel(t *testing.T, src *fakeSource) *Model {
+	t.Helper()
+	m := New("gastown", src)
+	m.Update(m.fetch())
+	return m
+}
+
+// runCmd executes a command and feeds its message back into the model.
+func runCmd(m *Model, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	if msg := cmd(); msg != nil {
+		m.Update(msg)
+	}
+}
+
+func TestNavigationClampsToQueue(t *testing.T) {
+	src := newFakeSource(MRItem{ID: "gt-mr-1"}, MRItem{ID: "gt-mr-2"})
+	m := loadedModel(t, src)
+
+	m.Update(keyRunes("j"))
+	m.Update(keyRunes("j"))
+	if m.cursor != 1 {
+		t.Errorf("cursor = %d, want 1", m.cursor)
+	}
+	m.Update(keyRunes("g"))
+	if m.cursor != 0 {
+		t.Errorf("cursor after top = %d, want 0", m.cursor)
+	}
+}
+
+func TestRefreshKeepsSelection(t *testing.T) {
+	src := newFakeSource(MRItem{ID: "gt-mr-1"}, MRItem{ID: "gt-mr-2"})
+	m := loadedModel(t, src)
+	m.Update(keyRunes("j"))
+
+	// gt-mr-2 jumps to the front of the queue after a priority bump.
+	src.items = []MRItem{{ID: "gt-mr-2"}, {ID: "gt-mr-1"}}
+	m.Update(m.fetch())
+
+	if item, _ := m.selected(); item.ID != "gt-mr-2" {
+		t.Errorf("selected = %q, want gt-mr-2", item.ID)
+	}
+}
+
+func TestRetrySelected(t *testing.T) {
+	src := newFakeSource(MRItem{ID: "gt-mr-1"})
+	m := loadedModel(t, src)
+
+	_, cmd := m.Update(keyRunes("r"))
+	runCmd(m, cmd)
+
+	if len(src.retried) != 1 || src.retried[0] != "gt-mr-1" {
+		t.Errorf("retried = %v, want [gt-mr-1]", src.retried)
+	}
+	if !strings.Contains(m.status, "gt-mr-1") {
+		t.Errorf("status = %q, want mention of gt-mr-1", m.status)
+	}
+}
+
+func TestBumpPriorityClamps(t *testing.T) {
+	src := newFakeSource(MRItem{ID: "gt-mr-1", Priority: 1}, MRItem{ID: "gt-mr-2", Priority: 0})
+	m := loadedModel(t, src)
+
+	_, cmd := m.Update(keyRunes("+"))
+	runCmd(m, cmd)
+	if got := src.priorities["gt-mr-1"]; got != 0 {
+		t.Errorf("priority = %d, want 0", got)
+	}
+
+	m.Update(keyRunes("j"))
+	_, cmd = m.Update(keyRunes("+"))
+	if cmd != nil {
+		t.Error("expected no action when priority is already P0")
+	}
+}
+
+func TestRejectPrompt(t *testing.T) {
+	src := newFakeSource(MRItem{ID: "gt-mr-1"})
+	m := loadedModel(t, src)
+
+	m.Update(keyRunes("x"))
+	if !m.rejecting {
+		t.Fatal("expected reject prompt to open")
+	}
+
+	// Empty reason is not accepted.
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || !m.rejecting {
+		t.Fatal("expected empty reason to keep prompt open")
+	}
+
+	m.Update(keyRunes("superseded"))
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	runCmd(m, cmd)
+
+	if m.rejecting {
+		t.Error("expected prompt to close after confirm")
+	}
+	if got := src.rejected["gt-mr-1"]; got != "superseded" {
+		t.Errorf("reject reason = %q, want %q", got, "superseded")
+	}
+}
+
+func TestRejectPromptKeepsOpenedMR(t *testing.T) {
+	src := newFakeSource(MRItem{ID: "gt-mr-1"}, MRItem{ID: "gt-mr-2"})
+	m := loadedModel(t, src)
+
+	m.Update(keyRunes("x"))
+
+	// A refresh while typing drops gt-mr-1, moving the cursor to gt-mr-2.
+	src.items = []MRItem{{ID: "gt-mr-2"}}
+	m.Update(m.fetch())
+
+	m.Update(keyRunes("stale"))
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	runCmd(m, cmd)
+
+	if _, ok := src.rejected["gt-mr-2"]; ok {
+		t.Error("rejected gt-mr-2, which was not selected when the prompt opened")
+	}
+	if got := src.rejected["gt-mr-1"]; got != "stale" {
+		t.Errorf("reject reason for gt-mr-1 = %q, want %q", got, "stale")
+	}
+}
+
+func TestRejectPromptCancel(t *testing.T) {
+	src := newFakeSource(MRItem{ID: "gt-mr-1"})
+	m := loadedModel(t, src)
+
+	m.Update(keyRunes("x"))
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.rejecting {
+		t.Error("expected esc to cancel the reject prompt")
+	}
+	if len(src.rejected) != 0 {
+		t.Errorf("expected no rejection, got %v", src.rejected)
+	}
+}
+
+func TestViewShowsBatchProgress(t *testing.T) {
+	src := newFakeSource(MRItem{ID: "gt-mr-1", Status: "ready"})
+	src.batch = &refinery.BatchState{
+		Phase:  refinery.BatchPhaseBisecting,
+		Target: "main",
+		MRs:    []string{"gt-mr-7", "gt-mr-8"},
+		Gates: []refinery.GateProgress{
+			{Name: "test", Status: refinery.GateStatusRunning, StartedAt: time.Now()},
+		},
+		Bisect: &refinery.BisectState{Round: 2, Testing: []string{"gt-mr-8"}},
+	}
+	m := loadedModel(t, src)
+
+	view := m.View()
+	for _, want := range []string{"gt-mr-7, gt-mr-8", "bisecting", "gate test", "bisect round 2", "gt-mr-1"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+// TestItemsWriteConcurrentWithView verifies that refreshes racing with
+// View() do not trigger data races.
+func TestItemsWriteConcurrentWithView(t *testing.T) {
+	src := newFakeSource(MRItem{ID: "gt-mr-1"}, MRItem{ID: "gt-mr-2"})
+	m := New("gastown", src)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			m.Update(m.fetch())
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = m.View()
+		}
+	}()
+	wg.Wait()
+}
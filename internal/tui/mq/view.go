@@ -0,0 +1,241 @@
+package mq
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+// Styles for the merge queue TUI
+var (
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("12"))
+
+	sectionStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("15"))
+
+	selectedStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("236")).
+			Foreground(lipgloss.Color("15"))
+
+	rowStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("15"))
+
+	readyStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("10")) // green
+
+	activeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("11")) // yellow
+
+	failStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9")) // red
+
+	dimStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("8")) // gray
+)
+
+// renderView renders the entire view.
+// Caller must hold m.mu.
+func (m *Model) renderView() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Merge Queue: %s", m.rigName)))
+	b.WriteString("\n\n")
+
+	if m.err != nil {
+		b.WriteString(failStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		b.WriteString("\n\n")
+	}
+
+	m.renderBatch(&b)
+	b.WriteString("\n")
+	m.renderQueue(&b)
+
+	if m.status != "" {
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render(m.status))
+		b.WriteString("\n")
+	}
+
+	if m.rejecting {
+		b.WriteString("\n")
+		b.WriteString("Reject reason: ")
+		b.WriteString(m.rejectInput.View())
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render("enter:confirm  esc:cancel"))
+		return b.String()
+	}
+
+	// Help footer
+	b.WriteString("\n")
+	if m.showHelp {
+		b.WriteString(m.help.View(m.keys))
+	} else {
+		b.WriteString(dimStyle.Render("j/k:navigate  r:retry  x:reject  +/-:priority  d:diff  q:quit  ?:help"))
+	}
+
+	return b.String()
+}
+
+// renderBatch renders the refinery's current (or last) batch.
+// Caller must hold m.mu.
+func (m *Model) renderBatch(b *strings.Builder) {
+	b.WriteString(sectionStyle.Render("Batch"))
+	b.WriteString("\n")
+
+	s := m.batch
+	if s == nil {
+		b.WriteString(dimStyle.Render("  No batch recorded by the refinery yet."))
+		b.WriteString("\n")
+		return
+	}
+
+	phase := string(s.Phase)
+	if s.Phase == refinery.BatchPhaseDone {
+		phase = fmt.Sprintf("done %s ago", formatSince(s.UpdatedAt))
+	}
+	fmt.Fprintf(b, "  %s → %s  %s\n", strings.Join(s.MRs, ", "), s.Target, phaseStyle(s.Phase).Render("["+phase+"]"))
+
+	if len(s.Conflicts) > 0 {
+		fmt.Fprintf(b, "  %s %s\n", failStyle.Render("conflicts:"), strings.Join(s.Conflicts, ", "))
+	}
+
+	for _, g := range s.Gates {
+		icon, style := "…", activeStyle
+		switch g.Status {
+		case refinery.GateStatusPassed:
+			icon, style = "✓", readyStyle
+		case refinery.GateStatusFailed:
+			icon, style = "✗", failStyle
+		}
+		detail := ""
+		if g.Status == refinery.GateStatusRunning && !g.StartedAt.IsZero() {
+			detail = "running " + formatSince(g.StartedAt)
+		} else if g.ElapsedMs > 0 {
+			detail = (time.Duration(g.ElapsedMs) * time.Millisecond).Truncate(time.Second).String()
+		}
+		line := fmt.Sprintf("  %s gate %s %s", icon, g.Name, dimStyle.Render(detail))
+		b.WriteString(style.Render(line))
+		b.WriteString("\n")
+		if g.Error != "" {
+			b.WriteString(dimStyle.Render("      " + truncate(g.Error, 70)))
+			b.WriteString("\n")
+		}
+	}
+
+	if bs := s.Bisect; bs != nil {
+		fmt.Fprintf(b, "  bisect round %d", bs.Round)
+		if len(bs.Testing) > 0 {
+			fmt.Fprintf(b, "  testing: %s", strings.Join(bs.Testing, ", "))
+		}
+		b.WriteString("\n")
+		if len(bs.KnownGood) > 0 {
+			fmt.Fprintf(b, "  %s %s\n", readyStyle.Render("good:"), strings.Join(bs.KnownGood, ", "))
+		}
+	}
+
+	if s.Phase == refinery.BatchPhaseDone {
+		if len(s.Merged) > 0 {
+			fmt.Fprintf(b, "  %s %s\n", readyStyle.Render("merged:"), strings.Join(s.Merged, ", "))
+		}
+		if len(s.Culprits) > 0 {
+			fmt.Fprintf(b, "  %s %s\n", failStyle.Render("culprits:"), strings.Join(s.Culprits, ", "))
+		}
+	}
+	if s.Error != "" {
+		fmt.Fprintf(b, "  %s\n", failStyle.Render("error: "+s.Error))
+	}
+}
+
+// renderQueue renders the queue in score order.
+// Caller must hold m.mu.
+func (m *Model) renderQueue(b *strings.Builder) {
+	b.WriteString(sectionStyle.Render(fmt.Sprintf("Queue (%d)", len(m.items))))
+	b.WriteString("\n")
+
+	if len(m.items) == 0 {
+		b.WriteString(dimStyle.Render("  (empty)"))
+		b.WriteString("\n")
+		return
+	}
+
+	header := fmt.Sprintf("  %-3s %-14s %6s %-3s %-8s %-32s %-12s %5s", "#", "ID", "SCORE", "PRI", "STATUS", "BRANCH", "WORKER", "AGE")
+	b.WriteString(dimStyle.Render(header))
+	b.WriteString("\n")
+
+	for i, it := range m.items {
+		line := fmt.Sprintf("  %-3d %-14s %6.1f P%-2d %-8s %-32s %-12s %5s",
+			i+1,
+			truncate(it.ID, 14),
+			it.Score,
+			it.Priority,
+			it.Status,
+			truncate(it.Branch, 32),
+			truncate(it.Worker, 12),
+			it.Age,
+		)
+		switch {
+		case i == m.cursor:
+			b.WriteString(selectedStyle.Render(line))
+		case it.Status == "ready":
+			b.WriteString(readyStyle.Render(line))
+		case it.Status == "active":
+			b.WriteString(activeStyle.Render(line))
+		case it.Status == "blocked":
+			b.WriteString(dimStyle.Render(line))
+		default:
+			b.WriteString(rowStyle.Render(line))
+		}
+		b.WriteString("\n")
+		if it.BlockedBy != "" {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("      waiting on %s", it.BlockedBy)))
+			b.WriteString("\n")
+		}
+	}
+}
+
+// phaseStyle returns the style used for a batch phase label.
+func phaseStyle(phase refinery.BatchPhase) lipgloss.Style {
+	switch phase {
+	case refinery.BatchPhaseDone:
+		return dimStyle
+	case refinery.BatchPhaseBisecting, refinery.BatchPhaseRetrying:
+		return failStyle
+	default:
+		return activeStyle
+	}
+}
+
+// formatSince formats the time elapsed since t as a short string.
+func formatSince(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// truncate shortens a string to the given rune length, preserving UTF-8.
+func truncate(s string, maxLen int) string {
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	runes := []rune(s)
+	if maxLen <= 3 {
+		return "..."
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
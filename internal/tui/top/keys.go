@@ -0,0 +1,102 @@
+package top
+
+import "github.com/charmbracelet/bubbles/key"
+
+// KeyMap defines the key bindings for the top TUI.
+type KeyMap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Top     key.Binding
+	Bottom  key.Binding
+	Sort    key.Binding
+	Reverse key.Binding
+	Peek    key.Binding
+	Nudge   key.Binding
+	Restart key.Binding
+	Warrant key.Binding
+	Refresh key.Binding
+	Confirm key.Binding
+	Cancel  key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+}
+
+// DefaultKeyMap returns the default key bindings.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		Top: key.NewBinding(
+			key.WithKeys("home", "g"),
+			key.WithHelp("g", "top"),
+		),
+		Bottom: key.NewBinding(
+			key.WithKeys("end", "G"),
+			key.WithHelp("G", "bottom"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort column"),
+		),
+		Reverse: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "reverse sort"),
+		),
+		Peek: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "peek"),
+		),
+		Nudge: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "nudge"),
+		),
+		Restart: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "restart"),
+		),
+		Warrant: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "file warrant"),
+		),
+		Refresh: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "refresh"),
+		),
+		Confirm: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "confirm"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+		Help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "help"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("q", "ctrl+c"),
+			key.WithHelp("q", "quit"),
+		),
+	}
+}
+
+// ShortHelp returns keybindings to show in the help view.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Sort, k.Peek, k.Nudge, k.Restart, k.Warrant, k.Quit}
+}
+
+// FullHelp returns keybindings for the expanded help view.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Top, k.Bottom},
+		{k.Sort, k.Reverse, k.Peek, k.Nudge, k.Restart, k.Warrant},
+		{k.Refresh, k.Help, k.Quit},
+	}
+}
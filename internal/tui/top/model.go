@@ -0,0 +1,519 @@
+// Package top provides a top-like TUI for monitoring every agent session in a town.
+package top
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/steveyegge/gastown/internal/activity"
+)
+
+// refreshInterval is how often the session snapshot is re-read.
+const refreshInterval = 3 * time.Second
+
+// peekLines is how many pane lines the peek overlay captures.
+const peekLines = 40
+
+// AgentRow is one tmux session in the top view.
+type AgentRow struct {
+	Session    string
+	Role       string
+	Rig        string
+	Name       string
+	HookedBead string
+	Heartbeat  string // agent-reported heartbeat state, "stale", or "" when none
+	Activity   activity.Info
+	Procs      int
+	CPUPercent float64
+	RSSKB      int64
+	TokenRate  float64 // tokens per minute since the previous snapshot
+	Cost       float64 // USD, from the session's latest transcript
+}
+
+// Label returns a short display name for the agent.
+func (r AgentRow) Label() string {
+	switch {
+	case r.Rig != "" && r.Name != "":
+		return r.Rig + "/" + r.Name
+	case r.Rig != "":
+		return r.Rig + "/" + r.Role
+	case r.Name != "":
+		return r.Name
+	default:
+		return r.Session
+	}
+}
+
+// Source supplies session snapshots and builds action commands for the TUI.
+// The cmd layer implements it on top of tmux, heartbeats, and transcripts so
+// this package stays free of town discovery.
+type Source interface {
+	Snapshot() ([]AgentRow, error)
+	Peek(row AgentRow, lines int) (string, error)
+	NudgeCommand(row AgentRow, message string) *exec.Cmd
+	RestartCommand(row AgentRow) *exec.Cmd
+	WarrantCommand(row AgentRow, reason string) *exec.Cmd
+}
+
+// SortColumn identifies the column rows are ordered by.
+type SortColumn int
+
+const (
+	SortCPU SortColumn = iota
+	SortMem
+	SortActivity
+	SortTokens
+	SortCost
+	SortName
+	numSortColumns
+)
+
+// String returns the column's display name.
+func (c SortColumn) String() string {
+	switch c {
+	case SortCPU:
+		return "cpu"
+	case SortMem:
+		return "mem"
+	case SortActivity:
+		return "activity"
+	case SortTokens:
+		return "tok/min"
+	case SortCost:
+		return "cost"
+	case SortName:
+		return "name"
+	default:
+		return "?"
+	}
+}
+
+// promptKind identifies which text prompt is open.
+type promptKind int
+
+const (
+	promptNone promptKind = iota
+	promptNudge
+	promptWarrant
+	promptRestart // y/n confirmation, no text
+)
+
+// Model is the bubbletea model for the top TUI.
+type Model struct {
+	source Source
+
+	rows      []AgentRow
+	cursor    int
+	sortBy    SortColumn
+	reverse   bool
+	err       error
+	status    string // result of the last action
+	updatedAt time.Time
+
+	// Prompt state
+	prompt      promptKind
+	promptInput textinput.Model
+
+	// Peek overlay state
+	peeking     bool
+	peekSession string
+	peekContent string
+
+	// UI state
+	keys     KeyMap
+	help     help.Model
+	showHelp bool
+	width    int
+	height   int
+
+	// mu protects all fields read by View() from concurrent access.
+	// Write lock is held during Update mutations; read lock during View/render.
+	mu sync.RWMutex
+}
+
+// New creates a new top TUI model.
+func New(source Source) *Model {
+	ti := textinput.New()
+	ti.CharLimit = 200
+	return &Model{
+		source:      source,
+		keys:        DefaultKeyMap(),
+		help:        help.New(),
+		promptInput: ti,
+	}
+}
+
+// Init initializes the model.
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(m.fetch, m.refreshTick())
+}
+
+// fetchMsg is the result of a session snapshot.
+type fetchMsg struct {
+	rows []AgentRow
+	err  error
+}
+
+// tickMsg triggers a periodic refresh.
+type tickMsg time.Time
+
+// actionMsg reports the outcome of an agent action.
+type actionMsg struct {
+	status string
+	err    error
+}
+
+// peekMsg carries captured pane content for the peek overlay.
+type peekMsg struct {
+	session string
+	content string
+	err     error
+}
+
+// fetch snapshots all agent sessions.
+func (m *Model) fetch() tea.Msg {
+	rows, err := m.source.Snapshot()
+	return fetchMsg{rows: rows, err: err}
+}
+
+func (m *Model) refreshTick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// Update handles messages.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.mu.Lock()
+		m.width = msg.Width
+		m.height = msg.Height
+		m.help.Width = msg.Width
+		m.mu.Unlock()
+		return m, nil
+
+	case fetchMsg:
+		m.mu.Lock()
+		m.err = msg.err
+		if msg.err == nil {
+			m.setRowsLocked(msg.rows)
+			m.updatedAt = time.Now()
+		}
+		m.mu.Unlock()
+		return m, nil
+
+	case tickMsg:
+		return m, tea.Batch(m.fetch, m.refreshTick())
+
+	case actionMsg:
+		m.mu.Lock()
+		if msg.err != nil {
+			m.status = "✗ " + msg.err.Error()
+		} else {
+			m.status = "✓ " + msg.status
+		}
+		m.mu.Unlock()
+		return m, m.fetch
+
+	case peekMsg:
+		m.mu.Lock()
+		if msg.err != nil {
+			m.status = fmt.Sprintf("✗ peek %s: %v", msg.session, msg.err)
+		} else {
+			m.peeking = true
+			m.peekSession = msg.session
+			m.peekContent = msg.content
+		}
+		m.mu.Unlock()
+		return m, nil
+
+	case tea.KeyMsg:
+		m.mu.RLock()
+		peeking, prompt := m.peeking, m.prompt
+		m.mu.RUnlock()
+		switch {
+		case peeking:
+			return m.handlePeekKey(msg)
+		case prompt == promptRestart:
+			return m.handleConfirmKey(msg)
+		case prompt != promptNone:
+			return m.handlePromptKey(msg)
+		}
+		return m.handleKey(msg)
+	}
+
+	return m, nil
+}
+
+// handleKey handles key presses in the table view.
+func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Help):
+		m.mu.Lock()
+		m.showHelp = !m.showHelp
+		m.mu.Unlock()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Up):
+		m.mu.Lock()
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		m.mu.Unlock()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		m.mu.Lock()
+		if m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+		m.mu.Unlock()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Top):
+		m.mu.Lock()
+		m.cursor = 0
+		m.mu.Unlock()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Bottom):
+		m.mu.Lock()
+		if len(m.rows) > 0 {
+			m.cursor = len(m.rows) - 1
+		}
+		m.mu.Unlock()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Sort):
+		m.mu.Lock()
+		m.sortBy = (m.sortBy + 1) % numSortColumns
+		m.setRowsLocked(m.rows)
+		m.mu.Unlock()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Reverse):
+		m.mu.Lock()
+		m.reverse = !m.reverse
+		m.setRowsLocked(m.rows)
+		m.mu.Unlock()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Refresh):
+		return m, m.fetch
+
+	case key.Matches(msg, m.keys.Peek):
+		row, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		return m, func() tea.Msg {
+			content, err := m.source.Peek(row, peekLines)
+			return peekMsg{session: row.Session, content: content, err: err}
+		}
+
+	case key.Matches(msg, m.keys.Nudge):
+		return m, m.openPrompt(promptNudge, "message to send")
+
+	case key.Matches(msg, m.keys.Warrant):
+		return m, m.openPrompt(promptWarrant, "reason for warrant")
+
+	case key.Matches(msg, m.keys.Restart):
+		if _, ok := m.selected(); !ok {
+			return m, nil
+		}
+		m.mu.Lock()
+		m.prompt = promptRestart
+		m.mu.Unlock()
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// openPrompt opens a text prompt for the selected row.
+func (m *Model) openPrompt(kind promptKind, placeholder string) tea.Cmd {
+	if _, ok := m.selected(); !ok {
+		return nil
+	}
+	m.mu.Lock()
+	m.prompt = kind
+	m.promptInput.Placeholder = placeholder
+	m.promptInput.SetValue("")
+	m.mu.Unlock()
+	return m.promptInput.Focus()
+}
+
+// closePromptLocked closes any open prompt.
+// Caller must hold m.mu write lock.
+func (m *Model) closePromptLocked() {
+	m.prompt = promptNone
+	m.promptInput.Blur()
+}
+
+// handlePromptKey handles key presses while the nudge or warrant prompt is open.
+func (m *Model) handlePromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Cancel):
+		m.mu.Lock()
+		m.closePromptLocked()
+		m.mu.Unlock()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Confirm):
+		m.mu.Lock()
+		text := strings.TrimSpace(m.promptInput.Value())
+		if text == "" {
+			m.mu.Unlock()
+			return m, nil
+		}
+		kind := m.prompt
+		m.closePromptLocked()
+		m.mu.Unlock()
+
+		row, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		if kind == promptNudge {
+			return m, m.execAction(m.source.NudgeCommand(row, text), fmt.Sprintf("nudged %s", row.Label()))
+		}
+		return m, m.execAction(m.source.WarrantCommand(row, text), fmt.Sprintf("warrant filed for %s", row.Label()))
+	}
+
+	m.mu.Lock()
+	var cmd tea.Cmd
+	m.promptInput, cmd = m.promptInput.Update(msg)
+	m.mu.Unlock()
+	return m, cmd
+}
+
+// handleConfirmKey handles the y/n restart confirmation.
+func (m *Model) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.mu.Lock()
+	m.closePromptLocked()
+	m.mu.Unlock()
+
+	if msg.String() != "y" && msg.String() != "Y" {
+		return m, nil
+	}
+	row, ok := m.selected()
+	if !ok {
+		return m, nil
+	}
+	return m, m.execAction(m.source.RestartCommand(row), fmt.Sprintf("restarted %s", row.Label()))
+}
+
+// handlePeekKey closes the peek overlay on esc, enter, p, or q.
+func (m *Model) handlePeekKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keys.Cancel, m.keys.Confirm, m.keys.Peek, m.keys.Quit) {
+		m.mu.Lock()
+		m.peeking = false
+		m.peekContent = ""
+		m.mu.Unlock()
+	}
+	return m, nil
+}
+
+// execAction runs a gt command and reports its outcome.
+// Output goes to the terminal while the TUI is suspended.
+func (m *Model) execAction(c *exec.Cmd, success string) tea.Cmd {
+	if c == nil {
+		return func() tea.Msg {
+			return actionMsg{err: fmt.Errorf("action not supported for this session")}
+		}
+	}
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		return actionMsg{status: success, err: err}
+	})
+}
+
+// selected returns the row under the cursor.
+func (m *Model) selected() (AgentRow, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return AgentRow{}, false
+	}
+	return m.rows[m.cursor], true
+}
+
+// setRowsLocked replaces and sorts the rows, keeping the cursor on the same
+// session when it is still present so a refresh doesn't jump the selection.
+// Caller must hold m.mu write lock.
+func (m *Model) setRowsLocked(rows []AgentRow) {
+	selected := ""
+	if m.cursor >= 0 && m.cursor < len(m.rows) {
+		selected = m.rows[m.cursor].Session
+	}
+	sortRows(rows, m.sortBy, m.reverse)
+	m.rows = rows
+	for i, r := range rows {
+		if r.Session == selected {
+			m.cursor = i
+			return
+		}
+	}
+	if m.cursor >= len(rows) {
+		m.cursor = len(rows) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// sortRows orders rows by column, heaviest first (most recent first for
+// activity, alphabetical for name). Ties break on session name.
+func sortRows(rows []AgentRow, by SortColumn, reverse bool) {
+	less := func(a, b AgentRow) bool {
+		switch by {
+		case SortCPU:
+			if a.CPUPercent != b.CPUPercent {
+				return a.CPUPercent > b.CPUPercent
+			}
+		case SortMem:
+			if a.RSSKB != b.RSSKB {
+				return a.RSSKB > b.RSSKB
+			}
+		case SortActivity:
+			if !a.Activity.LastActivity.Equal(b.Activity.LastActivity) {
+				return a.Activity.LastActivity.After(b.Activity.LastActivity)
+			}
+		case SortTokens:
+			if a.TokenRate != b.TokenRate {
+				return a.TokenRate > b.TokenRate
+			}
+		case SortCost:
+			if a.Cost != b.Cost {
+				return a.Cost > b.Cost
+			}
+		}
+		return a.Session < b.Session
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if reverse {
+			return less(rows[j], rows[i])
+		}
+		return less(rows[i], rows[j])
+	})
+}
+
+// View renders the model.
+// Acquires read lock to safely access all View-visible fields.
+func (m *Model) View() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.renderView()
+}
@@ -0,0 +1,248 @@
+package top
+
+import (
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/steveyegge/gastown/internal/activity"
+)
+
+// fakeSource serves canned rows and records which commands were built.
+type fakeSource struct {
+	mu       sync.Mutex
+	rows     []AgentRow
+	peeked   []string
+	nudges   map[string]string
+	warrants map[string]string
+	restarts []string
+}
+
+func newFakeSource(rows ...AgentRow) *fakeSource {
+	return &fakeSource{
+		rows:     rows,
+		nudges:   make(map[string]string),
+		warrants: make(map[string]string),
+	}
+}
+
+func (f *fakeSource) Snapshot() ([]AgentRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]AgentRow(nil), f.rows...), nil
+}
+
+func (f *fakeSource) Peek(row AgentRow, lines int) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.peeked = append(f.peeked, row.Session)
+	return "pane output for " + row.Session, nil
+}
+
+func (f *fakeSource) NudgeCommand(row AgentRow, message string) *exec.Cmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nudges[row.Session] = message
+	return exec.Command("true")
+}
+
+func (f *fakeSource) RestartCommand(row AgentRow) *exec.Cmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.restarts = append(f.restarts, row.Session)
+	return exec.Command("true")
+}
+
+func (f *fakeSource) WarrantCommand(row AgentRow, reason string) *exec.Cmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.warrants[row.Session] = reason
+	return exec.Command("true")
+}
+
+func keyRunes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// loadedModel returns a model that has already applied one fetch.
+func loadedModel(t *testing.T, src *fakeSource) *Model {
+	t.Helper()
+	m := New(src)
+	m.Update(m.fetch())
+	return m
+}
+
+func sessions(m *Model) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []string
+	for _, r := range m.rows {
+		out = append(out, r.Session)
+	}
+	return out
+}
+
+func TestSortCyclesColumns(t *testing.T) {
+	now := time.Now()
+	src := newFakeSource(
+		AgentRow{Session: "gt-a", CPUPercent: 5, RSSKB: 900, Cost: 3, Activity: activity.Calculate(now.Add(-time.Hour))},
+		AgentRow{Session: "gt-b", CPUPercent: 50, RSSKB: 100, Cost: 1, Activity: activity.Calculate(now)},
+	)
+	m := loadedModel(t, src)
+
+	if got := sessions(m); got[0] != "gt-b" {
+		t.Errorf("cpu sort = %v, want gt-b first", got)
+	}
+	m.Update(keyRunes("s")) // mem
+	if got := sessions(m); got[0] != "gt-a" {
+		t.Errorf("mem sort = %v, want gt-a first", got)
+	}
+	m.Update(keyRunes("s")) // activity
+	if got := sessions(m); got[0] != "gt-b" {
+		t.Errorf("activity sort = %v, want gt-b first", got)
+	}
+	m.Update(keyRunes("S"))
+	if got := sessions(m); got[0] != "gt-a" {
+		t.Errorf("reversed activity sort = %v, want gt-a first", got)
+	}
+}
+
+func TestSortKeepsSelection(t *testing.T) {
+	src := newFakeSource(
+		AgentRow{Session: "gt-a", CPUPercent: 10},
+		AgentRow{Session: "gt-b", CPUPercent: 1},
+	)
+	m := loadedModel(t, src)
+	m.Update(keyRunes("j"))
+	m.Update(keyRunes("S"))
+
+	if row, _ := m.selected(); row.Session != "gt-b" {
+		t.Errorf("selected = %q, want gt-b", row.Session)
+	}
+}
+
+func TestPeekOverlay(t *testing.T) {
+	src := newFakeSource(AgentRow{Session: "gt-a"})
+	m := loadedModel(t, src)
+
+	_, cmd := m.Update(keyRunes("p"))
+	if cmd == nil {
+		t.Fatal("expected peek command")
+	}
+	m.Update(cmd())
+	if view := m.View(); !strings.Contains(view, "pane output for gt-a") {
+		t.Errorf("peek view missing pane output:\n%s", view)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.peeking {
+		t.Error("expected esc to close peek overlay")
+	}
+}
+
+func TestNudgePrompt(t *testing.T) {
+	src := newFakeSource(AgentRow{Session: "gt-a"})
+	m := loadedModel(t, src)
+
+	m.Update(keyRunes("n"))
+	if m.prompt != promptNudge {
+		t.Fatal("expected nudge prompt to open")
+	}
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || m.prompt != promptNudge {
+		t.Fatal("expected empty message to keep prompt open")
+	}
+
+	m.Update(keyRunes("wake up"))
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected nudge command")
+	}
+	if got := src.nudges["gt-a"]; got != "wake up" {
+		t.Errorf("nudge message = %q, want %q", got, "wake up")
+	}
+}
+
+func TestWarrantPromptCancel(t *testing.T) {
+	src := newFakeSource(AgentRow{Session: "gt-a"})
+	m := loadedModel(t, src)
+
+	m.Update(keyRunes("w"))
+	m.Update(keyRunes("zombie"))
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.prompt != promptNone {
+		t.Error("expected esc to cancel the warrant prompt")
+	}
+	if len(src.warrants) != 0 {
+		t.Errorf("expected no warrant, got %v", src.warrants)
+	}
+}
+
+func TestRestartRequiresConfirmation(t *testing.T) {
+	src := newFakeSource(AgentRow{Session: "gt-a"})
+	m := loadedModel(t, src)
+
+	m.Update(keyRunes("R"))
+	m.Update(keyRunes("n"))
+	if len(src.restarts) != 0 {
+		t.Fatalf("restart ran without confirmation: %v", src.restarts)
+	}
+
+	m.Update(keyRunes("R"))
+	_, cmd := m.Update(keyRunes("y"))
+	if cmd == nil || len(src.restarts) != 1 || src.restarts[0] != "gt-a" {
+		t.Errorf("restarts = %v, want [gt-a]", src.restarts)
+	}
+}
+
+func TestViewShowsColumns(t *testing.T) {
+	src := newFakeSource(AgentRow{
+		Session:    "gt-gastown-alpha",
+		Role:       "polecat",
+		Rig:        "gastown",
+		Name:       "alpha",
+		HookedBead: "gt-abc",
+		Heartbeat:  "working",
+		Activity:   activity.Calculate(time.Now()),
+		Procs:      3,
+		CPUPercent: 12.5,
+		RSSKB:      2048,
+		TokenRate:  1500,
+		Cost:       1.25,
+	})
+	m := loadedModel(t, src)
+
+	view := m.View()
+	for _, want := range []string{"gastown/alpha", "gt-abc", "working", "12.5", "2M", "1.5k", "$1.25"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+// TestRowsWriteConcurrentWithView verifies that refreshes racing with
+// View() do not trigger data races.
+func TestRowsWriteConcurrentWithView(t *testing.T) {
+	src := newFakeSource(AgentRow{Session: "gt-a"}, AgentRow{Session: "gt-b"})
+	m := New(src)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			m.Update(m.fetch())
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = m.View()
+		}
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,233 @@
+package top
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/steveyegge/gastown/internal/activity"
+)
+
+// Styles for the top TUI
+var (
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("12"))
+
+	selectedStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("236")).
+			Foreground(lipgloss.Color("15"))
+
+	rowStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("15"))
+
+	greenStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("10"))
+
+	yellowStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("11"))
+
+	redStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9"))
+
+	dimStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("8")) // gray
+)
+
+// renderView renders the entire view.
+// Caller must hold m.mu.
+func (m *Model) renderView() string {
+	if m.peeking {
+		return m.renderPeek()
+	}
+
+	var b strings.Builder
+
+	var cpu float64
+	var rss int64
+	var cost float64
+	for _, r := range m.rows {
+		cpu += r.CPUPercent
+		rss += r.RSSKB
+		cost += r.Cost
+	}
+	b.WriteString(titleStyle.Render(fmt.Sprintf("gt top — %d sessions", len(m.rows))))
+	fmt.Fprintf(&b, "  cpu %.1f%%  mem %s  cost $%.2f  %s\n\n",
+		cpu, formatRSS(rss), cost, dimStyle.Render("sort: "+m.sortLabel()))
+
+	if m.err != nil {
+		b.WriteString(redStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		b.WriteString("\n\n")
+	}
+
+	m.renderTable(&b)
+
+	if m.status != "" {
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render(m.status))
+		b.WriteString("\n")
+	}
+
+	switch m.prompt {
+	case promptNudge, promptWarrant:
+		row, _ := m.selectedLocked()
+		verb := "Nudge"
+		if m.prompt == promptWarrant {
+			verb = "Warrant reason for"
+		}
+		fmt.Fprintf(&b, "\n%s %s: %s\n", verb, row.Label(), m.promptInput.View())
+		b.WriteString(dimStyle.Render("enter:confirm  esc:cancel"))
+		return b.String()
+	case promptRestart:
+		row, _ := m.selectedLocked()
+		fmt.Fprintf(&b, "\nRestart %s (%s)? [y/N]", row.Label(), row.Session)
+		return b.String()
+	}
+
+	// Help footer
+	b.WriteString("\n")
+	if m.showHelp {
+		b.WriteString(m.help.View(m.keys))
+	} else {
+		b.WriteString(dimStyle.Render("j/k:navigate  s/S:sort  p:peek  n:nudge  R:restart  w:warrant  q:quit  ?:help"))
+	}
+
+	return b.String()
+}
+
+// renderTable renders one line per session.
+// Caller must hold m.mu.
+func (m *Model) renderTable(b *strings.Builder) {
+	if len(m.rows) == 0 {
+		b.WriteString(dimStyle.Render("  No agent sessions running."))
+		b.WriteString("\n")
+		return
+	}
+
+	header := fmt.Sprintf("  %-24s %-9s %-14s %-9s %6s %5s %6s %8s %8s %8s",
+		"AGENT", "ROLE", "HOOK", "STATE", "IDLE", "PROCS", "CPU%", "MEM", "TOK/MIN", "COST")
+	b.WriteString(dimStyle.Render(header))
+	b.WriteString("\n")
+
+	for i, r := range m.rows {
+		line := fmt.Sprintf("  %-24s %-9s %-14s %-9s %6s %5d %6.1f %8s %8s %8s",
+			truncate(r.Label(), 24),
+			truncate(r.Role, 9),
+			truncate(orDash(r.HookedBead), 14),
+			truncate(orDash(r.Heartbeat), 9),
+			r.Activity.FormattedAge,
+			r.Procs,
+			r.CPUPercent,
+			formatRSS(r.RSSKB),
+			formatRate(r.TokenRate),
+			fmt.Sprintf("$%.2f", r.Cost),
+		)
+		if i == m.cursor {
+			b.WriteString(selectedStyle.Render(line))
+		} else {
+			b.WriteString(rowStyleFor(r).Render(line))
+		}
+		b.WriteString("\n")
+	}
+}
+
+// renderPeek renders captured pane output for the selected session.
+// Caller must hold m.mu.
+func (m *Model) renderPeek() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Peek: " + m.peekSession))
+	b.WriteString("\n\n")
+	content := strings.TrimRight(m.peekContent, "\n")
+	if m.height > 4 {
+		lines := strings.Split(content, "\n")
+		if limit := m.height - 4; len(lines) > limit {
+			lines = lines[len(lines)-limit:]
+		}
+		content = strings.Join(lines, "\n")
+	}
+	b.WriteString(content)
+	b.WriteString("\n\n")
+	b.WriteString(dimStyle.Render("esc:close"))
+	return b.String()
+}
+
+// sortLabel describes the active sort.
+// Caller must hold m.mu.
+func (m *Model) sortLabel() string {
+	if m.reverse {
+		return m.sortBy.String() + " (reversed)"
+	}
+	return m.sortBy.String()
+}
+
+// selectedLocked returns the row under the cursor.
+// Caller must hold m.mu.
+func (m *Model) selectedLocked() (AgentRow, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return AgentRow{}, false
+	}
+	return m.rows[m.cursor], true
+}
+
+// rowStyleFor colors a row by heartbeat state, then by activity age.
+func rowStyleFor(r AgentRow) lipgloss.Style {
+	switch r.Heartbeat {
+	case "stuck", "stale":
+		return redStyle
+	}
+	switch r.Activity.ColorClass {
+	case activity.ColorGreen:
+		return greenStyle
+	case activity.ColorYellow:
+		return yellowStyle
+	case activity.ColorRed:
+		return redStyle
+	default:
+		return rowStyle
+	}
+}
+
+// formatRSS formats a resident set size in KB as a short string.
+func formatRSS(kb int64) string {
+	switch {
+	case kb >= 1024*1024:
+		return fmt.Sprintf("%.1fG", float64(kb)/(1024*1024))
+	case kb >= 1024:
+		return fmt.Sprintf("%dM", kb/1024)
+	default:
+		return fmt.Sprintf("%dK", kb)
+	}
+}
+
+// formatRate formats a token rate per minute, abbreviating thousands.
+func formatRate(perMin float64) string {
+	switch {
+	case perMin <= 0:
+		return "-"
+	case perMin >= 1000:
+		return fmt.Sprintf("%.1fk", perMin/1000)
+	default:
+		return fmt.Sprintf("%.0f", perMin)
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// truncate shortens a string to the given rune length, preserving UTF-8.
+func truncate(s string, maxLen int) string {
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	runes := []rune(s)
+	if maxLen <= 3 {
+		return "..."
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
@@ -5,7 +5,9 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
@@ -14,9 +16,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/approval"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/logquery"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 // CommandRequest is the JSON request body for /api/run.
@@ -97,15 +102,17 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate CSRF token on all POST requests.
-	if r.Method == http.MethodPost && h.csrfToken != "" {
+	path := strings.TrimPrefix(r.URL.Path, "/api")
+
+	// Validate CSRF token on all POST requests. The approval webhook comes
+	// from outside the dashboard and is authenticated by its HMAC signature.
+	if r.Method == http.MethodPost && h.csrfToken != "" && path != "/approvals/webhook" {
 		if r.Header.Get("X-Dashboard-Token") != h.csrfToken {
 			h.sendError(w, "Invalid or missing dashboard token", http.StatusForbidden)
 			return
 		}
 	}
 
-	path := strings.TrimPrefix(r.URL.Path, "/api")
 	switch {
 	case path == "/run" && r.Method == http.MethodPost:
 		h.handleRun(w, r)
@@ -139,6 +146,14 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handleSSE(w, r)
 	case path == "/session/preview" && r.Method == http.MethodGet:
 		h.handleSessionPreview(w, r)
+	case path == "/log/query" && r.Method == http.MethodGet:
+		h.handleLogQuery(w, r)
+	case path == "/approvals" && r.Method == http.MethodGet:
+		h.handleApprovals(w, r)
+	case path == "/approvals/decide" && r.Method == http.MethodPost:
+		h.handleApprovalDecide(w, r)
+	case path == "/approvals/webhook" && r.Method == http.MethodPost:
+		h.handleApprovalWebhook(w, r)
 	default:
 		http.Error(w, "Not found", http.StatusNotFound)
 	}
@@ -224,6 +239,22 @@ func (h *APIHandler) handleCommands(w http.ResponseWriter, _ *http.Request) {
 
 // runGtCommand executes a gt command with the given args.
 func (h *APIHandler) runGtCommand(ctx context.Context, timeout time.Duration, args []string) (string, error) {
+	stdout, stderr, err := h.runGtCommandSplit(ctx, timeout, args)
+
+	// Combine stdout and stderr for output
+	output := stdout
+	if stderr != "" {
+		if output != "" {
+			output += "\n"
+		}
+		output += stderr
+	}
+	return output, err
+}
+
+// runGtCommandSplit executes a gt command, returning stdout and stderr
+// separately for callers that parse machine-readable output.
+func (h *APIHandler) runGtCommandSplit(ctx context.Context, timeout time.Duration, args []string) (string, string, error) {
 	// Apply timeout first so it bounds both semaphore wait and command execution.
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
@@ -233,7 +264,7 @@ func (h *APIHandler) runGtCommand(ctx context.Context, timeout time.Duration, ar
 	case h.cmdSem <- struct{}{}:
 		defer func() { <-h.cmdSem }()
 	case <-ctx.Done():
-		return "", fmt.Errorf("command slot unavailable: %w", ctx.Err())
+		return "", "", fmt.Errorf("command slot unavailable: %w", ctx.Err())
 	}
 
 	cmd := exec.CommandContext(ctx, h.gtPath, args...)
@@ -249,24 +280,15 @@ func (h *APIHandler) runGtCommand(ctx context.Context, timeout time.Duration, ar
 
 	err := cmd.Run()
 
-	// Combine stdout and stderr for output
-	output := stdout.String()
-	if stderr.Len() > 0 {
-		if output != "" {
-			output += "\n"
-		}
-		output += stderr.String()
-	}
-
 	if ctx.Err() == context.DeadlineExceeded {
-		return output, fmt.Errorf("command timed out after %v", timeout)
+		return stdout.String(), stderr.String(), fmt.Errorf("command timed out after %v", timeout)
 	}
 
 	if err != nil {
-		return output, fmt.Errorf("command failed: %v", err)
+		return stdout.String(), stderr.String(), fmt.Errorf("command failed: %v", err)
 	}
 
-	return output, nil
+	return stdout.String(), stderr.String(), nil
 }
 
 // sendError sends a JSON error response.
@@ -2022,6 +2044,179 @@ func (h *APIHandler) handleSessionPreview(w http.ResponseWriter, r *http.Request
 	})
 }
 
+// maxLogQueryRecords caps records returned by /api/log/query so a broad
+// query over a large events file can't produce an unbounded response.
+const maxLogQueryRecords = 500
+
+// LogQueryResponse is the response for /api/log/query.
+type LogQueryResponse struct {
+	Query     string            `json:"query"`
+	Records   []json.RawMessage `json:"records,omitempty"`
+	Aggregate json.RawMessage   `json:"aggregate,omitempty"`
+}
+
+// handleLogQuery runs a log query (see gt log query) over the town log and
+// events file. The query is validated here so syntax errors return 400;
+// execution goes through gt so the dashboard and CLI stay in agreement.
+// Unlike /api/run, arguments are not sanitized: the query is passed as a
+// single argv entry after "--", so it never reaches a shell and a leading
+// dash can't be read as a flag.
+func (h *APIHandler) handleLogQuery(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	q, err := logquery.Parse(query)
+	if err != nil {
+		h.sendError(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	run := query
+	if !q.Aggregated() {
+		run = fmt.Sprintf("%s | tail %d", query, maxLogQueryRecords)
+	}
+
+	output, stderr, err := h.runGtCommandSplit(r.Context(), h.defaultRunTimeout, []string{"log", "query", "--json", "--", run})
+	if err != nil {
+		h.sendError(w, fmt.Sprintf("Query failed: %v: %s", err, strings.TrimSpace(stderr)), http.StatusInternalServerError)
+		return
+	}
+
+	resp := LogQueryResponse{Query: query, Records: []json.RawMessage{}}
+	if q.Aggregated() {
+		resp.Aggregate = json.RawMessage(strings.TrimSpace(output))
+	} else {
+		for _, line := range strings.Split(output, "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "{") && json.Valid([]byte(line)) {
+				resp.Records = append(resp.Records, json.RawMessage(line))
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// maxApprovalReasonLen caps the reason passed to gt approve.
+const maxApprovalReasonLen = 500
+
+// maxApprovalWebhookBody caps the size of an approval webhook body.
+const maxApprovalWebhookBody = 64 * 1024
+
+// handleApprovals lists pending approval requests (see gt approve list).
+func (h *APIHandler) handleApprovals(w http.ResponseWriter, r *http.Request) {
+	output, stderr, err := h.runGtCommandSplit(r.Context(), 10*time.Second, []string{"approve", "list", "--json"})
+	if err != nil {
+		h.sendError(w, fmt.Sprintf("Failed to list approvals: %v: %s", err, strings.TrimSpace(stderr)), http.StatusInternalServerError)
+		return
+	}
+	approvals := json.RawMessage(strings.TrimSpace(output))
+	if !json.Valid(approvals) {
+		approvals = json.RawMessage("[]")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"approvals": approvals})
+}
+
+// ApprovalDecideRequest is the request body for /api/approvals/decide.
+type ApprovalDecideRequest struct {
+	ID       string `json:"id"`
+	Decision string `json:"decision"` // "approve" or "reject"
+	Reason   string `json:"reason,omitempty"`
+}
+
+// handleApprovalDecide approves or rejects a request from the dashboard.
+func (h *APIHandler) handleApprovalDecide(w http.ResponseWriter, r *http.Request) {
+	var req ApprovalDecideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	h.decideApproval(w, req.ID, req.Decision, "overseer", req.Reason, approval.ViaWeb)
+}
+
+// handleApprovalWebhook applies a decision from an external system. The
+// body must be signed with the town's approval webhook secret.
+func (h *APIHandler) handleApprovalWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxApprovalWebhookBody))
+	if err != nil {
+		h.sendError(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+	townRoot, err := workspace.Find(h.workDir)
+	if err != nil || townRoot == "" {
+		h.sendError(w, "Not in a Gas Town workspace", http.StatusInternalServerError)
+		return
+	}
+	secret, err := approval.LoadSecret(townRoot)
+	if err != nil {
+		h.sendError(w, "Approval webhook is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	payload, err := approval.ParseWebhook(secret, body, r.Header.Get(approval.SignatureHeader), time.Now())
+	if err != nil {
+		h.sendError(w, "Rejected webhook: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if payload.By == "" {
+		h.sendError(w, "Webhook must name the deciding approver in \"by\"", http.StatusBadRequest)
+		return
+	}
+	h.decideApproval(w, payload.ID, payload.Decision, payload.By, payload.Reason, approval.ViaWebhook)
+}
+
+// decideApproval records a decision on id. It decides in-process rather
+// than through gt approve, so the recorded source is the endpoint that was
+// called, not a flag.
+func (h *APIHandler) decideApproval(w http.ResponseWriter, id, decision, by, reason, via string) {
+	if !strings.HasPrefix(id, "ap-") || !isValidID(id) {
+		h.sendError(w, "Invalid approval ID format", http.StatusBadRequest)
+		return
+	}
+	if !isValidMailAddress(by) {
+		h.sendError(w, "Invalid decider", http.StatusBadRequest)
+		return
+	}
+	if len(reason) > maxApprovalReasonLen {
+		reason = reason[:maxApprovalReasonLen]
+	}
+	if decision != "approve" && decision != "reject" {
+		h.sendError(w, "Decision must be approve or reject", http.StatusBadRequest)
+		return
+	}
+	townRoot, err := workspace.Find(h.workDir)
+	if err != nil || townRoot == "" {
+		h.sendError(w, "Not in a Gas Town workspace", http.StatusInternalServerError)
+		return
+	}
+
+	req, err := approval.NewStore(townRoot).Decide(id, approval.Decision{
+		Approve: decision == "approve",
+		By:      by,
+		Reason:  reason,
+		Via:     via,
+	})
+	switch {
+	case errors.Is(err, approval.ErrNotFound):
+		h.sendError(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, approval.ErrNotApprover):
+		h.sendError(w, err.Error(), http.StatusForbidden)
+		return
+	case errors.Is(err, approval.ErrDecided):
+		h.sendError(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		h.sendError(w, fmt.Sprintf("Failed to record decision: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	output := fmt.Sprintf("%s %s (%s step %s)", req.ID, req.Status, req.Bead, req.Step)
+	if err := approval.RecordDecision(townRoot, req); err != nil {
+		output += "\nwarning: " + err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(CommandResponse{Success: true, Output: output})
+}
+
 // parseCommandArgs splits a command string into args, respecting quotes.
 func parseCommandArgs(command string) []string {
 	var args []string
@@ -4,13 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/approval"
 	"github.com/steveyegge/gastown/internal/session"
 )
 
@@ -1158,3 +1163,194 @@ func TestHandleSessionPreviewPrefixValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIHandler_LogQuery_InvalidQuery(t *testing.T) {
+	handler := NewAPIHandler(30*time.Second, 60*time.Second, "test-token")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/log/query?q=type%3D", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(w.Body.String(), "Invalid query") {
+		t.Errorf("body = %q, want invalid query error", w.Body.String())
+	}
+}
+
+func TestAPIHandler_LogQuery_Records(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell-based command test")
+	}
+
+	// Fake gt that prints its args as one record plus a warning on stderr.
+	dir := t.TempDir()
+	script := filepath.Join(dir, "gt")
+	body := "#!/bin/sh\necho 'WARNING: noise' >&2\nprintf '{\"args\":\"%s|%s|%s|%s|%s\"}\\n' \"$1\" \"$2\" \"$3\" \"$4\" \"$5\"\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	h := &APIHandler{
+		gtPath:            script,
+		workDir:           dir,
+		defaultRunTimeout: 30 * time.Second,
+		maxRunTimeout:     30 * time.Second,
+		cmdSem:            make(chan struct{}, 1),
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/log/query?q=type%3Dcrash", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp LogQueryResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Records) != 1 {
+		t.Fatalf("records = %d, want 1", len(resp.Records))
+	}
+	want := `{"args":"log|query|--json|--|type=crash | tail 500"}`
+	if string(resp.Records[0]) != want {
+		t.Errorf("record = %s, want %s", resp.Records[0], want)
+	}
+}
+
+// newApprovalTestTown returns a fake town with one pending approval request
+// and a handler rooted in it. bd is kept off PATH so recording the decision
+// on the bead fails fast as a warning.
+func newApprovalTestTown(t *testing.T) (*APIHandler, string, *approval.Request) {
+	t.Helper()
+	town := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(town, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(town, "mayor", "town.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv(approval.SecretEnv, "")
+
+	req := &approval.Request{Bead: "gt-wisp-abc", Step: "signoff", RequestedBy: "gastown/polecats/Toast", Approvers: []string{"overseer", "ci"}}
+	if err := approval.NewStore(town).Create(req); err != nil {
+		t.Fatal(err)
+	}
+	h := &APIHandler{
+		workDir:           town,
+		defaultRunTimeout: 30 * time.Second,
+		maxRunTimeout:     30 * time.Second,
+		cmdSem:            make(chan struct{}, 1),
+		csrfToken:         "test-token",
+	}
+	return h, town, req
+}
+
+func TestAPIHandler_ApprovalWebhook(t *testing.T) {
+	h, town, req := newApprovalTestTown(t)
+	secret, err := approval.GenerateSecret(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(body []byte, sig string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/approvals/webhook", bytes.NewReader(body))
+		req.Header.Set(approval.SignatureHeader, sig)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	payload := func(by string) []byte {
+		return []byte(fmt.Sprintf(`{"id":%q,"decision":"reject","by":%q,"reason":"tests red","timestamp":%d}`, req.ID, by, time.Now().Unix()))
+	}
+
+	// No CSRF token needed, but the signature must match.
+	body := payload("ci")
+	if w := post(body, approval.Sign([]byte("wrong"), body)); w.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status = %d, want 401", w.Code)
+	}
+
+	// A signed webhook still has to name an approver, and not the requester.
+	for by, want := range map[string]int{
+		"":                       http.StatusBadRequest,
+		"mayor/":                 http.StatusForbidden,
+		"gastown/polecats/Toast": http.StatusForbidden,
+	} {
+		body := payload(by)
+		if w := post(body, approval.Sign([]byte(secret), body)); w.Code != want {
+			t.Errorf("by %q: status = %d, want %d", by, w.Code, want)
+		}
+	}
+
+	w := post(body, approval.Sign([]byte(secret), body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp CommandResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.Success {
+		t.Errorf("resp = %+v", resp)
+	}
+	got, err := approval.NewStore(town).Get(req.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != approval.StatusRejected || got.DecidedBy != "ci" || got.Via != approval.ViaWebhook || got.Reason != "tests red" {
+		t.Errorf("decided = %+v", got)
+	}
+
+	// Replaying the decision finds it already decided.
+	if w := post(body, approval.Sign([]byte(secret), body)); w.Code != http.StatusConflict {
+		t.Errorf("second decision: status = %d, want 409", w.Code)
+	}
+}
+
+func TestAPIHandler_ApprovalDecide(t *testing.T) {
+	h, town, req := newApprovalTestTown(t)
+
+	body := fmt.Sprintf(`{"id":%q,"decision":"approve"}`, req.ID)
+	r := httptest.NewRequest(http.MethodPost, "/api/approvals/decide", strings.NewReader(body))
+	r.Header.Set("X-Dashboard-Token", "test-token")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	got, err := approval.NewStore(town).Get(req.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != approval.StatusApproved || got.DecidedBy != "overseer" || got.Via != approval.ViaWeb {
+		t.Errorf("decided = %+v", got)
+	}
+}
+
+func TestAPIHandler_ApprovalDecide_Validation(t *testing.T) {
+	handler := NewAPIHandler(30*time.Second, 60*time.Second, "test-token")
+
+	for _, body := range []string{
+		`{"id":"gt-abc","decision":"approve"}`,
+		`{"id":"ap-1a2b","decision":"maybe"}`,
+		`{"id":"--help","decision":"approve"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/approvals/decide", strings.NewReader(body))
+		req.Header.Set("X-Dashboard-Token", "test-token")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+
+	// The dashboard endpoint still requires the CSRF token.
+	req := httptest.NewRequest(http.MethodPost, "/api/approvals/decide", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("missing token: status = %d, want 403", w.Code)
+	}
+}
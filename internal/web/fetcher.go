@@ -17,6 +17,8 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/daemon"
+	"github.com/steveyegge/gastown/internal/procstat"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -1338,7 +1340,12 @@ func (f *LiveConvoyFetcher) FetchQueues() ([]QueueRow, error) {
 	return rows, nil
 }
 
-// FetchSessions returns active tmux sessions with role detection.
+// sessionUsageStaleAfter hides resource samples older than this from the
+// sessions panel (the daemon's sampler runs every 30s by default).
+const sessionUsageStaleAfter = 5 * time.Minute
+
+// FetchSessions returns active tmux sessions with role detection and the
+// latest resource sample of each session's process tree.
 func (f *LiveConvoyFetcher) FetchSessions() ([]SessionRow, error) {
 	// List tmux sessions
 	stdout, err := runCmd(f.tmuxCmdTimeout, "tmux", "list-sessions", "-F", "#{session_name}:#{session_activity}")
@@ -1346,6 +1353,10 @@ func (f *LiveConvoyFetcher) FetchSessions() ([]SessionRow, error) {
 		return nil, nil // tmux not running or no sessions
 	}
 
+	// Resource samples recorded by the daemon's resource_sampler patrol.
+	usage, _ := procstat.Latest(f.townRoot, sessionUsageStaleAfter)
+	thresholds, _ := daemon.RunawayThresholds(daemon.LoadPatrolConfig(f.townRoot))
+
 	var rows []SessionRow
 	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
 		if line == "" {
@@ -1380,6 +1391,15 @@ func (f *LiveConvoyFetcher) FetchSessions() ([]SessionRow, error) {
 			row.Worker = identity.Name
 		}
 
+		if u, ok := usage[name]; ok {
+			row.Sampled = true
+			row.Procs = u.Procs
+			row.CPU = fmt.Sprintf("%.0f%%", u.CPUPercent)
+			row.RSS = procstat.FormatKB(u.RSSKB)
+			row.Files = u.OpenFiles
+			row.Runaway = strings.Join(thresholds.Exceeded(u), ", ")
+		}
+
 		rows = append(rows, row)
 	}
 
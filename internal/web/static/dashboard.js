@@ -148,6 +148,7 @@
         // Reload dynamic panels after swap (handled via window functions)
         if (window.refreshCrewPanel) window.refreshCrewPanel();
         if (window.refreshReadyPanel) window.refreshReadyPanel();
+        if (window.refreshApprovalsPanel) window.refreshApprovalsPanel();
         // Update connection status indicator after morph
         updateConnectionStatus(window.sseConnected ? 'live' : 'reconnecting');
     });
@@ -2722,6 +2723,118 @@
 
 
 
+    // ============================================
+    // APPROVALS PANEL
+    // ============================================
+    function formatApprovalExpiry(expiresAt) {
+        if (!expiresAt) return '—';
+        var mins = Math.round((new Date(expiresAt) - new Date()) / 60000);
+        if (mins <= 0) return 'due';
+        if (mins < 60) return mins + 'm';
+        if (mins < 48 * 60) return Math.round(mins / 60) + 'h';
+        return Math.round(mins / 1440) + 'd';
+    }
+
+    function loadApprovals() {
+        var loading = document.getElementById('approvals-loading');
+        var table = document.getElementById('approvals-table');
+        var tbody = document.getElementById('approvals-tbody');
+        var empty = document.getElementById('approvals-empty');
+        var count = document.getElementById('approvals-count');
+
+        if (!loading || !table || !tbody) return;
+
+        fetch('/api/approvals')
+            .then(function(r) { return r.json(); })
+            .then(function(data) {
+                loading.style.display = 'none';
+                var approvals = data.approvals || [];
+
+                if (approvals.length > 0) {
+                    table.style.display = 'table';
+                    empty.style.display = 'none';
+                    tbody.innerHTML = '';
+
+                    approvals.forEach(function(a) {
+                        var tr = document.createElement('tr');
+                        tr.className = 'approval-row';
+                        tr.innerHTML =
+                            '<td><span class="approval-step">' + escapeHtml(a.title || a.step) + '</span></td>' +
+                            '<td>' + escapeHtml(a.bead) + '</td>' +
+                            '<td>' + escapeHtml(a.requested_by || '') + '</td>' +
+                            '<td>' + formatApprovalExpiry(a.expires_at) + '</td>' +
+                            '<td class="escalation-actions">' +
+                                '<button class="approval-btn approval-approve-btn" data-decision="approve" data-id="' + escapeHtml(a.id) + '" title="Approve">✓ Approve</button>' +
+                                '<button class="approval-btn approval-reject-btn" data-decision="reject" data-id="' + escapeHtml(a.id) + '" title="Reject">✗ Reject</button>' +
+                            '</td>';
+                        tbody.appendChild(tr);
+                    });
+
+                    if (count) {
+                        count.textContent = approvals.length;
+                        count.classList.add('count-alert');
+                    }
+                } else {
+                    table.style.display = 'none';
+                    empty.style.display = 'block';
+                    if (count) {
+                        count.textContent = '0';
+                        count.classList.remove('count-alert');
+                    }
+                }
+            })
+            .catch(function(err) {
+                loading.textContent = 'Failed to load approvals';
+                console.error('Approvals load error:', err);
+            });
+    }
+
+    loadApprovals();
+    // Expose for refresh after HTMX swaps
+    window.refreshApprovalsPanel = loadApprovals;
+
+    document.addEventListener('click', function(e) {
+        var btn = e.target.closest('.approval-btn');
+        if (!btn) return;
+
+        e.preventDefault();
+        e.stopPropagation();
+
+        var decision = btn.getAttribute('data-decision');
+        var id = btn.getAttribute('data-id');
+        if (!decision || !id) return;
+
+        var reason = '';
+        if (decision === 'reject') {
+            reason = window.prompt('Reason for rejecting ' + id + ':', '');
+            if (reason === null) return;
+        }
+
+        var row = btn.closest('.approval-row');
+        var buttons = row ? row.querySelectorAll('.approval-btn') : [btn];
+        Array.prototype.forEach.call(buttons, function(b) { b.disabled = true; });
+
+        fetch('/api/approvals/decide', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ id: id, decision: decision, reason: reason })
+        })
+        .then(function(r) { return r.json(); })
+        .then(function(data) {
+            if (data.success) {
+                showToast('success', decision === 'approve' ? 'Approved' : 'Rejected', id);
+                loadApprovals();
+            } else {
+                showToast('error', 'Failed', data.error || 'Unknown error');
+                Array.prototype.forEach.call(buttons, function(b) { b.disabled = false; });
+            }
+        })
+        .catch(function(err) {
+            showToast('error', 'Error', err.message || 'Request failed');
+            Array.prototype.forEach.call(buttons, function(b) { b.disabled = false; });
+        });
+    });
+
     // ============================================
     // ESCALATION ACTIONS
     // ============================================
@@ -2979,6 +3092,70 @@
         }
     }
 
+    // ============================================
+    // ACTIVITY LOG QUERY (see gt log query)
+    // ============================================
+
+    document.addEventListener('submit', function(e) {
+        if (e.target.id !== 'tl-query-form') return;
+        e.preventDefault();
+        var input = document.getElementById('tl-query-input');
+        var results = document.getElementById('tl-query-results');
+        var clearBtn = document.getElementById('tl-query-clear');
+        if (!input || !results) return;
+
+        var query = input.value.trim();
+        if (!query) return;
+
+        window.pauseRefresh = true;
+        results.style.display = 'block';
+        results.textContent = 'Running query...';
+        if (clearBtn) clearBtn.style.display = '';
+
+        fetch('/api/log/query?q=' + encodeURIComponent(query))
+            .then(function(r) { return r.json(); })
+            .then(function(data) {
+                if (data.error) {
+                    results.textContent = data.error;
+                    return;
+                }
+                results.textContent = formatLogQueryResult(data);
+            })
+            .catch(function(err) {
+                results.textContent = 'Query failed: ' + err.message;
+            });
+    });
+
+    document.addEventListener('click', function(e) {
+        if (!e.target.closest('#tl-query-clear')) return;
+        var input = document.getElementById('tl-query-input');
+        var results = document.getElementById('tl-query-results');
+        if (input) input.value = '';
+        if (results) {
+            results.textContent = '';
+            results.style.display = 'none';
+        }
+        e.target.style.display = 'none';
+        window.pauseRefresh = false;
+    });
+
+    function formatLogQueryResult(data) {
+        if (data.aggregate) {
+            var agg = data.aggregate;
+            if (!agg.groups || agg.groups.length === 0) return 'No events match query';
+            return agg.groups.map(function(g) {
+                var keys = g.keys ? Object.keys(g.keys).map(function(k) { return g.keys[k] || '-'; }).join('  ') : 'total';
+                return String(g.count).padStart(6) + '  ' + keys;
+            }).join('\n');
+        }
+        if (!data.records || data.records.length === 0) return 'No events match query';
+        return data.records.map(function(r) {
+            var ts = new Date(r.ts).toLocaleString();
+            var detail = r.message || (r.payload ? JSON.stringify(r.payload) : '');
+            return ts + '  [' + r.type + '] ' + (r.actor || '') + ' ' + detail;
+        }).join('\n');
+    }
+
     // Init on page load
     initTimelineFilters();
 
@@ -92,6 +92,15 @@ type SessionRow struct {
 	Worker   string // Worker name for polecats/crew
 	Activity string // Age since last activity
 	IsAlive  bool   // Whether Claude is running in session
+
+	// Resource usage of the session's process tree, from the daemon's
+	// resource sampler. Sampled is false when there is no recent sample.
+	Sampled bool
+	Procs   int
+	CPU     string // e.g. "35%"
+	RSS     string // e.g. "1.2G"
+	Files   int
+	Runaway string // Exceeded runaway thresholds, empty if none
 }
 
 // HookRow represents a hooked bead (work pinned to an agent).
@@ -236,3 +236,34 @@ func TestConvoyTemplate_EmptyState(t *testing.T) {
 		t.Error("Template should show empty state message when no convoys")
 	}
 }
+
+func TestConvoyTemplate_SessionResourceUsage(t *testing.T) {
+	tmpl, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+
+	data := ConvoyData{
+		Sessions: []SessionRow{
+			{Name: "gt-gastown-toast", Role: "polecat", Rig: "gastown", Worker: "toast",
+				Sampled: true, Procs: 412, CPU: "350%", RSS: "2.1G", Files: 90,
+				Runaway: "412 processes (limit 256)"},
+			{Name: "gt-gastown-witness", Role: "witness", Rig: "gastown"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "convoy.html", data); err != nil {
+		t.Fatalf("ExecuteTemplate() error = %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{"412", "350%", "2.1G", "session-runaway", "Runaway: 412 processes (limit 256)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("sessions panel missing %q", want)
+		}
+	}
+	if strings.Count(output, "session-runaway") != 1 {
+		t.Error("only the runaway session should be marked")
+	}
+}
@@ -47,8 +47,7 @@ func NewManager(r *rig.Rig) *Manager {
 // ZFC: tmux session existence is the source of truth for session state,
 // but agent liveness determines if the session is actually functional.
 func (m *Manager) IsRunning() (bool, error) {
-	t := tmux.NewTmux()
-	status := t.CheckSessionHealth(m.SessionName(), 0)
+	status := session.CheckSessionHealth(m.sessions(), m.SessionName(), 0)
 	return status == tmux.SessionHealthy, nil
 }
 
@@ -58,8 +57,7 @@ func (m *Manager) IsRunning() (bool, error) {
 // Returns the detailed ZombieStatus for callers that need to distinguish
 // between different failure modes.
 func (m *Manager) IsHealthy(maxInactivity time.Duration) tmux.ZombieStatus {
-	t := tmux.NewTmux()
-	return t.CheckSessionHealth(m.SessionName(), maxInactivity)
+	return session.CheckSessionHealth(m.sessions(), m.SessionName(), maxInactivity)
 }
 
 // SessionName returns the tmux session name for this witness.
@@ -67,13 +65,19 @@ func (m *Manager) SessionName() string {
 	return session.WitnessSessionName(session.PrefixFor(m.rig.Name))
 }
 
+// sessions returns the town's session backend: tmux, or the daemon's PTY
+// supervisor for headless towns.
+func (m *Manager) sessions() session.SessionBackend {
+	return session.NewBackend(m.townRoot(), nil)
+}
+
 // Status returns information about the witness session.
 // ZFC-compliant: tmux session is the source of truth.
 func (m *Manager) Status() (*tmux.SessionInfo, error) {
-	t := tmux.NewTmux()
+	b := m.sessions()
 	sessionID := m.SessionName()
 
-	running, err := t.HasSession(sessionID)
+	running, err := b.HasSession(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("checking session: %w", err)
 	}
@@ -81,7 +85,7 @@ func (m *Manager) Status() (*tmux.SessionInfo, error) {
 		return nil, ErrNotRunning
 	}
 
-	return t.GetSessionInfo(sessionID)
+	return session.GetSessionInfo(b, sessionID)
 }
 
 // witnessDir returns the working directory for the witness.
@@ -109,6 +113,7 @@ func (m *Manager) witnessDir() string {
 func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []string) error {
 	t := tmux.NewTmux()
 	sessionID := m.SessionName()
+	headless := session.IsHeadless(m.townRoot())
 
 	if foreground {
 		// Foreground mode is deprecated - patrol logic moved to mol-witness-patrol
@@ -116,8 +121,12 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 	}
 
 	// Check if session already exists
-	running, _ := t.HasSession(sessionID)
-	if running {
+	if headless {
+		// PTY sessions end with their agent: no zombies to reap.
+		if running, _ := m.sessions().HasSession(sessionID); running {
+			return ErrAlreadyRunning
+		}
+	} else if running, _ := t.HasSession(sessionID); running {
 		// Session exists - check if Claude is actually running (healthy vs zombie)
 		if t.IsAgentAlive(sessionID) {
 			// Healthy - Claude is running
@@ -187,14 +196,9 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 	// Generate the GASTA run ID for this witness session.
 	runID := uuid.New().String()
 
-	// Create session with command directly to avoid send-keys race condition.
-	// See: https://github.com/anthropics/gastown/issues/280
-	if err := t.NewSessionWithCommand(sessionID, witnessDir, command); err != nil {
-		return fmt.Errorf("creating tmux session: %w", err)
-	}
-
-	// Set environment variables (non-fatal: session works without these)
-	// Use centralized AgentEnv for consistency across all role startup paths
+	// Session environment. Use centralized AgentEnv for consistency across
+	// all role startup paths; role config env vars and then CLI env
+	// overrides take priority.
 	envVars := config.AgentEnv(config.AgentEnvConfig{
 		Role:        "witness",
 		Rig:         m.rig.Name,
@@ -203,24 +207,57 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 		SessionName: sessionID,
 	})
 	envVars = session.MergeRuntimeLivenessEnv(envVars, runtimeConfig)
-	for k, v := range envVars {
-		_ = t.SetEnvironment(sessionID, k, v)
-	}
-	_ = t.SetEnvironment(sessionID, "GT_RUN", runID)
-	// Apply role config env vars if present (non-fatal).
+	envVars["GT_RUN"] = runID
 	for key, value := range roleConfigEnvVars(roleConfig, townRoot, m.rig.Name) {
-		_ = t.SetEnvironment(sessionID, key, value)
+		envVars[key] = value
 	}
-	// Apply CLI env overrides (highest priority, non-fatal).
 	for _, override := range envOverrides {
 		if key, value, ok := strings.Cut(override, "="); ok {
-			_ = t.SetEnvironment(sessionID, key, value)
+			envVars[key] = value
+		}
+	}
+
+	if headless {
+		if err := session.StartHeadlessAgent(m.sessions(), sessionID, witnessDir, command, envVars, runtimeConfig); err != nil {
+			return fmt.Errorf("starting witness: %w", err)
+		}
+	} else if err := startTmuxSession(t, sessionID, witnessDir, command, envVars, townRoot, m.rig.Name); err != nil {
+		return err
+	}
+
+	// Stream witness's Claude Code JSONL conversation log to VictoriaLogs (opt-in).
+	if os.Getenv("GT_LOG_AGENT_OUTPUT") == "true" && os.Getenv("GT_OTEL_LOGS_URL") != "" {
+		if err := session.ActivateAgentLogging(sessionID, witnessDir, runID); err != nil {
+			log.Printf("warning: agent log watcher setup failed for %s: %v", sessionID, err)
 		}
 	}
 
+	// Record the agent instantiation event (GASTA root span).
+	session.RecordAgentInstantiateFromDir(context.Background(), runID, runtimeConfig.ResolvedAgent,
+		"witness", "witness", sessionID, m.rig.Name, townRoot, "", witnessDir)
+
+	time.Sleep(constants.ShutdownNotifyDelay)
+
+	return nil
+}
+
+// startTmuxSession creates the witness's tmux session, sets its
+// environment, and waits for the agent to start.
+func startTmuxSession(t *tmux.Tmux, sessionID, witnessDir, command string, envVars map[string]string, townRoot, rigName string) error {
+	// Create session with command directly to avoid send-keys race condition.
+	// See: https://github.com/anthropics/gastown/issues/280
+	if err := t.NewSessionWithCommand(sessionID, witnessDir, command); err != nil {
+		return fmt.Errorf("creating tmux session: %w", err)
+	}
+
+	// Set environment variables (non-fatal: session works without these)
+	for k, v := range envVars {
+		_ = t.SetEnvironment(sessionID, k, v)
+	}
+
 	// Apply Gas Town theming (non-fatal: theming failure doesn't affect operation)
-	theme := tmux.AssignTheme(m.rig.Name)
-	_ = t.ConfigureGasTownSession(sessionID, theme, m.rig.Name, "witness", "witness")
+	theme := tmux.AssignTheme(rigName)
+	_ = t.ConfigureGasTownSession(sessionID, theme, rigName, "witness", "witness")
 
 	// Wait for Claude to start - fatal if Claude fails to launch
 	if err := t.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
@@ -238,20 +275,6 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 	if err := session.TrackSessionPID(townRoot, sessionID, t); err != nil {
 		log.Printf("warning: tracking session PID for %s: %v", sessionID, err)
 	}
-
-	// Stream witness's Claude Code JSONL conversation log to VictoriaLogs (opt-in).
-	if os.Getenv("GT_LOG_AGENT_OUTPUT") == "true" && os.Getenv("GT_OTEL_LOGS_URL") != "" {
-		if err := session.ActivateAgentLogging(sessionID, witnessDir, runID); err != nil {
-			log.Printf("warning: agent log watcher setup failed for %s: %v", sessionID, err)
-		}
-	}
-
-	// Record the agent instantiation event (GASTA root span).
-	session.RecordAgentInstantiateFromDir(context.Background(), runID, runtimeConfig.ResolvedAgent,
-		"witness", "witness", sessionID, m.rig.Name, townRoot, "", witnessDir)
-
-	time.Sleep(constants.ShutdownNotifyDelay)
-
 	return nil
 }
 
@@ -318,15 +341,15 @@ func buildWitnessStartCommand(rigPath, rigName, townRoot, sessionName, agentOver
 // Stop stops the witness.
 // ZFC-compliant: tmux session is the source of truth.
 func (m *Manager) Stop() error {
-	t := tmux.NewTmux()
+	b := m.sessions()
 	sessionID := m.SessionName()
 
-	// Check if tmux session exists
-	running, _ := t.HasSession(sessionID)
+	// Check if session exists
+	running, _ := b.HasSession(sessionID)
 	if !running {
 		return ErrNotRunning
 	}
 
-	// Kill the tmux session
-	return t.KillSession(sessionID)
+	// Kill the session and all its processes
+	return b.KillSessionWithProcesses(sessionID)
 }